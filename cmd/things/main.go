@@ -1,56 +1,150 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
 	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	"github.com/go-redis/redis"
 	"github.com/mainflux/mainflux"
 	log "github.com/mainflux/mainflux/logger"
 	"github.com/mainflux/mainflux/things"
 	"github.com/mainflux/mainflux/things/api"
 	grpcapi "github.com/mainflux/mainflux/things/api/grpc"
 	httpapi "github.com/mainflux/mainflux/things/api/http"
+	"github.com/mainflux/mainflux/things/cache"
 	"github.com/mainflux/mainflux/things/postgres"
+	rediscli "github.com/mainflux/mainflux/things/redis"
+	"github.com/mainflux/mainflux/things/tracing"
+	"github.com/mainflux/mainflux/things/ulid"
 	"github.com/mainflux/mainflux/things/uuid"
 	usersapi "github.com/mainflux/mainflux/users/api/grpc"
+	opentracing "github.com/opentracing/opentracing-go"
 	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	jconfig "github.com/uber/jaeger-client-go/config"
 	"google.golang.org/grpc"
 )
 
 const (
-	defDBHost   = "localhost"
-	defDBPort   = "5432"
-	defDBUser   = "mainflux"
-	defDBPass   = "mainflux"
-	defDBName   = "things"
-	defHTTPPort = "8180"
-	defGRPCPort = "8181"
-	defUsersURL = "localhost:8181"
-	envDBHost   = "MF_THINGS_DB_HOST"
-	envDBPort   = "MF_THINGS_DB_PORT"
-	envDBUser   = "MF_THINGS_DB_USER"
-	envDBPass   = "MF_THINGS_DB_PASS"
-	envDBName   = "MF_THINGS_DB"
-	envHTTPPort = "MF_THINGS_HTTP_PORT"
-	envGRPCPort = "MF_THINGS_GRPC_PORT"
-	envUsersURL = "MF_USERS_URL"
+	defDBHost       = "localhost"
+	defDBPort       = "5432"
+	defDBUser       = "mainflux"
+	defDBPass       = "mainflux"
+	defDBName       = "things"
+	defHTTPPort     = "8180"
+	defGRPCPort     = "8181"
+	defUsersURL     = "localhost:8181"
+	defUsersTimeout = "1s"
+	defAttempts     = "3"
+	defCacheTTL     = "10s"
+	defOrigins      = ""
+	defServiceKey   = ""
+	defIDProvider   = "uuid"
+	defUniqueNames  = "false"
+	defDisconnWnd   = "24h"
+	defHookTimeout  = "5s"
+	defHookAllowed  = ""
+	defHookDenied   = ""
+	defBasicAuth    = "false"
+	defStrictView   = "false"
+	defThingsQuota  = "0"
+	defChansQuota   = "0"
+	defRedacted     = ""
+	defDefLimit     = "10"
+	defMaxLimit     = "100"
+	defIdemTTL      = "24h"
+	defMaxBodySize  = "1048576"
+	defIdemDisconn  = "false"
+	defPurgeRetent  = "0"
+	defPurgeIntvl   = "1h"
+	defESURL        = ""
+	defESPass       = ""
+	defESDB         = "0"
+	defJaegerURL    = ""
+	envDBHost       = "MF_THINGS_DB_HOST"
+	envDBPort       = "MF_THINGS_DB_PORT"
+	envDBUser       = "MF_THINGS_DB_USER"
+	envDBPass       = "MF_THINGS_DB_PASS"
+	envDBName       = "MF_THINGS_DB"
+	envHTTPPort     = "MF_THINGS_HTTP_PORT"
+	envGRPCPort     = "MF_THINGS_GRPC_PORT"
+	envUsersURL     = "MF_USERS_URL"
+	envUsersTimeout = "MF_THINGS_USERS_TIMEOUT"
+	envAttempts     = "MF_THINGS_USERS_ATTEMPTS"
+	envCacheTTL     = "MF_THINGS_CACHE_TTL"
+	envOrigins      = "MF_THINGS_CORS_ORIGINS"
+	envServiceKey   = "MF_THINGS_SERVICE_KEY"
+	envIDProvider   = "MF_THINGS_ID_PROVIDER"
+	envUniqueNames  = "MF_THINGS_UNIQUE_CHANNEL_NAMES"
+	envDisconnWnd   = "MF_THINGS_DISCONNECTION_WINDOW"
+	envHookTimeout  = "MF_THINGS_WEBHOOK_TIMEOUT"
+	envHookAllowed  = "MF_THINGS_WEBHOOK_ALLOWED_HOSTS"
+	envHookDenied   = "MF_THINGS_WEBHOOK_DENIED_NETS"
+	envBasicAuth    = "MF_THINGS_BASIC_AUTH"
+	envStrictView   = "MF_THINGS_STRICT_VIEW"
+	envThingsQuota  = "MF_THINGS_THINGS_QUOTA"
+	envChansQuota   = "MF_THINGS_CHANNELS_QUOTA"
+	envRedacted     = "MF_THINGS_LOG_REDACTED_FIELDS"
+	envDefLimit     = "MF_THINGS_DEFAULT_LIMIT"
+	envMaxLimit     = "MF_THINGS_MAX_LIMIT"
+	envIdemTTL      = "MF_THINGS_IDEMPOTENCY_TTL"
+	envMaxBodySize  = "MF_THINGS_MAX_BODY_SIZE"
+	envIdemDisconn  = "MF_THINGS_IDEMPOTENT_DISCONNECT"
+	envPurgeRetent  = "MF_THINGS_PURGE_RETENTION"
+	envPurgeIntvl   = "MF_THINGS_PURGE_INTERVAL"
+	envESURL        = "MF_THINGS_ES_URL"
+	envESPass       = "MF_THINGS_ES_PASS"
+	envESDB         = "MF_THINGS_ES_DB"
+	envJaegerURL    = "MF_JAEGER_URL"
 )
 
 type config struct {
-	DBHost   string
-	DBPort   string
-	DBUser   string
-	DBPass   string
-	DBName   string
-	HTTPPort string
-	GRPCPort string
-	UsersURL string
+	DBHost       string
+	DBPort       string
+	DBUser       string
+	DBPass       string
+	DBName       string
+	HTTPPort     string
+	GRPCPort     string
+	UsersURL     string
+	UsersTimeout string
+	Attempts     string
+	CacheTTL     string
+	Origins      []string
+	ServiceKey   string
+	IDProvider   string
+	UniqueNames  string
+	DisconnWnd   string
+	HookTimeout  string
+	HookAllowed  []string
+	HookDenied   []string
+	BasicAuth    string
+	StrictView   string
+	ThingsQuota  string
+	ChansQuota   string
+	Redacted     []string
+	DefLimit     string
+	MaxLimit     string
+	IdemTTL      string
+	MaxBodySize  string
+	IdemDisconn  string
+	PurgeRetent  string
+	PurgeIntvl   string
+	ESURL        string
+	ESPass       string
+	ESDB         string
+	JaegerURL    string
 }
 
 func main() {
@@ -64,35 +158,254 @@ func main() {
 	conn := connectToUsersService(cfg.UsersURL, logger)
 	defer conn.Close()
 
-	svc := newService(conn, db, logger)
+	tracer, closer := initJaeger("things", cfg.JaegerURL, logger)
+	defer closer.Close()
+
+	ttl, err := time.ParseDuration(cfg.CacheTTL)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to parse cache TTL: %s", err))
+		os.Exit(1)
+	}
+
+	timeout, err := time.ParseDuration(cfg.UsersTimeout)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to parse users service timeout: %s", err))
+		os.Exit(1)
+	}
+
+	attempts, err := strconv.Atoi(cfg.Attempts)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to parse users service attempts: %s", err))
+		os.Exit(1)
+	}
+
+	disconnWnd, err := time.ParseDuration(cfg.DisconnWnd)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to parse disconnection window: %s", err))
+		os.Exit(1)
+	}
+
+	hookTimeout, err := time.ParseDuration(cfg.HookTimeout)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to parse webhook timeout: %s", err))
+		os.Exit(1)
+	}
+
+	hookDenied := cfg.HookDenied
+	if len(hookDenied) == 0 {
+		hookDenied = things.DefaultWebhookDeniedNets
+	}
+	hookPolicy, err := things.NewWebhookPolicy(cfg.HookAllowed, hookDenied)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to parse webhook denied networks: %s", err))
+		os.Exit(1)
+	}
+
+	idp, err := identityProvider(cfg.IDProvider)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to create identity provider: %s", err))
+		os.Exit(1)
+	}
+
+	opts := []things.Option{
+		things.WithIdentifyTimeout(timeout),
+		things.WithIdentifyAttempts(attempts),
+		things.WithServiceKey(cfg.ServiceKey),
+		things.WithKeyProvider(uuid.New()),
+		things.WithDisconnectionWindow(disconnWnd),
+		things.WithWebhookPolicy(hookPolicy),
+	}
+
+	uniqueNames, err := strconv.ParseBool(cfg.UniqueNames)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to parse unique channel names flag: %s", err))
+		os.Exit(1)
+	}
+	if uniqueNames {
+		opts = append(opts, things.WithUniqueChannelNames())
+	}
+
+	strictView, err := strconv.ParseBool(cfg.StrictView)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to parse strict view flag: %s", err))
+		os.Exit(1)
+	}
+	if strictView {
+		opts = append(opts, things.WithStrictView())
+	}
+
+	thingsQuota, err := strconv.Atoi(cfg.ThingsQuota)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to parse things quota: %s", err))
+		os.Exit(1)
+	}
+	chansQuota, err := strconv.Atoi(cfg.ChansQuota)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to parse channels quota: %s", err))
+		os.Exit(1)
+	}
+	if thingsQuota > 0 || chansQuota > 0 {
+		opts = append(opts, things.WithQuota(things.NewStaticQuota(thingsQuota, chansQuota)))
+	}
+
+	basicAuth, err := strconv.ParseBool(cfg.BasicAuth)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to parse basic auth flag: %s", err))
+		os.Exit(1)
+	}
+
+	defLimit, err := strconv.Atoi(cfg.DefLimit)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to parse default limit: %s", err))
+		os.Exit(1)
+	}
+	maxLimit, err := strconv.Atoi(cfg.MaxLimit)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to parse max limit: %s", err))
+		os.Exit(1)
+	}
+	if defLimit <= 0 || defLimit > maxLimit {
+		logger.Error(fmt.Sprintf("Invalid limits: default limit %d must be positive and at most max limit %d", defLimit, maxLimit))
+		os.Exit(1)
+	}
+
+	idemTTL, err := time.ParseDuration(cfg.IdemTTL)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to parse idempotency TTL: %s", err))
+		os.Exit(1)
+	}
+
+	maxBodySize, err := strconv.ParseInt(cfg.MaxBodySize, 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to parse max body size: %s", err))
+		os.Exit(1)
+	}
+	if maxBodySize <= 0 {
+		logger.Error(fmt.Sprintf("Invalid max body size %d: must be positive", maxBodySize))
+		os.Exit(1)
+	}
+
+	idemDisconn, err := strconv.ParseBool(cfg.IdemDisconn)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to parse idempotent disconnect flag: %s", err))
+		os.Exit(1)
+	}
+
+	purgeRetent, err := time.ParseDuration(cfg.PurgeRetent)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to parse purge retention: %s", err))
+		os.Exit(1)
+	}
+	purgeIntvl, err := time.ParseDuration(cfg.PurgeIntvl)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to parse purge interval: %s", err))
+		os.Exit(1)
+	}
+	if purgeRetent > 0 && (purgeIntvl <= 0 || cfg.ServiceKey == "") {
+		logger.Error("Invalid purge configuration: purging requires positive interval and the service key")
+		os.Exit(1)
+	}
+
+	bus := things.NewEventBus()
+	var events things.EventStream = bus
+	if cfg.ESURL != "" {
+		esClient := connectToRedis(cfg.ESURL, cfg.ESPass, cfg.ESDB, logger)
+		defer esClient.Close()
+
+		events = things.NewMultiStream(bus, rediscli.NewEventStream(esClient))
+	}
+
+	httpOpts := []httpapi.Option{
+		httpapi.WithEventBus(bus),
+		httpapi.WithLimits(defLimit, maxLimit),
+		httpapi.WithIdempotency(cache.NewIdempotencyStore(), idemTTL),
+		httpapi.WithMaxBodySize(maxBodySize),
+	}
+	if basicAuth {
+		httpOpts = append(httpOpts, httpapi.WithBasicAuth())
+	}
+	if idemDisconn {
+		httpOpts = append(httpOpts, httpapi.WithIdempotentDisconnect())
+	}
+
+	hooks := &http.Client{
+		Timeout:   hookTimeout,
+		Transport: &http.Transport{DialContext: hookPolicy.DialContext},
+	}
+
+	svc := newService(conn, db, tracer, idp, ttl, hooks, events, logger, cfg.Redacted, opts...)
 	errs := make(chan error, 2)
 
-	go startHTTPServer(svc, cfg.HTTPPort, logger, errs)
+	go startHTTPServer(svc, cfg.HTTPPort, cfg.Origins, logger, errs, httpOpts...)
 	go startGRPCServer(svc, cfg.GRPCPort, logger, errs)
 
+	if purgeRetent > 0 {
+		go things.PurgePeriodically(context.Background(), svc, cfg.ServiceKey, purgeRetent, purgeIntvl, logger)
+	}
+
 	go func() {
 		c := make(chan os.Signal)
 		signal.Notify(c, syscall.SIGINT)
 		errs <- fmt.Errorf("%s", <-c)
 	}()
 
-	err := <-errs
+	err = <-errs
 	logger.Error(fmt.Sprintf("Things service terminated: %s", err))
 }
 
 func loadConfig() config {
 	return config{
-		DBHost:   mainflux.Env(envDBHost, defDBHost),
-		DBPort:   mainflux.Env(envDBPort, defDBPort),
-		DBUser:   mainflux.Env(envDBUser, defDBUser),
-		DBPass:   mainflux.Env(envDBPass, defDBPass),
-		DBName:   mainflux.Env(envDBName, defDBName),
-		HTTPPort: mainflux.Env(envHTTPPort, defHTTPPort),
-		GRPCPort: mainflux.Env(envGRPCPort, defGRPCPort),
-		UsersURL: mainflux.Env(envUsersURL, defUsersURL),
+		DBHost:       mainflux.Env(envDBHost, defDBHost),
+		DBPort:       mainflux.Env(envDBPort, defDBPort),
+		DBUser:       mainflux.Env(envDBUser, defDBUser),
+		DBPass:       mainflux.Env(envDBPass, defDBPass),
+		DBName:       mainflux.Env(envDBName, defDBName),
+		HTTPPort:     mainflux.Env(envHTTPPort, defHTTPPort),
+		GRPCPort:     mainflux.Env(envGRPCPort, defGRPCPort),
+		UsersURL:     mainflux.Env(envUsersURL, defUsersURL),
+		UsersTimeout: mainflux.Env(envUsersTimeout, defUsersTimeout),
+		Attempts:     mainflux.Env(envAttempts, defAttempts),
+		CacheTTL:     mainflux.Env(envCacheTTL, defCacheTTL),
+		Origins:      list(mainflux.Env(envOrigins, defOrigins)),
+		ServiceKey:   mainflux.Env(envServiceKey, defServiceKey),
+		IDProvider:   mainflux.Env(envIDProvider, defIDProvider),
+		UniqueNames:  mainflux.Env(envUniqueNames, defUniqueNames),
+		DisconnWnd:   mainflux.Env(envDisconnWnd, defDisconnWnd),
+		HookTimeout:  mainflux.Env(envHookTimeout, defHookTimeout),
+		HookAllowed:  list(mainflux.Env(envHookAllowed, defHookAllowed)),
+		HookDenied:   list(mainflux.Env(envHookDenied, defHookDenied)),
+		BasicAuth:    mainflux.Env(envBasicAuth, defBasicAuth),
+		StrictView:   mainflux.Env(envStrictView, defStrictView),
+		ThingsQuota:  mainflux.Env(envThingsQuota, defThingsQuota),
+		ChansQuota:   mainflux.Env(envChansQuota, defChansQuota),
+		Redacted:     list(mainflux.Env(envRedacted, defRedacted)),
+		DefLimit:     mainflux.Env(envDefLimit, defDefLimit),
+		MaxLimit:     mainflux.Env(envMaxLimit, defMaxLimit),
+		IdemTTL:      mainflux.Env(envIdemTTL, defIdemTTL),
+		MaxBodySize:  mainflux.Env(envMaxBodySize, defMaxBodySize),
+		IdemDisconn:  mainflux.Env(envIdemDisconn, defIdemDisconn),
+		PurgeRetent:  mainflux.Env(envPurgeRetent, defPurgeRetent),
+		PurgeIntvl:   mainflux.Env(envPurgeIntvl, defPurgeIntvl),
+		ESURL:        mainflux.Env(envESURL, defESURL),
+		ESPass:       mainflux.Env(envESPass, defESPass),
+		ESDB:         mainflux.Env(envESDB, defESDB),
+		JaegerURL:    mainflux.Env(envJaegerURL, defJaegerURL),
 	}
 }
 
+// list parses the comma-separated list of values, such as the allowed CORS
+// origins.
+func list(values string) []string {
+	res := []string{}
+	for _, value := range strings.Split(values, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			res = append(res, value)
+		}
+	}
+
+	return res
+}
+
 func connectToDB(cfg config, logger log.Logger) *sql.DB {
 	db, err := postgres.Connect(cfg.DBHost, cfg.DBPort, cfg.DBName, cfg.DBUser, cfg.DBPass)
 	if err != nil {
@@ -103,7 +416,7 @@ func connectToDB(cfg config, logger log.Logger) *sql.DB {
 }
 
 func connectToUsersService(usersAddr string, logger log.Logger) *grpc.ClientConn {
-	conn, err := grpc.Dial(usersAddr, grpc.WithInsecure())
+	conn, err := grpc.Dial(usersAddr, grpc.WithInsecure(), grpc.WithUnaryInterceptor(grpcapi.RequestIDInterceptor))
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to connect to users service: %s", err))
 		os.Exit(1)
@@ -111,14 +424,69 @@ func connectToUsersService(usersAddr string, logger log.Logger) *grpc.ClientConn
 	return conn
 }
 
-func newService(conn *grpc.ClientConn, db *sql.DB, logger log.Logger) things.Service {
-	users := usersapi.NewClient(conn)
-	thingsRepo := postgres.NewThingRepository(db, logger)
-	channelsRepo := postgres.NewChannelRepository(db, logger)
-	idp := uuid.New()
+func connectToRedis(url, pass, db string, logger log.Logger) *redis.Client {
+	n, err := strconv.Atoi(db)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to parse event store database: %s", err))
+		os.Exit(1)
+	}
+
+	return redis.NewClient(&redis.Options{
+		Addr:     url,
+		Password: pass,
+		DB:       n,
+	})
+}
+
+// initJaeger creates the tracer reporting to the Jaeger agent at the provided
+// address. If the address is empty, tracing is disabled.
+func initJaeger(svcName, url string, logger log.Logger) (opentracing.Tracer, io.Closer) {
+	if url == "" {
+		return opentracing.NoopTracer{}, ioutil.NopCloser(nil)
+	}
+
+	tracer, closer, err := jconfig.Configuration{
+		ServiceName: svcName,
+		Sampler: &jconfig.SamplerConfig{
+			Type:  "const",
+			Param: 1,
+		},
+		Reporter: &jconfig.ReporterConfig{
+			LocalAgentHostPort: url,
+		},
+	}.NewTracer()
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to init Jaeger client: %s", err))
+		os.Exit(1)
+	}
+
+	return tracer, closer
+}
+
+// identityProvider creates the provider of things' and channels' identifiers
+// of the specified kind.
+func identityProvider(kind string) (things.IdentityProvider, error) {
+	switch kind {
+	case "uuid":
+		return uuid.New(), nil
+	case "ulid":
+		return ulid.New(), nil
+	default:
+		return nil, fmt.Errorf("unknown identity provider %s", kind)
+	}
+}
+
+func newService(conn *grpc.ClientConn, db *sql.DB, tracer opentracing.Tracer, idp things.IdentityProvider, ttl time.Duration, hooks things.HTTPClient, events things.EventStream, logger log.Logger, redacted []string, opts ...things.Option) things.Service {
+	users := tracing.UsersServiceMiddleware(tracer, usersapi.NewClient(conn))
+	thingsRepo := tracing.ThingRepositoryMiddleware(tracer, postgres.NewThingRepository(db, logger))
+	channelsRepo := tracing.ChannelRepositoryMiddleware(tracer, postgres.NewChannelRepository(db, logger))
 
-	svc := things.New(users, thingsRepo, channelsRepo, idp)
-	svc = api.LoggingMiddleware(svc, logger)
+	svc := things.New(users, thingsRepo, channelsRepo, idp, opts...)
+	svc = things.NewCachingService(svc, cache.New(), ttl)
+	svc = things.NewWebhookService(svc, channelsRepo, hooks, things.DefaultWebhookRetries, things.DefaultWebhookBackoff, logger)
+	svc = things.NewEventStoreService(svc, events, logger)
+	svc = api.TracingMiddleware(svc, tracer)
+	svc = api.LoggingMiddleware(svc, logger, redacted...)
 	svc = api.MetricsMiddleware(
 		svc,
 		kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
@@ -137,10 +505,10 @@ func newService(conn *grpc.ClientConn, db *sql.DB, logger log.Logger) things.Ser
 	return svc
 }
 
-func startHTTPServer(svc things.Service, port string, logger log.Logger, errs chan error) {
+func startHTTPServer(svc things.Service, port string, origins []string, logger log.Logger, errs chan error, opts ...httpapi.Option) {
 	p := fmt.Sprintf(":%s", port)
 	logger.Info(fmt.Sprintf("Things service started, exposed port %s", port))
-	errs <- http.ListenAndServe(p, httpapi.MakeHandler(svc))
+	errs <- http.ListenAndServe(p, httpapi.MakeHandler(svc, uuid.New(), origins, opts...))
 }
 
 func startGRPCServer(svc things.Service, port string, logger log.Logger, errs chan error) {
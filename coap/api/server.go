@@ -14,6 +14,7 @@ import (
 	gocoap "github.com/dustin/go-coap"
 	"github.com/mainflux/mainflux"
 	"github.com/mainflux/mainflux/coap"
+	"github.com/mainflux/mainflux/things"
 	"google.golang.org/grpc/status"
 )
 
@@ -34,7 +35,7 @@ func authKey(opt interface{}) (string, error) {
 	return arr[1], nil
 }
 
-func authorize(msg *gocoap.Message, res *gocoap.Message, cid string) (publisher *mainflux.Identity, err error) {
+func authorize(msg *gocoap.Message, res *gocoap.Message, cid string, mode things.AccessMode) (publisher *mainflux.Identity, err error) {
 	if !govalidator.IsUUID(cid) {
 		res.Code = gocoap.NotFound
 		return
@@ -55,7 +56,7 @@ func authorize(msg *gocoap.Message, res *gocoap.Message, cid string) (publisher
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
 
-	publisher, err = auth.CanAccess(ctx, &mainflux.AccessReq{Token: key, ChanID: cid})
+	publisher, err = auth.CanAccess(ctx, &mainflux.AccessReq{Token: key, ChanID: cid, Mode: string(mode)})
 
 	if err != nil {
 		e, ok := status.FromError(err)
@@ -94,7 +95,7 @@ func serve(svc coap.Service, conn *net.UDPConn, data []byte, addr *net.UDPAddr,
 		}
 		cid := mux.Var(&msg, chanID)
 		res.Type = gocoap.Acknowledgement
-		publisher, err := authorize(&msg, res, cid)
+		publisher, err := authorize(&msg, res, cid, things.AccessSub)
 		if err != nil {
 			break
 		}
@@ -104,7 +105,7 @@ func serve(svc coap.Service, conn *net.UDPConn, data []byte, addr *net.UDPAddr,
 	case gocoap.Acknowledgement:
 		cid := mux.Var(&msg, chanID)
 		res.Type = gocoap.Acknowledgement
-		publisher, err := authorize(&msg, res, cid)
+		publisher, err := authorize(&msg, res, cid, things.AccessSub)
 		if err != nil {
 			break
 		}
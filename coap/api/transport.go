@@ -18,6 +18,7 @@ import (
 	mux "github.com/dereulenspiegel/coap-mux"
 	gocoap "github.com/dustin/go-coap"
 	"github.com/mainflux/mainflux"
+	"github.com/mainflux/mainflux/things"
 )
 
 var (
@@ -86,7 +87,7 @@ func receive(svc coap.Service) handler {
 		}
 
 		cid := mux.Var(msg, "id")
-		publisher, err := authorize(msg, res, cid)
+		publisher, err := authorize(msg, res, cid, things.AccessPub)
 		if err != nil {
 			return res
 		}
@@ -120,7 +121,7 @@ func observe(svc coap.Service) handler {
 		}
 
 		cid := mux.Var(msg, "id")
-		publisher, err := authorize(msg, res, cid)
+		publisher, err := authorize(msg, res, cid, things.AccessSub)
 
 		if err != nil {
 			return res
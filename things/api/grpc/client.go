@@ -4,6 +4,7 @@ import (
 	"github.com/go-kit/kit/endpoint"
 	kitgrpc "github.com/go-kit/kit/transport/grpc"
 	"github.com/mainflux/mainflux"
+	"github.com/mainflux/mainflux/things"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 )
@@ -11,25 +12,34 @@ import (
 var _ mainflux.ThingsServiceClient = (*grpcClient)(nil)
 
 type grpcClient struct {
-	canAccess endpoint.Endpoint
+	canAccess      endpoint.Endpoint
+	canAccessBatch endpoint.Endpoint
 }
 
 // NewClient returns new gRPC client instance.
 func NewClient(conn *grpc.ClientConn) mainflux.ThingsServiceClient {
-	endpoint := kitgrpc.NewClient(
-		conn,
-		"mainflux.ThingsService",
-		"CanAccess",
-		encodeCanAccessRequest,
-		decodeCanAccessResponse,
-		mainflux.Identity{},
-	).Endpoint()
-
-	return &grpcClient{endpoint}
+	return &grpcClient{
+		canAccess: kitgrpc.NewClient(
+			conn,
+			"mainflux.ThingsService",
+			"CanAccess",
+			encodeCanAccessRequest,
+			decodeCanAccessResponse,
+			mainflux.Identity{},
+		).Endpoint(),
+		canAccessBatch: kitgrpc.NewClient(
+			conn,
+			"mainflux.ThingsService",
+			"CanAccessBatch",
+			encodeCanAccessBatchRequest,
+			decodeCanAccessBatchResponse,
+			mainflux.AccessBatchRes{},
+		).Endpoint(),
+	}
 }
 
 func (client grpcClient) CanAccess(ctx context.Context, req *mainflux.AccessReq, _ ...grpc.CallOption) (*mainflux.Identity, error) {
-	res, err := client.canAccess(ctx, accessReq{req.GetToken(), req.GetChanID()})
+	res, err := client.canAccess(ctx, accessReq{req.GetToken(), req.GetChanID(), things.AccessMode(req.GetMode())})
 	if err != nil {
 		return nil, err
 	}
@@ -38,12 +48,39 @@ func (client grpcClient) CanAccess(ctx context.Context, req *mainflux.AccessReq,
 	return &mainflux.Identity{Value: ar.id}, ar.err
 }
 
+func (client grpcClient) CanAccessBatch(ctx context.Context, req *mainflux.AccessBatchReq, _ ...grpc.CallOption) (*mainflux.AccessBatchRes, error) {
+	reqs := make([]accessReq, len(req.GetRequests()))
+	for i, r := range req.GetRequests() {
+		reqs[i] = accessReq{r.GetToken(), r.GetChanID(), things.AccessMode(r.GetMode())}
+	}
+
+	res, err := client.canAccessBatch(ctx, accessBatchReq{reqs})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.(*mainflux.AccessBatchRes), nil
+}
+
 func encodeCanAccessRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
 	req := grpcReq.(accessReq)
-	return &mainflux.AccessReq{Token: req.thingKey, ChanID: req.chanID}, nil
+	return &mainflux.AccessReq{Token: req.thingKey, ChanID: req.chanID, Mode: string(req.mode)}, nil
 }
 
 func decodeCanAccessResponse(_ context.Context, grpcRes interface{}) (interface{}, error) {
 	res := grpcRes.(*mainflux.Identity)
 	return accessRes{res.GetValue(), nil}, nil
 }
+
+func encodeCanAccessBatchRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(accessBatchReq)
+	reqs := make([]*mainflux.AccessReq, len(req.reqs))
+	for i, r := range req.reqs {
+		reqs[i] = &mainflux.AccessReq{Token: r.thingKey, ChanID: r.chanID, Mode: string(r.mode)}
+	}
+	return &mainflux.AccessBatchReq{Requests: reqs}, nil
+}
+
+func decodeCanAccessBatchResponse(_ context.Context, grpcRes interface{}) (interface{}, error) {
+	return grpcRes.(*mainflux.AccessBatchRes), nil
+}
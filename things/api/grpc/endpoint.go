@@ -13,10 +13,44 @@ func canAccessEndpoint(svc things.Service) endpoint.Endpoint {
 			return nil, err
 		}
 
-		id, err := svc.CanAccess(req.thingKey, req.chanID)
+		id, err := svc.CanAccess(ctx, req.thingKey, req.chanID, req.mode)
 		if err != nil {
 			return accessRes{"", err}, err
 		}
 		return accessRes{id, nil}, nil
 	}
 }
+
+func canAccessBatchEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(accessBatchReq)
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		// Malformed requests are answered right away, while the rest of
+		// them are checked by the service and merged back in place.
+		results := make([]accessRes, len(req.reqs))
+		valid := []things.AccessRequest{}
+		indices := []int{}
+		for i, r := range req.reqs {
+			if err := r.validate(); err != nil {
+				results[i] = accessRes{"", err}
+				continue
+			}
+			valid = append(valid, things.AccessRequest{ChanID: r.chanID, Key: r.thingKey, Mode: r.mode})
+			indices = append(indices, i)
+		}
+
+		checked, err := svc.CanAccessBatch(ctx, valid)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, res := range checked {
+			results[indices[i]] = accessRes{res.ThingID, res.Err}
+		}
+
+		return accessBatchRes{results}, nil
+	}
+}
@@ -48,10 +48,18 @@ func TestCanAccess(t *testing.T) {
 	svc := newService(map[string]string{token: email})
 	startGRPCServer(svc, port)
 
-	oth, _ := svc.AddThing(token, thing)
-	cth, _ := svc.AddThing(token, thing)
-	sch, _ := svc.CreateChannel(token, channel)
-	svc.Connect(token, sch.ID, cth.ID)
+	oth, _ := svc.AddThing(context.Background(), token, thing)
+	cth, _ := svc.AddThing(context.Background(), token, thing)
+	dth, _ := svc.AddThing(context.Background(), token, thing)
+	rth, _ := svc.AddThing(context.Background(), token, thing)
+	pth, _ := svc.AddThing(context.Background(), token, thing)
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	svc.Connect(context.Background(), token, sch.ID, cth.ID, things.AccessPubSub)
+	svc.Connect(context.Background(), token, sch.ID, dth.ID, things.AccessPubSub)
+	svc.Connect(context.Background(), token, sch.ID, rth.ID, things.AccessPubSub)
+	svc.Connect(context.Background(), token, sch.ID, pth.ID, things.AccessPub)
+	svc.DisableThing(context.Background(), token, dth.ID)
+	svc.RemoveThing(context.Background(), token, rth.ID)
 
 	usersAddr := fmt.Sprintf("localhost:%d", port)
 	conn, _ := grpc.Dial(usersAddr, grpc.WithInsecure())
@@ -62,20 +70,90 @@ func TestCanAccess(t *testing.T) {
 	cases := map[string]struct {
 		thingKey string
 		chanID   string
+		mode     string
 		id       string
 		code     codes.Code
 	}{
-		"check if connected thing can access existing channel":             {cth.Key, sch.ID, cth.ID, codes.OK},
-		"check if unconnected thing can access existing channel":           {oth.Key, sch.ID, "", codes.PermissionDenied},
-		"check if thing with wrong access key can access existing channel": {wrong, sch.ID, "", codes.PermissionDenied},
-		"check if connected thing can access non-existent channel":         {cth.Key, wrong, "", codes.InvalidArgument},
+		"check if connected thing can access existing channel":             {cth.Key, sch.ID, "", cth.ID, codes.OK},
+		"check if unconnected thing can access existing channel":           {oth.Key, sch.ID, "", "", codes.PermissionDenied},
+		"check if thing with wrong access key can access existing channel": {wrong, sch.ID, "", "", codes.PermissionDenied},
+		"check if connected thing can access non-existent channel":         {cth.Key, wrong, "", "", codes.InvalidArgument},
+		"check if disabled thing can access existing channel":              {dth.Key, sch.ID, "", "", codes.PermissionDenied},
+		"check if removed thing can access existing channel":               {rth.Key, sch.ID, "", "", codes.PermissionDenied},
+		"check if connected thing can access channel with invalid mode":    {cth.Key, sch.ID, "invalid", "", codes.InvalidArgument},
+		"check if pub-only thing can publish to existing channel":          {pth.Key, sch.ID, "pub", pth.ID, codes.OK},
+		"check if pub-only thing can subscribe to existing channel":        {pth.Key, sch.ID, "sub", "", codes.PermissionDenied},
+		"check if pub-only thing can access existing channel without mode": {pth.Key, sch.ID, "", "", codes.PermissionDenied},
 	}
 
 	for desc, tc := range cases {
-		id, err := cli.CanAccess(ctx, &mainflux.AccessReq{tc.thingKey, tc.chanID})
+		id, err := cli.CanAccess(ctx, &mainflux.AccessReq{Token: tc.thingKey, ChanID: tc.chanID, Mode: tc.mode})
 		e, ok := status.FromError(err)
 		assert.True(t, ok, "OK expected to be true")
 		assert.Equal(t, tc.id, id.GetValue(), fmt.Sprintf("%s: expected %s got %s", desc, tc.id, id.GetValue()))
 		assert.Equal(t, tc.code, e.Code(), fmt.Sprintf("%s: expected %s got %s", desc, tc.code, e.Code()))
 	}
 }
+
+func TestCanAccessBatch(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	startGRPCServer(svc, port+1)
+
+	oth, _ := svc.AddThing(context.Background(), token, thing)
+	cth, _ := svc.AddThing(context.Background(), token, thing)
+	dth, _ := svc.AddThing(context.Background(), token, thing)
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	svc.Connect(context.Background(), token, sch.ID, cth.ID, things.AccessPubSub)
+	svc.Connect(context.Background(), token, sch.ID, dth.ID, things.AccessPubSub)
+	svc.DisableThing(context.Background(), token, dth.ID)
+
+	usersAddr := fmt.Sprintf("localhost:%d", port+1)
+	conn, _ := grpc.Dial(usersAddr, grpc.WithInsecure())
+	cli := grpcapi.NewClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	cases := []struct {
+		desc     string
+		thingKey string
+		chanID   string
+		id       string
+		code     codes.Code
+	}{
+		{"check if connected thing can access existing channel", cth.Key, sch.ID, cth.ID, codes.OK},
+		{"check if unconnected thing can access existing channel", oth.Key, sch.ID, "", codes.PermissionDenied},
+		{"check if thing with wrong access key can access existing channel", wrong, sch.ID, "", codes.PermissionDenied},
+		{"check if connected thing can access non-existent channel", cth.Key, wrong, "", codes.InvalidArgument},
+		{"check if disabled thing can access existing channel", dth.Key, sch.ID, "", codes.PermissionDenied},
+		{"check if thing without access key can access existing channel", "", sch.ID, "", codes.InvalidArgument},
+		{"check again if connected thing can access existing channel", cth.Key, sch.ID, cth.ID, codes.OK},
+	}
+
+	reqs := []*mainflux.AccessReq{}
+	for _, tc := range cases {
+		reqs = append(reqs, &mainflux.AccessReq{Token: tc.thingKey, ChanID: tc.chanID})
+	}
+
+	res, err := cli.CanAccessBatch(ctx, &mainflux.AccessBatchReq{Requests: reqs})
+	assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	results := res.GetResults()
+	assert.Equal(t, len(cases), len(results), fmt.Sprintf("expected %d results got %d", len(cases), len(results)))
+
+	for i, tc := range cases {
+		if i >= len(results) {
+			break
+		}
+		code := codes.Code(results[i].GetCode())
+		assert.Equal(t, tc.id, results[i].GetValue(), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.id, results[i].GetValue()))
+		assert.Equal(t, tc.code, code, fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.code, code))
+	}
+
+	tooLarge := make([]*mainflux.AccessReq, things.MaxAccessBatchSize+1)
+	for i := range tooLarge {
+		tooLarge[i] = &mainflux.AccessReq{Token: cth.Key, ChanID: sch.ID}
+	}
+	_, err = cli.CanAccessBatch(ctx, &mainflux.AccessBatchReq{Requests: tooLarge})
+	e, ok := status.FromError(err)
+	assert.True(t, ok, "OK expected to be true")
+	assert.Equal(t, codes.InvalidArgument, e.Code(), fmt.Sprintf("too large batch: expected %s got %s", codes.InvalidArgument, e.Code()))
+}
@@ -0,0 +1,24 @@
+package grpc
+
+import (
+	"github.com/mainflux/mainflux/things"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const requestIDKey = "x-request-id"
+
+var _ grpc.UnaryClientInterceptor = RequestIDInterceptor
+
+// RequestIDInterceptor is a unary client interceptor that attaches the
+// request identifier carried by the call's context, if any, to the outgoing
+// metadata. It allows correlating the calls made to other services (e.g.
+// users) with the request that caused them.
+func RequestIDInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if id := things.RequestID(ctx); id != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, requestIDKey, id)
+	}
+
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
@@ -0,0 +1,38 @@
+package grpc_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/mainflux/mainflux/things"
+	grpcapi "github.com/mainflux/mainflux/things/api/grpc"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestRequestIDInterceptor(t *testing.T) {
+	reqID := "5f1c8a2e-4b7d-4e3a-9c61-2d8f0b7a9e34"
+
+	cases := map[string]struct {
+		ctx context.Context
+		ids []string
+	}{
+		"call with request ID":    {things.WithRequestID(context.Background(), reqID), []string{reqID}},
+		"call without request ID": {context.Background(), nil},
+	}
+
+	for desc, tc := range cases {
+		var ids []string
+		invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			md, _ := metadata.FromOutgoingContext(ctx)
+			ids = md.Get("x-request-id")
+			return nil
+		}
+
+		err := grpcapi.RequestIDInterceptor(tc.ctx, "/mainflux.UsersService/Identify", nil, nil, nil, invoker)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", desc, err))
+		assert.Equal(t, tc.ids, ids, fmt.Sprintf("%s: expected %v got %v", desc, tc.ids, ids))
+	}
+}
@@ -8,11 +8,23 @@ import (
 type accessReq struct {
 	thingKey string
 	chanID   string
+	mode     things.AccessMode
 }
 
 func (req accessReq) validate() error {
 	if !govalidator.IsUUID(req.chanID) || req.thingKey == "" {
 		return things.ErrMalformedEntity
 	}
+	return req.mode.Validate()
+}
+
+type accessBatchReq struct {
+	reqs []accessReq
+}
+
+func (req accessBatchReq) validate() error {
+	if len(req.reqs) > things.MaxAccessBatchSize {
+		return things.ErrMalformedEntity
+	}
 	return nil
 }
@@ -4,3 +4,7 @@ type accessRes struct {
 	id  string
 	err error
 }
+
+type accessBatchRes struct {
+	results []accessRes
+}
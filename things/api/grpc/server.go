@@ -12,30 +12,54 @@ import (
 var _ mainflux.ThingsServiceServer = (*grpcServer)(nil)
 
 type grpcServer struct {
-	handler kitgrpc.Handler
+	canAccess      kitgrpc.Handler
+	canAccessBatch kitgrpc.Handler
 }
 
 // NewServer returns new ThingsServiceServer instance.
 func NewServer(svc things.Service) mainflux.ThingsServiceServer {
-	handler := kitgrpc.NewServer(
-		canAccessEndpoint(svc),
-		decodeCanAccessRequest,
-		encodeCanAccessResponse,
-	)
-	return &grpcServer{handler}
+	return &grpcServer{
+		canAccess: kitgrpc.NewServer(
+			canAccessEndpoint(svc),
+			decodeCanAccessRequest,
+			encodeCanAccessResponse,
+		),
+		canAccessBatch: kitgrpc.NewServer(
+			canAccessBatchEndpoint(svc),
+			decodeCanAccessBatchRequest,
+			encodeCanAccessBatchResponse,
+		),
+	}
 }
 
 func (s *grpcServer) CanAccess(ctx context.Context, req *mainflux.AccessReq) (*mainflux.Identity, error) {
-	_, res, err := s.handler.ServeGRPC(ctx, req)
+	_, res, err := s.canAccess.ServeGRPC(ctx, req)
 	if err != nil {
 		return nil, encodeError(err)
 	}
 	return res.(*mainflux.Identity), nil
 }
 
+func (s *grpcServer) CanAccessBatch(ctx context.Context, req *mainflux.AccessBatchReq) (*mainflux.AccessBatchRes, error) {
+	_, res, err := s.canAccessBatch.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, encodeError(err)
+	}
+	return res.(*mainflux.AccessBatchRes), nil
+}
+
 func decodeCanAccessRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
 	req := grpcReq.(*mainflux.AccessReq)
-	return accessReq{req.GetToken(), req.GetChanID()}, nil
+	return accessReq{req.GetToken(), req.GetChanID(), accessMode(req.GetMode())}, nil
+}
+
+// accessMode parses the requested access mode. Requests lacking the mode ask
+// both to publish and to subscribe.
+func accessMode(mode string) things.AccessMode {
+	if mode == "" {
+		return things.AccessPubSub
+	}
+	return things.AccessMode(mode)
 }
 
 func encodeCanAccessResponse(_ context.Context, grpcRes interface{}) (interface{}, error) {
@@ -43,6 +67,29 @@ func encodeCanAccessResponse(_ context.Context, grpcRes interface{}) (interface{
 	return &mainflux.Identity{Value: res.id}, encodeError(res.err)
 }
 
+func decodeCanAccessBatchRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*mainflux.AccessBatchReq)
+	reqs := make([]accessReq, len(req.GetRequests()))
+	for i, r := range req.GetRequests() {
+		reqs[i] = accessReq{r.GetToken(), r.GetChanID(), accessMode(r.GetMode())}
+	}
+	return accessBatchReq{reqs}, nil
+}
+
+func encodeCanAccessBatchResponse(_ context.Context, grpcRes interface{}) (interface{}, error) {
+	res := grpcRes.(accessBatchRes)
+	results := make([]*mainflux.AccessResult, len(res.results))
+	for i, r := range res.results {
+		s := status.Convert(encodeError(r.err))
+		results[i] = &mainflux.AccessResult{
+			Value: r.id,
+			Code:  uint32(s.Code()),
+			Error: s.Message(),
+		}
+	}
+	return &mainflux.AccessBatchRes{Results: results}, nil
+}
+
 func encodeError(err error) error {
 	if err == nil {
 		return nil
@@ -53,6 +100,8 @@ func encodeError(err error) error {
 		return status.Error(codes.InvalidArgument, "received invalid can access request")
 	case things.ErrUnauthorizedAccess:
 		return status.Error(codes.PermissionDenied, "missing or invalid credentials provided")
+	case things.ErrUnavailable:
+		return status.Error(codes.Unavailable, "service unavailable")
 	default:
 		return status.Error(codes.Internal, "internal server error")
 	}
@@ -0,0 +1,61 @@
+package http
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strings"
+)
+
+const basicScheme = "basic "
+
+// basicAuth makes the key provided as the password of the HTTP Basic
+// credentials available to the decoders as the Authorization header value.
+// The key provided directly as the Authorization header value takes
+// precedence over the Basic credentials.
+func basicAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if values := r.Header["Authorization"]; len(values) > 0 {
+			r.Header.Set("Authorization", authKey(values))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authKey resolves the key from the provided Authorization header values,
+// preferring the first value that isn't the Basic credentials.
+func authKey(values []string) string {
+	basic := ""
+	for _, val := range values {
+		password, ok := basicPassword(val)
+		if !ok {
+			return val
+		}
+
+		if basic == "" {
+			basic = password
+		}
+	}
+
+	return basic
+}
+
+// basicPassword extracts the password from the Basic credentials. False is
+// returned if the provided value isn't the valid Basic credentials.
+func basicPassword(val string) (string, bool) {
+	if len(val) < len(basicScheme) || !strings.EqualFold(val[:len(basicScheme)], basicScheme) {
+		return "", false
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(val[len(basicScheme):]))
+	if err != nil {
+		return "", false
+	}
+
+	parts := strings.SplitN(string(decoded), ":", 2)
+	if len(parts) != 2 {
+		return "", false
+	}
+
+	return parts[1], true
+}
@@ -0,0 +1,428 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mainflux/mainflux/things"
+)
+
+const (
+	contentType     = "application/json"
+	channelIDHeader = "X-Channel-ID"
+	thingIDHeader   = "X-Thing-ID"
+	thingsPath      = "/things"
+	channelsPath    = "/channels"
+)
+
+// ErrUnexpectedResponse indicates that the API responded with the status
+// that doesn't correspond to any of the things service errors.
+var ErrUnexpectedResponse = errors.New("unexpected response")
+
+// Client provides access to the things service HTTP API. Its methods mirror
+// the ones of things.Service, reporting the service errors the API responses
+// correspond to.
+type Client struct {
+	url  string
+	http *http.Client
+}
+
+// New returns the client of the API served at the provided base URL. If the
+// provided HTTP client is nil, http.DefaultClient is used instead.
+func New(baseURL string, client *http.Client) Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return Client{
+		url:  strings.TrimSuffix(baseURL, "/"),
+		http: client,
+	}
+}
+
+// AddThing adds the thing, and returns it as it was saved. The returned thing
+// is marked as existing if the owner had already added it with the same
+// external identifier.
+func (c Client) AddThing(ctx context.Context, key string, thing things.Thing) (things.Thing, error) {
+	res, err := c.send(ctx, http.MethodPost, thingsPath, nil, key, nil, thing)
+	if err != nil {
+		return things.Thing{}, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated && res.StatusCode != http.StatusOK {
+		return things.Thing{}, errorOf(res.StatusCode)
+	}
+
+	saved, err := c.ViewThing(ctx, key, locationID(res, thingsPath))
+	if err != nil {
+		return things.Thing{}, err
+	}
+	saved.Existing = res.StatusCode == http.StatusOK
+
+	return saved, nil
+}
+
+// UpdateThing updates the thing identified by the provided thing's ID. The
+// update is rejected with things.ErrVersionMismatch unless the provided
+// thing's version is the current one. Zero version updates any version.
+func (c Client) UpdateThing(ctx context.Context, key string, thing things.Thing) error {
+	tag := "*"
+	if thing.Version > 0 {
+		tag = fmt.Sprintf(`"%d"`, thing.Version)
+	}
+	header := map[string]string{"If-Match": tag}
+
+	res, err := c.send(ctx, http.MethodPut, resourcePath(thingsPath, thing.ID), nil, key, header, thing)
+	if err != nil {
+		return err
+	}
+
+	return expect(res, http.StatusOK, nil)
+}
+
+// ViewThing retrieves the thing identified by the provided ID.
+func (c Client) ViewThing(ctx context.Context, key, id string) (things.Thing, error) {
+	res, err := c.send(ctx, http.MethodGet, resourcePath(thingsPath, id), nil, key, nil, nil)
+	if err != nil {
+		return things.Thing{}, err
+	}
+
+	var thing things.Thing
+	if err := expect(res, http.StatusOK, &thing); err != nil {
+		return things.Thing{}, err
+	}
+
+	return thing, nil
+}
+
+// ListThings retrieves the page of things satisfying all of the provided
+// filter's criteria.
+func (c Client) ListThings(ctx context.Context, key string, offset, limit int, sorting things.Sorting, filter things.ThingFilter) (things.ThingPage, error) {
+	query := pageQuery(offset, limit, sorting, filter.Metadata)
+	setQuery(query, "name", filter.Name)
+	setQuery(query, "type", filter.Type)
+	setQuery(query, "status", filter.Status)
+	setQuery(query, "tag", filter.Tag)
+
+	res, err := c.send(ctx, http.MethodGet, thingsPath, query, key, nil, nil)
+	if err != nil {
+		return things.ThingPage{}, err
+	}
+
+	var page thingsPageRes
+	if err := expect(res, http.StatusOK, &page); err != nil {
+		return things.ThingPage{}, err
+	}
+
+	return things.ThingPage{
+		Things: page.Things,
+		Total:  page.Total,
+		Offset: page.Offset,
+		Limit:  page.Limit,
+	}, nil
+}
+
+// ListThingsAfter retrieves at most limit things following the one
+// identified by afterID, in the order of their identifiers. Empty afterID
+// retrieves the first page, and the ID of the last retrieved thing is the
+// afterID of the next one.
+func (c Client) ListThingsAfter(ctx context.Context, key, afterID string, limit int) ([]things.Thing, error) {
+	query := url.Values{}
+	query.Set("page_token", base64.RawURLEncoding.EncodeToString([]byte(afterID)))
+	query.Set("limit", strconv.Itoa(limit))
+
+	res, err := c.send(ctx, http.MethodGet, thingsPath, query, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var page thingsPageRes
+	if err := expect(res, http.StatusOK, &page); err != nil {
+		return nil, err
+	}
+
+	return page.Things, nil
+}
+
+// RemoveThing removes the thing identified by the provided ID.
+func (c Client) RemoveThing(ctx context.Context, key, id string) error {
+	res, err := c.send(ctx, http.MethodDelete, resourcePath(thingsPath, id), nil, key, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	return expect(res, http.StatusNoContent, nil)
+}
+
+// CreateChannel adds the channel, and returns it as it was saved.
+func (c Client) CreateChannel(ctx context.Context, key string, channel things.Channel) (things.Channel, error) {
+	res, err := c.send(ctx, http.MethodPost, channelsPath, nil, key, nil, channel)
+	if err != nil {
+		return things.Channel{}, err
+	}
+
+	if err := expect(res, http.StatusCreated, nil); err != nil {
+		return things.Channel{}, err
+	}
+
+	return c.ViewChannel(ctx, key, locationID(res, channelsPath))
+}
+
+// UpdateChannel updates the channel identified by the provided channel's ID.
+func (c Client) UpdateChannel(ctx context.Context, key string, channel things.Channel) error {
+	res, err := c.send(ctx, http.MethodPut, resourcePath(channelsPath, channel.ID), nil, key, nil, channel)
+	if err != nil {
+		return err
+	}
+
+	return expect(res, http.StatusOK, nil)
+}
+
+// ViewChannel retrieves the channel identified by the provided ID.
+func (c Client) ViewChannel(ctx context.Context, key, id string) (things.Channel, error) {
+	res, err := c.send(ctx, http.MethodGet, resourcePath(channelsPath, id), nil, key, nil, nil)
+	if err != nil {
+		return things.Channel{}, err
+	}
+
+	var channel things.Channel
+	if err := expect(res, http.StatusOK, &channel); err != nil {
+		return things.Channel{}, err
+	}
+
+	return channel, nil
+}
+
+// ListChannels retrieves the page of channels matching the provided
+// metadata filter.
+func (c Client) ListChannels(ctx context.Context, key string, offset, limit int, sorting things.Sorting, filter things.MetadataFilter) (things.ChannelPage, error) {
+	query := pageQuery(offset, limit, sorting, filter)
+
+	res, err := c.send(ctx, http.MethodGet, channelsPath, query, key, nil, nil)
+	if err != nil {
+		return things.ChannelPage{}, err
+	}
+
+	var page channelsPageRes
+	if err := expect(res, http.StatusOK, &page); err != nil {
+		return things.ChannelPage{}, err
+	}
+
+	return things.ChannelPage{
+		Channels: page.Channels,
+		Total:    page.Total,
+		Offset:   page.Offset,
+		Limit:    page.Limit,
+	}, nil
+}
+
+// RemoveChannel removes the channel identified by the provided ID.
+func (c Client) RemoveChannel(ctx context.Context, key, id string) error {
+	res, err := c.send(ctx, http.MethodDelete, resourcePath(channelsPath, id), nil, key, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	return expect(res, http.StatusNoContent, nil)
+}
+
+// Connect connects the thing to the channel with the provided access mode.
+func (c Client) Connect(ctx context.Context, key, chanID, thingID string, mode things.AccessMode) (things.Connection, error) {
+	query := url.Values{}
+	setQuery(query, "mode", string(mode))
+
+	res, err := c.send(ctx, http.MethodPut, connectionPath(chanID, thingID), query, key, nil, nil)
+	if err != nil {
+		return things.Connection{}, err
+	}
+
+	var conn connectionRes
+	if err := expect(res, http.StatusCreated, &conn); err != nil {
+		return things.Connection{}, err
+	}
+
+	return things.Connection{
+		ChanID:      conn.ChanID,
+		ThingID:     conn.ThingID,
+		Mode:        things.AccessMode(conn.Mode),
+		ConnectedAt: conn.ConnectedAt,
+	}, nil
+}
+
+// Disconnect disconnects the thing from the channel, and reports whether
+// they were connected. Unless the API is configured to disconnect
+// idempotently, things.ErrNotFound is returned instead of false.
+func (c Client) Disconnect(ctx context.Context, key, chanID, thingID string) (bool, error) {
+	res, err := c.send(ctx, http.MethodDelete, connectionPath(chanID, thingID), nil, key, nil, nil)
+	if err != nil {
+		return false, err
+	}
+
+	var disc disconnectionRes
+	if err := expect(res, http.StatusOK, &disc); err != nil {
+		return false, err
+	}
+
+	return disc.Disconnected, nil
+}
+
+// CanAccess determines whether the thing identified by the provided key can
+// access the channel with the provided mode, and returns the thing's ID.
+func (c Client) CanAccess(ctx context.Context, key, chanID string, mode things.AccessMode) (string, error) {
+	query := url.Values{}
+	setQuery(query, "mode", string(mode))
+	header := map[string]string{channelIDHeader: chanID}
+
+	res, err := c.send(ctx, http.MethodGet, "/access", query, key, header, nil)
+	if err != nil {
+		return "", err
+	}
+
+	if err := expect(res, http.StatusOK, nil); err != nil {
+		return "", err
+	}
+
+	return res.Header.Get(thingIDHeader), nil
+}
+
+type thingsPageRes struct {
+	Things []things.Thing `json:"things"`
+	Total  int            `json:"total"`
+	Offset int            `json:"offset"`
+	Limit  int            `json:"limit"`
+}
+
+type channelsPageRes struct {
+	Channels []things.Channel `json:"channels"`
+	Total    int              `json:"total"`
+	Offset   int              `json:"offset"`
+	Limit    int              `json:"limit"`
+}
+
+type connectionRes struct {
+	ChanID      string    `json:"channel_id"`
+	ThingID     string    `json:"thing_id"`
+	Mode        string    `json:"mode"`
+	ConnectedAt time.Time `json:"connected_at"`
+}
+
+type disconnectionRes struct {
+	Disconnected bool `json:"disconnected"`
+}
+
+// send makes the request authorized by the provided key, sending the body
+// encoded as JSON unless it is nil.
+func (c Client) send(ctx context.Context, method, path string, query url.Values, key string, header map[string]string, body interface{}) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	u := c.url + path
+	if len(query) > 0 {
+		u = fmt.Sprintf("%s?%s", u, query.Encode())
+	}
+
+	req, err := http.NewRequest(method, u, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", key)
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for name, value := range header {
+		req.Header.Set(name, value)
+	}
+
+	return c.http.Do(req.WithContext(ctx))
+}
+
+// expect closes the response, decoding its body into the provided value
+// unless it is nil. The response with other than the expected status is
+// reported as the service error it corresponds to.
+func expect(res *http.Response, status int, v interface{}) error {
+	defer res.Body.Close()
+
+	if res.StatusCode != status {
+		io.Copy(ioutil.Discard, res.Body)
+		return errorOf(res.StatusCode)
+	}
+
+	if v == nil {
+		return nil
+	}
+
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+// errorOf maps the status of the error response to the service error the
+// API reports with it.
+func errorOf(status int) error {
+	switch status {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return things.ErrMalformedEntity
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return things.ErrUnauthorizedAccess
+	case http.StatusNotFound:
+		return things.ErrNotFound
+	case http.StatusConflict:
+		return things.ErrConflict
+	case http.StatusPreconditionFailed:
+		return things.ErrVersionMismatch
+	case http.StatusTooManyRequests:
+		return things.ErrQuotaExceeded
+	default:
+		return ErrUnexpectedResponse
+	}
+}
+
+func pageQuery(offset, limit int, sorting things.Sorting, filter things.MetadataFilter) url.Values {
+	query := url.Values{}
+	query.Set("offset", strconv.Itoa(offset))
+	query.Set("limit", strconv.Itoa(limit))
+	setQuery(query, "order", sorting.Order)
+	setQuery(query, "dir", sorting.Dir)
+	if filter.Key != "" {
+		query.Set("metadata", fmt.Sprintf("%s:%s", filter.Key, filter.Value))
+	}
+
+	return query
+}
+
+func setQuery(query url.Values, name, value string) {
+	if value != "" {
+		query.Set(name, value)
+	}
+}
+
+func resourcePath(collection, id string) string {
+	return fmt.Sprintf("%s/%s", collection, url.PathEscape(id))
+}
+
+func connectionPath(chanID, thingID string) string {
+	return fmt.Sprintf("%s/%s/things/%s", channelsPath, url.PathEscape(chanID), url.PathEscape(thingID))
+}
+
+// locationID extracts the identifier of the created resource from the
+// response's Location header.
+func locationID(res *http.Response, collection string) string {
+	return strings.TrimPrefix(res.Header.Get("Location"), collection+"/")
+}
@@ -0,0 +1,272 @@
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mainflux/mainflux/things"
+	httpapi "github.com/mainflux/mainflux/things/api/http"
+	"github.com/mainflux/mainflux/things/api/http/client"
+	"github.com/mainflux/mainflux/things/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	email   = "user@example.com"
+	token   = "token"
+	wrong   = "wrong"
+	wrongID = "123e4567-e89b-12d3-a456-000000000042"
+)
+
+var (
+	thing   = things.Thing{Type: "app", Name: "test_app", Metadata: map[string]interface{}{"test": "data"}}
+	channel = things.Channel{Name: "test", Metadata: map[string]interface{}{"test": "data"}}
+)
+
+func newService(tokens map[string]string) things.Service {
+	users := mocks.NewUsersService(tokens)
+	thingsRepo := mocks.NewThingRepository()
+	channelsRepo := mocks.NewChannelRepository(thingsRepo)
+	idp := mocks.NewIdentityProvider()
+	return things.New(users, thingsRepo, channelsRepo, idp)
+}
+
+func newClient(svc things.Service, opts ...httpapi.Option) (client.Client, func()) {
+	ts := httptest.NewServer(httpapi.MakeHandler(svc, mocks.NewIdentityProvider(), nil, opts...))
+	return client.New(ts.URL, ts.Client()), ts.Close
+}
+
+func TestAddThing(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	c, stop := newClient(svc)
+	defer stop()
+
+	cases := []struct {
+		desc  string
+		thing things.Thing
+		key   string
+		err   error
+	}{
+		{"add valid thing", thing, token, nil},
+		{"add thing with invalid type", things.Thing{Type: "foo", Name: "invalid"}, token, things.ErrMalformedEntity},
+		{"add thing with wrong credentials", thing, wrong, things.ErrUnauthorizedAccess},
+	}
+
+	for _, tc := range cases {
+		saved, err := c.AddThing(context.Background(), tc.key, tc.thing)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+		if err != nil {
+			continue
+		}
+		assert.NotEmpty(t, saved.ID, fmt.Sprintf("%s: expected non-empty ID", tc.desc))
+		assert.NotEmpty(t, saved.Key, fmt.Sprintf("%s: expected non-empty key", tc.desc))
+		assert.Equal(t, tc.thing.Name, saved.Name, fmt.Sprintf("%s: expected name %s got %s", tc.desc, tc.thing.Name, saved.Name))
+	}
+}
+
+func TestUpdateThing(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	c, stop := newClient(svc)
+	defer stop()
+
+	saved, err := c.AddThing(context.Background(), token, thing)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	updated := saved
+	updated.Name = "updated"
+	stale := saved
+	stale.Version = saved.Version + 1
+	missing := saved
+	missing.ID = wrongID
+
+	cases := []struct {
+		desc  string
+		thing things.Thing
+		key   string
+		err   error
+	}{
+		{"update existing thing", updated, token, nil},
+		{"update thing with stale version", updated, token, things.ErrVersionMismatch},
+		{"update thing with any version", func() things.Thing { th := updated; th.Version = 0; return th }(), token, nil},
+		{"update thing with future version", stale, token, things.ErrVersionMismatch},
+		{"update non-existent thing", missing, token, things.ErrNotFound},
+		{"update thing with wrong credentials", updated, wrong, things.ErrUnauthorizedAccess},
+	}
+
+	for _, tc := range cases {
+		err := c.UpdateThing(context.Background(), tc.key, tc.thing)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+	}
+
+	th, err := c.ViewThing(context.Background(), token, saved.ID)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	assert.Equal(t, updated.Name, th.Name, fmt.Sprintf("expected name %s got %s", updated.Name, th.Name))
+}
+
+func TestViewAndRemoveThing(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	c, stop := newClient(svc)
+	defer stop()
+
+	saved, err := c.AddThing(context.Background(), token, thing)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	th, err := c.ViewThing(context.Background(), token, saved.ID)
+	assert.Nil(t, err, fmt.Sprintf("view existing thing: unexpected error %s", err))
+	assert.Equal(t, saved, th, fmt.Sprintf("view existing thing: expected %v got %v", saved, th))
+
+	_, err = c.ViewThing(context.Background(), token, wrongID)
+	assert.Equal(t, things.ErrNotFound, err, fmt.Sprintf("view non-existent thing: expected %s got %s", things.ErrNotFound, err))
+
+	err = c.RemoveThing(context.Background(), wrong, saved.ID)
+	assert.Equal(t, things.ErrUnauthorizedAccess, err, fmt.Sprintf("remove thing with wrong credentials: expected %s got %s", things.ErrUnauthorizedAccess, err))
+
+	err = c.RemoveThing(context.Background(), token, saved.ID)
+	assert.Nil(t, err, fmt.Sprintf("remove existing thing: unexpected error %s", err))
+
+	_, err = c.ViewThing(context.Background(), token, saved.ID)
+	assert.Equal(t, things.ErrNotFound, err, fmt.Sprintf("view removed thing: expected %s got %s", things.ErrNotFound, err))
+}
+
+func TestListThings(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	c, stop := newClient(svc)
+	defer stop()
+
+	n := 10
+	var ids []string
+	for i := 0; i < n; i++ {
+		th := thing
+		th.Name = fmt.Sprintf("thing-%d", i)
+		if i%2 == 0 {
+			th.Type = "device"
+		}
+		saved, err := c.AddThing(context.Background(), token, th)
+		assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+		ids = append(ids, saved.ID)
+	}
+
+	cases := []struct {
+		desc   string
+		key    string
+		offset int
+		limit  int
+		filter things.ThingFilter
+		size   int
+		total  int
+		err    error
+	}{
+		{"list first page", token, 0, 4, things.ThingFilter{}, 4, n, nil},
+		{"list last page", token, 8, 4, things.ThingFilter{}, 2, n, nil},
+		{"list things by type and metadata", token, 0, n, things.ThingFilter{Type: "device", Metadata: things.MetadataFilter{Key: "test", Value: "data"}}, n / 2, n / 2, nil},
+		{"list things with invalid type", token, 0, n, things.ThingFilter{Type: "foo", Status: things.StatusEnabled}, 0, 0, things.ErrMalformedEntity},
+		{"list things with wrong credentials", wrong, 0, n, things.ThingFilter{}, 0, 0, things.ErrUnauthorizedAccess},
+	}
+
+	for _, tc := range cases {
+		page, err := c.ListThings(context.Background(), tc.key, tc.offset, tc.limit, things.Sorting{}, tc.filter)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+		assert.Equal(t, tc.size, len(page.Things), fmt.Sprintf("%s: expected %d things got %d", tc.desc, tc.size, len(page.Things)))
+		assert.Equal(t, tc.total, page.Total, fmt.Sprintf("%s: expected total %d got %d", tc.desc, tc.total, page.Total))
+	}
+
+	var listed []string
+	afterID := ""
+	for {
+		ths, err := c.ListThingsAfter(context.Background(), token, afterID, 3)
+		assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+		if err != nil || len(ths) == 0 {
+			break
+		}
+		for _, th := range ths {
+			listed = append(listed, th.ID)
+		}
+		afterID = ths[len(ths)-1].ID
+	}
+	assert.ElementsMatch(t, ids, listed, fmt.Sprintf("list things after: expected %v got %v", ids, listed))
+}
+
+func TestChannels(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	c, stop := newClient(svc)
+	defer stop()
+
+	saved, err := c.CreateChannel(context.Background(), token, channel)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	assert.NotEmpty(t, saved.ID, "create channel: expected non-empty ID")
+	assert.Equal(t, channel.Name, saved.Name, fmt.Sprintf("create channel: expected name %s got %s", channel.Name, saved.Name))
+
+	_, err = c.CreateChannel(context.Background(), wrong, channel)
+	assert.Equal(t, things.ErrUnauthorizedAccess, err, fmt.Sprintf("create channel with wrong credentials: expected %s got %s", things.ErrUnauthorizedAccess, err))
+
+	saved.Name = "updated"
+	err = c.UpdateChannel(context.Background(), token, saved)
+	assert.Nil(t, err, fmt.Sprintf("update channel: unexpected error %s", err))
+
+	ch, err := c.ViewChannel(context.Background(), token, saved.ID)
+	assert.Nil(t, err, fmt.Sprintf("view channel: unexpected error %s", err))
+	assert.Equal(t, saved.Name, ch.Name, fmt.Sprintf("view channel: expected name %s got %s", saved.Name, ch.Name))
+
+	page, err := c.ListChannels(context.Background(), token, 0, 10, things.Sorting{}, things.MetadataFilter{Key: "test", Value: "data"})
+	assert.Nil(t, err, fmt.Sprintf("list channels: unexpected error %s", err))
+	assert.Equal(t, 1, page.Total, fmt.Sprintf("list channels: expected total 1 got %d", page.Total))
+	assert.Equal(t, 1, len(page.Channels), fmt.Sprintf("list channels: expected 1 channel got %d", len(page.Channels)))
+
+	err = c.RemoveChannel(context.Background(), token, saved.ID)
+	assert.Nil(t, err, fmt.Sprintf("remove channel: unexpected error %s", err))
+
+	_, err = c.ViewChannel(context.Background(), token, saved.ID)
+	assert.Equal(t, things.ErrNotFound, err, fmt.Sprintf("view removed channel: expected %s got %s", things.ErrNotFound, err))
+}
+
+func TestConnections(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	c, stop := newClient(svc)
+	defer stop()
+
+	th, err := c.AddThing(context.Background(), token, thing)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	ch, err := c.CreateChannel(context.Background(), token, channel)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	_, err = c.Connect(context.Background(), token, wrongID, th.ID, things.AccessPubSub)
+	assert.Equal(t, things.ErrNotFound, err, fmt.Sprintf("connect to non-existent channel: expected %s got %s", things.ErrNotFound, err))
+
+	conn, err := c.Connect(context.Background(), token, ch.ID, th.ID, things.AccessPub)
+	assert.Nil(t, err, fmt.Sprintf("connect: unexpected error %s", err))
+	assert.Equal(t, things.AccessPub, conn.Mode, fmt.Sprintf("connect: expected mode %s got %s", things.AccessPub, conn.Mode))
+
+	cases := []struct {
+		desc string
+		key  string
+		mode things.AccessMode
+		id   string
+		err  error
+	}{
+		{"publish to connected channel", th.Key, things.AccessPub, th.ID, nil},
+		{"subscribe to publish-only channel", th.Key, things.AccessSub, "", things.ErrUnauthorizedAccess},
+		{"access channel with wrong key", wrong, things.AccessPub, "", things.ErrUnauthorizedAccess},
+	}
+
+	for _, tc := range cases {
+		id, err := c.CanAccess(context.Background(), tc.key, ch.ID, tc.mode)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+		assert.Equal(t, tc.id, id, fmt.Sprintf("%s: expected id %s got %s", tc.desc, tc.id, id))
+	}
+
+	removed, err := c.Disconnect(context.Background(), token, ch.ID, th.ID)
+	assert.Nil(t, err, fmt.Sprintf("disconnect: unexpected error %s", err))
+	assert.True(t, removed, "disconnect: expected connection to be removed")
+
+	_, err = c.Disconnect(context.Background(), token, ch.ID, th.ID)
+	assert.Equal(t, things.ErrNotFound, err, fmt.Sprintf("disconnect again: expected %s got %s", things.ErrNotFound, err))
+
+	idem, stopIdem := newClient(svc, httpapi.WithIdempotentDisconnect())
+	defer stopIdem()
+
+	removed, err = idem.Disconnect(context.Background(), token, ch.ID, th.ID)
+	assert.Nil(t, err, fmt.Sprintf("disconnect idempotently: unexpected error %s", err))
+	assert.False(t, removed, "disconnect idempotently: expected no connection to be removed")
+}
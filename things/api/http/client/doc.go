@@ -0,0 +1,2 @@
+// Package client contains the client of things service HTTP API.
+package client
@@ -0,0 +1,135 @@
+package http
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	gzipEncoding = "gzip"
+	metricsPath  = "/metrics"
+
+	// minGzipSize is the smallest body worth compressing. Below it, gzip
+	// overhead outweighs the savings.
+	minGzipSize = 1024
+)
+
+// compress gzip-encodes the response bodies of at least minGzipSize bytes,
+// if the client accepts gzip encoding. The metrics endpoint is left as is,
+// since the Prometheus handler negotiates the encoding on its own, and so are
+// the event streams, since they are flushed event by event.
+func compress(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == metricsPath || strings.HasSuffix(r.URL.Path, eventsPathSuffix) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		gw := &gzipWriter{ResponseWriter: w}
+		defer gw.close()
+
+		next.ServeHTTP(gw, r)
+	})
+}
+
+// acceptsGzip determines whether the request's Accept-Encoding header lists
+// gzip encoding, without explicitly rejecting it.
+func acceptsGzip(r *http.Request) bool {
+	return accepts(r.Header.Get("Accept-Encoding"), gzipEncoding)
+}
+
+// accepts determines whether the provided value of the Accept or the
+// Accept-Encoding header lists the provided choice, without explicitly
+// rejecting it with zero quality.
+func accepts(header, choice string) bool {
+	for _, enc := range strings.Split(header, ",") {
+		parts := strings.Split(enc, ";")
+		if !strings.EqualFold(strings.TrimSpace(parts[0]), choice) {
+			continue
+		}
+
+		for _, param := range parts[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+
+			if q, err := strconv.ParseFloat(param[len("q="):], 64); err == nil && q == 0 {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// gzipWriter buffers the response until it either reaches minGzipSize bytes,
+// in which case the rest of it is compressed, or is completed, in which case
+// it is sent as is. The status code is withheld until then, since the
+// encoding headers must precede it.
+type gzipWriter struct {
+	http.ResponseWriter
+	status int
+	buf    []byte
+	gz     *gzip.Writer
+}
+
+func (gw *gzipWriter) WriteHeader(status int) {
+	if gw.status == 0 {
+		gw.status = status
+	}
+}
+
+func (gw *gzipWriter) Write(b []byte) (int, error) {
+	if gw.status == 0 {
+		gw.status = http.StatusOK
+	}
+
+	if gw.gz != nil {
+		return gw.gz.Write(b)
+	}
+
+	gw.buf = append(gw.buf, b...)
+	if len(gw.buf) < minGzipSize {
+		return len(b), nil
+	}
+
+	gw.Header().Set("Content-Encoding", gzipEncoding)
+	gw.Header().Del("Content-Length")
+	gw.ResponseWriter.WriteHeader(gw.status)
+
+	gw.gz = gzip.NewWriter(gw.ResponseWriter)
+	if _, err := gw.gz.Write(gw.buf); err != nil {
+		return 0, err
+	}
+	gw.buf = nil
+
+	return len(b), nil
+}
+
+func (gw *gzipWriter) close() error {
+	if gw.gz != nil {
+		return gw.gz.Close()
+	}
+
+	if gw.status != 0 {
+		gw.ResponseWriter.WriteHeader(gw.status)
+	}
+
+	if len(gw.buf) == 0 {
+		return nil
+	}
+
+	_, err := gw.ResponseWriter.Write(gw.buf)
+	return err
+}
@@ -0,0 +1,86 @@
+package http
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-zoo/bone"
+)
+
+const anyOrigin = "*"
+
+var (
+	corsMethods = []string{
+		http.MethodGet,
+		http.MethodPost,
+		http.MethodPut,
+		http.MethodPatch,
+		http.MethodDelete,
+		http.MethodHead,
+	}
+	corsHeaders = []string{"Authorization", "Content-Type", "If-Match", "If-None-Match", idempotencyKeyHeader, requestIDHeader}
+	corsExposed = []string{"ETag", "Location", requestIDHeader}
+)
+
+// registerPreflight registers the OPTIONS handler for each of the routes
+// already registered with the provided router. The handler reports the
+// methods supported by the route.
+func registerPreflight(r *bone.Mux) {
+	paths := []string{}
+	methods := map[string][]string{}
+	for _, method := range corsMethods {
+		for _, route := range r.Routes[method] {
+			if _, ok := methods[route.Path]; !ok {
+				paths = append(paths, route.Path)
+			}
+			methods[route.Path] = append(methods[route.Path], method)
+		}
+	}
+
+	// routes are matched in the order of their registration, so the ones
+	// with fewer parameters must take precedence (e.g. /things/count over
+	// /things/:id)
+	sort.SliceStable(paths, func(i, j int) bool {
+		return strings.Count(paths[i], ":") < strings.Count(paths[j], ":")
+	})
+
+	for _, path := range paths {
+		allowed := strings.Join(append(methods[path], http.MethodOptions), ", ")
+		r.OptionsFunc(path, func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Allow", allowed)
+			w.Header().Set("Access-Control-Allow-Methods", allowed)
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+// cors sets the CORS response headers on the requests coming from one of the
+// allowed origins. Only the explicitly listed origins are echoed back and
+// allowed to send credentials, while any other origin is allowed without
+// credentials if any origin is allowed.
+func cors(next http.Handler, origins []string) http.Handler {
+	allowed := map[string]bool{}
+	for _, origin := range origins {
+		allowed[origin] = true
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		switch {
+		case origin == "":
+		case origin != anyOrigin && allowed[origin]:
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(corsHeaders, ", "))
+			w.Header().Set("Access-Control-Expose-Headers", strings.Join(corsExposed, ", "))
+			w.Header().Add("Vary", "Origin")
+		case allowed[anyOrigin]:
+			w.Header().Set("Access-Control-Allow-Origin", anyOrigin)
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(corsHeaders, ", "))
+			w.Header().Set("Access-Control-Expose-Headers", strings.Join(corsExposed, ", "))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
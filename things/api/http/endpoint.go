@@ -4,37 +4,87 @@ import (
 	"context"
 
 	"github.com/go-kit/kit/endpoint"
+	"github.com/mainflux/mainflux"
 	"github.com/mainflux/mainflux/things"
 )
 
 func addThingEndpoint(svc things.Service) endpoint.Endpoint {
-	return func(_ context.Context, request interface{}) (interface{}, error) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(addThingReq)
 
 		if err := req.validate(); err != nil {
 			return nil, err
 		}
 
-		saved, err := svc.AddThing(req.key, req.thing)
+		saved, err := svc.AddThing(ctx, req.key, req.thing)
 		if err != nil {
 			return nil, err
 		}
 
-		return thingRes{id: saved.ID, created: true}, nil
+		return thingRes{id: saved.ID, created: !saved.Existing, existing: saved.Existing}, nil
+	}
+}
+
+func identifyThingEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(identifyThingReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		thing, err := svc.ViewThingByKey(ctx, req.Key)
+		if err == things.ErrNotFound {
+			return nil, errInvalidThingKey
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// disabled things cannot access any of the channels, so their keys
+		// are of no use to the device
+		if thing.Status == things.StatusDisabled {
+			return nil, errInvalidThingKey
+		}
+
+		return thingIdentityRes{ID: thing.ID, Owner: thing.Owner}, nil
+	}
+}
+
+func createThingsEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(createThingsReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		saved, err := svc.CreateThings(ctx, req.key, req.things, req.dryRun)
+		if err != nil {
+			return nil, err
+		}
+
+		return createThingsRes{Things: saved, created: !req.dryRun}, nil
 	}
 }
 
 func updateThingEndpoint(svc things.Service) endpoint.Endpoint {
-	return func(_ context.Context, request interface{}) (interface{}, error) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(updateThingReq)
 
 		if err := req.validate(); err != nil {
 			return nil, err
 		}
 
+		version, err := ifMatchVersion(req.ifMatch)
+		if err != nil {
+			return nil, err
+		}
+
 		req.thing.ID = req.id
+		req.thing.Version = version
 
-		if err := svc.UpdateThing(req.key, req.thing); err != nil {
+		if err := svc.UpdateThing(ctx, req.key, req.thing); err != nil {
 			return nil, err
 		}
 
@@ -42,42 +92,293 @@ func updateThingEndpoint(svc things.Service) endpoint.Endpoint {
 	}
 }
 
-func viewThingEndpoint(svc things.Service) endpoint.Endpoint {
-	return func(_ context.Context, request interface{}) (interface{}, error) {
+func patchThingEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(patchThingReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		thing, err := svc.ViewThing(ctx, req.key, req.id)
+		if err != nil {
+			return nil, err
+		}
+
+		req.patch.apply(&thing)
+
+		if err := svc.UpdateThing(ctx, req.key, thing); err != nil {
+			return nil, err
+		}
+
+		return thingRes{id: req.id, created: false}, nil
+	}
+}
+
+func updateKeyEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(updateKeyReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		if err := svc.UpdateKey(ctx, req.key, req.id, req.newKey); err != nil {
+			return nil, err
+		}
+
+		return thingRes{id: req.id, created: false}, nil
+	}
+}
+
+func rotateKeyEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(viewResourceReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		key, err := svc.RotateKey(ctx, req.key, req.id)
+		if err != nil {
+			return nil, err
+		}
+
+		return keyRes{Key: key}, nil
+	}
+}
+
+func disableThingEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(viewResourceReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		if err := svc.DisableThing(ctx, req.key, req.id); err != nil {
+			return nil, err
+		}
+
+		return thingRes{id: req.id, created: false}, nil
+	}
+}
+
+func enableThingEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(viewResourceReq)
 
 		if err := req.validate(); err != nil {
 			return nil, err
 		}
 
-		thing, err := svc.ViewThing(req.key, req.id)
+		if err := svc.EnableThing(ctx, req.key, req.id); err != nil {
+			return nil, err
+		}
+
+		return thingRes{id: req.id, created: false}, nil
+	}
+}
+
+func viewThingEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(viewThingReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		thing, err := svc.ViewThing(ctx, req.key, req.id)
 		if err != nil {
 			return nil, err
 		}
 
-		return viewThingRes{thing}, nil
+		res := viewThingRes{Thing: thing}
+		if req.channels {
+			// connecting the thing doesn't change its version, so the tag
+			// can't tell whether the included channels have changed
+			if res.Channels, err = connectedChannels(ctx, svc, req.key, thing.ID); err != nil {
+				return nil, err
+			}
+		} else {
+			res.etag = thingETag(thing)
+			res.notModified = etagMatches(req.ifNoneMatch, res.etag)
+		}
+
+		if req.fields != nil {
+			fields := req.fields
+			if req.channels {
+				fields = append(fields, "channels")
+			}
+			return sparseRes{Response: res, fields: fields}, nil
+		}
+
+		return res, nil
+	}
+}
+
+// connectedChannels retrieves the summaries of all of the channels the thing
+// is connected to, page by page.
+func connectedChannels(ctx context.Context, svc things.Service, key, thingID string) ([]channelSummary, error) {
+	summaries := []channelSummary{}
+	for offset := 0; ; offset += maxLimitSize {
+		chs, err := svc.ListChannelsByThing(ctx, key, thingID, offset, maxLimitSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ch := range chs {
+			summaries = append(summaries, channelSummary{ID: ch.ID, Name: ch.Name})
+		}
+
+		if len(chs) < maxLimitSize {
+			return summaries, nil
+		}
 	}
 }
 
 func listThingsEndpoint(svc things.Service) endpoint.Endpoint {
-	return func(_ context.Context, request interface{}) (interface{}, error) {
-		req := request.(listResourcesReq)
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(searchThingsReq)
 
 		if err := req.validate(); err != nil {
 			return nil, err
 		}
 
-		things, err := svc.ListThings(req.key, req.offset, req.limit)
+		res, err := listThings(ctx, svc, req)
+		if err != nil {
+			return nil, err
+		}
+
+		if req.csv {
+			return csvThingsRes{Things: listedThings(res)}, nil
+		}
+
+		if req.fields != nil {
+			return sparseRes{Response: res, fields: req.fields}, nil
+		}
+
+		return res, nil
+	}
+}
+
+// listThings retrieves the things selected by the validated request.
+func listThings(ctx context.Context, svc things.Service, req searchThingsReq) (mainflux.Response, error) {
+	if req.ids != nil {
+		ths, err := svc.ViewThings(ctx, req.key, req.ids)
+		if err != nil {
+			return nil, err
+		}
+
+		return viewThingsRes{Things: ths}, nil
+	}
+
+	if req.paged {
+		ths, err := svc.ListThingsAfter(ctx, req.key, req.afterID, req.limit)
+		if err != nil {
+			return nil, err
+		}
+
+		res := tokenThingsRes{
+			Things: ths,
+			Limit:  req.limit,
+		}
+		if len(ths) == req.limit {
+			res.NextToken = pageToken(ths[len(ths)-1].ID)
+		}
+
+		return res, nil
+	}
+
+	if req.unconnected {
+		ths, err := svc.ListUnconnectedThings(ctx, req.key, req.offset, req.limit)
 		if err != nil {
 			return nil, err
 		}
 
-		return listThingsRes{things}, nil
+		res := searchThingsRes{
+			Things: ths,
+			Offset: req.offset,
+			Limit:  req.limit,
+			Links:  newPageLinks(req.url, req.offset, req.limit, len(ths) < req.limit),
+		}
+
+		return res, nil
+	}
+
+	var page things.ThingPage
+	var err error
+	if req.deleted {
+		page, err = svc.ListDeletedThings(ctx, req.key, req.offset, req.limit, req.sorting)
+	} else {
+		page, err = svc.ListThings(ctx, req.key, req.offset, req.limit, req.sorting, req.filter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := listThingsRes{
+		Things: page.Things,
+		Total:  page.Total,
+		Offset: page.Offset,
+		Limit:  page.Limit,
+		Links:  newPageLinks(req.url, page.Offset, page.Limit, page.Offset+page.Limit >= page.Total),
+	}
+
+	return res, nil
+}
+
+func countThingsEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(countThingsReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		count, err := svc.CountThings(ctx, req.key, req.filter)
+		if err != nil {
+			return nil, err
+		}
+
+		return countRes{Count: count}, nil
+	}
+}
+
+func removeAllThingsEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(identityReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		if err := svc.RemoveAllThings(ctx, req.key); err != nil {
+			return nil, err
+		}
+
+		return removeRes{}, nil
+	}
+}
+
+func removeAllChannelsEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(identityReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		if err := svc.RemoveAllChannels(ctx, req.key); err != nil {
+			return nil, err
+		}
+
+		return removeRes{}, nil
 	}
 }
 
 func removeThingEndpoint(svc things.Service) endpoint.Endpoint {
-	return func(_ context.Context, request interface{}) (interface{}, error) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(viewResourceReq)
 
 		err := req.validate()
@@ -89,7 +390,7 @@ func removeThingEndpoint(svc things.Service) endpoint.Endpoint {
 			return nil, err
 		}
 
-		if err = svc.RemoveThing(req.key, req.id); err != nil {
+		if err = svc.RemoveThing(ctx, req.key, req.id); err != nil {
 			return nil, err
 		}
 
@@ -97,15 +398,47 @@ func removeThingEndpoint(svc things.Service) endpoint.Endpoint {
 	}
 }
 
+func restoreThingEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(viewResourceReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		if err := svc.RestoreThing(ctx, req.key, req.id); err != nil {
+			return nil, err
+		}
+
+		return thingRes{id: req.id, created: false}, nil
+	}
+}
+
+func transferThingEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transferReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		if err := svc.TransferThing(ctx, req.key, req.id, req.Owner); err != nil {
+			return nil, err
+		}
+
+		return thingRes{id: req.id, created: false}, nil
+	}
+}
+
 func createChannelEndpoint(svc things.Service) endpoint.Endpoint {
-	return func(_ context.Context, request interface{}) (interface{}, error) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(createChannelReq)
 
 		if err := req.validate(); err != nil {
 			return nil, err
 		}
 
-		saved, err := svc.CreateChannel(req.key, req.channel)
+		saved, err := svc.CreateChannel(ctx, req.key, req.channel)
 		if err != nil {
 			return nil, err
 		}
@@ -115,7 +448,7 @@ func createChannelEndpoint(svc things.Service) endpoint.Endpoint {
 }
 
 func updateChannelEndpoint(svc things.Service) endpoint.Endpoint {
-	return func(_ context.Context, request interface{}) (interface{}, error) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(updateChannelReq)
 
 		if err := req.validate(); err != nil {
@@ -124,7 +457,32 @@ func updateChannelEndpoint(svc things.Service) endpoint.Endpoint {
 
 		req.channel.ID = req.id
 
-		if err := svc.UpdateChannel(req.key, req.channel); err != nil {
+		if err := svc.UpdateChannel(ctx, req.key, req.channel); err != nil {
+			return nil, err
+		}
+
+		return channelRes{id: req.id, created: false}, nil
+	}
+}
+
+func patchChannelEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(patchChannelReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		channel, err := svc.ViewChannel(ctx, req.key, req.id)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := req.patch.apply(&channel); err != nil {
+			return nil, err
+		}
+
+		if err := svc.UpdateChannel(ctx, req.key, channel); err != nil {
 			return nil, err
 		}
 
@@ -133,41 +491,165 @@ func updateChannelEndpoint(svc things.Service) endpoint.Endpoint {
 }
 
 func viewChannelEndpoint(svc things.Service) endpoint.Endpoint {
-	return func(_ context.Context, request interface{}) (interface{}, error) {
-		req := request.(viewResourceReq)
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(viewChannelReq)
 
 		if err := req.validate(); err != nil {
 			return nil, err
 		}
 
-		channel, err := svc.ViewChannel(req.key, req.id)
+		channel, err := svc.ViewChannel(ctx, req.key, req.id)
 		if err != nil {
 			return nil, err
 		}
 
-		return viewChannelRes{channel}, nil
+		res := viewChannelRes{Channel: withoutSecret(channel)}
+		if !req.connections {
+			return res, nil
+		}
+
+		conns, err := svc.ChannelConnections(ctx, req.key, req.id)
+		if err != nil {
+			return nil, err
+		}
+
+		res.Connections = []channelConnection{}
+		for _, conn := range conns {
+			res.Connections = append(res.Connections, channelConnection{
+				ThingID:     conn.ThingID,
+				Mode:        string(conn.Mode),
+				ConnectedAt: conn.ConnectedAt,
+			})
+		}
+
+		return res, nil
 	}
 }
 
 func listChannelsEndpoint(svc things.Service) endpoint.Endpoint {
-	return func(_ context.Context, request interface{}) (interface{}, error) {
-		req := request.(listResourcesReq)
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listChannelsReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		page, err := svc.ListChannels(ctx, req.key, req.offset, req.limit, req.sorting, req.metadata)
+		if err != nil {
+			return nil, err
+		}
+
+		res := listChannelsRes{
+			Channels: withoutSecrets(page.Channels),
+			Total:    page.Total,
+			Offset:   page.Offset,
+			Limit:    page.Limit,
+			Links:    newPageLinks(req.url, page.Offset, page.Limit, page.Offset+page.Limit >= page.Total),
+		}
+
+		return res, nil
+	}
+}
+
+func countChannelsEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(identityReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		count, err := svc.CountChannels(ctx, req.key)
+		if err != nil {
+			return nil, err
+		}
+
+		return countRes{Count: count}, nil
+	}
+}
+
+func channelConnectionsCountEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(viewResourceReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		count, err := svc.ChannelConnectionsCount(ctx, req.key, req.id)
+		if err != nil {
+			return nil, err
+		}
+
+		return countRes{Count: count}, nil
+	}
+}
+
+func channelOwnerEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(viewResourceReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		owner, err := svc.ChannelOwner(ctx, req.key, req.id)
+		if err != nil {
+			return nil, err
+		}
+
+		return channelOwnerRes{Owner: owner}, nil
+	}
+}
+
+func listChannelsByThingEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listByConnectionReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		channels, err := svc.ListChannelsByThing(ctx, req.key, req.id, req.offset, req.limit)
+		if err != nil {
+			return nil, err
+		}
+
+		res := listChannelsRes{
+			Channels: withoutSecrets(channels),
+			Links:    newPageLinks(req.url, req.offset, req.limit, len(channels) < req.limit),
+		}
+
+		return res, nil
+	}
+}
+
+func listThingsByChannelEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listThingsByChannelReq)
 
 		if err := req.validate(); err != nil {
 			return nil, err
 		}
 
-		channels, err := svc.ListChannels(req.key, req.offset, req.limit)
+		ths, err := svc.ListThingsByChannel(ctx, req.key, req.id, req.offset, req.limit, req.state)
 		if err != nil {
 			return nil, err
 		}
 
-		return listChannelsRes{channels}, nil
+		res := searchThingsRes{
+			Things: ths,
+			Offset: req.offset,
+			Limit:  req.limit,
+			Links:  newPageLinks(req.url, req.offset, req.limit, len(ths) < req.limit),
+		}
+
+		return res, nil
 	}
 }
 
 func removeChannelEndpoint(svc things.Service) endpoint.Endpoint {
-	return func(_ context.Context, request interface{}) (interface{}, error) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(viewResourceReq)
 
 		if err := req.validate(); err != nil {
@@ -177,22 +659,92 @@ func removeChannelEndpoint(svc things.Service) endpoint.Endpoint {
 			return nil, err
 		}
 
-		if err := svc.RemoveChannel(req.key, req.id); err != nil {
+		if err := svc.RemoveChannel(ctx, req.key, req.id); err != nil {
 			return nil, err
 		}
 
 		return removeRes{}, nil
 	}
 }
+
+func restoreChannelEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(viewResourceReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		if err := svc.RestoreChannel(ctx, req.key, req.id); err != nil {
+			return nil, err
+		}
+
+		return channelRes{id: req.id, created: false}, nil
+	}
+}
+
+func transferChannelEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transferReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		if err := svc.TransferChannel(ctx, req.key, req.id, req.Owner); err != nil {
+			return nil, err
+		}
+
+		return channelRes{id: req.id, created: false}, nil
+	}
+}
 func connectEndpoint(svc things.Service) endpoint.Endpoint {
-	return func(_ context.Context, request interface{}) (interface{}, error) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		cr := request.(connectionReq)
 
 		if err := cr.validate(); err != nil {
 			return nil, err
 		}
 
-		if err := svc.Connect(cr.key, cr.chanID, cr.thingID); err != nil {
+		conn, err := svc.Connect(ctx, cr.key, cr.chanID, cr.thingID, cr.mode)
+		if err != nil {
+			return nil, err
+		}
+
+		return connectRes{
+			ChanID:      conn.ChanID,
+			ThingID:     conn.ThingID,
+			Mode:        string(conn.Mode),
+			ConnectedAt: conn.ConnectedAt,
+		}, nil
+	}
+}
+
+func connectManyEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		cr := request.(connectManyReq)
+
+		if err := cr.validate(); err != nil {
+			return nil, err
+		}
+
+		if err := svc.ConnectMany(ctx, cr.key, cr.thingID, cr.chanIDs); err != nil {
+			return nil, err
+		}
+
+		return connectionRes{}, nil
+	}
+}
+
+func connectThingsEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		cr := request.(connectThingsReq)
+
+		if err := cr.validate(); err != nil {
+			return nil, err
+		}
+
+		if err := svc.ConnectThings(ctx, cr.key, cr.chanID, cr.thingIDs); err != nil {
 			return nil, err
 		}
 
@@ -200,18 +752,127 @@ func connectEndpoint(svc things.Service) endpoint.Endpoint {
 	}
 }
 
-func disconnectEndpoint(svc things.Service) endpoint.Endpoint {
-	return func(_ context.Context, request interface{}) (interface{}, error) {
+func setChannelThingsEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		cr := request.(setChannelThingsReq)
+
+		if err := cr.validate(); err != nil {
+			return nil, err
+		}
+
+		if err := svc.SetChannelThings(ctx, cr.key, cr.chanID, cr.thingIDs); err != nil {
+			return nil, err
+		}
+
+		return connectionRes{}, nil
+	}
+}
+
+// disconnectEndpoint reports disconnecting the thing that isn't connected as
+// not found, unless the disconnection is idempotent.
+func disconnectEndpoint(svc things.Service, idempotent bool) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		cr := request.(connectionReq)
 
 		if err := cr.validate(); err != nil {
 			return nil, err
 		}
 
-		if err := svc.Disconnect(cr.key, cr.chanID, cr.thingID); err != nil {
+		removed, err := svc.Disconnect(ctx, cr.key, cr.chanID, cr.thingID)
+		if err != nil {
+			return nil, err
+		}
+
+		if !removed && !idempotent {
+			return nil, things.ErrNotFound
+		}
+
+		res := disconnectRes{
+			ChanID:       cr.chanID,
+			ThingID:      cr.thingID,
+			Disconnected: removed,
+		}
+
+		return res, nil
+	}
+}
+
+func disconnectManyEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		cr := request.(connectManyReq)
+
+		if err := cr.validate(); err != nil {
+			return nil, err
+		}
+
+		if err := svc.DisconnectMany(ctx, cr.key, cr.thingID, cr.chanIDs); err != nil {
 			return nil, err
 		}
 
 		return disconnectionRes{}, nil
 	}
 }
+
+func disconnectAllEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(viewResourceReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		if err := svc.DisconnectAll(ctx, req.key, req.id); err != nil {
+			return nil, err
+		}
+
+		return disconnectionRes{}, nil
+	}
+}
+
+func isConnectedEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		cr := request.(connectionReq)
+
+		if err := cr.validate(); err != nil {
+			return nil, err
+		}
+
+		connected, err := svc.IsConnected(ctx, cr.key, cr.chanID, cr.thingID)
+		if err != nil {
+			return nil, err
+		}
+
+		if !connected {
+			return nil, things.ErrNotFound
+		}
+
+		return connectionStatusRes{Connected: connected}, nil
+	}
+}
+
+func canAccessEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(accessReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		id, err := svc.CanAccess(ctx, req.key, req.chanID, req.mode)
+		if err != nil {
+			return nil, err
+		}
+
+		return identityRes{id: id}, nil
+	}
+}
+
+func healthEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, _ interface{}) (interface{}, error) {
+		if err := svc.Health(ctx); err != nil {
+			return healthRes{Status: healthFail}, nil
+		}
+
+		return healthRes{Status: healthPass}, nil
+	}
+}
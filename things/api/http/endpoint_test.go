@@ -1,7 +1,13 @@
 package http_test
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -9,9 +15,13 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/mainflux/mainflux"
+	"github.com/mainflux/mainflux/logger"
 	"github.com/mainflux/mainflux/things"
 	httpapi "github.com/mainflux/mainflux/things/api/http"
+	"github.com/mainflux/mainflux/things/cache"
 	"github.com/mainflux/mainflux/things/mocks"
 	"github.com/stretchr/testify/assert"
 )
@@ -22,6 +32,7 @@ const (
 	token       = "token"
 	invalid     = "invalid_value"
 	wrongID     = "123e4567-e89b-12d3-a456-000000000042"
+	origin      = "https://console.example.com"
 )
 
 var (
@@ -29,13 +40,35 @@ var (
 	channel = things.Channel{Name: "test"}
 )
 
+type thingsPageRes struct {
+	Things []things.Thing    `json:"things"`
+	Total  int               `json:"total"`
+	Offset int               `json:"offset"`
+	Limit  int               `json:"limit"`
+	Links  map[string]string `json:"links"`
+}
+
+type channelsPageRes struct {
+	Channels []things.Channel  `json:"channels"`
+	Total    int               `json:"total"`
+	Offset   int               `json:"offset"`
+	Limit    int               `json:"limit"`
+	Links    map[string]string `json:"links"`
+}
+
 type testRequest struct {
-	client      *http.Client
-	method      string
-	url         string
-	contentType string
-	token       string
-	body        io.Reader
+	client         *http.Client
+	method         string
+	url            string
+	contentType    string
+	accept         string
+	token          string
+	requestID      string
+	ifMatch        string
+	ifNoneMatch    string
+	channelID      string
+	idempotencyKey string
+	body           io.Reader
 }
 
 func (tr testRequest) make() (*http.Response, error) {
@@ -49,6 +82,24 @@ func (tr testRequest) make() (*http.Response, error) {
 	if tr.contentType != "" {
 		req.Header.Set("Content-Type", tr.contentType)
 	}
+	if tr.accept != "" {
+		req.Header.Set("Accept", tr.accept)
+	}
+	if tr.requestID != "" {
+		req.Header.Set("X-Request-ID", tr.requestID)
+	}
+	if tr.ifMatch != "" {
+		req.Header.Set("If-Match", tr.ifMatch)
+	}
+	if tr.ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", tr.ifNoneMatch)
+	}
+	if tr.channelID != "" {
+		req.Header.Set("X-Channel-ID", tr.channelID)
+	}
+	if tr.idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", tr.idempotencyKey)
+	}
 	return tr.client.Do(req)
 }
 
@@ -61,7 +112,7 @@ func newService(tokens map[string]string) things.Service {
 }
 
 func newServer(svc things.Service) *httptest.Server {
-	mux := httpapi.MakeHandler(svc)
+	mux := httpapi.MakeHandler(svc, mocks.NewIdentityProvider(), []string{origin})
 	return httptest.NewServer(mux)
 }
 
@@ -70,13 +121,29 @@ func toJSON(data interface{}) string {
 	return string(jsonData)
 }
 
+type errorRes struct {
+	Err  string `json:"error"`
+	Code string `json:"code"`
+}
+
+func errorJSON(err error, code string) string {
+	return toJSON(errorRes{Err: err.Error(), Code: code})
+}
+
 func TestAddThing(t *testing.T) {
 	id := "123e4567-e89b-12d3-a456-000000000001"
+	charsetID := "123e4567-e89b-12d3-a456-000000000003"
+	suppliedID := "123e4567-e89b-12d3-a456-426614174000"
 	svc := newService(map[string]string{token: email})
 	ts := newServer(svc)
 	defer ts.Close()
 
 	data := toJSON(thing)
+	th := thing
+	th.ID = suppliedID
+	suppliedData := toJSON(th)
+	th.ID = invalid
+	invalidIDData := toJSON(th)
 	invalidData := toJSON(things.Thing{
 		Type:    "foo",
 		Name:    "invalid_thing",
@@ -92,12 +159,19 @@ func TestAddThing(t *testing.T) {
 		location    string
 	}{
 		{"add valid thing", data, contentType, token, http.StatusCreated, fmt.Sprintf("/things/%s", id)},
-		{"add thing with invalid data", invalidData, contentType, token, http.StatusBadRequest, ""},
+		{"add thing with invalid data", invalidData, contentType, token, http.StatusUnprocessableEntity, ""},
 		{"add thing with invalid auth token", data, contentType, invalid, http.StatusForbidden, ""},
 		{"add thing with invalid request format", "}", contentType, token, http.StatusBadRequest, ""},
-		{"add thing with empty JSON request", "{}", contentType, token, http.StatusBadRequest, ""},
+		{"add thing with empty JSON request", "{}", contentType, token, http.StatusUnprocessableEntity, ""},
+		{"add thing with empty name", toJSON(things.Thing{Type: "app"}), contentType, token, http.StatusUnprocessableEntity, ""},
+		{"add thing with too long name", toJSON(things.Thing{Type: "app", Name: strings.Repeat("a", things.MaxNameLength+1)}), contentType, token, http.StatusUnprocessableEntity, ""},
 		{"add thing with empty request", "", contentType, token, http.StatusBadRequest, ""},
 		{"add thing with missing content type", data, "", token, http.StatusUnsupportedMediaType, ""},
+		{"add thing with invalid content type", data, "text/plain", token, http.StatusUnsupportedMediaType, ""},
+		{"add thing with charset in content type", data, "application/json; charset=utf-8", token, http.StatusCreated, fmt.Sprintf("/things/%s", charsetID)},
+		{"add thing with supplied ID", suppliedData, contentType, token, http.StatusCreated, fmt.Sprintf("/things/%s", suppliedID)},
+		{"add thing with existing ID", suppliedData, contentType, token, http.StatusConflict, ""},
+		{"add thing with invalid ID", invalidIDData, contentType, token, http.StatusUnprocessableEntity, ""},
 	}
 
 	for _, tc := range cases {
@@ -118,204 +192,214 @@ func TestAddThing(t *testing.T) {
 	}
 }
 
-func TestUpdateThing(t *testing.T) {
+func TestAddThingIdempotently(t *testing.T) {
 	svc := newService(map[string]string{token: email})
-	ts := newServer(svc)
+	ts := httptest.NewServer(httpapi.MakeHandler(svc, mocks.NewIdentityProvider(), []string{origin}, httpapi.WithIdempotency(cache.NewIdempotencyStore(), time.Minute)))
 	defer ts.Close()
 
 	data := toJSON(thing)
-	invalidData := toJSON(things.Thing{
-		Type:    "foo",
-		Name:    thing.Name,
-		Payload: thing.Payload,
-	})
-	sth, _ := svc.AddThing(token, thing)
+	other := toJSON(things.Thing{Type: "device", Name: "other"})
+	location := fmt.Sprintf("/things/%s", "123e4567-e89b-12d3-a456-000000000001")
 
 	cases := []struct {
-		desc        string
-		req         string
-		id          string
-		contentType string
-		auth        string
-		status      int
+		desc     string
+		auth     string
+		key      string
+		req      string
+		status   int
+		location string
 	}{
-		{"update existing thing", data, sth.ID, contentType, token, http.StatusOK},
-		{"update non-existent thing", data, wrongID, contentType, token, http.StatusNotFound},
-		{"update thing with invalid id", data, invalid, contentType, token, http.StatusNotFound},
-		{"update thing with invalid data", invalidData, sth.ID, contentType, token, http.StatusBadRequest},
-		{"update thing with invalid user token", data, sth.ID, contentType, invalid, http.StatusForbidden},
-		{"update thing with invalid data format", "{", sth.ID, contentType, token, http.StatusBadRequest},
-		{"update thing with empty JSON request", "{}", sth.ID, contentType, token, http.StatusBadRequest},
-		{"update thing with empty request", "", sth.ID, contentType, token, http.StatusBadRequest},
-		{"update thing with missing content type", data, sth.ID, "", token, http.StatusUnsupportedMediaType},
+		{"add thing with idempotency key", token, "key", data, http.StatusCreated, location},
+		{"retry adding thing with idempotency key", token, "key", data, http.StatusCreated, location},
+		{"retry adding thing with idempotency key and invalid auth token", invalid, "key", data, http.StatusForbidden, ""},
+		{"reuse idempotency key for different thing", token, "key", other, http.StatusUnprocessableEntity, ""},
 	}
 
 	for _, tc := range cases {
 		req := testRequest{
-			client:      ts.Client(),
-			method:      http.MethodPut,
-			url:         fmt.Sprintf("%s/things/%s", ts.URL, tc.id),
-			contentType: tc.contentType,
-			token:       tc.auth,
-			body:        strings.NewReader(tc.req),
+			client:         ts.Client(),
+			method:         http.MethodPost,
+			url:            fmt.Sprintf("%s/things", ts.URL),
+			contentType:    contentType,
+			token:          tc.auth,
+			idempotencyKey: tc.key,
+			body:           strings.NewReader(tc.req),
 		}
 		res, err := req.make()
 		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+
+		location := res.Header.Get("Location")
 		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		assert.Equal(t, tc.location, location, fmt.Sprintf("%s: expected location %s got %s", tc.desc, tc.location, location))
 	}
+
+	page, err := svc.ListThings(context.Background(), token, 0, 10, things.Sorting{}, things.ThingFilter{})
+	assert.Nil(t, err, fmt.Sprintf("list things: unexpected error %s", err))
+	assert.Equal(t, 1, page.Total, fmt.Sprintf("list things: expected total %d got %d", 1, page.Total))
 }
 
-func TestViewThing(t *testing.T) {
-	svc := newService(map[string]string{token: email})
+func TestAddThingOverQuota(t *testing.T) {
+	users := mocks.NewUsersService(map[string]string{token: email})
+	thingsRepo := mocks.NewThingRepository()
+	channelsRepo := mocks.NewChannelRepository(thingsRepo)
+	idp := mocks.NewIdentityProvider()
+	svc := things.New(users, thingsRepo, channelsRepo, idp, things.WithQuota(things.NewStaticQuota(1, 1)))
 	ts := newServer(svc)
 	defer ts.Close()
 
-	sth, _ := svc.AddThing(token, thing)
-	data := toJSON(sth)
-
 	cases := []struct {
 		desc   string
-		id     string
-		auth   string
+		url    string
+		req    string
 		status int
-		res    string
 	}{
-		{"view existing thing", sth.ID, token, http.StatusOK, data},
-		{"view non-existent thing", wrongID, token, http.StatusNotFound, ""},
-		{"view thing by passing invalid id", invalid, token, http.StatusNotFound, ""},
-		{"view thing by passing invalid token", sth.ID, invalid, http.StatusForbidden, ""},
+		{"add thing within quota", "/things", toJSON(thing), http.StatusCreated},
+		{"add thing over quota", "/things", toJSON(thing), http.StatusTooManyRequests},
+		{"create channel within quota", "/channels", toJSON(channel), http.StatusCreated},
+		{"create channel over quota", "/channels", toJSON(channel), http.StatusTooManyRequests},
 	}
 
 	for _, tc := range cases {
 		req := testRequest{
-			client: ts.Client(),
-			method: http.MethodGet,
-			url:    fmt.Sprintf("%s/things/%s", ts.URL, tc.id),
-			token:  tc.auth,
+			client:      ts.Client(),
+			method:      http.MethodPost,
+			url:         fmt.Sprintf("%s%s", ts.URL, tc.url),
+			contentType: contentType,
+			token:       token,
+			body:        strings.NewReader(tc.req),
 		}
 		res, err := req.make()
 		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
-		body, err := ioutil.ReadAll(res.Body)
-		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
-		data := strings.Trim(string(body), "\n")
 		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
-		assert.Equal(t, tc.res, data, fmt.Sprintf("%s: expected body %s got %s", tc.desc, tc.res, data))
 	}
 }
 
-func TestListThings(t *testing.T) {
+func TestIdentifyThing(t *testing.T) {
 	svc := newService(map[string]string{token: email})
 	ts := newServer(svc)
 	defer ts.Close()
 
-	data := []things.Thing{}
-	for i := 0; i < 101; i++ {
-		sth, _ := svc.AddThing(token, thing)
-		// must be "nulled" due to the JSON serialization that ignores owner
-		sth.Owner = ""
-		data = append(data, sth)
-	}
-	thingURL := fmt.Sprintf("%s/things", ts.URL)
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	dth, _ := svc.AddThing(context.Background(), token, thing)
+	svc.DisableThing(context.Background(), token, dth.ID)
+
+	data := fmt.Sprintf(`{"id":"%s","owner":"%s"}`, sth.ID, email)
+	unauthenticated := errorJSON(errors.New("invalid thing key"), "unauthenticated")
+
 	cases := []struct {
-		desc   string
-		auth   string
-		status int
-		url    string
-		res    []things.Thing
+		desc        string
+		req         string
+		contentType string
+		status      int
+		res         string
 	}{
-		{"get a list of things", token, http.StatusOK, fmt.Sprintf("%s?offset=%d&limit=%d", thingURL, 0, 5), data[0:5]},
-		{"get a list of things with invalid token", invalid, http.StatusForbidden, fmt.Sprintf("%s?offset=%d&limit=%d", thingURL, 0, 1), nil},
-		{"get a list of things with invalid offset", token, http.StatusBadRequest, fmt.Sprintf("%s?offset=%d&limit=%d", thingURL, -1, 5), nil},
-		{"get a list of things with invalid limit", token, http.StatusBadRequest, fmt.Sprintf("%s?offset=%d&limit=%d", thingURL, 1, -5), nil},
-		{"get a list of things with zero limit", token, http.StatusBadRequest, fmt.Sprintf("%s?offset=%d&limit=%d", thingURL, 1, 0), nil},
-		{"get a list of things with no offset provided", token, http.StatusOK, fmt.Sprintf("%s?limit=%d", thingURL, 5), data[0:5]},
-		{"get a list of things with no limit provided", token, http.StatusOK, fmt.Sprintf("%s?offset=%d", thingURL, 1), data[1:11]},
-		{"get a list of things with redundant query params", token, http.StatusOK, fmt.Sprintf("%s?offset=%d&limit=%d&value=something", thingURL, 0, 5), data[0:5]},
-		{"get a list of things with limit greater than max", token, http.StatusBadRequest, fmt.Sprintf("%s?offset=%d&limit=%d", thingURL, 0, 110), nil},
-		{"get a list of things with default URL", token, http.StatusOK, fmt.Sprintf("%s%s", thingURL, ""), data[0:10]},
-		{"get a list of things with invalid URL", token, http.StatusBadRequest, fmt.Sprintf("%s%s", thingURL, "?%%"), nil},
-		{"get a list of things with invalid number of params", token, http.StatusBadRequest, fmt.Sprintf("%s%s", thingURL, "?offset=4&limit=4&limit=5&offset=5"), nil},
-		{"get a list of things with invalid offset", token, http.StatusBadRequest, fmt.Sprintf("%s%s", thingURL, "?offset=e&limit=5"), nil},
-		{"get a list of things with invalid limit", token, http.StatusBadRequest, fmt.Sprintf("%s%s", thingURL, "?offset=5&limit=e"), nil},
+		{"identify thing with valid key", fmt.Sprintf(`{"key":"%s"}`, sth.Key), contentType, http.StatusOK, data},
+		{"identify thing with invalid key", fmt.Sprintf(`{"key":"%s"}`, invalid), contentType, http.StatusUnauthorized, unauthenticated},
+		{"identify thing with empty key", `{"key":""}`, contentType, http.StatusUnauthorized, unauthenticated},
+		{"identify disabled thing", fmt.Sprintf(`{"key":"%s"}`, dth.Key), contentType, http.StatusUnauthorized, unauthenticated},
+		{"identify thing with invalid request format", "}", contentType, http.StatusBadRequest, ""},
+		{"identify thing with invalid content type", fmt.Sprintf(`{"key":"%s"}`, sth.Key), "text/plain", http.StatusUnsupportedMediaType, ""},
 	}
 
 	for _, tc := range cases {
 		req := testRequest{
-			client: ts.Client(),
-			method: http.MethodGet,
-			url:    tc.url,
-			token:  tc.auth,
+			client:      ts.Client(),
+			method:      http.MethodPost,
+			url:         fmt.Sprintf("%s/things/identify", ts.URL),
+			contentType: tc.contentType,
+			body:        strings.NewReader(tc.req),
 		}
 		res, err := req.make()
 		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
-		var data map[string][]things.Thing
-		json.NewDecoder(res.Body).Decode(&data)
 		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
-		assert.ElementsMatch(t, tc.res, data["things"], fmt.Sprintf("%s: expected body %s got %s", tc.desc, tc.res, data["things"]))
+		if tc.res == "" {
+			continue
+		}
+
+		data, err := ioutil.ReadAll(res.Body)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		body := strings.Trim(string(data), "\n")
+		assert.Equal(t, tc.res, body, fmt.Sprintf("%s: expected body %v got %v", tc.desc, tc.res, body))
 	}
 }
 
-func TestRemoveThing(t *testing.T) {
+func TestAddThingWithExternalID(t *testing.T) {
 	svc := newService(map[string]string{token: email})
 	ts := newServer(svc)
 	defer ts.Close()
 
-	sth, _ := svc.AddThing(token, thing)
+	th := thing
+	th.ExternalID = "sensor-1"
+	sth, _ := svc.AddThing(context.Background(), token, th)
+
+	other := thing
+	other.ExternalID = "sensor-2"
+	id := "123e4567-e89b-12d3-a456-000000000003"
 
 	cases := []struct {
-		desc   string
-		id     string
-		auth   string
-		status int
+		desc     string
+		req      string
+		status   int
+		location string
 	}{
-		{"delete existing thing", sth.ID, token, http.StatusNoContent},
-		{"delete non-existent thing", wrongID, token, http.StatusNoContent},
-		{"delete thing with invalid id", invalid, token, http.StatusNoContent},
-		{"delete thing with invalid token", sth.ID, invalid, http.StatusForbidden},
+		{"add thing with existing external ID", toJSON(th), http.StatusOK, fmt.Sprintf("/things/%s", sth.ID)},
+		{"add thing with new external ID", toJSON(other), http.StatusCreated, fmt.Sprintf("/things/%s", id)},
 	}
 
 	for _, tc := range cases {
 		req := testRequest{
-			client: ts.Client(),
-			method: http.MethodDelete,
-			url:    fmt.Sprintf("%s/things/%s", ts.URL, tc.id),
-			token:  tc.auth,
+			client:      ts.Client(),
+			method:      http.MethodPost,
+			url:         fmt.Sprintf("%s/things", ts.URL),
+			contentType: contentType,
+			token:       token,
+			body:        strings.NewReader(tc.req),
 		}
 		res, err := req.make()
 		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+
+		location := res.Header.Get("Location")
 		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		assert.Equal(t, tc.location, location, fmt.Sprintf("%s: expected location %s got %s", tc.desc, tc.location, location))
 	}
 }
 
-func TestCreateChannel(t *testing.T) {
-	id := "123e4567-e89b-12d3-a456-000000000001"
+func TestCreateThings(t *testing.T) {
 	svc := newService(map[string]string{token: email})
 	ts := newServer(svc)
 	defer ts.Close()
 
-	data := toJSON(channel)
+	data := toJSON([]things.Thing{thing, thing})
+	invalidData := toJSON([]things.Thing{thing, {Type: "foo", Name: "invalid_thing"}})
 
 	cases := []struct {
 		desc        string
 		req         string
+		query       string
 		contentType string
 		auth        string
 		status      int
-		location    string
+		size        int
 	}{
-		{"create new channel", data, contentType, token, http.StatusCreated, fmt.Sprintf("/channels/%s", id)},
-		{"create new channel with invalid token", data, contentType, invalid, http.StatusForbidden, ""},
-		{"create new channel with invalid data format", "{", contentType, token, http.StatusBadRequest, ""},
-		{"create new channel with empty JSON request", "{}", contentType, token, http.StatusCreated, "/channels/123e4567-e89b-12d3-a456-000000000002"},
-		{"create new channel with empty request", "", contentType, token, http.StatusBadRequest, ""},
-		{"create new channel with missing content type", data, "", token, http.StatusUnsupportedMediaType, ""},
+		{"dry run valid things", data, "?dry_run=true", contentType, token, http.StatusOK, 2},
+		{"dry run things with invalid thing", invalidData, "?dry_run=true", contentType, token, http.StatusUnprocessableEntity, 0},
+		{"dry run things with invalid flag", data, "?dry_run=maybe", contentType, token, http.StatusBadRequest, 0},
+		{"dry run things with repeated flag", data, "?dry_run=true&dry_run=false", contentType, token, http.StatusBadRequest, 0},
+		{"create valid things without dry run", data, "?dry_run=false", contentType, token, http.StatusCreated, 2},
+		{"create valid things", data, "", contentType, token, http.StatusCreated, 2},
+		{"create things with invalid thing", invalidData, "", contentType, token, http.StatusUnprocessableEntity, 0},
+		{"create things with invalid auth token", data, "", contentType, invalid, http.StatusForbidden, 0},
+		{"create things with empty list", "[]", "", contentType, token, http.StatusUnprocessableEntity, 0},
+		{"create things with invalid request format", "[", "", contentType, token, http.StatusBadRequest, 0},
+		{"create things with single thing instead of list", toJSON(thing), "", contentType, token, http.StatusBadRequest, 0},
+		{"create things with missing content type", data, "", "", token, http.StatusUnsupportedMediaType, 0},
 	}
 
 	for _, tc := range cases {
 		req := testRequest{
 			client:      ts.Client(),
 			method:      http.MethodPost,
-			url:         fmt.Sprintf("%s/channels", ts.URL),
+			url:         fmt.Sprintf("%s/things/bulk%s", ts.URL, tc.query),
 			contentType: tc.contentType,
 			token:       tc.auth,
 			body:        strings.NewReader(tc.req),
@@ -323,45 +407,49 @@ func TestCreateChannel(t *testing.T) {
 		res, err := req.make()
 		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
 
-		location := res.Header.Get("Location")
+		var body map[string][]things.Thing
+		json.NewDecoder(res.Body).Decode(&body)
+		size := len(body["things"])
 		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
-		assert.Equal(t, tc.location, location, fmt.Sprintf("%s: expected location %s got %s", tc.desc, tc.location, location))
+		assert.Equal(t, tc.size, size, fmt.Sprintf("%s: expected %d things got %d", tc.desc, tc.size, size))
 	}
+
+	page, _ := svc.ListThings(context.Background(), token, 0, 10, things.Sorting{}, things.ThingFilter{})
+	assert.Equal(t, 4, page.Total, fmt.Sprintf("expected %d saved things got %d", 4, page.Total))
 }
 
-func TestUpdateChannel(t *testing.T) {
+func TestErrorResponse(t *testing.T) {
 	svc := newService(map[string]string{token: email})
 	ts := newServer(svc)
 	defer ts.Close()
 
-	updateData := toJSON(map[string]string{
-		"name": "updated_channel",
-	})
-	sch, _ := svc.CreateChannel(token, channel)
+	th := thing
+	th.ID = "123e4567-e89b-12d3-a456-426614174000"
+	data := toJSON(th)
+	svc.AddThing(context.Background(), token, th)
 
 	cases := []struct {
 		desc        string
 		req         string
-		id          string
 		contentType string
 		auth        string
 		status      int
+		code        string
 	}{
-		{"update existing channel", updateData, sch.ID, contentType, token, http.StatusOK},
-		{"update non-existing channel", updateData, wrongID, contentType, token, http.StatusNotFound},
-		{"update channel with invalid token", updateData, sch.ID, contentType, invalid, http.StatusForbidden},
-		{"update channel with invalid id", updateData, invalid, contentType, token, http.StatusNotFound},
-		{"update channel with invalid data format", "}", sch.ID, contentType, token, http.StatusBadRequest},
-		{"update channel with empty JSON object", "{}", sch.ID, contentType, token, http.StatusOK},
-		{"update channel with empty request", "", sch.ID, contentType, token, http.StatusBadRequest},
-		{"update channel with missing content type", updateData, sch.ID, "", token, http.StatusUnsupportedMediaType},
+		{"add thing with existing ID", data, contentType, token, http.StatusConflict, "conflict"},
+		{"add thing with invalid auth token", data, contentType, invalid, http.StatusForbidden, "unauthorized"},
+		{"add thing with invalid request format", "}", contentType, token, http.StatusBadRequest, "malformed_json"},
+		{"add thing with unterminated JSON request", "{", contentType, token, http.StatusBadRequest, "malformed_json"},
+		{"add thing with empty JSON request", "{}", contentType, token, http.StatusUnprocessableEntity, "malformed_entity"},
+		{"add thing with empty name", `{"type":"app","name":""}`, contentType, token, http.StatusUnprocessableEntity, "malformed_entity"},
+		{"add thing with missing content type", data, "", token, http.StatusUnsupportedMediaType, "unsupported_content_type"},
 	}
 
 	for _, tc := range cases {
 		req := testRequest{
 			client:      ts.Client(),
-			method:      http.MethodPut,
-			url:         fmt.Sprintf("%s/channels/%s", ts.URL, tc.id),
+			method:      http.MethodPost,
+			url:         fmt.Sprintf("%s/things", ts.URL),
 			contentType: tc.contentType,
 			token:       tc.auth,
 			body:        strings.NewReader(tc.req),
@@ -369,171 +457,2886 @@ func TestUpdateChannel(t *testing.T) {
 		res, err := req.make()
 		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
 		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+
+		var body errorRes
+		json.NewDecoder(res.Body).Decode(&body)
+		assert.Equal(t, tc.code, body.Code, fmt.Sprintf("%s: expected error code %s got %s", tc.desc, tc.code, body.Code))
+		assert.NotEmpty(t, body.Err, fmt.Sprintf("%s: expected error message", tc.desc))
 	}
 }
 
-func TestViewChannel(t *testing.T) {
+func TestUpdateThing(t *testing.T) {
 	svc := newService(map[string]string{token: email})
 	ts := newServer(svc)
 	defer ts.Close()
 
-	sch, _ := svc.CreateChannel(token, channel)
-	data := toJSON(sch)
+	data := toJSON(thing)
+	invalidData := toJSON(things.Thing{
+		Type:    "foo",
+		Name:    thing.Name,
+		Payload: thing.Payload,
+	})
+	sth, _ := svc.AddThing(context.Background(), token, thing)
 
 	cases := []struct {
-		desc   string
-		id     string
-		auth   string
-		status int
-		res    string
+		desc        string
+		req         string
+		id          string
+		contentType string
+		auth        string
+		status      int
 	}{
-		{"view existing channel", sch.ID, token, http.StatusOK, data},
-		{"view non-existent channel", wrongID, token, http.StatusNotFound, ""},
-		{"view channel with invalid id", invalid, token, http.StatusNotFound, ""},
-		{"view channel with invalid token", sch.ID, invalid, http.StatusForbidden, ""},
+		{"update existing thing", data, sth.ID, contentType, token, http.StatusOK},
+		{"update non-existent thing", data, wrongID, contentType, token, http.StatusNotFound},
+		{"update thing with invalid id", data, invalid, contentType, token, http.StatusNotFound},
+		{"update thing with invalid data", invalidData, sth.ID, contentType, token, http.StatusUnprocessableEntity},
+		{"update thing with invalid user token", data, sth.ID, contentType, invalid, http.StatusForbidden},
+		{"update thing with invalid data format", "{", sth.ID, contentType, token, http.StatusBadRequest},
+		{"update thing with empty JSON request", "{}", sth.ID, contentType, token, http.StatusUnprocessableEntity},
+		{"update thing with empty request", "", sth.ID, contentType, token, http.StatusBadRequest},
+		{"update thing with missing content type", data, sth.ID, "", token, http.StatusUnsupportedMediaType},
+		{"update thing with invalid content type", data, sth.ID, "text/plain", token, http.StatusUnsupportedMediaType},
+		{"update thing with charset in content type", data, sth.ID, "application/json; charset=utf-8", token, http.StatusOK},
 	}
 
 	for _, tc := range cases {
 		req := testRequest{
-			client: ts.Client(),
-			method: http.MethodGet,
-			url:    fmt.Sprintf("%s/channels/%s", ts.URL, tc.id),
-			token:  tc.auth,
+			client:      ts.Client(),
+			method:      http.MethodPut,
+			url:         fmt.Sprintf("%s/things/%s", ts.URL, tc.id),
+			contentType: tc.contentType,
+			token:       tc.auth,
+			ifMatch:     "*",
+			body:        strings.NewReader(tc.req),
 		}
 		res, err := req.make()
 		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
-		data, err := ioutil.ReadAll(res.Body)
-		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
-		body := strings.Trim(string(data), "\n")
 		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
-		assert.Equal(t, tc.res, body, fmt.Sprintf("%s: expected body %s got %s", tc.desc, tc.res, body))
 	}
 }
 
-func TestListChannels(t *testing.T) {
+func TestUpdateThingIfMatch(t *testing.T) {
 	svc := newService(map[string]string{token: email})
 	ts := newServer(svc)
 	defer ts.Close()
 
-	channels := []things.Channel{}
-	for i := 0; i < 101; i++ {
-		sch, _ := svc.CreateChannel(token, channel)
-		// must be "nulled" due to the JSON serialization that ignores owner
-		sch.Owner = ""
-		channels = append(channels, sch)
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	view := testRequest{
+		client: ts.Client(),
+		method: http.MethodGet,
+		url:    fmt.Sprintf("%s/things/%s", ts.URL, sth.ID),
+		token:  token,
 	}
-	channelURL := fmt.Sprintf("%s/channels", ts.URL)
+	res, err := view.make()
+	assert.Nil(t, err, fmt.Sprintf("view thing: unexpected error %s", err))
+	etag := res.Header.Get("ETag")
 
 	cases := []struct {
-		desc   string
-		auth   string
-		status int
-		url    string
-		res    []things.Channel
+		desc    string
+		ifMatch string
+		status  int
 	}{
-		{"get a list of channels", token, http.StatusOK, fmt.Sprintf("%s?offset=%d&limit=%d", channelURL, 0, 6), channels[0:6]},
-		{"get a list of channels with invalid token", invalid, http.StatusForbidden, fmt.Sprintf("%s?offset=%d&limit=%d", channelURL, 0, 1), nil},
-		{"get a list of channels with invalid offset", token, http.StatusBadRequest, fmt.Sprintf("%s?offset=%d&limit=%d", channelURL, -1, 5), nil},
-		{"get a list of channels with invalid limit", token, http.StatusBadRequest, fmt.Sprintf("%s?offset=%d&limit=%d", channelURL, -1, 5), nil},
-		{"get a list of channels with zero limit", token, http.StatusBadRequest, fmt.Sprintf("%s?offset=%d&limit=%d", channelURL, 1, 0), nil},
-		{"get a list of channels with no offset provided", token, http.StatusOK, fmt.Sprintf("%s?limit=%d", channelURL, 5), channels[0:5]},
-		{"get a list of channels with no limit provided", token, http.StatusOK, fmt.Sprintf("%s?offset=%d", channelURL, 1), channels[1:11]},
-		{"get a list of channels with redundant query params", token, http.StatusOK, fmt.Sprintf("%s?offset=%d&limit=%d&value=something", channelURL, 0, 5), channels[0:5]},
-		{"get a list of channels with limit greater than max", token, http.StatusBadRequest, fmt.Sprintf("%s?offset=%d&limit=%d", channelURL, 0, 110), nil},
-		{"get a list of channels with default URL", token, http.StatusOK, fmt.Sprintf("%s%s", channelURL, ""), channels[0:10]},
-		{"get a list of channels with invalid URL", token, http.StatusBadRequest, fmt.Sprintf("%s%s", channelURL, "?%%"), nil},
-		{"get a list of channels with invalid number of params", token, http.StatusBadRequest, fmt.Sprintf("%s%s", channelURL, "?offset=4&limit=4&limit=5&offset=5"), nil},
-		{"get a list of channels with invalid offset", token, http.StatusBadRequest, fmt.Sprintf("%s%s", channelURL, "?offset=e&limit=5"), nil},
-		{"get a list of channels with invalid limit", token, http.StatusBadRequest, fmt.Sprintf("%s%s", channelURL, "?offset=5&limit=e"), nil},
+		{"update thing without If-Match header", "", http.StatusPreconditionRequired},
+		{"update thing with malformed ETag", "stale", http.StatusPreconditionFailed},
+		{"update thing with weak ETag", fmt.Sprintf("W/%s", etag), http.StatusPreconditionFailed},
+		{"update thing with current ETag", etag, http.StatusOK},
+		{"update thing with stale ETag", etag, http.StatusPreconditionFailed},
+		{"update thing with wildcard ETag", "*", http.StatusOK},
 	}
 
 	for _, tc := range cases {
 		req := testRequest{
-			client: ts.Client(),
-			method: http.MethodGet,
-			url:    tc.url,
-			token:  tc.auth,
+			client:      ts.Client(),
+			method:      http.MethodPut,
+			url:         fmt.Sprintf("%s/things/%s", ts.URL, sth.ID),
+			contentType: contentType,
+			token:       token,
+			ifMatch:     tc.ifMatch,
+			body:        strings.NewReader(toJSON(thing)),
 		}
 		res, err := req.make()
 		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
-		var body map[string][]things.Channel
-		json.NewDecoder(res.Body).Decode(&body)
 		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
-		assert.ElementsMatch(t, tc.res, body["channels"], fmt.Sprintf("%s: expected body %s got %s", tc.desc, tc.res, body["channels"]))
 	}
 }
 
-func TestRemoveChannel(t *testing.T) {
+func TestPatchThing(t *testing.T) {
 	svc := newService(map[string]string{token: email})
 	ts := newServer(svc)
 	defer ts.Close()
 
-	sch, _ := svc.CreateChannel(token, channel)
+	th := thing
+	th.Metadata = map[string]interface{}{"firmware": "1.0"}
+	sth, _ := svc.AddThing(context.Background(), token, th)
+
+	data := toJSON(map[string]string{"name": "patched_app"})
 
 	cases := []struct {
-		desc   string
-		id     string
-		auth   string
-		status int
+		desc        string
+		req         string
+		id          string
+		contentType string
+		auth        string
+		status      int
 	}{
-		{"remove existing channel", sch.ID, token, http.StatusNoContent},
-		{"remove non-existent channel", sch.ID, token, http.StatusNoContent},
-		{"remove channel with invalid id", wrongID, token, http.StatusNoContent},
-		{"remove channel with invalid token", sch.ID, invalid, http.StatusForbidden},
+		{"patch existing thing", data, sth.ID, contentType, token, http.StatusOK},
+		{"patch non-existent thing", data, wrongID, contentType, token, http.StatusNotFound},
+		{"patch thing with invalid id", data, invalid, contentType, token, http.StatusNotFound},
+		{"patch thing with invalid user token", data, sth.ID, contentType, invalid, http.StatusForbidden},
+		{"patch thing with empty name", `{"name":""}`, sth.ID, contentType, token, http.StatusUnprocessableEntity},
+		{"patch thing with invalid data format", "{", sth.ID, contentType, token, http.StatusBadRequest},
+		{"patch thing with empty JSON request", "{}", sth.ID, contentType, token, http.StatusUnprocessableEntity},
+		{"patch thing with missing content type", data, sth.ID, "", token, http.StatusUnsupportedMediaType},
 	}
 
 	for _, tc := range cases {
 		req := testRequest{
-			client: ts.Client(),
-			method: http.MethodDelete,
-			url:    fmt.Sprintf("%s/channels/%s", ts.URL, tc.id),
-			token:  tc.auth,
+			client:      ts.Client(),
+			method:      http.MethodPatch,
+			url:         fmt.Sprintf("%s/things/%s", ts.URL, tc.id),
+			contentType: tc.contentType,
+			token:       tc.auth,
+			body:        strings.NewReader(tc.req),
 		}
 		res, err := req.make()
 		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
 		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
 	}
+
+	patched, _ := svc.ViewThing(context.Background(), token, sth.ID)
+	assert.Equal(t, "patched_app", patched.Name, fmt.Sprintf("patch thing name: expected %s got %s", "patched_app", patched.Name))
+	assert.Equal(t, sth.Key, patched.Key, fmt.Sprintf("patch thing name: expected key %s got %s", sth.Key, patched.Key))
+	assert.Equal(t, sth.Payload, patched.Payload, fmt.Sprintf("patch thing name: expected payload %s got %s", sth.Payload, patched.Payload))
+	assert.Equal(t, sth.Metadata, patched.Metadata, fmt.Sprintf("patch thing name: expected metadata %v got %v", sth.Metadata, patched.Metadata))
 }
 
-func TestConnect(t *testing.T) {
-	otherToken := "other_token"
-	otherEmail := "other_user@example.com"
-	svc := newService(map[string]string{
-		token:      email,
-		otherToken: otherEmail,
-	})
+func TestUpdateKey(t *testing.T) {
+	svc := newService(map[string]string{token: email})
 	ts := newServer(svc)
 	defer ts.Close()
 
-	ath, _ := svc.AddThing(token, thing)
-	ach, _ := svc.CreateChannel(token, channel)
-	bch, _ := svc.CreateChannel(otherToken, channel)
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	other, _ := svc.AddThing(context.Background(), token, thing)
+
+	newKey := "9c3f1b2e-7d4a-4c1e-8f6b-0a5d3e2c1b7f"
+	data := toJSON(things.Thing{Key: newKey})
+	conflictData := toJSON(things.Thing{Key: other.Key})
+	shortData := toJSON(things.Thing{Key: "new-key"})
+	longData := toJSON(things.Thing{Key: newKey + "0"})
 
 	cases := []struct {
-		desc    string
-		chanID  string
-		thingID string
-		auth    string
-		status  int
+		desc        string
+		req         string
+		id          string
+		contentType string
+		auth        string
+		status      int
+	}{
+		{"update key of existing thing", data, sth.ID, contentType, token, http.StatusOK},
+		{"update key of non-existent thing", data, wrongID, contentType, token, http.StatusNotFound},
+		{"update key of thing with invalid id", data, invalid, contentType, token, http.StatusNotFound},
+		{"update key to the one already in use", conflictData, sth.ID, contentType, token, http.StatusConflict},
+		{"update key with invalid user token", data, sth.ID, contentType, invalid, http.StatusForbidden},
+		{"update key with invalid data format", "{", sth.ID, contentType, token, http.StatusBadRequest},
+		{"update key with empty JSON request", "{}", sth.ID, contentType, token, http.StatusUnprocessableEntity},
+		{"update key with too short key", shortData, sth.ID, contentType, token, http.StatusUnprocessableEntity},
+		{"update key with too long key", longData, sth.ID, contentType, token, http.StatusUnprocessableEntity},
+		{"update key with empty request", "", sth.ID, contentType, token, http.StatusBadRequest},
+		{"update key with missing content type", data, sth.ID, "", token, http.StatusUnsupportedMediaType},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client:      ts.Client(),
+			method:      http.MethodPatch,
+			url:         fmt.Sprintf("%s/things/%s/key", ts.URL, tc.id),
+			contentType: tc.contentType,
+			token:       tc.auth,
+			body:        strings.NewReader(tc.req),
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+	}
+}
+
+func TestRotateKey(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+
+	cases := []struct {
+		desc   string
+		id     string
+		auth   string
+		status int
+	}{
+		{"rotate key of non-existent thing", wrongID, token, http.StatusNotFound},
+		{"rotate key of thing with invalid id", invalid, token, http.StatusNotFound},
+		{"rotate key with invalid user token", sth.ID, invalid, http.StatusForbidden},
+		{"rotate key with empty user token", sth.ID, "", http.StatusForbidden},
+		{"rotate key of existing thing", sth.ID, token, http.StatusOK},
+	}
+
+	var body struct {
+		Key string `json:"key"`
+	}
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodPost,
+			url:    fmt.Sprintf("%s/things/%s/key/rotate", ts.URL, tc.id),
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		json.NewDecoder(res.Body).Decode(&body)
+	}
+
+	assert.NotEmpty(t, body.Key, fmt.Sprintf("rotate key: expected non-empty key"))
+	assert.NotEqual(t, sth.Key, body.Key, fmt.Sprintf("rotate key: expected key other than %s", sth.Key))
+
+	id, err := svc.CanAccess(context.Background(), body.Key, sch.ID, things.AccessPubSub)
+	assert.Nil(t, err, fmt.Sprintf("access channel with rotated key: unexpected error %s", err))
+	assert.Equal(t, sth.ID, id, fmt.Sprintf("access channel with rotated key: expected thing %s got %s", sth.ID, id))
+
+	_, err = svc.CanAccess(context.Background(), sth.Key, sch.ID, things.AccessPubSub)
+	assert.Equal(t, things.ErrUnauthorizedAccess, err, fmt.Sprintf("access channel with old key: expected %s got %s", things.ErrUnauthorizedAccess, err))
+}
+
+func TestChangeThingStatus(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+
+	cases := []struct {
+		desc   string
+		action string
+		id     string
+		auth   string
+		status int
+	}{
+		{"disable existing thing", "disable", sth.ID, token, http.StatusOK},
+		{"disable non-existent thing", "disable", wrongID, token, http.StatusNotFound},
+		{"disable thing with invalid id", "disable", invalid, token, http.StatusNotFound},
+		{"disable thing with invalid token", "disable", sth.ID, invalid, http.StatusForbidden},
+		{"enable existing thing", "enable", sth.ID, token, http.StatusOK},
+		{"enable non-existent thing", "enable", wrongID, token, http.StatusNotFound},
+		{"enable thing with invalid id", "enable", invalid, token, http.StatusNotFound},
+		{"enable thing with invalid token", "enable", sth.ID, invalid, http.StatusForbidden},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodPost,
+			url:    fmt.Sprintf("%s/things/%s/%s", ts.URL, tc.id, tc.action),
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+	}
+}
+
+func TestViewThing(t *testing.T) {
+	otherToken := "other_token"
+	svc := newService(map[string]string{
+		token:      email,
+		otherToken: "other_user@example.com",
+	})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	data := toJSON(sth)
+	oth, _ := svc.AddThing(context.Background(), otherToken, thing)
+
+	mth := thing
+	mth.Metadata = map[string]interface{}{"firmware": "1.0", "location": "lab"}
+	smth, _ := svc.AddThing(context.Background(), token, mth)
+	mdata := toJSON(smth)
+
+	cases := []struct {
+		desc   string
+		id     string
+		auth   string
+		status int
+		res    string
+	}{
+		{"view existing thing", sth.ID, token, http.StatusOK, data},
+		{"view existing thing with metadata", smth.ID, token, http.StatusOK, mdata},
+		{"view non-existent thing", wrongID, token, http.StatusNotFound, errorJSON(things.ErrNotFound, "not_found")},
+		{"view thing owned by other user", oth.ID, token, http.StatusNotFound, errorJSON(things.ErrNotFound, "not_found")},
+		{"view thing by passing invalid id", invalid, token, http.StatusNotFound, errorJSON(things.ErrNotFound, "not_found")},
+		{"view thing by passing invalid token", sth.ID, invalid, http.StatusForbidden, errorJSON(things.ErrUnauthorizedAccess, "unauthorized")},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    fmt.Sprintf("%s/things/%s", ts.URL, tc.id),
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		body, err := ioutil.ReadAll(res.Body)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		data := strings.Trim(string(body), "\n")
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		assert.Equal(t, tc.res, data, fmt.Sprintf("%s: expected body %v got %v", tc.desc, tc.res, data))
+	}
+}
+
+func TestViewThingChannels(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	eth, _ := svc.AddThing(context.Background(), token, thing)
+	mth, _ := svc.AddThing(context.Background(), token, thing)
+
+	var chs []string
+	for i := 0; i < 2; i++ {
+		ch := channel
+		ch.Name = fmt.Sprintf("channel-%d", i)
+		sch, _ := svc.CreateChannel(context.Background(), token, ch)
+		svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+		chs = append(chs, fmt.Sprintf("%s:%s", sch.ID, sch.Name))
+	}
+
+	// more channels than fit the largest page
+	var many []string
+	for i := 0; i < 101; i++ {
+		sch, _ := svc.CreateChannel(context.Background(), token, channel)
+		svc.Connect(context.Background(), token, sch.ID, mth.ID, things.AccessPubSub)
+		many = append(many, fmt.Sprintf("%s:%s", sch.ID, sch.Name))
+	}
+
+	cases := []struct {
+		desc     string
+		id       string
+		query    string
+		status   int
+		channels []string
+		etag     bool
+	}{
+		{"view thing with channels", sth.ID, "include=channels", http.StatusOK, chs, false},
+		{"view thing without connected channels", eth.ID, "include=channels", http.StatusOK, []string{}, false},
+		{"view thing with many channels", mth.ID, "include=channels", http.StatusOK, many, false},
+		{"view thing with channels and fields", sth.ID, "include=channels&fields=name", http.StatusOK, chs, false},
+		{"view thing without included channels", sth.ID, "", http.StatusOK, nil, true},
+		{"view thing with invalid include", sth.ID, "include=connections", http.StatusBadRequest, nil, false},
+		{"view thing with duplicated include", sth.ID, "include=channels&include=channels", http.StatusBadRequest, nil, false},
+		{"view non-existent thing with channels", wrongID, "include=channels", http.StatusNotFound, nil, false},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    fmt.Sprintf("%s/things/%s?%s", ts.URL, tc.id, tc.query),
+			token:  token,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		etag := res.Header.Get("ETag")
+		assert.Equal(t, tc.etag, etag != "", fmt.Sprintf("%s: expected ETag presence %t got %q", tc.desc, tc.etag, etag))
+
+		var body struct {
+			Channels []struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"channels"`
+		}
+		json.NewDecoder(res.Body).Decode(&body)
+		var channels []string
+		for _, ch := range body.Channels {
+			channels = append(channels, fmt.Sprintf("%s:%s", ch.ID, ch.Name))
+		}
+		assert.ElementsMatch(t, tc.channels, channels, fmt.Sprintf("%s: expected channels %v got %v", tc.desc, tc.channels, channels))
+	}
+}
+
+func TestHeadThing(t *testing.T) {
+	otherToken := "other_token"
+	svc := newService(map[string]string{
+		token:      email,
+		otherToken: "other_user@example.com",
+	})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	oth, _ := svc.AddThing(context.Background(), otherToken, thing)
+
+	cases := []struct {
+		desc   string
+		id     string
+		auth   string
+		status int
+		etag   bool
+	}{
+		{"check existing thing", sth.ID, token, http.StatusOK, true},
+		{"check non-existent thing", wrongID, token, http.StatusNotFound, false},
+		{"check thing owned by other user", oth.ID, token, http.StatusNotFound, false},
+		{"check thing with invalid token", sth.ID, invalid, http.StatusForbidden, false},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodHead,
+			url:    fmt.Sprintf("%s/things/%s", ts.URL, tc.id),
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		body, err := ioutil.ReadAll(res.Body)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		assert.Empty(t, body, fmt.Sprintf("%s: expected empty body got %s", tc.desc, body))
+		etag := res.Header.Get("ETag")
+		assert.Equal(t, tc.etag, etag != "", fmt.Sprintf("%s: unexpected ETag %q", tc.desc, etag))
+	}
+}
+
+func TestViewThings(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	th1, _ := svc.AddThing(context.Background(), token, thing)
+	th2, _ := svc.AddThing(context.Background(), token, thing)
+	thingURL := fmt.Sprintf("%s/things", ts.URL)
+
+	tooMany := make([]string, 101)
+	for i := range tooMany {
+		tooMany[i] = th1.ID
+	}
+
+	cases := []struct {
+		desc   string
+		url    string
+		auth   string
+		status int
+		res    []string
+	}{
+		{"view things", fmt.Sprintf("%s?ids=%s,%s", thingURL, th2.ID, th1.ID), token, http.StatusOK, []string{th2.ID, th1.ID}},
+		{"view things including non-existing thing", fmt.Sprintf("%s?ids=%s,%s,%s", thingURL, th1.ID, wrongID, th2.ID), token, http.StatusOK, []string{th1.ID, th2.ID}},
+		{"view things with invalid token", fmt.Sprintf("%s?ids=%s", thingURL, th1.ID), invalid, http.StatusForbidden, nil},
+		{"view things with empty id", fmt.Sprintf("%s?ids=%s,", thingURL, th1.ID), token, http.StatusBadRequest, nil},
+		{"view things with repeated ids", fmt.Sprintf("%s?ids=%s&ids=%s", thingURL, th1.ID, th2.ID), token, http.StatusBadRequest, nil},
+		{"view things with name", fmt.Sprintf("%s?ids=%s&name=test", thingURL, th1.ID), token, http.StatusBadRequest, nil},
+		{"view too many things", fmt.Sprintf("%s?ids=%s", thingURL, strings.Join(tooMany, ",")), token, http.StatusBadRequest, nil},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    tc.url,
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+
+		var body struct {
+			Things []things.Thing `json:"things"`
+		}
+		json.NewDecoder(res.Body).Decode(&body)
+
+		var ids []string
+		for _, th := range body.Things {
+			ids = append(ids, th.ID)
+		}
+		assert.Equal(t, tc.res, ids, fmt.Sprintf("%s: expected things %v got %v", tc.desc, tc.res, ids))
+	}
+}
+
+func TestViewThingETag(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	view := func(etag string) *http.Response {
+		req := testRequest{
+			client:      ts.Client(),
+			method:      http.MethodGet,
+			url:         fmt.Sprintf("%s/things/%s", ts.URL, sth.ID),
+			token:       token,
+			ifNoneMatch: etag,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("view thing: unexpected error %s", err))
+		return res
+	}
+
+	res := view("")
+	etag := res.Header.Get("ETag")
+	assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("view thing: expected status code %d got %d", http.StatusOK, res.StatusCode))
+	assert.NotEmpty(t, etag, "view thing: expected ETag header")
+
+	cases := []struct {
+		desc   string
+		etag   string
+		status int
+		empty  bool
+	}{
+		{"view thing with matching ETag", etag, http.StatusNotModified, true},
+		{"view thing with matching weak ETag", fmt.Sprintf("W/%s", etag), http.StatusNotModified, true},
+		{"view thing with one of matching ETags", fmt.Sprintf(`"other", %s`, etag), http.StatusNotModified, true},
+		{"view thing with wildcard ETag", "*", http.StatusNotModified, true},
+		{"view thing with stale ETag", `"stale"`, http.StatusOK, false},
+	}
+
+	for _, tc := range cases {
+		res := view(tc.etag)
+		body, err := ioutil.ReadAll(res.Body)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		assert.Equal(t, tc.empty, len(body) == 0, fmt.Sprintf("%s: expected empty body %t got %s", tc.desc, tc.empty, body))
+		assert.Equal(t, etag, res.Header.Get("ETag"), fmt.Sprintf("%s: expected ETag %s got %s", tc.desc, etag, res.Header.Get("ETag")))
+	}
+
+	svc.UpdateThing(context.Background(), token, things.Thing{ID: sth.ID, Type: sth.Type, Name: "updated"})
+	res = view(etag)
+	assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("view updated thing: expected status code %d got %d", http.StatusOK, res.StatusCode))
+	assert.NotEqual(t, etag, res.Header.Get("ETag"), "view updated thing: expected new ETag")
+}
+
+func TestListLimits(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := httptest.NewServer(httpapi.MakeHandler(svc, mocks.NewIdentityProvider(), []string{origin}, httpapi.WithLimits(25, 50)))
+	defer ts.Close()
+
+	for i := 0; i < 60; i++ {
+		svc.AddThing(context.Background(), token, thing)
+	}
+
+	cases := []struct {
+		desc   string
+		url    string
+		status int
+		size   int
+	}{
+		{"list things without limit", fmt.Sprintf("%s/things", ts.URL), http.StatusOK, 25},
+		{"list things with max limit", fmt.Sprintf("%s/things?limit=%d", ts.URL, 50), http.StatusOK, 50},
+		{"list things with limit over max", fmt.Sprintf("%s/things?limit=%d", ts.URL, 51), http.StatusBadRequest, 0},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    tc.url,
+			token:  token,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+
+		var page thingsPageRes
+		json.NewDecoder(res.Body).Decode(&page)
+		assert.Equal(t, tc.size, len(page.Things), fmt.Sprintf("%s: expected size %d got %d", tc.desc, tc.size, len(page.Things)))
+	}
+
+	res, err := ts.Client().Get(fmt.Sprintf("%s/config", ts.URL))
+	assert.Nil(t, err, fmt.Sprintf("view config: unexpected error %s", err))
+	body, err := ioutil.ReadAll(res.Body)
+	assert.Nil(t, err, fmt.Sprintf("view config: unexpected error %s", err))
+	expected := `{"default_limit":25,"max_limit":50,"max_body_size":1048576,"basic_auth":false,"events":false,"idempotent_disconnect":false}`
+	assert.Equal(t, expected, strings.Trim(string(body), "\n"), fmt.Sprintf("view config: expected body %s got %s", expected, body))
+}
+
+func TestMaxBodySize(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := httptest.NewServer(httpapi.MakeHandler(svc, mocks.NewIdentityProvider(), []string{origin}, httpapi.WithMaxBodySize(256)))
+	defer ts.Close()
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	large := toJSON(things.Thing{Type: "app", Name: strings.Repeat("a", 512)})
+	data := toJSON(thing)
+	limit := data + strings.Repeat(" ", 256-len(data))
+
+	cases := []struct {
+		desc   string
+		method string
+		url    string
+		body   string
+		status int
+	}{
+		{"add thing within size limit", http.MethodPost, fmt.Sprintf("%s/things", ts.URL), toJSON(thing), http.StatusCreated},
+		{"add thing at size limit", http.MethodPost, fmt.Sprintf("%s/things", ts.URL), limit, http.StatusCreated},
+		{"add thing exceeding size limit", http.MethodPost, fmt.Sprintf("%s/things", ts.URL), large, http.StatusRequestEntityTooLarge},
+		{"update thing exceeding size limit", http.MethodPut, fmt.Sprintf("%s/things/%s", ts.URL, sth.ID), large, http.StatusRequestEntityTooLarge},
+		{"add channel exceeding size limit", http.MethodPost, fmt.Sprintf("%s/channels", ts.URL), large, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client:      ts.Client(),
+			method:      tc.method,
+			url:         tc.url,
+			contentType: contentType,
+			token:       token,
+			body:        strings.NewReader(tc.body),
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+	}
+}
+
+func TestBasicAuth(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+	bts := httptest.NewServer(httpapi.MakeHandler(svc, mocks.NewIdentityProvider(), []string{origin}, httpapi.WithBasicAuth()))
+	defer bts.Close()
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	data := toJSON(sth)
+
+	basic := func(username, password string) string {
+		creds := fmt.Sprintf("%s:%s", username, password)
+		return fmt.Sprintf("Basic %s", base64.StdEncoding.EncodeToString([]byte(creds)))
+	}
+
+	cases := []struct {
+		desc   string
+		server *httptest.Server
+		auth   []string
+		status int
+	}{
+		{"view thing with key", bts, []string{token}, http.StatusOK},
+		{"view thing with basic credentials", bts, []string{basic("user", token)}, http.StatusOK},
+		{"view thing with basic credentials without username", bts, []string{basic("", token)}, http.StatusOK},
+		{"view thing with invalid basic credentials", bts, []string{basic("user", invalid)}, http.StatusForbidden},
+		{"view thing with malformed basic credentials", bts, []string{"Basic !"}, http.StatusForbidden},
+		{"view thing with key and invalid basic credentials", bts, []string{basic("user", invalid), token}, http.StatusOK},
+		{"view thing with invalid key and basic credentials", bts, []string{invalid, basic("user", token)}, http.StatusForbidden},
+		{"view thing with basic credentials when disabled", ts, []string{basic("user", token)}, http.StatusForbidden},
+	}
+
+	for _, tc := range cases {
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/things/%s", tc.server.URL, sth.ID), nil)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		for _, auth := range tc.auth {
+			req.Header.Add("Authorization", auth)
+		}
+
+		res, err := tc.server.Client().Do(req)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+
+		if tc.status == http.StatusOK {
+			body, err := ioutil.ReadAll(res.Body)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			assert.Equal(t, data, strings.Trim(string(body), "\n"), fmt.Sprintf("%s: expected body %s got %s", tc.desc, data, body))
+		}
+	}
+}
+
+func TestListThings(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	data := []things.Thing{}
+	for i := 0; i < 101; i++ {
+		sth, _ := svc.AddThing(context.Background(), token, thing)
+		// must be "nulled" due to the JSON serialization that ignores owner
+		sth.Owner = ""
+		data = append(data, sth)
+	}
+	thingURL := fmt.Sprintf("%s/things", ts.URL)
+	cases := []struct {
+		desc   string
+		auth   string
+		status int
+		url    string
+		res    []things.Thing
+		total  int
+	}{
+		{"get a list of things", token, http.StatusOK, fmt.Sprintf("%s?offset=%d&limit=%d", thingURL, 0, 5), data[0:5], 101},
+		{"get a list of things with invalid token", invalid, http.StatusForbidden, fmt.Sprintf("%s?offset=%d&limit=%d", thingURL, 0, 1), nil, 0},
+		{"get a list of things with invalid offset", token, http.StatusBadRequest, fmt.Sprintf("%s?offset=%d&limit=%d", thingURL, -1, 5), nil, 0},
+		{"get a list of things with invalid limit", token, http.StatusBadRequest, fmt.Sprintf("%s?offset=%d&limit=%d", thingURL, 1, -5), nil, 0},
+		{"get a list of things with zero limit", token, http.StatusBadRequest, fmt.Sprintf("%s?offset=%d&limit=%d", thingURL, 1, 0), nil, 0},
+		{"get a list of things with no offset provided", token, http.StatusOK, fmt.Sprintf("%s?limit=%d", thingURL, 5), data[0:5], 101},
+		{"get a list of things with no limit provided", token, http.StatusOK, fmt.Sprintf("%s?offset=%d", thingURL, 1), data[1:11], 101},
+		{"get a list of things with redundant query params", token, http.StatusOK, fmt.Sprintf("%s?offset=%d&limit=%d&value=something", thingURL, 0, 5), data[0:5], 101},
+		{"get a list of things with limit greater than max", token, http.StatusBadRequest, fmt.Sprintf("%s?offset=%d&limit=%d", thingURL, 0, 110), nil, 0},
+		{"get a list of things with default URL", token, http.StatusOK, fmt.Sprintf("%s%s", thingURL, ""), data[0:10], 101},
+		{"get a list of things with invalid URL", token, http.StatusBadRequest, fmt.Sprintf("%s%s", thingURL, "?%%"), nil, 0},
+		{"get a list of things with invalid number of params", token, http.StatusBadRequest, fmt.Sprintf("%s%s", thingURL, "?offset=4&limit=4&limit=5&offset=5"), nil, 0},
+		{"get a list of things with invalid offset", token, http.StatusBadRequest, fmt.Sprintf("%s%s", thingURL, "?offset=e&limit=5"), nil, 0},
+		{"get a list of things with invalid limit", token, http.StatusBadRequest, fmt.Sprintf("%s%s", thingURL, "?offset=5&limit=e"), nil, 0},
+		{"get a list of things sorted in descending order", token, http.StatusOK, fmt.Sprintf("%s%s", thingURL, "?offset=0&limit=5&order=id&dir=desc"), data[96:101], 101},
+		{"get a list of things with invalid order", token, http.StatusBadRequest, fmt.Sprintf("%s%s", thingURL, "?order=key"), nil, 0},
+		{"get a list of things with invalid direction", token, http.StatusBadRequest, fmt.Sprintf("%s%s", thingURL, "?dir=up"), nil, 0},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    tc.url,
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		var data thingsPageRes
+		json.NewDecoder(res.Body).Decode(&data)
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		assert.ElementsMatch(t, tc.res, data.Things, fmt.Sprintf("%s: expected body %v got %v", tc.desc, tc.res, data.Things))
+		assert.Equal(t, tc.total, data.Total, fmt.Sprintf("%s: expected total %d got %d", tc.desc, tc.total, data.Total))
+	}
+}
+
+func TestListThingsPageToken(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	n := 25
+	for i := 0; i < n; i++ {
+		svc.AddThing(context.Background(), token, thing)
+	}
+	thingURL := fmt.Sprintf("%s/things", ts.URL)
+
+	cases := []struct {
+		desc   string
+		url    string
+		status int
+	}{
+		{"list things with invalid page token", fmt.Sprintf("%s?page_token=%s", thingURL, "!"), http.StatusBadRequest},
+		{"list things with repeated page token", fmt.Sprintf("%s?page_token=&page_token=", thingURL), http.StatusBadRequest},
+		{"list things with page token and offset", fmt.Sprintf("%s?page_token=&offset=5", thingURL), http.StatusBadRequest},
+		{"list things with page token and order", fmt.Sprintf("%s?page_token=&order=name", thingURL), http.StatusBadRequest},
+		{"list things with page token and name", fmt.Sprintf("%s?page_token=&name=test", thingURL), http.StatusBadRequest},
+		{"list things with page token and type", fmt.Sprintf("%s?page_token=&type=device", thingURL), http.StatusBadRequest},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    tc.url,
+			token:  token,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+	}
+
+	visited := map[string]int{}
+	pageToken, pages := "", 0
+	for {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    fmt.Sprintf("%s?limit=%d&page_token=%s", thingURL, 10, pageToken),
+			token:  token,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("list things with page token %s: unexpected error %s", pageToken, err))
+		assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("list things with page token %s: expected status code %d got %d", pageToken, http.StatusOK, res.StatusCode))
+
+		var body struct {
+			Things    []things.Thing `json:"things"`
+			NextToken string         `json:"next_token"`
+		}
+		json.NewDecoder(res.Body).Decode(&body)
+		for _, th := range body.Things {
+			visited[th.ID]++
+		}
+
+		pages++
+		if body.NextToken == "" || pages > n {
+			break
+		}
+		pageToken = body.NextToken
+	}
+
+	assert.Equal(t, 3, pages, fmt.Sprintf("walk things: expected %d pages got %d", 3, pages))
+	assert.Equal(t, n, len(visited), fmt.Sprintf("walk things: expected %d things got %d", n, len(visited)))
+	for id, cnt := range visited {
+		assert.Equal(t, 1, cnt, fmt.Sprintf("walk things: expected thing %s visited once got %d times", id, cnt))
+	}
+}
+
+func TestListThingsLinks(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	for i := 0; i < 101; i++ {
+		svc.AddThing(context.Background(), token, thing)
+	}
+	thingURL := fmt.Sprintf("%s/things", ts.URL)
+	pageURL := func(offset, limit int) string {
+		return fmt.Sprintf("%s?limit=%d&offset=%d", thingURL, limit, offset)
+	}
+
+	cases := []struct {
+		desc  string
+		url   string
+		links map[string]string
+	}{
+		{
+			desc:  "get links of the first page",
+			url:   fmt.Sprintf("%s?offset=%d&limit=%d", thingURL, 0, 5),
+			links: map[string]string{"first": pageURL(0, 5), "next": pageURL(5, 5)},
+		},
+		{
+			desc:  "get links of the middle page",
+			url:   fmt.Sprintf("%s?offset=%d&limit=%d", thingURL, 50, 5),
+			links: map[string]string{"first": pageURL(0, 5), "next": pageURL(55, 5), "prev": pageURL(45, 5)},
+		},
+		{
+			desc:  "get links of the page not aligned to the limit",
+			url:   fmt.Sprintf("%s?offset=%d&limit=%d", thingURL, 3, 5),
+			links: map[string]string{"first": pageURL(0, 5), "next": pageURL(8, 5), "prev": pageURL(0, 5)},
+		},
+		{
+			desc:  "get links of the page ending with the last thing",
+			url:   fmt.Sprintf("%s?offset=%d&limit=%d", thingURL, 96, 5),
+			links: map[string]string{"first": pageURL(0, 5), "prev": pageURL(91, 5)},
+		},
+		{
+			desc:  "get links of the page exceeding the total",
+			url:   fmt.Sprintf("%s?offset=%d&limit=%d", thingURL, 100, 10),
+			links: map[string]string{"first": pageURL(0, 10), "prev": pageURL(90, 10)},
+		},
+		{
+			desc:  "get links of the page with default URL",
+			url:   thingURL,
+			links: map[string]string{"first": pageURL(0, 10), "next": pageURL(10, 10)},
+		},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    tc.url,
+			token:  token,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		var data thingsPageRes
+		json.NewDecoder(res.Body).Decode(&data)
+		assert.Equal(t, tc.links, data.Links, fmt.Sprintf("%s: expected links %v got %v", tc.desc, tc.links, data.Links))
+	}
+}
+
+func TestListThingsAsCSV(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	th := thing
+	th.Name = "csv,thing"
+	sth, err := svc.AddThing(context.Background(), token, th)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	row := fmt.Sprintf("%s,\"%s\",%s,%s", sth.ID, sth.Name, sth.Key, things.StatusEnabled)
+	thingURL := fmt.Sprintf("%s/things", ts.URL)
+
+	cases := []struct {
+		desc        string
+		url         string
+		accept      string
+		contentType string
+		body        string
+	}{
+		{
+			desc:        "list things as CSV",
+			url:         thingURL,
+			accept:      "text/csv",
+			contentType: "text/csv",
+			body:        "id,name,key,status\n" + row + "\n",
+		},
+		{
+			desc:        "list things as preferred CSV",
+			url:         thingURL,
+			accept:      "text/csv, application/json;q=0.5",
+			contentType: "text/csv",
+			body:        "id,name,key,status\n" + row + "\n",
+		},
+		{
+			desc:        "list things as CSV by page token",
+			url:         fmt.Sprintf("%s?page_token=", thingURL),
+			accept:      "text/csv",
+			contentType: "text/csv",
+			body:        "id,name,key,status\n" + row + "\n",
+		},
+		{
+			desc:        "list things as CSV past the last page",
+			url:         fmt.Sprintf("%s?offset=%d", thingURL, 1),
+			accept:      "text/csv",
+			contentType: "text/csv",
+			body:        "id,name,key,status\n",
+		},
+		{
+			desc:        "list things with rejected CSV",
+			url:         thingURL,
+			accept:      "text/csv;q=0",
+			contentType: contentType,
+		},
+		{
+			desc:        "list things with any representation",
+			url:         thingURL,
+			accept:      "*/*",
+			contentType: contentType,
+		},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    tc.url,
+			token:  token,
+			accept: tc.accept,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, http.StatusOK, res.StatusCode))
+		ct := res.Header.Get("Content-Type")
+		assert.Equal(t, tc.contentType, ct, fmt.Sprintf("%s: expected content type %s got %s", tc.desc, tc.contentType, ct))
+		assert.Contains(t, res.Header["Vary"], "Accept", fmt.Sprintf("%s: expected response to vary by Accept header", tc.desc))
+		if tc.body == "" {
+			continue
+		}
+		body, err := ioutil.ReadAll(res.Body)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.body, string(body), fmt.Sprintf("%s: expected body %s got %s", tc.desc, tc.body, string(body)))
+	}
+}
+
+func TestListThingsAsCSVWithFormulas(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	th := thing
+	th.Name = "=HYPERLINK(\"https://evil.example.com\")"
+	sth, err := svc.AddThing(context.Background(), token, th)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	req := testRequest{
+		client: ts.Client(),
+		method: http.MethodGet,
+		url:    fmt.Sprintf("%s/things", ts.URL),
+		token:  token,
+		accept: "text/csv",
+	}
+	res, err := req.make()
+	assert.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+
+	expected := fmt.Sprintf("id,name,key,status\n%s,\"'=HYPERLINK(\"\"https://evil.example.com\"\")\",%s,%s\n", sth.ID, sth.Key, things.StatusEnabled)
+	body, err := ioutil.ReadAll(res.Body)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	assert.Equal(t, expected, string(body), fmt.Sprintf("expected body %s got %s", expected, string(body)))
+}
+
+func TestCountThings(t *testing.T) {
+	otherToken := "other_token"
+	otherEmail := "other_user@example.com"
+	svc := newService(map[string]string{
+		token:      email,
+		otherToken: otherEmail,
+	})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	n := 5
+	for i := 0; i < n; i++ {
+		svc.AddThing(context.Background(), token, thing)
+	}
+	svc.AddThing(context.Background(), otherToken, thing)
+
+	cases := []struct {
+		desc   string
+		auth   string
+		status int
+		count  int
+	}{
+		{"count things", token, http.StatusOK, n},
+		{"count other user's things", otherToken, http.StatusOK, 1},
+		{"count things with invalid token", invalid, http.StatusForbidden, 0},
+		{"count things with empty token", "", http.StatusForbidden, 0},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    fmt.Sprintf("%s/things/count", ts.URL),
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+
+		var body struct {
+			Count int `json:"count"`
+		}
+		json.NewDecoder(res.Body).Decode(&body)
+		assert.Equal(t, tc.count, body.Count, fmt.Sprintf("%s: expected count %d got %d", tc.desc, tc.count, body.Count))
+	}
+}
+
+func TestCountFilteredThings(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	n := 4
+	for i := 0; i < n; i++ {
+		svc.AddThing(context.Background(), token, thing)
+
+		device := thing
+		device.Type = "device"
+		device.Tags = []string{"lab"}
+		sth, _ := svc.AddThing(context.Background(), token, device)
+		if i%2 == 0 {
+			svc.DisableThing(context.Background(), token, sth.ID)
+		}
+	}
+
+	cases := []struct {
+		desc   string
+		query  string
+		status int
+		count  int
+	}{
+		{"count disabled devices", "type=device&status=disabled", http.StatusOK, n / 2},
+		{"count things by tag", "tag=lab", http.StatusOK, n},
+		{"count things by name", "name=TEST", http.StatusOK, 2 * n},
+		{"count things by missing metadata", "metadata=region:eu", http.StatusOK, 0},
+		{"count things with invalid status", "status=invalid", http.StatusUnprocessableEntity, 0},
+		{"count things with invalid metadata", "metadata=region", http.StatusBadRequest, 0},
+		{"count things with duplicated status", "status=enabled&status=disabled", http.StatusBadRequest, 0},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    fmt.Sprintf("%s/things/count?%s", ts.URL, tc.query),
+			token:  token,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+
+		var body struct {
+			Count int `json:"count"`
+		}
+		json.NewDecoder(res.Body).Decode(&body)
+		assert.Equal(t, tc.count, body.Count, fmt.Sprintf("%s: expected count %d got %d", tc.desc, tc.count, body.Count))
+	}
+}
+
+func TestSearchThings(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	data := []things.Thing{}
+	for i := 0; i < 20; i++ {
+		th := thing
+		th.Name = fmt.Sprintf("Sensor-%d", i%2)
+		sth, _ := svc.AddThing(context.Background(), token, th)
+		// must be "nulled" due to the JSON serialization that ignores owner
+		sth.Owner = ""
+		data = append(data, sth)
+	}
+	even, odd := []things.Thing{}, []things.Thing{}
+	for i := 0; i < len(data); i += 2 {
+		even = append(even, data[i])
+		odd = append(odd, data[i+1])
+	}
+	sorted := append(even, odd...)
+	thingURL := fmt.Sprintf("%s/things", ts.URL)
+
+	cases := []struct {
+		desc   string
+		auth   string
+		status int
+		url    string
+		res    []things.Thing
+		total  int
+	}{
+		{"search things by name", token, http.StatusOK, fmt.Sprintf("%s?name=%s", thingURL, "sensor-1"), odd, len(odd)},
+		{"search things by name with offset and limit", token, http.StatusOK, fmt.Sprintf("%s?name=%s&offset=%d&limit=%d", thingURL, "SENSOR", 5, 5), data[5:10], len(data)},
+		{"search things by name sorted by name", token, http.StatusOK, fmt.Sprintf("%s?name=%s&offset=%d&limit=%d&order=name", thingURL, "SENSOR", 5, 5), sorted[5:10], len(data)},
+		{"search things with no match", token, http.StatusOK, fmt.Sprintf("%s?name=%s", thingURL, "actuator"), []things.Thing{}, 0},
+		{"search things with invalid token", invalid, http.StatusForbidden, fmt.Sprintf("%s?name=%s", thingURL, "sensor"), nil, 0},
+		{"search things with invalid limit", token, http.StatusBadRequest, fmt.Sprintf("%s?name=%s&limit=%d", thingURL, "sensor", 0), nil, 0},
+		{"search things with multiple names", token, http.StatusBadRequest, fmt.Sprintf("%s?name=%s&name=%s", thingURL, "sensor", "actuator"), nil, 0},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    tc.url,
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		var data thingsPageRes
+		json.NewDecoder(res.Body).Decode(&data)
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		assert.ElementsMatch(t, tc.res, data.Things, fmt.Sprintf("%s: expected body %v got %v", tc.desc, tc.res, data.Things))
+		assert.Equal(t, tc.total, data.Total, fmt.Sprintf("%s: expected total %d got %d", tc.desc, tc.total, data.Total))
+	}
+}
+
+func TestListUnconnectedThings(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+
+	unconnected := []things.Thing{}
+	for i := 0; i < 10; i++ {
+		sth, _ := svc.AddThing(context.Background(), token, thing)
+		if i%3 == 0 {
+			svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+			continue
+		}
+		// must be "nulled" due to the JSON serialization that ignores owner
+		sth.Owner = ""
+		unconnected = append(unconnected, sth)
+	}
+	thingURL := fmt.Sprintf("%s/things", ts.URL)
+
+	cases := []struct {
+		desc   string
+		auth   string
+		status int
+		url    string
+		res    []things.Thing
+	}{
+		{"list unconnected things", token, http.StatusOK, fmt.Sprintf("%s?connected=false", thingURL), unconnected},
+		{"list unconnected things with offset and limit", token, http.StatusOK, fmt.Sprintf("%s?connected=false&offset=%d&limit=%d", thingURL, 2, 3), unconnected[2:5]},
+		{"list unconnected things with invalid token", invalid, http.StatusForbidden, fmt.Sprintf("%s?connected=false", thingURL), nil},
+		{"list connected things", token, http.StatusBadRequest, fmt.Sprintf("%s?connected=true", thingURL), nil},
+		{"list unconnected things by name", token, http.StatusBadRequest, fmt.Sprintf("%s?connected=false&name=%s", thingURL, "sensor"), nil},
+		{"list unconnected things with multiple flags", token, http.StatusBadRequest, fmt.Sprintf("%s?connected=false&connected=false", thingURL), nil},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    tc.url,
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		var data thingsPageRes
+		json.NewDecoder(res.Body).Decode(&data)
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		assert.Equal(t, len(tc.res), len(data.Things), fmt.Sprintf("%s: expected %d things got %d", tc.desc, len(tc.res), len(data.Things)))
+		assert.ElementsMatch(t, tc.res, data.Things, fmt.Sprintf("%s: expected body %v got %v", tc.desc, tc.res, data.Things))
+	}
+}
+
+func TestThingFields(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	th := thing
+	th.Name = "sensor"
+	th.Metadata = map[string]interface{}{"firmware": "1.0"}
+	sth, _ := svc.AddThing(context.Background(), token, th)
+	expected := map[string]interface{}{"id": sth.ID, "name": sth.Name}
+
+	cases := []struct {
+		desc   string
+		url    string
+		status int
+		res    map[string]interface{}
+	}{
+		{"view thing with fields", fmt.Sprintf("%s/things/%s?fields=id,name", ts.URL, sth.ID), http.StatusOK, expected},
+		{"view thing with fields lacking id", fmt.Sprintf("%s/things/%s?fields=name", ts.URL, sth.ID), http.StatusOK, expected},
+		{"view thing with unknown field", fmt.Sprintf("%s/things/%s?fields=name,owner", ts.URL, sth.ID), http.StatusBadRequest, nil},
+		{"view thing with multiple fields params", fmt.Sprintf("%s/things/%s?fields=id&fields=name", ts.URL, sth.ID), http.StatusBadRequest, nil},
+		{"list things with fields", fmt.Sprintf("%s/things?fields=id,name", ts.URL), http.StatusOK, expected},
+		{"list things with empty field", fmt.Sprintf("%s/things?fields=", ts.URL), http.StatusBadRequest, nil},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    tc.url,
+			token:  token,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		if tc.status != http.StatusOK {
+			continue
+		}
+
+		var doc map[string]interface{}
+		json.NewDecoder(res.Body).Decode(&doc)
+		if ths, ok := doc["things"].([]interface{}); ok {
+			assert.Equal(t, 1, len(ths), fmt.Sprintf("%s: expected %d things got %d", tc.desc, 1, len(ths)))
+			doc, _ = ths[0].(map[string]interface{})
+		}
+		assert.NotContains(t, doc, "metadata", fmt.Sprintf("%s: expected metadata to be absent", tc.desc))
+		assert.Equal(t, tc.res, doc, fmt.Sprintf("%s: expected body %v got %v", tc.desc, tc.res, doc))
+	}
+}
+
+func TestListThingsByMetadata(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	data := []things.Thing{}
+	for i := 0; i < 20; i++ {
+		th := thing
+		th.Metadata = map[string]interface{}{"firmware": fmt.Sprintf("1.%d", i%2)}
+		sth, _ := svc.AddThing(context.Background(), token, th)
+		// must be "nulled" due to the JSON serialization that ignores owner
+		sth.Owner = ""
+		data = append(data, sth)
+	}
+	odd := []things.Thing{}
+	for i := 1; i < len(data); i += 2 {
+		odd = append(odd, data[i])
+	}
+	thingURL := fmt.Sprintf("%s/things", ts.URL)
+
+	cases := []struct {
+		desc   string
+		auth   string
+		status int
+		url    string
+		res    []things.Thing
+	}{
+		{"list things by metadata", token, http.StatusOK, fmt.Sprintf("%s?metadata=%s", thingURL, "firmware:1.1"), odd},
+		{"list things by metadata with offset and limit", token, http.StatusOK, fmt.Sprintf("%s?metadata=%s&offset=%d&limit=%d", thingURL, "firmware:1.1", 5, 2), odd[5:7]},
+		{"list things by metadata with no match", token, http.StatusOK, fmt.Sprintf("%s?metadata=%s", thingURL, "firmware:2.0"), []things.Thing{}},
+		{"list things by metadata with invalid token", invalid, http.StatusForbidden, fmt.Sprintf("%s?metadata=%s", thingURL, "firmware:1.1"), nil},
+		{"list things by malformed metadata", token, http.StatusBadRequest, fmt.Sprintf("%s?metadata=%s", thingURL, "firmware"), nil},
+		{"list things by metadata and name", token, http.StatusOK, fmt.Sprintf("%s?metadata=%s&name=%s", thingURL, "firmware:1.1", "test"), odd},
+		{"list things by metadata and unmatched name", token, http.StatusOK, fmt.Sprintf("%s?metadata=%s&name=%s", thingURL, "firmware:1.1", "sensor"), []things.Thing{}},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    tc.url,
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		var data thingsPageRes
+		json.NewDecoder(res.Body).Decode(&data)
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		assert.ElementsMatch(t, tc.res, data.Things, fmt.Sprintf("%s: expected body %v got %v", tc.desc, tc.res, data.Things))
+	}
+}
+
+func TestRemoveThing(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+
+	cases := []struct {
+		desc   string
+		id     string
+		auth   string
+		status int
+	}{
+		{"delete existing thing", sth.ID, token, http.StatusNoContent},
+		{"delete non-existent thing", wrongID, token, http.StatusNoContent},
+		{"delete thing with invalid id", invalid, token, http.StatusNoContent},
+		{"delete thing with invalid token", sth.ID, invalid, http.StatusForbidden},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodDelete,
+			url:    fmt.Sprintf("%s/things/%s", ts.URL, tc.id),
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+	}
+}
+
+func TestRemoveAllThings(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	for i := 0; i < 3; i++ {
+		svc.AddThing(context.Background(), token, thing)
+	}
+
+	cases := []struct {
+		desc   string
+		auth   string
+		status int
+	}{
+		{"delete all things with invalid token", invalid, http.StatusForbidden},
+		{"delete all things with empty token", "", http.StatusForbidden},
+		{"delete all things", token, http.StatusNoContent},
+		{"delete all things of user without things", token, http.StatusNoContent},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodDelete,
+			url:    fmt.Sprintf("%s/things", ts.URL),
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+	}
+
+	count, _ := svc.CountThings(context.Background(), token, things.ThingFilter{})
+	assert.Equal(t, 0, count, fmt.Sprintf("count removed things: expected %d got %d", 0, count))
+}
+
+func TestRestoreThing(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	svc.RemoveThing(context.Background(), token, sth.ID)
+
+	cases := []struct {
+		desc   string
+		id     string
+		auth   string
+		status int
+	}{
+		{"restore removed thing", sth.ID, token, http.StatusOK},
+		{"restore non-existent thing", wrongID, token, http.StatusNotFound},
+		{"restore thing with invalid id", invalid, token, http.StatusNotFound},
+		{"restore thing with invalid token", sth.ID, invalid, http.StatusForbidden},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodPost,
+			url:    fmt.Sprintf("%s/things/%s/restore", ts.URL, tc.id),
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+	}
+}
+
+func TestTransferThing(t *testing.T) {
+	otherToken := "other_token"
+	otherEmail := "other_user@example.com"
+	svc := newService(map[string]string{
+		token:      email,
+		otherToken: otherEmail,
+	})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	data := toJSON(map[string]string{"owner": otherEmail})
+
+	cases := []struct {
+		desc        string
+		req         string
+		id          string
+		contentType string
+		auth        string
+		status      int
+	}{
+		{"transfer thing with invalid token", data, sth.ID, contentType, invalid, http.StatusForbidden},
+		{"transfer thing with invalid id", data, invalid, contentType, token, http.StatusNotFound},
+		{"transfer non-existent thing", data, wrongID, contentType, token, http.StatusNotFound},
+		{"transfer thing with invalid owner", toJSON(map[string]string{"owner": invalid}), sth.ID, contentType, token, http.StatusUnprocessableEntity},
+		{"transfer thing with empty JSON request", "{}", sth.ID, contentType, token, http.StatusUnprocessableEntity},
+		{"transfer thing with invalid request format", "}", sth.ID, contentType, token, http.StatusBadRequest},
+		{"transfer thing with missing content type", data, sth.ID, "", token, http.StatusUnsupportedMediaType},
+		{"transfer existing thing", data, sth.ID, contentType, token, http.StatusOK},
+		{"transfer transferred thing", data, sth.ID, contentType, token, http.StatusNotFound},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client:      ts.Client(),
+			method:      http.MethodPost,
+			url:         fmt.Sprintf("%s/things/%s/transfer", ts.URL, tc.id),
+			contentType: tc.contentType,
+			token:       tc.auth,
+			body:        strings.NewReader(tc.req),
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+	}
+
+	views := []struct {
+		desc   string
+		auth   string
+		status int
+	}{
+		{"view transferred thing as old owner", token, http.StatusNotFound},
+		{"view transferred thing as new owner", otherToken, http.StatusOK},
+	}
+
+	for _, tc := range views {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    fmt.Sprintf("%s/things/%s", ts.URL, sth.ID),
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+	}
+}
+
+func TestListDeletedThings(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	data := []things.Thing{}
+	for i := 0; i < 20; i++ {
+		sth, _ := svc.AddThing(context.Background(), token, thing)
+		if i%2 == 0 {
+			continue
+		}
+		svc.RemoveThing(context.Background(), token, sth.ID)
+		// must be "nulled" due to the JSON serialization that ignores owner
+		sth.Owner = ""
+		data = append(data, sth)
+	}
+	thingURL := fmt.Sprintf("%s/things", ts.URL)
+
+	cases := []struct {
+		desc   string
+		auth   string
+		status int
+		url    string
+		res    []things.Thing
+		total  int
+	}{
+		{"get a list of deleted things", token, http.StatusOK, fmt.Sprintf("%s?deleted=true", thingURL), data, 10},
+		{"get a subset of deleted things", token, http.StatusOK, fmt.Sprintf("%s?deleted=true&offset=%d&limit=%d", thingURL, 2, 3), data[2:5], 10},
+		{"get a list of deleted things with invalid token", invalid, http.StatusForbidden, fmt.Sprintf("%s?deleted=true", thingURL), nil, 0},
+		{"get a list of deleted things with invalid flag", token, http.StatusBadRequest, fmt.Sprintf("%s?deleted=maybe", thingURL), nil, 0},
+		{"get a list of deleted things by name", token, http.StatusBadRequest, fmt.Sprintf("%s?deleted=true&name=test", thingURL), nil, 0},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    tc.url,
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		var data thingsPageRes
+		json.NewDecoder(res.Body).Decode(&data)
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		assert.ElementsMatch(t, tc.res, data.Things, fmt.Sprintf("%s: expected body %v got %v", tc.desc, tc.res, data.Things))
+		assert.Equal(t, tc.total, data.Total, fmt.Sprintf("%s: expected total %d got %d", tc.desc, tc.total, data.Total))
+	}
+}
+
+func TestListThingsByType(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	app := thing
+	app.Type = "app"
+	sapp, _ := svc.AddThing(context.Background(), token, app)
+	sapp.Owner = ""
+
+	device := thing
+	device.Type = "device"
+	sdev, _ := svc.AddThing(context.Background(), token, device)
+	sdev.Owner = ""
+
+	thingURL := fmt.Sprintf("%s/things", ts.URL)
+
+	cases := []struct {
+		desc   string
+		auth   string
+		status int
+		url    string
+		res    []things.Thing
+		total  int
+	}{
+		{"get a list of devices", token, http.StatusOK, fmt.Sprintf("%s?type=device", thingURL), []things.Thing{sdev}, 1},
+		{"get a list of apps", token, http.StatusOK, fmt.Sprintf("%s?type=app", thingURL), []things.Thing{sapp}, 1},
+		{"get a list of things of any type", token, http.StatusOK, thingURL, []things.Thing{sapp, sdev}, 2},
+		{"get a list of things with invalid type", token, http.StatusUnprocessableEntity, fmt.Sprintf("%s?type=gateway", thingURL), nil, 0},
+		{"get a list of things by type and name", token, http.StatusOK, fmt.Sprintf("%s?type=device&name=test", thingURL), []things.Thing{sdev}, 1},
+		{"get a list of deleted things by type", token, http.StatusBadRequest, fmt.Sprintf("%s?type=device&deleted=true", thingURL), nil, 0},
+		{"get a list of devices with invalid token", invalid, http.StatusForbidden, fmt.Sprintf("%s?type=device", thingURL), nil, 0},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    tc.url,
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		var data thingsPageRes
+		json.NewDecoder(res.Body).Decode(&data)
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		assert.ElementsMatch(t, tc.res, data.Things, fmt.Sprintf("%s: expected body %v got %v", tc.desc, tc.res, data.Things))
+		assert.Equal(t, tc.total, data.Total, fmt.Sprintf("%s: expected total %d got %d", tc.desc, tc.total, data.Total))
+	}
+}
+
+func TestListThingsByTag(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	hvac := thing
+	hvac.Tags = []string{"floor-1", "hvac"}
+	shvac, _ := svc.AddThing(context.Background(), token, hvac)
+	shvac.Owner = ""
+
+	light := thing
+	light.Tags = []string{"floor-1", "lighting"}
+	slight, _ := svc.AddThing(context.Background(), token, light)
+	slight.Owner = ""
+
+	thingURL := fmt.Sprintf("%s/things", ts.URL)
+
+	cases := []struct {
+		desc   string
+		auth   string
+		status int
+		url    string
+		res    []things.Thing
+		total  int
+	}{
+		{"get a list of things by tag", token, http.StatusOK, fmt.Sprintf("%s?tag=hvac", thingURL), []things.Thing{shvac}, 1},
+		{"get a list of things by shared tag", token, http.StatusOK, fmt.Sprintf("%s?tag=floor-1", thingURL), []things.Thing{shvac, slight}, 2},
+		{"get a list of things by unknown tag", token, http.StatusOK, fmt.Sprintf("%s?tag=floor-2", thingURL), []things.Thing{}, 0},
+		{"get a list of things by tag and type", token, http.StatusOK, fmt.Sprintf("%s?tag=hvac&type=app", thingURL), []things.Thing{shvac}, 1},
+		{"get a list of things by tag and name", token, http.StatusOK, fmt.Sprintf("%s?tag=hvac&name=test", thingURL), []things.Thing{shvac}, 1},
+		{"get a list of deleted things by tag", token, http.StatusBadRequest, fmt.Sprintf("%s?tag=hvac&deleted=true", thingURL), nil, 0},
+		{"get a list of things by multiple tags", token, http.StatusBadRequest, fmt.Sprintf("%s?tag=hvac&tag=floor-1", thingURL), nil, 0},
+		{"get a list of things by tag with invalid token", invalid, http.StatusForbidden, fmt.Sprintf("%s?tag=hvac", thingURL), nil, 0},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    tc.url,
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		var data thingsPageRes
+		json.NewDecoder(res.Body).Decode(&data)
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		assert.ElementsMatch(t, tc.res, data.Things, fmt.Sprintf("%s: expected body %v got %v", tc.desc, tc.res, data.Things))
+		assert.Equal(t, tc.total, data.Total, fmt.Sprintf("%s: expected total %d got %d", tc.desc, tc.total, data.Total))
+	}
+}
+
+func TestListThingsByFilters(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	add := func(thingType, tag string, disabled bool) things.Thing {
+		th := thing
+		th.Type = thingType
+		th.Tags = []string{"floor-1", tag}
+		sth, _ := svc.AddThing(context.Background(), token, th)
+		if disabled {
+			svc.DisableThing(context.Background(), token, sth.ID)
+			sth, _ = svc.ViewThing(context.Background(), token, sth.ID)
+		}
+		sth.Owner = ""
+		return sth
+	}
+
+	match := add("device", "hvac", true)
+	add("device", "hvac", false)
+	add("app", "hvac", true)
+	add("device", "lighting", true)
+
+	thingURL := fmt.Sprintf("%s/things", ts.URL)
+
+	cases := []struct {
+		desc   string
+		status int
+		url    string
+		res    []things.Thing
+		total  int
+	}{
+		{"get a list of disabled hvac devices", http.StatusOK, fmt.Sprintf("%s?type=device&status=disabled&tag=hvac", thingURL), []things.Thing{match}, 1},
+		{"get a list of disabled hvac devices by name", http.StatusOK, fmt.Sprintf("%s?type=device&status=disabled&tag=hvac&name=test", thingURL), []things.Thing{match}, 1},
+		{"get a list of disabled hvac devices with unmatched name", http.StatusOK, fmt.Sprintf("%s?type=device&status=disabled&tag=hvac&name=sensor", thingURL), []things.Thing{}, 0},
+		{"get a list of things with invalid status", http.StatusUnprocessableEntity, fmt.Sprintf("%s?status=invalid&tag=hvac", thingURL), nil, 0},
+		{"get a list of things with multiple statuses", http.StatusBadRequest, fmt.Sprintf("%s?status=enabled&status=disabled", thingURL), nil, 0},
+		{"get a list of deleted things by status", http.StatusBadRequest, fmt.Sprintf("%s?status=disabled&deleted=true", thingURL), nil, 0},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    tc.url,
+			token:  token,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		var data thingsPageRes
+		json.NewDecoder(res.Body).Decode(&data)
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		assert.ElementsMatch(t, tc.res, data.Things, fmt.Sprintf("%s: expected body %v got %v", tc.desc, tc.res, data.Things))
+		assert.Equal(t, tc.total, data.Total, fmt.Sprintf("%s: expected total %d got %d", tc.desc, tc.total, data.Total))
+	}
+}
+
+func TestCreateChannel(t *testing.T) {
+	id := "123e4567-e89b-12d3-a456-000000000001"
+	charsetID := "123e4567-e89b-12d3-a456-000000000002"
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	data := toJSON(channel)
+
+	cases := []struct {
+		desc        string
+		req         string
+		contentType string
+		auth        string
+		status      int
+		location    string
+	}{
+		{"create new channel", data, contentType, token, http.StatusCreated, fmt.Sprintf("/channels/%s", id)},
+		{"create new channel with invalid token", data, contentType, invalid, http.StatusForbidden, ""},
+		{"create new channel with invalid data format", "{", contentType, token, http.StatusBadRequest, ""},
+		{"create new channel with empty JSON request", "{}", contentType, token, http.StatusUnprocessableEntity, ""},
+		{"create new channel with too long name", toJSON(things.Channel{Name: strings.Repeat("a", things.MaxNameLength+1)}), contentType, token, http.StatusUnprocessableEntity, ""},
+		{"create new channel with invalid webhook", `{"name":"test","webhook":{"url":"ftp://example.com"}}`, contentType, token, http.StatusUnprocessableEntity, ""},
+		{"create new channel with empty request", "", contentType, token, http.StatusBadRequest, ""},
+		{"create new channel with missing content type", data, "", token, http.StatusUnsupportedMediaType, ""},
+		{"create new channel with invalid content type", data, "text/plain", token, http.StatusUnsupportedMediaType, ""},
+		{"create new channel with charset in content type", data, "application/json; charset=utf-8", token, http.StatusCreated, fmt.Sprintf("/channels/%s", charsetID)},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client:      ts.Client(),
+			method:      http.MethodPost,
+			url:         fmt.Sprintf("%s/channels", ts.URL),
+			contentType: tc.contentType,
+			token:       tc.auth,
+			body:        strings.NewReader(tc.req),
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+
+		location := res.Header.Get("Location")
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		assert.Equal(t, tc.location, location, fmt.Sprintf("%s: expected location %s got %s", tc.desc, tc.location, location))
+	}
+}
+
+func TestUpdateChannel(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	updateData := toJSON(map[string]string{
+		"name": "updated_channel",
+	})
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+
+	cases := []struct {
+		desc        string
+		req         string
+		id          string
+		contentType string
+		auth        string
+		status      int
+	}{
+		{"update existing channel", updateData, sch.ID, contentType, token, http.StatusOK},
+		{"update non-existing channel", updateData, wrongID, contentType, token, http.StatusNotFound},
+		{"update channel with invalid token", updateData, sch.ID, contentType, invalid, http.StatusForbidden},
+		{"update channel with invalid id", updateData, invalid, contentType, token, http.StatusNotFound},
+		{"update channel with invalid data format", "}", sch.ID, contentType, token, http.StatusBadRequest},
+		{"update channel with empty JSON object", "{}", sch.ID, contentType, token, http.StatusUnprocessableEntity},
+		{"update channel with empty request", "", sch.ID, contentType, token, http.StatusBadRequest},
+		{"update channel with missing content type", updateData, sch.ID, "", token, http.StatusUnsupportedMediaType},
+		{"update channel with invalid content type", updateData, sch.ID, "text/plain", token, http.StatusUnsupportedMediaType},
+		{"update channel with charset in content type", updateData, sch.ID, "application/json; charset=utf-8", token, http.StatusOK},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client:      ts.Client(),
+			method:      http.MethodPut,
+			url:         fmt.Sprintf("%s/channels/%s", ts.URL, tc.id),
+			contentType: tc.contentType,
+			token:       tc.auth,
+			body:        strings.NewReader(tc.req),
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+	}
+}
+
+func TestPatchChannel(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	ch := channel
+	ch.Metadata = map[string]interface{}{
+		"kept":    "value",
+		"removed": "value",
+		"nested":  map[string]interface{}{"kept": "value", "removed": "value"},
+	}
+	sch, _ := svc.CreateChannel(context.Background(), token, ch)
+
+	data := toJSON(map[string]string{"name": "patched_channel"})
+	metadataData := `{"metadata":{"removed":null,"added":"value","nested":{"removed":null}}}`
+
+	cases := []struct {
+		desc        string
+		req         string
+		id          string
+		contentType string
+		auth        string
+		status      int
+	}{
+		{"patch channel name", data, sch.ID, contentType, token, http.StatusOK},
+		{"patch channel metadata", metadataData, sch.ID, "application/merge-patch+json", token, http.StatusOK},
+		{"patch non-existent channel", data, wrongID, contentType, token, http.StatusNotFound},
+		{"patch channel with invalid id", data, invalid, contentType, token, http.StatusNotFound},
+		{"patch channel with invalid user token", data, sch.ID, contentType, invalid, http.StatusForbidden},
+		{"patch channel with removed name", `{"name":null}`, sch.ID, contentType, token, http.StatusUnprocessableEntity},
+		{"patch channel with invalid name", `{"name":1}`, sch.ID, contentType, token, http.StatusUnprocessableEntity},
+		{"patch channel with invalid metadata", `{"metadata":"value"}`, sch.ID, contentType, token, http.StatusUnprocessableEntity},
+		{"patch channel with invalid data format", "{", sch.ID, contentType, token, http.StatusBadRequest},
+		{"patch channel with empty JSON request", "{}", sch.ID, contentType, token, http.StatusUnprocessableEntity},
+		{"patch channel with missing content type", data, sch.ID, "", token, http.StatusUnsupportedMediaType},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client:      ts.Client(),
+			method:      http.MethodPatch,
+			url:         fmt.Sprintf("%s/channels/%s", ts.URL, tc.id),
+			contentType: tc.contentType,
+			token:       tc.auth,
+			body:        strings.NewReader(tc.req),
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+	}
+
+	metadata := map[string]interface{}{
+		"kept":   "value",
+		"added":  "value",
+		"nested": map[string]interface{}{"kept": "value"},
+	}
+	patched, _ := svc.ViewChannel(context.Background(), token, sch.ID)
+	assert.Equal(t, "patched_channel", patched.Name, fmt.Sprintf("patch channel: expected name %s got %s", "patched_channel", patched.Name))
+	assert.Equal(t, metadata, patched.Metadata, fmt.Sprintf("patch channel: expected metadata %v got %v", metadata, patched.Metadata))
+}
+
+func TestViewChannel(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	data := toJSON(sch)
+
+	cases := []struct {
+		desc   string
+		id     string
+		auth   string
+		status int
+		res    string
+	}{
+		{"view existing channel", sch.ID, token, http.StatusOK, data},
+		{"view non-existent channel", wrongID, token, http.StatusNotFound, errorJSON(things.ErrNotFound, "not_found")},
+		{"view channel with invalid id", invalid, token, http.StatusNotFound, errorJSON(things.ErrNotFound, "not_found")},
+		{"view channel with invalid token", sch.ID, invalid, http.StatusForbidden, errorJSON(things.ErrUnauthorizedAccess, "unauthorized")},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    fmt.Sprintf("%s/channels/%s", ts.URL, tc.id),
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		data, err := ioutil.ReadAll(res.Body)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		body := strings.Trim(string(data), "\n")
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		assert.Equal(t, tc.res, body, fmt.Sprintf("%s: expected body %v got %v", tc.desc, tc.res, body))
+	}
+}
+
+func TestViewChannelWebhookSecret(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	ch := channel
+	ch.Webhook = &things.Webhook{URL: "https://example.com/hook", Secret: "secret"}
+	sch, _ := svc.CreateChannel(context.Background(), token, ch)
+
+	cases := []struct {
+		desc string
+		url  string
+	}{
+		{"view channel", fmt.Sprintf("%s/channels/%s", ts.URL, sch.ID)},
+		{"list channels", fmt.Sprintf("%s/channels", ts.URL)},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    tc.url,
+			token:  token,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		data, err := ioutil.ReadAll(res.Body)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, http.StatusOK, res.StatusCode))
+		assert.Contains(t, string(data), ch.Webhook.URL, fmt.Sprintf("%s: expected webhook URL in %s", tc.desc, data))
+		assert.NotContains(t, string(data), ch.Webhook.Secret, fmt.Sprintf("%s: unexpected webhook secret in %s", tc.desc, data))
+	}
+
+	saved, _ := svc.ViewChannel(context.Background(), token, sch.ID)
+	assert.Equal(t, ch.Webhook.Secret, saved.Webhook.Secret, fmt.Sprintf("expected stored secret %s got %s", ch.Webhook.Secret, saved.Webhook.Secret))
+}
+
+func TestHeadChannel(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+
+	cases := []struct {
+		desc   string
+		id     string
+		auth   string
+		status int
+	}{
+		{"check existing channel", sch.ID, token, http.StatusOK},
+		{"check non-existent channel", wrongID, token, http.StatusNotFound},
+		{"check channel with invalid token", sch.ID, invalid, http.StatusForbidden},
+		{"check channel with empty token", sch.ID, "", http.StatusForbidden},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodHead,
+			url:    fmt.Sprintf("%s/channels/%s", ts.URL, tc.id),
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		body, err := ioutil.ReadAll(res.Body)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		assert.Empty(t, body, fmt.Sprintf("%s: expected empty body got %s", tc.desc, body))
+	}
+}
+
+func TestViewChannelConnections(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	ech, _ := svc.CreateChannel(context.Background(), token, channel)
+	first, _ := svc.AddThing(context.Background(), token, thing)
+	second, _ := svc.AddThing(context.Background(), token, thing)
+	svc.Connect(context.Background(), token, sch.ID, second.ID, things.AccessPubSub)
+	time.Sleep(10 * time.Millisecond)
+	svc.Connect(context.Background(), token, sch.ID, first.ID, things.AccessPubSub)
+
+	cases := []struct {
+		desc   string
+		id     string
+		query  string
+		status int
+		things []string
+	}{
+		{"view channel with connections", sch.ID, "include=connections", http.StatusOK, []string{second.ID, first.ID}},
+		{"view channel without connections", ech.ID, "include=connections", http.StatusOK, []string{}},
+		{"view channel without included connections", sch.ID, "", http.StatusOK, nil},
+		{"view channel with invalid include", sch.ID, "include=things", http.StatusBadRequest, nil},
+		{"view channel with duplicated include", sch.ID, "include=connections&include=connections", http.StatusBadRequest, nil},
+		{"view non-existent channel with connections", wrongID, "include=connections", http.StatusNotFound, nil},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    fmt.Sprintf("%s/channels/%s?%s", ts.URL, tc.id, tc.query),
+			token:  token,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+
+		var body struct {
+			Connections []struct {
+				ThingID     string    `json:"thing_id"`
+				ConnectedAt time.Time `json:"connected_at"`
+			} `json:"connections"`
+		}
+		json.NewDecoder(res.Body).Decode(&body)
+		assert.Equal(t, len(tc.things), len(body.Connections), fmt.Sprintf("%s: expected %d connections got %d", tc.desc, len(tc.things), len(body.Connections)))
+		for i, conn := range body.Connections {
+			if i >= len(tc.things) {
+				break
+			}
+			assert.Equal(t, tc.things[i], conn.ThingID, fmt.Sprintf("%s: expected thing %s got %s", tc.desc, tc.things[i], conn.ThingID))
+			assert.False(t, conn.ConnectedAt.IsZero(), fmt.Sprintf("%s: expected connection timestamp", tc.desc))
+			if i > 0 {
+				prev := body.Connections[i-1].ConnectedAt
+				assert.True(t, prev.Before(conn.ConnectedAt), fmt.Sprintf("%s: expected connection at %s to follow the one at %s", tc.desc, conn.ConnectedAt, prev))
+			}
+		}
+	}
+}
+
+func TestChannelOwner(t *testing.T) {
+	serviceKey := "service-key"
+	users := mocks.NewUsersService(map[string]string{token: email})
+	thingsRepo := mocks.NewThingRepository()
+	channelsRepo := mocks.NewChannelRepository(thingsRepo)
+	svc := things.New(users, thingsRepo, channelsRepo, mocks.NewIdentityProvider(), things.WithServiceKey(serviceKey))
+	ts := newServer(svc)
+	defer ts.Close()
+
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+
+	cases := []struct {
+		desc   string
+		id     string
+		auth   string
+		status int
+		res    string
+	}{
+		{"view owner of existing channel", sch.ID, serviceKey, http.StatusOK, fmt.Sprintf(`{"owner":"%s"}`, email)},
+		{"view owner of non-existent channel", wrongID, serviceKey, http.StatusNotFound, errorJSON(things.ErrNotFound, "not_found")},
+		{"view owner of channel with invalid id", invalid, serviceKey, http.StatusNotFound, errorJSON(things.ErrNotFound, "not_found")},
+		{"view owner of channel with user's token", sch.ID, token, http.StatusForbidden, errorJSON(things.ErrUnauthorizedAccess, "unauthorized")},
+		{"view owner of channel with empty key", sch.ID, "", http.StatusForbidden, errorJSON(things.ErrUnauthorizedAccess, "unauthorized")},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    fmt.Sprintf("%s/channels/%s/owner", ts.URL, tc.id),
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		data, err := ioutil.ReadAll(res.Body)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		body := strings.Trim(string(data), "\n")
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		assert.Equal(t, tc.res, body, fmt.Sprintf("%s: expected body %v got %v", tc.desc, tc.res, body))
+	}
+}
+
+func TestListChannels(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	channels := []things.Channel{}
+	for i := 0; i < 101; i++ {
+		sch, _ := svc.CreateChannel(context.Background(), token, channel)
+		// must be "nulled" due to the JSON serialization that ignores owner
+		sch.Owner = ""
+		channels = append(channels, sch)
+	}
+	channelURL := fmt.Sprintf("%s/channels", ts.URL)
+
+	cases := []struct {
+		desc   string
+		auth   string
+		status int
+		url    string
+		res    []things.Channel
+		total  int
+	}{
+		{"get a list of channels", token, http.StatusOK, fmt.Sprintf("%s?offset=%d&limit=%d", channelURL, 0, 6), channels[0:6], 101},
+		{"get a list of channels with invalid token", invalid, http.StatusForbidden, fmt.Sprintf("%s?offset=%d&limit=%d", channelURL, 0, 1), nil, 0},
+		{"get a list of channels with invalid offset", token, http.StatusBadRequest, fmt.Sprintf("%s?offset=%d&limit=%d", channelURL, -1, 5), nil, 0},
+		{"get a list of channels with invalid limit", token, http.StatusBadRequest, fmt.Sprintf("%s?offset=%d&limit=%d", channelURL, -1, 5), nil, 0},
+		{"get a list of channels with zero limit", token, http.StatusBadRequest, fmt.Sprintf("%s?offset=%d&limit=%d", channelURL, 1, 0), nil, 0},
+		{"get a list of channels with no offset provided", token, http.StatusOK, fmt.Sprintf("%s?limit=%d", channelURL, 5), channels[0:5], 101},
+		{"get a list of channels with no limit provided", token, http.StatusOK, fmt.Sprintf("%s?offset=%d", channelURL, 1), channels[1:11], 101},
+		{"get a list of channels with redundant query params", token, http.StatusOK, fmt.Sprintf("%s?offset=%d&limit=%d&value=something", channelURL, 0, 5), channels[0:5], 101},
+		{"get a list of channels with limit greater than max", token, http.StatusBadRequest, fmt.Sprintf("%s?offset=%d&limit=%d", channelURL, 0, 110), nil, 0},
+		{"get a list of channels with default URL", token, http.StatusOK, fmt.Sprintf("%s%s", channelURL, ""), channels[0:10], 101},
+		{"get a list of channels with invalid URL", token, http.StatusBadRequest, fmt.Sprintf("%s%s", channelURL, "?%%"), nil, 0},
+		{"get a list of channels with invalid number of params", token, http.StatusBadRequest, fmt.Sprintf("%s%s", channelURL, "?offset=4&limit=4&limit=5&offset=5"), nil, 0},
+		{"get a list of channels with invalid offset", token, http.StatusBadRequest, fmt.Sprintf("%s%s", channelURL, "?offset=e&limit=5"), nil, 0},
+		{"get a list of channels with invalid limit", token, http.StatusBadRequest, fmt.Sprintf("%s%s", channelURL, "?offset=5&limit=e"), nil, 0},
+		{"get a list of channels with invalid order", token, http.StatusBadRequest, fmt.Sprintf("%s%s", channelURL, "?order=key"), nil, 0},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    tc.url,
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		var data channelsPageRes
+		json.NewDecoder(res.Body).Decode(&data)
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		assert.ElementsMatch(t, tc.res, data.Channels, fmt.Sprintf("%s: expected body %v got %v", tc.desc, tc.res, data.Channels))
+		assert.Equal(t, tc.total, data.Total, fmt.Sprintf("%s: expected total %d got %d", tc.desc, tc.total, data.Total))
+	}
+}
+
+func TestListChannelsByMetadata(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	eu, us := []things.Channel{}, []things.Channel{}
+	for i := 0; i < 10; i++ {
+		ch := channel
+		ch.Metadata = map[string]interface{}{"region": "eu"}
+		if i%2 == 1 {
+			ch.Metadata["region"] = "us"
+		}
+		sch, _ := svc.CreateChannel(context.Background(), token, ch)
+		// must be "nulled" due to the JSON serialization that ignores owner
+		sch.Owner = ""
+		if i%2 == 1 {
+			us = append(us, sch)
+			continue
+		}
+		eu = append(eu, sch)
+	}
+	channelURL := fmt.Sprintf("%s/channels", ts.URL)
+
+	cases := []struct {
+		desc   string
+		status int
+		url    string
+		res    []things.Channel
+	}{
+		{"get a list of channels in the eu region", http.StatusOK, fmt.Sprintf("%s?metadata=%s", channelURL, "region:eu"), eu},
+		{"get a list of channels in the us region", http.StatusOK, fmt.Sprintf("%s?metadata=%s", channelURL, "region:us"), us},
+		{"get a list of channels in non-existing region", http.StatusOK, fmt.Sprintf("%s?metadata=%s", channelURL, "region:asia"), []things.Channel{}},
+		{"get a list of channels with metadata filter without value", http.StatusBadRequest, fmt.Sprintf("%s?metadata=%s", channelURL, "region"), nil},
+		{"get a list of channels with metadata filter without key", http.StatusBadRequest, fmt.Sprintf("%s?metadata=%s", channelURL, ":eu"), nil},
+		{"get a list of channels with multiple metadata filters", http.StatusBadRequest, fmt.Sprintf("%s?metadata=%s&metadata=%s", channelURL, "region:eu", "region:us"), nil},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    tc.url,
+			token:  token,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		var body map[string][]things.Channel
+		json.NewDecoder(res.Body).Decode(&body)
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		assert.ElementsMatch(t, tc.res, body["channels"], fmt.Sprintf("%s: expected body %v got %v", tc.desc, tc.res, body["channels"]))
+	}
+}
+
+func TestCountChannels(t *testing.T) {
+	otherToken := "other_token"
+	otherEmail := "other_user@example.com"
+	svc := newService(map[string]string{
+		token:      email,
+		otherToken: otherEmail,
+	})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	n := 5
+	for i := 0; i < n; i++ {
+		svc.CreateChannel(context.Background(), token, channel)
+	}
+	svc.CreateChannel(context.Background(), otherToken, channel)
+
+	cases := []struct {
+		desc   string
+		auth   string
+		status int
+		count  int
+	}{
+		{"count channels", token, http.StatusOK, n},
+		{"count other user's channels", otherToken, http.StatusOK, 1},
+		{"count channels with invalid token", invalid, http.StatusForbidden, 0},
+		{"count channels with empty token", "", http.StatusForbidden, 0},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    fmt.Sprintf("%s/channels/count", ts.URL),
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+
+		var body struct {
+			Count int `json:"count"`
+		}
+		json.NewDecoder(res.Body).Decode(&body)
+		assert.Equal(t, tc.count, body.Count, fmt.Sprintf("%s: expected count %d got %d", tc.desc, tc.count, body.Count))
+	}
+}
+
+func TestChannelConnectionsCount(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	empty, _ := svc.CreateChannel(context.Background(), token, channel)
+
+	n := 3
+	for i := 0; i < n; i++ {
+		sth, _ := svc.AddThing(context.Background(), token, thing)
+		svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+	}
+
+	cases := []struct {
+		desc   string
+		chanID string
+		auth   string
+		status int
+		count  int
+	}{
+		{"count things connected to channel", sch.ID, token, http.StatusOK, n},
+		{"count things connected to empty channel", empty.ID, token, http.StatusOK, 0},
+		{"count things connected to non-existent channel", wrongID, token, http.StatusNotFound, 0},
+		{"count things connected to channel with invalid id", invalid, token, http.StatusNotFound, 0},
+		{"count things connected to channel with invalid token", sch.ID, invalid, http.StatusForbidden, 0},
+		{"count things connected to channel with empty token", sch.ID, "", http.StatusForbidden, 0},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    fmt.Sprintf("%s/channels/%s/things/count", ts.URL, tc.chanID),
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+
+		var body struct {
+			Count int `json:"count"`
+		}
+		json.NewDecoder(res.Body).Decode(&body)
+		assert.Equal(t, tc.count, body.Count, fmt.Sprintf("%s: expected count %d got %d", tc.desc, tc.count, body.Count))
+	}
+}
+
+func TestChannelEvents(t *testing.T) {
+	bus := things.NewEventBus()
+	svc := things.NewEventStoreService(newService(map[string]string{token: email}), bus, logger.New(&bytes.Buffer{}))
+	ts := httptest.NewServer(httpapi.MakeHandler(svc, mocks.NewIdentityProvider(), []string{origin}, httpapi.WithEventBus(bus)))
+	defer ts.Close()
+	nts := newServer(svc)
+	defer nts.Close()
+
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+
+	cases := []struct {
+		desc   string
+		server *httptest.Server
+		chanID string
+		auth   string
+		status int
+	}{
+		{"stream events of non-existent channel", ts, wrongID, token, http.StatusNotFound},
+		{"stream events of channel with invalid token", ts, sch.ID, invalid, http.StatusForbidden},
+		{"stream events of channel with empty token", ts, sch.ID, "", http.StatusForbidden},
+		{"stream events of channel without event bus", nts, sch.ID, token, http.StatusNotImplemented},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: tc.server.Client(),
+			method: http.MethodGet,
+			url:    fmt.Sprintf("%s/channels/%s/events", tc.server.URL, tc.chanID),
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+	}
+
+	req := testRequest{
+		client: ts.Client(),
+		method: http.MethodGet,
+		url:    fmt.Sprintf("%s/channels/%s/events", ts.URL, sch.ID),
+		token:  token,
+	}
+	res, err := req.make()
+	assert.Nil(t, err, fmt.Sprintf("stream events of channel: unexpected error %s", err))
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("stream events of channel: expected status code %d got %d", http.StatusOK, res.StatusCode))
+	ct := res.Header.Get("Content-Type")
+	assert.Equal(t, "text/event-stream", ct, fmt.Sprintf("stream events of channel: expected content type text/event-stream got %s", ct))
+
+	_, err = svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+	assert.Nil(t, err, fmt.Sprintf("connect thing to channel: unexpected error %s", err))
+
+	reader := bufio.NewReader(res.Body)
+	event, err := reader.ReadString('\n')
+	assert.Nil(t, err, fmt.Sprintf("read event: unexpected error %s", err))
+	assert.Equal(t, "event: channel.connect\n", event, fmt.Sprintf("read event: expected event channel.connect got %s", event))
+
+	line, err := reader.ReadString('\n')
+	assert.Nil(t, err, fmt.Sprintf("read event data: unexpected error %s", err))
+	var data struct {
+		Event   string `json:"event"`
+		ChanID  string `json:"channel_id"`
+		ThingID string `json:"thing_id"`
+	}
+	err = json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &data)
+	assert.Nil(t, err, fmt.Sprintf("decode event data: unexpected error %s", err))
+	assert.Equal(t, things.EventConnect, data.Event, fmt.Sprintf("event data: expected event %s got %s", things.EventConnect, data.Event))
+	assert.Equal(t, sch.ID, data.ChanID, fmt.Sprintf("event data: expected channel %s got %s", sch.ID, data.ChanID))
+	assert.Equal(t, sth.ID, data.ThingID, fmt.Sprintf("event data: expected thing %s got %s", sth.ID, data.ThingID))
+}
+
+func TestListChannelsByThing(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	channels := []things.Channel{}
+	for i := 0; i < 101; i++ {
+		sch, _ := svc.CreateChannel(context.Background(), token, channel)
+		svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+		sch, _ = svc.ViewChannel(context.Background(), token, sch.ID)
+		// must be "nulled" due to the JSON serialization that ignores owner
+		sch.Owner = ""
+		for j := range sch.Things {
+			sch.Things[j].Owner = ""
+		}
+		channels = append(channels, sch)
+	}
+	channelURL := fmt.Sprintf("%s/things/%s/channels", ts.URL, sth.ID)
+
+	cases := []struct {
+		desc   string
+		auth   string
+		status int
+		url    string
+		res    []things.Channel
+	}{
+		{"get a list of channels by thing", token, http.StatusOK, fmt.Sprintf("%s?offset=%d&limit=%d", channelURL, 0, 6), channels[0:6]},
+		{"get a list of channels by thing with invalid token", invalid, http.StatusForbidden, fmt.Sprintf("%s?offset=%d&limit=%d", channelURL, 0, 1), nil},
+		{"get a list of channels by thing with invalid offset", token, http.StatusBadRequest, fmt.Sprintf("%s?offset=%d&limit=%d", channelURL, -1, 5), nil},
+		{"get a list of channels by thing with zero limit", token, http.StatusBadRequest, fmt.Sprintf("%s?offset=%d&limit=%d", channelURL, 1, 0), nil},
+		{"get a list of channels by thing with default URL", token, http.StatusOK, channelURL, channels[0:10]},
+		{"get a list of channels by thing with invalid thing id", token, http.StatusNotFound, fmt.Sprintf("%s/things/%s/channels", ts.URL, invalid), nil},
+		{"get a list of channels by non-existent thing", token, http.StatusOK, fmt.Sprintf("%s/things/%s/channels", ts.URL, wrongID), []things.Channel{}},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    tc.url,
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		var body map[string][]things.Channel
+		json.NewDecoder(res.Body).Decode(&body)
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		assert.ElementsMatch(t, tc.res, body["channels"], fmt.Sprintf("%s: expected body %v got %v", tc.desc, tc.res, body["channels"]))
+	}
+}
+
+func TestListThingsByChannel(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	data := []things.Thing{}
+	for i := 0; i < 101; i++ {
+		sth, _ := svc.AddThing(context.Background(), token, thing)
+		svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+		// must be "nulled" due to the JSON serialization that ignores owner
+		sth.Owner = ""
+		data = append(data, sth)
+	}
+	thingURL := fmt.Sprintf("%s/channels/%s/things", ts.URL, sch.ID)
+
+	// the last thing is recently disconnected
+	svc.Disconnect(context.Background(), token, sch.ID, data[100].ID)
+
+	cases := []struct {
+		desc   string
+		auth   string
+		status int
+		url    string
+		res    []things.Thing
+	}{
+		{"get a list of things by channel", token, http.StatusOK, fmt.Sprintf("%s?offset=%d&limit=%d", thingURL, 0, 6), data[0:6]},
+		{"get a list of connected things by channel", token, http.StatusOK, fmt.Sprintf("%s?offset=%d&limit=%d&connected=true", thingURL, 95, 10), data[95:100]},
+		{"get a list of connected and disconnected things by channel", token, http.StatusOK, fmt.Sprintf("%s?offset=%d&limit=%d&connected=all", thingURL, 95, 10), data[95:101]},
+		{"get a list of disconnected things by channel", token, http.StatusOK, fmt.Sprintf("%s?connected=false", thingURL), data[100:101]},
+		{"get a list of things by channel with invalid connection state", token, http.StatusBadRequest, fmt.Sprintf("%s?connected=%s", thingURL, invalid), nil},
+		{"get a list of things by channel with multiple connection states", token, http.StatusBadRequest, fmt.Sprintf("%s?connected=true&connected=all", thingURL), nil},
+		{"get a list of things by channel with invalid token", invalid, http.StatusForbidden, fmt.Sprintf("%s?offset=%d&limit=%d", thingURL, 0, 1), nil},
+		{"get a list of things by channel with invalid offset", token, http.StatusBadRequest, fmt.Sprintf("%s?offset=%d&limit=%d", thingURL, -1, 5), nil},
+		{"get a list of things by channel with zero limit", token, http.StatusBadRequest, fmt.Sprintf("%s?offset=%d&limit=%d", thingURL, 1, 0), nil},
+		{"get a list of things by channel with default URL", token, http.StatusOK, thingURL, data[0:10]},
+		{"get a list of things by channel with invalid channel id", token, http.StatusNotFound, fmt.Sprintf("%s/channels/%s/things", ts.URL, invalid), nil},
+		{"get a list of things by non-existent channel", token, http.StatusOK, fmt.Sprintf("%s/channels/%s/things", ts.URL, wrongID), []things.Thing{}},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    tc.url,
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		var data thingsPageRes
+		json.NewDecoder(res.Body).Decode(&data)
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		assert.ElementsMatch(t, tc.res, data.Things, fmt.Sprintf("%s: expected body %v got %v", tc.desc, tc.res, data.Things))
+	}
+}
+
+func TestRemoveChannel(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+
+	cases := []struct {
+		desc   string
+		id     string
+		auth   string
+		status int
+	}{
+		{"remove existing channel", sch.ID, token, http.StatusNoContent},
+		{"remove non-existent channel", sch.ID, token, http.StatusNoContent},
+		{"remove channel with invalid id", wrongID, token, http.StatusNoContent},
+		{"remove channel with invalid token", sch.ID, invalid, http.StatusForbidden},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodDelete,
+			url:    fmt.Sprintf("%s/channels/%s", ts.URL, tc.id),
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+	}
+}
+
+func TestRemoveAllChannels(t *testing.T) {
+	otherToken := "other_token"
+	svc := newService(map[string]string{
+		token:      email,
+		otherToken: "other_user@example.com",
+	})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	for i := 0; i < 3; i++ {
+		svc.CreateChannel(context.Background(), token, channel)
+		svc.CreateChannel(context.Background(), otherToken, channel)
+	}
+
+	cases := []struct {
+		desc   string
+		auth   string
+		status int
+	}{
+		{"delete all channels with invalid token", invalid, http.StatusForbidden},
+		{"delete all channels with empty token", "", http.StatusForbidden},
+		{"delete all channels", token, http.StatusNoContent},
+		{"delete all channels of user without channels", token, http.StatusNoContent},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodDelete,
+			url:    fmt.Sprintf("%s/channels", ts.URL),
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+	}
+
+	count, _ := svc.CountChannels(context.Background(), token)
+	assert.Equal(t, 0, count, fmt.Sprintf("count removed channels: expected %d got %d", 0, count))
+
+	count, _ = svc.CountChannels(context.Background(), otherToken)
+	assert.Equal(t, 3, count, fmt.Sprintf("count other user's channels: expected %d got %d", 3, count))
+}
+
+func TestRestoreChannel(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	svc.RemoveChannel(context.Background(), token, sch.ID)
+
+	cases := []struct {
+		desc   string
+		id     string
+		auth   string
+		status int
+	}{
+		{"restore channel with invalid token", sch.ID, invalid, http.StatusForbidden},
+		{"restore removed channel", sch.ID, token, http.StatusOK},
+		{"restore restored channel", sch.ID, token, http.StatusNotFound},
+		{"restore non-existent channel", wrongID, token, http.StatusNotFound},
+		{"restore channel with invalid id", invalid, token, http.StatusNotFound},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodPost,
+			url:    fmt.Sprintf("%s/channels/%s/restore", ts.URL, tc.id),
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+	}
+}
+
+func TestTransferChannel(t *testing.T) {
+	otherToken := "other_token"
+	otherEmail := "other_user@example.com"
+	svc := newService(map[string]string{
+		token:      email,
+		otherToken: otherEmail,
+	})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	data := toJSON(map[string]string{"owner": otherEmail})
+
+	cases := []struct {
+		desc   string
+		req    string
+		id     string
+		auth   string
+		status int
+	}{
+		{"transfer channel with invalid token", data, sch.ID, invalid, http.StatusForbidden},
+		{"transfer non-existent channel", data, wrongID, token, http.StatusNotFound},
+		{"transfer channel with invalid owner", toJSON(map[string]string{"owner": invalid}), sch.ID, token, http.StatusUnprocessableEntity},
+		{"transfer existing channel", data, sch.ID, token, http.StatusOK},
+		{"transfer transferred channel", data, sch.ID, token, http.StatusNotFound},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client:      ts.Client(),
+			method:      http.MethodPost,
+			url:         fmt.Sprintf("%s/channels/%s/transfer", ts.URL, tc.id),
+			contentType: contentType,
+			token:       tc.auth,
+			body:        strings.NewReader(tc.req),
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+	}
+
+	_, err := svc.ViewChannel(context.Background(), otherToken, sch.ID)
+	assert.Nil(t, err, fmt.Sprintf("view transferred channel as new owner: unexpected error %s", err))
+}
+
+func TestConnect(t *testing.T) {
+	otherToken := "other_token"
+	otherEmail := "other_user@example.com"
+	svc := newService(map[string]string{
+		token:      email,
+		otherToken: otherEmail,
+	})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	ath, _ := svc.AddThing(context.Background(), token, thing)
+	bth, _ := svc.AddThing(context.Background(), otherToken, thing)
+	ach, _ := svc.CreateChannel(context.Background(), token, channel)
+	bch, _ := svc.CreateChannel(context.Background(), otherToken, channel)
+
+	cases := []struct {
+		desc    string
+		chanID  string
+		thingID string
+		auth    string
+		query   string
+		status  int
+		mode    string
+	}{
+		{"connect existing thing to existing channel", ach.ID, ath.ID, token, "", http.StatusCreated, "pubsub"},
+		{"connect existing thing to non-existent channel", wrongID, ath.ID, token, "", http.StatusNotFound, ""},
+		{"connect thing with invalid id to channel", ach.ID, invalid, token, "", http.StatusNotFound, ""},
+		{"connect thing to channel with invalid id", invalid, ath.ID, token, "", http.StatusNotFound, ""},
+		{"connect existing thing to existing channel with invalid token", ach.ID, ath.ID, invalid, "", http.StatusForbidden, ""},
+		{"connect thing from owner to channel of other user", bch.ID, ath.ID, token, "", http.StatusForbidden, ""},
+		{"connect thing of other user to owner's channel", ach.ID, bth.ID, token, "", http.StatusForbidden, ""},
+		{"connect thing to channel in pub mode", ach.ID, ath.ID, token, "?mode=pub", http.StatusCreated, "pub"},
+		{"connect thing to channel in sub mode", ach.ID, ath.ID, token, "?mode=sub", http.StatusCreated, "sub"},
+		{"connect thing to channel in invalid mode", ach.ID, ath.ID, token, "?mode=invalid", http.StatusBadRequest, ""},
+		{"connect thing to channel in multiple modes", ach.ID, ath.ID, token, "?mode=pub&mode=sub", http.StatusBadRequest, ""},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodPut,
+			url:    fmt.Sprintf("%s/channels/%s/things/%s%s", ts.URL, tc.chanID, tc.thingID, tc.query),
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		if tc.status != http.StatusCreated {
+			continue
+		}
+
+		location := res.Header.Get("Location")
+		expected := fmt.Sprintf("/channels/%s/things/%s", tc.chanID, tc.thingID)
+		assert.Equal(t, expected, location, fmt.Sprintf("%s: expected location %s got %s", tc.desc, expected, location))
+
+		var body struct {
+			ChanID      string    `json:"channel_id"`
+			ThingID     string    `json:"thing_id"`
+			Mode        string    `json:"mode"`
+			ConnectedAt time.Time `json:"connected_at"`
+		}
+		err = json.NewDecoder(res.Body).Decode(&body)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.chanID, body.ChanID, fmt.Sprintf("%s: expected channel %s got %s", tc.desc, tc.chanID, body.ChanID))
+		assert.Equal(t, tc.thingID, body.ThingID, fmt.Sprintf("%s: expected thing %s got %s", tc.desc, tc.thingID, body.ThingID))
+		assert.Equal(t, tc.mode, body.Mode, fmt.Sprintf("%s: expected mode %s got %s", tc.desc, tc.mode, body.Mode))
+		assert.False(t, body.ConnectedAt.IsZero(), fmt.Sprintf("%s: expected connection time to be set", tc.desc))
+	}
+}
+
+func TestConnectMany(t *testing.T) {
+	otherToken := "other_token"
+	otherEmail := "other_user@example.com"
+	svc := newService(map[string]string{
+		token:      email,
+		otherToken: otherEmail,
+	})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	ath, _ := svc.AddThing(context.Background(), token, thing)
+	ach, _ := svc.CreateChannel(context.Background(), token, channel)
+	bch, _ := svc.CreateChannel(context.Background(), token, channel)
+	och, _ := svc.CreateChannel(context.Background(), otherToken, channel)
+
+	data := toJSON([]string{ach.ID, bch.ID})
+	otherData := toJSON([]string{ach.ID, och.ID})
+	invalidData := toJSON([]string{ach.ID, invalid})
+
+	cases := []struct {
+		desc        string
+		req         string
+		thingID     string
+		contentType string
+		auth        string
+		status      int
+	}{
+		{"connect existing thing to existing channels", data, ath.ID, contentType, token, http.StatusOK},
+		{"connect non-existent thing to existing channels", data, wrongID, contentType, token, http.StatusNotFound},
+		{"connect thing with invalid id to channels", data, invalid, contentType, token, http.StatusNotFound},
+		{"connect thing to channel with invalid id", invalidData, ath.ID, contentType, token, http.StatusNotFound},
+		{"connect thing to channel of other user", otherData, ath.ID, contentType, token, http.StatusNotFound},
+		{"connect thing with invalid token", data, ath.ID, contentType, invalid, http.StatusForbidden},
+		{"connect thing with empty list of channels", "[]", ath.ID, contentType, token, http.StatusUnprocessableEntity},
+		{"connect thing with invalid data format", "{", ath.ID, contentType, token, http.StatusBadRequest},
+		{"connect thing with missing content type", data, ath.ID, "", token, http.StatusUnsupportedMediaType},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client:      ts.Client(),
+			method:      http.MethodPut,
+			url:         fmt.Sprintf("%s/things/%s/channels", ts.URL, tc.thingID),
+			contentType: tc.contentType,
+			token:       tc.auth,
+			body:        strings.NewReader(tc.req),
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+	}
+}
+
+func TestConnectThings(t *testing.T) {
+	otherToken := "other_token"
+	otherEmail := "other_user@example.com"
+	svc := newService(map[string]string{
+		token:      email,
+		otherToken: otherEmail,
+	})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	ath, _ := svc.AddThing(context.Background(), token, thing)
+	bth, _ := svc.AddThing(context.Background(), token, thing)
+	oth, _ := svc.AddThing(context.Background(), otherToken, thing)
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+
+	data := toJSON([]string{ath.ID, bth.ID})
+	otherData := toJSON([]string{ath.ID, oth.ID})
+	invalidData := toJSON([]string{ath.ID, invalid})
+
+	cases := []struct {
+		desc        string
+		req         string
+		chanID      string
+		contentType string
+		auth        string
+		status      int
+	}{
+		{"connect existing things to existing channel", data, sch.ID, contentType, token, http.StatusOK},
+		{"connect existing things to non-existent channel", data, wrongID, contentType, token, http.StatusNotFound},
+		{"connect things to channel with invalid id", data, invalid, contentType, token, http.StatusNotFound},
+		{"connect thing with invalid id to channel", invalidData, sch.ID, contentType, token, http.StatusNotFound},
+		{"connect thing of other user to channel", otherData, sch.ID, contentType, token, http.StatusNotFound},
+		{"connect things with invalid token", data, sch.ID, contentType, invalid, http.StatusForbidden},
+		{"connect empty list of things", "[]", sch.ID, contentType, token, http.StatusUnprocessableEntity},
+		{"connect things with invalid data format", "{", sch.ID, contentType, token, http.StatusBadRequest},
+		{"connect things with missing content type", data, sch.ID, "", token, http.StatusUnsupportedMediaType},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client:      ts.Client(),
+			method:      http.MethodPut,
+			url:         fmt.Sprintf("%s/channels/%s/things", ts.URL, tc.chanID),
+			contentType: tc.contentType,
+			token:       tc.auth,
+			body:        strings.NewReader(tc.req),
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+	}
+}
+
+func TestSetChannelThings(t *testing.T) {
+	otherToken := "other_token"
+	otherEmail := "other_user@example.com"
+	svc := newService(map[string]string{
+		token:      email,
+		otherToken: otherEmail,
+	})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	ath, _ := svc.AddThing(context.Background(), token, thing)
+	bth, _ := svc.AddThing(context.Background(), token, thing)
+	cth, _ := svc.AddThing(context.Background(), token, thing)
+	oth, _ := svc.AddThing(context.Background(), otherToken, thing)
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	svc.ConnectThings(context.Background(), token, sch.ID, []string{ath.ID, bth.ID})
+
+	data := toJSON([]string{bth.ID, cth.ID})
+	otherData := toJSON([]string{ath.ID, oth.ID})
+	invalidData := toJSON([]string{ath.ID, invalid})
+
+	cases := []struct {
+		desc        string
+		req         string
+		chanID      string
+		contentType string
+		auth        string
+		status      int
+		connected   []string
+	}{
+		{"set things of channel", data, sch.ID, contentType, token, http.StatusOK, []string{bth.ID, cth.ID}},
+		{"set same things of channel", data, sch.ID, contentType, token, http.StatusOK, []string{bth.ID, cth.ID}},
+		{"set things of non-existent channel", data, wrongID, contentType, token, http.StatusNotFound, []string{bth.ID, cth.ID}},
+		{"set things of channel with invalid id", data, invalid, contentType, token, http.StatusNotFound, []string{bth.ID, cth.ID}},
+		{"set thing with invalid id", invalidData, sch.ID, contentType, token, http.StatusNotFound, []string{bth.ID, cth.ID}},
+		{"set thing of other user", otherData, sch.ID, contentType, token, http.StatusNotFound, []string{bth.ID, cth.ID}},
+		{"set things with invalid token", data, sch.ID, contentType, invalid, http.StatusForbidden, []string{bth.ID, cth.ID}},
+		{"set things with missing list", "null", sch.ID, contentType, token, http.StatusUnprocessableEntity, []string{bth.ID, cth.ID}},
+		{"set things with invalid data format", "{", sch.ID, contentType, token, http.StatusBadRequest, []string{bth.ID, cth.ID}},
+		{"set things with missing content type", data, sch.ID, "", token, http.StatusUnsupportedMediaType, []string{bth.ID, cth.ID}},
+		{"set empty list of things", "[]", sch.ID, contentType, token, http.StatusOK, []string{}},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client:      ts.Client(),
+			method:      http.MethodPost,
+			url:         fmt.Sprintf("%s/channels/%s/things/sync", ts.URL, tc.chanID),
+			contentType: tc.contentType,
+			token:       tc.auth,
+			body:        strings.NewReader(tc.req),
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+
+		ths, _ := svc.ListThingsByChannel(context.Background(), token, sch.ID, 0, 10, things.StateConnected)
+		connected := []string{}
+		for _, th := range ths {
+			connected = append(connected, th.ID)
+		}
+		assert.ElementsMatch(t, tc.connected, connected, fmt.Sprintf("%s: expected connected things %v got %v", tc.desc, tc.connected, connected))
+	}
+}
+
+func TestIsConnected(t *testing.T) {
+	otherToken := "other_token"
+	otherEmail := "other_user@example.com"
+	svc := newService(map[string]string{
+		token:      email,
+		otherToken: otherEmail,
+	})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	ath, _ := svc.AddThing(context.Background(), token, thing)
+	ach, _ := svc.CreateChannel(context.Background(), token, channel)
+	svc.Connect(context.Background(), token, ach.ID, ath.ID, things.AccessPubSub)
+	bch, _ := svc.CreateChannel(context.Background(), token, channel)
+	cch, _ := svc.CreateChannel(context.Background(), otherToken, channel)
+
+	cases := []struct {
+		desc      string
+		chanID    string
+		thingID   string
+		auth      string
+		status    int
+		connected bool
 	}{
-		{"connect existing thing to existing channel", ach.ID, ath.ID, token, http.StatusOK},
-		{"connect existing thing to non-existent channel", wrongID, ath.ID, token, http.StatusNotFound},
-		{"connect thing with invalid id to channel", ach.ID, invalid, token, http.StatusNotFound},
-		{"connect thing to channel with invalid id", invalid, ath.ID, token, http.StatusNotFound},
-		{"connect existing thing to existing channel with invalid token", ach.ID, ath.ID, invalid, http.StatusForbidden},
-		{"connect thing from owner to channel of other user", bch.ID, ath.ID, token, http.StatusNotFound},
+		{"check connected thing", ach.ID, ath.ID, token, http.StatusOK, true},
+		{"check non-connected thing", bch.ID, ath.ID, token, http.StatusNotFound, false},
+		{"check non-existent thing", ach.ID, wrongID, token, http.StatusNotFound, false},
+		{"check thing with invalid id", ach.ID, invalid, token, http.StatusNotFound, false},
+		{"check non-existent channel", wrongID, ath.ID, token, http.StatusNotFound, false},
+		{"check owner's thing in someone else's channel", cch.ID, ath.ID, token, http.StatusNotFound, false},
+		{"check connection with invalid token", ach.ID, ath.ID, invalid, http.StatusForbidden, false},
+		{"check connection with empty token", ach.ID, ath.ID, "", http.StatusForbidden, false},
 	}
 
 	for _, tc := range cases {
 		req := testRequest{
 			client: ts.Client(),
-			method: http.MethodPut,
+			method: http.MethodGet,
 			url:    fmt.Sprintf("%s/channels/%s/things/%s", ts.URL, tc.chanID, tc.thingID),
 			token:  tc.auth,
 		}
 		res, err := req.make()
 		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
 		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+
+		var body struct {
+			Connected bool `json:"connected"`
+		}
+		json.NewDecoder(res.Body).Decode(&body)
+		assert.Equal(t, tc.connected, body.Connected, fmt.Sprintf("%s: expected connected %t got %t", tc.desc, tc.connected, body.Connected))
 	}
 }
 
@@ -547,10 +3350,10 @@ func TestDisconnnect(t *testing.T) {
 	ts := newServer(svc)
 	defer ts.Close()
 
-	ath, _ := svc.AddThing(token, thing)
-	ach, _ := svc.CreateChannel(token, channel)
-	svc.Connect(token, ach.ID, ath.ID)
-	bch, _ := svc.CreateChannel(otherToken, channel)
+	ath, _ := svc.AddThing(context.Background(), token, thing)
+	ach, _ := svc.CreateChannel(context.Background(), token, channel)
+	svc.Connect(context.Background(), token, ach.ID, ath.ID, things.AccessPubSub)
+	bch, _ := svc.CreateChannel(context.Background(), otherToken, channel)
 
 	cases := []struct {
 		desc    string
@@ -559,12 +3362,12 @@ func TestDisconnnect(t *testing.T) {
 		auth    string
 		status  int
 	}{
-		{"disconnect connected thing from channel", ach.ID, ath.ID, token, http.StatusNoContent},
+		{"disconnect connected thing from channel", ach.ID, ath.ID, token, http.StatusOK},
 		{"disconnect non-connected thing from channel", ach.ID, ath.ID, token, http.StatusNotFound},
 		{"disconnect non-existent thing from channel", ach.ID, invalid, token, http.StatusNotFound},
 		{"disconnect thing from non-existent channel", invalid, ath.ID, token, http.StatusNotFound},
 		{"disconnect thing from channel with invalid token", ach.ID, ath.ID, invalid, http.StatusForbidden},
-		{"disconnect owner's thing from someone elses channel", bch.ID, ath.ID, token, http.StatusNotFound},
+		{"disconnect owner's thing from someone elses channel", bch.ID, ath.ID, token, http.StatusForbidden},
 	}
 
 	for _, tc := range cases {
@@ -579,3 +3382,484 @@ func TestDisconnnect(t *testing.T) {
 		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
 	}
 }
+
+func TestIdempotentDisconnect(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := httptest.NewServer(httpapi.MakeHandler(svc, mocks.NewIdentityProvider(), []string{origin}, httpapi.WithIdempotentDisconnect()))
+	defer ts.Close()
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+
+	cases := []struct {
+		desc         string
+		thingID      string
+		status       int
+		disconnected bool
+	}{
+		{"disconnect connected thing from channel", sth.ID, http.StatusOK, true},
+		{"disconnect non-connected thing from channel", sth.ID, http.StatusOK, false},
+		{"disconnect non-existent thing from channel", wrongID, http.StatusNotFound, false},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodDelete,
+			url:    fmt.Sprintf("%s/channels/%s/things/%s", ts.URL, sch.ID, tc.thingID),
+			token:  token,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+
+		var body struct {
+			Disconnected bool `json:"disconnected"`
+		}
+		json.NewDecoder(res.Body).Decode(&body)
+		assert.Equal(t, tc.disconnected, body.Disconnected, fmt.Sprintf("%s: expected disconnected %t got %t", tc.desc, tc.disconnected, body.Disconnected))
+	}
+}
+
+func TestAccess(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	cth, _ := svc.AddThing(context.Background(), token, thing)
+	oth, _ := svc.AddThing(context.Background(), token, thing)
+	dth, _ := svc.AddThing(context.Background(), token, thing)
+	pth, _ := svc.AddThing(context.Background(), token, thing)
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	svc.Connect(context.Background(), token, sch.ID, cth.ID, things.AccessPubSub)
+	svc.Connect(context.Background(), token, sch.ID, dth.ID, things.AccessPubSub)
+	svc.Connect(context.Background(), token, sch.ID, pth.ID, things.AccessPub)
+	svc.DisableThing(context.Background(), token, dth.ID)
+
+	cases := []struct {
+		desc    string
+		key     string
+		chanID  string
+		query   string
+		status  int
+		thingID string
+	}{
+		{"access channel by connected thing", cth.Key, sch.ID, "", http.StatusOK, cth.ID},
+		{"access channel by unconnected thing", oth.Key, sch.ID, "", http.StatusForbidden, ""},
+		{"access channel by disabled thing", dth.Key, sch.ID, "", http.StatusForbidden, ""},
+		{"access channel with wrong key", invalid, sch.ID, "", http.StatusForbidden, ""},
+		{"access channel with empty key", "", sch.ID, "", http.StatusForbidden, ""},
+		{"access non-existent channel", cth.Key, wrongID, "", http.StatusForbidden, ""},
+		{"access channel with invalid id", cth.Key, invalid, "", http.StatusForbidden, ""},
+		{"access channel with empty id", cth.Key, "", "", http.StatusForbidden, ""},
+		{"publish to channel by pub-only thing", pth.Key, sch.ID, "?mode=pub", http.StatusOK, pth.ID},
+		{"subscribe to channel by pub-only thing", pth.Key, sch.ID, "?mode=sub", http.StatusForbidden, ""},
+		{"access channel by pub-only thing", pth.Key, sch.ID, "", http.StatusForbidden, ""},
+		{"access channel in invalid mode", cth.Key, sch.ID, "?mode=invalid", http.StatusForbidden, ""},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client:    ts.Client(),
+			method:    http.MethodGet,
+			url:       fmt.Sprintf("%s/access%s", ts.URL, tc.query),
+			token:     tc.key,
+			channelID: tc.chanID,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+
+		thingID := res.Header.Get("X-Thing-ID")
+		assert.Equal(t, tc.thingID, thingID, fmt.Sprintf("%s: expected thing id %s got %s", tc.desc, tc.thingID, thingID))
+
+		body, err := ioutil.ReadAll(res.Body)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Empty(t, body, fmt.Sprintf("%s: expected empty body got %s", tc.desc, body))
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	reqID := "5f1c8a2e-4b7d-4e3a-9c61-2d8f0b7a9e34"
+
+	cases := []struct {
+		desc      string
+		auth      string
+		requestID string
+		status    int
+	}{
+		{"list things with request ID", token, reqID, http.StatusOK},
+		{"list things with request ID and invalid token", invalid, reqID, http.StatusForbidden},
+		{"list things without request ID", token, "", http.StatusOK},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client:    ts.Client(),
+			method:    http.MethodGet,
+			url:       fmt.Sprintf("%s/things", ts.URL),
+			token:     tc.auth,
+			requestID: tc.requestID,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+
+		id := res.Header.Get("X-Request-ID")
+		if tc.requestID == "" {
+			assert.NotEmpty(t, id, fmt.Sprintf("%s: expected generated request ID", tc.desc))
+			continue
+		}
+		assert.Equal(t, tc.requestID, id, fmt.Sprintf("%s: expected request ID %s got %s", tc.desc, tc.requestID, id))
+	}
+}
+
+func TestCORS(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	cases := []struct {
+		desc    string
+		method  string
+		url     string
+		origin  string
+		status  int
+		allowed string
+		methods string
+	}{
+		{"preflight things request", http.MethodOptions, fmt.Sprintf("%s/things", ts.URL), origin, http.StatusNoContent, origin, "GET, POST, DELETE, OPTIONS"},
+		{"preflight thing request", http.MethodOptions, fmt.Sprintf("%s/things/%s", ts.URL, wrongID), origin, http.StatusNoContent, origin, "GET, PUT, PATCH, DELETE, HEAD, OPTIONS"},
+		{"preflight things count request", http.MethodOptions, fmt.Sprintf("%s/things/count", ts.URL), origin, http.StatusNoContent, origin, "GET, OPTIONS"},
+		{"preflight connection request", http.MethodOptions, fmt.Sprintf("%s/channels/%s/things/%s", ts.URL, wrongID, wrongID), origin, http.StatusNoContent, origin, "GET, PUT, DELETE, OPTIONS"},
+		{"preflight request from disallowed origin", http.MethodOptions, fmt.Sprintf("%s/things", ts.URL), "https://evil.example.com", http.StatusNoContent, "", "GET, POST, DELETE, OPTIONS"},
+		{"list things from allowed origin", http.MethodGet, fmt.Sprintf("%s/things", ts.URL), origin, http.StatusOK, origin, ""},
+		{"list things from disallowed origin", http.MethodGet, fmt.Sprintf("%s/things", ts.URL), "https://evil.example.com", http.StatusOK, "", ""},
+	}
+
+	for _, tc := range cases {
+		req, err := http.NewRequest(tc.method, tc.url, nil)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		req.Header.Set("Authorization", token)
+		req.Header.Set("Origin", tc.origin)
+
+		res, err := ts.Client().Do(req)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+
+		allowed := res.Header.Get("Access-Control-Allow-Origin")
+		assert.Equal(t, tc.allowed, allowed, fmt.Sprintf("%s: expected allowed origin %s got %s", tc.desc, tc.allowed, allowed))
+		methods := res.Header.Get("Access-Control-Allow-Methods")
+		assert.Equal(t, tc.methods, methods, fmt.Sprintf("%s: expected allowed methods %s got %s", tc.desc, tc.methods, methods))
+		if tc.allowed == "" {
+			continue
+		}
+
+		headers := res.Header.Get("Access-Control-Allow-Headers")
+		assert.True(t, strings.Contains(headers, "Authorization"), fmt.Sprintf("%s: expected Authorization in allowed headers %s", tc.desc, headers))
+		credentials := res.Header.Get("Access-Control-Allow-Credentials")
+		assert.Equal(t, "true", credentials, fmt.Sprintf("%s: expected credentials to be allowed got %s", tc.desc, credentials))
+	}
+}
+
+func TestCORSAnyOrigin(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := httptest.NewServer(httpapi.MakeHandler(svc, mocks.NewIdentityProvider(), []string{"*", origin}))
+	defer ts.Close()
+
+	cases := []struct {
+		desc        string
+		origin      string
+		allowed     string
+		credentials string
+	}{
+		{"list things from listed origin", origin, origin, "true"},
+		{"list things from any origin", "https://other.example.com", "*", ""},
+	}
+
+	for _, tc := range cases {
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/things", ts.URL), nil)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		req.Header.Set("Authorization", token)
+		req.Header.Set("Origin", tc.origin)
+
+		res, err := ts.Client().Do(req)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+
+		allowed := res.Header.Get("Access-Control-Allow-Origin")
+		assert.Equal(t, tc.allowed, allowed, fmt.Sprintf("%s: expected allowed origin %s got %s", tc.desc, tc.allowed, allowed))
+		credentials := res.Header.Get("Access-Control-Allow-Credentials")
+		assert.Equal(t, tc.credentials, credentials, fmt.Sprintf("%s: expected credentials header %q got %q", tc.desc, tc.credentials, credentials))
+	}
+}
+
+func TestCompression(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	for i := 0; i < 50; i++ {
+		svc.AddThing(context.Background(), token, thing)
+	}
+
+	listURL := fmt.Sprintf("%s/things?offset=%d&limit=%d", ts.URL, 0, 50)
+	countURL := fmt.Sprintf("%s/things/count", ts.URL)
+
+	cases := []struct {
+		desc     string
+		url      string
+		encoding string
+		gzipped  bool
+	}{
+		{"list things accepting gzip", listURL, "gzip", true},
+		{"list things accepting gzip among other encodings", listURL, "deflate, gzip;q=0.8", true},
+		{"list things without accepting gzip", listURL, "", false},
+		{"list things rejecting gzip", listURL, "gzip;q=0", false},
+		{"count things accepting gzip", countURL, "gzip", false},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    tc.url,
+			token:  token,
+		}
+		plain, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		expected, err := ioutil.ReadAll(plain.Body)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+
+		r, err := http.NewRequest(http.MethodGet, tc.url, nil)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		r.Header.Set("Authorization", token)
+		r.Header.Set("Accept-Encoding", tc.encoding)
+		res, err := ts.Client().Do(r)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, http.StatusOK, res.StatusCode))
+
+		encoding := res.Header.Get("Content-Encoding")
+		body := io.Reader(res.Body)
+		if tc.gzipped {
+			assert.Equal(t, "gzip", encoding, fmt.Sprintf("%s: expected gzip encoding got %s", tc.desc, encoding))
+			gr, err := gzip.NewReader(res.Body)
+			if !assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err)) {
+				continue
+			}
+			body = gr
+		} else {
+			assert.Empty(t, encoding, fmt.Sprintf("%s: expected no encoding got %s", tc.desc, encoding))
+		}
+
+		data, err := ioutil.ReadAll(body)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, string(expected), string(data), fmt.Sprintf("%s: expected body %s got %s", tc.desc, expected, data))
+	}
+}
+
+func TestHealth(t *testing.T) {
+	thingsRepo := mocks.NewThingRepository()
+	channelsRepo := mocks.NewChannelRepository(thingsRepo)
+	idp := mocks.NewIdentityProvider()
+
+	users := mocks.NewUsersService(map[string]string{token: email})
+
+	cases := []struct {
+		desc     string
+		users    mainflux.UsersServiceClient
+		things   things.ThingRepository
+		channels things.ChannelRepository
+		status   int
+		res      string
+	}{
+		{"check health with reachable users service", users, thingsRepo, channelsRepo, http.StatusOK, "pass"},
+		{"check health with unreachable users service", mocks.NewUnavailableUsersService(), thingsRepo, channelsRepo, http.StatusServiceUnavailable, "fail"},
+		{"check health with unreachable things storage", users, mocks.NewUnavailableThingRepository(thingsRepo), channelsRepo, http.StatusServiceUnavailable, "fail"},
+		{"check health with unreachable channels storage", users, thingsRepo, mocks.NewUnavailableChannelRepository(channelsRepo), http.StatusServiceUnavailable, "fail"},
+	}
+
+	for _, tc := range cases {
+		ts := newServer(things.New(tc.users, tc.things, tc.channels, idp))
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    fmt.Sprintf("%s/health", ts.URL),
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+
+		var body struct {
+			Status string `json:"status"`
+		}
+		json.NewDecoder(res.Body).Decode(&body)
+		assert.Equal(t, tc.res, body.Status, fmt.Sprintf("%s: expected status %s got %s", tc.desc, tc.res, body.Status))
+		ts.Close()
+	}
+}
+
+func TestUsersServiceUnavailable(t *testing.T) {
+	thingsRepo := mocks.NewThingRepository()
+	channelsRepo := mocks.NewChannelRepository(thingsRepo)
+	svc := things.New(mocks.NewUnavailableUsersService(), thingsRepo, channelsRepo, mocks.NewIdentityProvider())
+	ts := newServer(svc)
+	defer ts.Close()
+
+	req := testRequest{
+		client: ts.Client(),
+		method: http.MethodGet,
+		url:    fmt.Sprintf("%s/things", ts.URL),
+		token:  token,
+	}
+	res, err := req.make()
+	assert.Nil(t, err, fmt.Sprintf("list things: unexpected error %s", err))
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode, fmt.Sprintf("list things: expected status code %d got %d", http.StatusServiceUnavailable, res.StatusCode))
+
+	data, err := ioutil.ReadAll(res.Body)
+	assert.Nil(t, err, fmt.Sprintf("list things: unexpected error %s", err))
+	expected := errorJSON(things.ErrUnavailable, "unavailable")
+	body := strings.Trim(string(data), "\n")
+	assert.Equal(t, expected, body, fmt.Sprintf("list things: expected body %s got %s", expected, body))
+}
+
+func TestDisconnectMany(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	ath, _ := svc.AddThing(context.Background(), token, thing)
+	ach, _ := svc.CreateChannel(context.Background(), token, channel)
+	bch, _ := svc.CreateChannel(context.Background(), token, channel)
+	cch, _ := svc.CreateChannel(context.Background(), token, channel)
+	svc.Connect(context.Background(), token, ach.ID, ath.ID, things.AccessPubSub)
+	svc.Connect(context.Background(), token, bch.ID, ath.ID, things.AccessPubSub)
+
+	notConnectedErr := things.NotConnectedError{ChanIDs: []string{cch.ID}}
+
+	cases := []struct {
+		desc        string
+		req         string
+		thingID     string
+		contentType string
+		auth        string
+		status      int
+		res         string
+	}{
+		{"disconnect thing from connected and non-connected channels", toJSON([]string{ach.ID, cch.ID}), ath.ID, contentType, token, http.StatusNotFound, errorJSON(notConnectedErr, "not_connected")},
+		{"disconnect thing from connected channels", toJSON([]string{bch.ID}), ath.ID, contentType, token, http.StatusNoContent, ""},
+		{"disconnect non-existent thing from channels", toJSON([]string{ach.ID}), wrongID, contentType, token, http.StatusNotFound, errorJSON(things.ErrNotFound, "not_found")},
+		{"disconnect thing with invalid id from channels", toJSON([]string{ach.ID}), invalid, contentType, token, http.StatusNotFound, errorJSON(things.ErrNotFound, "not_found")},
+		{"disconnect thing from channel with invalid id", toJSON([]string{ach.ID, invalid}), ath.ID, contentType, token, http.StatusNotFound, errorJSON(things.ErrNotFound, "not_found")},
+		{"disconnect thing from channels with invalid token", toJSON([]string{ach.ID}), ath.ID, contentType, invalid, http.StatusForbidden, errorJSON(things.ErrUnauthorizedAccess, "unauthorized")},
+		{"disconnect thing from empty list of channels", "[]", ath.ID, contentType, token, http.StatusUnprocessableEntity, errorJSON(things.ErrMalformedEntity, "malformed_entity")},
+		{"disconnect thing with missing content type", toJSON([]string{ach.ID}), ath.ID, "", token, http.StatusUnsupportedMediaType, `{"error":"unsupported content type","code":"unsupported_content_type"}`},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client:      ts.Client(),
+			method:      http.MethodDelete,
+			url:         fmt.Sprintf("%s/things/%s/channels", ts.URL, tc.thingID),
+			contentType: tc.contentType,
+			token:       tc.auth,
+			body:        strings.NewReader(tc.req),
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+
+		body, err := ioutil.ReadAll(res.Body)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		data := strings.Trim(string(body), "\n")
+		assert.Equal(t, tc.res, data, fmt.Sprintf("%s: expected body %s got %s", tc.desc, tc.res, data))
+	}
+
+	for _, id := range []string{ach.ID, bch.ID} {
+		_, err := svc.CanAccess(context.Background(), ath.Key, id, things.AccessPubSub)
+		assert.Equal(t, things.ErrUnauthorizedAccess, err, fmt.Sprintf("check disconnected thing: expected %s got %s", things.ErrUnauthorizedAccess, err))
+	}
+}
+
+func TestDisconnectAll(t *testing.T) {
+	otherToken := "other_token"
+	otherEmail := "other_user@example.com"
+	svc := newService(map[string]string{
+		token:      email,
+		otherToken: otherEmail,
+	})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	chs := []things.Channel{}
+	for i := 0; i < 3; i++ {
+		sch, _ := svc.CreateChannel(context.Background(), token, channel)
+		svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+		chs = append(chs, sch)
+	}
+
+	cases := []struct {
+		desc    string
+		thingID string
+		auth    string
+		status  int
+	}{
+		{"disconnect thing from all channels", sth.ID, token, http.StatusNoContent},
+		{"disconnect non-connected thing from all channels", sth.ID, token, http.StatusNoContent},
+		{"disconnect non-existent thing from all channels", wrongID, token, http.StatusNotFound},
+		{"disconnect thing with invalid id from all channels", invalid, token, http.StatusNotFound},
+		{"disconnect someone else's thing from all channels", sth.ID, otherToken, http.StatusNotFound},
+		{"disconnect thing from all channels with invalid token", sth.ID, invalid, http.StatusForbidden},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodDelete,
+			url:    fmt.Sprintf("%s/things/%s/channels", ts.URL, tc.thingID),
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+	}
+
+	for _, ch := range chs {
+		_, err := svc.CanAccess(context.Background(), sth.Key, ch.ID, things.AccessPubSub)
+		assert.Equal(t, things.ErrUnauthorizedAccess, err, fmt.Sprintf("check disconnected thing: expected %s got %s", things.ErrUnauthorizedAccess, err))
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	req := testRequest{
+		client: ts.Client(),
+		method: http.MethodGet,
+		url:    fmt.Sprintf("%s/openapi.json", ts.URL),
+	}
+	res, err := req.make()
+	assert.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("expected status code %d got %d", http.StatusOK, res.StatusCode))
+	assert.Equal(t, contentType, res.Header.Get("Content-Type"), fmt.Sprintf("expected content type %s got %s", contentType, res.Header.Get("Content-Type")))
+
+	var doc struct {
+		OpenAPI string                     `json:"openapi"`
+		Paths   map[string]json.RawMessage `json:"paths"`
+	}
+	err = json.NewDecoder(res.Body).Decode(&doc)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	assert.True(t, strings.HasPrefix(doc.OpenAPI, "3."), fmt.Sprintf("expected OpenAPI 3 document got version %s", doc.OpenAPI))
+
+	for _, path := range []string{"/things", "/channels/{chanId}/things/{thingId}"} {
+		_, ok := doc.Paths[path]
+		assert.True(t, ok, fmt.Sprintf("expected %s to be documented", path))
+	}
+}
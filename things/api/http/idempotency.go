@@ -0,0 +1,162 @@
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/mainflux/mainflux/things"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// unstoredHeaders lists the headers describing the encoding of the response
+// to the particular request, which is applied anew to the replayed responses.
+var unstoredHeaders = map[string]bool{
+	"Content-Encoding": true,
+	"Content-Length":   true,
+	"Vary":             true,
+}
+
+var (
+	errRequestInProgress = errors.New("request with the same idempotency key in progress")
+	errCorruptResponse   = errors.New("corrupt stored response")
+	errKeyReused         = errors.New("idempotency key reused with different request body")
+)
+
+// storedResponse is the response to the request carrying the idempotency
+// key, as kept by the idempotency store, along with the hash of the request's
+// body.
+type storedResponse struct {
+	Request []byte      `json:"request"`
+	Status  int         `json:"status"`
+	Header  http.Header `json:"header"`
+	Body    []byte      `json:"body"`
+}
+
+// idempotent answers the retries of the requests carrying the same
+// Idempotency-Key header with the response to the first one, for as long as
+// the key is kept by the store. The keys are scoped by the credentials, so
+// the users can't replay each other's responses. Reusing the key for the
+// request having a different body is rejected, rather than answered with the
+// response to the other request. The server errors aren't kept, so that the
+// retries of the failed requests are processed again. Only the first
+// maxBodySize bytes of the body, the most the handler accepts, are hashed.
+func idempotent(next http.Handler, store things.IdempotencyStore, ttl time.Duration, maxBodySize int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyKeyHeader)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+		if err != nil {
+			encodeError(r.Context(), io.ErrUnexpectedEOF, w)
+			return
+		}
+		r.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+		hash := sha256.Sum256(body)
+
+		key = r.Header.Get("Authorization") + "\n" + key
+		switch err := store.Reserve(key, ttl); err {
+		case nil:
+		case things.ErrConflict:
+			replay(w, r, store, key, hash[:])
+			return
+		default:
+			encodeError(r.Context(), err, w)
+			return
+		}
+
+		rw := &recordingWriter{ResponseWriter: w, preset: map[string]bool{}}
+		for name := range w.Header() {
+			rw.preset[name] = true
+		}
+
+		next.ServeHTTP(rw, r)
+
+		if rw.status == 0 || rw.status >= http.StatusInternalServerError {
+			store.Release(key)
+			return
+		}
+
+		res := storedResponse{Request: hash[:], Status: rw.status, Header: http.Header{}, Body: rw.body}
+		for name, values := range w.Header() {
+			if !rw.preset[name] && !unstoredHeaders[name] {
+				res.Header[name] = values
+			}
+		}
+
+		data, err := json.Marshal(res)
+		if err != nil {
+			store.Release(key)
+			return
+		}
+
+		if err := store.Save(key, data); err != nil {
+			store.Release(key)
+		}
+	})
+}
+
+// replay writes the stored response to the request claiming the provided
+// key, as long as the request's body has the provided hash. If the request is
+// still being processed, the conflict is reported.
+func replay(w http.ResponseWriter, r *http.Request, store things.IdempotencyStore, key string, hash []byte) {
+	data, err := store.Response(key)
+	if err == things.ErrNotFound {
+		encodeError(r.Context(), errRequestInProgress, w)
+		return
+	}
+	if err != nil {
+		encodeError(r.Context(), err, w)
+		return
+	}
+
+	var res storedResponse
+	if err := json.Unmarshal(data, &res); err != nil {
+		encodeError(r.Context(), errCorruptResponse, w)
+		return
+	}
+
+	if !bytes.Equal(res.Request, hash) {
+		encodeError(r.Context(), errKeyReused, w)
+		return
+	}
+
+	for name, values := range res.Header {
+		w.Header()[name] = values
+	}
+	w.WriteHeader(res.Status)
+	w.Write(res.Body)
+}
+
+// recordingWriter passes the response through, while recording its status
+// code and body.
+type recordingWriter struct {
+	http.ResponseWriter
+	preset map[string]bool
+	status int
+	body   []byte
+}
+
+func (rw *recordingWriter) WriteHeader(status int) {
+	if rw.status == 0 {
+		rw.status = status
+	}
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *recordingWriter) Write(b []byte) (int, error) {
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+	rw.body = append(rw.body, b...)
+	return rw.ResponseWriter.Write(b)
+}
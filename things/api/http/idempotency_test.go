@@ -0,0 +1,61 @@
+package http
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/things/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotentCompressedResponse(t *testing.T) {
+	body := strings.Repeat("a", 2*minGzipSize)
+	h := compress(idempotent(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, body)
+	}), cache.NewIdempotencyStore(), time.Minute, defMaxBodySize))
+
+	cases := []struct {
+		desc     string
+		encoding string
+	}{
+		{"respond accepting gzip", "gzip"},
+		{"replay without accepting gzip", ""},
+		{"replay accepting gzip", "gzip"},
+	}
+
+	for _, tc := range cases {
+		r := httptest.NewRequest(http.MethodPost, "/things", nil)
+		r.Header.Set(idempotencyKeyHeader, "key")
+		r.Header.Set("Accept-Encoding", tc.encoding)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusCreated, w.Code, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, http.StatusCreated, w.Code))
+		assert.Equal(t, "text/plain", w.Header().Get("Content-Type"), fmt.Sprintf("%s: expected replayed content type", tc.desc))
+
+		encoding := w.Header().Get("Content-Encoding")
+		assert.Equal(t, tc.encoding, encoding, fmt.Sprintf("%s: expected encoding %s got %s", tc.desc, tc.encoding, encoding))
+
+		rd := io.Reader(w.Body)
+		if encoding == "gzip" {
+			gr, err := gzip.NewReader(w.Body)
+			if !assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err)) {
+				continue
+			}
+			rd = gr
+		}
+
+		data, err := ioutil.ReadAll(rd)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, body, string(data), fmt.Sprintf("%s: expected body of %d bytes got %d", tc.desc, len(body), len(data)))
+	}
+}
@@ -0,0 +1,2950 @@
+package http
+
+import "net/http"
+
+const openAPIPath = "/openapi.json"
+
+func serveOpenAPI(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", contentType)
+	w.Write([]byte(openAPI))
+}
+
+// openAPI is the OpenAPI 3 document describing the service's HTTP API. It is
+// maintained along with swagger.yaml, and each route registered by makeRouter
+// must be documented in it (see TestOpenAPIRoutes).
+const openAPI = `{
+  "openapi": "3.0.1",
+  "info": {
+    "title": "Mainflux things service",
+    "description": "HTTP API for managing platform devices, applications and channels.\nFailed requests are described by the JSON-encoded ErrorRes document.\nRequests authorized by the user's access token fail with 503 if the users\nservice stays unreachable after all of the identification attempts.\n",
+    "version": "1.0.0"
+  },
+  "paths": {
+    "/things": {
+      "post": {
+        "summary": "Adds new thing",
+        "description": "Adds new thing to the list of things owned by user identified using\nthe provided access token. If the user already owns the thing with the\nprovided external ID, no new thing is added. If the ID is provided, the\nthing is stored under it instead of the generated one. If the idempotency\nkey is provided, the retries of the request carrying the same key are\nanswered with the response to the first one, without adding new things.\nReusing the key for the request having a different body is rejected.\n",
+        "tags": [
+          "things"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          },
+          {
+            "$ref": "#/components/parameters/IdempotencyKey"
+          }
+        ],
+        "requestBody": {
+          "description": "JSON-formatted document describing the new thing.",
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/ThingReq"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "Thing with the same external ID already registered.",
+            "headers": {
+              "Location": {
+                "description": "Existing thing's relative URL (i.e. /things/{thingId}).",
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "201": {
+            "description": "Thing registered.",
+            "headers": {
+              "Location": {
+                "description": "Created thing's relative URL (i.e. /things/{thingId}).",
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Failed due to malformed JSON."
+          },
+          "403": {
+            "description": "Missing or invalid access token provided."
+          },
+          "409": {
+            "description": "Thing with the same ID already registered, or the request with the\nsame idempotency key is still in progress.\n"
+          },
+          "413": {
+            "description": "Request body exceeding the size limit."
+          },
+          "415": {
+            "description": "Missing or invalid content type."
+          },
+          "422": {
+            "description": "Failed due to invalid thing, or due to reusing the idempotency key\nfor the request having a different body.\n"
+          },
+          "429": {
+            "description": "Failed due to exceeding the quota of the user."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      },
+      "get": {
+        "summary": "Retrieves managed things",
+        "description": "Retrieves a list of managed things. Due to performance concerns, data\nis retrieved in subsets. The API things must ensure that the entire\ndataset is consumed either by making subsequent requests, or by\nincreasing the subset size of the initial request. If any of the\nname, metadata, type, status or tag is provided, only things matching all\nof them are retrieved. Names are matched case insensitively as a part of\nthe name, while the other filters have to match exactly. Filtered\nthings are sorted and counted like all of the others. If the deleted\nflag is set, removed things are retrieved instead; it cannot be\ncombined with any of the filters. If the page token is provided,\nthings are retrieved sorted by their identifiers, starting after the\nlast thing of the previous page, and the token of the next page is\nreturned instead of the total and the navigation links. Empty token\nretrieves the first page. The page token can only be combined with the\nlimit. If the identifiers are provided, the things having them are\nretrieved in the same order, skipping the unknown ones, unless the\nservice is configured to reject them; they cannot be combined with\nany of the filters or the page token. If the connected flag is false,\nonly things that aren't connected to any channel are retrieved, sorted by\ntheir identifiers, and the total is omitted; it cannot be combined with\nany of the filters or the page token. If the fields are provided, only\nthose fields and the identifiers of the things are retrieved. If the\nAccept header lists text/csv, the things are retrieved as CSV having the\nid, name, key and status columns, without the paging details.\n",
+        "tags": [
+          "things"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          },
+          {
+            "$ref": "#/components/parameters/Limit"
+          },
+          {
+            "$ref": "#/components/parameters/Offset"
+          },
+          {
+            "$ref": "#/components/parameters/Order"
+          },
+          {
+            "$ref": "#/components/parameters/Dir"
+          },
+          {
+            "$ref": "#/components/parameters/Name"
+          },
+          {
+            "$ref": "#/components/parameters/Metadata"
+          },
+          {
+            "$ref": "#/components/parameters/Deleted"
+          },
+          {
+            "$ref": "#/components/parameters/Type"
+          },
+          {
+            "$ref": "#/components/parameters/Status"
+          },
+          {
+            "$ref": "#/components/parameters/Tag"
+          },
+          {
+            "$ref": "#/components/parameters/PageToken"
+          },
+          {
+            "$ref": "#/components/parameters/Ids"
+          },
+          {
+            "$ref": "#/components/parameters/Unconnected"
+          },
+          {
+            "$ref": "#/components/parameters/Fields"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Data retrieved.",
+            "headers": {
+              "X-Count": {
+                "description": "Total number of managed things. This value can be used to\nimplement the paging strategy on API things.\n",
+                "schema": {
+                  "type": "integer"
+                }
+              }
+            },
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ThingList"
+                }
+              },
+              "text/csv": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Failed due to malformed query parameters."
+          },
+          "403": {
+            "description": "Missing or invalid access token provided."
+          },
+          "404": {
+            "description": "Any of the requested things does not exist, if rejected."
+          },
+          "422": {
+            "description": "Failed due to unknown thing type or status."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      },
+      "delete": {
+        "summary": "Removes all things",
+        "description": "Removes all of the things owned by the user identified using the\nprovided access token, and disconnects them from all of the channels.\n",
+        "tags": [
+          "things"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          }
+        ],
+        "responses": {
+          "204": {
+            "description": "Things removed."
+          },
+          "403": {
+            "description": "Missing or invalid access token provided."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      }
+    },
+    "/things/identify": {
+      "post": {
+        "summary": "Identifies thing by its key",
+        "description": "Verifies the provided thing's access key and retrieves the identifier\nand the owner of the thing it belongs to. Unlike the other endpoints,\nit doesn't require the user's access token, so devices can use it to\ncheck their key before publishing any messages.\n",
+        "tags": [
+          "things"
+        ],
+        "requestBody": {
+          "description": "JSON-formatted document containing thing's access key.",
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/IdentifyReq"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "Thing identified.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ThingIdentityRes"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Failed due to malformed JSON."
+          },
+          "401": {
+            "description": "Missing or invalid key, or the thing is disabled."
+          },
+          "413": {
+            "description": "Request body exceeding the size limit."
+          },
+          "415": {
+            "description": "Missing or invalid content type."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      }
+    },
+    "/things/bulk": {
+      "post": {
+        "summary": "Adds multiple things",
+        "description": "Adds all of the provided things to the list of things owned by user\nidentified using the provided access token. Things are either created\nall at once, or none of them is created. If dry run is requested, the\nthings are validated and returned as they would be created, but none\nof them is persisted.\n",
+        "tags": [
+          "things"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          },
+          {
+            "$ref": "#/components/parameters/DryRun"
+          }
+        ],
+        "requestBody": {
+          "description": "JSON-formatted array of documents describing the new things.",
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "type": "array",
+                "minItems": 1,
+                "items": {
+                  "$ref": "#/components/schemas/ThingReq"
+                }
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "Things validated without being registered.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ThingList"
+                }
+              }
+            }
+          },
+          "201": {
+            "description": "Things registered.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ThingList"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Failed due to malformed JSON or invalid query parameters."
+          },
+          "403": {
+            "description": "Missing or invalid access token provided."
+          },
+          "409": {
+            "description": "Failed due to the identifier collision during dry run."
+          },
+          "413": {
+            "description": "Request body exceeding the size limit."
+          },
+          "415": {
+            "description": "Missing or invalid content type."
+          },
+          "422": {
+            "description": "Failed due to empty list of things or any of them being invalid."
+          },
+          "429": {
+            "description": "Failed due to exceeding the quota of the user."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      }
+    },
+    "/things/count": {
+      "get": {
+        "summary": "Retrieves the number of managed things",
+        "description": "Retrieves the number of things owned by the user, that match all of\nthe provided filters. Removed things are not counted.\n",
+        "tags": [
+          "things"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          },
+          {
+            "$ref": "#/components/parameters/Name"
+          },
+          {
+            "$ref": "#/components/parameters/Type"
+          },
+          {
+            "$ref": "#/components/parameters/Status"
+          },
+          {
+            "$ref": "#/components/parameters/Tag"
+          },
+          {
+            "$ref": "#/components/parameters/Metadata"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Data retrieved.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/CountRes"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Failed due to malformed query parameters."
+          },
+          "403": {
+            "description": "Missing or invalid access token provided."
+          },
+          "422": {
+            "description": "Unsupported thing type or status provided."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      }
+    },
+    "/things/{thingId}": {
+      "get": {
+        "summary": "Retrieves thing info",
+        "description": "Retrieves thing info, tagged with the ETag that changes whenever the\nthing is updated. If the provided If-None-Match header matches the\ncurrent tag, no data is retrieved. If the fields are provided, only\nthose fields and the identifier of the thing are retrieved. If the\nchannels are included, the thing's connected channels are retrieved\nalong with it, without the ETag.\n",
+        "tags": [
+          "things"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          },
+          {
+            "$ref": "#/components/parameters/ThingId"
+          },
+          {
+            "$ref": "#/components/parameters/IfNoneMatch"
+          },
+          {
+            "$ref": "#/components/parameters/Fields"
+          },
+          {
+            "$ref": "#/components/parameters/ThingInclude"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Data retrieved.",
+            "headers": {
+              "ETag": {
+                "description": "Current tag of the thing.",
+                "schema": {
+                  "type": "string"
+                }
+              }
+            },
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ThingRes"
+                }
+              }
+            }
+          },
+          "304": {
+            "description": "Thing is not modified.",
+            "headers": {
+              "ETag": {
+                "description": "Current tag of the thing.",
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Failed due to unknown fields."
+          },
+          "403": {
+            "description": "Missing or invalid access token provided."
+          },
+          "404": {
+            "description": "Thing does not exist."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      },
+      "head": {
+        "summary": "Checks thing existence",
+        "description": "Performs the same checks as the retrieval of the thing info, without\nretrieving any data.\n",
+        "tags": [
+          "things"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          },
+          {
+            "$ref": "#/components/parameters/ThingId"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Thing exists.",
+            "headers": {
+              "ETag": {
+                "description": "Current tag of the thing.",
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "403": {
+            "description": "Missing or invalid access token provided."
+          },
+          "404": {
+            "description": "Thing does not exist."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      },
+      "put": {
+        "summary": "Updates thing info",
+        "description": "Update is performed by replacing the current resource data with values\nprovided in a request payload. Note that the thing's type and ID\ncannot be changed. The provided If-Match header must match the current\nETag of the thing, unless it is the wildcard.\n",
+        "tags": [
+          "things"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          },
+          {
+            "$ref": "#/components/parameters/ThingId"
+          },
+          {
+            "$ref": "#/components/parameters/IfMatch"
+          }
+        ],
+        "requestBody": {
+          "description": "JSON-formatted document describing the updated thing.",
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/ThingReq"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "Thing updated."
+          },
+          "400": {
+            "description": "Failed due to malformed JSON."
+          },
+          "403": {
+            "description": "Missing or invalid access token provided."
+          },
+          "404": {
+            "description": "Thing does not exist."
+          },
+          "412": {
+            "description": "Thing was modified since the provided ETag was retrieved."
+          },
+          "413": {
+            "description": "Request body exceeding the size limit."
+          },
+          "415": {
+            "description": "Missing or invalid content type."
+          },
+          "422": {
+            "description": "Failed due to invalid thing."
+          },
+          "428": {
+            "description": "Missing If-Match header."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      },
+      "patch": {
+        "summary": "Partially updates thing info",
+        "description": "Update is performed by replacing only the fields provided in a request\npayload, while the omitted ones are left untouched.\n",
+        "tags": [
+          "things"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          },
+          {
+            "$ref": "#/components/parameters/ThingId"
+          }
+        ],
+        "requestBody": {
+          "description": "JSON-formatted document containing the fields to update.",
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/ThingPatchReq"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "Thing updated."
+          },
+          "400": {
+            "description": "Failed due to malformed JSON."
+          },
+          "403": {
+            "description": "Missing or invalid access token provided."
+          },
+          "404": {
+            "description": "Thing does not exist."
+          },
+          "412": {
+            "description": "Thing was modified concurrently."
+          },
+          "413": {
+            "description": "Request body exceeding the size limit."
+          },
+          "415": {
+            "description": "Missing or invalid content type."
+          },
+          "422": {
+            "description": "Failed due to no fields provided or invalid thing."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      },
+      "delete": {
+        "summary": "Removes a thing",
+        "description": "Removes a thing and disconnects it from all of the channels. Removed\nthing can be restored later on, but its connections are not.\n",
+        "tags": [
+          "things"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          },
+          {
+            "$ref": "#/components/parameters/ThingId"
+          }
+        ],
+        "responses": {
+          "204": {
+            "description": "Thing removed."
+          },
+          "403": {
+            "description": "Missing or invalid access token provided."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      }
+    },
+    "/things/{thingId}/disable": {
+      "post": {
+        "summary": "Disables thing",
+        "description": "Disables the thing. Disabled thing keeps its channel connections, but\nit cannot access any of the channels until it is enabled again.\n",
+        "tags": [
+          "things"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          },
+          {
+            "$ref": "#/components/parameters/ThingId"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Thing disabled."
+          },
+          "403": {
+            "description": "Missing or invalid access token provided."
+          },
+          "404": {
+            "description": "Thing does not exist."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      }
+    },
+    "/things/{thingId}/enable": {
+      "post": {
+        "summary": "Enables thing",
+        "description": "Enables previously disabled thing, allowing it to access the channels\nit is connected to.\n",
+        "tags": [
+          "things"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          },
+          {
+            "$ref": "#/components/parameters/ThingId"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Thing enabled."
+          },
+          "403": {
+            "description": "Missing or invalid access token provided."
+          },
+          "404": {
+            "description": "Thing does not exist."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      }
+    },
+    "/things/{thingId}/restore": {
+      "post": {
+        "summary": "Restores removed thing",
+        "description": "Restores previously removed thing, along with its channel connections.\n",
+        "tags": [
+          "things"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          },
+          {
+            "$ref": "#/components/parameters/ThingId"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Thing restored."
+          },
+          "403": {
+            "description": "Missing or invalid access token provided."
+          },
+          "404": {
+            "description": "Thing does not exist."
+          },
+          "409": {
+            "description": "Thing's external ID is taken by another thing."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      }
+    },
+    "/things/{thingId}/transfer": {
+      "post": {
+        "summary": "Transfers the thing to another user",
+        "description": "Transfers the thing to the registered user having the provided email.\nThe thing is disconnected from all of the channels along with the\ntransfer, unless the transfer fails.\n",
+        "tags": [
+          "things"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          },
+          {
+            "$ref": "#/components/parameters/ThingId"
+          }
+        ],
+        "requestBody": {
+          "description": "JSON-formatted document describing the new owner.",
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/TransferReq"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "Thing transferred."
+          },
+          "400": {
+            "description": "Failed due to malformed JSON."
+          },
+          "403": {
+            "description": "Missing or invalid access token provided."
+          },
+          "404": {
+            "description": "Thing does not exist."
+          },
+          "409": {
+            "description": "New owner already has the thing with the same ID or external ID.\n"
+          },
+          "413": {
+            "description": "Request body exceeding the size limit."
+          },
+          "415": {
+            "description": "Missing or invalid content type."
+          },
+          "422": {
+            "description": "Failed due to invalid or unregistered owner's email."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      }
+    },
+    "/things/{thingId}/key": {
+      "patch": {
+        "summary": "Updates thing's key",
+        "description": "Replaces the thing's access key with the provided one. Once the key is\nreplaced, the old key can no longer be used to access channels.\n",
+        "tags": [
+          "things"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          },
+          {
+            "$ref": "#/components/parameters/ThingId"
+          }
+        ],
+        "requestBody": {
+          "description": "JSON-formatted document containing the new key.",
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/KeyReq"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "Thing's key updated."
+          },
+          "400": {
+            "description": "Failed due to malformed JSON."
+          },
+          "403": {
+            "description": "Missing or invalid access token provided."
+          },
+          "404": {
+            "description": "Thing does not exist."
+          },
+          "409": {
+            "description": "Provided key is already in use."
+          },
+          "413": {
+            "description": "Request body exceeding the size limit."
+          },
+          "415": {
+            "description": "Missing or invalid content type."
+          },
+          "422": {
+            "description": "Failed due to missing key, or key of invalid length."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      }
+    },
+    "/things/{thingId}/key/rotate": {
+      "post": {
+        "summary": "Rotates thing's key",
+        "description": "Replaces the thing's access key with the newly generated one, and\nreturns it. Once the key is replaced, the old key can no longer be\nused to access channels.\n",
+        "tags": [
+          "things"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          },
+          {
+            "$ref": "#/components/parameters/ThingId"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Thing's key rotated.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/KeyRes"
+                }
+              }
+            }
+          },
+          "403": {
+            "description": "Missing or invalid access token provided."
+          },
+          "404": {
+            "description": "Thing does not exist."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      }
+    },
+    "/things/{thingId}/channels": {
+      "get": {
+        "summary": "Retrieves channels connected to the thing",
+        "description": "Retrieves a list of managed channels that have the specified thing\nconnected to them. Due to performance concerns, data is retrieved in\nsubsets.\n",
+        "tags": [
+          "things"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          },
+          {
+            "$ref": "#/components/parameters/ThingId"
+          },
+          {
+            "$ref": "#/components/parameters/Limit"
+          },
+          {
+            "$ref": "#/components/parameters/Offset"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Data retrieved.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ChannelList"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Failed due to malformed query parameters."
+          },
+          "403": {
+            "description": "Missing or invalid access token provided."
+          },
+          "404": {
+            "description": "Failed due to malformed thing's ID."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      },
+      "put": {
+        "summary": "Connects the thing to multiple channels",
+        "description": "Connects the specified thing to all of the listed channels at once.\nIf any of the channels does not exist, none of the connections is\nmade.\n",
+        "tags": [
+          "things"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          },
+          {
+            "$ref": "#/components/parameters/ThingId"
+          }
+        ],
+        "requestBody": {
+          "description": "JSON-formatted list of channel identifiers.",
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "type": "array",
+                "minItems": 1,
+                "items": {
+                  "type": "string",
+                  "format": "uuid"
+                }
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "Thing connected to all of the channels."
+          },
+          "400": {
+            "description": "Failed due to malformed JSON."
+          },
+          "403": {
+            "description": "Missing or invalid access token provided."
+          },
+          "404": {
+            "description": "Thing or any of the channels does not exist."
+          },
+          "413": {
+            "description": "Request body exceeding the size limit."
+          },
+          "415": {
+            "description": "Missing or invalid content type."
+          },
+          "422": {
+            "description": "Failed due to empty list of channels."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      },
+      "delete": {
+        "summary": "Disconnects the thing from channels",
+        "description": "Removes all of the connections of the specified thing. If the list of\nchannels is provided, the thing is disconnected only from the listed\nchannels. Disconnecting is attempted for each of them, and the ones\nthe thing was not connected to are listed in the not_connected error.\nOnce disconnected, thing can no longer exchange messages through the\nchannels.\n",
+        "tags": [
+          "things"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          },
+          {
+            "$ref": "#/components/parameters/ThingId"
+          }
+        ],
+        "requestBody": {
+          "description": "JSON-formatted list of channel identifiers.",
+          "required": false,
+          "content": {
+            "application/json": {
+              "schema": {
+                "type": "array",
+                "minItems": 1,
+                "items": {
+                  "type": "string",
+                  "format": "uuid"
+                }
+              }
+            }
+          }
+        },
+        "responses": {
+          "204": {
+            "description": "Thing disconnected from the channels."
+          },
+          "400": {
+            "description": "Failed due to malformed JSON."
+          },
+          "403": {
+            "description": "Missing or invalid access token provided."
+          },
+          "404": {
+            "description": "Thing does not exist, or it was not connected to some of the\nlisted channels.\n",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ErrorRes"
+                }
+              }
+            }
+          },
+          "413": {
+            "description": "Request body exceeding the size limit."
+          },
+          "415": {
+            "description": "Missing or invalid content type."
+          },
+          "422": {
+            "description": "Failed due to empty list of channels."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      }
+    },
+    "/channels": {
+      "post": {
+        "summary": "Creates new channel",
+        "description": "Creates new channel. User identified by the provided access token will\nbe the channel's owner.\n",
+        "tags": [
+          "channels"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          }
+        ],
+        "requestBody": {
+          "description": "JSON-formatted document describing the new channel.",
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/ChannelReq"
+              }
+            }
+          }
+        },
+        "responses": {
+          "201": {
+            "description": "Channel created.",
+            "headers": {
+              "Location": {
+                "description": "Created channel's relative URL (i.e. /channels/{chanId}).",
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Failed due to malformed JSON."
+          },
+          "403": {
+            "description": "Missing or invalid access token provided."
+          },
+          "409": {
+            "description": "Failed due to the name being taken by another channel, if names must be unique."
+          },
+          "413": {
+            "description": "Request body exceeding the size limit."
+          },
+          "415": {
+            "description": "Missing or invalid content type."
+          },
+          "422": {
+            "description": "Failed due to invalid channel."
+          },
+          "429": {
+            "description": "Failed due to exceeding the quota of the user."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      },
+      "get": {
+        "summary": "Retrieves managed channels",
+        "description": "Retrieves a list of managed channels. Due to performance concerns, data\nis retrieved in subsets. The API things must ensure that the entire\ndataset is consumed either by making subsequent requests, or by\nincreasing the subset size of the initial request. If the metadata is\nprovided, only channels having the specified metadata key/value pair\nare retrieved.\n",
+        "tags": [
+          "channels"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          },
+          {
+            "$ref": "#/components/parameters/Limit"
+          },
+          {
+            "$ref": "#/components/parameters/Offset"
+          },
+          {
+            "$ref": "#/components/parameters/Order"
+          },
+          {
+            "$ref": "#/components/parameters/Dir"
+          },
+          {
+            "$ref": "#/components/parameters/Metadata"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Data retrieved.",
+            "headers": {
+              "X-Count": {
+                "description": "Total number of managed channels. This value can be used to\nimplement the paging strategy on API things.\n",
+                "schema": {
+                  "type": "integer"
+                }
+              }
+            },
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ChannelList"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Failed due to malformed query parameters."
+          },
+          "403": {
+            "description": "Missing or invalid access token provided."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      },
+      "delete": {
+        "summary": "Removes all channels",
+        "description": "Removes all of the channels owned by the user identified using the\nprovided access token, and disconnects all of the things from them.\n",
+        "tags": [
+          "channels"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          }
+        ],
+        "responses": {
+          "204": {
+            "description": "Channels removed."
+          },
+          "403": {
+            "description": "Missing or invalid access token provided."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      }
+    },
+    "/channels/count": {
+      "get": {
+        "summary": "Retrieves the number of managed channels",
+        "description": "Retrieves the number of channels owned by the user.\n",
+        "tags": [
+          "channels"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Data retrieved.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/CountRes"
+                }
+              }
+            }
+          },
+          "403": {
+            "description": "Missing or invalid access token provided."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      }
+    },
+    "/channels/{chanId}": {
+      "get": {
+        "summary": "Retrieves channel info",
+        "description": "Retrieves channel info, optionally including the connections of the\nthings connected to the channel, sorted by the time they were made.\n",
+        "tags": [
+          "channels"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          },
+          {
+            "$ref": "#/components/parameters/ChanId"
+          },
+          {
+            "$ref": "#/components/parameters/Include"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Data retrieved.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ChannelRes"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Failed due to malformed query parameters."
+          },
+          "403": {
+            "description": "Missing or invalid access token provided."
+          },
+          "404": {
+            "description": "Channel does not exist."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      },
+      "head": {
+        "summary": "Checks channel existence",
+        "description": "Performs the same checks as the retrieval of the channel info, without\nretrieving any data.\n",
+        "tags": [
+          "channels"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          },
+          {
+            "$ref": "#/components/parameters/ChanId"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Channel exists."
+          },
+          "403": {
+            "description": "Missing or invalid access token provided."
+          },
+          "404": {
+            "description": "Channel does not exist."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      },
+      "put": {
+        "summary": "Updates channel info",
+        "description": "Update is performed by replacing the current resource data with values\nprovided in a request payload. Note that the channel's ID will not be\naffected.\n",
+        "tags": [
+          "channels"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          },
+          {
+            "$ref": "#/components/parameters/ChanId"
+          }
+        ],
+        "requestBody": {
+          "description": "JSON-formatted document describing the updated channel.",
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/ChannelReq"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "Channel updated."
+          },
+          "400": {
+            "description": "Failed due to malformed JSON."
+          },
+          "403": {
+            "description": "Missing or invalid access token provided."
+          },
+          "404": {
+            "description": "Channel does not exist."
+          },
+          "409": {
+            "description": "Failed due to the name being taken by another channel, if names must be unique."
+          },
+          "413": {
+            "description": "Request body exceeding the size limit."
+          },
+          "415": {
+            "description": "Missing or invalid content type."
+          },
+          "422": {
+            "description": "Failed due to invalid channel."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      },
+      "patch": {
+        "summary": "Partially updates channel info",
+        "description": "Update is performed by merging the request payload onto the current\nchannel, following the JSON merge patch semantics (RFC 7386). The\nomitted fields are left untouched, while the fields and metadata keys\nset to null are removed.\n",
+        "tags": [
+          "channels"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          },
+          {
+            "$ref": "#/components/parameters/ChanId"
+          }
+        ],
+        "requestBody": {
+          "description": "JSON merge patch of the channel.",
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/ChannelPatchReq"
+              }
+            },
+            "application/merge-patch+json": {
+              "schema": {
+                "$ref": "#/components/schemas/ChannelPatchReq"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "Channel updated."
+          },
+          "400": {
+            "description": "Failed due to malformed JSON."
+          },
+          "403": {
+            "description": "Missing or invalid access token provided."
+          },
+          "404": {
+            "description": "Channel does not exist."
+          },
+          "409": {
+            "description": "Failed due to the name being taken by another channel, if names must be unique."
+          },
+          "413": {
+            "description": "Request body exceeding the size limit."
+          },
+          "415": {
+            "description": "Missing or invalid content type."
+          },
+          "422": {
+            "description": "Failed due to no fields provided or invalid channel."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      },
+      "delete": {
+        "summary": "Removes a channel",
+        "description": "Removes a channel. The service will ensure that the subscribed apps and\ndevices are unsubscribed from the removed channel. Removed channel keeps\nits connections, and can be restored later on.\n",
+        "tags": [
+          "channels"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          },
+          {
+            "$ref": "#/components/parameters/ChanId"
+          }
+        ],
+        "responses": {
+          "204": {
+            "description": "Channel removed."
+          },
+          "403": {
+            "description": "Missing or invalid access token provided."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      }
+    },
+    "/channels/{chanId}/restore": {
+      "post": {
+        "summary": "Restores removed channel",
+        "description": "Restores previously removed channel, along with its thing connections.\n",
+        "tags": [
+          "channels"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          },
+          {
+            "$ref": "#/components/parameters/ChanId"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Channel restored."
+          },
+          "403": {
+            "description": "Missing or invalid access token provided."
+          },
+          "404": {
+            "description": "Channel does not exist."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      }
+    },
+    "/channels/{chanId}/transfer": {
+      "post": {
+        "summary": "Transfers the channel to another user",
+        "description": "Transfers the channel to the registered user having the provided\nemail. All of the things are disconnected from the channel before it\nis transferred.\n",
+        "tags": [
+          "channels"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          },
+          {
+            "$ref": "#/components/parameters/ChanId"
+          }
+        ],
+        "requestBody": {
+          "description": "JSON-formatted document describing the new owner.",
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/TransferReq"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "Channel transferred."
+          },
+          "400": {
+            "description": "Failed due to malformed JSON."
+          },
+          "403": {
+            "description": "Missing or invalid access token provided."
+          },
+          "404": {
+            "description": "Channel does not exist."
+          },
+          "409": {
+            "description": "New owner already has the channel with the same ID."
+          },
+          "413": {
+            "description": "Request body exceeding the size limit."
+          },
+          "415": {
+            "description": "Missing or invalid content type."
+          },
+          "422": {
+            "description": "Failed due to invalid or unregistered owner's email."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      }
+    },
+    "/channels/{chanId}/owner": {
+      "get": {
+        "summary": "Retrieves channel's owner",
+        "description": "Retrieves the owner of the specified channel, regardless of the user\nthat owns it. The endpoint is meant for the other Mainflux services,\nso it requires the service key instead of the user's access token.\n",
+        "tags": [
+          "channels"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/ServiceKey"
+          },
+          {
+            "$ref": "#/components/parameters/ChanId"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Data retrieved.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ChannelOwnerRes"
+                }
+              }
+            }
+          },
+          "403": {
+            "description": "Missing or invalid service key provided."
+          },
+          "404": {
+            "description": "Channel does not exist."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      }
+    },
+    "/channels/{chanId}/events": {
+      "get": {
+        "summary": "Streams connection events of the channel",
+        "description": "Holds the connection open and streams the server-sent events whenever\na thing is connected to or disconnected from the specified channel.\nEach event is named after its type (channel.connect or\nchannel.disconnect), and carries the ConnectionEvent as its data.\n",
+        "tags": [
+          "channels"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          },
+          {
+            "$ref": "#/components/parameters/ChanId"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Event stream opened.",
+            "content": {
+              "text/event-stream": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "403": {
+            "description": "Missing or invalid access token provided."
+          },
+          "404": {
+            "description": "Channel does not exist."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          },
+          "501": {
+            "description": "Event streaming is not supported."
+          }
+        }
+      }
+    },
+    "/channels/{chanId}/things/count": {
+      "get": {
+        "summary": "Retrieves the number of connected things",
+        "description": "Retrieves the number of things connected to the specified channel.\n",
+        "tags": [
+          "channels"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          },
+          {
+            "$ref": "#/components/parameters/ChanId"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Data retrieved.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/CountRes"
+                }
+              }
+            }
+          },
+          "403": {
+            "description": "Missing or invalid access token provided."
+          },
+          "404": {
+            "description": "Channel does not exist."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      }
+    },
+    "/channels/{chanId}/things": {
+      "get": {
+        "summary": "Retrieves things connected to the channel",
+        "description": "Retrieves a list of managed things that are connected to the specified\nchannel. Due to performance concerns, data is retrieved in subsets.\n",
+        "tags": [
+          "channels"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          },
+          {
+            "$ref": "#/components/parameters/ChanId"
+          },
+          {
+            "$ref": "#/components/parameters/Limit"
+          },
+          {
+            "$ref": "#/components/parameters/Offset"
+          },
+          {
+            "$ref": "#/components/parameters/Connected"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Data retrieved.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ThingList"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Failed due to malformed query parameters."
+          },
+          "403": {
+            "description": "Missing or invalid access token provided."
+          },
+          "404": {
+            "description": "Failed due to malformed channel's ID."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      },
+      "put": {
+        "summary": "Connects multiple things to the channel",
+        "description": "Connects all of the listed things to the specified channel at once.\nIf the channel or any of the things does not exist, none of the\nconnections is made.\n",
+        "tags": [
+          "channels"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          },
+          {
+            "$ref": "#/components/parameters/ChanId"
+          }
+        ],
+        "requestBody": {
+          "description": "JSON-formatted list of thing identifiers.",
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "type": "array",
+                "minItems": 1,
+                "items": {
+                  "type": "string",
+                  "format": "uuid"
+                }
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "All of the things connected to the channel."
+          },
+          "400": {
+            "description": "Failed due to malformed JSON."
+          },
+          "403": {
+            "description": "Missing or invalid access token provided."
+          },
+          "404": {
+            "description": "Channel or any of the things does not exist."
+          },
+          "413": {
+            "description": "Request body exceeding the size limit."
+          },
+          "415": {
+            "description": "Missing or invalid content type."
+          },
+          "422": {
+            "description": "Failed due to empty list of things."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      }
+    },
+    "/channels/{chanId}/things/sync": {
+      "post": {
+        "summary": "Replaces things connected to the channel",
+        "description": "Makes the listed things the only ones connected to the specified\nchannel, by connecting the missing things and disconnecting the rest of\nthem. An empty list disconnects all of the things. If the channel or any\nof the things does not exist, the connections are left intact.\n",
+        "tags": [
+          "channels"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          },
+          {
+            "$ref": "#/components/parameters/ChanId"
+          }
+        ],
+        "requestBody": {
+          "description": "JSON-formatted list of thing identifiers.",
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "type": "array",
+                "items": {
+                  "type": "string",
+                  "format": "uuid"
+                }
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "Exactly the listed things connected to the channel."
+          },
+          "400": {
+            "description": "Failed due to malformed JSON."
+          },
+          "403": {
+            "description": "Missing or invalid access token provided."
+          },
+          "404": {
+            "description": "Channel or any of the things does not exist."
+          },
+          "413": {
+            "description": "Request body exceeding the size limit."
+          },
+          "415": {
+            "description": "Missing or invalid content type."
+          },
+          "422": {
+            "description": "Failed due to missing list of things."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      }
+    },
+    "/channels/{chanId}/things/{thingId}": {
+      "get": {
+        "summary": "Checks whether the thing is connected to the channel",
+        "description": "Determines whether the connection between a thing and a channel\nexists, without requiring the thing's access key.\n",
+        "tags": [
+          "channels"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          },
+          {
+            "$ref": "#/components/parameters/ChanId"
+          },
+          {
+            "$ref": "#/components/parameters/ThingId"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Thing is connected.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ConnectionStatusRes"
+                }
+              }
+            }
+          },
+          "403": {
+            "description": "Missing or invalid access token provided, or the channel or the thing belongs to another user."
+          },
+          "404": {
+            "description": "Connection does not exist."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      },
+      "put": {
+        "summary": "Connects the thing to the channel",
+        "description": "Creates connection between a thing and a channel. Once connected to\nthe channel, things are allowed to exchange messages through it in\nthe provided access mode. If the thing is already connected, the\nexisting connection is returned with its access mode replaced.\n",
+        "tags": [
+          "channels"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          },
+          {
+            "$ref": "#/components/parameters/ChanId"
+          },
+          {
+            "$ref": "#/components/parameters/ThingId"
+          },
+          {
+            "$ref": "#/components/parameters/Mode"
+          }
+        ],
+        "responses": {
+          "201": {
+            "description": "Thing connected.",
+            "headers": {
+              "Location": {
+                "description": "Connection's relative URL (i.e. /channels/{chanId}/things/{thingId}).",
+                "schema": {
+                  "type": "string"
+                }
+              }
+            },
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ConnectionRes"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Failed due to invalid access mode."
+          },
+          "403": {
+            "description": "Missing or invalid access token provided, or the channel or the thing belongs to another user."
+          },
+          "404": {
+            "description": "Channel or thing does not exist."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      },
+      "delete": {
+        "summary": "Disconnects the thing from the channel",
+        "description": "Removes connection between a thing and a channel. Once connection is\nremoved, thing can no longer exchange messages through the channel.\nDisconnecting the thing that isn't connected is rejected as not found,\nunless the service is configured to make disconnecting idempotent, in\nwhich case it succeeds and reports that nothing was disconnected.\n",
+        "tags": [
+          "channels"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/Authorization"
+          },
+          {
+            "$ref": "#/components/parameters/ChanId"
+          },
+          {
+            "$ref": "#/components/parameters/ThingId"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Thing disconnected, or already disconnected if idempotent.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/DisconnectionRes"
+                }
+              }
+            }
+          },
+          "403": {
+            "description": "Missing or invalid access token provided."
+          },
+          "404": {
+            "description": "Channel or thing does not exist, or the thing isn't connected."
+          },
+          "500": {
+            "$ref": "#/components/responses/ServiceError"
+          }
+        }
+      }
+    },
+    "/access": {
+      "get": {
+        "summary": "Checks whether the thing can access the channel",
+        "description": "Verifies that the thing having the provided key is connected to the\nspecified channel in the access mode permitting the requested kind of\naccess. It's meant for the reverse proxies authorizing their requests\nby a subrequest, so none of the responses have the body.\n",
+        "tags": [
+          "access"
+        ],
+        "parameters": [
+          {
+            "name": "Authorization",
+            "description": "Thing's access key.",
+            "in": "header",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "X-Channel-ID",
+            "description": "Unique channel identifier.",
+            "in": "header",
+            "required": true,
+            "schema": {
+              "type": "string",
+              "format": "uuid"
+            }
+          },
+          {
+            "$ref": "#/components/parameters/Mode"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Access granted.",
+            "headers": {
+              "X-Thing-ID": {
+                "description": "Unique identifier of the thing accessing the channel.\n",
+                "schema": {
+                  "type": "string",
+                  "format": "uuid"
+                }
+              }
+            }
+          },
+          "403": {
+            "description": "Access denied."
+          },
+          "500": {
+            "description": "Unexpected server-side error occurred."
+          }
+        }
+      }
+    },
+    "/health": {
+      "get": {
+        "summary": "Retrieves service health check info",
+        "description": "Reports whether the service is able to serve requests, i.e. whether\nthe users service and the database it relies on are reachable.\n",
+        "tags": [
+          "health"
+        ],
+        "responses": {
+          "200": {
+            "description": "Service is healthy.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/HealthRes"
+                }
+              }
+            }
+          },
+          "503": {
+            "description": "Users service or database is unreachable.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/HealthRes"
+                }
+              }
+            }
+          }
+        }
+      }
+    }
+  },
+  "components": {
+    "parameters": {
+      "Authorization": {
+        "name": "Authorization",
+        "description": "User's access token.",
+        "in": "header",
+        "required": true,
+        "schema": {
+          "type": "string"
+        }
+      },
+      "ServiceKey": {
+        "name": "Authorization",
+        "description": "Key shared with the other Mainflux services.",
+        "in": "header",
+        "required": true,
+        "schema": {
+          "type": "string"
+        }
+      },
+      "IdempotencyKey": {
+        "name": "Idempotency-Key",
+        "description": "Unique key of the request, making its retries safe.",
+        "in": "header",
+        "required": false,
+        "schema": {
+          "type": "string"
+        }
+      },
+      "IfMatch": {
+        "name": "If-Match",
+        "description": "Current ETag of the thing, or the wildcard.",
+        "in": "header",
+        "required": true,
+        "schema": {
+          "type": "string"
+        }
+      },
+      "IfNoneMatch": {
+        "name": "If-None-Match",
+        "description": "Previously retrieved ETag values.",
+        "in": "header",
+        "required": false,
+        "schema": {
+          "type": "string"
+        }
+      },
+      "ChanId": {
+        "name": "chanId",
+        "description": "Unique channel identifier.",
+        "in": "path",
+        "required": true,
+        "schema": {
+          "type": "string",
+          "format": "uuid"
+        }
+      },
+      "ThingId": {
+        "name": "thingId",
+        "description": "Unique thing identifier.",
+        "in": "path",
+        "required": true,
+        "schema": {
+          "type": "string",
+          "format": "uuid"
+        }
+      },
+      "Limit": {
+        "name": "limit",
+        "description": "Size of the subset to retrieve. The default and maximum sizes are\nconfigurable, and the effective ones are served at /config.\n",
+        "in": "query",
+        "required": false,
+        "schema": {
+          "type": "integer",
+          "default": 10,
+          "maximum": 100,
+          "minimum": 1
+        }
+      },
+      "Name": {
+        "name": "name",
+        "description": "Case insensitive part of the thing's name to search for.",
+        "in": "query",
+        "required": false,
+        "schema": {
+          "type": "string"
+        }
+      },
+      "Metadata": {
+        "name": "metadata",
+        "description": "Metadata key/value pair to filter by, in the key:value format.",
+        "in": "query",
+        "required": false,
+        "schema": {
+          "type": "string"
+        }
+      },
+      "Ids": {
+        "name": "ids",
+        "description": "Comma-separated list of at most 100 thing identifiers.",
+        "in": "query",
+        "required": false,
+        "schema": {
+          "type": "string"
+        }
+      },
+      "PageToken": {
+        "name": "page_token",
+        "description": "Opaque token of the page to retrieve, as returned by the previous page.",
+        "in": "query",
+        "required": false,
+        "schema": {
+          "type": "string"
+        }
+      },
+      "DryRun": {
+        "name": "dry_run",
+        "description": "Whether to validate the things without creating them.",
+        "in": "query",
+        "required": false,
+        "schema": {
+          "type": "boolean",
+          "default": false
+        }
+      },
+      "Deleted": {
+        "name": "deleted",
+        "description": "Whether to retrieve removed things instead of active ones.",
+        "in": "query",
+        "required": false,
+        "schema": {
+          "type": "boolean",
+          "default": false
+        }
+      },
+      "Type": {
+        "name": "type",
+        "description": "Type of things to retrieve.",
+        "in": "query",
+        "required": false,
+        "schema": {
+          "type": "string",
+          "enum": [
+            "app",
+            "device"
+          ]
+        }
+      },
+      "Connected": {
+        "name": "connected",
+        "description": "Connection state of things to retrieve. Value \"false\" retrieves things\nthat were recently disconnected from the channel.\n",
+        "in": "query",
+        "required": false,
+        "schema": {
+          "type": "string",
+          "enum": [
+            "true",
+            "false",
+            "all"
+          ],
+          "default": "true"
+        }
+      },
+      "Mode": {
+        "name": "mode",
+        "description": "Kind of access to the channel. Value \"pub\" stands for publishing, \"sub\"\nfor subscribing, and \"pubsub\" for both of them.\n",
+        "in": "query",
+        "required": false,
+        "schema": {
+          "type": "string",
+          "enum": [
+            "pub",
+            "sub",
+            "pubsub"
+          ],
+          "default": "pubsub"
+        }
+      },
+      "Unconnected": {
+        "name": "connected",
+        "description": "Value \"false\" retrieves only things that aren't connected to any channel.",
+        "in": "query",
+        "required": false,
+        "schema": {
+          "type": "string",
+          "enum": [
+            "false"
+          ]
+        }
+      },
+      "Fields": {
+        "name": "fields",
+        "description": "Comma-separated fields of the thing to retrieve, along with its identifier.",
+        "in": "query",
+        "required": false,
+        "schema": {
+          "type": "string"
+        }
+      },
+      "Include": {
+        "name": "include",
+        "description": "Additional data to include in the channel info.",
+        "in": "query",
+        "required": false,
+        "schema": {
+          "type": "string",
+          "enum": [
+            "connections"
+          ]
+        }
+      },
+      "ThingInclude": {
+        "name": "include",
+        "description": "Additional data to include in the thing info.",
+        "in": "query",
+        "required": false,
+        "schema": {
+          "type": "string",
+          "enum": [
+            "channels"
+          ]
+        }
+      },
+      "Status": {
+        "name": "status",
+        "description": "Status of things to retrieve.",
+        "in": "query",
+        "required": false,
+        "schema": {
+          "type": "string",
+          "enum": [
+            "enabled",
+            "disabled"
+          ]
+        }
+      },
+      "Tag": {
+        "name": "tag",
+        "description": "Tag of things to retrieve.",
+        "in": "query",
+        "required": false,
+        "schema": {
+          "type": "string"
+        }
+      },
+      "Offset": {
+        "name": "offset",
+        "description": "Number of items to skip during retrieval.",
+        "in": "query",
+        "required": false,
+        "schema": {
+          "type": "integer",
+          "default": 0,
+          "minimum": 0
+        }
+      },
+      "Order": {
+        "name": "order",
+        "description": "Field to sort the retrieved items by.",
+        "in": "query",
+        "required": false,
+        "schema": {
+          "type": "string",
+          "enum": [
+            "id",
+            "name",
+            "created"
+          ],
+          "default": "id"
+        }
+      },
+      "Dir": {
+        "name": "dir",
+        "description": "Sorting direction.",
+        "in": "query",
+        "required": false,
+        "schema": {
+          "type": "string",
+          "enum": [
+            "asc",
+            "desc"
+          ],
+          "default": "asc"
+        }
+      }
+    },
+    "responses": {
+      "ServiceError": {
+        "description": "Unexpected server-side error occured.",
+        "content": {
+          "application/json": {
+            "schema": {
+              "$ref": "#/components/schemas/ErrorRes"
+            }
+          }
+        }
+      }
+    },
+    "schemas": {
+      "ErrorRes": {
+        "type": "object",
+        "properties": {
+          "error": {
+            "type": "string",
+            "description": "Human-readable error description."
+          },
+          "code": {
+            "type": "string",
+            "enum": [
+              "malformed_entity",
+              "malformed_json",
+              "unauthorized",
+              "not_found",
+              "not_connected",
+              "conflict",
+              "idempotency_key_reused",
+              "unsupported_content_type",
+              "invalid_query_params",
+              "internal"
+            ],
+            "description": "Stable, machine-readable error code."
+          }
+        },
+        "required": [
+          "error",
+          "code"
+        ]
+      },
+      "HealthRes": {
+        "type": "object",
+        "properties": {
+          "status": {
+            "type": "string",
+            "enum": [
+              "pass",
+              "fail"
+            ],
+            "description": "Service health status."
+          }
+        }
+      },
+      "ChannelList": {
+        "type": "object",
+        "properties": {
+          "channels": {
+            "type": "array",
+            "minItems": 0,
+            "uniqueItems": true,
+            "items": {
+              "type": "object",
+              "properties": {
+                "id": {
+                  "type": "string",
+                  "description": "Unique channel identifier generated by the service."
+                },
+                "name": {
+                  "type": "string",
+                  "description": "Free-form channel name."
+                },
+                "metadata": {
+                  "type": "object",
+                  "description": "Arbitrary, object-encoded channel's data."
+                }
+              },
+              "required": [
+                "id"
+              ]
+            }
+          },
+          "total": {
+            "type": "integer",
+            "description": "Total number of channels owned by the user."
+          },
+          "offset": {
+            "type": "integer",
+            "description": "Number of items skipped during retrieval."
+          },
+          "limit": {
+            "type": "integer",
+            "description": "Maximum number of items retrieved."
+          },
+          "links": {
+            "$ref": "#/components/schemas/PageLinks"
+          }
+        },
+        "required": [
+          "channels"
+        ]
+      },
+      "ChannelRes": {
+        "type": "object",
+        "properties": {
+          "id": {
+            "type": "string",
+            "description": "Unique channel identifier generated by the service."
+          },
+          "name": {
+            "type": "string",
+            "description": "Free-form channel name."
+          },
+          "metadata": {
+            "type": "object",
+            "description": "Arbitrary, object-encoded channel's data."
+          },
+          "connected": {
+            "type": "array",
+            "minItems": 0,
+            "uniqueItems": true,
+            "items": {
+              "$ref": "#/components/schemas/ThingRes"
+            }
+          },
+          "webhook": {
+            "$ref": "#/components/schemas/Webhook"
+          },
+          "connections": {
+            "type": "array",
+            "description": "Connections of the things connected to the channel, included only\nin the channel view if requested.\n",
+            "items": {
+              "type": "object",
+              "properties": {
+                "thing_id": {
+                  "type": "string",
+                  "description": "Identifier of the connected thing."
+                },
+                "mode": {
+                  "type": "string",
+                  "enum": [
+                    "pub",
+                    "sub",
+                    "pubsub"
+                  ],
+                  "description": "Kind of access granted to the thing."
+                },
+                "connected_at": {
+                  "type": "string",
+                  "format": "date-time",
+                  "description": "Time when the thing was connected to the channel."
+                }
+              }
+            }
+          },
+          "created_at": {
+            "type": "string",
+            "format": "date-time",
+            "description": "Time when the channel was created."
+          },
+          "updated_at": {
+            "type": "string",
+            "format": "date-time",
+            "description": "Time when the channel was last updated."
+          }
+        },
+        "required": [
+          "id"
+        ]
+      },
+      "ChannelReq": {
+        "type": "object",
+        "properties": {
+          "name": {
+            "type": "string",
+            "minLength": 1,
+            "maxLength": 1024,
+            "description": "Free-form channel name."
+          },
+          "metadata": {
+            "type": "object",
+            "description": "Arbitrary, object-encoded channel's data."
+          },
+          "webhook": {
+            "$ref": "#/components/schemas/Webhook"
+          }
+        },
+        "required": [
+          "name"
+        ]
+      },
+      "Webhook": {
+        "type": "object",
+        "description": "HTTP endpoint notified by POST request whenever a thing is connected to\nor disconnected from the channel. If the secret is set, the request\ncarries the X-Mainflux-Signature header, containing \"sha256=\" followed\nby the hex encoded HMAC-SHA256 of the request body.\n",
+        "properties": {
+          "url": {
+            "type": "string",
+            "format": "uri",
+            "description": "HTTP or HTTPS URL of the notified endpoint."
+          },
+          "secret": {
+            "type": "string",
+            "description": "Secret used to sign the notifications. It is write-only, so it is\nnever retrieved along with the channel.\n"
+          }
+        },
+        "required": [
+          "url"
+        ]
+      },
+      "CountRes": {
+        "type": "object",
+        "properties": {
+          "count": {
+            "type": "integer",
+            "description": "Number of entities owned by the user."
+          }
+        },
+        "required": [
+          "count"
+        ]
+      },
+      "ChannelOwnerRes": {
+        "type": "object",
+        "properties": {
+          "owner": {
+            "type": "string",
+            "description": "Channel owner's identifier."
+          }
+        },
+        "required": [
+          "owner"
+        ]
+      },
+      "ConnectionEvent": {
+        "type": "object",
+        "properties": {
+          "event": {
+            "type": "string",
+            "enum": [
+              "channel.connect",
+              "channel.disconnect"
+            ],
+            "description": "Type of the event."
+          },
+          "channel_id": {
+            "type": "string",
+            "format": "uuid",
+            "description": "Unique channel identifier."
+          },
+          "thing_id": {
+            "type": "string",
+            "format": "uuid",
+            "description": "Unique thing identifier."
+          },
+          "timestamp": {
+            "type": "string",
+            "format": "date-time",
+            "description": "Time when the event occurred."
+          }
+        }
+      },
+      "ConnectionRes": {
+        "type": "object",
+        "properties": {
+          "channel_id": {
+            "type": "string",
+            "description": "Connected channel's identifier."
+          },
+          "thing_id": {
+            "type": "string",
+            "description": "Connected thing's identifier."
+          },
+          "mode": {
+            "type": "string",
+            "enum": [
+              "pub",
+              "sub",
+              "pubsub"
+            ],
+            "description": "Kind of access granted to the thing."
+          },
+          "connected_at": {
+            "type": "string",
+            "format": "date-time",
+            "description": "Time the connection was made."
+          }
+        },
+        "required": [
+          "channel_id",
+          "thing_id",
+          "mode",
+          "connected_at"
+        ]
+      },
+      "DisconnectionRes": {
+        "type": "object",
+        "properties": {
+          "channel_id": {
+            "type": "string",
+            "description": "Channel's identifier."
+          },
+          "thing_id": {
+            "type": "string",
+            "description": "Thing's identifier."
+          },
+          "disconnected": {
+            "type": "boolean",
+            "description": "Whether the thing was connected to the channel."
+          }
+        },
+        "required": [
+          "channel_id",
+          "thing_id",
+          "disconnected"
+        ]
+      },
+      "ConnectionStatusRes": {
+        "type": "object",
+        "properties": {
+          "connected": {
+            "type": "boolean",
+            "description": "Whether the thing is connected to the channel."
+          }
+        },
+        "required": [
+          "connected"
+        ]
+      },
+      "IdentifyReq": {
+        "type": "object",
+        "properties": {
+          "key": {
+            "type": "string",
+            "description": "Thing's access key."
+          }
+        },
+        "required": [
+          "key"
+        ]
+      },
+      "ThingIdentityRes": {
+        "type": "object",
+        "properties": {
+          "id": {
+            "type": "string",
+            "description": "Unique thing identifier generated by the service."
+          },
+          "owner": {
+            "type": "string",
+            "description": "Thing owner's identifier."
+          }
+        },
+        "required": [
+          "id",
+          "owner"
+        ]
+      },
+      "KeyReq": {
+        "type": "object",
+        "properties": {
+          "key": {
+            "type": "string",
+            "minLength": 36,
+            "maxLength": 36,
+            "description": "New thing's access key, 36 characters long."
+          }
+        },
+        "required": [
+          "key"
+        ]
+      },
+      "KeyRes": {
+        "type": "object",
+        "properties": {
+          "key": {
+            "type": "string",
+            "description": "Newly generated thing's access key."
+          }
+        }
+      },
+      "TransferReq": {
+        "type": "object",
+        "properties": {
+          "owner": {
+            "type": "string",
+            "format": "email",
+            "description": "Email of the new owner."
+          }
+        },
+        "required": [
+          "owner"
+        ]
+      },
+      "PageLinks": {
+        "type": "object",
+        "properties": {
+          "first": {
+            "type": "string",
+            "format": "uri",
+            "description": "Absolute URL of the first page."
+          },
+          "next": {
+            "type": "string",
+            "format": "uri",
+            "description": "Absolute URL of the next page, omitted on the last page."
+          },
+          "prev": {
+            "type": "string",
+            "format": "uri",
+            "description": "Absolute URL of the previous page, omitted on the first page."
+          }
+        },
+        "required": [
+          "first"
+        ]
+      },
+      "ThingList": {
+        "type": "object",
+        "properties": {
+          "things": {
+            "type": "array",
+            "minItems": 0,
+            "uniqueItems": true,
+            "items": {
+              "$ref": "#/components/schemas/ThingRes"
+            }
+          },
+          "total": {
+            "type": "integer",
+            "description": "Total number of things owned by the user."
+          },
+          "offset": {
+            "type": "integer",
+            "description": "Number of items skipped during retrieval."
+          },
+          "limit": {
+            "type": "integer",
+            "description": "Maximum number of items retrieved."
+          },
+          "links": {
+            "$ref": "#/components/schemas/PageLinks"
+          },
+          "next_token": {
+            "type": "string",
+            "description": "Token of the next page, omitted on the last one."
+          }
+        },
+        "required": [
+          "things"
+        ]
+      },
+      "ThingRes": {
+        "type": "object",
+        "properties": {
+          "id": {
+            "type": "string",
+            "description": "Unique thing identifier generated by the service."
+          },
+          "external_id": {
+            "type": "string",
+            "description": "Client-supplied thing identifier."
+          },
+          "type": {
+            "type": "string",
+            "enum": [
+              "app",
+              "device"
+            ],
+            "description": "Type of the thing."
+          },
+          "name": {
+            "type": "string",
+            "description": "Free-form thing name."
+          },
+          "key": {
+            "type": "string",
+            "description": "Auto-generated access key."
+          },
+          "payload": {
+            "type": "string",
+            "description": "Arbitrary, string-encoded thing's data."
+          },
+          "metadata": {
+            "type": "object",
+            "description": "Arbitrary, object-encoded thing's data."
+          },
+          "tags": {
+            "type": "array",
+            "items": {
+              "type": "string"
+            },
+            "description": "Tags used to group the things."
+          },
+          "status": {
+            "type": "string",
+            "enum": [
+              "enabled",
+              "disabled"
+            ],
+            "description": "Whether the thing is allowed to access its channels."
+          },
+          "created_at": {
+            "type": "string",
+            "format": "date-time",
+            "description": "Time when the thing was created."
+          },
+          "updated_at": {
+            "type": "string",
+            "format": "date-time",
+            "description": "Time when the thing was last updated."
+          },
+          "version": {
+            "type": "integer",
+            "description": "Version of the thing, incremented on every update."
+          },
+          "channels": {
+            "type": "array",
+            "description": "Channels the thing is connected to, included only in the thing view\nif requested.\n",
+            "items": {
+              "type": "object",
+              "properties": {
+                "id": {
+                  "type": "string",
+                  "description": "Identifier of the connected channel."
+                },
+                "name": {
+                  "type": "string",
+                  "description": "Name of the connected channel."
+                }
+              }
+            }
+          }
+        },
+        "required": [
+          "id",
+          "type",
+          "key"
+        ]
+      },
+      "ChannelPatchReq": {
+        "type": "object",
+        "properties": {
+          "name": {
+            "type": "string",
+            "minLength": 1,
+            "maxLength": 1024,
+            "description": "Free-form channel name."
+          },
+          "metadata": {
+            "type": "object",
+            "description": "Arbitrary, object-encoded channel's data, merged onto the current\nmetadata. Keys set to null are removed.\n"
+          }
+        }
+      },
+      "ThingPatchReq": {
+        "type": "object",
+        "properties": {
+          "name": {
+            "type": "string",
+            "minLength": 1,
+            "maxLength": 1024,
+            "description": "Free-form thing name."
+          },
+          "payload": {
+            "type": "string",
+            "description": "Arbitrary, string-encoded thing's data."
+          },
+          "metadata": {
+            "type": "object",
+            "description": "Arbitrary, object-encoded thing's data."
+          },
+          "tags": {
+            "type": "array",
+            "items": {
+              "type": "string"
+            },
+            "description": "Tags used to group the things."
+          }
+        }
+      },
+      "ThingReq": {
+        "type": "object",
+        "properties": {
+          "id": {
+            "type": "string",
+            "format": "uuid",
+            "description": "Client-supplied thing ID. Generated by the service if omitted.\n"
+          },
+          "external_id": {
+            "type": "string",
+            "description": "Client-supplied identifier used to prevent creating duplicate things.\n"
+          },
+          "type": {
+            "type": "string",
+            "enum": [
+              "app",
+              "device"
+            ],
+            "description": "Type of the thing."
+          },
+          "name": {
+            "type": "string",
+            "minLength": 1,
+            "maxLength": 1024,
+            "description": "Free-form thing name."
+          },
+          "payload": {
+            "type": "string",
+            "description": "Arbitrary, string-encoded thing's data."
+          },
+          "metadata": {
+            "type": "object",
+            "description": "Arbitrary, object-encoded thing's data."
+          },
+          "tags": {
+            "type": "array",
+            "items": {
+              "type": "string"
+            },
+            "description": "Tags used to group the things."
+          }
+        },
+        "required": [
+          "type",
+          "name"
+        ]
+      }
+    }
+  }
+}
+`
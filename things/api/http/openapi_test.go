@@ -0,0 +1,61 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenAPIRoutes(t *testing.T) {
+	var doc struct {
+		Paths map[string]map[string]interface{} `json:"paths"`
+	}
+	err := json.Unmarshal([]byte(openAPI), &doc)
+	if !assert.Nil(t, err, fmt.Sprintf("unexpected error parsing OpenAPI document: %s\n", err)) {
+		return
+	}
+
+	routes := map[string]bool{}
+	r := makeRouter(nil, handlerConfig{})
+	for _, method := range corsMethods {
+		for _, route := range r.Routes[method] {
+			path := openAPIRoutePath(route.Path)
+			routes[fmt.Sprintf("%s %s", method, path)] = true
+
+			_, ok := doc.Paths[path][strings.ToLower(method)]
+			assert.True(t, ok, fmt.Sprintf("%s %s: expected route to be documented\n", method, path))
+		}
+	}
+
+	for path, ops := range doc.Paths {
+		for method := range ops {
+			route := fmt.Sprintf("%s %s", strings.ToUpper(method), path)
+			assert.True(t, routes[route], fmt.Sprintf("%s: expected documented operation to be routed\n", route))
+		}
+	}
+}
+
+// openAPIRoutePath converts the router's path parameters to the ones used by
+// the OpenAPI document.
+func openAPIRoutePath(path string) string {
+	parts := strings.Split(path, "/")
+	for i, part := range parts {
+		switch part {
+		case ":chanId":
+			parts[i] = "{chanId}"
+		case ":thingId":
+			parts[i] = "{thingId}"
+		case ":id":
+			if parts[1] == "channels" {
+				parts[i] = "{chanId}"
+				continue
+			}
+			parts[i] = "{thingId}"
+		}
+	}
+
+	return strings.Join(parts, "/")
+}
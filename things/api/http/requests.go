@@ -1,6 +1,8 @@
 package http
 
 import (
+	"net/url"
+
 	"github.com/asaskevich/govalidator"
 	"github.com/mainflux/mainflux/things"
 )
@@ -23,6 +25,32 @@ func (req identityReq) validate() error {
 	return nil
 }
 
+type identifyThingReq struct {
+	Key string `json:"key"`
+}
+
+func (req identifyThingReq) validate() error {
+	if req.Key == "" {
+		return errInvalidThingKey
+	}
+
+	return nil
+}
+
+type accessReq struct {
+	key    string
+	chanID string
+	mode   things.AccessMode
+}
+
+func (req accessReq) validate() error {
+	if req.key == "" || !govalidator.IsUUID(req.chanID) {
+		return things.ErrUnauthorizedAccess
+	}
+
+	return nil
+}
+
 type addThingReq struct {
 	key   string
 	thing things.Thing
@@ -33,13 +61,36 @@ func (req addThingReq) validate() error {
 		return things.ErrUnauthorizedAccess
 	}
 
+	if req.thing.ID != "" && !govalidator.IsUUID(req.thing.ID) {
+		return things.ErrMalformedEntity
+	}
+
 	return req.thing.Validate()
 }
 
+type createThingsReq struct {
+	key    string
+	dryRun bool
+	things []things.Thing
+}
+
+func (req createThingsReq) validate() error {
+	if req.key == "" {
+		return things.ErrUnauthorizedAccess
+	}
+
+	if len(req.things) == 0 {
+		return things.ErrMalformedEntity
+	}
+
+	return nil
+}
+
 type updateThingReq struct {
-	key   string
-	id    string
-	thing things.Thing
+	key     string
+	id      string
+	ifMatch string
+	thing   things.Thing
 }
 
 func (req updateThingReq) validate() error {
@@ -51,9 +102,108 @@ func (req updateThingReq) validate() error {
 		return things.ErrNotFound
 	}
 
+	if req.ifMatch == "" {
+		return errMissingIfMatch
+	}
+
 	return req.thing.Validate()
 }
 
+// thingPatch contains only the thing's fields provided in the request body,
+// while the omitted ones are left nil.
+type thingPatch struct {
+	Name     *string                 `json:"name"`
+	Payload  *string                 `json:"payload"`
+	Metadata *map[string]interface{} `json:"metadata"`
+	Tags     *[]string               `json:"tags"`
+}
+
+// apply replaces the thing's fields with the provided ones.
+func (p thingPatch) apply(thing *things.Thing) {
+	if p.Name != nil {
+		thing.Name = *p.Name
+	}
+
+	if p.Payload != nil {
+		thing.Payload = *p.Payload
+	}
+
+	if p.Metadata != nil {
+		thing.Metadata = *p.Metadata
+	}
+
+	if p.Tags != nil {
+		thing.Tags = *p.Tags
+	}
+}
+
+type patchThingReq struct {
+	key   string
+	id    string
+	patch thingPatch
+}
+
+func (req patchThingReq) validate() error {
+	if req.key == "" {
+		return things.ErrUnauthorizedAccess
+	}
+
+	if !govalidator.IsUUID(req.id) {
+		return things.ErrNotFound
+	}
+
+	p := req.patch
+	if p.Name == nil && p.Payload == nil && p.Metadata == nil && p.Tags == nil {
+		return things.ErrMalformedEntity
+	}
+
+	return nil
+}
+
+type updateKeyReq struct {
+	key    string
+	id     string
+	newKey string
+}
+
+func (req updateKeyReq) validate() error {
+	if req.key == "" {
+		return things.ErrUnauthorizedAccess
+	}
+
+	if !govalidator.IsUUID(req.id) {
+		return things.ErrNotFound
+	}
+
+	if len(req.newKey) != things.KeyLength {
+		return things.ErrMalformedEntity
+	}
+
+	return nil
+}
+
+type transferReq struct {
+	key   string
+	id    string
+	Owner string `json:"owner"`
+}
+
+func (req transferReq) validate() error {
+	if req.key == "" {
+		return things.ErrUnauthorizedAccess
+	}
+
+	if !govalidator.IsUUID(req.id) {
+		return things.ErrNotFound
+	}
+
+	if !govalidator.IsEmail(req.Owner) {
+		return things.ErrMalformedEntity
+	}
+
+	return nil
+}
+
 type createChannelReq struct {
 	key     string
 	channel things.Channel
@@ -64,7 +214,7 @@ func (req createChannelReq) validate() error {
 		return things.ErrUnauthorizedAccess
 	}
 
-	return nil
+	return req.channel.Validate()
 }
 
 type updateChannelReq struct {
@@ -82,6 +232,92 @@ func (req updateChannelReq) validate() error {
 		return things.ErrNotFound
 	}
 
+	return req.channel.Validate()
+}
+
+// channelPatch is the JSON merge patch (RFC 7386) of the channel, containing
+// only the channel's fields that are changed.
+type channelPatch map[string]interface{}
+
+// apply merges the patch onto the channel. The null value removes the field
+// it is provided for, including the individual metadata keys.
+func (p channelPatch) apply(channel *things.Channel) error {
+	if name, ok := p["name"]; ok {
+		switch v := name.(type) {
+		case nil:
+			channel.Name = ""
+		case string:
+			channel.Name = v
+		default:
+			return things.ErrMalformedEntity
+		}
+	}
+
+	if metadata, ok := p["metadata"]; ok {
+		var target interface{}
+		if channel.Metadata != nil {
+			target = channel.Metadata
+		}
+
+		switch v := mergePatch(target, metadata).(type) {
+		case nil:
+			channel.Metadata = nil
+		case map[string]interface{}:
+			channel.Metadata = v
+		default:
+			return things.ErrMalformedEntity
+		}
+	}
+
+	return nil
+}
+
+// mergePatch applies the patch onto the target, as specified by RFC 7386.
+// The target is never modified, instead the merged copy is returned.
+func mergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	t, _ := target.(map[string]interface{})
+	merged := make(map[string]interface{}, len(t))
+	for k, v := range t {
+		merged[k] = v
+	}
+
+	for k, v := range p {
+		if v == nil {
+			delete(merged, k)
+			continue
+		}
+		merged[k] = mergePatch(merged[k], v)
+	}
+
+	return merged
+}
+
+type patchChannelReq struct {
+	key   string
+	id    string
+	patch channelPatch
+}
+
+func (req patchChannelReq) validate() error {
+	if req.key == "" {
+		return things.ErrUnauthorizedAccess
+	}
+
+	if !govalidator.IsUUID(req.id) {
+		return things.ErrNotFound
+	}
+
+	_, name := req.patch["name"]
+	_, metadata := req.patch["metadata"]
+	if !name && !metadata {
+		return things.ErrMalformedEntity
+	}
+
 	return nil
 }
 
@@ -102,10 +338,62 @@ func (req viewResourceReq) validate() error {
 	return nil
 }
 
+// thingFields holds the JSON keys of the thing, which may be selected by
+// the sparse fieldsets.
+var thingFields = map[string]bool{
+	"id":          true,
+	"external_id": true,
+	"type":        true,
+	"name":        true,
+	"key":         true,
+	"payload":     true,
+	"metadata":    true,
+	"tags":        true,
+	"status":      true,
+	"created_at":  true,
+	"updated_at":  true,
+	"version":     true,
+}
+
+// validateFields verifies that the sparse fieldset selects only the known
+// fields of the thing. Nil fieldset selects all of them.
+func validateFields(fields []string) error {
+	for _, field := range fields {
+		if !thingFields[field] {
+			return errInvalidQueryParams
+		}
+	}
+
+	return nil
+}
+
+type viewThingReq struct {
+	viewResourceReq
+	ifNoneMatch string
+	fields      []string
+	channels    bool
+}
+
+func (req viewThingReq) validate() error {
+	if err := req.viewResourceReq.validate(); err != nil {
+		return err
+	}
+
+	return validateFields(req.fields)
+}
+
+type viewChannelReq struct {
+	viewResourceReq
+	connections bool
+}
+
 type listResourcesReq struct {
-	key    string
-	offset int
-	limit  int
+	key      string
+	offset   int
+	limit    int
+	maxLimit int
+	sorting  things.Sorting
+	url      *url.URL
 }
 
 func (req *listResourcesReq) validate() error {
@@ -113,17 +401,113 @@ func (req *listResourcesReq) validate() error {
 		return things.ErrUnauthorizedAccess
 	}
 
-	if req.offset >= 0 && req.limit > 0 && req.limit <= maxLimitSize {
+	if req.offset >= 0 && req.limit > 0 && req.limit <= req.maxLimit {
 		return nil
 	}
 
-	return things.ErrMalformedEntity
+	return errInvalidQueryParams
+}
+
+type searchThingsReq struct {
+	listResourcesReq
+	filter      things.ThingFilter
+	deleted     bool
+	unconnected bool
+	paged       bool
+	afterID     string
+	ids         []string
+	fields      []string
+	csv         bool
+}
+
+func (req searchThingsReq) validate() error {
+	if err := req.listResourcesReq.validate(); err != nil {
+		return err
+	}
+
+	// the filters are combined with each other, but only the existing
+	// things listed by offset can be filtered
+	filtered := req.filter != things.ThingFilter{}
+	if req.deleted && filtered {
+		return errInvalidQueryParams
+	}
+
+	if req.paged && (filtered || req.deleted) {
+		return errInvalidQueryParams
+	}
+
+	if err := validateFields(req.fields); err != nil {
+		return err
+	}
+
+	if req.unconnected && (req.paged || filtered || req.deleted) {
+		return errInvalidQueryParams
+	}
+
+	if req.ids != nil {
+		if req.paged || filtered || req.deleted || req.unconnected {
+			return errInvalidQueryParams
+		}
+
+		if len(req.ids) > req.maxLimit {
+			return errInvalidQueryParams
+		}
+
+		for _, id := range req.ids {
+			if id == "" {
+				return errInvalidQueryParams
+			}
+		}
+	}
+
+	return nil
+}
+
+type countThingsReq struct {
+	key    string
+	filter things.ThingFilter
+}
+
+func (req countThingsReq) validate() error {
+	if req.key == "" {
+		return things.ErrUnauthorizedAccess
+	}
+
+	return nil
+}
+
+type listChannelsReq struct {
+	listResourcesReq
+	metadata things.MetadataFilter
+}
+
+type listByConnectionReq struct {
+	listResourcesReq
+	id string
+}
+
+func (req listByConnectionReq) validate() error {
+	if err := req.listResourcesReq.validate(); err != nil {
+		return err
+	}
+
+	if !govalidator.IsUUID(req.id) {
+		return things.ErrNotFound
+	}
+
+	return nil
+}
+
+type listThingsByChannelReq struct {
+	listByConnectionReq
+	state things.ConnectionState
 }
 
 type connectionReq struct {
 	key     string
 	chanID  string
 	thingID string
+	mode    things.AccessMode
 }
 
 func (req connectionReq) validate() error {
@@ -137,3 +521,87 @@ func (req connectionReq) validate() error {
 
 	return nil
 }
+
+type connectManyReq struct {
+	key     string
+	thingID string
+	chanIDs []string
+}
+
+func (req connectManyReq) validate() error {
+	if req.key == "" {
+		return things.ErrUnauthorizedAccess
+	}
+
+	if !govalidator.IsUUID(req.thingID) {
+		return things.ErrNotFound
+	}
+
+	if len(req.chanIDs) == 0 {
+		return things.ErrMalformedEntity
+	}
+
+	for _, id := range req.chanIDs {
+		if !govalidator.IsUUID(id) {
+			return things.ErrNotFound
+		}
+	}
+
+	return nil
+}
+
+type connectThingsReq struct {
+	key      string
+	chanID   string
+	thingIDs []string
+}
+
+func (req connectThingsReq) validate() error {
+	if req.key == "" {
+		return things.ErrUnauthorizedAccess
+	}
+
+	if !govalidator.IsUUID(req.chanID) {
+		return things.ErrNotFound
+	}
+
+	if len(req.thingIDs) == 0 {
+		return things.ErrMalformedEntity
+	}
+
+	for _, id := range req.thingIDs {
+		if !govalidator.IsUUID(id) {
+			return things.ErrNotFound
+		}
+	}
+
+	return nil
+}
+
+// setChannelThingsReq differs from connectThingsReq in accepting the empty
+// list of things, which disconnects all of them.
+type setChannelThingsReq struct {
+	connectThingsReq
+}
+
+func (req setChannelThingsReq) validate() error {
+	if req.key == "" {
+		return things.ErrUnauthorizedAccess
+	}
+
+	if !govalidator.IsUUID(req.chanID) {
+		return things.ErrNotFound
+	}
+
+	if req.thingIDs == nil {
+		return things.ErrMalformedEntity
+	}
+
+	for _, id := range req.thingIDs {
+		if !govalidator.IsUUID(id) {
+			return things.ErrNotFound
+		}
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package http
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/mainflux/mainflux/things"
@@ -12,8 +13,9 @@ import (
 const wrong string = "?"
 
 var (
-	thing   = things.Thing{Type: "app"}
-	channel = things.Channel{}
+	thing    = things.Thing{Type: "app", Name: "test"}
+	channel  = things.Channel{Name: "test"}
+	longName = strings.Repeat("a", things.MaxNameLength+1)
 )
 
 func TestIdentityReqValidation(t *testing.T) {
@@ -32,6 +34,22 @@ func TestIdentityReqValidation(t *testing.T) {
 	}
 }
 
+func TestIdentifyThingReqValidation(t *testing.T) {
+	cases := map[string]struct {
+		key string
+		err error
+	}{
+		"non-empty thing key": {uuid.NewV4().String(), nil},
+		"empty thing key":     {"", errInvalidThingKey},
+	}
+
+	for desc, tc := range cases {
+		req := identifyThingReq{tc.key}
+		err := req.validate()
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+}
+
 func TestAddThingReqValidation(t *testing.T) {
 	key := uuid.NewV4().String()
 
@@ -42,7 +60,9 @@ func TestAddThingReqValidation(t *testing.T) {
 	}{
 		"valid thing addition request": {thing, key, nil},
 		"missing token":                {thing, "", things.ErrUnauthorizedAccess},
-		"wrong thing type":             {things.Thing{Type: wrong}, key, things.ErrMalformedEntity},
+		"wrong thing type":             {things.Thing{Type: wrong, Name: "test"}, key, things.ErrMalformedEntity},
+		"empty thing name":             {things.Thing{Type: "app"}, key, things.ErrMalformedEntity},
+		"too long thing name":          {things.Thing{Type: "app", Name: longName}, key, things.ErrMalformedEntity},
 	}
 
 	for desc, tc := range cases {
@@ -56,6 +76,30 @@ func TestAddThingReqValidation(t *testing.T) {
 	}
 }
 
+func TestCreateThingsReqValidation(t *testing.T) {
+	key := uuid.NewV4().String()
+
+	cases := map[string]struct {
+		things []things.Thing
+		key    string
+		err    error
+	}{
+		"valid things creation request": {[]things.Thing{thing}, key, nil},
+		"missing token":                 {[]things.Thing{thing}, "", things.ErrUnauthorizedAccess},
+		"empty things list":             {[]things.Thing{}, key, things.ErrMalformedEntity},
+	}
+
+	for desc, tc := range cases {
+		req := createThingsReq{
+			key:    tc.key,
+			things: tc.things,
+		}
+
+		err := req.validate()
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+}
+
 func TestUpdateThingReqValidation(t *testing.T) {
 	key := uuid.NewV4().String()
 	id := uuid.NewV4().String()
@@ -69,14 +113,75 @@ func TestUpdateThingReqValidation(t *testing.T) {
 		"valid thing update request": {thing, id, key, nil},
 		"non-uuid thing ID":          {thing, wrong, key, things.ErrNotFound},
 		"missing token":              {thing, id, "", things.ErrUnauthorizedAccess},
-		"wrong thing type":           {things.Thing{Type: "invalid"}, id, key, things.ErrMalformedEntity},
+		"wrong thing type":           {things.Thing{Type: "invalid", Name: "test"}, id, key, things.ErrMalformedEntity},
+		"empty thing name":           {things.Thing{Type: "app"}, id, key, things.ErrMalformedEntity},
+		"too long thing name":        {things.Thing{Type: "app", Name: longName}, id, key, things.ErrMalformedEntity},
 	}
 
 	for desc, tc := range cases {
 		req := updateThingReq{
+			key:     tc.key,
+			id:      tc.id,
+			ifMatch: "*",
+			thing:   tc.thing,
+		}
+
+		err := req.validate()
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+}
+
+func TestPatchThingReqValidation(t *testing.T) {
+	key := uuid.NewV4().String()
+	id := uuid.NewV4().String()
+	name := "test"
+
+	cases := map[string]struct {
+		patch thingPatch
+		id    string
+		key   string
+		err   error
+	}{
+		"valid thing patch request": {thingPatch{Name: &name}, id, key, nil},
+		"non-uuid thing ID":         {thingPatch{Name: &name}, wrong, key, things.ErrNotFound},
+		"missing token":             {thingPatch{Name: &name}, id, "", things.ErrUnauthorizedAccess},
+		"empty patch":               {thingPatch{}, id, key, things.ErrMalformedEntity},
+	}
+
+	for desc, tc := range cases {
+		req := patchThingReq{
 			key:   tc.key,
 			id:    tc.id,
-			thing: tc.thing,
+			patch: tc.patch,
+		}
+
+		err := req.validate()
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+}
+
+func TestUpdateKeyReqValidation(t *testing.T) {
+	key := uuid.NewV4().String()
+	id := uuid.NewV4().String()
+	newKey := uuid.NewV4().String()
+
+	cases := map[string]struct {
+		key    string
+		id     string
+		newKey string
+		err    error
+	}{
+		"valid key update request": {key, id, newKey, nil},
+		"missing token":            {"", id, newKey, things.ErrUnauthorizedAccess},
+		"non-uuid thing ID":        {key, wrong, newKey, things.ErrNotFound},
+		"missing new key":          {key, id, "", things.ErrMalformedEntity},
+	}
+
+	for desc, tc := range cases {
+		req := updateKeyReq{
+			key:    tc.key,
+			id:     tc.id,
+			newKey: tc.newKey,
 		}
 
 		err := req.validate()
@@ -94,6 +199,8 @@ func TestCreateChannelReqValidation(t *testing.T) {
 	}{
 		"valid channel creation request": {channel, key, nil},
 		"missing token":                  {channel, "", things.ErrUnauthorizedAccess},
+		"empty channel name":             {things.Channel{}, key, things.ErrMalformedEntity},
+		"too long channel name":          {things.Channel{Name: longName}, key, things.ErrMalformedEntity},
 	}
 
 	for desc, tc := range cases {
@@ -120,6 +227,8 @@ func TestUpdateChannelReqValidation(t *testing.T) {
 		"valid channel update request": {channel, id, key, nil},
 		"non-uuid channel ID":          {channel, wrong, key, things.ErrNotFound},
 		"missing token":                {channel, id, "", things.ErrUnauthorizedAccess},
+		"empty channel name":           {things.Channel{}, id, key, things.ErrMalformedEntity},
+		"too long channel name":        {things.Channel{Name: longName}, id, key, things.ErrMalformedEntity},
 	}
 
 	for desc, tc := range cases {
@@ -167,17 +276,90 @@ func TestListResourcesReqValidation(t *testing.T) {
 	}{
 		"valid listing request": {key, value, value, nil},
 		"missing token":         {"", value, value, things.ErrUnauthorizedAccess},
-		"negative offset":       {key, -value, value, things.ErrMalformedEntity},
-		"zero limit":            {key, value, 0, things.ErrMalformedEntity},
-		"negative limit":        {key, value, -value, things.ErrMalformedEntity},
-		"too big limit":         {key, value, 20 * value, things.ErrMalformedEntity},
+		"negative offset":       {key, -value, value, errInvalidQueryParams},
+		"zero limit":            {key, value, 0, errInvalidQueryParams},
+		"negative limit":        {key, value, -value, errInvalidQueryParams},
+		"too big limit":         {key, value, 20 * value, errInvalidQueryParams},
 	}
 
 	for desc, tc := range cases {
 		req := listResourcesReq{
-			key:    tc.key,
-			offset: tc.offset,
-			limit:  tc.limit,
+			key:      tc.key,
+			offset:   tc.offset,
+			limit:    tc.limit,
+			maxLimit: maxLimitSize,
+		}
+
+		err := req.validate()
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+}
+
+func TestSearchThingsReqValidation(t *testing.T) {
+	key := uuid.NewV4().String()
+	value := 10
+
+	meta := things.MetadataFilter{Key: "firmware", Value: "1.1"}
+
+	cases := map[string]struct {
+		key     string
+		filter  things.ThingFilter
+		deleted bool
+		limit   int
+		err     error
+	}{
+		"valid search by name request":     {key, things.ThingFilter{Name: "name"}, false, value, nil},
+		"valid search by metadata request": {key, things.ThingFilter{Metadata: meta}, false, value, nil},
+		"valid combined filters request":   {key, things.ThingFilter{Name: "name", Type: "device", Metadata: meta}, false, value, nil},
+		"missing token":                    {"", things.ThingFilter{Name: "name"}, false, value, things.ErrUnauthorizedAccess},
+		"zero limit":                       {key, things.ThingFilter{Name: "name"}, false, 0, errInvalidQueryParams},
+		"filtered deleted things":          {key, things.ThingFilter{Tag: "hvac"}, true, value, errInvalidQueryParams},
+	}
+
+	for desc, tc := range cases {
+		req := searchThingsReq{
+			listResourcesReq: listResourcesReq{
+				key:      tc.key,
+				limit:    tc.limit,
+				maxLimit: maxLimitSize,
+			},
+			filter:  tc.filter,
+			deleted: tc.deleted,
+		}
+
+		err := req.validate()
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+}
+
+func TestListByConnectionReqValidation(t *testing.T) {
+	key := uuid.NewV4().String()
+	id := uuid.NewV4().String()
+	value := 10
+
+	cases := map[string]struct {
+		key    string
+		id     string
+		offset int
+		limit  int
+		err    error
+	}{
+		"valid listing request": {key, id, value, value, nil},
+		"missing token":         {"", id, value, value, things.ErrUnauthorizedAccess},
+		"non-uuid resource ID":  {key, wrong, value, value, things.ErrNotFound},
+		"negative offset":       {key, id, -value, value, errInvalidQueryParams},
+		"zero limit":            {key, id, value, 0, errInvalidQueryParams},
+	}
+
+	for desc, tc := range cases {
+		req := listByConnectionReq{
+			listResourcesReq: listResourcesReq{
+				key:      tc.key,
+				offset:   tc.offset,
+				limit:    tc.limit,
+				maxLimit: maxLimitSize,
+			},
+			id: tc.id,
 		}
 
 		err := req.validate()
@@ -1,8 +1,16 @@
 package http
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/mainflux/mainflux"
 	"github.com/mainflux/mainflux/things"
@@ -10,24 +18,68 @@ import (
 
 var (
 	_ mainflux.Response = (*identityRes)(nil)
+	_ mainflux.Response = (*thingIdentityRes)(nil)
 	_ mainflux.Response = (*removeRes)(nil)
 	_ mainflux.Response = (*thingRes)(nil)
+	_ mainflux.Response = (*keyRes)(nil)
+	_ mainflux.Response = (*createThingsRes)(nil)
 	_ mainflux.Response = (*viewThingRes)(nil)
 	_ mainflux.Response = (*listThingsRes)(nil)
+	_ mainflux.Response = (*searchThingsRes)(nil)
+	_ mainflux.Response = (*tokenThingsRes)(nil)
+	_ mainflux.Response = (*viewThingsRes)(nil)
+	_ mainflux.Response = (*sparseRes)(nil)
+	_ mainflux.Response = (*csvThingsRes)(nil)
 	_ mainflux.Response = (*channelRes)(nil)
 	_ mainflux.Response = (*viewChannelRes)(nil)
 	_ mainflux.Response = (*listChannelsRes)(nil)
+	_ mainflux.Response = (*connectRes)(nil)
 	_ mainflux.Response = (*connectionRes)(nil)
+	_ mainflux.Response = (*disconnectRes)(nil)
 	_ mainflux.Response = (*disconnectionRes)(nil)
+	_ mainflux.Response = (*connectionStatusRes)(nil)
+	_ mainflux.Response = (*countRes)(nil)
+	_ mainflux.Response = (*channelOwnerRes)(nil)
+	_ mainflux.Response = (*healthRes)(nil)
 )
 
+const (
+	healthPass = "pass"
+	healthFail = "fail"
+)
+
+const (
+	codeMalformedEntity        = "malformed_entity"
+	codeMalformedJSON          = "malformed_json"
+	codeUnauthorized           = "unauthorized"
+	codeUnauthenticated        = "unauthenticated"
+	codeNotFound               = "not_found"
+	codeNotConnected           = "not_connected"
+	codeConflict               = "conflict"
+	codePreconditionFailed     = "precondition_failed"
+	codePreconditionRequired   = "precondition_required"
+	codeUnsupportedContentType = "unsupported_content_type"
+	codeBodyTooLarge           = "body_too_large"
+	codeInvalidQueryParams     = "invalid_query_params"
+	codeNotImplemented         = "not_implemented"
+	codeQuotaExceeded          = "quota_exceeded"
+	codeKeyReused              = "idempotency_key_reused"
+	codeUnavailable            = "unavailable"
+	codeInternal               = "internal"
+)
+
+type errorRes struct {
+	Err  string `json:"error"`
+	Code string `json:"code"`
+}
+
 type identityRes struct {
 	id string
 }
 
 func (res identityRes) Headers() map[string]string {
 	return map[string]string{
-		"X-thing-id": res.id,
+		"X-Thing-ID": res.id,
 	}
 }
 
@@ -39,6 +91,23 @@ func (res identityRes) Empty() bool {
 	return true
 }
 
+type thingIdentityRes struct {
+	ID    string `json:"id"`
+	Owner string `json:"owner"`
+}
+
+func (res thingIdentityRes) Code() int {
+	return http.StatusOK
+}
+
+func (res thingIdentityRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res thingIdentityRes) Empty() bool {
+	return false
+}
+
 type removeRes struct{}
 
 func (res removeRes) Code() int {
@@ -54,8 +123,9 @@ func (res removeRes) Empty() bool {
 }
 
 type thingRes struct {
-	id      string
-	created bool
+	id       string
+	created  bool
+	existing bool
 }
 
 func (res thingRes) Code() int {
@@ -67,7 +137,7 @@ func (res thingRes) Code() int {
 }
 
 func (res thingRes) Headers() map[string]string {
-	if res.created {
+	if res.created || res.existing {
 		return map[string]string{
 			"Location": fmt.Sprint("/things/", res.id),
 		}
@@ -80,24 +150,164 @@ func (res thingRes) Empty() bool {
 	return true
 }
 
+type keyRes struct {
+	Key string `json:"key"`
+}
+
+func (res keyRes) Code() int {
+	return http.StatusOK
+}
+
+func (res keyRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res keyRes) Empty() bool {
+	return false
+}
+
+type createThingsRes struct {
+	Things  []things.Thing `json:"things"`
+	created bool
+}
+
+func (res createThingsRes) Code() int {
+	if !res.created {
+		return http.StatusOK
+	}
+
+	return http.StatusCreated
+}
+
+func (res createThingsRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res createThingsRes) Empty() bool {
+	return false
+}
+
 type viewThingRes struct {
 	things.Thing
+	Channels    []channelSummary `json:"channels,omitempty"`
+	etag        string
+	notModified bool
+}
+
+type channelSummary struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
 }
 
 func (res viewThingRes) Code() int {
+	if res.notModified {
+		return http.StatusNotModified
+	}
+
 	return http.StatusOK
 }
 
 func (res viewThingRes) Headers() map[string]string {
-	return map[string]string{}
+	if res.etag == "" {
+		return map[string]string{}
+	}
+
+	return map[string]string{
+		"ETag": res.etag,
+	}
 }
 
 func (res viewThingRes) Empty() bool {
+	return res.notModified
+}
+
+// thingETag computes the entity tag of the thing's representation, which
+// changes whenever the thing is updated. The tag is the quoted version of the
+// thing.
+func thingETag(thing things.Thing) string {
+	return fmt.Sprintf(`"%d"`, thing.Version)
+}
+
+// ifMatchVersion resolves the version of the thing the If-Match header value
+// refers to, using the strong comparison. The wildcard matches any version,
+// which is reported as zero. Since the tag that isn't the thing's version
+// can't match, ErrVersionMismatch is returned for it.
+func ifMatchVersion(header string) (uint64, error) {
+	tag := strings.TrimSpace(header)
+	if tag == "*" {
+		return 0, nil
+	}
+
+	if len(tag) < 2 || !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) {
+		return 0, things.ErrVersionMismatch
+	}
+
+	version, err := strconv.ParseUint(tag[1:len(tag)-1], 10, 64)
+	if err != nil || version == 0 {
+		return 0, things.ErrVersionMismatch
+	}
+
+	return version, nil
+}
+
+// etagMatches determines whether the If-None-Match header value matches the
+// provided entity tag, using the weak comparison.
+func etagMatches(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+
 	return false
 }
 
+// pageLinks contains the absolute URLs of the pages neighbouring the
+// retrieved one. Next page is omitted when the retrieved page is the last one,
+// and previous page is omitted when the retrieved page is the first one.
+type pageLinks struct {
+	First string `json:"first"`
+	Next  string `json:"next,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+}
+
+func newPageLinks(base *url.URL, offset, limit int, last bool) pageLinks {
+	links := pageLinks{
+		First: pageURL(base, 0, limit),
+	}
+
+	if !last {
+		links.Next = pageURL(base, offset+limit, limit)
+	}
+
+	if offset > 0 {
+		prev := offset - limit
+		if prev < 0 {
+			prev = 0
+		}
+		links.Prev = pageURL(base, prev, limit)
+	}
+
+	return links
+}
+
+func pageURL(base *url.URL, offset, limit int) string {
+	u := *base
+	q := u.Query()
+	q.Set("offset", strconv.Itoa(offset))
+	q.Set("limit", strconv.Itoa(limit))
+	u.RawQuery = q.Encode()
+
+	return u.String()
+}
+
 type listThingsRes struct {
 	Things []things.Thing `json:"things"`
+	Total  int            `json:"total"`
+	Offset int            `json:"offset"`
+	Limit  int            `json:"limit"`
+	Links  pageLinks      `json:"links"`
 }
 
 func (res listThingsRes) Code() int {
@@ -112,6 +322,185 @@ func (res listThingsRes) Empty() bool {
 	return false
 }
 
+type searchThingsRes struct {
+	Things []things.Thing `json:"things"`
+	Offset int            `json:"offset"`
+	Limit  int            `json:"limit"`
+	Links  pageLinks      `json:"links"`
+}
+
+func (res searchThingsRes) Code() int {
+	return http.StatusOK
+}
+
+func (res searchThingsRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res searchThingsRes) Empty() bool {
+	return false
+}
+
+type tokenThingsRes struct {
+	Things    []things.Thing `json:"things"`
+	Limit     int            `json:"limit"`
+	NextToken string         `json:"next_token,omitempty"`
+}
+
+func (res tokenThingsRes) Code() int {
+	return http.StatusOK
+}
+
+func (res tokenThingsRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res tokenThingsRes) Empty() bool {
+	return false
+}
+
+type viewThingsRes struct {
+	Things []things.Thing `json:"things"`
+}
+
+func (res viewThingsRes) Code() int {
+	return http.StatusOK
+}
+
+func (res viewThingsRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res viewThingsRes) Empty() bool {
+	return false
+}
+
+// sparseRes restricts the encoded thing, or each of the things of the encoded
+// page, to the requested fields. The thing's identifier is always included.
+type sparseRes struct {
+	mainflux.Response
+	fields []string
+}
+
+func (res sparseRes) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(res.Response)
+	if err != nil {
+		return nil, err
+	}
+
+	// numbers are kept as they are, so that the large ones don't lose
+	// precision when they are encoded again
+	var doc map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	ths, ok := doc["things"].([]interface{})
+	if !ok {
+		return json.Marshal(project(doc, res.fields))
+	}
+
+	for i, th := range ths {
+		if th, ok := th.(map[string]interface{}); ok {
+			ths[i] = project(th, res.fields)
+		}
+	}
+
+	return json.Marshal(doc)
+}
+
+// project retains only the identifier and the provided fields of the encoded
+// thing.
+func project(thing map[string]interface{}, fields []string) map[string]interface{} {
+	projected := map[string]interface{}{"id": thing["id"]}
+	for _, field := range fields {
+		if val, ok := thing[field]; ok {
+			projected[field] = val
+		}
+	}
+
+	return projected
+}
+
+// csvThingsRes is the CSV representation of the list of things, holding a
+// header row followed by a row per thing. Since it is meant to be imported as
+// it is, it omits the pagination details of the JSON representation.
+type csvThingsRes struct {
+	Things []things.Thing
+}
+
+func (res csvThingsRes) Code() int {
+	return http.StatusOK
+}
+
+func (res csvThingsRes) Headers() map[string]string {
+	return map[string]string{
+		"Content-Type": csvContentType,
+	}
+}
+
+func (res csvThingsRes) Empty() bool {
+	return false
+}
+
+func (res csvThingsRes) encode(w http.ResponseWriter, noBody bool) error {
+	for k, v := range res.Headers() {
+		w.Header().Set(k, v)
+	}
+	w.WriteHeader(res.Code())
+
+	if noBody {
+		return nil
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"id", "name", "key", "status"}); err != nil {
+		return err
+	}
+	for _, th := range res.Things {
+		if err := cw.Write([]string{csvCell(th.ID), csvCell(th.Name), csvCell(th.Key), csvCell(th.Status)}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// csvCell escapes the value starting like the spreadsheet formula, so that
+// it is imported as the plain text rather than evaluated.
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@", rune(value[0])) {
+		return "'" + value
+	}
+
+	return value
+}
+
+// listedThings returns the things held by any of the thing list responses.
+func listedThings(res mainflux.Response) []things.Thing {
+	switch res := res.(type) {
+	case listThingsRes:
+		return res.Things
+	case searchThingsRes:
+		return res.Things
+	case tokenThingsRes:
+		return res.Things
+	case viewThingsRes:
+		return res.Things
+	default:
+		return nil
+	}
+}
+
+// pageToken encodes the identifier of the last retrieved thing as the opaque
+// token of the next page.
+func pageToken(id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(id))
+}
+
 type channelRes struct {
 	id      string
 	created bool
@@ -141,6 +530,34 @@ func (res channelRes) Empty() bool {
 
 type viewChannelRes struct {
 	things.Channel
+	Connections []channelConnection `json:"connections,omitempty"`
+}
+
+// withoutSecret returns the copy of the channel whose webhook omits the
+// secret. The secret is write-only, so it is never sent back to the clients.
+func withoutSecret(channel things.Channel) things.Channel {
+	if channel.Webhook != nil {
+		channel.Webhook = &things.Webhook{URL: channel.Webhook.URL}
+	}
+
+	return channel
+}
+
+// withoutSecrets returns the copies of the channels whose webhooks omit the
+// secrets.
+func withoutSecrets(channels []things.Channel) []things.Channel {
+	res := make([]things.Channel, len(channels))
+	for i, channel := range channels {
+		res[i] = withoutSecret(channel)
+	}
+
+	return res
+}
+
+type channelConnection struct {
+	ThingID     string    `json:"thing_id"`
+	Mode        string    `json:"mode"`
+	ConnectedAt time.Time `json:"connected_at"`
 }
 
 func (res viewChannelRes) Code() int {
@@ -157,6 +574,10 @@ func (res viewChannelRes) Empty() bool {
 
 type listChannelsRes struct {
 	Channels []things.Channel `json:"channels"`
+	Total    int              `json:"total"`
+	Offset   int              `json:"offset"`
+	Limit    int              `json:"limit"`
+	Links    pageLinks        `json:"links"`
 }
 
 func (res listChannelsRes) Code() int {
@@ -171,6 +592,27 @@ func (res listChannelsRes) Empty() bool {
 	return false
 }
 
+type connectRes struct {
+	ChanID      string    `json:"channel_id"`
+	ThingID     string    `json:"thing_id"`
+	Mode        string    `json:"mode"`
+	ConnectedAt time.Time `json:"connected_at"`
+}
+
+func (res connectRes) Code() int {
+	return http.StatusCreated
+}
+
+func (res connectRes) Headers() map[string]string {
+	return map[string]string{
+		"Location": fmt.Sprintf("/channels/%s/things/%s", res.ChanID, res.ThingID),
+	}
+}
+
+func (res connectRes) Empty() bool {
+	return false
+}
+
 type connectionRes struct{}
 
 func (res connectionRes) Code() int {
@@ -185,6 +627,24 @@ func (res connectionRes) Empty() bool {
 	return true
 }
 
+type disconnectRes struct {
+	ChanID       string `json:"channel_id"`
+	ThingID      string `json:"thing_id"`
+	Disconnected bool   `json:"disconnected"`
+}
+
+func (res disconnectRes) Code() int {
+	return http.StatusOK
+}
+
+func (res disconnectRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res disconnectRes) Empty() bool {
+	return false
+}
+
 type disconnectionRes struct{}
 
 func (res disconnectionRes) Code() int {
@@ -198,3 +658,71 @@ func (res disconnectionRes) Headers() map[string]string {
 func (res disconnectionRes) Empty() bool {
 	return true
 }
+
+type connectionStatusRes struct {
+	Connected bool `json:"connected"`
+}
+
+func (res connectionStatusRes) Code() int {
+	return http.StatusOK
+}
+
+func (res connectionStatusRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res connectionStatusRes) Empty() bool {
+	return false
+}
+
+type countRes struct {
+	Count int `json:"count"`
+}
+
+func (res countRes) Code() int {
+	return http.StatusOK
+}
+
+func (res countRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res countRes) Empty() bool {
+	return false
+}
+
+type channelOwnerRes struct {
+	Owner string `json:"owner"`
+}
+
+func (res channelOwnerRes) Code() int {
+	return http.StatusOK
+}
+
+func (res channelOwnerRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res channelOwnerRes) Empty() bool {
+	return false
+}
+
+type healthRes struct {
+	Status string `json:"status"`
+}
+
+func (res healthRes) Code() int {
+	if res.Status != healthPass {
+		return http.StatusServiceUnavailable
+	}
+
+	return http.StatusOK
+}
+
+func (res healthRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res healthRes) Empty() bool {
+	return false
+}
@@ -0,0 +1,88 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-zoo/bone"
+	"github.com/mainflux/mainflux/things"
+)
+
+const (
+	eventStreamContentType = "text/event-stream"
+	eventsPathSuffix       = "/events"
+)
+
+type connectionEventRes struct {
+	Event     string    `json:"event"`
+	ChanID    string    `json:"channel_id"`
+	ThingID   string    `json:"thing_id"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// channelEvents streams the connection events of the requested channel as
+// the server-sent events, until the client closes the connection. Each
+// event is named after its type, and carries its JSON representation.
+func channelEvents(svc things.Service, bus things.EventBus) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		req := viewResourceReq{
+			key: r.Header.Get("Authorization"),
+			id:  bone.GetValue(r, "id"),
+		}
+
+		if err := req.validate(); err != nil {
+			encodeError(ctx, err, w)
+			return
+		}
+
+		channel, err := svc.ViewChannel(ctx, req.key, req.id)
+		if err != nil {
+			encodeError(ctx, err, w)
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if bus == nil || !ok {
+			encodeError(ctx, errStreamingUnsupported, w)
+			return
+		}
+
+		events, cancel := bus.Subscribe(channel.ID)
+		defer cancel()
+
+		w.Header().Set("Content-Type", eventStreamContentType)
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-events:
+				if event.Owner != channel.Owner {
+					continue
+				}
+
+				data, err := json.Marshal(connectionEventRes{
+					Event:     event.Type,
+					ChanID:    event.ChanID,
+					ThingID:   event.EntityID,
+					Timestamp: event.Timestamp.UTC(),
+				})
+				if err != nil {
+					continue
+				}
+
+				if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+					return
+				}
+				flusher.Flush()
+			}
+		}
+	}
+}
@@ -2,12 +2,16 @@ package http
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"io"
+	"mime"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	kithttp "github.com/go-kit/kit/transport/http"
 	"github.com/go-zoo/bone"
@@ -16,26 +20,181 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-const contentType = "application/json"
+const (
+	contentType           = "application/json"
+	mergePatchContentType = "application/merge-patch+json"
+	csvContentType        = "text/csv"
+	requestIDHeader       = "X-Request-ID"
+	channelIDHeader       = "X-Channel-ID"
+	configPath            = "/config"
+	defLimit              = 10
+	defMaxBodySize        = 1 << 20
+)
 
 var (
 	errUnsupportedContentType = errors.New("unsupported content type")
 	errInvalidQueryParams     = errors.New("invalid query params")
+	errInvalidThingKey        = errors.New("invalid thing key")
+	errMissingIfMatch         = errors.New("missing If-Match header")
+	errStreamingUnsupported   = errors.New("event streaming unsupported")
+	errBodyTooLarge           = errors.New("request body too large")
 )
 
-// MakeHandler returns a HTTP handler for API endpoints.
-func MakeHandler(svc things.Service) http.Handler {
+// Option configures the HTTP handler created by MakeHandler.
+type Option func(*handlerConfig)
+
+type handlerConfig struct {
+	basicAuth      bool
+	bus            things.EventBus
+	limits         pageLimits
+	idempotency    things.IdempotencyStore
+	idempotencyTTL time.Duration
+	maxBodySize    int64
+	idempotentDisc bool
+}
+
+// pageLimits holds the page size of the list requests lacking the limit
+// query parameter, and the largest page size they may request.
+type pageLimits struct {
+	def int
+	max int
+}
+
+type limitsKey struct{}
+
+type bodySizeKey struct{}
+
+// WithBasicAuth makes the handler accept the key provided as the password of
+// the HTTP Basic credentials, as an alternative to providing it directly as
+// the Authorization header value. The latter takes precedence when both are
+// provided.
+func WithBasicAuth() Option {
+	return func(cfg *handlerConfig) {
+		cfg.basicAuth = true
+	}
+}
+
+// WithEventBus makes the handler stream the connection events of the
+// channels, as delivered by the provided event bus. Without it, the event
+// streams are unsupported.
+func WithEventBus(bus things.EventBus) Option {
+	return func(cfg *handlerConfig) {
+		cfg.bus = bus
+	}
+}
+
+// WithIdempotency makes the thing creation requests carrying the
+// Idempotency-Key header safe to retry. The response to the first request
+// with the given key is kept by the provided store for the provided TTL, and
+// its retries are answered with it instead of creating duplicate things.
+func WithIdempotency(store things.IdempotencyStore, ttl time.Duration) Option {
+	return func(cfg *handlerConfig) {
+		cfg.idempotency = store
+		cfg.idempotencyTTL = ttl
+	}
+}
+
+// WithLimits sets the page size of the list requests lacking the limit query
+// parameter, and the largest page size they may request. By default, the
+// pages hold 10 resources and may hold up to 100 of them.
+func WithLimits(def, max int) Option {
+	return func(cfg *handlerConfig) {
+		cfg.limits = pageLimits{def: def, max: max}
+	}
+}
+
+// WithMaxBodySize sets the largest size, in bytes, of the request bodies.
+// Larger bodies are rejected without being read past the limit. By default,
+// the bodies may take up to 1MB.
+func WithMaxBodySize(size int64) Option {
+	return func(cfg *handlerConfig) {
+		cfg.maxBodySize = size
+	}
+}
+
+// WithIdempotentDisconnect makes disconnecting the thing that isn't connected
+// to the channel succeed, reporting that nothing was disconnected. By default,
+// it is rejected as not found.
+func WithIdempotentDisconnect() Option {
+	return func(cfg *handlerConfig) {
+		cfg.idempotentDisc = true
+	}
+}
+
+// MakeHandler returns a HTTP handler for API endpoints. Requests lacking the
+// X-Request-ID header are assigned the identifier generated by the provided
+// identity provider. Cross-origin requests are allowed only from the provided
+// origins, where "*" allows any origin. Large responses are gzip-encoded for
+// the clients that accept it. The effective non-sensitive configuration of
+// the handler is served at /config.
+func MakeHandler(svc things.Service, idp things.IdentityProvider, origins []string, opts ...Option) http.Handler {
+	cfg := handlerConfig{
+		limits:      pageLimits{def: defLimit, max: maxLimitSize},
+		maxBodySize: defMaxBodySize,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	r := makeRouter(svc, cfg)
+	registerPreflight(r)
+
+	r.GetFunc("/version", mainflux.Version("things"))
+	r.GetFunc(configPath, serveConfig(cfg))
+	r.GetFunc(openAPIPath, serveOpenAPI)
+	r.Handle(metricsPath, promhttp.Handler())
+
+	var h http.Handler = r
+	if cfg.basicAuth {
+		h = basicAuth(h)
+	}
+
+	return cors(requestID(compress(h), idp), origins)
+}
+
+// makeRouter registers the API endpoints, all of which are described by the
+// OpenAPI document.
+func makeRouter(svc things.Service, cfg handlerConfig) *bone.Mux {
 	opts := []kithttp.ServerOption{
 		kithttp.ServerErrorEncoder(encodeError),
+		kithttp.ServerBefore(func(ctx context.Context, _ *http.Request) context.Context {
+			ctx = context.WithValue(ctx, limitsKey{}, cfg.limits)
+			return context.WithValue(ctx, bodySizeKey{}, cfg.maxBodySize)
+		}),
 	}
 
 	r := bone.New()
 
-	r.Post("/things", kithttp.NewServer(
+	var addThing http.Handler = kithttp.NewServer(
 		addThingEndpoint(svc),
 		decodeThingCreation,
 		encodeResponse,
 		opts...,
+	)
+	if cfg.idempotency != nil {
+		addThing = idempotent(addThing, cfg.idempotency, cfg.idempotencyTTL, cfg.maxBodySize)
+	}
+	r.Post("/things", addThing)
+
+	r.Delete("/things", kithttp.NewServer(
+		removeAllThingsEndpoint(svc),
+		decodeIdentity,
+		encodeResponse,
+		opts...,
+	))
+
+	r.Post("/things/identify", kithttp.NewServer(
+		identifyThingEndpoint(svc),
+		decodeThingIdentification,
+		encodeResponse,
+		opts...,
+	))
+
+	r.Post("/things/bulk", kithttp.NewServer(
+		createThingsEndpoint(svc),
+		decodeThingsCreation,
+		encodeResponse,
+		opts...,
 	))
 
 	r.Put("/things/:id", kithttp.NewServer(
@@ -45,6 +204,20 @@ func MakeHandler(svc things.Service) http.Handler {
 		opts...,
 	))
 
+	r.Patch("/things/:id", kithttp.NewServer(
+		patchThingEndpoint(svc),
+		decodeThingPatch,
+		encodeResponse,
+		opts...,
+	))
+
+	r.Patch("/things/:id/key", kithttp.NewServer(
+		updateKeyEndpoint(svc),
+		decodeKeyUpdate,
+		encodeResponse,
+		opts...,
+	))
+
 	r.Delete("/things/:id", kithttp.NewServer(
 		removeThingEndpoint(svc),
 		decodeView,
@@ -52,20 +225,100 @@ func MakeHandler(svc things.Service) http.Handler {
 		opts...,
 	))
 
+	r.Post("/things/:id/key/rotate", kithttp.NewServer(
+		rotateKeyEndpoint(svc),
+		decodeView,
+		encodeResponse,
+		opts...,
+	))
+
+	r.Post("/things/:id/disable", kithttp.NewServer(
+		disableThingEndpoint(svc),
+		decodeView,
+		encodeResponse,
+		opts...,
+	))
+
+	r.Post("/things/:id/enable", kithttp.NewServer(
+		enableThingEndpoint(svc),
+		decodeView,
+		encodeResponse,
+		opts...,
+	))
+
+	r.Post("/things/:id/restore", kithttp.NewServer(
+		restoreThingEndpoint(svc),
+		decodeView,
+		encodeResponse,
+		opts...,
+	))
+
+	r.Post("/things/:id/transfer", kithttp.NewServer(
+		transferThingEndpoint(svc),
+		decodeTransfer,
+		encodeResponse,
+		opts...,
+	))
+
+	// must be registered before the view route, since the latter would
+	// match the count path as well
+	r.Get("/things/count", kithttp.NewServer(
+		countThingsEndpoint(svc),
+		decodeThingsCount,
+		encodeResponse,
+		opts...,
+	))
+
 	r.Get("/things/:id", kithttp.NewServer(
 		viewThingEndpoint(svc),
-		decodeView,
+		decodeThingView,
 		encodeResponse,
 		opts...,
 	))
 
+	r.Head("/things/:id", kithttp.NewServer(
+		viewThingEndpoint(svc),
+		decodeThingView,
+		encodeHeadResponse,
+		opts...,
+	))
+
 	r.Get("/things", kithttp.NewServer(
 		listThingsEndpoint(svc),
-		decodeList,
+		decodeThingsList,
+		encodeThingsList,
+		opts...,
+	))
+
+	r.Get("/things/:id/channels", kithttp.NewServer(
+		listChannelsByThingEndpoint(svc),
+		decodeListByConnection,
+		encodeResponse,
+		opts...,
+	))
+
+	r.Put("/things/:id/channels", kithttp.NewServer(
+		connectManyEndpoint(svc),
+		decodeConnectMany,
 		encodeResponse,
 		opts...,
 	))
 
+	r.Delete("/things/:id/channels", withBody(
+		kithttp.NewServer(
+			disconnectManyEndpoint(svc),
+			decodeConnectMany,
+			encodeResponse,
+			opts...,
+		),
+		kithttp.NewServer(
+			disconnectAllEndpoint(svc),
+			decodeView,
+			encodeResponse,
+			opts...,
+		),
+	))
+
 	r.Post("/channels", kithttp.NewServer(
 		createChannelEndpoint(svc),
 		decodeChannelCreation,
@@ -80,6 +333,13 @@ func MakeHandler(svc things.Service) http.Handler {
 		opts...,
 	))
 
+	r.Patch("/channels/:id", kithttp.NewServer(
+		patchChannelEndpoint(svc),
+		decodeChannelPatch,
+		encodeResponse,
+		opts...,
+	))
+
 	r.Delete("/channels/:id", kithttp.NewServer(
 		removeChannelEndpoint(svc),
 		decodeView,
@@ -87,47 +347,280 @@ func MakeHandler(svc things.Service) http.Handler {
 		opts...,
 	))
 
+	r.Post("/channels/:id/restore", kithttp.NewServer(
+		restoreChannelEndpoint(svc),
+		decodeView,
+		encodeResponse,
+		opts...,
+	))
+
+	r.Post("/channels/:id/transfer", kithttp.NewServer(
+		transferChannelEndpoint(svc),
+		decodeTransfer,
+		encodeResponse,
+		opts...,
+	))
+
+	r.Get("/channels/count", kithttp.NewServer(
+		countChannelsEndpoint(svc),
+		decodeIdentity,
+		encodeResponse,
+		opts...,
+	))
+
 	r.Get("/channels/:id", kithttp.NewServer(
 		viewChannelEndpoint(svc),
-		decodeView,
+		decodeChannelView,
 		encodeResponse,
 		opts...,
 	))
 
+	r.Head("/channels/:id", kithttp.NewServer(
+		viewChannelEndpoint(svc),
+		decodeChannelView,
+		encodeHeadResponse,
+		opts...,
+	))
+
 	r.Get("/channels", kithttp.NewServer(
 		listChannelsEndpoint(svc),
-		decodeList,
+		decodeChannelsList,
+		encodeResponse,
+		opts...,
+	))
+
+	r.Delete("/channels", kithttp.NewServer(
+		removeAllChannelsEndpoint(svc),
+		decodeIdentity,
+		encodeResponse,
+		opts...,
+	))
+
+	r.Get("/channels/:id/owner", kithttp.NewServer(
+		channelOwnerEndpoint(svc),
+		decodeView,
+		encodeResponse,
+		opts...,
+	))
+
+	r.Get("/channels/:id/events", channelEvents(svc, cfg.bus))
+
+	r.Get("/channels/:id/things/count", kithttp.NewServer(
+		channelConnectionsCountEndpoint(svc),
+		decodeView,
+		encodeResponse,
+		opts...,
+	))
+
+	r.Get("/channels/:id/things", kithttp.NewServer(
+		listThingsByChannelEndpoint(svc),
+		decodeThingsByChannel,
+		encodeResponse,
+		opts...,
+	))
+
+	r.Put("/channels/:id/things", kithttp.NewServer(
+		connectThingsEndpoint(svc),
+		decodeConnectThings,
+		encodeResponse,
+		opts...,
+	))
+
+	r.Post("/channels/:id/things/sync", kithttp.NewServer(
+		setChannelThingsEndpoint(svc),
+		decodeSetChannelThings,
 		encodeResponse,
 		opts...,
 	))
 
 	r.Put("/channels/:chanId/things/:thingId", kithttp.NewServer(
 		connectEndpoint(svc),
+		decodeConnect,
+		encodeResponse,
+		opts...,
+	))
+
+	r.Get("/channels/:chanId/things/:thingId", kithttp.NewServer(
+		isConnectedEndpoint(svc),
 		decodeConnection,
 		encodeResponse,
 		opts...,
 	))
 
 	r.Delete("/channels/:chanId/things/:thingId", kithttp.NewServer(
-		disconnectEndpoint(svc),
+		disconnectEndpoint(svc, cfg.idempotentDisc),
 		decodeConnection,
 		encodeResponse,
 		opts...,
 	))
 
-	r.GetFunc("/version", mainflux.Version("things"))
-	r.Handle("/metrics", promhttp.Handler())
+	r.Get("/access", kithttp.NewServer(
+		canAccessEndpoint(svc),
+		decodeAccess,
+		encodeResponse,
+		kithttp.ServerErrorEncoder(encodeAccessError),
+	))
+
+	r.Get("/health", kithttp.NewServer(
+		healthEndpoint(svc),
+		decodeHealth,
+		encodeResponse,
+		opts...,
+	))
 
 	return r
 }
 
-func decodeThingCreation(_ context.Context, r *http.Request) (interface{}, error) {
-	if r.Header.Get("Content-Type") != contentType {
+// serveConfig serves the handler's configuration, leaving out the values
+// which shouldn't be disclosed to the clients.
+func serveConfig(cfg handlerConfig) http.HandlerFunc {
+	res := struct {
+		DefaultLimit         int   `json:"default_limit"`
+		MaxLimit             int   `json:"max_limit"`
+		MaxBodySize          int64 `json:"max_body_size"`
+		BasicAuth            bool  `json:"basic_auth"`
+		Events               bool  `json:"events"`
+		IdempotentDisconnect bool  `json:"idempotent_disconnect"`
+	}{
+		DefaultLimit:         cfg.limits.def,
+		MaxLimit:             cfg.limits.max,
+		MaxBodySize:          cfg.maxBodySize,
+		BasicAuth:            cfg.basicAuth,
+		Events:               cfg.bus != nil,
+		IdempotentDisconnect: cfg.idempotentDisc,
+	}
+
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		json.NewEncoder(w).Encode(res)
+	}
+}
+
+// decodeBody decodes the JSON request body into the provided value. Bodies
+// exceeding the size limit of the handler are rejected with errBodyTooLarge.
+func decodeBody(ctx context.Context, r *http.Request, v interface{}) error {
+	size, ok := ctx.Value(bodySizeKey{}).(int64)
+	if !ok {
+		size = defMaxBodySize
+	}
+
+	return json.NewDecoder(&limitedReader{r: r.Body, n: size}).Decode(v)
+}
+
+// limitedReader reads at most n bytes from the underlying reader, failing
+// with errBodyTooLarge as soon as it turns out that there are more of them.
+type limitedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (lr *limitedReader) Read(p []byte) (int, error) {
+	if lr.n < 0 {
+		return 0, errBodyTooLarge
+	}
+
+	// a byte past the limit is requested, so that the exceeded limit is
+	// noticed even if the body ends right after it
+	if int64(len(p)) > lr.n+1 {
+		p = p[:lr.n+1]
+	}
+
+	n, err := lr.r.Read(p)
+	if int64(n) > lr.n {
+		n, lr.n = int(lr.n), -1
+		return n, errBodyTooLarge
+	}
+	lr.n -= int64(n)
+
+	return n, err
+}
+
+// requestID makes the request identifier available through the request's
+// context, and echoes it back in the response header.
+func requestID(next http.Handler, idp things.IdentityProvider) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if id == "" {
+			id = idp.ID()
+		}
+
+		w.Header().Set(requestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(things.WithRequestID(r.Context(), id)))
+	})
+}
+
+// withBody dispatches the requests carrying a body to the first handler, and
+// the ones without it to the second.
+func withBody(body, empty http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength == 0 {
+			empty.ServeHTTP(w, r)
+			return
+		}
+
+		body.ServeHTTP(w, r)
+	})
+}
+
+func decodeHealth(_ context.Context, _ *http.Request) (interface{}, error) {
+	return nil, nil
+}
+
+func decodeThingIdentification(ctx context.Context, r *http.Request) (interface{}, error) {
+	if !isJSON(r) {
+		return nil, errUnsupportedContentType
+	}
+
+	var req identifyThingReq
+	if err := decodeBody(ctx, r, &req); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func decodeAccess(_ context.Context, r *http.Request) (interface{}, error) {
+	mode, err := decodeMode(r)
+	if err != nil {
+		return nil, err
+	}
+
+	req := accessReq{
+		key:    r.Header.Get("Authorization"),
+		chanID: r.Header.Get(channelIDHeader),
+		mode:   mode,
+	}
+
+	return req, nil
+}
+
+// decodeMode parses the access mode of the connection. Requests lacking the
+// mode query parameter ask both to publish and to subscribe.
+func decodeMode(r *http.Request) (things.AccessMode, error) {
+	mode := r.URL.Query()["mode"]
+	if len(mode) == 0 {
+		return things.AccessPubSub, nil
+	}
+
+	if len(mode) > 1 {
+		return "", errInvalidQueryParams
+	}
+
+	m := things.AccessMode(mode[0])
+	if err := m.Validate(); err != nil {
+		return "", errInvalidQueryParams
+	}
+
+	return m, nil
+}
+
+func decodeThingCreation(ctx context.Context, r *http.Request) (interface{}, error) {
+	if !isJSON(r) {
 		return nil, errUnsupportedContentType
 	}
 
 	var thing things.Thing
-	if err := json.NewDecoder(r.Body).Decode(&thing); err != nil {
+	if err := decodeBody(ctx, r, &thing); err != nil {
 		return nil, err
 	}
 
@@ -139,32 +632,117 @@ func decodeThingCreation(_ context.Context, r *http.Request) (interface{}, error
 	return req, nil
 }
 
-func decodeThingUpdate(_ context.Context, r *http.Request) (interface{}, error) {
-	if r.Header.Get("Content-Type") != contentType {
+func decodeThingsCreation(ctx context.Context, r *http.Request) (interface{}, error) {
+	if !isJSON(r) {
+		return nil, errUnsupportedContentType
+	}
+
+	dryRun := r.URL.Query()["dry_run"]
+	if len(dryRun) > 1 {
+		return nil, errInvalidQueryParams
+	}
+
+	var ths []things.Thing
+	if err := decodeBody(ctx, r, &ths); err != nil {
+		return nil, err
+	}
+
+	req := createThingsReq{
+		key:    r.Header.Get("Authorization"),
+		things: ths,
+	}
+
+	if len(dryRun) == 1 {
+		var err error
+		if req.dryRun, err = strconv.ParseBool(dryRun[0]); err != nil {
+			return nil, errInvalidQueryParams
+		}
+	}
+
+	return req, nil
+}
+
+func decodeThingUpdate(ctx context.Context, r *http.Request) (interface{}, error) {
+	if !isJSON(r) {
 		return nil, errUnsupportedContentType
 	}
 
 	var thing things.Thing
-	if err := json.NewDecoder(r.Body).Decode(&thing); err != nil {
+	if err := decodeBody(ctx, r, &thing); err != nil {
 		return nil, err
 	}
 
 	req := updateThingReq{
+		key:     r.Header.Get("Authorization"),
+		id:      bone.GetValue(r, "id"),
+		ifMatch: r.Header.Get("If-Match"),
+		thing:   thing,
+	}
+
+	return req, nil
+}
+
+func decodeThingPatch(ctx context.Context, r *http.Request) (interface{}, error) {
+	if !isJSON(r) {
+		return nil, errUnsupportedContentType
+	}
+
+	var patch thingPatch
+	if err := decodeBody(ctx, r, &patch); err != nil {
+		return nil, err
+	}
+
+	req := patchThingReq{
 		key:   r.Header.Get("Authorization"),
 		id:    bone.GetValue(r, "id"),
-		thing: thing,
+		patch: patch,
 	}
 
 	return req, nil
 }
 
-func decodeChannelCreation(_ context.Context, r *http.Request) (interface{}, error) {
-	if r.Header.Get("Content-Type") != contentType {
+func decodeKeyUpdate(ctx context.Context, r *http.Request) (interface{}, error) {
+	if !isJSON(r) {
+		return nil, errUnsupportedContentType
+	}
+
+	var thing things.Thing
+	if err := decodeBody(ctx, r, &thing); err != nil {
+		return nil, err
+	}
+
+	req := updateKeyReq{
+		key:    r.Header.Get("Authorization"),
+		id:     bone.GetValue(r, "id"),
+		newKey: thing.Key,
+	}
+
+	return req, nil
+}
+
+func decodeTransfer(ctx context.Context, r *http.Request) (interface{}, error) {
+	if !isJSON(r) {
+		return nil, errUnsupportedContentType
+	}
+
+	req := transferReq{
+		key: r.Header.Get("Authorization"),
+		id:  bone.GetValue(r, "id"),
+	}
+	if err := decodeBody(ctx, r, &req); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func decodeChannelCreation(ctx context.Context, r *http.Request) (interface{}, error) {
+	if !isJSON(r) {
 		return nil, errUnsupportedContentType
 	}
 
 	var channel things.Channel
-	if err := json.NewDecoder(r.Body).Decode(&channel); err != nil {
+	if err := decodeBody(ctx, r, &channel); err != nil {
 		return nil, err
 	}
 
@@ -176,13 +754,13 @@ func decodeChannelCreation(_ context.Context, r *http.Request) (interface{}, err
 	return req, nil
 }
 
-func decodeChannelUpdate(_ context.Context, r *http.Request) (interface{}, error) {
-	if r.Header.Get("Content-Type") != contentType {
+func decodeChannelUpdate(ctx context.Context, r *http.Request) (interface{}, error) {
+	if !isJSON(r) {
 		return nil, errUnsupportedContentType
 	}
 
 	var channel things.Channel
-	if err := json.NewDecoder(r.Body).Decode(&channel); err != nil {
+	if err := decodeBody(ctx, r, &channel); err != nil {
 		return nil, err
 	}
 
@@ -195,6 +773,46 @@ func decodeChannelUpdate(_ context.Context, r *http.Request) (interface{}, error
 	return req, nil
 }
 
+func decodeChannelPatch(ctx context.Context, r *http.Request) (interface{}, error) {
+	if !isJSON(r) && !isMergePatch(r) {
+		return nil, errUnsupportedContentType
+	}
+
+	var patch channelPatch
+	if err := decodeBody(ctx, r, &patch); err != nil {
+		return nil, err
+	}
+
+	req := patchChannelReq{
+		key:   r.Header.Get("Authorization"),
+		id:    bone.GetValue(r, "id"),
+		patch: patch,
+	}
+
+	return req, nil
+}
+
+// isJSON determines whether the request's body is JSON-encoded. Media type
+// parameters (e.g. charset) are ignored.
+func isJSON(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == contentType
+}
+
+// isMergePatch determines whether the request's body is the JSON merge patch.
+func isMergePatch(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == mergePatchContentType
+}
+
+func decodeIdentity(_ context.Context, r *http.Request) (interface{}, error) {
+	req := identityReq{
+		key: r.Header.Get("Authorization"),
+	}
+
+	return req, nil
+}
+
 func decodeView(_ context.Context, r *http.Request) (interface{}, error) {
 	req := viewResourceReq{
 		key: r.Header.Get("Authorization"),
@@ -204,17 +822,93 @@ func decodeView(_ context.Context, r *http.Request) (interface{}, error) {
 	return req, nil
 }
 
-func decodeList(_ context.Context, r *http.Request) (interface{}, error) {
+func decodeThingView(ctx context.Context, r *http.Request) (interface{}, error) {
+	req, err := decodeView(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := decodeFields(r)
+	if err != nil {
+		return nil, err
+	}
+
+	vreq := viewThingReq{
+		viewResourceReq: req.(viewResourceReq),
+		ifNoneMatch:     r.Header.Get("If-None-Match"),
+		fields:          fields,
+	}
+
+	include := r.URL.Query()["include"]
+	if len(include) > 1 {
+		return nil, errInvalidQueryParams
+	}
+
+	if len(include) == 1 {
+		if include[0] != "channels" {
+			return nil, errInvalidQueryParams
+		}
+		vreq.channels = true
+	}
+
+	return vreq, nil
+}
+
+// decodeFields parses the comma-separated sparse fieldset. Nil fieldset is
+// returned if the fields query parameter is not provided.
+func decodeFields(r *http.Request) ([]string, error) {
+	fields := r.URL.Query()["fields"]
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	if len(fields) > 1 {
+		return nil, errInvalidQueryParams
+	}
+
+	return strings.Split(fields[0], ","), nil
+}
+
+func decodeChannelView(ctx context.Context, r *http.Request) (interface{}, error) {
+	req, err := decodeView(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	vreq := viewChannelReq{viewResourceReq: req.(viewResourceReq)}
+	include := r.URL.Query()["include"]
+	if len(include) > 1 {
+		return nil, errInvalidQueryParams
+	}
+
+	if len(include) == 1 {
+		if include[0] != "connections" {
+			return nil, errInvalidQueryParams
+		}
+		vreq.connections = true
+	}
+
+	return vreq, nil
+}
+
+func decodeList(ctx context.Context, r *http.Request) (interface{}, error) {
 	q, err := url.ParseQuery(r.URL.RawQuery)
 	if err != nil {
 		return nil, errInvalidQueryParams
 	}
+
+	limits, ok := ctx.Value(limitsKey{}).(pageLimits)
+	if !ok {
+		limits = pageLimits{def: defLimit, max: maxLimitSize}
+	}
+
 	offset := 0
-	limit := 10
+	limit := limits.def
 
 	off, lmt := q["offset"], q["limit"]
+	order, dir := q["order"], q["dir"]
 
-	if len(off) > 1 || len(lmt) > 1 {
+	if len(off) > 1 || len(lmt) > 1 || len(order) > 1 || len(dir) > 1 {
 		return nil, errInvalidQueryParams
 	}
 
@@ -231,15 +925,232 @@ func decodeList(_ context.Context, r *http.Request) (interface{}, error) {
 			return nil, errInvalidQueryParams
 		}
 	}
+	sorting := things.Sorting{}
+	if len(order) == 1 {
+		sorting.Order = order[0]
+	}
+
+	if len(dir) == 1 {
+		sorting.Dir = dir[0]
+	}
+
+	if err := sorting.Validate(); err != nil {
+		return nil, errInvalidQueryParams
+	}
+
+	// navigation links are built from the absolute request URL
+	u := *r.URL
+	u.Scheme, u.Host = "http", r.Host
+	if r.TLS != nil {
+		u.Scheme = "https"
+	}
+
 	req := listResourcesReq{
-		key:    r.Header.Get("Authorization"),
-		offset: offset,
-		limit:  limit,
+		key:      r.Header.Get("Authorization"),
+		offset:   offset,
+		limit:    limit,
+		maxLimit: limits.max,
+		sorting:  sorting,
+		url:      &u,
 	}
 
 	return req, nil
 }
 
+func decodeThingsList(ctx context.Context, r *http.Request) (interface{}, error) {
+	req, err := decodeList(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	q := r.URL.Query()
+	name, meta, del, typ, status, tag, conn := q["name"], q["metadata"], q["deleted"], q["type"], q["status"], q["tag"], q["connected"]
+	if len(name) > 1 || len(meta) > 1 || len(del) > 1 || len(typ) > 1 || len(status) > 1 || len(tag) > 1 || len(conn) > 1 {
+		return nil, errInvalidQueryParams
+	}
+
+	sreq := searchThingsReq{listResourcesReq: req.(listResourcesReq)}
+	if sreq.fields, err = decodeFields(r); err != nil {
+		return nil, err
+	}
+
+	if ids := q["ids"]; len(ids) > 0 {
+		if len(ids) > 1 {
+			return nil, errInvalidQueryParams
+		}
+		sreq.ids = strings.Split(ids[0], ",")
+	}
+
+	if token := q["page_token"]; len(token) > 0 {
+		// the page token replaces the offset, and fixes the order of things
+		if len(token) > 1 || len(q["offset"]) > 0 || len(q["order"]) > 0 || len(q["dir"]) > 0 {
+			return nil, errInvalidQueryParams
+		}
+
+		id, err := base64.RawURLEncoding.DecodeString(token[0])
+		if err != nil {
+			return nil, errInvalidQueryParams
+		}
+		sreq.paged, sreq.afterID = true, string(id)
+	}
+
+	if len(name) == 1 {
+		sreq.filter.Name = name[0]
+	}
+
+	if len(meta) == 1 {
+		filter, err := decodeMetadataFilter(meta[0])
+		if err != nil {
+			return nil, err
+		}
+		sreq.filter.Metadata = filter
+	}
+
+	if len(del) == 1 {
+		if sreq.deleted, err = strconv.ParseBool(del[0]); err != nil {
+			return nil, errInvalidQueryParams
+		}
+	}
+
+	if len(typ) == 1 {
+		sreq.filter.Type = strings.ToLower(typ[0])
+	}
+
+	if len(status) == 1 {
+		sreq.filter.Status = strings.ToLower(status[0])
+	}
+
+	if len(tag) == 1 {
+		sreq.filter.Tag = tag[0]
+	}
+
+	// only the unconnected things are selected separately, since all of the
+	// things are listed otherwise
+	if len(conn) == 1 {
+		if conn[0] != "false" {
+			return nil, errInvalidQueryParams
+		}
+		sreq.unconnected = true
+	}
+
+	sreq.csv = accepts(r.Header.Get("Accept"), csvContentType)
+
+	return sreq, nil
+}
+
+func decodeThingsCount(_ context.Context, r *http.Request) (interface{}, error) {
+	q := r.URL.Query()
+	name, meta, typ, status, tag := q["name"], q["metadata"], q["type"], q["status"], q["tag"]
+	if len(name) > 1 || len(meta) > 1 || len(typ) > 1 || len(status) > 1 || len(tag) > 1 {
+		return nil, errInvalidQueryParams
+	}
+
+	req := countThingsReq{key: r.Header.Get("Authorization")}
+	if len(name) == 1 {
+		req.filter.Name = name[0]
+	}
+
+	if len(meta) == 1 {
+		filter, err := decodeMetadataFilter(meta[0])
+		if err != nil {
+			return nil, err
+		}
+		req.filter.Metadata = filter
+	}
+
+	if len(typ) == 1 {
+		req.filter.Type = strings.ToLower(typ[0])
+	}
+
+	if len(status) == 1 {
+		req.filter.Status = strings.ToLower(status[0])
+	}
+
+	if len(tag) == 1 {
+		req.filter.Tag = tag[0]
+	}
+
+	return req, nil
+}
+
+func decodeChannelsList(ctx context.Context, r *http.Request) (interface{}, error) {
+	req, err := decodeList(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := r.URL.Query()["metadata"]
+	if len(meta) > 1 {
+		return nil, errInvalidQueryParams
+	}
+
+	lreq := listChannelsReq{listResourcesReq: req.(listResourcesReq)}
+	if len(meta) == 1 {
+		if lreq.metadata, err = decodeMetadataFilter(meta[0]); err != nil {
+			return nil, err
+		}
+	}
+
+	return lreq, nil
+}
+
+// decodeMetadataFilter parses the metadata filter, which is expected in the
+// key:value format.
+func decodeMetadataFilter(filter string) (things.MetadataFilter, error) {
+	pair := strings.SplitN(filter, ":", 2)
+	if len(pair) != 2 || pair[0] == "" {
+		return things.MetadataFilter{}, errInvalidQueryParams
+	}
+
+	return things.MetadataFilter{Key: pair[0], Value: pair[1]}, nil
+}
+
+func decodeListByConnection(ctx context.Context, r *http.Request) (interface{}, error) {
+	req, err := decodeList(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return listByConnectionReq{
+		listResourcesReq: req.(listResourcesReq),
+		id:               bone.GetValue(r, "id"),
+	}, nil
+}
+
+// connectionStates maps the values of the connected query parameter to the
+// connection states they select.
+var connectionStates = map[string]things.ConnectionState{
+	"true":  things.StateConnected,
+	"false": things.StateDisconnected,
+	"all":   things.StateAny,
+}
+
+func decodeThingsByChannel(ctx context.Context, r *http.Request) (interface{}, error) {
+	req, err := decodeListByConnection(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := r.URL.Query()["connected"]
+	if len(conn) > 1 {
+		return nil, errInvalidQueryParams
+	}
+
+	lreq := listThingsByChannelReq{
+		listByConnectionReq: req.(listByConnectionReq),
+		state:               things.StateConnected,
+	}
+	if len(conn) == 1 {
+		state, ok := connectionStates[conn[0]]
+		if !ok {
+			return nil, errInvalidQueryParams
+		}
+		lreq.state = state
+	}
+
+	return lreq, nil
+}
+
 func decodeConnection(_ context.Context, r *http.Request) (interface{}, error) {
 	req := connectionReq{
 		key:     r.Header.Get("Authorization"),
@@ -250,7 +1161,91 @@ func decodeConnection(_ context.Context, r *http.Request) (interface{}, error) {
 	return req, nil
 }
 
+func decodeConnect(_ context.Context, r *http.Request) (interface{}, error) {
+	mode, err := decodeMode(r)
+	if err != nil {
+		return nil, err
+	}
+
+	req := connectionReq{
+		key:     r.Header.Get("Authorization"),
+		chanID:  bone.GetValue(r, "chanId"),
+		thingID: bone.GetValue(r, "thingId"),
+		mode:    mode,
+	}
+
+	return req, nil
+}
+
+func decodeConnectMany(ctx context.Context, r *http.Request) (interface{}, error) {
+	if !isJSON(r) {
+		return nil, errUnsupportedContentType
+	}
+
+	var chanIDs []string
+	if err := decodeBody(ctx, r, &chanIDs); err != nil {
+		return nil, err
+	}
+
+	req := connectManyReq{
+		key:     r.Header.Get("Authorization"),
+		thingID: bone.GetValue(r, "id"),
+		chanIDs: chanIDs,
+	}
+
+	return req, nil
+}
+
+func decodeConnectThings(ctx context.Context, r *http.Request) (interface{}, error) {
+	if !isJSON(r) {
+		return nil, errUnsupportedContentType
+	}
+
+	var thingIDs []string
+	if err := decodeBody(ctx, r, &thingIDs); err != nil {
+		return nil, err
+	}
+
+	req := connectThingsReq{
+		key:      r.Header.Get("Authorization"),
+		chanID:   bone.GetValue(r, "id"),
+		thingIDs: thingIDs,
+	}
+
+	return req, nil
+}
+
+func decodeSetChannelThings(ctx context.Context, r *http.Request) (interface{}, error) {
+	req, err := decodeConnectThings(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return setChannelThingsReq{req.(connectThingsReq)}, nil
+}
+
 func encodeResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	return writeResponse(w, response, false)
+}
+
+// encodeHeadResponse encodes the response of the GET endpoint reused for the
+// HEAD request, reporting its status code and headers without the body.
+func encodeHeadResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	return writeResponse(w, response, true)
+}
+
+// encodeThingsList encodes the list of things either as JSON or as CSV,
+// depending on the representation negotiated by the request's Accept header.
+func encodeThingsList(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	w.Header().Add("Vary", "Accept")
+	return writeResponse(w, response, false)
+}
+
+func writeResponse(w http.ResponseWriter, response interface{}, noBody bool) error {
+	if res, ok := response.(csvThingsRes); ok {
+		return res.encode(w, noBody)
+	}
+
 	w.Header().Set("Content-Type", contentType)
 
 	if ar, ok := response.(mainflux.Response); ok {
@@ -265,37 +1260,87 @@ func encodeResponse(_ context.Context, w http.ResponseWriter, response interface
 		}
 	}
 
+	if noBody {
+		return nil
+	}
+
 	return json.NewEncoder(w).Encode(response)
 }
 
 func encodeError(_ context.Context, err error, w http.ResponseWriter) {
 	w.Header().Set("Content-Type", contentType)
 
+	status, code := errorStatus(err)
+	res := errorRes{Err: err.Error(), Code: code}
+	if status == http.StatusInternalServerError {
+		// internal errors may expose implementation details
+		res.Err = strings.ToLower(http.StatusText(status))
+	}
+
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(res)
+}
+
+// encodeAccessError reports the denied access by the status code alone, as
+// expected by the reverse proxies delegating the authorization of their
+// requests.
+func encodeAccessError(_ context.Context, err error, w http.ResponseWriter) {
+	status, _ := errorStatus(err)
+	if status != http.StatusInternalServerError && status != http.StatusServiceUnavailable {
+		status = http.StatusForbidden
+	}
+
+	w.WriteHeader(status)
+}
+
+// errorStatus maps the provided error to the HTTP status code and the stable
+// error code reported to the client.
+func errorStatus(err error) (int, string) {
 	switch err {
 	case things.ErrMalformedEntity:
-		w.WriteHeader(http.StatusBadRequest)
+		return http.StatusUnprocessableEntity, codeMalformedEntity
+	case errKeyReused:
+		return http.StatusUnprocessableEntity, codeKeyReused
 	case things.ErrUnauthorizedAccess:
-		w.WriteHeader(http.StatusForbidden)
+		return http.StatusForbidden, codeUnauthorized
+	case errInvalidThingKey:
+		return http.StatusUnauthorized, codeUnauthenticated
 	case things.ErrNotFound:
-		w.WriteHeader(http.StatusNotFound)
-	case things.ErrConflict:
-		w.WriteHeader(http.StatusConflict)
+		return http.StatusNotFound, codeNotFound
+	case things.ErrConflict, errRequestInProgress:
+		return http.StatusConflict, codeConflict
+	case things.ErrVersionMismatch:
+		return http.StatusPreconditionFailed, codePreconditionFailed
+	case things.ErrQuotaExceeded:
+		return http.StatusTooManyRequests, codeQuotaExceeded
+	case things.ErrUnavailable:
+		return http.StatusServiceUnavailable, codeUnavailable
+	case errMissingIfMatch:
+		return http.StatusPreconditionRequired, codePreconditionRequired
+	case errStreamingUnsupported:
+		return http.StatusNotImplemented, codeNotImplemented
+	case errBodyTooLarge:
+		return http.StatusRequestEntityTooLarge, codeBodyTooLarge
 	case errUnsupportedContentType:
-		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return http.StatusUnsupportedMediaType, codeUnsupportedContentType
 	case errInvalidQueryParams:
-		w.WriteHeader(http.StatusBadRequest)
+		return http.StatusBadRequest, codeInvalidQueryParams
 	case io.ErrUnexpectedEOF:
-		w.WriteHeader(http.StatusBadRequest)
+		return http.StatusBadRequest, codeMalformedJSON
 	case io.EOF:
-		w.WriteHeader(http.StatusBadRequest)
+		return http.StatusBadRequest, codeMalformedJSON
+	}
+
+	switch e := err.(type) {
+	case things.BulkError:
+		return errorStatus(e.Err)
+	case things.NotConnectedError:
+		return http.StatusNotFound, codeNotConnected
+	case *json.SyntaxError:
+		return http.StatusBadRequest, codeMalformedJSON
+	case *json.UnmarshalTypeError:
+		return http.StatusBadRequest, codeMalformedJSON
 	default:
-		switch err.(type) {
-		case *json.SyntaxError:
-			w.WriteHeader(http.StatusBadRequest)
-		case *json.UnmarshalTypeError:
-			w.WriteHeader(http.StatusBadRequest)
-		default:
-			w.WriteHeader(http.StatusInternalServerError)
-		}
+		return http.StatusInternalServerError, codeInternal
 	}
 }
@@ -3,6 +3,7 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -12,19 +13,108 @@ import (
 
 var _ things.Service = (*loggingMiddleware)(nil)
 
+// redactedValue replaces the values of the redacted metadata fields.
+const redactedValue = "***"
+
 type loggingMiddleware struct {
-	logger log.Logger
-	svc    things.Service
+	logger   log.Logger
+	svc      things.Service
+	redacted map[string]bool
+}
+
+// LoggingMiddleware adds logging facilities to the core service. The values
+// of the metadata fields having any of the provided names are masked in the
+// log, at any level of the metadata nesting.
+func LoggingMiddleware(svc things.Service, logger log.Logger, redacted ...string) things.Service {
+	fields := make(map[string]bool, len(redacted))
+	for _, field := range redacted {
+		fields[field] = true
+	}
+
+	return &loggingMiddleware{logger, svc, fields}
+}
+
+func (lm *loggingMiddleware) AddThing(ctx context.Context, key string, thing things.Thing) (saved things.Thing, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method add_thing with request ID %s for key %s and thing %s with metadata %s took %s to complete", things.RequestID(ctx), redact(key), saved.ID, lm.metadata(thing.Metadata), time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.AddThing(ctx, key, thing)
+}
+
+func (lm *loggingMiddleware) CreateThings(ctx context.Context, key string, ths []things.Thing, dryRun bool) (saved []things.Thing, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method create_things with request ID %s for key %s and %d things (dry run %t) took %s to complete", things.RequestID(ctx), redact(key), len(ths), dryRun, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.CreateThings(ctx, key, ths, dryRun)
+}
+
+func (lm *loggingMiddleware) UpdateThing(ctx context.Context, key string, thing things.Thing) (err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method update_thing with request ID %s for key %s and thing %s with metadata %s took %s to complete", things.RequestID(ctx), redact(key), thing.ID, lm.metadata(thing.Metadata), time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.UpdateThing(ctx, key, thing)
+}
+
+func (lm *loggingMiddleware) UpdateKey(ctx context.Context, key, id, newKey string) (err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method update_key with request ID %s for key %s and thing %s took %s to complete", things.RequestID(ctx), redact(key), id, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.UpdateKey(ctx, key, id, newKey)
+}
+
+func (lm *loggingMiddleware) RotateKey(ctx context.Context, key, id string) (newKey string, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method rotate_key with request ID %s for key %s and thing %s took %s to complete", things.RequestID(ctx), redact(key), id, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.RotateKey(ctx, key, id)
 }
 
-// LoggingMiddleware adds logging facilities to the core service.
-func LoggingMiddleware(svc things.Service, logger log.Logger) things.Service {
-	return &loggingMiddleware{logger, svc}
+func (lm *loggingMiddleware) DisableThing(ctx context.Context, key, id string) (err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method disable_thing with request ID %s for key %s and thing %s took %s to complete", things.RequestID(ctx), redact(key), id, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.DisableThing(ctx, key, id)
 }
 
-func (lm *loggingMiddleware) AddThing(key string, thing things.Thing) (saved things.Thing, err error) {
+func (lm *loggingMiddleware) EnableThing(ctx context.Context, key, id string) (err error) {
 	defer func(begin time.Time) {
-		message := fmt.Sprintf("Method add_thing for key %s and thing %s took %s to complete", key, saved.ID, time.Since(begin))
+		message := fmt.Sprintf("Method enable_thing with request ID %s for key %s and thing %s took %s to complete", things.RequestID(ctx), redact(key), id, time.Since(begin))
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
 			return
@@ -32,12 +122,12 @@ func (lm *loggingMiddleware) AddThing(key string, thing things.Thing) (saved thi
 		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
 	}(time.Now())
 
-	return lm.svc.AddThing(key, thing)
+	return lm.svc.EnableThing(ctx, key, id)
 }
 
-func (lm *loggingMiddleware) UpdateThing(key string, thing things.Thing) (err error) {
+func (lm *loggingMiddleware) ViewThing(ctx context.Context, key string, id string) (thing things.Thing, err error) {
 	defer func(begin time.Time) {
-		message := fmt.Sprintf("Method update_thing for key %s and thing %s took %s to complete", key, thing.ID, time.Since(begin))
+		message := fmt.Sprintf("Method view_thing with request ID %s for key %s and thing %s took %s to complete", things.RequestID(ctx), redact(key), id, time.Since(begin))
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
 			return
@@ -45,12 +135,12 @@ func (lm *loggingMiddleware) UpdateThing(key string, thing things.Thing) (err er
 		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
 	}(time.Now())
 
-	return lm.svc.UpdateThing(key, thing)
+	return lm.svc.ViewThing(ctx, key, id)
 }
 
-func (lm *loggingMiddleware) ViewThing(key string, id string) (thing things.Thing, err error) {
+func (lm *loggingMiddleware) ViewThings(ctx context.Context, key string, ids []string) (ths []things.Thing, err error) {
 	defer func(begin time.Time) {
-		message := fmt.Sprintf("Method view_thing for key %s and thing %s took %s to complete", key, id, time.Since(begin))
+		message := fmt.Sprintf("Method view_things with request ID %s for key %s and %d things took %s to complete", things.RequestID(ctx), redact(key), len(ids), time.Since(begin))
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
 			return
@@ -58,12 +148,12 @@ func (lm *loggingMiddleware) ViewThing(key string, id string) (thing things.Thin
 		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
 	}(time.Now())
 
-	return lm.svc.ViewThing(key, id)
+	return lm.svc.ViewThings(ctx, key, ids)
 }
 
-func (lm *loggingMiddleware) ListThings(key string, offset, limit int) (things []things.Thing, err error) {
+func (lm *loggingMiddleware) ViewThingByKey(ctx context.Context, key string) (thing things.Thing, err error) {
 	defer func(begin time.Time) {
-		message := fmt.Sprintf("Method list_things for key %s took %s to complete", key, time.Since(begin))
+		message := fmt.Sprintf("Method view_thing_by_key with request ID %s for key %s took %s to complete", things.RequestID(ctx), redact(key), time.Since(begin))
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
 			return
@@ -71,12 +161,12 @@ func (lm *loggingMiddleware) ListThings(key string, offset, limit int) (things [
 		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
 	}(time.Now())
 
-	return lm.svc.ListThings(key, offset, limit)
+	return lm.svc.ViewThingByKey(ctx, key)
 }
 
-func (lm *loggingMiddleware) RemoveThing(key string, id string) (err error) {
+func (lm *loggingMiddleware) ListThings(ctx context.Context, key string, offset, limit int, sorting things.Sorting, filter things.ThingFilter) (page things.ThingPage, err error) {
 	defer func(begin time.Time) {
-		message := fmt.Sprintf("Method remove_thing for key %s and thing %s took %s to complete", key, id, time.Since(begin))
+		message := fmt.Sprintf("Method list_things with request ID %s for key %s took %s to complete", things.RequestID(ctx), redact(key), time.Since(begin))
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
 			return
@@ -84,12 +174,12 @@ func (lm *loggingMiddleware) RemoveThing(key string, id string) (err error) {
 		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
 	}(time.Now())
 
-	return lm.svc.RemoveThing(key, id)
+	return lm.svc.ListThings(ctx, key, offset, limit, sorting, filter)
 }
 
-func (lm *loggingMiddleware) CreateChannel(key string, channel things.Channel) (saved things.Channel, err error) {
+func (lm *loggingMiddleware) ListThingsAfter(ctx context.Context, key, afterID string, limit int) (ths []things.Thing, err error) {
 	defer func(begin time.Time) {
-		message := fmt.Sprintf("Method create_channel for key %s and channel %s took %s to complete", key, channel.ID, time.Since(begin))
+		message := fmt.Sprintf("Method list_things_after with request ID %s for key %s, cursor %s and limit %d took %s to complete", things.RequestID(ctx), redact(key), afterID, limit, time.Since(begin))
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
 			return
@@ -97,12 +187,12 @@ func (lm *loggingMiddleware) CreateChannel(key string, channel things.Channel) (
 		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
 	}(time.Now())
 
-	return lm.svc.CreateChannel(key, channel)
+	return lm.svc.ListThingsAfter(ctx, key, afterID, limit)
 }
 
-func (lm *loggingMiddleware) UpdateChannel(key string, channel things.Channel) (err error) {
+func (lm *loggingMiddleware) SearchThings(ctx context.Context, key, name string, offset, limit int) (ths []things.Thing, err error) {
 	defer func(begin time.Time) {
-		message := fmt.Sprintf("Method update_channel for key %s and channel %s took %s to complete", key, channel.ID, time.Since(begin))
+		message := fmt.Sprintf("Method search_things with request ID %s for key %s and name %s took %s to complete", things.RequestID(ctx), redact(key), name, time.Since(begin))
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
 			return
@@ -110,12 +200,12 @@ func (lm *loggingMiddleware) UpdateChannel(key string, channel things.Channel) (
 		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
 	}(time.Now())
 
-	return lm.svc.UpdateChannel(key, channel)
+	return lm.svc.SearchThings(ctx, key, name, offset, limit)
 }
 
-func (lm *loggingMiddleware) ViewChannel(key string, id string) (channel things.Channel, err error) {
+func (lm *loggingMiddleware) ListUnconnectedThings(ctx context.Context, key string, offset, limit int) (ths []things.Thing, err error) {
 	defer func(begin time.Time) {
-		message := fmt.Sprintf("Method view_channel for key %s and channel %s took %s to complete", key, id, time.Since(begin))
+		message := fmt.Sprintf("Method list_unconnected_things with request ID %s for key %s took %s to complete", things.RequestID(ctx), redact(key), time.Since(begin))
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
 			return
@@ -123,12 +213,17 @@ func (lm *loggingMiddleware) ViewChannel(key string, id string) (channel things.
 		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
 	}(time.Now())
 
-	return lm.svc.ViewChannel(key, id)
+	return lm.svc.ListUnconnectedThings(ctx, key, offset, limit)
 }
 
-func (lm *loggingMiddleware) ListChannels(key string, offset, limit int) (channels []things.Channel, err error) {
+func (lm *loggingMiddleware) ListThingsByMetadata(ctx context.Context, key, metaKey, metaValue string, offset, limit int) (ths []things.Thing, err error) {
+	value := metaValue
+	if lm.redacted[metaKey] {
+		value = redactedValue
+	}
+
 	defer func(begin time.Time) {
-		message := fmt.Sprintf("Method list_channels for key %s took %s to complete", key, time.Since(begin))
+		message := fmt.Sprintf("Method list_things_by_metadata with request ID %s for key %s and metadata %s:%s took %s to complete", things.RequestID(ctx), redact(key), metaKey, value, time.Since(begin))
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
 			return
@@ -136,12 +231,12 @@ func (lm *loggingMiddleware) ListChannels(key string, offset, limit int) (channe
 		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
 	}(time.Now())
 
-	return lm.svc.ListChannels(key, offset, limit)
+	return lm.svc.ListThingsByMetadata(ctx, key, metaKey, metaValue, offset, limit)
 }
 
-func (lm *loggingMiddleware) RemoveChannel(key string, id string) (err error) {
+func (lm *loggingMiddleware) ListDeletedThings(ctx context.Context, key string, offset, limit int, sorting things.Sorting) (page things.ThingPage, err error) {
 	defer func(begin time.Time) {
-		message := fmt.Sprintf("Method remove_channel for key %s and channel %s took %s to complete", key, id, time.Since(begin))
+		message := fmt.Sprintf("Method list_deleted_things with request ID %s for key %s took %s to complete", things.RequestID(ctx), redact(key), time.Since(begin))
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
 			return
@@ -149,12 +244,12 @@ func (lm *loggingMiddleware) RemoveChannel(key string, id string) (err error) {
 		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
 	}(time.Now())
 
-	return lm.svc.RemoveChannel(key, id)
+	return lm.svc.ListDeletedThings(ctx, key, offset, limit, sorting)
 }
 
-func (lm *loggingMiddleware) Connect(key, chanID, thingID string) (err error) {
+func (lm *loggingMiddleware) CountThings(ctx context.Context, key string, filter things.ThingFilter) (count int, err error) {
 	defer func(begin time.Time) {
-		message := fmt.Sprintf("Method connect for key %s, channel %s, thing %s took %s to complete", key, chanID, thingID, time.Since(begin))
+		message := fmt.Sprintf("Method count_things with request ID %s for key %s took %s to complete", things.RequestID(ctx), redact(key), time.Since(begin))
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
 			return
@@ -162,12 +257,12 @@ func (lm *loggingMiddleware) Connect(key, chanID, thingID string) (err error) {
 		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
 	}(time.Now())
 
-	return lm.svc.Connect(key, chanID, thingID)
+	return lm.svc.CountThings(ctx, key, filter)
 }
 
-func (lm *loggingMiddleware) Disconnect(key, chanID, thingID string) (err error) {
+func (lm *loggingMiddleware) RemoveThing(ctx context.Context, key string, id string) (err error) {
 	defer func(begin time.Time) {
-		message := fmt.Sprintf("Method disconnect for key %s, channel %s, thing %s took %s to complete", key, chanID, thingID, time.Since(begin))
+		message := fmt.Sprintf("Method remove_thing with request ID %s for key %s and thing %s took %s to complete", things.RequestID(ctx), redact(key), id, time.Since(begin))
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
 			return
@@ -175,12 +270,12 @@ func (lm *loggingMiddleware) Disconnect(key, chanID, thingID string) (err error)
 		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
 	}(time.Now())
 
-	return lm.svc.Disconnect(key, chanID, thingID)
+	return lm.svc.RemoveThing(ctx, key, id)
 }
 
-func (lm *loggingMiddleware) CanAccess(key string, id string) (pub string, err error) {
+func (lm *loggingMiddleware) RemoveAllThings(ctx context.Context, key string) (err error) {
 	defer func(begin time.Time) {
-		message := fmt.Sprintf("Method can_access for key %s, channel %s and publisher %s took %s to complete", key, id, pub, time.Since(begin))
+		message := fmt.Sprintf("Method remove_all_things with request ID %s for key %s took %s to complete", things.RequestID(ctx), redact(key), time.Since(begin))
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
 			return
@@ -188,5 +283,438 @@ func (lm *loggingMiddleware) CanAccess(key string, id string) (pub string, err e
 		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
 	}(time.Now())
 
-	return lm.svc.CanAccess(key, id)
+	return lm.svc.RemoveAllThings(ctx, key)
+}
+
+func (lm *loggingMiddleware) RestoreThing(ctx context.Context, key string, id string) (err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method restore_thing with request ID %s for key %s and thing %s took %s to complete", things.RequestID(ctx), redact(key), id, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.RestoreThing(ctx, key, id)
+}
+
+func (lm *loggingMiddleware) TransferThing(ctx context.Context, key, id, newOwner string) (err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method transfer_thing with request ID %s for key %s, thing %s and new owner %s took %s to complete", things.RequestID(ctx), redact(key), id, newOwner, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.TransferThing(ctx, key, id, newOwner)
+}
+
+func (lm *loggingMiddleware) CreateChannel(ctx context.Context, key string, channel things.Channel) (saved things.Channel, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method create_channel with request ID %s for key %s and channel %s with metadata %s took %s to complete", things.RequestID(ctx), redact(key), channel.ID, lm.metadata(channel.Metadata), time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.CreateChannel(ctx, key, channel)
+}
+
+func (lm *loggingMiddleware) UpdateChannel(ctx context.Context, key string, channel things.Channel) (err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method update_channel with request ID %s for key %s and channel %s with metadata %s took %s to complete", things.RequestID(ctx), redact(key), channel.ID, lm.metadata(channel.Metadata), time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.UpdateChannel(ctx, key, channel)
+}
+
+func (lm *loggingMiddleware) ViewChannel(ctx context.Context, key string, id string) (channel things.Channel, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method view_channel with request ID %s for key %s and channel %s took %s to complete", things.RequestID(ctx), redact(key), id, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.ViewChannel(ctx, key, id)
+}
+
+func (lm *loggingMiddleware) ListChannels(ctx context.Context, key string, offset, limit int, sorting things.Sorting, filter things.MetadataFilter) (page things.ChannelPage, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method list_channels with request ID %s for key %s took %s to complete", things.RequestID(ctx), redact(key), time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.ListChannels(ctx, key, offset, limit, sorting, filter)
+}
+
+func (lm *loggingMiddleware) ListChannelsByThing(ctx context.Context, key, id string, offset, limit int) (channels []things.Channel, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method list_channels_by_thing with request ID %s for key %s and thing %s took %s to complete", things.RequestID(ctx), redact(key), id, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.ListChannelsByThing(ctx, key, id, offset, limit)
+}
+
+func (lm *loggingMiddleware) ListThingsByChannel(ctx context.Context, key, id string, offset, limit int, state things.ConnectionState) (ths []things.Thing, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method list_things_by_channel with request ID %s for key %s and channel %s took %s to complete", things.RequestID(ctx), redact(key), id, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.ListThingsByChannel(ctx, key, id, offset, limit, state)
+}
+
+func (lm *loggingMiddleware) CountChannels(ctx context.Context, key string) (count int, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method count_channels with request ID %s for key %s took %s to complete", things.RequestID(ctx), redact(key), time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.CountChannels(ctx, key)
+}
+
+func (lm *loggingMiddleware) ChannelConnectionsCount(ctx context.Context, key, chanID string) (count int, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method channel_connections_count with request ID %s for key %s and channel %s took %s to complete", things.RequestID(ctx), redact(key), chanID, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.ChannelConnectionsCount(ctx, key, chanID)
+}
+
+func (lm *loggingMiddleware) ChannelConnections(ctx context.Context, key, chanID string) (conns []things.Connection, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method channel_connections with request ID %s for key %s and channel %s took %s to complete", things.RequestID(ctx), redact(key), chanID, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.ChannelConnections(ctx, key, chanID)
+}
+
+func (lm *loggingMiddleware) RemoveChannel(ctx context.Context, key string, id string) (err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method remove_channel with request ID %s for key %s and channel %s took %s to complete", things.RequestID(ctx), redact(key), id, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.RemoveChannel(ctx, key, id)
+}
+
+func (lm *loggingMiddleware) RestoreChannel(ctx context.Context, key string, id string) (err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method restore_channel with request ID %s for key %s and channel %s took %s to complete", things.RequestID(ctx), redact(key), id, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.RestoreChannel(ctx, key, id)
+}
+
+func (lm *loggingMiddleware) RemoveAllChannels(ctx context.Context, key string) (err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method remove_all_channels with request ID %s for key %s took %s to complete", things.RequestID(ctx), redact(key), time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.RemoveAllChannels(ctx, key)
+}
+
+func (lm *loggingMiddleware) TransferChannel(ctx context.Context, key, id, newOwner string) (err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method transfer_channel with request ID %s for key %s, channel %s and new owner %s took %s to complete", things.RequestID(ctx), redact(key), id, newOwner, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.TransferChannel(ctx, key, id, newOwner)
+}
+
+func (lm *loggingMiddleware) Connect(ctx context.Context, key, chanID, thingID string, mode things.AccessMode) (conn things.Connection, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method connect with request ID %s for key %s, channel %s, thing %s and mode %s took %s to complete", things.RequestID(ctx), redact(key), chanID, thingID, mode, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.Connect(ctx, key, chanID, thingID, mode)
+}
+
+func (lm *loggingMiddleware) ConnectMany(ctx context.Context, key, thingID string, chanIDs []string) (err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method connect_many with request ID %s for key %s, thing %s, channels %v took %s to complete", things.RequestID(ctx), redact(key), thingID, chanIDs, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.ConnectMany(ctx, key, thingID, chanIDs)
+}
+
+func (lm *loggingMiddleware) ConnectThings(ctx context.Context, key, chanID string, thingIDs []string) (err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method connect_things with request ID %s for key %s, channel %s, things %v took %s to complete", things.RequestID(ctx), redact(key), chanID, thingIDs, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.ConnectThings(ctx, key, chanID, thingIDs)
+}
+
+func (lm *loggingMiddleware) SetChannelThings(ctx context.Context, key, chanID string, thingIDs []string) (err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method set_channel_things with request ID %s for key %s, channel %s, things %v took %s to complete", things.RequestID(ctx), redact(key), chanID, thingIDs, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.SetChannelThings(ctx, key, chanID, thingIDs)
+}
+
+func (lm *loggingMiddleware) Disconnect(ctx context.Context, key, chanID, thingID string) (removed bool, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method disconnect with request ID %s for key %s, channel %s, thing %s and removed %t took %s to complete", things.RequestID(ctx), redact(key), chanID, thingID, removed, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.Disconnect(ctx, key, chanID, thingID)
+}
+
+func (lm *loggingMiddleware) DisconnectMany(ctx context.Context, key, thingID string, chanIDs []string) (err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method disconnect_many with request ID %s for key %s, thing %s, channels %v took %s to complete", things.RequestID(ctx), redact(key), thingID, chanIDs, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.DisconnectMany(ctx, key, thingID, chanIDs)
+}
+
+func (lm *loggingMiddleware) DisconnectAll(ctx context.Context, key, thingID string) (err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method disconnect_all with request ID %s for key %s and thing %s took %s to complete", things.RequestID(ctx), redact(key), thingID, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.DisconnectAll(ctx, key, thingID)
+}
+
+func (lm *loggingMiddleware) RebindConnections(ctx context.Context, key, fromID, toID string) (err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method rebind_connections with request ID %s for key %s from thing %s to thing %s took %s to complete", things.RequestID(ctx), redact(key), fromID, toID, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.RebindConnections(ctx, key, fromID, toID)
+}
+
+func (lm *loggingMiddleware) ThingChannelIDs(ctx context.Context, key, thingID string) (ids []string, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method thing_channel_ids with request ID %s for key %s and thing %s took %s to complete", things.RequestID(ctx), redact(key), thingID, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.ThingChannelIDs(ctx, key, thingID)
+}
+
+func (lm *loggingMiddleware) IsConnected(ctx context.Context, key, chanID, thingID string) (connected bool, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method is_connected with request ID %s for key %s, channel %s and thing %s took %s to complete", things.RequestID(ctx), redact(key), chanID, thingID, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.IsConnected(ctx, key, chanID, thingID)
+}
+
+func (lm *loggingMiddleware) CanAccess(ctx context.Context, key string, id string, mode things.AccessMode) (pub string, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method can_access with request ID %s for key %s, channel %s, mode %s and publisher %s took %s to complete", things.RequestID(ctx), redact(key), id, mode, pub, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.CanAccess(ctx, key, id, mode)
+}
+
+func (lm *loggingMiddleware) CanAccessBatch(ctx context.Context, reqs []things.AccessRequest) (res []things.AccessResult, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method can_access_batch with request ID %s for %d requests took %s to complete", things.RequestID(ctx), len(reqs), time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.CanAccessBatch(ctx, reqs)
+}
+
+func (lm *loggingMiddleware) ChannelOwner(ctx context.Context, key, chanID string) (owner string, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method channel_owner with request ID %s for key %s and channel %s took %s to complete", things.RequestID(ctx), redact(key), chanID, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.ChannelOwner(ctx, key, chanID)
+}
+
+func (lm *loggingMiddleware) PurgeDeleted(ctx context.Context, key string, olderThan time.Time) (purged int, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method purge_deleted with request ID %s for entities removed before %s purged %d of them and took %s to complete", things.RequestID(ctx), olderThan.Format(time.RFC3339), purged, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.PurgeDeleted(ctx, key, olderThan)
+}
+
+func (lm *loggingMiddleware) Health(ctx context.Context) (err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method health with request ID %s took %s to complete", things.RequestID(ctx), time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.Health(ctx)
+}
+
+// redact masks the provided access key, so that only its last few characters
+// are written to the log.
+func redact(key string) string {
+	const visible = 4
+
+	if len(key) <= 2*visible {
+		return "****"
+	}
+
+	return fmt.Sprintf("****%s", key[len(key)-visible:])
+}
+
+// metadata formats the provided metadata for the log, masking the values of
+// the redacted fields.
+func (lm *loggingMiddleware) metadata(metadata map[string]interface{}) string {
+	return fmt.Sprint(lm.mask(metadata))
+}
+
+// mask returns the copy of the provided metadata value, whose redacted fields
+// are masked. Nested objects and arrays are masked recursively.
+func (lm *loggingMiddleware) mask(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		masked := make(map[string]interface{}, len(v))
+		for field, val := range v {
+			if lm.redacted[field] {
+				masked[field] = redactedValue
+				continue
+			}
+			masked[field] = lm.mask(val)
+		}
+		return masked
+	case []interface{}:
+		masked := make([]interface{}, len(v))
+		for i, val := range v {
+			masked[i] = lm.mask(val)
+		}
+		return masked
+	default:
+		return v
+	}
 }
@@ -0,0 +1,108 @@
+// +build !test
+
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	log "github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/things"
+	"github.com/mainflux/mainflux/things/api"
+	"github.com/mainflux/mainflux/things/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	token = "7ba32e6c-0d12-4bfc-9d6b-09e7c6f9b4a1"
+	email = "user@example.com"
+)
+
+func newService(tokens map[string]string) things.Service {
+	users := mocks.NewUsersService(tokens)
+	thingsRepo := mocks.NewThingRepository()
+	channelsRepo := mocks.NewChannelRepository(thingsRepo)
+	idp := mocks.NewIdentityProvider()
+
+	return things.New(users, thingsRepo, channelsRepo, idp)
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	svc := api.LoggingMiddleware(newService(map[string]string{token: email}), log.New(&buf))
+
+	reqID := "5f1c8a2e-4b7d-4e3a-9c61-2d8f0b7a9e34"
+	ctx := things.WithRequestID(context.Background(), reqID)
+	saved, err := svc.AddThing(ctx, token, things.Thing{Type: "app", Name: "test"})
+	assert.Nil(t, err, fmt.Sprintf("add thing: unexpected error %s", err))
+
+	out := buf.String()
+	assert.True(t, strings.Contains(out, "add_thing"), fmt.Sprintf("log method: expected add_thing in %s", out))
+	assert.True(t, strings.Contains(out, saved.ID), fmt.Sprintf("log thing: expected %s in %s", saved.ID, out))
+	assert.True(t, strings.Contains(out, reqID), fmt.Sprintf("log request: expected %s in %s", reqID, out))
+	assert.True(t, strings.Contains(out, "took"), fmt.Sprintf("log duration: expected duration in %s", out))
+	assert.False(t, strings.Contains(out, token), fmt.Sprintf("log key: expected key to be redacted in %s", out))
+}
+
+func TestLoggingMiddlewareRedactedMetadata(t *testing.T) {
+	var buf bytes.Buffer
+	svc := api.LoggingMiddleware(newService(map[string]string{token: email}), log.New(&buf), "email")
+
+	thing := things.Thing{
+		Type: "app",
+		Name: "test",
+		Metadata: map[string]interface{}{
+			"email":    "owner@example.com",
+			"location": "lab",
+			"contacts": []interface{}{
+				map[string]interface{}{"email": "first@example.com", "role": "admin"},
+			},
+			"owner": map[string]interface{}{"email": "nested@example.com", "team": "ops"},
+		},
+	}
+	_, err := svc.AddThing(context.Background(), token, thing)
+	assert.Nil(t, err, fmt.Sprintf("add thing: unexpected error %s", err))
+
+	out := buf.String()
+	for _, value := range []string{"owner@example.com", "first@example.com", "nested@example.com"} {
+		assert.False(t, strings.Contains(out, value), fmt.Sprintf("log metadata: expected %s to be redacted in %s", value, out))
+	}
+	for _, value := range []string{"email:***", "location:lab", "role:admin", "team:ops"} {
+		assert.True(t, strings.Contains(out, value), fmt.Sprintf("log metadata: expected %s in %s", value, out))
+	}
+	assert.Equal(t, "owner@example.com", thing.Metadata["email"], "log metadata: expected thing's metadata to be intact")
+
+	buf.Reset()
+	_, err = svc.ListThingsByMetadata(context.Background(), token, "email", "owner@example.com", 0, 10)
+	assert.Nil(t, err, fmt.Sprintf("list things by metadata: unexpected error %s", err))
+
+	out = buf.String()
+	assert.False(t, strings.Contains(out, "owner@example.com"), fmt.Sprintf("log metadata filter: expected value to be redacted in %s", out))
+	assert.True(t, strings.Contains(out, "email:***"), fmt.Sprintf("log metadata filter: expected email:*** in %s", out))
+}
+
+func TestLoggingMiddlewareThingKey(t *testing.T) {
+	var buf bytes.Buffer
+	svc := api.LoggingMiddleware(newService(map[string]string{token: email}), log.New(&buf))
+
+	saved, err := svc.AddThing(context.Background(), token, things.Thing{Type: "app", Name: "test"})
+	assert.Nil(t, err, fmt.Sprintf("add thing: unexpected error %s", err))
+
+	wrong := "c2f5d0e4-8a1b-4f7e-b3d6-5e9a7c1f2b80"
+	cases := map[string]string{
+		"view thing by valid key":   saved.Key,
+		"view thing by invalid key": wrong,
+	}
+
+	for desc, key := range cases {
+		buf.Reset()
+		svc.ViewThingByKey(context.Background(), key)
+
+		out := buf.String()
+		assert.True(t, strings.Contains(out, "view_thing_by_key"), fmt.Sprintf("%s: expected view_thing_by_key in %s", desc, out))
+		assert.False(t, strings.Contains(out, key), fmt.Sprintf("%s: expected key to be redacted in %s", desc, out))
+	}
+}
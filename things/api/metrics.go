@@ -3,6 +3,7 @@
 package api
 
 import (
+	"context"
 	"time"
 
 	"github.com/go-kit/kit/metrics"
@@ -27,119 +28,443 @@ func MetricsMiddleware(svc things.Service, counter metrics.Counter, latency metr
 	}
 }
 
-func (ms *metricsMiddleware) AddThing(key string, thing things.Thing) (things.Thing, error) {
+func (ms *metricsMiddleware) AddThing(ctx context.Context, key string, thing things.Thing) (things.Thing, error) {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "add_thing").Add(1)
 		ms.latency.With("method", "add_thing").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return ms.svc.AddThing(key, thing)
+	return ms.svc.AddThing(ctx, key, thing)
 }
 
-func (ms *metricsMiddleware) UpdateThing(key string, thing things.Thing) error {
+func (ms *metricsMiddleware) CreateThings(ctx context.Context, key string, ths []things.Thing, dryRun bool) ([]things.Thing, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "create_things").Add(1)
+		ms.latency.With("method", "create_things").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.CreateThings(ctx, key, ths, dryRun)
+}
+
+func (ms *metricsMiddleware) UpdateThing(ctx context.Context, key string, thing things.Thing) error {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "update_thing").Add(1)
 		ms.latency.With("method", "update_thing").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return ms.svc.UpdateThing(key, thing)
+	return ms.svc.UpdateThing(ctx, key, thing)
+}
+
+func (ms *metricsMiddleware) UpdateKey(ctx context.Context, key, id, newKey string) error {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "update_key").Add(1)
+		ms.latency.With("method", "update_key").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.UpdateKey(ctx, key, id, newKey)
 }
 
-func (ms *metricsMiddleware) ViewThing(key string, id string) (things.Thing, error) {
+func (ms *metricsMiddleware) RotateKey(ctx context.Context, key, id string) (string, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "rotate_key").Add(1)
+		ms.latency.With("method", "rotate_key").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.RotateKey(ctx, key, id)
+}
+
+func (ms *metricsMiddleware) DisableThing(ctx context.Context, key, id string) error {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "disable_thing").Add(1)
+		ms.latency.With("method", "disable_thing").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.DisableThing(ctx, key, id)
+}
+
+func (ms *metricsMiddleware) EnableThing(ctx context.Context, key, id string) error {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "enable_thing").Add(1)
+		ms.latency.With("method", "enable_thing").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.EnableThing(ctx, key, id)
+}
+
+func (ms *metricsMiddleware) ViewThing(ctx context.Context, key string, id string) (things.Thing, error) {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "view_thing").Add(1)
 		ms.latency.With("method", "view_thing").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return ms.svc.ViewThing(key, id)
+	return ms.svc.ViewThing(ctx, key, id)
+}
+
+func (ms *metricsMiddleware) ViewThings(ctx context.Context, key string, ids []string) ([]things.Thing, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "view_things").Add(1)
+		ms.latency.With("method", "view_things").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.ViewThings(ctx, key, ids)
+}
+
+func (ms *metricsMiddleware) ViewThingByKey(ctx context.Context, key string) (things.Thing, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "view_thing_by_key").Add(1)
+		ms.latency.With("method", "view_thing_by_key").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.ViewThingByKey(ctx, key)
 }
 
-func (ms *metricsMiddleware) ListThings(key string, offset, limit int) ([]things.Thing, error) {
+func (ms *metricsMiddleware) ListThings(ctx context.Context, key string, offset, limit int, sorting things.Sorting, filter things.ThingFilter) (things.ThingPage, error) {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "list_things").Add(1)
 		ms.latency.With("method", "list_things").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return ms.svc.ListThings(key, offset, limit)
+	return ms.svc.ListThings(ctx, key, offset, limit, sorting, filter)
+}
+
+func (ms *metricsMiddleware) ListThingsAfter(ctx context.Context, key, afterID string, limit int) ([]things.Thing, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "list_things_after").Add(1)
+		ms.latency.With("method", "list_things_after").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.ListThingsAfter(ctx, key, afterID, limit)
+}
+
+func (ms *metricsMiddleware) SearchThings(ctx context.Context, key, name string, offset, limit int) ([]things.Thing, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "search_things").Add(1)
+		ms.latency.With("method", "search_things").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.SearchThings(ctx, key, name, offset, limit)
 }
 
-func (ms *metricsMiddleware) RemoveThing(key string, id string) error {
+func (ms *metricsMiddleware) ListUnconnectedThings(ctx context.Context, key string, offset, limit int) ([]things.Thing, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "list_unconnected_things").Add(1)
+		ms.latency.With("method", "list_unconnected_things").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.ListUnconnectedThings(ctx, key, offset, limit)
+}
+
+func (ms *metricsMiddleware) ListThingsByMetadata(ctx context.Context, key, metaKey, metaValue string, offset, limit int) ([]things.Thing, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "list_things_by_metadata").Add(1)
+		ms.latency.With("method", "list_things_by_metadata").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.ListThingsByMetadata(ctx, key, metaKey, metaValue, offset, limit)
+}
+
+func (ms *metricsMiddleware) ListDeletedThings(ctx context.Context, key string, offset, limit int, sorting things.Sorting) (things.ThingPage, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "list_deleted_things").Add(1)
+		ms.latency.With("method", "list_deleted_things").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.ListDeletedThings(ctx, key, offset, limit, sorting)
+}
+
+func (ms *metricsMiddleware) CountThings(ctx context.Context, key string, filter things.ThingFilter) (int, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "count_things").Add(1)
+		ms.latency.With("method", "count_things").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.CountThings(ctx, key, filter)
+}
+
+func (ms *metricsMiddleware) RemoveThing(ctx context.Context, key string, id string) error {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "remove_thing").Add(1)
 		ms.latency.With("method", "remove_thing").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return ms.svc.RemoveThing(key, id)
+	return ms.svc.RemoveThing(ctx, key, id)
 }
 
-func (ms *metricsMiddleware) CreateChannel(key string, channel things.Channel) (things.Channel, error) {
+func (ms *metricsMiddleware) RemoveAllThings(ctx context.Context, key string) error {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "remove_all_things").Add(1)
+		ms.latency.With("method", "remove_all_things").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.RemoveAllThings(ctx, key)
+}
+
+func (ms *metricsMiddleware) RestoreThing(ctx context.Context, key string, id string) error {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "restore_thing").Add(1)
+		ms.latency.With("method", "restore_thing").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.RestoreThing(ctx, key, id)
+}
+
+func (ms *metricsMiddleware) TransferThing(ctx context.Context, key, id, newOwner string) error {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "transfer_thing").Add(1)
+		ms.latency.With("method", "transfer_thing").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.TransferThing(ctx, key, id, newOwner)
+}
+
+func (ms *metricsMiddleware) CreateChannel(ctx context.Context, key string, channel things.Channel) (things.Channel, error) {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "create_channel").Add(1)
 		ms.latency.With("method", "create_channel").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return ms.svc.CreateChannel(key, channel)
+	return ms.svc.CreateChannel(ctx, key, channel)
 }
 
-func (ms *metricsMiddleware) UpdateChannel(key string, channel things.Channel) error {
+func (ms *metricsMiddleware) UpdateChannel(ctx context.Context, key string, channel things.Channel) error {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "update_channel").Add(1)
 		ms.latency.With("method", "update_channel").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return ms.svc.UpdateChannel(key, channel)
+	return ms.svc.UpdateChannel(ctx, key, channel)
 }
 
-func (ms *metricsMiddleware) ViewChannel(key string, id string) (things.Channel, error) {
+func (ms *metricsMiddleware) ViewChannel(ctx context.Context, key string, id string) (things.Channel, error) {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "view_channel").Add(1)
 		ms.latency.With("method", "view_channel").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return ms.svc.ViewChannel(key, id)
+	return ms.svc.ViewChannel(ctx, key, id)
 }
 
-func (ms *metricsMiddleware) ListChannels(key string, offset, limit int) ([]things.Channel, error) {
+func (ms *metricsMiddleware) ListChannels(ctx context.Context, key string, offset, limit int, sorting things.Sorting, filter things.MetadataFilter) (things.ChannelPage, error) {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "list_channels").Add(1)
 		ms.latency.With("method", "list_channels").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return ms.svc.ListChannels(key, offset, limit)
+	return ms.svc.ListChannels(ctx, key, offset, limit, sorting, filter)
+}
+
+func (ms *metricsMiddleware) ListChannelsByThing(ctx context.Context, key, id string, offset, limit int) ([]things.Channel, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "list_channels_by_thing").Add(1)
+		ms.latency.With("method", "list_channels_by_thing").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.ListChannelsByThing(ctx, key, id, offset, limit)
+}
+
+func (ms *metricsMiddleware) ListThingsByChannel(ctx context.Context, key, id string, offset, limit int, state things.ConnectionState) ([]things.Thing, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "list_things_by_channel").Add(1)
+		ms.latency.With("method", "list_things_by_channel").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.ListThingsByChannel(ctx, key, id, offset, limit, state)
 }
 
-func (ms *metricsMiddleware) RemoveChannel(key string, id string) error {
+func (ms *metricsMiddleware) CountChannels(ctx context.Context, key string) (int, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "count_channels").Add(1)
+		ms.latency.With("method", "count_channels").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.CountChannels(ctx, key)
+}
+
+func (ms *metricsMiddleware) ChannelConnectionsCount(ctx context.Context, key, chanID string) (int, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "channel_connections_count").Add(1)
+		ms.latency.With("method", "channel_connections_count").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.ChannelConnectionsCount(ctx, key, chanID)
+}
+
+func (ms *metricsMiddleware) ChannelConnections(ctx context.Context, key, chanID string) ([]things.Connection, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "channel_connections").Add(1)
+		ms.latency.With("method", "channel_connections").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.ChannelConnections(ctx, key, chanID)
+}
+
+func (ms *metricsMiddleware) RemoveChannel(ctx context.Context, key string, id string) error {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "remove_channel").Add(1)
 		ms.latency.With("method", "remove_channel").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return ms.svc.RemoveChannel(key, id)
+	return ms.svc.RemoveChannel(ctx, key, id)
 }
 
-func (ms *metricsMiddleware) Connect(key, chanID, thingID string) error {
+func (ms *metricsMiddleware) RestoreChannel(ctx context.Context, key string, id string) error {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "restore_channel").Add(1)
+		ms.latency.With("method", "restore_channel").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.RestoreChannel(ctx, key, id)
+}
+
+func (ms *metricsMiddleware) RemoveAllChannels(ctx context.Context, key string) error {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "remove_all_channels").Add(1)
+		ms.latency.With("method", "remove_all_channels").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.RemoveAllChannels(ctx, key)
+}
+
+func (ms *metricsMiddleware) TransferChannel(ctx context.Context, key, id, newOwner string) error {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "transfer_channel").Add(1)
+		ms.latency.With("method", "transfer_channel").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.TransferChannel(ctx, key, id, newOwner)
+}
+
+func (ms *metricsMiddleware) Connect(ctx context.Context, key, chanID, thingID string, mode things.AccessMode) (things.Connection, error) {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "connect").Add(1)
 		ms.latency.With("method", "connect").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return ms.svc.Connect(key, chanID, thingID)
+	return ms.svc.Connect(ctx, key, chanID, thingID, mode)
+}
+
+func (ms *metricsMiddleware) ConnectMany(ctx context.Context, key, thingID string, chanIDs []string) error {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "connect_many").Add(1)
+		ms.latency.With("method", "connect_many").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.ConnectMany(ctx, key, thingID, chanIDs)
+}
+
+func (ms *metricsMiddleware) ConnectThings(ctx context.Context, key, chanID string, thingIDs []string) error {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "connect_things").Add(1)
+		ms.latency.With("method", "connect_things").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.ConnectThings(ctx, key, chanID, thingIDs)
+}
+
+func (ms *metricsMiddleware) SetChannelThings(ctx context.Context, key, chanID string, thingIDs []string) error {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "set_channel_things").Add(1)
+		ms.latency.With("method", "set_channel_things").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.SetChannelThings(ctx, key, chanID, thingIDs)
 }
 
-func (ms *metricsMiddleware) Disconnect(key, chanID, thingID string) error {
+func (ms *metricsMiddleware) Disconnect(ctx context.Context, key, chanID, thingID string) (bool, error) {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "disconnect").Add(1)
 		ms.latency.With("method", "disconnect").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return ms.svc.Disconnect(key, chanID, thingID)
+	return ms.svc.Disconnect(ctx, key, chanID, thingID)
 }
 
-func (ms *metricsMiddleware) CanAccess(key string, id string) (string, error) {
+func (ms *metricsMiddleware) DisconnectMany(ctx context.Context, key, thingID string, chanIDs []string) error {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "disconnect_many").Add(1)
+		ms.latency.With("method", "disconnect_many").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.DisconnectMany(ctx, key, thingID, chanIDs)
+}
+
+func (ms *metricsMiddleware) DisconnectAll(ctx context.Context, key, thingID string) error {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "disconnect_all").Add(1)
+		ms.latency.With("method", "disconnect_all").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.DisconnectAll(ctx, key, thingID)
+}
+
+func (ms *metricsMiddleware) RebindConnections(ctx context.Context, key, fromID, toID string) error {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "rebind_connections").Add(1)
+		ms.latency.With("method", "rebind_connections").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.RebindConnections(ctx, key, fromID, toID)
+}
+
+func (ms *metricsMiddleware) ThingChannelIDs(ctx context.Context, key, thingID string) ([]string, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "thing_channel_ids").Add(1)
+		ms.latency.With("method", "thing_channel_ids").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.ThingChannelIDs(ctx, key, thingID)
+}
+
+func (ms *metricsMiddleware) IsConnected(ctx context.Context, key, chanID, thingID string) (bool, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "is_connected").Add(1)
+		ms.latency.With("method", "is_connected").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.IsConnected(ctx, key, chanID, thingID)
+}
+
+func (ms *metricsMiddleware) CanAccess(ctx context.Context, key string, id string, mode things.AccessMode) (string, error) {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "can_access").Add(1)
 		ms.latency.With("method", "can_access").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return ms.svc.CanAccess(key, id)
+	return ms.svc.CanAccess(ctx, key, id, mode)
+}
+
+func (ms *metricsMiddleware) CanAccessBatch(ctx context.Context, reqs []things.AccessRequest) ([]things.AccessResult, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "can_access_batch").Add(1)
+		ms.latency.With("method", "can_access_batch").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.CanAccessBatch(ctx, reqs)
+}
+
+func (ms *metricsMiddleware) ChannelOwner(ctx context.Context, key, chanID string) (string, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "channel_owner").Add(1)
+		ms.latency.With("method", "channel_owner").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.ChannelOwner(ctx, key, chanID)
+}
+
+func (ms *metricsMiddleware) PurgeDeleted(ctx context.Context, key string, olderThan time.Time) (int, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "purge_deleted").Add(1)
+		ms.latency.With("method", "purge_deleted").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.PurgeDeleted(ctx, key, olderThan)
+}
+
+func (ms *metricsMiddleware) Health(ctx context.Context) error {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "health").Add(1)
+		ms.latency.With("method", "health").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.Health(ctx)
 }
@@ -0,0 +1,73 @@
+// +build !test
+
+package api_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/mainflux/mainflux/things"
+	"github.com/mainflux/mainflux/things/api"
+	"github.com/stretchr/testify/assert"
+)
+
+// counterMock counts the values added under each set of label values.
+type counterMock struct {
+	mu     *sync.Mutex
+	values map[string]float64
+	labels string
+}
+
+func newCounter() *counterMock {
+	return &counterMock{
+		mu:     &sync.Mutex{},
+		values: make(map[string]float64),
+	}
+}
+
+func (c *counterMock) With(labelValues ...string) metrics.Counter {
+	return &counterMock{
+		mu:     c.mu,
+		values: c.values,
+		labels: strings.Join(labelValues, ","),
+	}
+}
+
+func (c *counterMock) Add(delta float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.values[c.labels] += delta
+}
+
+func (c *counterMock) value(labelValues ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.values[strings.Join(labelValues, ",")]
+}
+
+type histogramMock struct{}
+
+func (h histogramMock) With(...string) metrics.Histogram {
+	return h
+}
+
+func (h histogramMock) Observe(float64) {}
+
+func TestMetricsMiddleware(t *testing.T) {
+	counter := newCounter()
+	svc := newService(map[string]string{token: email})
+	sch, _ := svc.CreateChannel(context.Background(), token, things.Channel{Name: "test"})
+
+	svc = api.MetricsMiddleware(svc, counter, histogramMock{})
+	_, err := svc.ViewChannel(context.Background(), token, sch.ID)
+	assert.Nil(t, err, fmt.Sprintf("view channel: unexpected error %s", err))
+
+	cnt := counter.value("method", "view_channel")
+	assert.Equal(t, float64(1), cnt, fmt.Sprintf("count view_channel calls: expected %f got %f", float64(1), cnt))
+}
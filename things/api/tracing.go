@@ -0,0 +1,409 @@
+// +build !test
+
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/mainflux/mainflux/things"
+	"github.com/mainflux/mainflux/things/tracing"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var _ things.Service = (*tracingMiddleware)(nil)
+
+type tracingMiddleware struct {
+	tracer opentracing.Tracer
+	svc    things.Service
+}
+
+// TracingMiddleware traces the core service operations. The span of each
+// operation is the child of the span carried by the operation's context, and
+// is tagged with the identifiers of the affected things and channels.
+func TracingMiddleware(svc things.Service, tracer opentracing.Tracer) things.Service {
+	return &tracingMiddleware{
+		tracer: tracer,
+		svc:    svc,
+	}
+}
+
+func (tm *tracingMiddleware) AddThing(ctx context.Context, key string, thing things.Thing) (things.Thing, error) {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.add_thing")
+	defer span.Finish()
+
+	return tm.svc.AddThing(ctx, key, thing)
+}
+
+func (tm *tracingMiddleware) CreateThings(ctx context.Context, key string, ths []things.Thing, dryRun bool) ([]things.Thing, error) {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.create_things")
+	defer span.Finish()
+
+	return tm.svc.CreateThings(ctx, key, ths, dryRun)
+}
+
+func (tm *tracingMiddleware) UpdateThing(ctx context.Context, key string, thing things.Thing) error {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.update_thing")
+	span.SetTag("thing_id", thing.ID)
+	defer span.Finish()
+
+	return tm.svc.UpdateThing(ctx, key, thing)
+}
+
+func (tm *tracingMiddleware) UpdateKey(ctx context.Context, key, id, newKey string) error {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.update_key")
+	span.SetTag("thing_id", id)
+	defer span.Finish()
+
+	return tm.svc.UpdateKey(ctx, key, id, newKey)
+}
+
+func (tm *tracingMiddleware) RotateKey(ctx context.Context, key, id string) (string, error) {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.rotate_key")
+	span.SetTag("thing_id", id)
+	defer span.Finish()
+
+	return tm.svc.RotateKey(ctx, key, id)
+}
+
+func (tm *tracingMiddleware) DisableThing(ctx context.Context, key, id string) error {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.disable_thing")
+	span.SetTag("thing_id", id)
+	defer span.Finish()
+
+	return tm.svc.DisableThing(ctx, key, id)
+}
+
+func (tm *tracingMiddleware) EnableThing(ctx context.Context, key, id string) error {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.enable_thing")
+	span.SetTag("thing_id", id)
+	defer span.Finish()
+
+	return tm.svc.EnableThing(ctx, key, id)
+}
+
+func (tm *tracingMiddleware) ViewThing(ctx context.Context, key string, id string) (things.Thing, error) {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.view_thing")
+	span.SetTag("thing_id", id)
+	defer span.Finish()
+
+	return tm.svc.ViewThing(ctx, key, id)
+}
+
+func (tm *tracingMiddleware) ViewThings(ctx context.Context, key string, ids []string) ([]things.Thing, error) {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.view_things")
+	defer span.Finish()
+
+	return tm.svc.ViewThings(ctx, key, ids)
+}
+
+func (tm *tracingMiddleware) ViewThingByKey(ctx context.Context, key string) (things.Thing, error) {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.view_thing_by_key")
+	defer span.Finish()
+
+	return tm.svc.ViewThingByKey(ctx, key)
+}
+
+func (tm *tracingMiddleware) ListThings(ctx context.Context, key string, offset, limit int, sorting things.Sorting, filter things.ThingFilter) (things.ThingPage, error) {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.list_things")
+	defer span.Finish()
+
+	return tm.svc.ListThings(ctx, key, offset, limit, sorting, filter)
+}
+
+func (tm *tracingMiddleware) ListThingsAfter(ctx context.Context, key, afterID string, limit int) ([]things.Thing, error) {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.list_things_after")
+	defer span.Finish()
+
+	return tm.svc.ListThingsAfter(ctx, key, afterID, limit)
+}
+
+func (tm *tracingMiddleware) SearchThings(ctx context.Context, key, name string, offset, limit int) ([]things.Thing, error) {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.search_things")
+	defer span.Finish()
+
+	return tm.svc.SearchThings(ctx, key, name, offset, limit)
+}
+
+func (tm *tracingMiddleware) ListUnconnectedThings(ctx context.Context, key string, offset, limit int) ([]things.Thing, error) {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.list_unconnected_things")
+	defer span.Finish()
+
+	return tm.svc.ListUnconnectedThings(ctx, key, offset, limit)
+}
+
+func (tm *tracingMiddleware) ListThingsByMetadata(ctx context.Context, key, metaKey, metaValue string, offset, limit int) ([]things.Thing, error) {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.list_things_by_metadata")
+	defer span.Finish()
+
+	return tm.svc.ListThingsByMetadata(ctx, key, metaKey, metaValue, offset, limit)
+}
+
+func (tm *tracingMiddleware) ListDeletedThings(ctx context.Context, key string, offset, limit int, sorting things.Sorting) (things.ThingPage, error) {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.list_deleted_things")
+	defer span.Finish()
+
+	return tm.svc.ListDeletedThings(ctx, key, offset, limit, sorting)
+}
+
+func (tm *tracingMiddleware) CountThings(ctx context.Context, key string, filter things.ThingFilter) (int, error) {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.count_things")
+	defer span.Finish()
+
+	return tm.svc.CountThings(ctx, key, filter)
+}
+
+func (tm *tracingMiddleware) RemoveThing(ctx context.Context, key string, id string) error {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.remove_thing")
+	span.SetTag("thing_id", id)
+	defer span.Finish()
+
+	return tm.svc.RemoveThing(ctx, key, id)
+}
+
+func (tm *tracingMiddleware) RemoveAllThings(ctx context.Context, key string) error {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.remove_all_things")
+	defer span.Finish()
+
+	return tm.svc.RemoveAllThings(ctx, key)
+}
+
+func (tm *tracingMiddleware) RestoreThing(ctx context.Context, key string, id string) error {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.restore_thing")
+	span.SetTag("thing_id", id)
+	defer span.Finish()
+
+	return tm.svc.RestoreThing(ctx, key, id)
+}
+
+func (tm *tracingMiddleware) TransferThing(ctx context.Context, key, id, newOwner string) error {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.transfer_thing")
+	span.SetTag("thing_id", id)
+	defer span.Finish()
+
+	return tm.svc.TransferThing(ctx, key, id, newOwner)
+}
+
+func (tm *tracingMiddleware) CreateChannel(ctx context.Context, key string, channel things.Channel) (things.Channel, error) {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.create_channel")
+	defer span.Finish()
+
+	return tm.svc.CreateChannel(ctx, key, channel)
+}
+
+func (tm *tracingMiddleware) UpdateChannel(ctx context.Context, key string, channel things.Channel) error {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.update_channel")
+	span.SetTag("channel_id", channel.ID)
+	defer span.Finish()
+
+	return tm.svc.UpdateChannel(ctx, key, channel)
+}
+
+func (tm *tracingMiddleware) ViewChannel(ctx context.Context, key string, id string) (things.Channel, error) {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.view_channel")
+	span.SetTag("channel_id", id)
+	defer span.Finish()
+
+	return tm.svc.ViewChannel(ctx, key, id)
+}
+
+func (tm *tracingMiddleware) ListChannels(ctx context.Context, key string, offset, limit int, sorting things.Sorting, filter things.MetadataFilter) (things.ChannelPage, error) {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.list_channels")
+	defer span.Finish()
+
+	return tm.svc.ListChannels(ctx, key, offset, limit, sorting, filter)
+}
+
+func (tm *tracingMiddleware) ListChannelsByThing(ctx context.Context, key, id string, offset, limit int) ([]things.Channel, error) {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.list_channels_by_thing")
+	span.SetTag("thing_id", id)
+	defer span.Finish()
+
+	return tm.svc.ListChannelsByThing(ctx, key, id, offset, limit)
+}
+
+func (tm *tracingMiddleware) ListThingsByChannel(ctx context.Context, key, id string, offset, limit int, state things.ConnectionState) ([]things.Thing, error) {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.list_things_by_channel")
+	span.SetTag("channel_id", id)
+	defer span.Finish()
+
+	return tm.svc.ListThingsByChannel(ctx, key, id, offset, limit, state)
+}
+
+func (tm *tracingMiddleware) CountChannels(ctx context.Context, key string) (int, error) {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.count_channels")
+	defer span.Finish()
+
+	return tm.svc.CountChannels(ctx, key)
+}
+
+func (tm *tracingMiddleware) ChannelConnectionsCount(ctx context.Context, key, chanID string) (int, error) {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.channel_connections_count")
+	span.SetTag("channel_id", chanID)
+	defer span.Finish()
+
+	return tm.svc.ChannelConnectionsCount(ctx, key, chanID)
+}
+
+func (tm *tracingMiddleware) ChannelConnections(ctx context.Context, key, chanID string) ([]things.Connection, error) {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.channel_connections")
+	span.SetTag("channel_id", chanID)
+	defer span.Finish()
+
+	return tm.svc.ChannelConnections(ctx, key, chanID)
+}
+
+func (tm *tracingMiddleware) RemoveChannel(ctx context.Context, key string, id string) error {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.remove_channel")
+	span.SetTag("channel_id", id)
+	defer span.Finish()
+
+	return tm.svc.RemoveChannel(ctx, key, id)
+}
+
+func (tm *tracingMiddleware) RestoreChannel(ctx context.Context, key string, id string) error {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.restore_channel")
+	span.SetTag("channel_id", id)
+	defer span.Finish()
+
+	return tm.svc.RestoreChannel(ctx, key, id)
+}
+
+func (tm *tracingMiddleware) RemoveAllChannels(ctx context.Context, key string) error {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.remove_all_channels")
+	defer span.Finish()
+
+	return tm.svc.RemoveAllChannels(ctx, key)
+}
+
+func (tm *tracingMiddleware) TransferChannel(ctx context.Context, key, id, newOwner string) error {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.transfer_channel")
+	span.SetTag("channel_id", id)
+	defer span.Finish()
+
+	return tm.svc.TransferChannel(ctx, key, id, newOwner)
+}
+
+func (tm *tracingMiddleware) Connect(ctx context.Context, key, chanID, thingID string, mode things.AccessMode) (things.Connection, error) {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.connect")
+	span.SetTag("channel_id", chanID)
+	span.SetTag("thing_id", thingID)
+	span.SetTag("mode", string(mode))
+	defer span.Finish()
+
+	return tm.svc.Connect(ctx, key, chanID, thingID, mode)
+}
+
+func (tm *tracingMiddleware) ConnectMany(ctx context.Context, key, thingID string, chanIDs []string) error {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.connect_many")
+	span.SetTag("thing_id", thingID)
+	defer span.Finish()
+
+	return tm.svc.ConnectMany(ctx, key, thingID, chanIDs)
+}
+
+func (tm *tracingMiddleware) ConnectThings(ctx context.Context, key, chanID string, thingIDs []string) error {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.connect_things")
+	span.SetTag("channel_id", chanID)
+	defer span.Finish()
+
+	return tm.svc.ConnectThings(ctx, key, chanID, thingIDs)
+}
+
+func (tm *tracingMiddleware) SetChannelThings(ctx context.Context, key, chanID string, thingIDs []string) error {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.set_channel_things")
+	span.SetTag("channel_id", chanID)
+	defer span.Finish()
+
+	return tm.svc.SetChannelThings(ctx, key, chanID, thingIDs)
+}
+
+func (tm *tracingMiddleware) Disconnect(ctx context.Context, key, chanID, thingID string) (bool, error) {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.disconnect")
+	span.SetTag("channel_id", chanID)
+	span.SetTag("thing_id", thingID)
+	defer span.Finish()
+
+	return tm.svc.Disconnect(ctx, key, chanID, thingID)
+}
+
+func (tm *tracingMiddleware) DisconnectMany(ctx context.Context, key, thingID string, chanIDs []string) error {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.disconnect_many")
+	span.SetTag("thing_id", thingID)
+	defer span.Finish()
+
+	return tm.svc.DisconnectMany(ctx, key, thingID, chanIDs)
+}
+
+func (tm *tracingMiddleware) DisconnectAll(ctx context.Context, key, thingID string) error {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.disconnect_all")
+	span.SetTag("thing_id", thingID)
+	defer span.Finish()
+
+	return tm.svc.DisconnectAll(ctx, key, thingID)
+}
+
+func (tm *tracingMiddleware) RebindConnections(ctx context.Context, key, fromID, toID string) error {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.rebind_connections")
+	span.SetTag("from_thing_id", fromID)
+	span.SetTag("to_thing_id", toID)
+	defer span.Finish()
+
+	return tm.svc.RebindConnections(ctx, key, fromID, toID)
+}
+
+func (tm *tracingMiddleware) ThingChannelIDs(ctx context.Context, key, thingID string) ([]string, error) {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.thing_channel_ids")
+	span.SetTag("thing_id", thingID)
+	defer span.Finish()
+
+	return tm.svc.ThingChannelIDs(ctx, key, thingID)
+}
+
+func (tm *tracingMiddleware) IsConnected(ctx context.Context, key, chanID, thingID string) (bool, error) {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.is_connected")
+	span.SetTag("channel_id", chanID)
+	span.SetTag("thing_id", thingID)
+	defer span.Finish()
+
+	return tm.svc.IsConnected(ctx, key, chanID, thingID)
+}
+
+func (tm *tracingMiddleware) CanAccess(ctx context.Context, key string, id string, mode things.AccessMode) (string, error) {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.can_access")
+	span.SetTag("channel_id", id)
+	span.SetTag("mode", string(mode))
+	defer span.Finish()
+
+	return tm.svc.CanAccess(ctx, key, id, mode)
+}
+
+func (tm *tracingMiddleware) CanAccessBatch(ctx context.Context, reqs []things.AccessRequest) ([]things.AccessResult, error) {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.can_access_batch")
+	span.SetTag("requests", len(reqs))
+	defer span.Finish()
+
+	return tm.svc.CanAccessBatch(ctx, reqs)
+}
+
+func (tm *tracingMiddleware) ChannelOwner(ctx context.Context, key, chanID string) (string, error) {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.channel_owner")
+	span.SetTag("channel_id", chanID)
+	defer span.Finish()
+
+	return tm.svc.ChannelOwner(ctx, key, chanID)
+}
+
+func (tm *tracingMiddleware) PurgeDeleted(ctx context.Context, key string, olderThan time.Time) (int, error) {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.purge_deleted")
+	defer span.Finish()
+
+	return tm.svc.PurgeDeleted(ctx, key, olderThan)
+}
+
+func (tm *tracingMiddleware) Health(ctx context.Context) error {
+	span, ctx := tracing.StartSpan(ctx, tm.tracer, "things.health")
+	defer span.Finish()
+
+	return tm.svc.Health(ctx)
+}
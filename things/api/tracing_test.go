@@ -0,0 +1,87 @@
+// +build !test
+
+package api_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/mainflux/mainflux/things"
+	"github.com/mainflux/mainflux/things/api"
+	"github.com/mainflux/mainflux/things/mocks"
+	"github.com/mainflux/mainflux/things/tracing"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracingMiddleware(t *testing.T) {
+	tracer := mocktracer.New()
+	users := tracing.UsersServiceMiddleware(tracer, mocks.NewUsersService(map[string]string{token: email}))
+	thingsRepo := mocks.NewThingRepository()
+	channelsRepo := mocks.NewChannelRepository(thingsRepo)
+	svc := things.New(
+		users,
+		tracing.ThingRepositoryMiddleware(tracer, thingsRepo),
+		tracing.ChannelRepositoryMiddleware(tracer, channelsRepo),
+		mocks.NewIdentityProvider(),
+	)
+	svc = api.TracingMiddleware(svc, tracer)
+
+	sth, _ := svc.AddThing(context.Background(), token, things.Thing{Type: "app", Name: "test"})
+	sch, _ := svc.CreateChannel(context.Background(), token, things.Channel{Name: "test"})
+	svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+
+	cases := []struct {
+		desc     string
+		operate  func() error
+		span     string
+		tag      string
+		value    string
+		children []string
+	}{
+		{
+			desc: "view thing",
+			operate: func() error {
+				_, err := svc.ViewThing(context.Background(), token, sth.ID)
+				return err
+			},
+			span:     "things.view_thing",
+			tag:      "thing_id",
+			value:    sth.ID,
+			children: []string{"users.identify", "thing_repository.one"},
+		},
+		{
+			desc: "access channel",
+			operate: func() error {
+				_, err := svc.CanAccess(context.Background(), sth.Key, sch.ID, things.AccessPubSub)
+				return err
+			},
+			span:     "things.can_access",
+			tag:      "channel_id",
+			value:    sch.ID,
+			children: []string{"channel_repository.has_thing"},
+		},
+	}
+
+	for _, tc := range cases {
+		tracer.Reset()
+		err := tc.operate()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+
+		spans := tracer.FinishedSpans()
+		if !assert.Len(t, spans, len(tc.children)+1, fmt.Sprintf("%s: expected %d spans got %d", tc.desc, len(tc.children)+1, len(spans))) {
+			continue
+		}
+
+		// spans are finished, and thus recorded, from the innermost one
+		span := spans[len(spans)-1]
+		assert.Equal(t, tc.span, span.OperationName, fmt.Sprintf("%s: expected operation %s got %s", tc.desc, tc.span, span.OperationName))
+		assert.Equal(t, tc.value, span.Tag(tc.tag), fmt.Sprintf("%s: expected tag %s to be %s got %v", tc.desc, tc.tag, tc.value, span.Tag(tc.tag)))
+
+		for i, child := range tc.children {
+			assert.Equal(t, child, spans[i].OperationName, fmt.Sprintf("%s: expected operation %s got %s", tc.desc, child, spans[i].OperationName))
+			assert.Equal(t, span.SpanContext.SpanID, spans[i].ParentID, fmt.Sprintf("%s: expected %s to be child of %s", tc.desc, child, tc.span))
+		}
+	}
+}
@@ -0,0 +1,384 @@
+package things
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// AuditCreate records the creation of the thing or channel.
+	AuditCreate = "create"
+
+	// AuditUpdate records the update of the thing or channel.
+	AuditUpdate = "update"
+
+	// AuditRemove records the removal of the thing or channel.
+	AuditRemove = "remove"
+
+	// AuditRestore records the restoration of the removed thing or channel.
+	AuditRestore = "restore"
+
+	// AuditTransfer records the transfer of the thing or channel to another
+	// user.
+	AuditTransfer = "transfer"
+
+	// AuditConnect records the connection of the thing to the channel.
+	AuditConnect = "connect"
+
+	// AuditDisconnect records the disconnection of the thing from the
+	// channel.
+	AuditDisconnect = "disconnect"
+)
+
+const (
+	// EntityThing marks the audit records of the things.
+	EntityThing = "thing"
+
+	// EntityChannel marks the audit records of the channels.
+	EntityChannel = "channel"
+)
+
+// AuditRecord represents the change made by the user. Connection changes
+// are recorded for the thing, and carry the channel's identifier as well.
+type AuditRecord struct {
+	Actor     string
+	Action    string
+	Entity    string
+	EntityID  string
+	ChanID    string
+	Timestamp time.Time
+}
+
+// AuditSink specifies an API for keeping the audit records. The records are
+// never altered once they are written.
+type AuditSink interface {
+	// Write appends the provided record to the audit log.
+	Write(AuditRecord) error
+}
+
+var _ Service = (*auditMiddleware)(nil)
+
+type auditMiddleware struct {
+	Service
+	sink AuditSink
+}
+
+// AuditMiddleware writes the audit record of each successful call changing
+// the things, the channels or their connections to the provided sink. The
+// actor is the owner of the changed entities, identified while serving the
+// call. Since the record is required for compliance, failing to write it
+// fails the call, even though the change itself is already made. The calls
+// that change nothing, such as removing all of the things of the user
+// having none, aren't recorded.
+func AuditMiddleware(svc Service, sink AuditSink) Service {
+	return &auditMiddleware{
+		Service: svc,
+		sink:    sink,
+	}
+}
+
+func (am *auditMiddleware) AddThing(ctx context.Context, key string, thing Thing) (Thing, error) {
+	saved, err := am.Service.AddThing(ctx, key, thing)
+	if err != nil || saved.Existing {
+		return saved, err
+	}
+
+	return saved, am.write(saved.Owner, AuditCreate, EntityThing, saved.ID, "")
+}
+
+func (am *auditMiddleware) CreateThings(ctx context.Context, key string, ths []Thing, dryRun bool) ([]Thing, error) {
+	saved, err := am.Service.CreateThings(ctx, key, ths, dryRun)
+	if err != nil || dryRun {
+		return saved, err
+	}
+
+	for _, th := range saved {
+		if err := am.write(th.Owner, AuditCreate, EntityThing, th.ID, ""); err != nil {
+			return saved, err
+		}
+	}
+
+	return saved, nil
+}
+
+func (am *auditMiddleware) UpdateThing(ctx context.Context, key string, thing Thing) error {
+	ctx, rec := recordCall(ctx)
+	if err := am.Service.UpdateThing(ctx, key, thing); err != nil {
+		return err
+	}
+
+	return am.write(rec.owner, AuditUpdate, EntityThing, thing.ID, "")
+}
+
+func (am *auditMiddleware) UpdateKey(ctx context.Context, key, id, newKey string) error {
+	ctx, rec := recordCall(ctx)
+	if err := am.Service.UpdateKey(ctx, key, id, newKey); err != nil {
+		return err
+	}
+
+	return am.write(rec.owner, AuditUpdate, EntityThing, id, "")
+}
+
+func (am *auditMiddleware) RotateKey(ctx context.Context, key, id string) (string, error) {
+	ctx, rec := recordCall(ctx)
+	newKey, err := am.Service.RotateKey(ctx, key, id)
+	if err != nil {
+		return newKey, err
+	}
+
+	return newKey, am.write(rec.owner, AuditUpdate, EntityThing, id, "")
+}
+
+func (am *auditMiddleware) DisableThing(ctx context.Context, key, id string) error {
+	ctx, rec := recordCall(ctx)
+	if err := am.Service.DisableThing(ctx, key, id); err != nil {
+		return err
+	}
+
+	return am.write(rec.owner, AuditUpdate, EntityThing, id, "")
+}
+
+func (am *auditMiddleware) EnableThing(ctx context.Context, key, id string) error {
+	ctx, rec := recordCall(ctx)
+	if err := am.Service.EnableThing(ctx, key, id); err != nil {
+		return err
+	}
+
+	return am.write(rec.owner, AuditUpdate, EntityThing, id, "")
+}
+
+func (am *auditMiddleware) RemoveThing(ctx context.Context, key, id string) error {
+	ctx, rec := recordCall(ctx)
+	if err := am.Service.RemoveThing(ctx, key, id); err != nil {
+		return err
+	}
+
+	return am.writeRemoved(rec, EntityThing)
+}
+
+func (am *auditMiddleware) RemoveAllThings(ctx context.Context, key string) error {
+	ctx, rec := recordCall(ctx)
+	if err := am.Service.RemoveAllThings(ctx, key); err != nil {
+		return err
+	}
+
+	return am.writeRemoved(rec, EntityThing)
+}
+
+func (am *auditMiddleware) RestoreThing(ctx context.Context, key, id string) error {
+	ctx, rec := recordCall(ctx)
+	if err := am.Service.RestoreThing(ctx, key, id); err != nil {
+		return err
+	}
+
+	return am.write(rec.owner, AuditRestore, EntityThing, id, "")
+}
+
+func (am *auditMiddleware) TransferThing(ctx context.Context, key, id, email string) error {
+	ctx, rec := recordCall(ctx)
+	if err := am.Service.TransferThing(ctx, key, id, email); err != nil {
+		return err
+	}
+
+	return am.write(rec.owner, AuditTransfer, EntityThing, id, "")
+}
+
+func (am *auditMiddleware) CreateChannel(ctx context.Context, key string, channel Channel) (Channel, error) {
+	saved, err := am.Service.CreateChannel(ctx, key, channel)
+	if err != nil {
+		return saved, err
+	}
+
+	return saved, am.write(saved.Owner, AuditCreate, EntityChannel, saved.ID, "")
+}
+
+func (am *auditMiddleware) UpdateChannel(ctx context.Context, key string, channel Channel) error {
+	ctx, rec := recordCall(ctx)
+	if err := am.Service.UpdateChannel(ctx, key, channel); err != nil {
+		return err
+	}
+
+	return am.write(rec.owner, AuditUpdate, EntityChannel, channel.ID, "")
+}
+
+func (am *auditMiddleware) RemoveChannel(ctx context.Context, key, id string) error {
+	ctx, rec := recordCall(ctx)
+	if err := am.Service.RemoveChannel(ctx, key, id); err != nil {
+		return err
+	}
+
+	return am.writeRemoved(rec, EntityChannel)
+}
+
+func (am *auditMiddleware) RestoreChannel(ctx context.Context, key, id string) error {
+	ctx, rec := recordCall(ctx)
+	if err := am.Service.RestoreChannel(ctx, key, id); err != nil {
+		return err
+	}
+
+	return am.write(rec.owner, AuditRestore, EntityChannel, id, "")
+}
+
+func (am *auditMiddleware) RemoveAllChannels(ctx context.Context, key string) error {
+	ctx, rec := recordCall(ctx)
+	if err := am.Service.RemoveAllChannels(ctx, key); err != nil {
+		return err
+	}
+
+	return am.writeRemoved(rec, EntityChannel)
+}
+
+func (am *auditMiddleware) TransferChannel(ctx context.Context, key, id, email string) error {
+	ctx, rec := recordCall(ctx)
+	if err := am.Service.TransferChannel(ctx, key, id, email); err != nil {
+		return err
+	}
+
+	return am.write(rec.owner, AuditTransfer, EntityChannel, id, "")
+}
+
+func (am *auditMiddleware) Connect(ctx context.Context, key, chanID, thingID string, mode AccessMode) (Connection, error) {
+	ctx, rec := recordCall(ctx)
+	conn, err := am.Service.Connect(ctx, key, chanID, thingID, mode)
+	if err != nil {
+		return conn, err
+	}
+
+	return conn, am.write(rec.owner, AuditConnect, EntityThing, thingID, chanID)
+}
+
+func (am *auditMiddleware) ConnectMany(ctx context.Context, key, thingID string, chanIDs []string) error {
+	ctx, rec := recordCall(ctx)
+	if err := am.Service.ConnectMany(ctx, key, thingID, chanIDs); err != nil {
+		return err
+	}
+
+	for _, chanID := range chanIDs {
+		if err := am.write(rec.owner, AuditConnect, EntityThing, thingID, chanID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (am *auditMiddleware) ConnectThings(ctx context.Context, key, chanID string, thingIDs []string) error {
+	ctx, rec := recordCall(ctx)
+	if err := am.Service.ConnectThings(ctx, key, chanID, thingIDs); err != nil {
+		return err
+	}
+
+	for _, thingID := range thingIDs {
+		if err := am.write(rec.owner, AuditConnect, EntityThing, thingID, chanID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (am *auditMiddleware) SetChannelThings(ctx context.Context, key, chanID string, thingIDs []string) error {
+	ctx, rec := recordCall(ctx)
+	if err := am.Service.SetChannelThings(ctx, key, chanID, thingIDs); err != nil {
+		return err
+	}
+
+	return am.write(rec.owner, AuditUpdate, EntityChannel, chanID, "")
+}
+
+func (am *auditMiddleware) Disconnect(ctx context.Context, key, chanID, thingID string) (bool, error) {
+	ctx, rec := recordCall(ctx)
+	removed, err := am.Service.Disconnect(ctx, key, chanID, thingID)
+	if err != nil || !removed {
+		return removed, err
+	}
+
+	return true, am.write(rec.owner, AuditDisconnect, EntityThing, thingID, chanID)
+}
+
+func (am *auditMiddleware) DisconnectMany(ctx context.Context, key, thingID string, chanIDs []string) error {
+	ctx, rec := recordCall(ctx)
+	err := am.Service.DisconnectMany(ctx, key, thingID, chanIDs)
+	nce, ok := err.(NotConnectedError)
+	if err != nil && !ok {
+		return err
+	}
+
+	// the thing is disconnected from the channels it was connected to, even
+	// if it wasn't connected to some of them
+	notConnected := map[string]bool{}
+	for _, chanID := range nce.ChanIDs {
+		notConnected[chanID] = true
+	}
+
+	for _, chanID := range chanIDs {
+		if notConnected[chanID] {
+			continue
+		}
+
+		if err := am.write(rec.owner, AuditDisconnect, EntityThing, thingID, chanID); err != nil {
+			return err
+		}
+	}
+
+	return err
+}
+
+func (am *auditMiddleware) DisconnectAll(ctx context.Context, key, thingID string) error {
+	ctx, rec := recordCall(ctx)
+	if err := am.Service.DisconnectAll(ctx, key, thingID); err != nil {
+		return err
+	}
+
+	return am.writeConnections(rec)
+}
+
+func (am *auditMiddleware) RebindConnections(ctx context.Context, key, fromID, toID string) error {
+	ctx, rec := recordCall(ctx)
+	if err := am.Service.RebindConnections(ctx, key, fromID, toID); err != nil {
+		return err
+	}
+
+	return am.writeConnections(rec)
+}
+
+// writeRemoved writes the record of each of the entities removed by the
+// recorded call.
+func (am *auditMiddleware) writeRemoved(rec *callRecorder, entity string) error {
+	for _, id := range rec.removed {
+		if err := am.write(rec.owner, AuditRemove, entity, id, ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeConnections writes the record of each of the connections removed and
+// made by the recorded call.
+func (am *auditMiddleware) writeConnections(rec *callRecorder) error {
+	for _, conn := range rec.disconnected {
+		if err := am.write(rec.owner, AuditDisconnect, EntityThing, conn.ThingID, conn.ChanID); err != nil {
+			return err
+		}
+	}
+
+	for _, conn := range rec.connected {
+		if err := am.write(rec.owner, AuditConnect, EntityThing, conn.ThingID, conn.ChanID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (am *auditMiddleware) write(actor, action, entity, id, chanID string) error {
+	return am.sink.Write(AuditRecord{
+		Actor:     actor,
+		Action:    action,
+		Entity:    entity,
+		EntityID:  id,
+		ChanID:    chanID,
+		Timestamp: time.Now().UTC(),
+	})
+}
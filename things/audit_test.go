@@ -0,0 +1,165 @@
+package things_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/things"
+	"github.com/mainflux/mainflux/things/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditMiddleware(t *testing.T) {
+	sink := mocks.NewAuditSink()
+	svc := things.AuditMiddleware(newService(map[string]string{token: email}), sink)
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+
+	cases := []struct {
+		desc    string
+		operate func() error
+		record  things.AuditRecord
+	}{
+		{
+			desc: "update thing",
+			operate: func() error {
+				th := sth
+				th.Name = "updated"
+				return svc.UpdateThing(context.Background(), token, th)
+			},
+			record: things.AuditRecord{Actor: email, Action: things.AuditUpdate, Entity: things.EntityThing, EntityID: sth.ID},
+		},
+		{
+			desc: "connect thing",
+			operate: func() error {
+				_, err := svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+				return err
+			},
+			record: things.AuditRecord{Actor: email, Action: things.AuditConnect, Entity: things.EntityThing, EntityID: sth.ID, ChanID: sch.ID},
+		},
+		{
+			desc: "remove channel",
+			operate: func() error {
+				return svc.RemoveChannel(context.Background(), token, sch.ID)
+			},
+			record: things.AuditRecord{Actor: email, Action: things.AuditRemove, Entity: things.EntityChannel, EntityID: sch.ID},
+		},
+		{
+			desc: "remove thing",
+			operate: func() error {
+				return svc.RemoveThing(context.Background(), token, sth.ID)
+			},
+			record: things.AuditRecord{Actor: email, Action: things.AuditRemove, Entity: things.EntityThing, EntityID: sth.ID},
+		},
+	}
+
+	for _, tc := range cases {
+		err := tc.operate()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+
+		records := sink.Records()
+		record := records[len(records)-1]
+		assert.False(t, record.Timestamp.IsZero(), fmt.Sprintf("%s: expected record timestamp", tc.desc))
+		record.Timestamp = tc.record.Timestamp
+		assert.Equal(t, tc.record, record, fmt.Sprintf("%s: expected record %v got %v", tc.desc, tc.record, record))
+	}
+}
+
+func TestAuditMiddlewareUnrecorded(t *testing.T) {
+	sink := mocks.NewAuditSink()
+	svc := things.AuditMiddleware(newService(map[string]string{token: email}), sink)
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	before := len(sink.Records())
+
+	err := svc.RemoveThing(context.Background(), "invalid", sth.ID)
+	assert.Equal(t, things.ErrUnauthorizedAccess, err, fmt.Sprintf("remove thing with invalid key: expected %s got %s", things.ErrUnauthorizedAccess, err))
+
+	err = svc.RemoveThing(context.Background(), token, "non-existing")
+	assert.Nil(t, err, fmt.Sprintf("remove non-existing thing: unexpected error %s", err))
+
+	after := len(sink.Records())
+	assert.Equal(t, before, after, fmt.Sprintf("failed and void calls: expected %d records got %d", before, after))
+}
+
+func TestAuditMiddlewareBulk(t *testing.T) {
+	sink := mocks.NewAuditSink()
+	svc := things.AuditMiddleware(newService(map[string]string{token: email}), sink)
+
+	sth1, _ := svc.AddThing(context.Background(), token, thing)
+	sth2, _ := svc.AddThing(context.Background(), token, thing)
+	sch1, _ := svc.CreateChannel(context.Background(), token, channel)
+	sch2, _ := svc.CreateChannel(context.Background(), token, channel)
+	svc.ConnectMany(context.Background(), token, sth1.ID, []string{sch1.ID, sch2.ID})
+
+	cases := []struct {
+		desc    string
+		operate func() error
+		records []things.AuditRecord
+	}{
+		{
+			desc: "rebind connections",
+			operate: func() error {
+				return svc.RebindConnections(context.Background(), token, sth1.ID, sth2.ID)
+			},
+			records: []things.AuditRecord{
+				{Actor: email, Action: things.AuditDisconnect, Entity: things.EntityThing, EntityID: sth1.ID, ChanID: sch1.ID},
+				{Actor: email, Action: things.AuditDisconnect, Entity: things.EntityThing, EntityID: sth1.ID, ChanID: sch2.ID},
+				{Actor: email, Action: things.AuditConnect, Entity: things.EntityThing, EntityID: sth2.ID, ChanID: sch1.ID},
+				{Actor: email, Action: things.AuditConnect, Entity: things.EntityThing, EntityID: sth2.ID, ChanID: sch2.ID},
+			},
+		},
+		{
+			desc: "disconnect all",
+			operate: func() error {
+				return svc.DisconnectAll(context.Background(), token, sth2.ID)
+			},
+			records: []things.AuditRecord{
+				{Actor: email, Action: things.AuditDisconnect, Entity: things.EntityThing, EntityID: sth2.ID, ChanID: sch1.ID},
+				{Actor: email, Action: things.AuditDisconnect, Entity: things.EntityThing, EntityID: sth2.ID, ChanID: sch2.ID},
+			},
+		},
+		{
+			desc: "remove all channels",
+			operate: func() error {
+				return svc.RemoveAllChannels(context.Background(), token)
+			},
+			records: []things.AuditRecord{
+				{Actor: email, Action: things.AuditRemove, Entity: things.EntityChannel, EntityID: sch1.ID},
+				{Actor: email, Action: things.AuditRemove, Entity: things.EntityChannel, EntityID: sch2.ID},
+			},
+		},
+		{
+			desc: "remove all things",
+			operate: func() error {
+				return svc.RemoveAllThings(context.Background(), token)
+			},
+			records: []things.AuditRecord{
+				{Actor: email, Action: things.AuditRemove, Entity: things.EntityThing, EntityID: sth1.ID},
+				{Actor: email, Action: things.AuditRemove, Entity: things.EntityThing, EntityID: sth2.ID},
+			},
+		},
+		{
+			desc: "remove all things of the user having none",
+			operate: func() error {
+				return svc.RemoveAllThings(context.Background(), token)
+			},
+			records: []things.AuditRecord{},
+		},
+	}
+
+	for _, tc := range cases {
+		before := len(sink.Records())
+		err := tc.operate()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+
+		records := sink.Records()[before:]
+		for i := range records {
+			records[i].Timestamp = time.Time{}
+		}
+		assert.ElementsMatch(t, tc.records, records, fmt.Sprintf("%s: expected records %v got %v", tc.desc, tc.records, records))
+	}
+}
@@ -0,0 +1,267 @@
+package things
+
+import (
+	"context"
+	"time"
+)
+
+// DenialTTL is the longest period during which the denied access is cached.
+const DenialTTL = time.Second
+
+// removalBatch is the number of things or channels retrieved at once when
+// collecting the ones whose cached access is revoked by their bulk removal.
+const removalBatch = 100
+
+// AccessCache specifies an API for caching the results of channel access
+// checks.
+type AccessCache interface {
+	// Save caches the identifier of the thing with the provided key that is
+	// allowed to access the specified channel in the provided mode. Empty
+	// identifier denotes that the access is denied. The entry expires after
+	// the provided TTL.
+	Save(string, string, AccessMode, string, time.Duration) error
+
+	// ID retrieves the cached identifier of the thing with the provided key
+	// that accesses the specified channel in the provided mode. ErrNotFound
+	// is returned if there is no such entry.
+	ID(string, string, AccessMode) (string, error)
+
+	// RemoveThing removes all entries of the thing having the provided
+	// identifier.
+	RemoveThing(string) error
+
+	// RemoveChannel removes all entries of the channel having the provided
+	// identifier.
+	RemoveChannel(string) error
+}
+
+var _ Service = (*cachingService)(nil)
+
+type cachingService struct {
+	Service
+	cache AccessCache
+	ttl   time.Duration
+}
+
+// NewCachingService decorates the provided service with the access cache.
+// Granted access is cached for the provided TTL, while denied access is
+// never cached longer than DenialTTL.
+func NewCachingService(svc Service, cache AccessCache, ttl time.Duration) Service {
+	return &cachingService{
+		Service: svc,
+		cache:   cache,
+		ttl:     ttl,
+	}
+}
+
+func (cs *cachingService) CanAccess(ctx context.Context, key, channel string, mode AccessMode) (string, error) {
+	if id, err := cs.cache.ID(channel, key, mode); err == nil {
+		if id == "" {
+			return "", ErrUnauthorizedAccess
+		}
+		return id, nil
+	}
+
+	id, err := cs.Service.CanAccess(ctx, key, channel, mode)
+	switch err {
+	case nil:
+		cs.cache.Save(channel, key, mode, id, cs.ttl)
+	case ErrUnauthorizedAccess:
+		ttl := cs.ttl
+		if ttl > DenialTTL {
+			ttl = DenialTTL
+		}
+		cs.cache.Save(channel, key, mode, "", ttl)
+	}
+
+	return id, err
+}
+
+func (cs *cachingService) CanAccessBatch(ctx context.Context, reqs []AccessRequest) ([]AccessResult, error) {
+	return canAccessBatch(ctx, cs.CanAccess, reqs)
+}
+
+func (cs *cachingService) UpdateKey(ctx context.Context, key, id, newKey string) error {
+	if err := cs.Service.UpdateKey(ctx, key, id, newKey); err != nil {
+		return err
+	}
+
+	return cs.cache.RemoveThing(id)
+}
+
+func (cs *cachingService) RotateKey(ctx context.Context, key, id string) (string, error) {
+	newKey, err := cs.Service.RotateKey(ctx, key, id)
+	if err != nil {
+		return "", err
+	}
+
+	return newKey, cs.cache.RemoveThing(id)
+}
+
+func (cs *cachingService) DisableThing(ctx context.Context, key, id string) error {
+	if err := cs.Service.DisableThing(ctx, key, id); err != nil {
+		return err
+	}
+
+	return cs.cache.RemoveThing(id)
+}
+
+func (cs *cachingService) RemoveThing(ctx context.Context, key, id string) error {
+	if err := cs.Service.RemoveThing(ctx, key, id); err != nil {
+		return err
+	}
+
+	return cs.cache.RemoveThing(id)
+}
+
+func (cs *cachingService) RemoveAllThings(ctx context.Context, key string) error {
+	// the removed things can't be retrieved, so they are collected beforehand
+	ids := []string{}
+	for after := ""; ; {
+		ths, err := cs.Service.ListThingsAfter(ctx, key, after, removalBatch)
+		if err != nil {
+			return err
+		}
+
+		for _, th := range ths {
+			ids = append(ids, th.ID)
+		}
+
+		if len(ths) < removalBatch {
+			break
+		}
+		after = ths[len(ths)-1].ID
+	}
+
+	if err := cs.Service.RemoveAllThings(ctx, key); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err := cs.cache.RemoveThing(id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (cs *cachingService) TransferThing(ctx context.Context, key, id, newOwner string) error {
+	if err := cs.Service.TransferThing(ctx, key, id, newOwner); err != nil {
+		return err
+	}
+
+	return cs.cache.RemoveThing(id)
+}
+
+func (cs *cachingService) TransferChannel(ctx context.Context, key, id, newOwner string) error {
+	if err := cs.Service.TransferChannel(ctx, key, id, newOwner); err != nil {
+		return err
+	}
+
+	return cs.cache.RemoveChannel(id)
+}
+
+func (cs *cachingService) RemoveChannel(ctx context.Context, key, id string) error {
+	if err := cs.Service.RemoveChannel(ctx, key, id); err != nil {
+		return err
+	}
+
+	return cs.cache.RemoveChannel(id)
+}
+
+func (cs *cachingService) RestoreChannel(ctx context.Context, key, id string) error {
+	if err := cs.Service.RestoreChannel(ctx, key, id); err != nil {
+		return err
+	}
+
+	// denied access to the removed channel is cached as well
+	return cs.cache.RemoveChannel(id)
+}
+
+func (cs *cachingService) RemoveAllChannels(ctx context.Context, key string) error {
+	// the removed channels can't be retrieved, so they are collected beforehand
+	ids := []string{}
+	for offset := 0; ; offset += removalBatch {
+		page, err := cs.Service.ListChannels(ctx, key, offset, removalBatch, Sorting{}, MetadataFilter{})
+		if err != nil {
+			return err
+		}
+
+		for _, ch := range page.Channels {
+			ids = append(ids, ch.ID)
+		}
+
+		if len(page.Channels) < removalBatch {
+			break
+		}
+	}
+
+	if err := cs.Service.RemoveAllChannels(ctx, key); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err := cs.cache.RemoveChannel(id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (cs *cachingService) SetChannelThings(ctx context.Context, key, chanID string, thingIDs []string) error {
+	if err := cs.Service.SetChannelThings(ctx, key, chanID, thingIDs); err != nil {
+		return err
+	}
+
+	return cs.cache.RemoveChannel(chanID)
+}
+
+func (cs *cachingService) Connect(ctx context.Context, key, chanID, thingID string, mode AccessMode) (Connection, error) {
+	conn, err := cs.Service.Connect(ctx, key, chanID, thingID, mode)
+	if err != nil {
+		return conn, err
+	}
+
+	// reconnecting the thing may narrow the access granted to it
+	return conn, cs.cache.RemoveThing(thingID)
+}
+
+func (cs *cachingService) Disconnect(ctx context.Context, key, chanID, thingID string) (bool, error) {
+	removed, err := cs.Service.Disconnect(ctx, key, chanID, thingID)
+	if err != nil || !removed {
+		return removed, err
+	}
+
+	return true, cs.cache.RemoveThing(thingID)
+}
+
+func (cs *cachingService) DisconnectMany(ctx context.Context, key, thingID string, chanIDs []string) error {
+	err := cs.Service.DisconnectMany(ctx, key, thingID, chanIDs)
+	if _, ok := err.(NotConnectedError); err != nil && !ok {
+		return err
+	}
+
+	if cerr := cs.cache.RemoveThing(thingID); cerr != nil {
+		return cerr
+	}
+
+	return err
+}
+
+func (cs *cachingService) DisconnectAll(ctx context.Context, key, thingID string) error {
+	if err := cs.Service.DisconnectAll(ctx, key, thingID); err != nil {
+		return err
+	}
+
+	return cs.cache.RemoveThing(thingID)
+}
+
+func (cs *cachingService) RebindConnections(ctx context.Context, key, fromID, toID string) error {
+	if err := cs.Service.RebindConnections(ctx, key, fromID, toID); err != nil {
+		return err
+	}
+
+	return cs.cache.RemoveThing(fromID)
+}
@@ -0,0 +1,143 @@
+// Package cache provides the in-memory access cache and idempotency store.
+package cache
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mainflux/mainflux/things"
+)
+
+// sweepInterval is the minimal period between the removals of all of the
+// expired entries and claims, which are otherwise removed only once looked
+// up.
+const sweepInterval = time.Minute
+
+var _ things.AccessCache = (*accessCache)(nil)
+
+type entry struct {
+	thingID string
+	expires time.Time
+}
+
+type access struct {
+	chanID string
+	key    string
+	mode   things.AccessMode
+}
+
+type accessCache struct {
+	mu        sync.Mutex
+	entries   map[access]entry
+	byThing   map[string]map[access]bool
+	byChan    map[string]map[access]bool
+	nextSweep time.Time
+}
+
+// New instantiates an in-memory access cache.
+func New() things.AccessCache {
+	return &accessCache{
+		entries:   make(map[access]entry),
+		byThing:   make(map[string]map[access]bool),
+		byChan:    make(map[string]map[access]bool),
+		nextSweep: time.Now().Add(sweepInterval),
+	}
+}
+
+func (ac *accessCache) Save(chanID, key string, mode things.AccessMode, thingID string, ttl time.Duration) error {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	now := time.Now()
+	if now.After(ac.nextSweep) {
+		ac.sweep(now)
+	}
+
+	a := access{chanID, key, mode}
+	ac.remove(a)
+	ac.entries[a] = entry{
+		thingID: thingID,
+		expires: now.Add(ttl),
+	}
+	index(ac.byThing, thingID, a)
+	index(ac.byChan, chanID, a)
+
+	return nil
+}
+
+func (ac *accessCache) ID(chanID, key string, mode things.AccessMode) (string, error) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	a := access{chanID, key, mode}
+	e, ok := ac.entries[a]
+	if !ok {
+		return "", things.ErrNotFound
+	}
+
+	if time.Now().After(e.expires) {
+		ac.remove(a)
+		return "", things.ErrNotFound
+	}
+
+	return e.thingID, nil
+}
+
+func (ac *accessCache) RemoveThing(thingID string) error {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	for a := range ac.byThing[thingID] {
+		ac.remove(a)
+	}
+
+	return nil
+}
+
+func (ac *accessCache) RemoveChannel(chanID string) error {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	for a := range ac.byChan[chanID] {
+		ac.remove(a)
+	}
+
+	return nil
+}
+
+// sweep removes all of the entries expired by the provided time.
+func (ac *accessCache) sweep(now time.Time) {
+	for a, e := range ac.entries {
+		if now.After(e.expires) {
+			ac.remove(a)
+		}
+	}
+
+	ac.nextSweep = now.Add(sweepInterval)
+}
+
+// remove removes the entry of the access, along with its index entries.
+func (ac *accessCache) remove(a access) {
+	e, ok := ac.entries[a]
+	if !ok {
+		return
+	}
+
+	delete(ac.entries, a)
+	unindex(ac.byThing, e.thingID, a)
+	unindex(ac.byChan, a.chanID, a)
+}
+
+func index(idx map[string]map[access]bool, id string, a access) {
+	if idx[id] == nil {
+		idx[id] = make(map[access]bool)
+	}
+	idx[id][a] = true
+}
+
+func unindex(idx map[string]map[access]bool, id string, a access) {
+	delete(idx[id], a)
+	if len(idx[id]) == 0 {
+		delete(idx, id)
+	}
+}
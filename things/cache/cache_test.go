@@ -0,0 +1,46 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/things"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessCacheSweep(t *testing.T) {
+	ac := New().(*accessCache)
+
+	ac.Save("chan1", "key1", things.AccessPubSub, "thing1", time.Millisecond)
+	ac.Save("chan2", "key2", things.AccessPubSub, "thing2", time.Minute)
+	time.Sleep(2 * time.Millisecond)
+
+	// the expired entry outlives the sweep interval without being looked up
+	ac.nextSweep = time.Now()
+	ac.Save("chan3", "key3", things.AccessPubSub, "thing3", time.Minute)
+
+	assert.Len(t, ac.entries, 2, fmt.Sprintf("expected %d entries got %d\n", 2, len(ac.entries)))
+	assert.NotContains(t, ac.byThing, "thing1", "expected expired entry to be unindexed by thing\n")
+	assert.NotContains(t, ac.byChan, "chan1", "expected expired entry to be unindexed by channel\n")
+}
+
+func TestAccessCacheRemove(t *testing.T) {
+	ac := New().(*accessCache)
+
+	ac.Save("chan1", "key1", things.AccessPub, "thing1", time.Minute)
+	ac.Save("chan1", "key1", things.AccessSub, "thing1", time.Minute)
+	ac.Save("chan1", "key2", things.AccessPubSub, "thing2", time.Minute)
+	ac.Save("chan2", "key2", things.AccessPubSub, "thing2", time.Minute)
+
+	ac.RemoveThing("thing1")
+	assert.Len(t, ac.entries, 2, fmt.Sprintf("remove thing: expected %d entries got %d\n", 2, len(ac.entries)))
+
+	ac.RemoveChannel("chan1")
+	assert.Len(t, ac.entries, 1, fmt.Sprintf("remove channel: expected %d entries got %d\n", 1, len(ac.entries)))
+
+	id, err := ac.ID("chan2", "key2", things.AccessPubSub)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error %s\n", err))
+	assert.Equal(t, "thing2", id, fmt.Sprintf("expected %s got %s\n", "thing2", id))
+	assert.Len(t, ac.byChan, 1, fmt.Sprintf("expected %d indexed channels got %d\n", 1, len(ac.byChan)))
+}
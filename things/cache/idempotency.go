@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mainflux/mainflux/things"
+)
+
+var _ things.IdempotencyStore = (*idempotencyStore)(nil)
+
+type claim struct {
+	res     []byte
+	saved   bool
+	expires time.Time
+}
+
+type idempotencyStore struct {
+	mu        sync.Mutex
+	claims    map[string]claim
+	nextSweep time.Time
+}
+
+// NewIdempotencyStore instantiates an in-memory idempotency store.
+func NewIdempotencyStore() things.IdempotencyStore {
+	return &idempotencyStore{
+		claims:    make(map[string]claim),
+		nextSweep: time.Now().Add(sweepInterval),
+	}
+}
+
+func (is *idempotencyStore) Reserve(key string, ttl time.Duration) error {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	now := time.Now()
+	if now.After(is.nextSweep) {
+		is.sweep(now)
+	}
+
+	if c, ok := is.claims[key]; ok && now.Before(c.expires) {
+		return things.ErrConflict
+	}
+
+	is.claims[key] = claim{expires: now.Add(ttl)}
+	return nil
+}
+
+func (is *idempotencyStore) Save(key string, res []byte) error {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	c, ok := is.claims[key]
+	if !ok || time.Now().After(c.expires) {
+		delete(is.claims, key)
+		return things.ErrNotFound
+	}
+
+	c.res, c.saved = res, true
+	is.claims[key] = c
+	return nil
+}
+
+func (is *idempotencyStore) Response(key string) ([]byte, error) {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	c, ok := is.claims[key]
+	if !ok {
+		return nil, things.ErrNotFound
+	}
+
+	if time.Now().After(c.expires) {
+		delete(is.claims, key)
+		return nil, things.ErrNotFound
+	}
+
+	if !c.saved {
+		return nil, things.ErrNotFound
+	}
+
+	return c.res, nil
+}
+
+func (is *idempotencyStore) Release(key string) error {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	delete(is.claims, key)
+	return nil
+}
+
+// sweep removes all of the claims expired by the provided time.
+func (is *idempotencyStore) sweep(now time.Time) {
+	for key, c := range is.claims {
+		if now.After(c.expires) {
+			delete(is.claims, key)
+		}
+	}
+
+	is.nextSweep = now.Add(sweepInterval)
+}
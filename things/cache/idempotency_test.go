@@ -0,0 +1,24 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyStoreSweep(t *testing.T) {
+	is := NewIdempotencyStore().(*idempotencyStore)
+
+	is.Reserve("key1", time.Millisecond)
+	is.Reserve("key2", time.Minute)
+	time.Sleep(2 * time.Millisecond)
+
+	// the expired claim outlives the sweep interval without being looked up
+	is.nextSweep = time.Now()
+	is.Reserve("key3", time.Minute)
+
+	assert.Len(t, is.claims, 2, fmt.Sprintf("expected %d claims got %d\n", 2, len(is.claims)))
+	assert.NotContains(t, is.claims, "key1", "expected expired claim to be removed\n")
+}
@@ -0,0 +1,139 @@
+package things_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/things"
+	"github.com/mainflux/mainflux/things/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachedCanAccess(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	csvc := things.NewCachingService(svc, cache.New(), time.Minute)
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+
+	id, err := csvc.CanAccess(context.Background(), sth.Key, sch.ID, things.AccessPubSub)
+	assert.Nil(t, err, fmt.Sprintf("check access of connected thing: unexpected error %s\n", err))
+
+	// the underlying service is bypassed, so the cached access is retained
+	svc.Disconnect(context.Background(), token, sch.ID, sth.ID)
+	cid, err := csvc.CanAccess(context.Background(), sth.Key, sch.ID, things.AccessPubSub)
+	assert.Nil(t, err, fmt.Sprintf("check cached access: unexpected error %s\n", err))
+	assert.Equal(t, id, cid, fmt.Sprintf("check cached access: expected %s got %s\n", id, cid))
+}
+
+func TestCachedAccessInvalidation(t *testing.T) {
+	svc := newService(map[string]string{token: email, "other-token": "other@example.com"})
+	csvc := things.NewCachingService(svc, cache.New(), time.Minute)
+
+	cases := map[string]func(thingID, chanID string) error{
+		"disconnect thing": func(thingID, chanID string) error {
+			_, err := csvc.Disconnect(context.Background(), token, chanID, thingID)
+			return err
+		},
+		"disconnect thing from many channels": func(thingID, chanID string) error {
+			return csvc.DisconnectMany(context.Background(), token, thingID, []string{chanID})
+		},
+		"disconnect thing from all channels": func(thingID, _ string) error {
+			return csvc.DisconnectAll(context.Background(), token, thingID)
+		},
+		"set channel's things": func(_, chanID string) error {
+			return csvc.SetChannelThings(context.Background(), token, chanID, []string{})
+		},
+		"rebind thing's connections": func(thingID, _ string) error {
+			sth, _ := svc.AddThing(context.Background(), token, thing)
+			return csvc.RebindConnections(context.Background(), token, thingID, sth.ID)
+		},
+		"remove all things": func(_, _ string) error {
+			return csvc.RemoveAllThings(context.Background(), token)
+		},
+		"disable thing": func(thingID, _ string) error {
+			return csvc.DisableThing(context.Background(), token, thingID)
+		},
+		"remove thing": func(thingID, _ string) error {
+			return csvc.RemoveThing(context.Background(), token, thingID)
+		},
+		"update thing's key": func(thingID, _ string) error {
+			return csvc.UpdateKey(context.Background(), token, thingID, "9c3f1b2e-7d4a-4c1e-8f6b-0a5d3e2c1b7f")
+		},
+		"rotate thing's key": func(thingID, _ string) error {
+			_, err := csvc.RotateKey(context.Background(), token, thingID)
+			return err
+		},
+		"remove channel": func(_, chanID string) error {
+			return csvc.RemoveChannel(context.Background(), token, chanID)
+		},
+		"remove all channels": func(_, _ string) error {
+			return csvc.RemoveAllChannels(context.Background(), token)
+		},
+		"transfer thing": func(thingID, _ string) error {
+			return csvc.TransferThing(context.Background(), token, thingID, "other@example.com")
+		},
+		"transfer channel": func(_, chanID string) error {
+			return csvc.TransferChannel(context.Background(), token, chanID, "other@example.com")
+		},
+		"reconnect thing in pub mode": func(thingID, chanID string) error {
+			_, err := csvc.Connect(context.Background(), token, chanID, thingID, things.AccessPub)
+			return err
+		},
+	}
+
+	for desc, invalidate := range cases {
+		sth, _ := svc.AddThing(context.Background(), token, thing)
+		sch, _ := svc.CreateChannel(context.Background(), token, channel)
+		svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+		csvc.CanAccess(context.Background(), sth.Key, sch.ID, things.AccessPubSub)
+
+		err := invalidate(sth.ID, sch.ID)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s\n", desc, err))
+
+		_, err = csvc.CanAccess(context.Background(), sth.Key, sch.ID, things.AccessPubSub)
+		assert.Equal(t, things.ErrUnauthorizedAccess, err, fmt.Sprintf("%s: expected %s got %s\n", desc, things.ErrUnauthorizedAccess, err))
+	}
+}
+
+func TestCachedAccessModes(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	csvc := things.NewCachingService(svc, cache.New(), time.Minute)
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPub)
+
+	_, err := csvc.CanAccess(context.Background(), sth.Key, sch.ID, things.AccessPub)
+	assert.Nil(t, err, fmt.Sprintf("publish using pub-only connection: unexpected error %s\n", err))
+
+	// the granted publishing doesn't grant subscribing
+	_, err = csvc.CanAccess(context.Background(), sth.Key, sch.ID, things.AccessSub)
+	assert.Equal(t, things.ErrUnauthorizedAccess, err, fmt.Sprintf("subscribe using pub-only connection: expected %s got %s\n", things.ErrUnauthorizedAccess, err))
+
+	_, err = csvc.CanAccess(context.Background(), sth.Key, sch.ID, things.AccessPub)
+	assert.Nil(t, err, fmt.Sprintf("publish after denied subscribing: unexpected error %s\n", err))
+}
+
+func TestCachedAccessDenial(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ttl := 10 * time.Millisecond
+	csvc := things.NewCachingService(svc, cache.New(), ttl)
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+
+	_, err := csvc.CanAccess(context.Background(), sth.Key, sch.ID, things.AccessPubSub)
+	assert.Equal(t, things.ErrUnauthorizedAccess, err, fmt.Sprintf("check access of unconnected thing: expected %s got %s\n", things.ErrUnauthorizedAccess, err))
+
+	svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+	_, err = csvc.CanAccess(context.Background(), sth.Key, sch.ID, things.AccessPubSub)
+	assert.Equal(t, things.ErrUnauthorizedAccess, err, fmt.Sprintf("check cached denial: expected %s got %s\n", things.ErrUnauthorizedAccess, err))
+
+	time.Sleep(2 * ttl)
+	_, err = csvc.CanAccess(context.Background(), sth.Key, sch.ID, things.AccessPubSub)
+	assert.Nil(t, err, fmt.Sprintf("check access after denial expired: unexpected error %s\n", err))
+}
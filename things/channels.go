@@ -1,12 +1,134 @@
 package things
 
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
 // Channel represents a Mainflux "communication group". This group contains the
 // things that can exchange messages between eachother.
 type Channel struct {
-	ID     string  `json:"id"`
-	Owner  string  `json:"-"`
-	Name   string  `json:"name,omitempty"`
-	Things []Thing `json:"connected,omitempty"`
+	ID        string                 `json:"id"`
+	Owner     string                 `json:"-"`
+	Name      string                 `json:"name,omitempty"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	Things    []Thing                `json:"connected,omitempty"`
+	Webhook   *Webhook               `json:"webhook,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+	UpdatedAt time.Time              `json:"updated_at"`
+	DeletedAt time.Time              `json:"-"`
+}
+
+// Deleted determines whether the channel is removed.
+func (c Channel) Deleted() bool {
+	return !c.DeletedAt.IsZero()
+}
+
+// Webhook represents the HTTP endpoint that is notified whenever a thing is
+// connected to or disconnected from the channel. If the secret is set, the
+// notifications are signed with it.
+type Webhook struct {
+	URL    string `json:"url"`
+	Secret string `json:"secret,omitempty"`
+}
+
+// Validate returns an error if channel representation is invalid.
+func (c *Channel) Validate() error {
+	if err := validateName(c.Name); err != nil {
+		return err
+	}
+
+	if c.Webhook != nil {
+		return c.Webhook.Validate()
+	}
+
+	return nil
+}
+
+// Validate returns an error if webhook representation is invalid.
+func (w *Webhook) Validate() error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrMalformedEntity
+	}
+
+	return nil
+}
+
+// AccessMode specifies the kind of access to the channel that is granted to
+// the thing by its connection to the channel.
+type AccessMode string
+
+const (
+	// AccessPub allows the thing only to publish to the channel.
+	AccessPub AccessMode = "pub"
+
+	// AccessSub allows the thing only to subscribe to the channel.
+	AccessSub AccessMode = "sub"
+
+	// AccessPubSub allows the thing both to publish and to subscribe to the
+	// channel.
+	AccessPubSub AccessMode = "pubsub"
+)
+
+// Validate returns an error if the access mode is unknown.
+func (m AccessMode) Validate() error {
+	switch m {
+	case AccessPub, AccessSub, AccessPubSub:
+		return nil
+	default:
+		return ErrMalformedEntity
+	}
+}
+
+// Permits determines whether the connection having the access mode allows
+// the requested kind of access.
+func (m AccessMode) Permits(req AccessMode) bool {
+	return m == AccessPubSub || m == req
+}
+
+// Connection represents the link between the channel and the thing connected
+// to it.
+type Connection struct {
+	ChanID      string
+	ThingID     string
+	Mode        AccessMode
+	ConnectedAt time.Time
+}
+
+// ConnectionState selects the things by the state of their connection to the
+// channel.
+type ConnectionState int
+
+const (
+	// StateConnected selects the things connected to the channel.
+	StateConnected ConnectionState = iota
+
+	// StateDisconnected selects the things recently disconnected from the
+	// channel, that haven't been connected to it again since.
+	StateDisconnected
+
+	// StateAny selects both the connected and the recently disconnected
+	// things.
+	StateAny
+)
+
+// ConnectionFilter restricts the retrieved things to the ones in the
+// specified state of connection to the channel. The things disconnected
+// before DisconnectedSince are not considered recently disconnected.
+type ConnectionFilter struct {
+	State             ConnectionState
+	DisconnectedSince time.Time
+}
+
+// ChannelPage contains a subset of channels owned by the user, along with the
+// total number of channels the user owns.
+type ChannelPage struct {
+	Channels []Channel
+	Total    int
+	Offset   int
+	Limit    int
 }
 
 // ChannelRepository specifies a channel persistence API.
@@ -14,31 +136,141 @@ type ChannelRepository interface {
 	// Save persists the channel. Successful operation is indicated by unique
 	// identifier accompanied by nil error response. A non-nil error is
 	// returned to indicate operation failure.
-	Save(Channel) (string, error)
+	Save(context.Context, Channel) (string, error)
 
 	// Update performs an update to the existing channel. A non-nil error is
 	// returned to indicate operation failure.
-	Update(Channel) error
+	Update(context.Context, Channel) error
 
 	// One retrieves the channel having the provided identifier, that is owned
 	// by the specified user.
-	One(string, string) (Channel, error)
+	One(context.Context, string, string) (Channel, error)
 
-	// All retrieves the subset of channels owned by the specified user.
-	All(string, int, int) []Channel
+	// ByName retrieves the channel having the provided name, that is owned
+	// by the specified user. If the user has more than one channel with the
+	// name, any of them is retrieved.
+	ByName(context.Context, string, string) (Channel, error)
 
-	// Remove removes the channel having the provided identifier, that is owned
-	// by the specified user.
-	Remove(string, string) error
+	// All retrieves the subset of channels owned by the specified user,
+	// whose metadata match the provided filter, sorted as specified. The
+	// returned page also reports the total number of channels the user owns
+	// that match the filter.
+	All(context.Context, string, int, int, Sorting, MetadataFilter) ChannelPage
+
+	// AllByThing retrieves the subset of channels owned by the specified
+	// user and connected to the specified thing.
+	AllByThing(context.Context, string, string, int, int) []Channel
+
+	// Things retrieves the subset of things connected to the channel having
+	// the provided identifier, that is owned by the specified user. The
+	// things are selected by the state of their connection to the channel,
+	// as specified by the provided filter.
+	Things(context.Context, string, string, int, int, ConnectionFilter) []Thing
+
+	// UnconnectedThings retrieves the subset of things owned by the specified
+	// user that aren't connected to any channel, sorted by their identifiers.
+	// Connections to the removed channels are not considered.
+	UnconnectedThings(context.Context, string, int, int) []Thing
+
+	// Count retrieves the number of channels owned by the specified user.
+	Count(context.Context, string) int
+
+	// CountThings retrieves the number of things connected to the channel
+	// having the provided identifier, that is owned by the specified user.
+	CountThings(context.Context, string, string) (int, error)
+
+	// ChangeOwner transfers the channel having the provided identifier, that
+	// is owned by the specified user, to the new owner. All of the things
+	// connected to the channel are disconnected before it is transferred.
+	// ErrConflict is returned if the new owner already has the channel with
+	// the same identifier.
+	ChangeOwner(context.Context, string, string, string) error
+
+	// Remove marks the channel having the provided identifier, that is owned
+	// by the specified user, as removed at the current time. Removed channel
+	// keeps its connections, but it's not retrieved, can't be accessed, and
+	// can be restored.
+	Remove(context.Context, string, string) error
+
+	// Restore restores the removed channel having the provided identifier,
+	// that is owned by the specified user, along with its connections.
+	Restore(context.Context, string, string) error
 
-	// Connect adds thing to the channel's list of connected things.
-	Connect(string, string, string) error
+	// Purge permanently removes the channels of all of the users, that were
+	// removed before the provided time, along with their connections, and
+	// returns their number.
+	Purge(context.Context, time.Time) (int, error)
+
+	// RemoveAll marks all of the channels owned by the specified user as
+	// removed. All of the things connected to the channels, including the
+	// removed ones, are disconnected before the channels themselves are
+	// removed.
+	RemoveAll(context.Context, string) error
+
+	// Connect adds thing to the channel's list of connected things with the
+	// provided access mode, and returns the connection stamped with the time
+	// it was made. If the thing is already connected, the existing
+	// connection is returned with its access mode replaced.
+	Connect(context.Context, string, string, string, AccessMode) (Connection, error)
+
+	// ConnectMany adds thing to the lists of connected things of all of the
+	// specified channels, allowing it both to publish and to subscribe.
+	// Either all connections are made, or none of them is made and a non-nil
+	// error is returned.
+	ConnectMany(context.Context, string, string, []string) error
+
+	// ConnectThings adds all of the specified things to the channel's list
+	// of connected things, allowing them both to publish and to subscribe.
+	// Either all connections are made, or none of them is made and a non-nil
+	// error is returned.
+	ConnectThings(context.Context, string, string, []string) error
 
 	// Disconnect removes thing from the channel's list of connected
-	// things.
-	Disconnect(string, string, string) error
+	// things, and reports whether the thing was connected to the channel.
+	// Disconnecting the thing that isn't connected changes nothing, and
+	// isn't an error.
+	Disconnect(context.Context, string, string, string) (bool, error)
+
+	// DisconnectAll removes thing from the lists of connected things of all
+	// of the channels owned by the specified user.
+	DisconnectAll(context.Context, string, string) error
+
+	// Rebind connects the thing having the second provided identifier to all
+	// of the channels the thing having the first one is connected to, with
+	// the same access modes, and disconnects the latter from them. Both
+	// things must be owned by the specified user. Either all connections are
+	// moved, or none of them is moved and a non-nil error is returned.
+	Rebind(context.Context, string, string, string) error
+
+	// DisconnectAllThings removes all of the things owned by the specified
+	// user from the lists of connected things of all of the channels.
+	DisconnectAllThings(context.Context, string) error
+
+	// HasConnection determines whether the thing having the provided
+	// identifier is connected to the specified channel. Both of them must be
+	// owned by the specified user.
+	HasConnection(context.Context, string, string, string) bool
+
+	// Connections retrieves the identifiers of all of the channels the
+	// thing having the provided identifier is connected to, sorted in
+	// ascending order. Both of them must be owned by the specified user.
+	Connections(context.Context, string, string) ([]string, error)
+
+	// ChannelConnections retrieves the connections of all of the things
+	// connected to the channel having the provided identifier, that is owned
+	// by the specified user, sorted by the time they were made.
+	ChannelConnections(context.Context, string, string) ([]Connection, error)
 
 	// HasThing determines whether the thing with the provided access key, is
-	// "connected" to the specified channel.
-	HasThing(string, string) (string, error)
+	// "connected" to the specified channel with the access mode permitting
+	// the requested kind of access.
+	HasThing(context.Context, string, string, AccessMode) (string, error)
+
+	// Owner retrieves the owner of the channel having the provided
+	// identifier, regardless of the user that owns it. Removed channels are
+	// not considered.
+	Owner(context.Context, string) (string, error)
+
+	// Ping verifies that the underlying storage is reachable.
+	Ping(context.Context) error
 }
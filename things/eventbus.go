@@ -0,0 +1,78 @@
+package things
+
+import "sync"
+
+// eventBufferSize is the number of events buffered for each subscriber.
+const eventBufferSize = 16
+
+// EventBus is the in-process event stream, that delivers the published
+// connection events to the subscribers of their channels. Publishing never
+// blocks, so the events are dropped for the subscribers that don't keep up
+// with them.
+type EventBus interface {
+	EventStream
+
+	// Subscribe subscribes to the connection events of the channel having
+	// the provided identifier. The returned function cancels the
+	// subscription and closes the events channel.
+	Subscribe(string) (<-chan Event, func())
+}
+
+var _ EventBus = (*eventBus)(nil)
+
+type eventBus struct {
+	mu   sync.Mutex
+	subs map[string]map[chan Event]bool
+}
+
+// NewEventBus creates the in-process event bus without any subscribers.
+func NewEventBus() EventBus {
+	return &eventBus{
+		subs: make(map[string]map[chan Event]bool),
+	}
+}
+
+func (eb *eventBus) Publish(event Event) error {
+	if event.ChanID == "" {
+		return nil
+	}
+
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+
+	for sub := range eb.subs[event.ChanID] {
+		select {
+		case sub <- event:
+		default:
+		}
+	}
+
+	return nil
+}
+
+func (eb *eventBus) Subscribe(chanID string) (<-chan Event, func()) {
+	sub := make(chan Event, eventBufferSize)
+
+	eb.mu.Lock()
+	if eb.subs[chanID] == nil {
+		eb.subs[chanID] = make(map[chan Event]bool)
+	}
+	eb.subs[chanID][sub] = true
+	eb.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			eb.mu.Lock()
+			delete(eb.subs[chanID], sub)
+			if len(eb.subs[chanID]) == 0 {
+				delete(eb.subs, chanID)
+			}
+			eb.mu.Unlock()
+
+			close(sub)
+		})
+	}
+
+	return sub, cancel
+}
@@ -0,0 +1,262 @@
+package things
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mainflux/mainflux/logger"
+)
+
+const (
+	// EventThingCreate is published when the thing is added.
+	EventThingCreate = "thing.create"
+
+	// EventThingUpdate is published when the thing is updated.
+	EventThingUpdate = "thing.update"
+
+	// EventThingRemove is published when the thing is removed.
+	EventThingRemove = "thing.remove"
+
+	// EventConnect is published when the thing is connected to the channel.
+	EventConnect = "channel.connect"
+
+	// EventDisconnect is published when the thing is disconnected from the
+	// channel.
+	EventDisconnect = "channel.disconnect"
+)
+
+// Event represents a change of the thing's or channel's state. Connection
+// events are identified by the thing, and carry the channel's identifier as
+// well.
+type Event struct {
+	Type      string
+	EntityID  string
+	ChanID    string
+	Owner     string
+	Timestamp time.Time
+}
+
+// EventStream specifies an API for publishing the domain events (e.g. to
+// the Redis stream).
+type EventStream interface {
+	// Publish appends the provided event to the stream.
+	Publish(Event) error
+}
+
+var _ EventStream = (multiStream)(nil)
+
+type multiStream []EventStream
+
+// NewMultiStream creates the event stream publishing each event to all of
+// the provided streams. The event is published to the rest of the streams
+// even if some of them fail, and the first of the failures is reported.
+func NewMultiStream(streams ...EventStream) EventStream {
+	return multiStream(streams)
+}
+
+func (ms multiStream) Publish(event Event) error {
+	var failed error
+	for _, stream := range ms {
+		if err := stream.Publish(event); err != nil && failed == nil {
+			failed = err
+		}
+	}
+
+	return failed
+}
+
+var _ Service = (*eventStoreService)(nil)
+
+type eventStoreService struct {
+	Service
+	stream EventStream
+	logger logger.Logger
+}
+
+// NewEventStoreService decorates the provided service with publishing of
+// the domain events to the provided stream. Events are published only for
+// the changes actually made, and failed publishing is logged without failing
+// the operation itself.
+func NewEventStoreService(svc Service, stream EventStream, logger logger.Logger) Service {
+	return &eventStoreService{
+		Service: svc,
+		stream:  stream,
+		logger:  logger,
+	}
+}
+
+func (es *eventStoreService) AddThing(ctx context.Context, key string, thing Thing) (Thing, error) {
+	saved, err := es.Service.AddThing(ctx, key, thing)
+	if err != nil || saved.Existing {
+		return saved, err
+	}
+
+	es.publish(Event{
+		Type:     EventThingCreate,
+		EntityID: saved.ID,
+		Owner:    saved.Owner,
+	})
+
+	return saved, nil
+}
+
+func (es *eventStoreService) UpdateThing(ctx context.Context, key string, thing Thing) error {
+	ctx, rec := recordCall(ctx)
+	if err := es.Service.UpdateThing(ctx, key, thing); err != nil {
+		return err
+	}
+
+	es.publish(Event{
+		Type:     EventThingUpdate,
+		EntityID: thing.ID,
+		Owner:    rec.owner,
+	})
+
+	return nil
+}
+
+func (es *eventStoreService) RemoveThing(ctx context.Context, key, id string) error {
+	ctx, rec := recordCall(ctx)
+	err := es.Service.RemoveThing(ctx, key, id)
+	es.publishConnections(rec)
+	if err != nil {
+		return err
+	}
+
+	es.publish(Event{
+		Type:     EventThingRemove,
+		EntityID: id,
+		Owner:    rec.owner,
+	})
+
+	return nil
+}
+
+func (es *eventStoreService) RemoveAllThings(ctx context.Context, key string) error {
+	ctx, rec := recordCall(ctx)
+	err := es.Service.RemoveAllThings(ctx, key)
+	es.publishConnections(rec)
+	return err
+}
+
+func (es *eventStoreService) TransferThing(ctx context.Context, key, id, newOwner string) error {
+	ctx, rec := recordCall(ctx)
+	err := es.Service.TransferThing(ctx, key, id, newOwner)
+	es.publishConnections(rec)
+	return err
+}
+
+func (es *eventStoreService) RemoveChannel(ctx context.Context, key, id string) error {
+	ctx, rec := recordCall(ctx)
+	err := es.Service.RemoveChannel(ctx, key, id)
+	es.publishConnections(rec)
+	return err
+}
+
+func (es *eventStoreService) RestoreChannel(ctx context.Context, key, id string) error {
+	ctx, rec := recordCall(ctx)
+	err := es.Service.RestoreChannel(ctx, key, id)
+	es.publishConnections(rec)
+	return err
+}
+
+func (es *eventStoreService) RemoveAllChannels(ctx context.Context, key string) error {
+	ctx, rec := recordCall(ctx)
+	err := es.Service.RemoveAllChannels(ctx, key)
+	es.publishConnections(rec)
+	return err
+}
+
+func (es *eventStoreService) TransferChannel(ctx context.Context, key, id, newOwner string) error {
+	ctx, rec := recordCall(ctx)
+	err := es.Service.TransferChannel(ctx, key, id, newOwner)
+	es.publishConnections(rec)
+	return err
+}
+
+func (es *eventStoreService) Connect(ctx context.Context, key, chanID, thingID string, mode AccessMode) (Connection, error) {
+	ctx, rec := recordCall(ctx)
+	conn, err := es.Service.Connect(ctx, key, chanID, thingID, mode)
+	es.publishConnections(rec)
+	return conn, err
+}
+
+func (es *eventStoreService) ConnectMany(ctx context.Context, key, thingID string, chanIDs []string) error {
+	ctx, rec := recordCall(ctx)
+	err := es.Service.ConnectMany(ctx, key, thingID, chanIDs)
+	es.publishConnections(rec)
+	return err
+}
+
+func (es *eventStoreService) ConnectThings(ctx context.Context, key, chanID string, thingIDs []string) error {
+	ctx, rec := recordCall(ctx)
+	err := es.Service.ConnectThings(ctx, key, chanID, thingIDs)
+	es.publishConnections(rec)
+	return err
+}
+
+func (es *eventStoreService) SetChannelThings(ctx context.Context, key, chanID string, thingIDs []string) error {
+	ctx, rec := recordCall(ctx)
+	err := es.Service.SetChannelThings(ctx, key, chanID, thingIDs)
+	es.publishConnections(rec)
+	return err
+}
+
+func (es *eventStoreService) Disconnect(ctx context.Context, key, chanID, thingID string) (bool, error) {
+	ctx, rec := recordCall(ctx)
+	removed, err := es.Service.Disconnect(ctx, key, chanID, thingID)
+	es.publishConnections(rec)
+	return removed, err
+}
+
+func (es *eventStoreService) DisconnectMany(ctx context.Context, key, thingID string, chanIDs []string) error {
+	ctx, rec := recordCall(ctx)
+	err := es.Service.DisconnectMany(ctx, key, thingID, chanIDs)
+	es.publishConnections(rec)
+	return err
+}
+
+func (es *eventStoreService) DisconnectAll(ctx context.Context, key, thingID string) error {
+	ctx, rec := recordCall(ctx)
+	err := es.Service.DisconnectAll(ctx, key, thingID)
+	es.publishConnections(rec)
+	return err
+}
+
+func (es *eventStoreService) RebindConnections(ctx context.Context, key, fromID, toID string) error {
+	ctx, rec := recordCall(ctx)
+	err := es.Service.RebindConnections(ctx, key, fromID, toID)
+	es.publishConnections(rec)
+	return err
+}
+
+// publishConnections publishes an event for each of the connections made or
+// removed by the recorded call. These are published even if the call failed
+// afterwards, since the recorded changes have been made nevertheless.
+func (es *eventStoreService) publishConnections(rec *callRecorder) {
+	for _, conn := range rec.connected {
+		es.publish(Event{
+			Type:     EventConnect,
+			EntityID: conn.ThingID,
+			ChanID:   conn.ChanID,
+			Owner:    rec.owner,
+		})
+	}
+
+	for _, conn := range rec.disconnected {
+		es.publish(Event{
+			Type:     EventDisconnect,
+			EntityID: conn.ThingID,
+			ChanID:   conn.ChanID,
+			Owner:    rec.owner,
+		})
+	}
+}
+
+func (es *eventStoreService) publish(event Event) {
+	event.Timestamp = time.Now()
+	if err := es.stream.Publish(event); err != nil {
+		es.logger.Warn(fmt.Sprintf("Failed to publish %s event for %s: %s", event.Type, event.EntityID, err))
+	}
+}
@@ -0,0 +1,207 @@
+package things_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/things"
+	"github.com/mainflux/mainflux/things/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventStoreService(t *testing.T) {
+	stream := mocks.NewEventStream()
+	svc := things.NewEventStoreService(newService(map[string]string{token: email}), stream, logger.New(&bytes.Buffer{}))
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+
+	cases := []struct {
+		desc    string
+		operate func() error
+		event   things.Event
+	}{
+		{
+			desc: "update thing",
+			operate: func() error {
+				th := sth
+				th.Name = "updated"
+				return svc.UpdateThing(context.Background(), token, th)
+			},
+			event: things.Event{Type: things.EventThingUpdate, EntityID: sth.ID, Owner: email},
+		},
+		{
+			desc: "connect thing",
+			operate: func() error {
+				_, err := svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+				return err
+			},
+			event: things.Event{Type: things.EventConnect, EntityID: sth.ID, ChanID: sch.ID, Owner: email},
+		},
+		{
+			desc: "disconnect thing",
+			operate: func() error {
+				_, err := svc.Disconnect(context.Background(), token, sch.ID, sth.ID)
+				return err
+			},
+			event: things.Event{Type: things.EventDisconnect, EntityID: sth.ID, ChanID: sch.ID, Owner: email},
+		},
+		{
+			desc: "remove thing",
+			operate: func() error {
+				return svc.RemoveThing(context.Background(), token, sth.ID)
+			},
+			event: things.Event{Type: things.EventThingRemove, EntityID: sth.ID, Owner: email},
+		},
+	}
+
+	events := stream.Events()
+	assert.Len(t, events, 1, fmt.Sprintf("add thing: expected %d events got %d\n", 1, len(events)))
+	assert.Equal(t, things.EventThingCreate, events[0].Type, fmt.Sprintf("add thing: expected %s got %s\n", things.EventThingCreate, events[0].Type))
+	assert.Equal(t, sth.ID, events[0].EntityID, fmt.Sprintf("add thing: expected %s got %s\n", sth.ID, events[0].EntityID))
+	assert.Equal(t, email, events[0].Owner, fmt.Sprintf("add thing: expected %s got %s\n", email, events[0].Owner))
+
+	for i, tc := range cases {
+		err := tc.operate()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s\n", tc.desc, err))
+
+		events := stream.Events()
+		if !assert.Len(t, events, i+2, fmt.Sprintf("%s: expected %d events got %d\n", tc.desc, i+2, len(events))) {
+			continue
+		}
+
+		event := events[i+1]
+		assert.False(t, event.Timestamp.IsZero(), fmt.Sprintf("%s: expected event timestamp\n", tc.desc))
+		event.Timestamp = tc.event.Timestamp
+		assert.Equal(t, tc.event, event, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.event, event))
+	}
+}
+
+func TestEventStoreServiceFailedOperation(t *testing.T) {
+	stream := mocks.NewEventStream()
+	svc := things.NewEventStoreService(newService(map[string]string{token: email}), stream, logger.New(&bytes.Buffer{}))
+
+	svc.AddThing(context.Background(), token, thing)
+	svc.UpdateThing(context.Background(), token, things.Thing{ID: wrong, Type: "app"})
+	svc.RemoveThing(context.Background(), wrong, wrong)
+	svc.Connect(context.Background(), token, wrong, wrong, things.AccessPubSub)
+	svc.Disconnect(context.Background(), token, wrong, wrong)
+
+	events := stream.Events()
+	assert.Len(t, events, 1, fmt.Sprintf("failed operations: expected %d events got %d\n", 1, len(events)))
+}
+
+func TestEventStoreServiceNoopDisconnect(t *testing.T) {
+	stream := mocks.NewEventStream()
+	svc := things.NewEventStoreService(newService(map[string]string{token: email}), stream, logger.New(&bytes.Buffer{}))
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+
+	removed, err := svc.Disconnect(context.Background(), token, sch.ID, sth.ID)
+	assert.Nil(t, err, fmt.Sprintf("disconnect unconnected thing: unexpected error %s\n", err))
+	assert.False(t, removed, fmt.Sprintf("disconnect unconnected thing: expected nothing to be removed\n"))
+
+	events := stream.Events()
+	assert.Len(t, events, 1, fmt.Sprintf("disconnect unconnected thing: expected %d events got %d\n", 1, len(events)))
+}
+
+func TestEventStoreServiceBulkConnections(t *testing.T) {
+	stream := mocks.NewEventStream()
+	svc := things.NewEventStoreService(newService(map[string]string{token: email}), stream, logger.New(&bytes.Buffer{}))
+
+	th1, _ := svc.AddThing(context.Background(), token, thing)
+	th2, _ := svc.AddThing(context.Background(), token, thing)
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+
+	cases := []struct {
+		desc    string
+		operate func() error
+		events  []things.Event
+	}{
+		{
+			desc: "connect things",
+			operate: func() error {
+				return svc.ConnectThings(context.Background(), token, sch.ID, []string{th1.ID, th2.ID})
+			},
+			events: []things.Event{
+				{Type: things.EventConnect, EntityID: th1.ID, ChanID: sch.ID, Owner: email},
+				{Type: things.EventConnect, EntityID: th2.ID, ChanID: sch.ID, Owner: email},
+			},
+		},
+		{
+			desc: "connect already connected things",
+			operate: func() error {
+				return svc.ConnectThings(context.Background(), token, sch.ID, []string{th1.ID, th2.ID})
+			},
+			events: []things.Event{},
+		},
+		{
+			desc: "set channel things",
+			operate: func() error {
+				return svc.SetChannelThings(context.Background(), token, sch.ID, []string{th1.ID})
+			},
+			events: []things.Event{
+				{Type: things.EventDisconnect, EntityID: th2.ID, ChanID: sch.ID, Owner: email},
+			},
+		},
+		{
+			desc: "remove channel",
+			operate: func() error {
+				return svc.RemoveChannel(context.Background(), token, sch.ID)
+			},
+			events: []things.Event{
+				{Type: things.EventDisconnect, EntityID: th1.ID, ChanID: sch.ID, Owner: email},
+			},
+		},
+	}
+
+	published := len(stream.Events())
+	for _, tc := range cases {
+		err := tc.operate()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s\n", tc.desc, err))
+
+		events := stream.Events()[published:]
+		published += len(events)
+		for i := range events {
+			events[i].Timestamp = time.Time{}
+		}
+		assert.ElementsMatch(t, tc.events, events, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.events, events))
+	}
+}
+
+func TestEventStoreServiceFailedPublish(t *testing.T) {
+	var buf bytes.Buffer
+	stream := mocks.NewFailingEventStream(errors.New("stream unavailable"))
+	svc := things.NewEventStoreService(newService(map[string]string{token: email}), stream, logger.New(&buf))
+
+	sth, err := svc.AddThing(context.Background(), token, thing)
+	assert.Nil(t, err, fmt.Sprintf("add thing: unexpected error %s\n", err))
+
+	err = svc.RemoveThing(context.Background(), token, sth.ID)
+	assert.Nil(t, err, fmt.Sprintf("remove thing: unexpected error %s\n", err))
+
+	assert.True(t, strings.Contains(buf.String(), "stream unavailable"), fmt.Sprintf("expected logged publish error got %s\n", buf.String()))
+}
+
+func TestMultiStream(t *testing.T) {
+	first := mocks.NewEventStream()
+	second := mocks.NewEventStream()
+	failure := errors.New("stream unavailable")
+	stream := things.NewMultiStream(first, mocks.NewFailingEventStream(failure), second)
+
+	event := things.Event{Type: things.EventConnect, EntityID: "thing", ChanID: "channel", Owner: email}
+	err := stream.Publish(event)
+	assert.Equal(t, failure, err, fmt.Sprintf("publish event: expected %s got %s\n", failure, err))
+
+	for i, s := range []mocks.EventStream{first, second} {
+		events := s.Events()
+		assert.Equal(t, []things.Event{event}, events, fmt.Sprintf("stream %d: expected %v got %v\n", i, []things.Event{event}, events))
+	}
+}
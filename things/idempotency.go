@@ -0,0 +1,26 @@
+package things
+
+import "time"
+
+// IdempotencyStore specifies an API for keeping the responses to the requests
+// carrying the idempotency keys, so that their retries are answered with the
+// same responses instead of being processed again. The responses are opaque
+// to the store, as their encoding is up to the transport.
+type IdempotencyStore interface {
+	// Reserve claims the provided key for the provided TTL. ErrConflict is
+	// returned if the key is already claimed.
+	Reserve(string, time.Duration) error
+
+	// Save stores the response to the request claiming the provided key.
+	// The response is kept until the claim expires.
+	Save(string, []byte) error
+
+	// Response retrieves the response to the request claiming the provided
+	// key. ErrNotFound is returned if the key isn't claimed, or if the
+	// response isn't saved yet.
+	Response(string) ([]byte, error)
+
+	// Release drops the claim of the provided key, along with the saved
+	// response.
+	Release(string) error
+}
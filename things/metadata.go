@@ -0,0 +1,8 @@
+package things
+
+// MetadataFilter restricts the retrieved entities to the ones whose metadata
+// contain the specified key/value pair. Zero value matches all entities.
+type MetadataFilter struct {
+	Key   string
+	Value string
+}
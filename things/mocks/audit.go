@@ -0,0 +1,46 @@
+package mocks
+
+import (
+	"sync"
+
+	"github.com/mainflux/mainflux/things"
+)
+
+var _ things.AuditSink = (*auditSinkMock)(nil)
+
+// AuditSink is the in-memory audit sink that keeps all of the written
+// records.
+type AuditSink interface {
+	things.AuditSink
+
+	// Records retrieves all of the written records, in the order they were
+	// written.
+	Records() []things.AuditRecord
+}
+
+type auditSinkMock struct {
+	mu      sync.Mutex
+	records []things.AuditRecord
+}
+
+// NewAuditSink creates an empty in-memory audit sink.
+func NewAuditSink() AuditSink {
+	return &auditSinkMock{}
+}
+
+func (asm *auditSinkMock) Write(record things.AuditRecord) error {
+	asm.mu.Lock()
+	defer asm.mu.Unlock()
+
+	asm.records = append(asm.records, record)
+	return nil
+}
+
+func (asm *auditSinkMock) Records() []things.AuditRecord {
+	asm.mu.Lock()
+	defer asm.mu.Unlock()
+
+	records := make([]things.AuditRecord, len(asm.records))
+	copy(records, asm.records)
+	return records
+}
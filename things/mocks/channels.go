@@ -1,10 +1,12 @@
 package mocks
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/mainflux/mainflux/things"
 )
@@ -12,106 +14,422 @@ import (
 var _ things.ChannelRepository = (*channelRepositoryMock)(nil)
 
 type channelRepositoryMock struct {
-	mu       sync.Mutex
-	counter  int
-	channels map[string]things.Channel
-	things   things.ThingRepository
+	mu             sync.Mutex
+	channels       map[string]things.Channel
+	connectedAt    map[string]time.Time
+	disconnectedAt map[string]time.Time
+	things         things.ThingRepository
+
+	// modes holds the access modes of the connections made with other
+	// modes than the default one.
+	modes map[string]things.AccessMode
+
+	// ids holds the identifiers of each owner's channels in ascending
+	// order, so that the owner's channels are found without scanning the
+	// whole map, and pages are sliced directly out of them.
+	ids map[string][]string
+
+	// members holds the identifiers of the things connected to each
+	// channel, so that the connection is checked without scanning the
+	// channel's things.
+	members map[string]map[string]bool
 }
 
 // NewChannelRepository creates in-memory channel repository.
 func NewChannelRepository(repo things.ThingRepository) things.ChannelRepository {
-	return &channelRepositoryMock{
-		channels: make(map[string]things.Channel),
-		things:   repo,
+	crm := &channelRepositoryMock{
+		channels:       make(map[string]things.Channel),
+		connectedAt:    make(map[string]time.Time),
+		disconnectedAt: make(map[string]time.Time),
+		things:         repo,
+		modes:          make(map[string]things.AccessMode),
+		ids:            make(map[string][]string),
+		members:        make(map[string]map[string]bool),
+	}
+
+	if trm, ok := repo.(*thingRepositoryMock); ok {
+		trm.transferred = crm.forget
 	}
+
+	return crm
 }
 
-func (crm *channelRepositoryMock) Save(channel things.Channel) (string, error) {
+func (crm *channelRepositoryMock) Save(_ context.Context, channel things.Channel) (string, error) {
 	crm.mu.Lock()
 	defer crm.mu.Unlock()
 
-	crm.channels[key(channel.Owner, channel.ID)] = channel
+	crm.put(channel)
+	crm.index(channel.Owner, channel.ID)
 
 	return channel.ID, nil
 }
 
-func (crm *channelRepositoryMock) Update(channel things.Channel) error {
+func (crm *channelRepositoryMock) Update(_ context.Context, channel things.Channel) error {
 	crm.mu.Lock()
 	defer crm.mu.Unlock()
 
 	dbKey := key(channel.Owner, channel.ID)
 
-	if _, ok := crm.channels[dbKey]; !ok {
+	ch, ok := crm.channels[dbKey]
+	if !ok || ch.Deleted() {
 		return things.ErrNotFound
 	}
 
-	crm.channels[dbKey] = channel
+	// only the fields updated by the real repository are replaced
+	ch.Name = channel.Name
+	ch.Metadata = channel.Metadata
+	ch.Webhook = channel.Webhook
+	ch.UpdatedAt = channel.UpdatedAt
+	crm.channels[dbKey] = ch
+
 	return nil
 }
 
-func (crm *channelRepositoryMock) One(owner, id string) (things.Channel, error) {
-	if c, ok := crm.channels[key(owner, id)]; ok {
+func (crm *channelRepositoryMock) One(_ context.Context, owner, id string) (things.Channel, error) {
+	crm.mu.Lock()
+	defer crm.mu.Unlock()
+
+	if c, ok := crm.channels[key(owner, id)]; ok && !c.Deleted() {
 		return c, nil
 	}
 
 	return things.Channel{}, things.ErrNotFound
 }
 
-func (crm *channelRepositoryMock) All(owner string, offset, limit int) []things.Channel {
-	// This obscure way to examine map keys is enforced by the key structure
-	// itself (see mocks/commons.go).
-	prefix := fmt.Sprintf("%s-", owner)
+func (crm *channelRepositoryMock) ByName(_ context.Context, owner, name string) (things.Channel, error) {
+	crm.mu.Lock()
+	defer crm.mu.Unlock()
+
+	for _, c := range crm.channels {
+		if c.Owner == owner && c.Name == name && !c.Deleted() {
+			return c, nil
+		}
+	}
+
+	return things.Channel{}, things.ErrNotFound
+}
+
+func (crm *channelRepositoryMock) All(_ context.Context, owner string, offset, limit int, sorting things.Sorting, filter things.MetadataFilter) things.ChannelPage {
+	crm.mu.Lock()
+	defer crm.mu.Unlock()
+
+	// pages ordered by identifiers are sliced directly out of the index,
+	// while the other ones require examining all of the owner's channels
+	if filter.Key == "" && sorting.Order != things.OrderName {
+		ids := crm.ids[owner]
+		page := things.ChannelPage{
+			Channels: []things.Channel{},
+			Total:    len(ids),
+			Offset:   offset,
+			Limit:    limit,
+		}
+
+		start, end, ok := bounds(len(ids), offset, limit)
+		if !ok {
+			return page
+		}
+
+		for i := start; i < end; i++ {
+			id := ids[i]
+			if sorting.Dir == things.DirDesc {
+				id = ids[len(ids)-1-i]
+			}
+			page.Channels = append(page.Channels, crm.channels[key(owner, id)])
+		}
+
+		return page
+	}
+
+	channels := make([]things.Channel, 0)
+	for _, c := range crm.owned(owner) {
+		if matches(c.Metadata, filter) {
+			channels = append(channels, c)
+		}
+	}
+
+	return things.ChannelPage{
+		Channels: sortedChannels(channels, sorting, offset, limit),
+		Total:    len(channels),
+		Offset:   offset,
+		Limit:    limit,
+	}
+}
+
+func (crm *channelRepositoryMock) AllByThing(_ context.Context, owner, thingID string, offset, limit int) []things.Channel {
+	crm.mu.Lock()
+	defer crm.mu.Unlock()
+
 	channels := make([]things.Channel, 0)
+	for _, c := range crm.owned(owner) {
+		if connected(c, thingID) {
+			channels = append(channels, c)
+		}
+	}
+
+	return sortedChannels(channels, things.Sorting{}, offset, limit)
+}
 
-	if offset < 0 || limit <= 0 {
-		return channels
+func (crm *channelRepositoryMock) Things(ctx context.Context, owner, chanID string, offset, limit int, filter things.ConnectionFilter) []things.Thing {
+	channel, err := crm.One(ctx, owner, chanID)
+	if err != nil {
+		return []things.Thing{}
 	}
 
-	// Since IDs starts from 1, shift everything by one.
-	first := fmt.Sprintf("%s%012d", startID, offset+1)
-	last := fmt.Sprintf("%s%012d", startID, offset+limit+1)
+	ids := []string{}
+	if filter.State != things.StateDisconnected {
+		for _, t := range channel.Things {
+			ids = append(ids, t.ID)
+		}
+	}
 
-	for k, v := range crm.channels {
-		if strings.HasPrefix(k, prefix) && v.ID >= first && v.ID < last {
-			channels = append(channels, v)
+	if filter.State != things.StateConnected {
+		crm.mu.Lock()
+		prefix := fmt.Sprintf("%s-", key(owner, chanID))
+		for k, at := range crm.disconnectedAt {
+			id := strings.TrimPrefix(k, prefix)
+			if id != k && !at.Before(filter.DisconnectedSince) && !connected(channel, id) {
+				ids = append(ids, id)
+			}
 		}
+		crm.mu.Unlock()
 	}
 
-	sort.SliceStable(channels, func(i, j int) bool {
-		return channels[i].ID < channels[j].ID
-	})
+	items := make([]things.Thing, 0)
+	for _, id := range ids {
+		// things are looked up again, so that removed things are left out
+		if thing, err := crm.things.One(ctx, owner, id); err == nil {
+			items = append(items, thing)
+		}
+	}
 
-	return channels
+	return sortedSubset(items, things.Sorting{}, offset, limit)
+}
+
+func (crm *channelRepositoryMock) UnconnectedThings(ctx context.Context, owner string, offset, limit int) []things.Thing {
+	all := crm.things.After(ctx, owner, "", crm.things.Count(ctx, owner, things.ThingFilter{}))
+
+	crm.mu.Lock()
+	linked := map[string]bool{}
+	for _, c := range crm.owned(owner) {
+		for id := range crm.members[c.ID] {
+			linked[id] = true
+		}
+	}
+	crm.mu.Unlock()
+
+	items := make([]things.Thing, 0)
+	for _, th := range all {
+		if !linked[th.ID] {
+			items = append(items, th)
+		}
+	}
+
+	return sortedSubset(items, things.Sorting{}, offset, limit)
+}
+
+func (crm *channelRepositoryMock) Count(_ context.Context, owner string) int {
+	crm.mu.Lock()
+	defer crm.mu.Unlock()
+
+	return len(crm.ids[owner])
+}
+
+func (crm *channelRepositoryMock) CountThings(ctx context.Context, owner, chanID string) (int, error) {
+	channel, err := crm.One(ctx, owner, chanID)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(channel.Things), nil
+}
+
+func (crm *channelRepositoryMock) ChangeOwner(_ context.Context, owner, id, newOwner string) error {
+	crm.mu.Lock()
+	defer crm.mu.Unlock()
+
+	dbKey := key(owner, id)
+
+	channel, ok := crm.channels[dbKey]
+	if !ok || channel.Deleted() {
+		return things.ErrNotFound
+	}
+
+	if _, ok := crm.channels[key(newOwner, id)]; ok {
+		return things.ErrConflict
+	}
+
+	delete(crm.channels, dbKey)
+	crm.unindex(owner, id)
+	channel.Owner = newOwner
+	channel.Things = []things.Thing{}
+	crm.put(channel)
+	crm.index(newOwner, id)
+
+	return nil
+}
+
+func (crm *channelRepositoryMock) Remove(_ context.Context, owner, id string) error {
+	crm.mu.Lock()
+	defer crm.mu.Unlock()
+
+	// removed channel keeps its connections, but it's left out of the
+	// owner's identifiers, so that it's not listed
+	channel, ok := crm.channels[key(owner, id)]
+	if !ok || channel.Deleted() {
+		return nil
+	}
+
+	channel.DeletedAt = time.Now().UTC()
+	crm.put(channel)
+	crm.unindex(owner, id)
+	return nil
+}
+
+func (crm *channelRepositoryMock) Restore(_ context.Context, owner, id string) error {
+	crm.mu.Lock()
+	defer crm.mu.Unlock()
+
+	channel, ok := crm.channels[key(owner, id)]
+	if !ok || !channel.Deleted() {
+		return things.ErrNotFound
+	}
+
+	channel.DeletedAt = time.Time{}
+	crm.put(channel)
+	crm.index(owner, id)
+	return nil
+}
+
+func (crm *channelRepositoryMock) Purge(_ context.Context, olderThan time.Time) (int, error) {
+	crm.mu.Lock()
+	defer crm.mu.Unlock()
+
+	purged := 0
+	for k, channel := range crm.channels {
+		if !channel.Deleted() || !channel.DeletedAt.Before(olderThan) {
+			continue
+		}
+
+		delete(crm.channels, k)
+		delete(crm.members, channel.ID)
+		purged++
+	}
+
+	return purged, nil
 }
 
-func (crm *channelRepositoryMock) Remove(owner, id string) error {
-	delete(crm.channels, key(owner, id))
+func (crm *channelRepositoryMock) RemoveAll(_ context.Context, owner string) error {
+	crm.mu.Lock()
+	defer crm.mu.Unlock()
+
+	// the channels removed before keep the time they were removed
+	now := time.Now().UTC()
+	for _, c := range append(crm.owned(owner), crm.removed(owner)...) {
+		c.Things = []things.Thing{}
+		if !c.Deleted() {
+			c.DeletedAt = now
+		}
+		crm.put(c)
+	}
+	delete(crm.ids, owner)
+
 	return nil
 }
 
-func (crm *channelRepositoryMock) Connect(owner, chanID, thingID string) error {
-	channel, err := crm.One(owner, chanID)
+func (crm *channelRepositoryMock) Connect(ctx context.Context, owner, chanID, thingID string, mode things.AccessMode) (things.Connection, error) {
+	channel, err := crm.One(ctx, owner, chanID)
 	if err != nil {
-		return err
+		return things.Connection{}, err
 	}
 
-	thing, err := crm.things.One(owner, thingID)
+	thing, err := crm.things.One(ctx, owner, thingID)
+	if err != nil {
+		return things.Connection{}, err
+	}
+
+	crm.mu.Lock()
+	defer crm.mu.Unlock()
+
+	connKey := key(key(owner, chanID), thingID)
+	if !connected(channel, thingID) {
+		channel.Things = append(channel.Things, thing)
+		crm.put(channel)
+		crm.connectedAt[connKey] = time.Now().UTC()
+	}
+	crm.modes[connKey] = mode
+
+	return things.Connection{ChanID: chanID, ThingID: thingID, Mode: mode, ConnectedAt: crm.connectedAt[connKey]}, nil
+}
+
+func (crm *channelRepositoryMock) ConnectMany(ctx context.Context, owner, thingID string, chanIDs []string) error {
+	thing, err := crm.things.One(ctx, owner, thingID)
 	if err != nil {
 		return err
 	}
-	channel.Things = append(channel.Things, thing)
-	return crm.Update(channel)
+
+	// all channels are validated before any of them is modified
+	channels := make([]things.Channel, 0, len(chanIDs))
+	for _, id := range chanIDs {
+		channel, err := crm.One(ctx, owner, id)
+		if err != nil {
+			return err
+		}
+		channels = append(channels, channel)
+	}
+
+	for _, channel := range channels {
+		if !connected(channel, thingID) {
+			channel.Things = append(channel.Things, thing)
+			crm.store(channel)
+			crm.stamp(owner, channel.ID, thingID)
+		}
+	}
+
+	return nil
 }
 
-func (crm *channelRepositoryMock) Disconnect(owner, chanID, thingID string) error {
-	channel, err := crm.One(owner, chanID)
+func (crm *channelRepositoryMock) ConnectThings(ctx context.Context, owner, chanID string, thingIDs []string) error {
+	channel, err := crm.One(ctx, owner, chanID)
 	if err != nil {
 		return err
 	}
 
+	// all things are validated before the channel is modified
+	ths := make([]things.Thing, 0, len(thingIDs))
+	for _, id := range thingIDs {
+		thing, err := crm.things.One(ctx, owner, id)
+		if err != nil {
+			return err
+		}
+		ths = append(ths, thing)
+	}
+
+	linked := make(map[string]bool, len(channel.Things)+len(ths))
+	for _, thing := range channel.Things {
+		linked[thing.ID] = true
+	}
+
+	for _, thing := range ths {
+		if !linked[thing.ID] {
+			linked[thing.ID] = true
+			channel.Things = append(channel.Things, thing)
+			crm.stamp(owner, chanID, thing.ID)
+		}
+	}
+	crm.store(channel)
+
+	return nil
+}
+
+func (crm *channelRepositoryMock) Disconnect(ctx context.Context, owner, chanID, thingID string) (bool, error) {
+	channel, err := crm.One(ctx, owner, chanID)
+	if err != nil {
+		return false, err
+	}
+
 	for _, t := range channel.Things {
 		if t.ID == thingID {
-			connected := make([]things.Thing, len(channel.Things)-1)
+			connected := make([]things.Thing, 0, len(channel.Things)-1)
 			for _, thing := range channel.Things {
 				if thing.ID != thingID {
 					connected = append(connected, thing)
@@ -119,28 +437,334 @@ func (crm *channelRepositoryMock) Disconnect(owner, chanID, thingID string) erro
 			}
 
 			channel.Things = connected
-			return crm.Update(channel)
+			crm.store(channel)
+
+			crm.mu.Lock()
+			crm.disconnectedAt[key(key(owner, chanID), thingID)] = time.Now().UTC()
+			crm.mu.Unlock()
+
+			return true, nil
 		}
 	}
 
-	return things.ErrNotFound
+	return false, nil
 }
 
-func (crm *channelRepositoryMock) HasThing(chanID, key string) (string, error) {
-	// This obscure way to examine map keys is enforced by the key structure
-	// itself (see mocks/commons.go).
-	suffix := fmt.Sprintf("-%s", chanID)
+func (crm *channelRepositoryMock) DisconnectAll(_ context.Context, owner, thingID string) error {
+	crm.mu.Lock()
+	defer crm.mu.Unlock()
+
+	for _, v := range append(crm.owned(owner), crm.removed(owner)...) {
+		if !connected(v, thingID) {
+			continue
+		}
 
-	for k, v := range crm.channels {
-		if strings.HasSuffix(k, suffix) {
-			for _, t := range v.Things {
-				if t.Key == key {
-					return t.ID, nil
-				}
+		remaining := make([]things.Thing, 0, len(v.Things)-1)
+		for _, thing := range v.Things {
+			if thing.ID != thingID {
+				remaining = append(remaining, thing)
+			}
+		}
+
+		k := key(owner, v.ID)
+		v.Things = remaining
+		crm.put(v)
+		crm.disconnectedAt[key(k, thingID)] = time.Now().UTC()
+	}
+
+	return nil
+}
+
+func (crm *channelRepositoryMock) Rebind(ctx context.Context, owner, fromID, toID string) error {
+	to, err := crm.things.One(ctx, owner, toID)
+	if err != nil {
+		return err
+	}
+
+	crm.mu.Lock()
+	defer crm.mu.Unlock()
+
+	now := time.Now().UTC()
+	for _, v := range append(crm.owned(owner), crm.removed(owner)...) {
+		if !connected(v, fromID) {
+			continue
+		}
+
+		remaining := make([]things.Thing, 0, len(v.Things))
+		for _, thing := range v.Things {
+			if thing.ID != fromID {
+				remaining = append(remaining, thing)
 			}
-			break
+		}
+
+		k := key(owner, v.ID)
+		if !connected(v, toID) {
+			remaining = append(remaining, to)
+			crm.connectedAt[key(k, toID)] = now
+			crm.modes[key(k, toID)] = crm.mode(key(k, fromID))
+		}
+		v.Things = remaining
+		crm.put(v)
+		crm.disconnectedAt[key(k, fromID)] = now
+	}
+
+	return nil
+}
+
+func (crm *channelRepositoryMock) DisconnectAllThings(_ context.Context, owner string) error {
+	crm.mu.Lock()
+	defer crm.mu.Unlock()
+
+	// things can be connected only to the channels of their owner
+	for _, v := range append(crm.owned(owner), crm.removed(owner)...) {
+		v.Things = []things.Thing{}
+		crm.put(v)
+	}
+
+	return nil
+}
+
+func (crm *channelRepositoryMock) HasConnection(ctx context.Context, owner, chanID, thingID string) bool {
+	channel, err := crm.One(ctx, owner, chanID)
+	return err == nil && connected(channel, thingID)
+}
+
+func (crm *channelRepositoryMock) Connections(_ context.Context, owner, thingID string) ([]string, error) {
+	crm.mu.Lock()
+	defer crm.mu.Unlock()
+
+	ids := []string{}
+	for _, v := range crm.owned(owner) {
+		if connected(v, thingID) {
+			ids = append(ids, v.ID)
+		}
+	}
+
+	return ids, nil
+}
+
+func (crm *channelRepositoryMock) ChannelConnections(_ context.Context, owner, chanID string) ([]things.Connection, error) {
+	crm.mu.Lock()
+	defer crm.mu.Unlock()
+
+	conns := []things.Connection{}
+	channel, ok := crm.channels[key(owner, chanID)]
+	if !ok {
+		return conns, nil
+	}
+
+	for _, t := range channel.Things {
+		conns = append(conns, things.Connection{
+			ChanID:      chanID,
+			ThingID:     t.ID,
+			Mode:        crm.mode(key(key(owner, chanID), t.ID)),
+			ConnectedAt: crm.connectedAt[key(key(owner, chanID), t.ID)],
+		})
+	}
+
+	sort.SliceStable(conns, func(i, j int) bool {
+		if !conns[i].ConnectedAt.Equal(conns[j].ConnectedAt) {
+			return conns[i].ConnectedAt.Before(conns[j].ConnectedAt)
+		}
+		return conns[i].ThingID < conns[j].ThingID
+	})
+
+	return conns, nil
+}
+
+func (crm *channelRepositoryMock) HasThing(ctx context.Context, chanID, thingKey string, mode things.AccessMode) (string, error) {
+	// the thing is looked up by its latest key, and then checked against
+	// the channel's members, so that neither of them is scanned
+	thing, err := crm.things.ByKey(ctx, thingKey)
+	if err != nil || thing.Status == things.StatusDisabled {
+		return "", things.ErrNotFound
+	}
+
+	crm.mu.Lock()
+	defer crm.mu.Unlock()
+
+	if !crm.members[chanID][thing.ID] || !crm.mode(key(key(thing.Owner, chanID), thing.ID)).Permits(mode) {
+		return "", things.ErrNotFound
+	}
+
+	return thing.ID, nil
+}
+
+func (crm *channelRepositoryMock) Owner(_ context.Context, chanID string) (string, error) {
+	crm.mu.Lock()
+	defer crm.mu.Unlock()
+
+	for _, v := range crm.channels {
+		if v.ID == chanID && !v.Deleted() {
+			return v.Owner, nil
 		}
 	}
 
 	return "", things.ErrNotFound
 }
+
+func (crm *channelRepositoryMock) Ping(context.Context) error {
+	return nil
+}
+
+// sortedChannels sorts provided channels as specified and returns the
+// requested subset of them.
+func sortedChannels(channels []things.Channel, sorting things.Sorting, offset, limit int) []things.Channel {
+	sort.SliceStable(channels, func(i, j int) bool {
+		return less(sorting, channels[i].ID, channels[i].Name, channels[j].ID, channels[j].Name)
+	})
+
+	start, end, ok := bounds(len(channels), offset, limit)
+	if !ok {
+		return []things.Channel{}
+	}
+
+	return channels[start:end]
+}
+
+// index adds the channel's identifier to the owner's identifiers, keeping
+// them in ascending order.
+func (crm *channelRepositoryMock) index(owner, id string) {
+	ids := crm.ids[owner]
+
+	i := sort.SearchStrings(ids, id)
+	if i < len(ids) && ids[i] == id {
+		return
+	}
+
+	ids = append(ids, "")
+	copy(ids[i+1:], ids[i:])
+	ids[i] = id
+	crm.ids[owner] = ids
+}
+
+// unindex removes the channel's identifier from the owner's identifiers.
+func (crm *channelRepositoryMock) unindex(owner, id string) {
+	ids := crm.ids[owner]
+
+	i := sort.SearchStrings(ids, id)
+	if i == len(ids) || ids[i] != id {
+		return
+	}
+
+	crm.ids[owner] = append(ids[:i], ids[i+1:]...)
+}
+
+// owned returns the channels of the provided owner, ordered by their
+// identifiers.
+func (crm *channelRepositoryMock) owned(owner string) []things.Channel {
+	ids := crm.ids[owner]
+
+	channels := make([]things.Channel, 0, len(ids))
+	for _, id := range ids {
+		channels = append(channels, crm.channels[key(owner, id)])
+	}
+
+	return channels
+}
+
+// removed returns the removed channels of the provided owner, which are left
+// out of the owner's identifiers.
+func (crm *channelRepositoryMock) removed(owner string) []things.Channel {
+	channels := []things.Channel{}
+	for _, c := range crm.channels {
+		if c.Owner == owner && c.Deleted() {
+			channels = append(channels, c)
+		}
+	}
+
+	return channels
+}
+
+// forget removes all of the connections and disconnections of the thing
+// transferred by its former owner.
+func (crm *channelRepositoryMock) forget(owner, thingID string) {
+	crm.mu.Lock()
+	defer crm.mu.Unlock()
+
+	for _, v := range append(crm.owned(owner), crm.removed(owner)...) {
+		if connected(v, thingID) {
+			remaining := make([]things.Thing, 0, len(v.Things)-1)
+			for _, thing := range v.Things {
+				if thing.ID != thingID {
+					remaining = append(remaining, thing)
+				}
+			}
+			v.Things = remaining
+			crm.put(v)
+		}
+
+		connKey := key(key(owner, v.ID), thingID)
+		delete(crm.connectedAt, connKey)
+		delete(crm.disconnectedAt, connKey)
+		delete(crm.modes, connKey)
+	}
+}
+
+func (crm *channelRepositoryMock) store(channel things.Channel) {
+	crm.mu.Lock()
+	defer crm.mu.Unlock()
+
+	crm.put(channel)
+}
+
+// put stores the channel and replaces its members with the identifiers of
+// its connected things. Removed channel has no members, since it can't be
+// accessed. The caller must hold the lock.
+func (crm *channelRepositoryMock) put(channel things.Channel) {
+	crm.channels[key(channel.Owner, channel.ID)] = channel
+	if channel.Deleted() {
+		delete(crm.members, channel.ID)
+		return
+	}
+
+	members := make(map[string]bool, len(channel.Things))
+	for _, thing := range channel.Things {
+		members[thing.ID] = true
+	}
+	crm.members[channel.ID] = members
+}
+
+// stamp records the time the thing was connected to the channel with the
+// default access mode.
+func (crm *channelRepositoryMock) stamp(owner, chanID, thingID string) {
+	crm.mu.Lock()
+	defer crm.mu.Unlock()
+
+	connKey := key(key(owner, chanID), thingID)
+	crm.connectedAt[connKey] = time.Now().UTC()
+	delete(crm.modes, connKey)
+}
+
+// mode returns the access mode of the connection having the provided key.
+func (crm *channelRepositoryMock) mode(connKey string) things.AccessMode {
+	if mode, ok := crm.modes[connKey]; ok {
+		return mode
+	}
+
+	return things.AccessPubSub
+}
+
+func connected(channel things.Channel, thingID string) bool {
+	for _, t := range channel.Things {
+		if t.ID == thingID {
+			return true
+		}
+	}
+
+	return false
+}
+
+type unavailableChannelsMock struct {
+	things.ChannelRepository
+}
+
+// NewUnavailableChannelRepository wraps the provided repository into the one
+// whose storage cannot be reached, so that only its Ping fails.
+func NewUnavailableChannelRepository(repo things.ChannelRepository) things.ChannelRepository {
+	return unavailableChannelsMock{repo}
+}
+
+func (crm unavailableChannelsMock) Ping(context.Context) error {
+	return errUnavailable
+}
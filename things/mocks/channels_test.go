@@ -0,0 +1,59 @@
+package mocks_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/mainflux/mainflux/things"
+	"github.com/mainflux/mainflux/things/mocks"
+)
+
+const (
+	owner    = "user@example.com"
+	channels = 10000
+	limit    = 10
+)
+
+func BenchmarkChannelRepositoryAll(b *testing.B) {
+	repo := mocks.NewChannelRepository(mocks.NewThingRepository())
+	idp := mocks.NewIdentityProvider()
+
+	for i := 0; i < channels; i++ {
+		repo.Save(context.Background(), things.Channel{ID: idp.ID(), Owner: owner, Name: "test"})
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		offset := (i * limit) % channels
+		repo.All(context.Background(), owner, offset, limit, things.Sorting{}, things.MetadataFilter{})
+	}
+}
+
+func BenchmarkChannelRepositoryHasThing(b *testing.B) {
+	for _, n := range []int{500, 5000, 50000} {
+		b.Run(fmt.Sprintf("%d things", n), func(b *testing.B) {
+			thingsRepo := mocks.NewThingRepository()
+			repo := mocks.NewChannelRepository(thingsRepo)
+			idp := mocks.NewIdentityProvider()
+
+			chanID := idp.ID()
+			repo.Save(context.Background(), things.Channel{ID: chanID, Owner: owner, Name: "test"})
+
+			ids := make([]string, n)
+			keys := make([]string, n)
+			for i := range ids {
+				ids[i], keys[i] = idp.ID(), idp.ID()
+				thingsRepo.Save(context.Background(), things.Thing{ID: ids[i], Key: keys[i], Owner: owner})
+			}
+			repo.ConnectThings(context.Background(), owner, chanID, ids)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := repo.HasThing(context.Background(), chanID, keys[i%n], things.AccessPubSub); err != nil {
+					b.Fatalf("unexpected error: %s", err)
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,13 @@
 package mocks
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mainflux/mainflux/things"
+)
+
+var errUnavailable = errors.New("storage is unreachable")
 
 // Since mocks will store data in map, and they need to resemble the real
 // identifiers as much as possible, a key will be created as combination of
@@ -9,3 +16,47 @@ import "fmt"
 func key(owner, id string) string {
 	return fmt.Sprintf("%s-%s", owner, id)
 }
+
+// less compares two entities, given their identifiers and names, as specified
+// by the provided sorting. Since identifiers are generated sequentially by
+// the identity provider mock, sorting by creation time is the same as sorting
+// by identifiers.
+func less(sorting things.Sorting, id1, name1, id2, name2 string) bool {
+	if sorting.Order == things.OrderName && name1 != name2 {
+		if sorting.Dir == things.DirDesc {
+			return name1 > name2
+		}
+		return name1 < name2
+	}
+
+	if sorting.Dir == things.DirDesc {
+		return id1 > id2
+	}
+	return id1 < id2
+}
+
+// bounds returns the boundaries of the requested subset of n items. False is
+// returned if the subset is empty.
+func bounds(n, offset, limit int) (int, int, bool) {
+	if offset < 0 || limit <= 0 || offset >= n {
+		return 0, 0, false
+	}
+
+	end := offset + limit
+	if end > n {
+		end = n
+	}
+
+	return offset, end, true
+}
+
+// matches determines whether the provided metadata satisfy the filter. The
+// metadata values are compared using their string representation.
+func matches(metadata map[string]interface{}, filter things.MetadataFilter) bool {
+	if filter.Key == "" {
+		return true
+	}
+
+	val, ok := metadata[filter.Key]
+	return ok && fmt.Sprint(val) == filter.Value
+}
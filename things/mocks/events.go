@@ -0,0 +1,63 @@
+package mocks
+
+import (
+	"sync"
+
+	"github.com/mainflux/mainflux/things"
+)
+
+var (
+	_ things.EventStream = (*eventStreamMock)(nil)
+	_ things.EventStream = (*failingEventStreamMock)(nil)
+)
+
+// EventStream is the in-memory event stream that records all of the
+// published events.
+type EventStream interface {
+	things.EventStream
+
+	// Events retrieves all of the published events, in the order they
+	// were published.
+	Events() []things.Event
+}
+
+type eventStreamMock struct {
+	mu     sync.Mutex
+	events []things.Event
+}
+
+// NewEventStream creates an empty in-memory event stream.
+func NewEventStream() EventStream {
+	return &eventStreamMock{}
+}
+
+func (esm *eventStreamMock) Publish(event things.Event) error {
+	esm.mu.Lock()
+	defer esm.mu.Unlock()
+
+	esm.events = append(esm.events, event)
+	return nil
+}
+
+func (esm *eventStreamMock) Events() []things.Event {
+	esm.mu.Lock()
+	defer esm.mu.Unlock()
+
+	events := make([]things.Event, len(esm.events))
+	copy(events, esm.events)
+	return events
+}
+
+type failingEventStreamMock struct {
+	err error
+}
+
+// NewFailingEventStream creates mock of event stream that rejects all of the
+// events with the provided error.
+func NewFailingEventStream(err error) things.EventStream {
+	return failingEventStreamMock{err: err}
+}
+
+func (esm failingEventStreamMock) Publish(things.Event) error {
+	return esm.err
+}
@@ -1,10 +1,12 @@
 package mocks
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/mainflux/mainflux/things"
 )
@@ -12,85 +14,526 @@ import (
 var _ things.ThingRepository = (*thingRepositoryMock)(nil)
 
 type thingRepositoryMock struct {
-	mu     sync.Mutex
-	things map[string]things.Thing
+	mu        sync.Mutex
+	things    map[string]things.Thing
+	externals map[string]string
+
+	// keys maps the things' keys to their storage keys, so that the thing
+	// is found by its key without scanning the whole map.
+	keys map[string]string
+
+	// transferred is called with the former owner and the identifier of
+	// each transferred thing, so that the channel repository drops its
+	// connections, as done within the real repository's transaction.
+	transferred func(string, string)
 }
 
 // NewThingRepository creates in-memory thing repository.
 func NewThingRepository() things.ThingRepository {
 	return &thingRepositoryMock{
-		things: make(map[string]things.Thing),
+		things:    make(map[string]things.Thing),
+		externals: make(map[string]string),
+		keys:      make(map[string]string),
 	}
 }
 
-func (trm *thingRepositoryMock) Save(thing things.Thing) (string, error) {
+func (trm *thingRepositoryMock) Save(_ context.Context, thing things.Thing) (string, error) {
 	trm.mu.Lock()
 	defer trm.mu.Unlock()
 
-	trm.things[key(thing.Owner, thing.ID)] = thing
+	if thing.ExternalID != "" && trm.takenExternalID(thing.Owner, thing.ExternalID) {
+		return "", things.ErrConflict
+	}
+
+	trm.save(thing)
 
 	return thing.ID, nil
 }
 
-func (trm *thingRepositoryMock) Update(thing things.Thing) error {
+func (trm *thingRepositoryMock) SaveBulk(_ context.Context, ths []things.Thing) ([]string, error) {
+	trm.mu.Lock()
+	defer trm.mu.Unlock()
+
+	// the whole batch is rejected, as done by the real repository
+	extIDs := make(map[string]bool, len(ths))
+	for _, thing := range ths {
+		if thing.ExternalID == "" {
+			continue
+		}
+
+		k := key(thing.Owner, thing.ExternalID)
+		if extIDs[k] || trm.takenExternalID(thing.Owner, thing.ExternalID) {
+			return nil, things.ErrConflict
+		}
+		extIDs[k] = true
+	}
+
+	ids := make([]string, 0, len(ths))
+	for _, thing := range ths {
+		trm.save(thing)
+		ids = append(ids, thing.ID)
+	}
+
+	return ids, nil
+}
+
+func (trm *thingRepositoryMock) Update(_ context.Context, thing things.Thing) error {
 	trm.mu.Lock()
 	defer trm.mu.Unlock()
 
 	dbKey := key(thing.Owner, thing.ID)
 
-	if _, ok := trm.things[dbKey]; !ok {
+	th, ok := trm.things[dbKey]
+	if !ok || th.Deleted() {
 		return things.ErrNotFound
 	}
 
+	if thing.Version != 0 && thing.Version != th.Version {
+		return things.ErrVersionMismatch
+	}
+
+	// only the fields updated by the real repository are replaced
+	th.Name = thing.Name
+	th.Payload = thing.Payload
+	th.Metadata = thing.Metadata
+	th.Tags = thing.Tags
+	th.UpdatedAt = thing.UpdatedAt
+	th.Version++
+	trm.things[dbKey] = th
+
+	return nil
+}
+
+func (trm *thingRepositoryMock) UpdateKey(_ context.Context, owner, id, val string) error {
+	trm.mu.Lock()
+	defer trm.mu.Unlock()
+
+	dbKey := key(owner, id)
+
+	thing, ok := trm.things[dbKey]
+	if !ok || thing.Deleted() {
+		return things.ErrNotFound
+	}
+
+	if k, ok := trm.keys[val]; ok && k != dbKey {
+		return things.ErrConflict
+	}
+
+	delete(trm.keys, thing.Key)
+	thing.Key = val
+	thing.Version++
 	trm.things[dbKey] = thing
+	trm.keys[val] = dbKey
 
 	return nil
 }
 
-func (trm *thingRepositoryMock) One(owner, id string) (things.Thing, error) {
-	if c, ok := trm.things[key(owner, id)]; ok {
+func (trm *thingRepositoryMock) UpdateStatus(_ context.Context, owner, id, status string) error {
+	trm.mu.Lock()
+	defer trm.mu.Unlock()
+
+	dbKey := key(owner, id)
+
+	thing, ok := trm.things[dbKey]
+	if !ok || thing.Deleted() {
+		return things.ErrNotFound
+	}
+
+	thing.Status = status
+	thing.Version++
+	trm.things[dbKey] = thing
+
+	return nil
+}
+
+func (trm *thingRepositoryMock) One(_ context.Context, owner, id string) (things.Thing, error) {
+	if c, ok := trm.things[key(owner, id)]; ok && !c.Deleted() {
 		return c, nil
 	}
 
 	return things.Thing{}, things.ErrNotFound
 }
 
-func (trm *thingRepositoryMock) All(owner string, offset, limit int) []things.Thing {
-	// This obscure way to examine map keys is enforced by the key structure
-	// itself (see mocks/commons.go).
+func (trm *thingRepositoryMock) Owner(_ context.Context, id string) (string, error) {
+	trm.mu.Lock()
+	defer trm.mu.Unlock()
+
+	for _, v := range trm.things {
+		if v.ID == id && !v.Deleted() {
+			return v.Owner, nil
+		}
+	}
+
+	return "", things.ErrNotFound
+}
+
+func (trm *thingRepositoryMock) ByKey(_ context.Context, key string) (things.Thing, error) {
+	trm.mu.Lock()
+	defer trm.mu.Unlock()
+
+	thing, ok := trm.things[trm.keys[key]]
+	if !ok || thing.Key != key || thing.Deleted() {
+		return things.Thing{}, things.ErrNotFound
+	}
+
+	return thing, nil
+}
+
+func (trm *thingRepositoryMock) ByExternalID(_ context.Context, owner, extID string) (things.Thing, error) {
+	trm.mu.Lock()
+	defer trm.mu.Unlock()
+
+	id, ok := trm.externals[key(owner, extID)]
+	if !ok {
+		return things.Thing{}, things.ErrNotFound
+	}
+
+	if t, ok := trm.things[key(owner, id)]; ok && !t.Deleted() {
+		return t, nil
+	}
+
+	return things.Thing{}, things.ErrNotFound
+}
+
+func (trm *thingRepositoryMock) All(_ context.Context, owner string, offset, limit int, sorting things.Sorting, filter things.ThingFilter) things.ThingPage {
+	return trm.page(owner, false, offset, limit, sorting, filter)
+}
+
+func (trm *thingRepositoryMock) AllDeleted(_ context.Context, owner string, offset, limit int, sorting things.Sorting) things.ThingPage {
+	return trm.page(owner, true, offset, limit, sorting, things.ThingFilter{})
+}
+
+func (trm *thingRepositoryMock) Multi(_ context.Context, owner string, ids []string) []things.Thing {
+	trm.mu.Lock()
+	defer trm.mu.Unlock()
+
+	items := make([]things.Thing, 0, len(ids))
+	for _, id := range ids {
+		if v, ok := trm.things[key(owner, id)]; ok && !v.Deleted() {
+			items = append(items, v)
+		}
+	}
+
+	return items
+}
+
+func (trm *thingRepositoryMock) After(_ context.Context, owner, afterID string, limit int) []things.Thing {
+	trm.mu.Lock()
+	defer trm.mu.Unlock()
+
 	prefix := fmt.Sprintf("%s-", owner)
-	things := make([]things.Thing, 0)
 
-	if offset < 0 || limit <= 0 {
-		return things
+	items := make([]things.Thing, 0)
+	for k, v := range trm.things {
+		if strings.HasPrefix(k, prefix) && !v.Deleted() && v.ID > afterID {
+			items = append(items, v)
+		}
 	}
 
-	// Since both ID and key are generated via the identity provider mock, all
-	// identifiers will be at "odd" positions. The following loop skips all
-	// values used for keys. Starting value of 1 indicates the first usable
-	// UUID produced by mocked identity provider.
-	skip := 1
-	for i := 0; i < offset; i++ {
-		skip += 2
+	return sortedSubset(items, things.Sorting{}, 0, limit)
+}
+
+func (trm *thingRepositoryMock) Count(_ context.Context, owner string, filter things.ThingFilter) int {
+	trm.mu.Lock()
+	defer trm.mu.Unlock()
+
+	prefix := fmt.Sprintf("%s-", owner)
+
+	count := 0
+	for k, v := range trm.things {
+		if strings.HasPrefix(k, prefix) && !v.Deleted() && matchesThing(v, filter) {
+			count++
+		}
 	}
 
-	first := fmt.Sprintf("%s%012d", startID, skip)
-	last := fmt.Sprintf("%s%012d", startID, skip+2*(limit-1))
+	return count
+}
+
+func (trm *thingRepositoryMock) Search(_ context.Context, owner, name string, offset, limit int) []things.Thing {
+	prefix := fmt.Sprintf("%s-", owner)
+	query := strings.ToLower(name)
 
+	items := make([]things.Thing, 0)
 	for k, v := range trm.things {
-		if strings.HasPrefix(k, prefix) && v.ID >= first && v.ID <= last {
-			things = append(things, v)
+		if strings.HasPrefix(k, prefix) && !v.Deleted() && strings.Contains(strings.ToLower(v.Name), query) {
+			items = append(items, v)
 		}
 	}
 
-	sort.SliceStable(things, func(i, j int) bool {
-		return things[i].ID < things[j].ID
+	sort.SliceStable(items, func(i, j int) bool {
+		ri, rj := searchRank(items[i].Name, query), searchRank(items[j].Name, query)
+		if ri != rj {
+			return ri < rj
+		}
+		if items[i].Name != items[j].Name {
+			return items[i].Name < items[j].Name
+		}
+		return items[i].ID < items[j].ID
 	})
 
-	return things
+	start, end, ok := bounds(len(items), offset, limit)
+	if !ok {
+		return []things.Thing{}
+	}
+
+	return items[start:end]
 }
 
-func (trm *thingRepositoryMock) Remove(owner, id string) error {
-	delete(trm.things, key(owner, id))
+// searchRank ranks exact name matches before prefix and substring matches.
+func searchRank(name, query string) int {
+	name = strings.ToLower(name)
+	switch {
+	case name == query:
+		return 0
+	case strings.HasPrefix(name, query):
+		return 1
+	default:
+		return 2
+	}
+}
+
+func (trm *thingRepositoryMock) AllByMetadata(_ context.Context, owner, metaKey, metaValue string, offset, limit int) []things.Thing {
+	prefix := fmt.Sprintf("%s-", owner)
+
+	items := make([]things.Thing, 0)
+	for k, v := range trm.things {
+		if !strings.HasPrefix(k, prefix) || v.Deleted() {
+			continue
+		}
+		if val, ok := v.Metadata[metaKey]; ok && fmt.Sprint(val) == metaValue {
+			items = append(items, v)
+		}
+	}
+
+	return sortedSubset(items, things.Sorting{}, offset, limit)
+}
+
+func (trm *thingRepositoryMock) ChangeOwner(_ context.Context, owner, id, newOwner string) error {
+	if err := trm.changeOwner(owner, id, newOwner); err != nil {
+		return err
+	}
+
+	if trm.transferred != nil {
+		trm.transferred(owner, id)
+	}
+
+	return nil
+}
+
+func (trm *thingRepositoryMock) changeOwner(owner, id, newOwner string) error {
+	trm.mu.Lock()
+	defer trm.mu.Unlock()
+
+	dbKey := key(owner, id)
+
+	thing, ok := trm.things[dbKey]
+	if !ok || thing.Deleted() {
+		return things.ErrNotFound
+	}
+
+	if _, ok := trm.things[key(newOwner, id)]; ok {
+		return things.ErrConflict
+	}
+
+	if thing.ExternalID != "" {
+		if _, ok := trm.externals[key(newOwner, thing.ExternalID)]; ok {
+			return things.ErrConflict
+		}
+		delete(trm.externals, key(owner, thing.ExternalID))
+	}
+
+	delete(trm.things, dbKey)
+	thing.Owner = newOwner
+	trm.save(thing)
+
+	return nil
+}
+
+func (trm *thingRepositoryMock) Remove(_ context.Context, owner, id string) error {
+	trm.mu.Lock()
+	defer trm.mu.Unlock()
+
+	dbKey := key(owner, id)
+	if thing, ok := trm.things[dbKey]; ok && !thing.Deleted() {
+		thing.DeletedAt = time.Now().UTC()
+		trm.things[dbKey] = thing
+	}
+
+	return nil
+}
+
+func (trm *thingRepositoryMock) RemoveAll(_ context.Context, owner string) error {
+	trm.mu.Lock()
+	defer trm.mu.Unlock()
+
+	prefix := fmt.Sprintf("%s-", owner)
+
+	for k, v := range trm.things {
+		if strings.HasPrefix(k, prefix) && !v.Deleted() {
+			v.DeletedAt = time.Now().UTC()
+			trm.things[k] = v
+		}
+	}
+
+	return nil
+}
+
+func (trm *thingRepositoryMock) Restore(_ context.Context, owner, id string) error {
+	trm.mu.Lock()
+	defer trm.mu.Unlock()
+
+	dbKey := key(owner, id)
+	thing, ok := trm.things[dbKey]
+	if !ok {
+		return things.ErrNotFound
+	}
+
+	if thing.Deleted() && thing.ExternalID != "" && trm.takenExternalID(owner, thing.ExternalID) {
+		return things.ErrConflict
+	}
+
+	thing.DeletedAt = time.Time{}
+	trm.things[dbKey] = thing
+	if thing.ExternalID != "" {
+		trm.externals[key(owner, thing.ExternalID)] = id
+	}
+
+	return nil
+}
+
+func (trm *thingRepositoryMock) Purge(_ context.Context, olderThan time.Time) (int, error) {
+	trm.mu.Lock()
+	defer trm.mu.Unlock()
+
+	purged := 0
+	for k, thing := range trm.things {
+		if !thing.Deleted() || !thing.DeletedAt.Before(olderThan) {
+			continue
+		}
+
+		delete(trm.things, k)
+		if trm.keys[thing.Key] == k {
+			delete(trm.keys, thing.Key)
+		}
+		if thing.ExternalID != "" && trm.externals[key(thing.Owner, thing.ExternalID)] == thing.ID {
+			delete(trm.externals, key(thing.Owner, thing.ExternalID))
+		}
+		purged++
+	}
+
+	return purged, nil
+}
+
+func (trm *thingRepositoryMock) Ping(context.Context) error {
 	return nil
 }
+
+// page retrieves the subset of things owned by the specified user, that are
+// either removed or not, depending on the deleted flag.
+func (trm *thingRepositoryMock) page(owner string, deleted bool, offset, limit int, sorting things.Sorting, filter things.ThingFilter) things.ThingPage {
+	// This obscure way to examine map keys is enforced by the key structure
+	// itself (see mocks/commons.go).
+	prefix := fmt.Sprintf("%s-", owner)
+
+	items := make([]things.Thing, 0)
+	for k, v := range trm.things {
+		if strings.HasPrefix(k, prefix) && v.Deleted() == deleted && matchesThing(v, filter) {
+			items = append(items, v)
+		}
+	}
+
+	return things.ThingPage{
+		Things: sortedSubset(items, sorting, offset, limit),
+		Total:  len(items),
+		Offset: offset,
+		Limit:  limit,
+	}
+}
+
+// hasTag determines whether the thing is tagged with the provided tag.
+func hasTag(thing things.Thing, tag string) bool {
+	for _, t := range thing.Tags {
+		if t == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesThing determines whether the thing matches all of the filter criteria.
+func matchesThing(thing things.Thing, filter things.ThingFilter) bool {
+	if !strings.Contains(strings.ToLower(thing.Name), strings.ToLower(filter.Name)) {
+		return false
+	}
+
+	if filter.Type != "" && thing.Type != filter.Type {
+		return false
+	}
+
+	if filter.Status != "" && thing.Status != filter.Status {
+		return false
+	}
+
+	if filter.Tag != "" && !hasTag(thing, filter.Tag) {
+		return false
+	}
+
+	return matches(thing.Metadata, filter.Metadata)
+}
+
+// sortedSubset sorts provided things as specified and returns the requested
+// subset of them.
+func sortedSubset(items []things.Thing, sorting things.Sorting, offset, limit int) []things.Thing {
+	sort.SliceStable(items, func(i, j int) bool {
+		return less(sorting, items[i].ID, items[i].Name, items[j].ID, items[j].Name)
+	})
+
+	start, end, ok := bounds(len(items), offset, limit)
+	if !ok {
+		return []things.Thing{}
+	}
+
+	return items[start:end]
+}
+
+// save stores the thing and indexes it by its key and external identifier,
+// if any.
+// takenExternalID reports whether the provided external ID is used by one
+// of the owner's things that is not removed.
+func (trm *thingRepositoryMock) takenExternalID(owner, extID string) bool {
+	id, ok := trm.externals[key(owner, extID)]
+	if !ok {
+		return false
+	}
+
+	t, ok := trm.things[key(owner, id)]
+	return ok && !t.Deleted()
+}
+
+func (trm *thingRepositoryMock) save(thing things.Thing) {
+	dbKey := key(thing.Owner, thing.ID)
+	trm.things[dbKey] = thing
+
+	if thing.Key != "" {
+		trm.keys[thing.Key] = dbKey
+	}
+
+	if thing.ExternalID != "" {
+		trm.externals[key(thing.Owner, thing.ExternalID)] = thing.ID
+	}
+}
+
+type unavailableThingsMock struct {
+	things.ThingRepository
+}
+
+// NewUnavailableThingRepository wraps the provided repository into the one
+// whose storage cannot be reached, so that only its Ping fails.
+func NewUnavailableThingRepository(repo things.ThingRepository) things.ThingRepository {
+	return unavailableThingsMock{repo}
+}
+
+func (trm unavailableThingsMock) Ping(context.Context) error {
+	return errUnavailable
+}
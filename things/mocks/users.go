@@ -2,26 +2,142 @@ package mocks
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	"github.com/mainflux/mainflux"
 	"github.com/mainflux/mainflux/users"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
-var _ mainflux.UsersServiceClient = (*usersServiceMock)(nil)
+var (
+	_ mainflux.UsersServiceClient = (*usersServiceMock)(nil)
+	_ mainflux.UsersServiceClient = (*unavailableUsersMock)(nil)
+	_ mainflux.UsersServiceClient = (*slowUsersMock)(nil)
+	_ mainflux.UsersServiceClient = (*flakyUsersMock)(nil)
+)
+
+// UsersService is the users service mock whose tokens can be registered
+// after it is created.
+type UsersService interface {
+	mainflux.UsersServiceClient
+
+	// Register maps the provided token to the user identified by the
+	// provided ID.
+	Register(string, string)
+}
 
 type usersServiceMock struct {
+	mu    sync.Mutex
 	users map[string]string
 }
 
-// NewUsersService creates mock of users service.
-func NewUsersService(users map[string]string) mainflux.UsersServiceClient {
-	return &usersServiceMock{users}
+// NewUsersService creates mock of users service. Each of the provided tokens
+// identifies the user it is mapped to, while all other tokens are rejected.
+// Only the users identified by the tokens are registered.
+func NewUsersService(tokens map[string]string) UsersService {
+	users := make(map[string]string, len(tokens))
+	for token, id := range tokens {
+		users[token] = id
+	}
+
+	return &usersServiceMock{users: users}
 }
 
-func (svc usersServiceMock) Identify(ctx context.Context, in *mainflux.Token, opts ...grpc.CallOption) (*mainflux.Identity, error) {
+func (svc *usersServiceMock) Identify(ctx context.Context, in *mainflux.Token, opts ...grpc.CallOption) (*mainflux.Identity, error) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
 	if id, ok := svc.users[in.Value]; ok {
 		return &mainflux.Identity{Value: id}, nil
 	}
 	return nil, users.ErrUnauthorizedAccess
 }
+
+func (svc *usersServiceMock) Resolve(ctx context.Context, in *mainflux.Identity, opts ...grpc.CallOption) (*mainflux.Identity, error) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	for _, id := range svc.users {
+		if id == in.Value {
+			return &mainflux.Identity{Value: id}, nil
+		}
+	}
+	return nil, status.Error(codes.NotFound, "user is not registered")
+}
+
+func (svc *usersServiceMock) Register(token, id string) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	svc.users[token] = id
+}
+
+type unavailableUsersMock struct{}
+
+// NewUnavailableUsersService creates mock of users service that cannot be
+// reached.
+func NewUnavailableUsersService() mainflux.UsersServiceClient {
+	return unavailableUsersMock{}
+}
+
+func (svc unavailableUsersMock) Identify(ctx context.Context, in *mainflux.Token, opts ...grpc.CallOption) (*mainflux.Identity, error) {
+	return nil, status.Error(codes.Unavailable, "users service is unreachable")
+}
+
+func (svc unavailableUsersMock) Resolve(ctx context.Context, in *mainflux.Identity, opts ...grpc.CallOption) (*mainflux.Identity, error) {
+	return nil, status.Error(codes.Unavailable, "users service is unreachable")
+}
+
+type slowUsersMock struct {
+	UsersService
+	delay time.Duration
+}
+
+// NewSlowUsersService creates mock of users service that identifies users
+// only after the provided delay. If the request's deadline expires first,
+// the request fails.
+func NewSlowUsersService(tokens map[string]string, delay time.Duration) mainflux.UsersServiceClient {
+	return slowUsersMock{
+		UsersService: NewUsersService(tokens),
+		delay:        delay,
+	}
+}
+
+func (svc slowUsersMock) Identify(ctx context.Context, in *mainflux.Token, opts ...grpc.CallOption) (*mainflux.Identity, error) {
+	select {
+	case <-time.After(svc.delay):
+		return svc.UsersService.Identify(ctx, in, opts...)
+	case <-ctx.Done():
+		return nil, status.Error(codes.DeadlineExceeded, ctx.Err().Error())
+	}
+}
+
+type flakyUsersMock struct {
+	UsersService
+	mu       sync.Mutex
+	failures int
+}
+
+// NewFlakyUsersService creates mock of users service that is unavailable for
+// the provided number of the first requests, and identifies users afterwards.
+func NewFlakyUsersService(tokens map[string]string, failures int) mainflux.UsersServiceClient {
+	return &flakyUsersMock{
+		UsersService: NewUsersService(tokens),
+		failures:     failures,
+	}
+}
+
+func (svc *flakyUsersMock) Identify(ctx context.Context, in *mainflux.Token, opts ...grpc.CallOption) (*mainflux.Identity, error) {
+	svc.mu.Lock()
+	if svc.failures > 0 {
+		svc.failures--
+		svc.mu.Unlock()
+		return nil, status.Error(codes.Unavailable, "users service is unreachable")
+	}
+	svc.mu.Unlock()
+
+	return svc.UsersService.Identify(ctx, in, opts...)
+}
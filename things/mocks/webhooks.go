@@ -0,0 +1,78 @@
+package mocks
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	"github.com/mainflux/mainflux/things"
+)
+
+var _ things.HTTPClient = (*webhookClientMock)(nil)
+
+// WebhookRequest represents the webhook notification recorded by the mock
+// HTTP client.
+type WebhookRequest struct {
+	URL    string
+	Header http.Header
+	Body   []byte
+}
+
+// WebhookClient is the HTTP client that records the webhook notifications
+// instead of sending them.
+type WebhookClient interface {
+	things.HTTPClient
+
+	// Requests retrieves all of the recorded requests, including the failed
+	// ones, in the order they were made.
+	Requests() []WebhookRequest
+}
+
+type webhookClientMock struct {
+	mu       sync.Mutex
+	failures int
+	requests []WebhookRequest
+}
+
+// NewWebhookClient creates mock of HTTP client that responds to the first
+// failures requests with the internal server error, and to the rest of them
+// with no content.
+func NewWebhookClient(failures int) WebhookClient {
+	return &webhookClientMock{failures: failures}
+}
+
+func (wcm *webhookClientMock) Do(req *http.Request) (*http.Response, error) {
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	wcm.mu.Lock()
+	defer wcm.mu.Unlock()
+
+	wcm.requests = append(wcm.requests, WebhookRequest{
+		URL:    req.URL.String(),
+		Header: req.Header,
+		Body:   body,
+	})
+
+	status := http.StatusNoContent
+	if len(wcm.requests) <= wcm.failures {
+		status = http.StatusInternalServerError
+	}
+
+	return &http.Response{
+		StatusCode: status,
+		Body:       ioutil.NopCloser(&bytes.Buffer{}),
+	}, nil
+}
+
+func (wcm *webhookClientMock) Requests() []WebhookRequest {
+	wcm.mu.Lock()
+	defer wcm.mu.Unlock()
+
+	requests := make([]WebhookRequest, len(wcm.requests))
+	copy(requests, wcm.requests)
+	return requests
+}
@@ -1,8 +1,11 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/lib/pq"
 	"github.com/mainflux/mainflux/logger"
@@ -27,21 +30,40 @@ func NewChannelRepository(db *sql.DB, log logger.Logger) things.ChannelRepositor
 	return &channelRepository{db: db, log: log}
 }
 
-func (cr channelRepository) Save(channel things.Channel) (string, error) {
-	q := `INSERT INTO channels (id, owner, name) VALUES ($1, $2, $3)`
+func (cr channelRepository) Save(ctx context.Context, channel things.Channel) (string, error) {
+	q := `INSERT INTO channels (id, owner, name, metadata, webhook, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7)`
 
-	_, err := cr.db.Exec(q, channel.ID, channel.Owner, channel.Name)
+	metadata, err := toJSON(channel.Metadata)
 	if err != nil {
 		return "", err
 	}
 
+	webhook, err := webhookToJSON(channel.Webhook)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := cr.db.ExecContext(ctx, q, channel.ID, channel.Owner, channel.Name, metadata, webhook, channel.CreatedAt, channel.UpdatedAt); err != nil {
+		return "", err
+	}
+
 	return channel.ID, nil
 }
 
-func (cr channelRepository) Update(channel things.Channel) error {
-	q := `UPDATE channels SET name = $1 WHERE owner = $2 AND id = $3;`
+func (cr channelRepository) Update(ctx context.Context, channel things.Channel) error {
+	q := `UPDATE channels SET name = $1, metadata = $2, webhook = $3, updated_at = $4 WHERE owner = $5 AND id = $6 AND deleted_at IS NULL;`
 
-	res, err := cr.db.Exec(q, channel.Name, channel.Owner, channel.ID)
+	metadata, err := toJSON(channel.Metadata)
+	if err != nil {
+		return err
+	}
+
+	webhook, err := webhookToJSON(channel.Webhook)
+	if err != nil {
+		return err
+	}
+
+	res, err := cr.db.ExecContext(ctx, q, channel.Name, metadata, webhook, channel.UpdatedAt, channel.Owner, channel.ID)
 	if err != nil {
 		return err
 	}
@@ -58,10 +80,11 @@ func (cr channelRepository) Update(channel things.Channel) error {
 	return nil
 }
 
-func (cr channelRepository) One(owner, id string) (things.Channel, error) {
-	q := `SELECT name FROM channels WHERE id = $1 AND owner = $2`
+func (cr channelRepository) One(ctx context.Context, owner, id string) (things.Channel, error) {
+	q := `SELECT name, metadata, webhook, created_at, updated_at FROM channels WHERE id = $1 AND owner = $2 AND deleted_at IS NULL`
 	channel := things.Channel{ID: id, Owner: owner}
-	if err := cr.db.QueryRow(q, id, owner).Scan(&channel.Name); err != nil {
+	var metadata, webhook []byte
+	if err := cr.db.QueryRowContext(ctx, q, id, owner).Scan(&channel.Name, &metadata, &webhook, &channel.CreatedAt, &channel.UpdatedAt); err != nil {
 		empty := things.Channel{}
 		if err == sql.ErrNoRows {
 			return empty, things.ErrNotFound
@@ -69,12 +92,22 @@ func (cr channelRepository) One(owner, id string) (things.Channel, error) {
 		return empty, err
 	}
 
-	qr := `SELECT id, type, name, key, payload FROM things t
+	m, err := fromJSON(metadata)
+	if err != nil {
+		return things.Channel{}, err
+	}
+	channel.Metadata = m
+
+	if channel.Webhook, err = webhookFromJSON(webhook); err != nil {
+		return things.Channel{}, err
+	}
+
+	qr := `SELECT id, COALESCE(external_id, ''), name, type, key, payload, metadata, tags, status, created_at, updated_at, version FROM things t
 	INNER JOIN connections conn
 	ON t.id = conn.thing_id AND t.owner = conn.thing_owner
-	WHERE conn.channel_id = $1 AND conn.channel_owner = $2`
+	WHERE conn.channel_id = $1 AND conn.channel_owner = $2 AND t.deleted_at IS NULL`
 
-	rows, err := cr.db.Query(qr, id, owner)
+	rows, err := cr.db.QueryContext(ctx, qr, id, owner)
 	if err != nil {
 		cr.log.Error(fmt.Sprintf("Failed to retrieve connected due to %s", err))
 		return things.Channel{}, err
@@ -82,8 +115,8 @@ func (cr channelRepository) One(owner, id string) (things.Channel, error) {
 	defer rows.Close()
 
 	for rows.Next() {
-		c := things.Thing{Owner: owner}
-		if err = rows.Scan(&c.ID, &c.Name, &c.Type, &c.Key, &c.Payload); err != nil {
+		c, err := scanThing(rows, owner)
+		if err != nil {
 			cr.log.Error(fmt.Sprintf("Failed to read connected thing due to %s", err))
 			return things.Channel{}, err
 		}
@@ -93,11 +126,83 @@ func (cr channelRepository) One(owner, id string) (things.Channel, error) {
 	return channel, nil
 }
 
-func (cr channelRepository) All(owner string, offset, limit int) []things.Channel {
-	q := `SELECT id, name FROM channels WHERE owner = $1 ORDER BY id LIMIT $2 OFFSET $3`
+func (cr channelRepository) ByName(ctx context.Context, owner, name string) (things.Channel, error) {
+	q := `SELECT id, name, metadata, webhook, created_at, updated_at FROM channels WHERE owner = $1 AND name = $2 AND deleted_at IS NULL LIMIT 1`
+
+	rows, err := cr.db.QueryContext(ctx, q, owner, name)
+	if err != nil {
+		return things.Channel{}, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return things.Channel{}, err
+		}
+		return things.Channel{}, things.ErrNotFound
+	}
+
+	return scanChannel(rows, owner)
+}
+
+func (cr channelRepository) All(ctx context.Context, owner string, offset, limit int, sorting things.Sorting, filter things.MetadataFilter) things.ChannelPage {
+	params := []interface{}{owner, limit, offset}
+	meta := ""
+	if filter.Key != "" {
+		meta = "AND metadata ->> $4 = $5"
+		params = append(params, filter.Key, filter.Value)
+	}
+
+	q := fmt.Sprintf(`SELECT id, name, metadata, webhook, created_at, updated_at FROM channels WHERE owner = $1 AND deleted_at IS NULL %s %s LIMIT $2 OFFSET $3`, meta, orderBy(sorting))
+	page := things.ChannelPage{
+		Channels: []things.Channel{},
+		Offset:   offset,
+		Limit:    limit,
+	}
+
+	rows, err := cr.db.QueryContext(ctx, q, params...)
+	if err != nil {
+		cr.log.Error(fmt.Sprintf("Failed to retrieve channels due to %s", err))
+		return page
+	}
+	defer rows.Close()
+
+	items := []things.Channel{}
+	for rows.Next() {
+		c, err := scanChannel(rows, owner)
+		if err != nil {
+			cr.log.Error(fmt.Sprintf("Failed to read retrieved channel due to %s", err))
+			return page
+		}
+		items = append(items, c)
+	}
+
+	countParams := []interface{}{owner}
+	meta = ""
+	if filter.Key != "" {
+		meta = "AND metadata ->> $2 = $3"
+		countParams = append(countParams, filter.Key, filter.Value)
+	}
+
+	q = fmt.Sprintf(`SELECT COUNT(*) FROM channels WHERE owner = $1 AND deleted_at IS NULL %s`, meta)
+	if err := cr.db.QueryRowContext(ctx, q, countParams...).Scan(&page.Total); err != nil {
+		cr.log.Error(fmt.Sprintf("Failed to count channels due to %s", err))
+		return page
+	}
+
+	page.Channels = items
+	return page
+}
+
+func (cr channelRepository) AllByThing(ctx context.Context, owner, thingID string, offset, limit int) []things.Channel {
+	q := `SELECT id, name, metadata, webhook, created_at, updated_at FROM channels ch
+	INNER JOIN connections conn
+	ON ch.id = conn.channel_id AND ch.owner = conn.channel_owner
+	WHERE conn.thing_id = $1 AND conn.thing_owner = $2 AND ch.deleted_at IS NULL
+	ORDER BY ch.id LIMIT $3 OFFSET $4`
 	items := []things.Channel{}
 
-	rows, err := cr.db.Query(q, owner, limit, offset)
+	rows, err := cr.db.QueryContext(ctx, q, thingID, owner, limit, offset)
 	if err != nil {
 		cr.log.Error(fmt.Sprintf("Failed to retrieve channels due to %s", err))
 		return []things.Channel{}
@@ -105,8 +210,8 @@ func (cr channelRepository) All(owner string, offset, limit int) []things.Channe
 	defer rows.Close()
 
 	for rows.Next() {
-		c := things.Channel{Owner: owner}
-		if err = rows.Scan(&c.ID, &c.Name); err != nil {
+		c, err := scanChannel(rows, owner)
+		if err != nil {
 			cr.log.Error(fmt.Sprintf("Failed to read retrieved channel due to %s", err))
 			return []things.Channel{}
 		}
@@ -116,39 +221,164 @@ func (cr channelRepository) All(owner string, offset, limit int) []things.Channe
 	return items
 }
 
-func (cr channelRepository) Remove(owner, id string) error {
-	q := `DELETE FROM channels WHERE id = $1 AND owner = $2`
-	cr.db.Exec(q, id, owner)
-	return nil
+func (cr channelRepository) Things(ctx context.Context, owner, chanID string, offset, limit int, filter things.ConnectionFilter) []things.Thing {
+	connected := `EXISTS (SELECT 1 FROM connections conn
+	WHERE conn.thing_id = t.id AND conn.thing_owner = t.owner
+	AND conn.channel_id = $1 AND conn.channel_owner = $2)`
+	disconnected := `EXISTS (SELECT 1 FROM disconnections dis
+	WHERE dis.thing_id = t.id AND dis.thing_owner = t.owner
+	AND dis.channel_id = $1 AND dis.channel_owner = $2 AND dis.disconnected_at >= $5)`
+
+	params := []interface{}{chanID, owner, limit, offset}
+	cond := connected
+	switch filter.State {
+	case things.StateDisconnected:
+		cond = fmt.Sprintf("NOT %s AND %s", connected, disconnected)
+		params = append(params, filter.DisconnectedSince)
+	case things.StateAny:
+		cond = fmt.Sprintf("(%s OR %s)", connected, disconnected)
+		params = append(params, filter.DisconnectedSince)
+	}
+
+	q := fmt.Sprintf(`SELECT id, COALESCE(external_id, ''), name, type, key, payload, metadata, tags, status, created_at, updated_at, version FROM things t
+	WHERE t.owner = $2 AND t.deleted_at IS NULL AND %s
+	AND EXISTS (SELECT 1 FROM channels WHERE id = $1 AND owner = $2 AND deleted_at IS NULL)
+	ORDER BY t.id LIMIT $3 OFFSET $4`, cond)
+	items := []things.Thing{}
+
+	rows, err := cr.db.QueryContext(ctx, q, params...)
+	if err != nil {
+		cr.log.Error(fmt.Sprintf("Failed to retrieve connected things due to %s", err))
+		return []things.Thing{}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		c, err := scanThing(rows, owner)
+		if err != nil {
+			cr.log.Error(fmt.Sprintf("Failed to read connected thing due to %s", err))
+			return []things.Thing{}
+		}
+		items = append(items, c)
+	}
+
+	return items
+}
+
+func (cr channelRepository) UnconnectedThings(ctx context.Context, owner string, offset, limit int) []things.Thing {
+	q := `SELECT id, COALESCE(external_id, ''), name, type, key, payload, metadata, tags, status, created_at, updated_at, version FROM things t
+	WHERE t.owner = $1 AND t.deleted_at IS NULL
+	AND NOT EXISTS (SELECT 1 FROM connections conn
+	JOIN channels ch ON ch.id = conn.channel_id AND ch.owner = conn.channel_owner
+	WHERE conn.thing_id = t.id AND conn.thing_owner = t.owner AND ch.deleted_at IS NULL)
+	ORDER BY t.id LIMIT $2 OFFSET $3`
+	items := []things.Thing{}
+
+	rows, err := cr.db.QueryContext(ctx, q, owner, limit, offset)
+	if err != nil {
+		cr.log.Error(fmt.Sprintf("Failed to retrieve unconnected things due to %s", err))
+		return []things.Thing{}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		th, err := scanThing(rows, owner)
+		if err != nil {
+			cr.log.Error(fmt.Sprintf("Failed to read unconnected thing due to %s", err))
+			return []things.Thing{}
+		}
+		items = append(items, th)
+	}
+
+	return items
 }
 
-func (cr channelRepository) Connect(owner, chanID, thingID string) error {
-	q := `INSERT INTO connections (channel_id, channel_owner, thing_id, thing_owner) VALUES ($1, $2, $3, $2)`
+func (cr channelRepository) Count(ctx context.Context, owner string) int {
+	q := `SELECT COUNT(*) FROM channels WHERE owner = $1 AND deleted_at IS NULL`
 
-	if _, err := cr.db.Exec(q, chanID, owner, thingID); err != nil {
-		pqErr, ok := err.(*pq.Error)
+	count := 0
+	if err := cr.db.QueryRowContext(ctx, q, owner).Scan(&count); err != nil {
+		cr.log.Error(fmt.Sprintf("Failed to count channels due to %s", err))
+		return 0
+	}
 
-		if ok && errFK == pqErr.Code.Name() {
-			return things.ErrNotFound
+	return count
+}
+
+func (cr channelRepository) CountThings(ctx context.Context, owner, chanID string) (int, error) {
+	q := `SELECT COUNT(conn.thing_id) FROM channels ch
+	LEFT JOIN connections conn ON conn.channel_id = ch.id AND conn.channel_owner = ch.owner
+	WHERE ch.id = $1 AND ch.owner = $2 AND ch.deleted_at IS NULL
+	GROUP BY ch.id`
+
+	count := 0
+	if err := cr.db.QueryRowContext(ctx, q, chanID, owner).Scan(&count); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, things.ErrNotFound
 		}
+		return 0, err
+	}
+
+	return count, nil
+}
 
-		// connect is idempotent
-		if ok && errDuplicate == pqErr.Code.Name() {
-			return nil
+func (cr channelRepository) ChangeOwner(ctx context.Context, owner, id, newOwner string) error {
+	tx, err := cr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	rollback := func() {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			cr.log.Error(fmt.Sprintf("Failed to rollback channel transfer due to %s", rbErr))
+		}
+	}
+
+	for _, q := range []string{
+		`DELETE FROM connections WHERE channel_id = $1 AND channel_owner = $2`,
+		`DELETE FROM disconnections WHERE channel_id = $1 AND channel_owner = $2`,
+	} {
+		if _, err := tx.ExecContext(ctx, q, id, owner); err != nil {
+			rollback()
+			return err
+		}
+	}
+
+	q := `UPDATE channels SET owner = $1 WHERE owner = $2 AND id = $3 AND deleted_at IS NULL`
+	res, err := tx.ExecContext(ctx, q, newOwner, owner, id)
+	if err != nil {
+		rollback()
+		if pqErr, ok := err.(*pq.Error); ok && errDuplicate == pqErr.Code.Name() {
+			return things.ErrConflict
 		}
+		return err
+	}
 
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		rollback()
 		return err
 	}
 
-	return nil
+	if cnt == 0 {
+		rollback()
+		return things.ErrNotFound
+	}
+
+	return tx.Commit()
 }
 
-func (cr channelRepository) Disconnect(owner, chanID, thingID string) error {
-	q := `DELETE FROM connections
-	WHERE channel_id = $1 AND channel_owner = $2
-	AND thing_id = $3 AND thing_owner = $2`
+func (cr channelRepository) Remove(ctx context.Context, owner, id string) error {
+	q := `UPDATE channels SET deleted_at = $3 WHERE id = $1 AND owner = $2 AND deleted_at IS NULL`
+
+	_, err := cr.db.ExecContext(ctx, q, id, owner, time.Now().UTC())
+	return err
+}
 
-	res, err := cr.db.Exec(q, chanID, owner, thingID)
+func (cr channelRepository) Restore(ctx context.Context, owner, id string) error {
+	q := `UPDATE channels SET deleted_at = NULL WHERE id = $1 AND owner = $2 AND deleted_at IS NOT NULL`
+
+	res, err := cr.db.ExecContext(ctx, q, id, owner)
 	if err != nil {
 		return err
 	}
@@ -165,25 +395,401 @@ func (cr channelRepository) Disconnect(owner, chanID, thingID string) error {
 	return nil
 }
 
-func (cr channelRepository) HasThing(chanID, key string) (string, error) {
-	var thingID string
+func (cr channelRepository) Purge(ctx context.Context, olderThan time.Time) (int, error) {
+	q := `DELETE FROM channels WHERE deleted_at < $1`
+
+	res, err := cr.db.ExecContext(ctx, q, olderThan.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(cnt), nil
+}
+
+func (cr channelRepository) RemoveAll(ctx context.Context, owner string) error {
+	queries := []string{
+		`DELETE FROM connections WHERE channel_owner = $1`,
+		`UPDATE channels SET deleted_at = $2 WHERE owner = $1 AND deleted_at IS NULL`,
+	}
+
+	tx, err := cr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	args := [][]interface{}{
+		{owner},
+		{owner, time.Now().UTC()},
+	}
+
+	for i, q := range queries {
+		if _, err := tx.ExecContext(ctx, q, args[i]...); err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				cr.log.Error(fmt.Sprintf("Failed to rollback channels removal due to %s", rbErr))
+			}
+
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (cr channelRepository) Connect(ctx context.Context, owner, chanID, thingID string, mode things.AccessMode) (things.Connection, error) {
+	// the existing connection keeps the time it was made
+	q := `INSERT INTO connections (channel_id, channel_owner, thing_id, thing_owner, connected_at, mode) VALUES ($1, $2, $3, $2, $4, $5)
+	ON CONFLICT (channel_id, channel_owner, thing_id, thing_owner) DO UPDATE SET mode = EXCLUDED.mode
+	RETURNING connected_at`
+
+	conn := things.Connection{ChanID: chanID, ThingID: thingID, Mode: mode}
+	if err := cr.db.QueryRowContext(ctx, q, chanID, owner, thingID, time.Now().UTC(), mode).Scan(&conn.ConnectedAt); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && errFK == pqErr.Code.Name() {
+			return things.Connection{}, things.ErrNotFound
+		}
+
+		return things.Connection{}, err
+	}
+
+	return conn, nil
+}
+
+func (cr channelRepository) ConnectMany(ctx context.Context, owner, thingID string, chanIDs []string) error {
+	q := `INSERT INTO connections (channel_id, channel_owner, thing_id, thing_owner) VALUES ($1, $2, $3, $2)
+	ON CONFLICT DO NOTHING`
 
-	q := `SELECT id FROM things WHERE key = $1`
-	if err := cr.db.QueryRow(q, key).Scan(&thingID); err != nil {
+	tx, err := cr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, chanID := range chanIDs {
+		err := live(ctx, tx, owner, chanID)
+		if err == nil {
+			_, err = tx.ExecContext(ctx, q, chanID, owner, thingID)
+		}
+
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				cr.log.Error(fmt.Sprintf("Failed to rollback connections due to %s", rbErr))
+			}
+
+			if pqErr, ok := err.(*pq.Error); ok && errFK == pqErr.Code.Name() {
+				return things.ErrNotFound
+			}
+
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (cr channelRepository) ConnectThings(ctx context.Context, owner, chanID string, thingIDs []string) error {
+	q := `INSERT INTO connections (channel_id, channel_owner, thing_id, thing_owner) VALUES ($1, $2, $3, $2)
+	ON CONFLICT DO NOTHING`
+
+	tx, err := cr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := live(ctx, tx, owner, chanID); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			cr.log.Error(fmt.Sprintf("Failed to rollback connections due to %s", rbErr))
+		}
+
+		return err
+	}
+
+	for _, thingID := range thingIDs {
+		if _, err := tx.ExecContext(ctx, q, chanID, owner, thingID); err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				cr.log.Error(fmt.Sprintf("Failed to rollback connections due to %s", rbErr))
+			}
+
+			if pqErr, ok := err.(*pq.Error); ok && errFK == pqErr.Code.Name() {
+				return things.ErrNotFound
+			}
+
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (cr channelRepository) Disconnect(ctx context.Context, owner, chanID, thingID string) (bool, error) {
+	q := `WITH removed AS (
+		DELETE FROM connections
+		WHERE channel_id = $1 AND channel_owner = $2
+		AND thing_id = $3 AND thing_owner = $2
+		RETURNING channel_id, channel_owner, thing_id, thing_owner
+	)
+	INSERT INTO disconnections (channel_id, channel_owner, thing_id, thing_owner, disconnected_at)
+	SELECT channel_id, channel_owner, thing_id, thing_owner, $4 FROM removed
+	ON CONFLICT (channel_id, channel_owner, thing_id, thing_owner)
+	DO UPDATE SET disconnected_at = EXCLUDED.disconnected_at`
+
+	res, err := cr.db.ExecContext(ctx, q, chanID, owner, thingID, time.Now().UTC())
+	if err != nil {
+		return false, err
+	}
+
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return cnt > 0, nil
+}
+
+func (cr channelRepository) DisconnectAll(ctx context.Context, owner, thingID string) error {
+	q := `WITH removed AS (
+		DELETE FROM connections WHERE thing_id = $1 AND thing_owner = $2
+		RETURNING channel_id, channel_owner, thing_id, thing_owner
+	)
+	INSERT INTO disconnections (channel_id, channel_owner, thing_id, thing_owner, disconnected_at)
+	SELECT channel_id, channel_owner, thing_id, thing_owner, $3 FROM removed
+	ON CONFLICT (channel_id, channel_owner, thing_id, thing_owner)
+	DO UPDATE SET disconnected_at = EXCLUDED.disconnected_at`
+
+	_, err := cr.db.ExecContext(ctx, q, thingID, owner, time.Now().UTC())
+	return err
+}
+
+func (cr channelRepository) Rebind(ctx context.Context, owner, fromID, toID string) error {
+	connect := `INSERT INTO connections (channel_id, channel_owner, thing_id, thing_owner, connected_at, mode)
+	SELECT channel_id, channel_owner, $2, thing_owner, $4, mode FROM connections
+	WHERE thing_id = $1 AND thing_owner = $3
+	ON CONFLICT DO NOTHING`
+
+	disconnect := `WITH removed AS (
+		DELETE FROM connections WHERE thing_id = $1 AND thing_owner = $2
+		RETURNING channel_id, channel_owner, thing_id, thing_owner
+	)
+	INSERT INTO disconnections (channel_id, channel_owner, thing_id, thing_owner, disconnected_at)
+	SELECT channel_id, channel_owner, thing_id, thing_owner, $3 FROM removed
+	ON CONFLICT (channel_id, channel_owner, thing_id, thing_owner)
+	DO UPDATE SET disconnected_at = EXCLUDED.disconnected_at`
+
+	tx, err := cr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	if _, err := tx.ExecContext(ctx, connect, fromID, toID, owner, now); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			cr.log.Error(fmt.Sprintf("Failed to rollback connections rebinding due to %s", rbErr))
+		}
+
+		if pqErr, ok := err.(*pq.Error); ok && errFK == pqErr.Code.Name() {
+			return things.ErrNotFound
+		}
+
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, disconnect, fromID, owner, now); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			cr.log.Error(fmt.Sprintf("Failed to rollback connections rebinding due to %s", rbErr))
+		}
+
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (cr channelRepository) DisconnectAllThings(ctx context.Context, owner string) error {
+	q := `DELETE FROM connections WHERE thing_owner = $1`
+
+	_, err := cr.db.ExecContext(ctx, q, owner)
+	return err
+}
+
+func (cr channelRepository) HasConnection(ctx context.Context, owner, chanID, thingID string) bool {
+	q := `SELECT EXISTS (SELECT 1 FROM connections conn
+	INNER JOIN channels ch ON ch.id = conn.channel_id AND ch.owner = conn.channel_owner
+	WHERE conn.channel_id = $1 AND conn.channel_owner = $2
+	AND conn.thing_id = $3 AND conn.thing_owner = $2 AND ch.deleted_at IS NULL);`
+
+	exists := false
+	if err := cr.db.QueryRowContext(ctx, q, chanID, owner, thingID).Scan(&exists); err != nil {
+		cr.log.Error(fmt.Sprintf("Failed to check connection existence due to %s", err))
+		return false
+	}
+
+	return exists
+}
+
+func (cr channelRepository) Connections(ctx context.Context, owner, thingID string) ([]string, error) {
+	q := `SELECT conn.channel_id FROM connections conn
+	INNER JOIN channels ch ON ch.id = conn.channel_id AND ch.owner = conn.channel_owner
+	WHERE conn.thing_id = $1 AND conn.thing_owner = $2 AND conn.channel_owner = $2 AND ch.deleted_at IS NULL
+	ORDER BY conn.channel_id`
+
+	rows, err := cr.db.QueryContext(ctx, q, thingID, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+func (cr channelRepository) ChannelConnections(ctx context.Context, owner, chanID string) ([]things.Connection, error) {
+	q := `SELECT thing_id, mode, connected_at FROM connections
+	WHERE channel_id = $1 AND channel_owner = $2
+	ORDER BY connected_at, thing_id`
+
+	rows, err := cr.db.QueryContext(ctx, q, chanID, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	conns := []things.Connection{}
+	for rows.Next() {
+		conn := things.Connection{ChanID: chanID}
+		if err := rows.Scan(&conn.ThingID, &conn.Mode, &conn.ConnectedAt); err != nil {
+			return nil, err
+		}
+		conns = append(conns, conn)
+	}
+
+	return conns, rows.Err()
+}
+
+func (cr channelRepository) HasThing(ctx context.Context, chanID, key string, mode things.AccessMode) (string, error) {
+	var thingID, owner string
+
+	q := `SELECT id, owner FROM things WHERE key = $1 AND deleted_at IS NULL AND status <> $2`
+	if err := cr.db.QueryRowContext(ctx, q, key, things.StatusDisabled).Scan(&thingID, &owner); err != nil {
 		cr.log.Error(fmt.Sprintf("Failed to obtain thing's ID due to %s", err))
 		return "", err
 	}
 
-	q = `SELECT EXISTS (SELECT 1 FROM connections WHERE channel_id = $1 AND thing_id = $2);`
-	exists := false
-	if err := cr.db.QueryRow(q, chanID, thingID).Scan(&exists); err != nil {
+	// things are connected only to the channels of their owner, so the whole
+	// primary key is matched, and the lookup doesn't depend on the number of
+	// things connected to the channel
+	q = `SELECT conn.mode FROM connections conn
+	INNER JOIN channels ch ON ch.id = conn.channel_id AND ch.owner = conn.channel_owner
+	WHERE conn.channel_id = $1 AND conn.channel_owner = $3
+	AND conn.thing_id = $2 AND conn.thing_owner = $3 AND ch.deleted_at IS NULL;`
+	var granted things.AccessMode
+	if err := cr.db.QueryRowContext(ctx, q, chanID, thingID, owner).Scan(&granted); err != nil {
+		if err == sql.ErrNoRows {
+			return "", things.ErrUnauthorizedAccess
+		}
+
 		cr.log.Error(fmt.Sprintf("Failed to check thing existence due to %s", err))
 		return "", err
 	}
 
-	if !exists {
+	if !granted.Permits(mode) {
 		return "", things.ErrUnauthorizedAccess
 	}
 
 	return thingID, nil
 }
+
+func (cr channelRepository) Owner(ctx context.Context, chanID string) (string, error) {
+	var owner string
+
+	q := `SELECT owner FROM channels WHERE id = $1 AND deleted_at IS NULL`
+	if err := cr.db.QueryRowContext(ctx, q, chanID).Scan(&owner); err != nil {
+		if err == sql.ErrNoRows {
+			return "", things.ErrNotFound
+		}
+		return "", err
+	}
+
+	return owner, nil
+}
+
+func (cr channelRepository) Ping(ctx context.Context) error {
+	return cr.db.PingContext(ctx)
+}
+
+// live returns ErrNotFound if the channel having the provided identifier, that
+// is owned by the specified user, doesn't exist or is removed.
+func live(ctx context.Context, tx *sql.Tx, owner, id string) error {
+	q := `SELECT EXISTS (SELECT 1 FROM channels WHERE id = $1 AND owner = $2 AND deleted_at IS NULL)`
+
+	exists := false
+	if err := tx.QueryRowContext(ctx, q, id, owner).Scan(&exists); err != nil {
+		return err
+	}
+
+	if !exists {
+		return things.ErrNotFound
+	}
+
+	return nil
+}
+
+// scanChannel reads the channel from the current row, whose columns are id,
+// name, metadata, webhook, created_at and updated_at, in that order.
+func scanChannel(rows *sql.Rows, owner string) (things.Channel, error) {
+	channel := things.Channel{Owner: owner}
+	var metadata, webhook []byte
+
+	if err := rows.Scan(&channel.ID, &channel.Name, &metadata, &webhook, &channel.CreatedAt, &channel.UpdatedAt); err != nil {
+		return things.Channel{}, err
+	}
+
+	m, err := fromJSON(metadata)
+	if err != nil {
+		return things.Channel{}, err
+	}
+	channel.Metadata = m
+
+	if channel.Webhook, err = webhookFromJSON(webhook); err != nil {
+		return things.Channel{}, err
+	}
+
+	return channel, nil
+}
+
+// webhookToJSON converts the channel's webhook into its database
+// representation. Missing webhook is stored as NULL.
+func webhookToJSON(webhook *things.Webhook) (interface{}, error) {
+	if webhook == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(webhook)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(data), nil
+}
+
+func webhookFromJSON(data []byte) (*things.Webhook, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	webhook := things.Webhook{}
+	if err := json.Unmarshal(data, &webhook); err != nil {
+		return nil, err
+	}
+
+	return &webhook, nil
+}
@@ -1,8 +1,11 @@
 package postgres_test
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/mainflux/mainflux/things"
 	"github.com/mainflux/mainflux/things/postgres"
@@ -16,7 +19,7 @@ func TestChannelSave(t *testing.T) {
 	channelRepo := postgres.NewChannelRepository(db, testLog)
 
 	channel := things.Channel{ID: idp.ID(), Owner: email}
-	_, err := channelRepo.Save(channel)
+	_, err := channelRepo.Save(context.Background(), channel)
 	hasErr := err != nil
 
 	assert.False(t, hasErr, fmt.Sprintf("create new channel: expected false got %t", hasErr))
@@ -28,7 +31,7 @@ func TestChannelUpdate(t *testing.T) {
 	chanRepo := postgres.NewChannelRepository(db, testLog)
 
 	c := things.Channel{ID: idp.ID(), Owner: email}
-	chanRepo.Save(c)
+	chanRepo.Save(context.Background(), c)
 
 	cases := map[string]struct {
 		channel things.Channel
@@ -40,7 +43,7 @@ func TestChannelUpdate(t *testing.T) {
 	}
 
 	for desc, tc := range cases {
-		err := chanRepo.Update(tc.channel)
+		err := chanRepo.Update(context.Background(), tc.channel)
 		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
 	}
 }
@@ -51,7 +54,7 @@ func TestSingleChannelRetrieval(t *testing.T) {
 	chanRepo := postgres.NewChannelRepository(db, testLog)
 
 	c := things.Channel{ID: idp.ID(), Owner: email}
-	chanRepo.Save(c)
+	chanRepo.Save(context.Background(), c)
 
 	cases := map[string]struct {
 		owner string
@@ -64,11 +67,38 @@ func TestSingleChannelRetrieval(t *testing.T) {
 	}
 
 	for desc, tc := range cases {
-		_, err := chanRepo.One(tc.owner, tc.ID)
+		_, err := chanRepo.One(context.Background(), tc.owner, tc.ID)
 		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
 	}
 }
 
+func TestChannelRetrievalByName(t *testing.T) {
+	email := "channel-by-name@example.com"
+	idp := uuid.New()
+	chanRepo := postgres.NewChannelRepository(db, testLog)
+
+	c := things.Channel{ID: idp.ID(), Owner: email, Name: "temperature"}
+	chanRepo.Save(context.Background(), c)
+
+	cases := map[string]struct {
+		owner string
+		name  string
+		err   error
+	}{
+		"existing user and name":           {c.Owner, c.Name, nil},
+		"existing user, non-existing name": {c.Owner, "humidity", things.ErrNotFound},
+		"non-existing owner":               {wrong, c.Name, things.ErrNotFound},
+	}
+
+	for desc, tc := range cases {
+		ch, err := chanRepo.ByName(context.Background(), tc.owner, tc.name)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+		if err == nil {
+			assert.Equal(t, c.ID, ch.ID, fmt.Sprintf("%s: expected %s got %s\n", desc, c.ID, ch.ID))
+		}
+	}
+}
+
 func TestMultiChannelRetrieval(t *testing.T) {
 	email := "channel-multi-retrieval@example.com"
 	idp := uuid.New()
@@ -78,21 +108,267 @@ func TestMultiChannelRetrieval(t *testing.T) {
 
 	for i := 0; i < n; i++ {
 		c := things.Channel{ID: idp.ID(), Owner: email}
-		chanRepo.Save(c)
+		chanRepo.Save(context.Background(), c)
+	}
+
+	cases := map[string]struct {
+		owner  string
+		offset int
+		limit  int
+		size   int
+		total  int
+	}{
+		"existing owner, retrieve all":    {email, 0, n, n, n},
+		"existing owner, retrieve subset": {email, 1, 6, 6, n},
+		"non-existing owner":              {wrong, 1, 6, 0, 0},
+	}
+
+	for desc, tc := range cases {
+		page := chanRepo.All(context.Background(), tc.owner, tc.offset, tc.limit, things.Sorting{}, things.MetadataFilter{})
+		size := len(page.Channels)
+		assert.Equal(t, tc.size, size, fmt.Sprintf("%s: expected %d got %d\n", desc, tc.size, size))
+		assert.Equal(t, tc.total, page.Total, fmt.Sprintf("%s: expected total %d got %d\n", desc, tc.total, page.Total))
+	}
+}
+
+func TestChannelCount(t *testing.T) {
+	email := "channel-count@example.com"
+	otherEmail := "other-channel-count@example.com"
+	idp := uuid.New()
+	chanRepo := postgres.NewChannelRepository(db, testLog)
+
+	n := 5
+	for i := 0; i < n; i++ {
+		chanRepo.Save(context.Background(), things.Channel{ID: idp.ID(), Owner: email})
+	}
+	chanRepo.Save(context.Background(), things.Channel{ID: idp.ID(), Owner: otherEmail})
+
+	cases := map[string]struct {
+		owner string
+		count int
+	}{
+		"existing owner":       {email, n},
+		"other existing owner": {otherEmail, 1},
+		"non-existing owner":   {wrong, 0},
+	}
+
+	for desc, tc := range cases {
+		count := chanRepo.Count(context.Background(), tc.owner)
+		assert.Equal(t, tc.count, count, fmt.Sprintf("%s: expected %d got %d\n", desc, tc.count, count))
+	}
+}
+
+func TestChannelThingsCount(t *testing.T) {
+	email := "channel-things-count@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+	chanRepo := postgres.NewChannelRepository(db, testLog)
+
+	chanID, _ := chanRepo.Save(context.Background(), things.Channel{ID: idp.ID(), Owner: email})
+	emptyID, _ := chanRepo.Save(context.Background(), things.Channel{ID: idp.ID(), Owner: email})
+
+	n := 3
+	for i := 0; i < n; i++ {
+		thingID, _ := thingRepo.Save(context.Background(), things.Thing{ID: idp.ID(), Owner: email, Key: idp.ID()})
+		chanRepo.Connect(context.Background(), email, chanID, thingID, things.AccessPubSub)
+	}
+
+	cases := map[string]struct {
+		owner  string
+		chanID string
+		count  int
+		err    error
+	}{
+		"channel with connected things":    {email, chanID, n, nil},
+		"channel without connected things": {email, emptyID, 0, nil},
+		"non-existing channel":             {email, wrong, 0, things.ErrNotFound},
+		"existing channel of other owner":  {wrong, chanID, 0, things.ErrNotFound},
+	}
+
+	for desc, tc := range cases {
+		count, err := chanRepo.CountThings(context.Background(), tc.owner, tc.chanID)
+		assert.Equal(t, tc.count, count, fmt.Sprintf("%s: expected %d got %d\n", desc, tc.count, count))
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+}
+
+func TestMultiChannelRetrievalByMetadata(t *testing.T) {
+	email := "channel-multi-retrieval-by-metadata@example.com"
+	idp := uuid.New()
+	chanRepo := postgres.NewChannelRepository(db, testLog)
+
+	n := 10
+
+	for i := 0; i < n; i++ {
+		c := things.Channel{
+			ID:       idp.ID(),
+			Owner:    email,
+			Metadata: map[string]interface{}{"region": "eu"},
+		}
+		if i%2 == 1 {
+			c.Metadata["region"] = "us"
+		}
+		chanRepo.Save(context.Background(), c)
+	}
+
+	cases := map[string]struct {
+		filter things.MetadataFilter
+		size   int
+	}{
+		"matching metadata":     {things.MetadataFilter{Key: "region", Value: "eu"}, n / 2},
+		"non-matching value":    {things.MetadataFilter{Key: "region", Value: "asia"}, 0},
+		"non-existing metadata": {things.MetadataFilter{Key: "protocol", Value: "eu"}, 0},
+		"no metadata filter":    {things.MetadataFilter{}, n},
+	}
+
+	for desc, tc := range cases {
+		page := chanRepo.All(context.Background(), email, 0, n, things.Sorting{}, tc.filter)
+		size := len(page.Channels)
+		assert.Equal(t, tc.size, size, fmt.Sprintf("%s: expected %d got %d\n", desc, tc.size, size))
+		assert.Equal(t, tc.size, page.Total, fmt.Sprintf("%s: expected total %d got %d\n", desc, tc.size, page.Total))
+	}
+}
+
+func TestMultiChannelRetrievalByThing(t *testing.T) {
+	email := "channel-multi-retrieval-by-thing@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+	chanRepo := postgres.NewChannelRepository(db, testLog)
+
+	thing := things.Thing{
+		ID:    idp.ID(),
+		Owner: email,
+		Key:   idp.ID(),
+	}
+	thingRepo.Save(context.Background(), thing)
+
+	n := 10
+	for i := 0; i < n; i++ {
+		chanID, _ := chanRepo.Save(context.Background(), things.Channel{ID: idp.ID(), Owner: email})
+		chanRepo.Connect(context.Background(), email, chanID, thing.ID, things.AccessPubSub)
+	}
+
+	cases := map[string]struct {
+		owner   string
+		thingID string
+		offset  int
+		limit   int
+		size    int
+	}{
+		"existing owner, retrieve all":    {email, thing.ID, 0, n, n},
+		"existing owner, retrieve subset": {email, thing.ID, 1, 6, 6},
+		"non-existing thing":              {email, wrong, 0, n, 0},
+		"non-existing owner":              {wrong, thing.ID, 0, n, 0},
+	}
+
+	for desc, tc := range cases {
+		size := len(chanRepo.AllByThing(context.Background(), tc.owner, tc.thingID, tc.offset, tc.limit))
+		assert.Equal(t, tc.size, size, fmt.Sprintf("%s: expected %d got %d\n", desc, tc.size, size))
+	}
+}
+
+func TestMultiThingRetrievalByChannel(t *testing.T) {
+	email := "thing-multi-retrieval-by-channel@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+	chanRepo := postgres.NewChannelRepository(db, testLog)
+
+	chanID, _ := chanRepo.Save(context.Background(), things.Channel{ID: idp.ID(), Owner: email})
+
+	n := 10
+	for i := 0; i < n; i++ {
+		thingID, _ := thingRepo.Save(context.Background(), things.Thing{ID: idp.ID(), Owner: email, Key: idp.ID()})
+		chanRepo.Connect(context.Background(), email, chanID, thingID, things.AccessPubSub)
 	}
 
 	cases := map[string]struct {
 		owner  string
+		chanID string
 		offset int
 		limit  int
 		size   int
 	}{
-		"existing owner":     {email, 0, n, n},
-		"non-existing owner": {wrong, 1, 6, 0},
+		"existing owner, retrieve all":    {email, chanID, 0, n, n},
+		"existing owner, retrieve subset": {email, chanID, 1, 6, 6},
+		"non-existing channel":            {email, wrong, 0, n, 0},
+		"non-existing owner":              {wrong, chanID, 0, n, 0},
 	}
 
 	for desc, tc := range cases {
-		size := len(chanRepo.All(tc.owner, tc.offset, tc.limit))
+		size := len(chanRepo.Things(context.Background(), tc.owner, tc.chanID, tc.offset, tc.limit, things.ConnectionFilter{}))
+		assert.Equal(t, tc.size, size, fmt.Sprintf("%s: expected %d got %d\n", desc, tc.size, size))
+	}
+}
+
+func TestUnconnectedThingRetrieval(t *testing.T) {
+	email := "unconnected-thing-retrieval@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+	chanRepo := postgres.NewChannelRepository(db, testLog)
+
+	chanID, _ := chanRepo.Save(context.Background(), things.Channel{ID: idp.ID(), Owner: email})
+	removedID, _ := chanRepo.Save(context.Background(), things.Channel{ID: idp.ID(), Owner: email})
+
+	n := 10
+	for i := 0; i < n; i++ {
+		thingID, _ := thingRepo.Save(context.Background(), things.Thing{ID: idp.ID(), Owner: email, Key: idp.ID()})
+		if i%2 == 0 {
+			chanRepo.Connect(context.Background(), email, chanID, thingID, things.AccessPubSub)
+			continue
+		}
+		chanRepo.Connect(context.Background(), email, removedID, thingID, things.AccessPubSub)
+	}
+	chanRepo.Remove(context.Background(), email, removedID)
+
+	cases := map[string]struct {
+		owner  string
+		offset int
+		limit  int
+		size   int
+	}{
+		"existing owner, retrieve all":    {email, 0, n, n / 2},
+		"existing owner, retrieve subset": {email, 1, 3, 3},
+		"non-existing owner":              {wrong, 0, n, 0},
+	}
+
+	for desc, tc := range cases {
+		size := len(chanRepo.UnconnectedThings(context.Background(), tc.owner, tc.offset, tc.limit))
+		assert.Equal(t, tc.size, size, fmt.Sprintf("%s: expected %d got %d\n", desc, tc.size, size))
+	}
+}
+
+func TestThingRetrievalByConnectionState(t *testing.T) {
+	email := "thing-retrieval-by-connection-state@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+	chanRepo := postgres.NewChannelRepository(db, testLog)
+
+	chanID, _ := chanRepo.Save(context.Background(), things.Channel{ID: idp.ID(), Owner: email})
+
+	n := 4
+	for i := 0; i < n; i++ {
+		thingID, _ := thingRepo.Save(context.Background(), things.Thing{ID: idp.ID(), Owner: email, Key: idp.ID()})
+		chanRepo.Connect(context.Background(), email, chanID, thingID, things.AccessPubSub)
+		if i%2 == 0 {
+			chanRepo.Disconnect(context.Background(), email, chanID, thingID)
+		}
+	}
+
+	before := time.Now().UTC().Add(-time.Minute)
+	after := time.Now().UTC().Add(time.Minute)
+
+	cases := map[string]struct {
+		filter things.ConnectionFilter
+		size   int
+	}{
+		"retrieve connected things":                   {things.ConnectionFilter{State: things.StateConnected}, n / 2},
+		"retrieve recently disconnected things":       {things.ConnectionFilter{State: things.StateDisconnected, DisconnectedSince: before}, n / 2},
+		"retrieve connected and disconnected things":  {things.ConnectionFilter{State: things.StateAny, DisconnectedSince: before}, n},
+		"retrieve things ignoring old disconnections": {things.ConnectionFilter{State: things.StateAny, DisconnectedSince: after}, n / 2},
+	}
+
+	for desc, tc := range cases {
+		size := len(chanRepo.Things(context.Background(), email, chanID, 0, n, tc.filter))
 		assert.Equal(t, tc.size, size, fmt.Sprintf("%s: expected %d got %d\n", desc, tc.size, size))
 	}
 }
@@ -101,21 +377,210 @@ func TestChannelRemoval(t *testing.T) {
 	email := "channel-removal@example.com"
 	idp := uuid.New()
 	chanRepo := postgres.NewChannelRepository(db, testLog)
-	chanID, _ := chanRepo.Save(things.Channel{ID: idp.ID(), Owner: email})
+	chanID, _ := chanRepo.Save(context.Background(), things.Channel{ID: idp.ID(), Owner: email})
 
 	// show that the removal works the same for both existing and non-existing
 	// (removed) channel
 	for i := 0; i < 2; i++ {
-		if err := chanRepo.Remove(email, chanID); err != nil {
+		if err := chanRepo.Remove(context.Background(), email, chanID); err != nil {
 			t.Fatalf("#%d: failed to remove channel due to: %s", i, err)
 		}
 
-		if _, err := chanRepo.One(email, chanID); err != things.ErrNotFound {
+		if _, err := chanRepo.One(context.Background(), email, chanID); err != things.ErrNotFound {
 			t.Fatalf("#%d: expected %s got %s", i, things.ErrNotFound, err)
 		}
 	}
 }
 
+func TestChannelRemovalDisconnects(t *testing.T) {
+	email := "channel-removal-disconnects@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+	thing := things.Thing{
+		ID:    idp.ID(),
+		Owner: email,
+		Key:   idp.ID(),
+	}
+	thingRepo.Save(context.Background(), thing)
+
+	chanRepo := postgres.NewChannelRepository(db, testLog)
+	chanID, _ := chanRepo.Save(context.Background(), things.Channel{ID: idp.ID(), Owner: email})
+	chanRepo.Connect(context.Background(), email, chanID, thing.ID, things.AccessPubSub)
+
+	err := chanRepo.Remove(context.Background(), email, chanID)
+	assert.Nil(t, err, fmt.Sprintf("remove channel with connected thing: unexpected error %s\n", err))
+
+	_, err = chanRepo.HasThing(context.Background(), chanID, thing.Key, things.AccessPubSub)
+	hasAccess := err == nil
+	assert.False(t, hasAccess, fmt.Sprintf("thing connected to removed channel: expected %t got %t\n", false, hasAccess))
+
+	chs := chanRepo.AllByThing(context.Background(), email, thing.ID, 0, 10)
+	assert.Empty(t, chs, fmt.Sprintf("channels of disconnected thing: expected none got %v\n", chs))
+}
+
+func TestChannelRestore(t *testing.T) {
+	email := "channel-restore@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+	thing := things.Thing{
+		ID:    idp.ID(),
+		Owner: email,
+		Key:   idp.ID(),
+	}
+	thingRepo.Save(context.Background(), thing)
+
+	chanRepo := postgres.NewChannelRepository(db, testLog)
+	chanID, _ := chanRepo.Save(context.Background(), things.Channel{ID: idp.ID(), Owner: email})
+	chanRepo.Connect(context.Background(), email, chanID, thing.ID, things.AccessPubSub)
+	chanRepo.Remove(context.Background(), email, chanID)
+
+	cases := []struct {
+		desc  string
+		owner string
+		id    string
+		err   error
+	}{
+		{"existing removed channel", email, chanID, nil},
+		{"existing restored channel", email, chanID, things.ErrNotFound},
+		{"non-existing channel with existing user", email, wrong, things.ErrNotFound},
+		{"non-existing channel with non-existing user", wrong, wrong, things.ErrNotFound},
+	}
+
+	for _, tc := range cases {
+		err := chanRepo.Restore(context.Background(), tc.owner, tc.id)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+
+	_, err := chanRepo.One(context.Background(), email, chanID)
+	assert.Nil(t, err, fmt.Sprintf("retrieve restored channel: unexpected error %s\n", err))
+
+	id, err := chanRepo.HasThing(context.Background(), chanID, thing.Key, things.AccessPubSub)
+	assert.Nil(t, err, fmt.Sprintf("access restored channel: unexpected error %s\n", err))
+	assert.Equal(t, thing.ID, id, fmt.Sprintf("access restored channel: expected %s got %s\n", thing.ID, id))
+}
+
+func TestChannelWebhook(t *testing.T) {
+	email := "channel-webhook@example.com"
+	idp := uuid.New()
+	chanRepo := postgres.NewChannelRepository(db, testLog)
+
+	hook := &things.Webhook{URL: "https://example.com/hook", Secret: "secret"}
+	channel := things.Channel{ID: idp.ID(), Owner: email, Webhook: hook}
+	chanRepo.Save(context.Background(), channel)
+
+	ch, err := chanRepo.One(context.Background(), email, channel.ID)
+	assert.Nil(t, err, fmt.Sprintf("retrieve channel with webhook: unexpected error %s\n", err))
+	assert.Equal(t, hook, ch.Webhook, fmt.Sprintf("retrieve channel with webhook: expected %v got %v\n", hook, ch.Webhook))
+
+	channel.Webhook = nil
+	err = chanRepo.Update(context.Background(), channel)
+	assert.Nil(t, err, fmt.Sprintf("remove channel's webhook: unexpected error %s\n", err))
+
+	ch, err = chanRepo.One(context.Background(), email, channel.ID)
+	assert.Nil(t, err, fmt.Sprintf("retrieve channel without webhook: unexpected error %s\n", err))
+	assert.Nil(t, ch.Webhook, fmt.Sprintf("retrieve channel without webhook: expected no webhook got %v\n", ch.Webhook))
+}
+
+func TestChannelRemoveAll(t *testing.T) {
+	email := "channel-removal-all@example.com"
+	otherEmail := "channel-removal-all-other@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+	chanRepo := postgres.NewChannelRepository(db, testLog)
+
+	thing := things.Thing{ID: idp.ID(), Owner: email, Key: idp.ID()}
+	thingRepo.Save(context.Background(), thing)
+
+	n := 3
+	chanID := ""
+	for _, owner := range []string{email, otherEmail} {
+		for i := 0; i < n; i++ {
+			chanID, _ = chanRepo.Save(context.Background(), things.Channel{ID: idp.ID(), Owner: owner})
+		}
+	}
+	otherChanID := chanID
+
+	chanID, _ = chanRepo.Save(context.Background(), things.Channel{ID: idp.ID(), Owner: email})
+	chanRepo.Connect(context.Background(), email, chanID, thing.ID, things.AccessPubSub)
+
+	err := chanRepo.RemoveAll(context.Background(), email)
+	assert.Nil(t, err, fmt.Sprintf("remove all channels: unexpected error %s\n", err))
+
+	cases := map[string]struct {
+		owner string
+		count int
+	}{
+		"owner of removed channels": {email, 0},
+		"other owner":               {otherEmail, n},
+	}
+
+	for desc, tc := range cases {
+		count := chanRepo.Count(context.Background(), tc.owner)
+		assert.Equal(t, tc.count, count, fmt.Sprintf("%s: expected %d channels got %d\n", desc, tc.count, count))
+	}
+
+	_, err = chanRepo.HasThing(context.Background(), chanID, thing.Key, things.AccessPubSub)
+	hasAccess := err == nil
+	assert.False(t, hasAccess, fmt.Sprintf("thing connected to removed channel: expected %t got %t\n", false, hasAccess))
+
+	_, err = chanRepo.One(context.Background(), otherEmail, otherChanID)
+	assert.Nil(t, err, fmt.Sprintf("retrieve other owner's channel: unexpected error %s\n", err))
+
+	_, err = chanRepo.One(context.Background(), email, chanID)
+	assert.Equal(t, things.ErrNotFound, err, fmt.Sprintf("retrieve removed channel: expected %s got %s\n", things.ErrNotFound, err))
+
+	// removed channels are kept until purged, so they can be restored
+	err = chanRepo.Restore(context.Background(), email, chanID)
+	assert.Nil(t, err, fmt.Sprintf("restore removed channel: unexpected error %s\n", err))
+
+	ch, err := chanRepo.One(context.Background(), email, chanID)
+	assert.Nil(t, err, fmt.Sprintf("retrieve restored channel: unexpected error %s\n", err))
+	assert.Empty(t, ch.Things, fmt.Sprintf("retrieve restored channel: expected no connected things got %d\n", len(ch.Things)))
+}
+
+func TestChannelOwnerChange(t *testing.T) {
+	email := "channel-owner-change@example.com"
+	newOwner := "channel-new-owner@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+	thing := things.Thing{
+		ID:    idp.ID(),
+		Owner: email,
+		Key:   idp.ID(),
+	}
+	thingRepo.Save(context.Background(), thing)
+
+	chanRepo := postgres.NewChannelRepository(db, testLog)
+	chanID, _ := chanRepo.Save(context.Background(), things.Channel{ID: idp.ID(), Owner: email})
+	chanRepo.Connect(context.Background(), email, chanID, thing.ID, things.AccessPubSub)
+
+	cases := []struct {
+		desc  string
+		owner string
+		id    string
+		err   error
+	}{
+		{"change owner of existing channel", email, chanID, nil},
+		{"change owner of transferred channel", email, chanID, things.ErrNotFound},
+		{"change owner of non-existing channel", email, wrong, things.ErrNotFound},
+	}
+
+	for _, tc := range cases {
+		err := chanRepo.ChangeOwner(context.Background(), tc.owner, tc.id, newOwner)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+
+	_, err := chanRepo.One(context.Background(), email, chanID)
+	assert.Equal(t, things.ErrNotFound, err, fmt.Sprintf("retrieve channel as old owner: expected %s got %s\n", things.ErrNotFound, err))
+
+	_, err = chanRepo.One(context.Background(), newOwner, chanID)
+	assert.Nil(t, err, fmt.Sprintf("retrieve channel as new owner: unexpected error %s\n", err))
+
+	_, err = chanRepo.HasThing(context.Background(), chanID, thing.Key, things.AccessPubSub)
+	hasAccess := err == nil
+	assert.False(t, hasAccess, fmt.Sprintf("thing connected to transferred channel: expected %t got %t\n", false, hasAccess))
+}
+
 func TestConnect(t *testing.T) {
 	email := "channel-connect@example.com"
 	idp := uuid.New()
@@ -126,10 +591,10 @@ func TestConnect(t *testing.T) {
 		Owner: email,
 		Key:   idp.ID(),
 	}
-	thingRepo.Save(thing)
+	thingRepo.Save(context.Background(), thing)
 
 	chanRepo := postgres.NewChannelRepository(db, testLog)
-	chanID, _ := chanRepo.Save(things.Channel{ID: idp.ID(), Owner: email})
+	chanID, _ := chanRepo.Save(context.Background(), things.Channel{ID: idp.ID(), Owner: email})
 
 	cases := []struct {
 		desc    string
@@ -146,9 +611,89 @@ func TestConnect(t *testing.T) {
 	}
 
 	for _, tc := range cases {
-		err := chanRepo.Connect(tc.owner, tc.chanID, tc.thingID)
+		_, err := chanRepo.Connect(context.Background(), tc.owner, tc.chanID, tc.thingID, things.AccessPubSub)
 		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
 	}
+
+	first, _ := chanRepo.Connect(context.Background(), email, chanID, thing.ID, things.AccessPubSub)
+	second, _ := chanRepo.Connect(context.Background(), email, chanID, thing.ID, things.AccessPubSub)
+	assert.False(t, first.ConnectedAt.IsZero(), fmt.Sprintf("connect thing: expected connection time to be set\n"))
+	assert.Equal(t, first, second, fmt.Sprintf("reconnect thing: expected %v got %v\n", first, second))
+}
+
+func TestConnectMany(t *testing.T) {
+	email := "channel-connect-many@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+
+	thing := things.Thing{
+		ID:    idp.ID(),
+		Owner: email,
+		Key:   idp.ID(),
+	}
+	thingRepo.Save(context.Background(), thing)
+
+	chanRepo := postgres.NewChannelRepository(db, testLog)
+	ch1, _ := chanRepo.Save(context.Background(), things.Channel{ID: idp.ID(), Owner: email})
+	ch2, _ := chanRepo.Save(context.Background(), things.Channel{ID: idp.ID(), Owner: email})
+
+	cases := []struct {
+		desc    string
+		owner   string
+		thingID string
+		chanIDs []string
+		err     error
+	}{
+		{"non-existing channel", email, thing.ID, []string{ch1, wrong}, things.ErrNotFound},
+		{"non-existing thing", email, wrong, []string{ch1, ch2}, things.ErrNotFound},
+		{"with non-existing user", wrong, thing.ID, []string{ch1, ch2}, things.ErrNotFound},
+		{"existing user, channels and thing", email, thing.ID, []string{ch1, ch2}, nil},
+		{"connected channels and thing", email, thing.ID, []string{ch1, ch2}, nil},
+	}
+
+	for _, tc := range cases {
+		err := chanRepo.ConnectMany(context.Background(), tc.owner, tc.thingID, tc.chanIDs)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+
+	chs := chanRepo.AllByThing(context.Background(), email, thing.ID, 0, 10)
+	assert.Equal(t, 2, len(chs), fmt.Sprintf("retrieve connected channels: expected %d got %d\n", 2, len(chs)))
+}
+
+func TestConnectThings(t *testing.T) {
+	email := "channel-connect-things@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+
+	th1 := things.Thing{ID: idp.ID(), Owner: email, Key: idp.ID()}
+	th2 := things.Thing{ID: idp.ID(), Owner: email, Key: idp.ID()}
+	thingRepo.Save(context.Background(), th1)
+	thingRepo.Save(context.Background(), th2)
+
+	chanRepo := postgres.NewChannelRepository(db, testLog)
+	chanID, _ := chanRepo.Save(context.Background(), things.Channel{ID: idp.ID(), Owner: email})
+
+	cases := []struct {
+		desc     string
+		owner    string
+		chanID   string
+		thingIDs []string
+		err      error
+	}{
+		{"non-existing thing", email, chanID, []string{th1.ID, wrong}, things.ErrNotFound},
+		{"non-existing channel", email, wrong, []string{th1.ID, th2.ID}, things.ErrNotFound},
+		{"with non-existing user", wrong, chanID, []string{th1.ID, th2.ID}, things.ErrNotFound},
+		{"existing user, channel and things", email, chanID, []string{th1.ID, th2.ID}, nil},
+		{"connected channel and things", email, chanID, []string{th1.ID, th2.ID}, nil},
+	}
+
+	for _, tc := range cases {
+		err := chanRepo.ConnectThings(context.Background(), tc.owner, tc.chanID, tc.thingIDs)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+
+	ths := chanRepo.Things(context.Background(), email, chanID, 0, 10, things.ConnectionFilter{})
+	assert.Equal(t, 2, len(ths), fmt.Sprintf("retrieve connected things: expected %d got %d\n", 2, len(ths)))
 }
 
 func TestDisconnect(t *testing.T) {
@@ -160,29 +705,236 @@ func TestDisconnect(t *testing.T) {
 		Owner: email,
 		Key:   idp.ID(),
 	}
-	thingRepo.Save(thing)
+	thingRepo.Save(context.Background(), thing)
 
 	chanRepo := postgres.NewChannelRepository(db, testLog)
-	chanID, _ := chanRepo.Save(things.Channel{ID: idp.ID(), Owner: email})
-	chanRepo.Connect(email, chanID, thing.ID)
+	chanID, _ := chanRepo.Save(context.Background(), things.Channel{ID: idp.ID(), Owner: email})
+	chanRepo.Connect(context.Background(), email, chanID, thing.ID, things.AccessPubSub)
 
 	cases := []struct {
 		desc    string
 		owner   string
 		chanID  string
 		thingID string
-		err     error
+		removed bool
 	}{
-		{"connected thing", email, chanID, thing.ID, nil},
-		{"non-connected thing", email, chanID, thing.ID, things.ErrNotFound},
-		{"non-existing user", wrong, chanID, thing.ID, things.ErrNotFound},
-		{"non-existing channel", email, wrong, thing.ID, things.ErrNotFound},
-		{"non-existing thing", email, chanID, wrong, things.ErrNotFound},
+		{"connected thing", email, chanID, thing.ID, true},
+		{"non-connected thing", email, chanID, thing.ID, false},
+		{"non-existing user", wrong, chanID, thing.ID, false},
+		{"non-existing channel", email, wrong, thing.ID, false},
+		{"non-existing thing", email, chanID, wrong, false},
 	}
 
 	for _, tc := range cases {
-		err := chanRepo.Disconnect(tc.owner, tc.chanID, tc.thingID)
-		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		removed, err := chanRepo.Disconnect(context.Background(), tc.owner, tc.chanID, tc.thingID)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s\n", tc.desc, err))
+		assert.Equal(t, tc.removed, removed, fmt.Sprintf("%s: expected removed %t got %t\n", tc.desc, tc.removed, removed))
+	}
+}
+
+func TestDisconnectAll(t *testing.T) {
+	email := "channel-disconnect-all@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+	thing := things.Thing{
+		ID:    idp.ID(),
+		Owner: email,
+		Key:   idp.ID(),
+	}
+	thingRepo.Save(context.Background(), thing)
+
+	chanRepo := postgres.NewChannelRepository(db, testLog)
+	chanIDs := []string{}
+	for i := 0; i < 3; i++ {
+		chanID, _ := chanRepo.Save(context.Background(), things.Channel{ID: idp.ID(), Owner: email})
+		chanRepo.Connect(context.Background(), email, chanID, thing.ID, things.AccessPubSub)
+		chanIDs = append(chanIDs, chanID)
+	}
+
+	err := chanRepo.DisconnectAll(context.Background(), email, thing.ID)
+	assert.Nil(t, err, fmt.Sprintf("disconnect thing from all channels: unexpected error %s\n", err))
+
+	for _, chanID := range chanIDs {
+		_, err := chanRepo.HasThing(context.Background(), chanID, thing.Key, things.AccessPubSub)
+		hasAccess := err == nil
+		assert.False(t, hasAccess, fmt.Sprintf("disconnected thing: expected %t got %t\n", false, hasAccess))
+	}
+
+	err = chanRepo.DisconnectAll(context.Background(), email, thing.ID)
+	assert.Nil(t, err, fmt.Sprintf("disconnect non-connected thing: unexpected error %s\n", err))
+}
+
+func TestRebind(t *testing.T) {
+	email := "channel-rebind@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+	chanRepo := postgres.NewChannelRepository(db, testLog)
+
+	from := things.Thing{ID: idp.ID(), Owner: email, Key: idp.ID()}
+	to := things.Thing{ID: idp.ID(), Owner: email, Key: idp.ID()}
+	thingRepo.Save(context.Background(), from)
+	thingRepo.Save(context.Background(), to)
+
+	chanIDs := []string{}
+	for i := 0; i < 3; i++ {
+		chanID, _ := chanRepo.Save(context.Background(), things.Channel{ID: idp.ID(), Owner: email})
+		chanRepo.Connect(context.Background(), email, chanID, from.ID, things.AccessPubSub)
+		chanIDs = append(chanIDs, chanID)
+	}
+	chanRepo.Connect(context.Background(), email, chanIDs[0], to.ID, things.AccessPubSub)
+
+	err := chanRepo.Rebind(context.Background(), email, from.ID, to.ID)
+	assert.Nil(t, err, fmt.Sprintf("rebind connections: unexpected error %s\n", err))
+
+	for _, chanID := range chanIDs {
+		assert.False(t, chanRepo.HasConnection(context.Background(), email, chanID, from.ID), fmt.Sprintf("rebind connections: expected source thing to be disconnected from %s\n", chanID))
+		assert.True(t, chanRepo.HasConnection(context.Background(), email, chanID, to.ID), fmt.Sprintf("rebind connections: expected target thing to be connected to %s\n", chanID))
+	}
+
+	err = chanRepo.Rebind(context.Background(), email, from.ID, to.ID)
+	assert.Nil(t, err, fmt.Sprintf("rebind connections of disconnected thing: unexpected error %s\n", err))
+}
+
+func TestDisconnectAllThings(t *testing.T) {
+	email := "channel-disconnect-all-things@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+	chanRepo := postgres.NewChannelRepository(db, testLog)
+	chanID, _ := chanRepo.Save(context.Background(), things.Channel{ID: idp.ID(), Owner: email})
+
+	ths := []things.Thing{}
+	for i := 0; i < 3; i++ {
+		thing := things.Thing{
+			ID:    idp.ID(),
+			Owner: email,
+			Key:   idp.ID(),
+		}
+		thingRepo.Save(context.Background(), thing)
+		chanRepo.Connect(context.Background(), email, chanID, thing.ID, things.AccessPubSub)
+		ths = append(ths, thing)
+	}
+
+	err := chanRepo.DisconnectAllThings(context.Background(), email)
+	assert.Nil(t, err, fmt.Sprintf("disconnect all things: unexpected error %s\n", err))
+
+	for _, thing := range ths {
+		_, err := chanRepo.HasThing(context.Background(), chanID, thing.Key, things.AccessPubSub)
+		hasAccess := err == nil
+		assert.False(t, hasAccess, fmt.Sprintf("disconnected thing: expected %t got %t\n", false, hasAccess))
+	}
+}
+
+func TestHasConnection(t *testing.T) {
+	email := "channel-connection-check@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+	thing := things.Thing{
+		ID:    idp.ID(),
+		Owner: email,
+		Key:   idp.ID(),
+	}
+	thingRepo.Save(context.Background(), thing)
+
+	chanRepo := postgres.NewChannelRepository(db, testLog)
+	chanID, _ := chanRepo.Save(context.Background(), things.Channel{ID: idp.ID(), Owner: email})
+	chanRepo.Connect(context.Background(), email, chanID, thing.ID, things.AccessPubSub)
+	otherID, _ := chanRepo.Save(context.Background(), things.Channel{ID: idp.ID(), Owner: email})
+
+	cases := map[string]struct {
+		owner     string
+		chanID    string
+		thingID   string
+		connected bool
+	}{
+		"connected thing":      {email, chanID, thing.ID, true},
+		"non-connected thing":  {email, otherID, thing.ID, false},
+		"non-existing user":    {wrong, chanID, thing.ID, false},
+		"non-existing channel": {email, wrong, thing.ID, false},
+		"non-existing thing":   {email, chanID, wrong, false},
+	}
+
+	for desc, tc := range cases {
+		connected := chanRepo.HasConnection(context.Background(), tc.owner, tc.chanID, tc.thingID)
+		assert.Equal(t, tc.connected, connected, fmt.Sprintf("%s: expected %t got %t\n", desc, tc.connected, connected))
+	}
+}
+
+func TestConnections(t *testing.T) {
+	email := "channel-connections@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+	thing := things.Thing{
+		ID:    idp.ID(),
+		Owner: email,
+		Key:   idp.ID(),
+	}
+	thingRepo.Save(context.Background(), thing)
+
+	chanRepo := postgres.NewChannelRepository(db, testLog)
+	ids := []string{}
+	for i := 0; i < 2; i++ {
+		chanID, _ := chanRepo.Save(context.Background(), things.Channel{ID: idp.ID(), Owner: email})
+		chanRepo.Connect(context.Background(), email, chanID, thing.ID, things.AccessPubSub)
+		ids = append(ids, chanID)
+	}
+	chanRepo.Save(context.Background(), things.Channel{ID: idp.ID(), Owner: email})
+	sort.Strings(ids)
+
+	cases := map[string]struct {
+		owner   string
+		thingID string
+		ids     []string
+	}{
+		"connected thing":    {email, thing.ID, ids},
+		"non-existing user":  {wrong, thing.ID, []string{}},
+		"non-existing thing": {email, wrong, []string{}},
+	}
+
+	for desc, tc := range cases {
+		ids, err := chanRepo.Connections(context.Background(), tc.owner, tc.thingID)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s\n", desc, err))
+		assert.Equal(t, tc.ids, ids, fmt.Sprintf("%s: expected %v got %v\n", desc, tc.ids, ids))
+	}
+}
+
+func TestChannelConnections(t *testing.T) {
+	email := "channel-connections-by-channel@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+	chanRepo := postgres.NewChannelRepository(db, testLog)
+
+	chanID, _ := chanRepo.Save(context.Background(), things.Channel{ID: idp.ID(), Owner: email})
+	emptyID, _ := chanRepo.Save(context.Background(), things.Channel{ID: idp.ID(), Owner: email})
+
+	conns := []things.Connection{}
+	for i := 0; i < 3; i++ {
+		thing := things.Thing{ID: idp.ID(), Owner: email, Key: idp.ID()}
+		thingRepo.Save(context.Background(), thing)
+		conn, _ := chanRepo.Connect(context.Background(), email, chanID, thing.ID, things.AccessPubSub)
+		conns = append(conns, conn)
+	}
+
+	cases := map[string]struct {
+		owner  string
+		chanID string
+		conns  []things.Connection
+	}{
+		"channel with connections":    {email, chanID, conns},
+		"channel without connections": {email, emptyID, []things.Connection{}},
+		"non-existing channel":        {email, wrong, []things.Connection{}},
+		"non-existing user":           {wrong, chanID, []things.Connection{}},
+	}
+
+	for desc, tc := range cases {
+		conns, err := chanRepo.ChannelConnections(context.Background(), tc.owner, tc.chanID)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s\n", desc, err))
+		if !assert.Equal(t, len(tc.conns), len(conns), fmt.Sprintf("%s: expected %d connections got %d\n", desc, len(tc.conns), len(conns))) {
+			continue
+		}
+		for i, conn := range conns {
+			assert.Equal(t, tc.conns[i].ThingID, conn.ThingID, fmt.Sprintf("%s: expected thing %s got %s\n", desc, tc.conns[i].ThingID, conn.ThingID))
+			assert.True(t, tc.conns[i].ConnectedAt.Equal(conn.ConnectedAt), fmt.Sprintf("%s: expected connection at %s got %s\n", desc, tc.conns[i].ConnectedAt, conn.ConnectedAt))
+		}
 	}
 }
 
@@ -195,25 +947,71 @@ func TestHasThing(t *testing.T) {
 		Owner: email,
 		Key:   idp.ID(),
 	}
-	thingRepo.Save(thing)
+	thingRepo.Save(context.Background(), thing)
+	pubThing := things.Thing{
+		ID:    idp.ID(),
+		Owner: email,
+		Key:   idp.ID(),
+	}
+	thingRepo.Save(context.Background(), pubThing)
 
 	chanRepo := postgres.NewChannelRepository(db, testLog)
-	chanID, _ := chanRepo.Save(things.Channel{ID: idp.ID(), Owner: email})
-	chanRepo.Connect(email, chanID, thing.ID)
+	chanID, _ := chanRepo.Save(context.Background(), things.Channel{ID: idp.ID(), Owner: email})
+	chanRepo.Connect(context.Background(), email, chanID, thing.ID, things.AccessPubSub)
+	chanRepo.Connect(context.Background(), email, chanID, pubThing.ID, things.AccessPub)
 
 	cases := map[string]struct {
 		chanID    string
 		key       string
+		mode      things.AccessMode
 		hasAccess bool
 	}{
-		"thing that has access":                {chanID, thing.Key, true},
-		"thing without access":                 {chanID, wrong, false},
-		"check access to non-existing channel": {wrong, thing.Key, false},
+		"thing that has access":                  {chanID, thing.Key, things.AccessPubSub, true},
+		"thing without access":                   {chanID, wrong, things.AccessPubSub, false},
+		"check access to non-existing channel":   {wrong, thing.Key, things.AccessPubSub, false},
+		"pub-only thing that publishes":          {chanID, pubThing.Key, things.AccessPub, true},
+		"pub-only thing that subscribes":         {chanID, pubThing.Key, things.AccessSub, false},
+		"pub-only thing that publishes and subs": {chanID, pubThing.Key, things.AccessPubSub, false},
 	}
 
 	for desc, tc := range cases {
-		_, err := chanRepo.HasThing(tc.chanID, tc.key)
+		_, err := chanRepo.HasThing(context.Background(), tc.chanID, tc.key, tc.mode)
 		hasAccess := err == nil
 		assert.Equal(t, tc.hasAccess, hasAccess, fmt.Sprintf("%s: expected %t got %t\n", desc, tc.hasAccess, hasAccess))
 	}
+
+	thingRepo.UpdateStatus(context.Background(), email, thing.ID, things.StatusDisabled)
+	_, err := chanRepo.HasThing(context.Background(), chanID, thing.Key, things.AccessPubSub)
+	hasAccess := err == nil
+	assert.False(t, hasAccess, fmt.Sprintf("disabled thing: expected %t got %t\n", false, hasAccess))
+}
+
+func TestChannelOwner(t *testing.T) {
+	email := "channel-owner@example.com"
+	idp := uuid.New()
+	chanRepo := postgres.NewChannelRepository(db, testLog)
+
+	chanID, _ := chanRepo.Save(context.Background(), things.Channel{ID: idp.ID(), Owner: email})
+
+	cases := map[string]struct {
+		chanID string
+		owner  string
+		err    error
+	}{
+		"retrieve owner of existing channel":     {chanID, email, nil},
+		"retrieve owner of non-existing channel": {wrong, "", things.ErrNotFound},
+	}
+
+	for desc, tc := range cases {
+		owner, err := chanRepo.Owner(context.Background(), tc.chanID)
+		assert.Equal(t, tc.owner, owner, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.owner, owner))
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+}
+
+func TestChannelRepositoryPing(t *testing.T) {
+	chanRepo := postgres.NewChannelRepository(db, testLog)
+
+	err := chanRepo.Ping(context.Background())
+	assert.Nil(t, err, fmt.Sprintf("ping channel repository: unexpected error %s\n", err))
 }
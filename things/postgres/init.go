@@ -64,6 +64,182 @@ func migrateDB(db *sql.DB) error {
 					"DROP TABLE channels",
 				},
 			},
+			&migrate.Migration{
+				Id: "things_2",
+				Up: []string{
+					"ALTER TABLE things ADD COLUMN metadata JSONB",
+				},
+				Down: []string{
+					"ALTER TABLE things DROP COLUMN metadata",
+				},
+			},
+			&migrate.Migration{
+				Id: "things_3",
+				Up: []string{
+					"ALTER TABLE things ADD COLUMN deleted BOOLEAN NOT NULL DEFAULT FALSE",
+				},
+				Down: []string{
+					"ALTER TABLE things DROP COLUMN deleted",
+				},
+			},
+			&migrate.Migration{
+				Id: "things_4",
+				Up: []string{
+					"ALTER TABLE things ADD COLUMN status VARCHAR(10) NOT NULL DEFAULT 'enabled'",
+				},
+				Down: []string{
+					"ALTER TABLE things DROP COLUMN status",
+				},
+			},
+			&migrate.Migration{
+				Id: "things_5",
+				Up: []string{
+					"ALTER TABLE things ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT NOW()",
+					"ALTER TABLE channels ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT NOW()",
+				},
+				Down: []string{
+					"ALTER TABLE things DROP COLUMN created_at",
+					"ALTER TABLE channels DROP COLUMN created_at",
+				},
+			},
+			&migrate.Migration{
+				Id: "things_6",
+				Up: []string{
+					"ALTER TABLE things ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT NOW()",
+					"ALTER TABLE channels ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT NOW()",
+				},
+				Down: []string{
+					"ALTER TABLE things DROP COLUMN updated_at",
+					"ALTER TABLE channels DROP COLUMN updated_at",
+				},
+			},
+			&migrate.Migration{
+				Id: "things_7",
+				Up: []string{
+					"ALTER TABLE things ADD COLUMN external_id VARCHAR(254)",
+					"CREATE UNIQUE INDEX things_external_id ON things (owner, external_id)",
+				},
+				Down: []string{
+					"DROP INDEX things_external_id",
+					"ALTER TABLE things DROP COLUMN external_id",
+				},
+			},
+			&migrate.Migration{
+				Id: "things_8",
+				Up: []string{
+					"ALTER TABLE channels ADD COLUMN metadata JSONB",
+				},
+				Down: []string{
+					"ALTER TABLE channels DROP COLUMN metadata",
+				},
+			},
+			&migrate.Migration{
+				Id: "things_9",
+				Up: []string{
+					"ALTER TABLE connections ADD COLUMN connected_at TIMESTAMP NOT NULL DEFAULT NOW()",
+				},
+				Down: []string{
+					"ALTER TABLE connections DROP COLUMN connected_at",
+				},
+			},
+			&migrate.Migration{
+				Id: "things_10",
+				Up: []string{
+					`CREATE TABLE disconnections (
+						channel_id      CHAR(36),
+						channel_owner   VARCHAR(254),
+						thing_id        CHAR(36),
+						thing_owner     VARCHAR(254),
+						disconnected_at TIMESTAMP NOT NULL,
+						FOREIGN KEY (channel_id, channel_owner) REFERENCES channels (id, owner) ON DELETE CASCADE ON UPDATE CASCADE,
+						FOREIGN KEY (thing_id, thing_owner) REFERENCES things (id, owner) ON DELETE CASCADE ON UPDATE CASCADE,
+						PRIMARY KEY (channel_id, channel_owner, thing_id, thing_owner)
+					)`,
+				},
+				Down: []string{
+					"DROP TABLE disconnections",
+				},
+			},
+			&migrate.Migration{
+				Id: "things_11",
+				Up: []string{
+					"ALTER TABLE channels ADD COLUMN webhook JSONB",
+				},
+				Down: []string{
+					"ALTER TABLE channels DROP COLUMN webhook",
+				},
+			},
+			&migrate.Migration{
+				Id: "things_12",
+				Up: []string{
+					"ALTER TABLE things ADD COLUMN tags TEXT[]",
+				},
+				Down: []string{
+					"ALTER TABLE things DROP COLUMN tags",
+				},
+			},
+			{
+				Id: "things_13",
+				Up: []string{
+					"ALTER TABLE things ADD COLUMN version BIGINT NOT NULL DEFAULT 1",
+				},
+				Down: []string{
+					"ALTER TABLE things DROP COLUMN version",
+				},
+			},
+			{
+				Id: "things_14",
+				Up: []string{
+					"ALTER TABLE channels ADD COLUMN deleted_at TIMESTAMP",
+				},
+				Down: []string{
+					"ALTER TABLE channels DROP COLUMN deleted_at",
+				},
+			},
+			{
+				Id: "things_15",
+				Up: []string{
+					"ALTER TABLE connections ADD COLUMN mode VARCHAR(6) NOT NULL DEFAULT 'pubsub'",
+				},
+				Down: []string{
+					"ALTER TABLE connections DROP COLUMN mode",
+				},
+			},
+			{
+				Id: "things_16",
+				Up: []string{
+					"ALTER TABLE things ADD COLUMN deleted_at TIMESTAMP",
+					"UPDATE things SET deleted_at = NOW() AT TIME ZONE 'UTC' WHERE deleted",
+				},
+				Down: []string{
+					"ALTER TABLE things DROP COLUMN deleted_at",
+				},
+			},
+			{
+				Id: "things_17",
+				Up: []string{
+					"DROP INDEX things_external_id",
+					"CREATE UNIQUE INDEX things_external_id ON things (owner, external_id) WHERE NOT deleted",
+				},
+				Down: []string{
+					"DROP INDEX things_external_id",
+					"CREATE UNIQUE INDEX things_external_id ON things (owner, external_id)",
+				},
+			},
+			{
+				Id: "things_18",
+				Up: []string{
+					"DROP INDEX things_external_id",
+					"CREATE UNIQUE INDEX things_external_id ON things (owner, external_id) WHERE deleted_at IS NULL",
+					"ALTER TABLE things DROP COLUMN deleted",
+				},
+				Down: []string{
+					"ALTER TABLE things ADD COLUMN deleted BOOLEAN NOT NULL DEFAULT FALSE",
+					"UPDATE things SET deleted = TRUE WHERE deleted_at IS NOT NULL",
+					"DROP INDEX things_external_id",
+					"CREATE UNIQUE INDEX things_external_id ON things (owner, external_id) WHERE NOT deleted",
+				},
+			},
 		},
 	}
 
@@ -0,0 +1,34 @@
+package postgres
+
+import (
+	"fmt"
+
+	"github.com/mainflux/mainflux/things"
+)
+
+var columns = map[string]string{
+	things.OrderID:      "id",
+	things.OrderName:    "name",
+	things.OrderCreated: "created_at",
+}
+
+// orderBy builds ORDER BY clause out of the provided sorting specification.
+// Only whitelisted columns are used, so the result is safe to be embedded
+// into the query. Identifiers are used to break ties.
+func orderBy(sorting things.Sorting) string {
+	col, ok := columns[sorting.Order]
+	if !ok {
+		col = columns[things.OrderID]
+	}
+
+	dir := "ASC"
+	if sorting.Dir == things.DirDesc {
+		dir = "DESC"
+	}
+
+	if col == columns[things.OrderID] {
+		return fmt.Sprintf("ORDER BY id %s", dir)
+	}
+
+	return fmt.Sprintf("ORDER BY %s %s, id %s", col, dir, dir)
+}
@@ -1,16 +1,29 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
-	_ "github.com/lib/pq" // required for DB access
+	"github.com/lib/pq"
 	"github.com/mainflux/mainflux/logger"
 	"github.com/mainflux/mainflux/things"
 )
 
 var _ things.ThingRepository = (*thingRepository)(nil)
 
+// likeEscaper escapes characters that have special meaning in LIKE patterns,
+// so that searched values are always matched literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// thingFilterCond matches the things against all of the filter criteria,
+// whose parameters start at $3 and are provided by filterParams.
+const thingFilterCond = `($3 = '' OR COALESCE(name, '') ILIKE $4) AND ($5 = '' OR type = $5) AND ($6 = '' OR status = $6)
+	AND ($7 = '' OR $7 = ANY(tags)) AND ($8 = '' OR metadata ->> $8 = $9)`
+
 type thingRepository struct {
 	db  *sql.DB
 	log logger.Logger
@@ -22,20 +35,117 @@ func NewThingRepository(db *sql.DB, log logger.Logger) things.ThingRepository {
 	return &thingRepository{db: db, log: log}
 }
 
-func (tr thingRepository) Save(thing things.Thing) (string, error) {
-	q := `INSERT INTO things (id, owner, type, name, key, payload) VALUES ($1, $2, $3, $4, $5, $6)`
+func (tr thingRepository) Save(ctx context.Context, thing things.Thing) (string, error) {
+	q := `INSERT INTO things (id, external_id, owner, type, name, key, payload, metadata, tags, status, created_at, updated_at) VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+
+	metadata, err := toJSON(thing.Metadata)
+	if err != nil {
+		return "", err
+	}
 
-	if _, err := tr.db.Exec(q, thing.ID, thing.Owner, thing.Type, thing.Name, thing.Key, thing.Payload); err != nil {
+	if _, err := tr.db.ExecContext(ctx, q, thing.ID, thing.ExternalID, thing.Owner, thing.Type, thing.Name, thing.Key, thing.Payload, metadata, pq.Array(thing.Tags), thing.Status, thing.CreatedAt, thing.UpdatedAt); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && errDuplicate == pqErr.Code.Name() {
+			return "", things.ErrConflict
+		}
 		return "", err
 	}
 
 	return thing.ID, nil
 }
 
-func (tr thingRepository) Update(thing things.Thing) error {
-	q := `UPDATE things SET name = $1, payload = $2 WHERE owner = $3 AND id = $4;`
+func (tr thingRepository) SaveBulk(ctx context.Context, things []things.Thing) ([]string, error) {
+	q := `INSERT INTO things (id, external_id, owner, type, name, key, payload, metadata, tags, status, created_at, updated_at) VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+
+	tx, err := tr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(things))
+	for _, thing := range things {
+		metadata, err := toJSON(thing.Metadata)
+		if err == nil {
+			_, err = tx.ExecContext(ctx, q, thing.ID, thing.ExternalID, thing.Owner, thing.Type, thing.Name, thing.Key, thing.Payload, metadata, pq.Array(thing.Tags), thing.Status, thing.CreatedAt, thing.UpdatedAt)
+		}
+
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				tr.log.Error(fmt.Sprintf("Failed to rollback bulk save due to %s", rbErr))
+			}
+			return nil, err
+		}
+		ids = append(ids, thing.ID)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (tr thingRepository) Update(ctx context.Context, thing things.Thing) error {
+	q := `UPDATE things SET name = $1, payload = $2, metadata = $3, tags = $4, updated_at = $5, version = version + 1
+	      WHERE owner = $6 AND id = $7 AND deleted_at IS NULL AND ($8 = 0 OR version = $8);`
+
+	metadata, err := toJSON(thing.Metadata)
+	if err != nil {
+		return err
+	}
+
+	res, err := tr.db.ExecContext(ctx, q, thing.Name, thing.Payload, metadata, pq.Array(thing.Tags), thing.UpdatedAt, thing.Owner, thing.ID, thing.Version)
+	if err != nil {
+		return err
+	}
+
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if cnt == 0 {
+		if thing.Version == 0 {
+			return things.ErrNotFound
+		}
+
+		// the thing either doesn't exist, or has a different version
+		if _, err := tr.One(ctx, thing.Owner, thing.ID); err != nil {
+			return err
+		}
+
+		return things.ErrVersionMismatch
+	}
+
+	return nil
+}
+
+func (tr thingRepository) UpdateKey(ctx context.Context, owner, id, key string) error {
+	q := `UPDATE things SET key = $1, version = version + 1 WHERE owner = $2 AND id = $3 AND deleted_at IS NULL;`
+
+	res, err := tr.db.ExecContext(ctx, q, key, owner, id)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && errDuplicate == pqErr.Code.Name() {
+			return things.ErrConflict
+		}
+		return err
+	}
+
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if cnt == 0 {
+		return things.ErrNotFound
+	}
+
+	return nil
+}
+
+func (tr thingRepository) UpdateStatus(ctx context.Context, owner, id, status string) error {
+	q := `UPDATE things SET status = $1, version = version + 1 WHERE owner = $2 AND id = $3 AND deleted_at IS NULL;`
 
-	res, err := tr.db.Exec(q, thing.Name, thing.Payload, thing.Owner, thing.ID)
+	res, err := tr.db.ExecContext(ctx, q, status, owner, id)
 	if err != nil {
 		return err
 	}
@@ -52,12 +162,13 @@ func (tr thingRepository) Update(thing things.Thing) error {
 	return nil
 }
 
-func (tr thingRepository) One(owner, id string) (things.Thing, error) {
-	q := `SELECT name, type, key, payload FROM things WHERE id = $1 AND owner = $2`
+func (tr thingRepository) One(ctx context.Context, owner, id string) (things.Thing, error) {
+	q := `SELECT COALESCE(external_id, ''), name, type, key, payload, metadata, tags, status, created_at, updated_at, version FROM things WHERE id = $1 AND owner = $2 AND deleted_at IS NULL`
 	thing := things.Thing{ID: id, Owner: owner}
+	var metadata []byte
 	err := tr.db.
-		QueryRow(q, id, owner).
-		Scan(&thing.Name, &thing.Type, &thing.Key, &thing.Payload)
+		QueryRowContext(ctx, q, id, owner).
+		Scan(&thing.ExternalID, &thing.Name, &thing.Type, &thing.Key, &thing.Payload, &metadata, pq.Array(&thing.Tags), &thing.Status, &thing.CreatedAt, &thing.UpdatedAt, &thing.Version)
 
 	if err != nil {
 		empty := things.Thing{}
@@ -67,23 +178,133 @@ func (tr thingRepository) One(owner, id string) (things.Thing, error) {
 		return empty, err
 	}
 
+	if thing.Metadata, err = fromJSON(metadata); err != nil {
+		return things.Thing{}, err
+	}
+
 	return thing, nil
 }
 
-func (tr thingRepository) All(owner string, offset, limit int) []things.Thing {
-	q := `SELECT id, name, type, key, payload FROM things WHERE owner = $1 ORDER BY id LIMIT $2 OFFSET $3`
+func (tr thingRepository) Owner(ctx context.Context, id string) (string, error) {
+	var owner string
+
+	q := `SELECT owner FROM things WHERE id = $1 AND deleted_at IS NULL`
+	if err := tr.db.QueryRowContext(ctx, q, id).Scan(&owner); err != nil {
+		if err == sql.ErrNoRows {
+			return "", things.ErrNotFound
+		}
+		return "", err
+	}
+
+	return owner, nil
+}
+
+func (tr thingRepository) ByKey(ctx context.Context, key string) (things.Thing, error) {
+	q := `SELECT owner, id FROM things WHERE key = $1 AND deleted_at IS NULL`
+
+	var owner, id string
+	if err := tr.db.QueryRowContext(ctx, q, key).Scan(&owner, &id); err != nil {
+		if err == sql.ErrNoRows {
+			return things.Thing{}, things.ErrNotFound
+		}
+		return things.Thing{}, err
+	}
+
+	return tr.One(ctx, owner, id)
+}
+
+func (tr thingRepository) ByExternalID(ctx context.Context, owner, extID string) (things.Thing, error) {
+	q := `SELECT id FROM things WHERE owner = $1 AND external_id = $2 AND deleted_at IS NULL`
+
+	var id string
+	if err := tr.db.QueryRowContext(ctx, q, owner, extID).Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return things.Thing{}, things.ErrNotFound
+		}
+		return things.Thing{}, err
+	}
+
+	return tr.One(ctx, owner, id)
+}
+
+func (tr thingRepository) All(ctx context.Context, owner string, offset, limit int, sorting things.Sorting, filter things.ThingFilter) things.ThingPage {
+	return tr.page(ctx, owner, false, offset, limit, sorting, filter)
+}
+
+func (tr thingRepository) AllDeleted(ctx context.Context, owner string, offset, limit int, sorting things.Sorting) things.ThingPage {
+	return tr.page(ctx, owner, true, offset, limit, sorting, things.ThingFilter{})
+}
+
+func (tr thingRepository) page(ctx context.Context, owner string, deleted bool, offset, limit int, sorting things.Sorting, filter things.ThingFilter) things.ThingPage {
+	q := fmt.Sprintf(`SELECT id, COALESCE(external_id, ''), name, type, key, payload, metadata, tags, status, created_at, updated_at, version, deleted_at FROM things WHERE owner = $1 AND (deleted_at IS NOT NULL) = $2 AND %s %s LIMIT $10 OFFSET $11`, thingFilterCond, orderBy(sorting))
+	page := things.ThingPage{
+		Things: []things.Thing{},
+		Offset: offset,
+		Limit:  limit,
+	}
+
+	params := append([]interface{}{owner, deleted}, filterParams(filter)...)
+	rows, err := tr.db.QueryContext(ctx, q, append(params, limit, offset)...)
+	if err != nil {
+		tr.log.Error(fmt.Sprintf("Failed to retrieve things due to %s", err))
+		return page
+	}
+	defer rows.Close()
+
 	items := []things.Thing{}
+	for rows.Next() {
+		var deletedAt pq.NullTime
+		c, err := scanThing(rows, owner, &deletedAt)
+		if err != nil {
+			tr.log.Error(fmt.Sprintf("Failed to read retrieved thing due to %s", err))
+			return page
+		}
+		c.DeletedAt = deletedAt.Time
+		items = append(items, c)
+	}
+
+	q = `SELECT COUNT(*) FROM things WHERE owner = $1 AND (deleted_at IS NOT NULL) = $2 AND ` + thingFilterCond
+	if err := tr.db.QueryRowContext(ctx, q, params...).Scan(&page.Total); err != nil {
+		tr.log.Error(fmt.Sprintf("Failed to count things due to %s", err))
+		return page
+	}
+
+	page.Things = items
+	return page
+}
+
+func (tr thingRepository) Count(ctx context.Context, owner string, filter things.ThingFilter) int {
+	q := `SELECT COUNT(*) FROM things WHERE owner = $1 AND (deleted_at IS NOT NULL) = $2 AND ` + thingFilterCond
+	params := append([]interface{}{owner, false}, filterParams(filter)...)
+
+	count := 0
+	if err := tr.db.QueryRowContext(ctx, q, params...).Scan(&count); err != nil {
+		tr.log.Error(fmt.Sprintf("Failed to count things due to %s", err))
+		return 0
+	}
 
-	rows, err := tr.db.Query(q, owner, limit, offset)
+	return count
+}
+
+func filterParams(filter things.ThingFilter) []interface{} {
+	name := fmt.Sprintf("%%%s%%", likeEscaper.Replace(filter.Name))
+	return []interface{}{filter.Name, name, filter.Type, filter.Status, filter.Tag, filter.Metadata.Key, filter.Metadata.Value}
+}
+
+func (tr thingRepository) Multi(ctx context.Context, owner string, ids []string) []things.Thing {
+	q := `SELECT id, COALESCE(external_id, ''), name, type, key, payload, metadata, tags, status, created_at, updated_at, version FROM things WHERE owner = $1 AND deleted_at IS NULL AND id = ANY($2)`
+
+	rows, err := tr.db.QueryContext(ctx, q, owner, pq.Array(ids))
 	if err != nil {
 		tr.log.Error(fmt.Sprintf("Failed to retrieve things due to %s", err))
 		return []things.Thing{}
 	}
 	defer rows.Close()
 
+	items := []things.Thing{}
 	for rows.Next() {
-		c := things.Thing{Owner: owner}
-		if err = rows.Scan(&c.ID, &c.Name, &c.Type, &c.Key, &c.Payload); err != nil {
+		c, err := scanThing(rows, owner)
+		if err != nil {
 			tr.log.Error(fmt.Sprintf("Failed to read retrieved thing due to %s", err))
 			return []things.Thing{}
 		}
@@ -93,8 +314,226 @@ func (tr thingRepository) All(owner string, offset, limit int) []things.Thing {
 	return items
 }
 
-func (tr thingRepository) Remove(owner, id string) error {
-	q := `DELETE FROM things WHERE id = $1 AND owner = $2`
-	tr.db.Exec(q, id, owner)
+func (tr thingRepository) After(ctx context.Context, owner, afterID string, limit int) []things.Thing {
+	q := `SELECT id, COALESCE(external_id, ''), name, type, key, payload, metadata, tags, status, created_at, updated_at, version FROM things WHERE owner = $1 AND deleted_at IS NULL AND id > $2 ORDER BY id LIMIT $3`
+
+	rows, err := tr.db.QueryContext(ctx, q, owner, afterID, limit)
+	if err != nil {
+		tr.log.Error(fmt.Sprintf("Failed to retrieve things due to %s", err))
+		return []things.Thing{}
+	}
+	defer rows.Close()
+
+	items := []things.Thing{}
+	for rows.Next() {
+		c, err := scanThing(rows, owner)
+		if err != nil {
+			tr.log.Error(fmt.Sprintf("Failed to read retrieved thing due to %s", err))
+			return []things.Thing{}
+		}
+		items = append(items, c)
+	}
+
+	return items
+}
+
+func (tr thingRepository) Search(ctx context.Context, owner, name string, offset, limit int) []things.Thing {
+	q := `SELECT id, COALESCE(external_id, ''), name, type, key, payload, metadata, tags, status, created_at, updated_at, version FROM things
+	      WHERE owner = $1 AND deleted_at IS NULL AND COALESCE(name, '') ILIKE $2
+	      ORDER BY CASE WHEN LOWER(COALESCE(name, '')) = LOWER($5) THEN 0 WHEN COALESCE(name, '') ILIKE $6 THEN 1 ELSE 2 END, name, id
+	      LIMIT $3 OFFSET $4`
+
+	escaped := likeEscaper.Replace(name)
+	rows, err := tr.db.QueryContext(ctx, q, owner, fmt.Sprintf("%%%s%%", escaped), limit, offset, name, fmt.Sprintf("%s%%", escaped))
+	if err != nil {
+		tr.log.Error(fmt.Sprintf("Failed to search things due to %s", err))
+		return []things.Thing{}
+	}
+	defer rows.Close()
+
+	items := []things.Thing{}
+	for rows.Next() {
+		c, err := scanThing(rows, owner)
+		if err != nil {
+			tr.log.Error(fmt.Sprintf("Failed to read retrieved thing due to %s", err))
+			return []things.Thing{}
+		}
+		items = append(items, c)
+	}
+
+	return items
+}
+
+func (tr thingRepository) AllByMetadata(ctx context.Context, owner, metaKey, metaValue string, offset, limit int) []things.Thing {
+	q := `SELECT id, COALESCE(external_id, ''), name, type, key, payload, metadata, tags, status, created_at, updated_at, version FROM things WHERE owner = $1 AND deleted_at IS NULL AND metadata ->> $2 = $3 ORDER BY id LIMIT $4 OFFSET $5`
+
+	rows, err := tr.db.QueryContext(ctx, q, owner, metaKey, metaValue, limit, offset)
+	if err != nil {
+		tr.log.Error(fmt.Sprintf("Failed to retrieve things by metadata due to %s", err))
+		return []things.Thing{}
+	}
+	defer rows.Close()
+
+	items := []things.Thing{}
+	for rows.Next() {
+		c, err := scanThing(rows, owner)
+		if err != nil {
+			tr.log.Error(fmt.Sprintf("Failed to read retrieved thing due to %s", err))
+			return []things.Thing{}
+		}
+		items = append(items, c)
+	}
+
+	return items
+}
+
+func (tr thingRepository) ChangeOwner(ctx context.Context, owner, id, newOwner string) error {
+	tx, err := tr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	rollback := func() {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			tr.log.Error(fmt.Sprintf("Failed to rollback thing transfer due to %s", rbErr))
+		}
+	}
+
+	for _, q := range []string{
+		`DELETE FROM connections WHERE thing_id = $1 AND thing_owner = $2`,
+		`DELETE FROM disconnections WHERE thing_id = $1 AND thing_owner = $2`,
+	} {
+		if _, err := tx.ExecContext(ctx, q, id, owner); err != nil {
+			rollback()
+			return err
+		}
+	}
+
+	q := `UPDATE things SET owner = $1 WHERE owner = $2 AND id = $3 AND deleted_at IS NULL;`
+	res, err := tx.ExecContext(ctx, q, newOwner, owner, id)
+	if err != nil {
+		rollback()
+		if pqErr, ok := err.(*pq.Error); ok && errDuplicate == pqErr.Code.Name() {
+			return things.ErrConflict
+		}
+		return err
+	}
+
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		rollback()
+		return err
+	}
+
+	if cnt == 0 {
+		rollback()
+		return things.ErrNotFound
+	}
+
+	return tx.Commit()
+}
+
+func (tr thingRepository) Remove(ctx context.Context, owner, id string) error {
+	q := `UPDATE things SET deleted_at = $3 WHERE id = $1 AND owner = $2 AND deleted_at IS NULL`
+	tr.db.ExecContext(ctx, q, id, owner, time.Now().UTC())
 	return nil
 }
+
+func (tr thingRepository) RemoveAll(ctx context.Context, owner string) error {
+	q := `UPDATE things SET deleted_at = $2 WHERE owner = $1 AND deleted_at IS NULL`
+	_, err := tr.db.ExecContext(ctx, q, owner, time.Now().UTC())
+	return err
+}
+
+func (tr thingRepository) Restore(ctx context.Context, owner, id string) error {
+	q := `UPDATE things SET deleted_at = NULL WHERE id = $1 AND owner = $2`
+
+	res, err := tr.db.ExecContext(ctx, q, id, owner)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && errDuplicate == pqErr.Code.Name() {
+			return things.ErrConflict
+		}
+		return err
+	}
+
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if cnt == 0 {
+		return things.ErrNotFound
+	}
+
+	return nil
+}
+
+func (tr thingRepository) Purge(ctx context.Context, olderThan time.Time) (int, error) {
+	q := `DELETE FROM things WHERE deleted_at < $1`
+
+	res, err := tr.db.ExecContext(ctx, q, olderThan.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(cnt), nil
+}
+
+func (tr thingRepository) Ping(ctx context.Context) error {
+	return tr.db.PingContext(ctx)
+}
+
+// scanThing reads the thing from the current row. Columns are expected to be
+// id, external_id, name, type, key, payload, metadata, tags, status,
+// created_at, updated_at and version, in that order, followed by any columns
+// read into dest.
+func scanThing(rows *sql.Rows, owner string, dest ...interface{}) (things.Thing, error) {
+	thing := things.Thing{Owner: owner}
+	var metadata []byte
+
+	cols := []interface{}{&thing.ID, &thing.ExternalID, &thing.Name, &thing.Type, &thing.Key, &thing.Payload, &metadata, pq.Array(&thing.Tags), &thing.Status, &thing.CreatedAt, &thing.UpdatedAt, &thing.Version}
+	if err := rows.Scan(append(cols, dest...)...); err != nil {
+		return things.Thing{}, err
+	}
+
+	m, err := fromJSON(metadata)
+	if err != nil {
+		return things.Thing{}, err
+	}
+	thing.Metadata = m
+
+	return thing, nil
+}
+
+// toJSON converts the thing's or channel's metadata into its database representation.
+// Missing metadata is stored as NULL.
+func toJSON(metadata map[string]interface{}) (interface{}, error) {
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(data), nil
+}
+
+func fromJSON(data []byte) (map[string]interface{}, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var metadata map[string]interface{}
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, err
+	}
+
+	return metadata, nil
+}
@@ -1,8 +1,11 @@
 package postgres_test
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/mainflux/mainflux/things"
 	"github.com/mainflux/mainflux/things/postgres"
@@ -20,7 +23,7 @@ func TestThingSave(t *testing.T) {
 		Key:   idp.ID(),
 	}
 
-	_, err := thingRepo.Save(thing)
+	_, err := thingRepo.Save(context.Background(), thing)
 	hasErr := err != nil
 
 	assert.False(t, hasErr, fmt.Sprintf("create new thing: expected false got %t\n", hasErr))
@@ -37,7 +40,7 @@ func TestThingUpdate(t *testing.T) {
 		Key:   idp.ID(),
 	}
 
-	thingRepo.Save(thing)
+	thingRepo.Save(context.Background(), thing)
 
 	cases := map[string]struct {
 		thing things.Thing
@@ -49,11 +52,201 @@ func TestThingUpdate(t *testing.T) {
 	}
 
 	for desc, tc := range cases {
-		err := thingRepo.Update(tc.thing)
+		err := thingRepo.Update(context.Background(), tc.thing)
 		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
 	}
 }
 
+func TestThingUpdateVersion(t *testing.T) {
+	email := "thing-update-version@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+
+	thing := things.Thing{
+		ID:    idp.ID(),
+		Owner: email,
+		Key:   idp.ID(),
+	}
+
+	thingRepo.Save(context.Background(), thing)
+	saved, _ := thingRepo.One(context.Background(), email, thing.ID)
+
+	cases := []struct {
+		desc  string
+		thing things.Thing
+		err   error
+	}{
+		{"existing thing with current version", saved, nil},
+		{"existing thing with stale version", saved, things.ErrVersionMismatch},
+		{"non-existing thing with version", things.Thing{ID: wrong, Owner: email, Version: saved.Version}, things.ErrNotFound},
+	}
+
+	for _, tc := range cases {
+		err := thingRepo.Update(context.Background(), tc.thing)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+
+	updated, _ := thingRepo.One(context.Background(), email, thing.ID)
+	assert.Equal(t, saved.Version+1, updated.Version, fmt.Sprintf("expected version %d got %d\n", saved.Version+1, updated.Version))
+}
+
+func TestThingKeyUpdate(t *testing.T) {
+	email := "thing-key-update@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+
+	thing := things.Thing{
+		ID:    idp.ID(),
+		Owner: email,
+		Key:   idp.ID(),
+	}
+	other := things.Thing{
+		ID:    idp.ID(),
+		Owner: email,
+		Key:   idp.ID(),
+	}
+
+	thingRepo.Save(context.Background(), thing)
+	thingRepo.Save(context.Background(), other)
+
+	cases := map[string]struct {
+		owner string
+		id    string
+		key   string
+		err   error
+	}{
+		"existing thing":                            {email, thing.ID, idp.ID(), nil},
+		"existing thing with key already taken":     {email, thing.ID, other.Key, things.ErrConflict},
+		"non-existing thing with existing user":     {email, wrong, idp.ID(), things.ErrNotFound},
+		"non-existing thing with non-existing user": {wrong, wrong, idp.ID(), things.ErrNotFound},
+	}
+
+	for desc, tc := range cases {
+		err := thingRepo.UpdateKey(context.Background(), tc.owner, tc.id, tc.key)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+}
+
+func TestThingStatusUpdate(t *testing.T) {
+	email := "thing-status-update@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+
+	thing := things.Thing{
+		ID:     idp.ID(),
+		Owner:  email,
+		Key:    idp.ID(),
+		Status: things.StatusEnabled,
+	}
+	thingRepo.Save(context.Background(), thing)
+
+	cases := map[string]struct {
+		owner  string
+		id     string
+		status string
+		err    error
+	}{
+		"existing thing":                            {email, thing.ID, things.StatusDisabled, nil},
+		"non-existing thing with existing user":     {email, wrong, things.StatusDisabled, things.ErrNotFound},
+		"non-existing thing with non-existing user": {wrong, wrong, things.StatusDisabled, things.ErrNotFound},
+	}
+
+	for desc, tc := range cases {
+		err := thingRepo.UpdateStatus(context.Background(), tc.owner, tc.id, tc.status)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+
+	saved, _ := thingRepo.One(context.Background(), email, thing.ID)
+	assert.Equal(t, things.StatusDisabled, saved.Status, fmt.Sprintf("retrieve disabled thing: expected %s got %s\n", things.StatusDisabled, saved.Status))
+}
+
+func TestThingRetrievalByExternalID(t *testing.T) {
+	email := "thing-retrieval-by-external-id@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+
+	thing := things.Thing{
+		ID:         idp.ID(),
+		ExternalID: idp.ID(),
+		Owner:      email,
+		Key:        idp.ID(),
+	}
+	thingRepo.Save(context.Background(), thing)
+
+	_, err := thingRepo.Save(context.Background(), things.Thing{ID: idp.ID(), ExternalID: thing.ExternalID, Owner: email, Key: idp.ID()})
+	assert.Equal(t, things.ErrConflict, err, fmt.Sprintf("save thing with existing external ID: expected %s got %s\n", things.ErrConflict, err))
+
+	cases := map[string]struct {
+		owner string
+		extID string
+		err   error
+	}{
+		"existing user and external ID":           {email, thing.ExternalID, nil},
+		"existing user, non-existing external ID": {email, wrong, things.ErrNotFound},
+		"non-existing owner":                      {wrong, thing.ExternalID, things.ErrNotFound},
+	}
+
+	for desc, tc := range cases {
+		th, err := thingRepo.ByExternalID(context.Background(), tc.owner, tc.extID)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+		if err == nil {
+			assert.Equal(t, thing.ID, th.ID, fmt.Sprintf("%s: expected %s got %s\n", desc, thing.ID, th.ID))
+		}
+	}
+}
+
+func TestThingExternalIDReuse(t *testing.T) {
+	email := "thing-external-id-reuse@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+
+	thing := things.Thing{
+		ID:         idp.ID(),
+		ExternalID: idp.ID(),
+		Owner:      email,
+		Key:        idp.ID(),
+	}
+	thingRepo.Save(context.Background(), thing)
+	thingRepo.Remove(context.Background(), email, thing.ID)
+
+	readded := things.Thing{ID: idp.ID(), ExternalID: thing.ExternalID, Owner: email, Key: idp.ID()}
+	_, err := thingRepo.Save(context.Background(), readded)
+	assert.Nil(t, err, fmt.Sprintf("save thing with removed thing's external ID: unexpected error %s\n", err))
+
+	th, err := thingRepo.ByExternalID(context.Background(), email, thing.ExternalID)
+	assert.Nil(t, err, fmt.Sprintf("retrieve re-added thing: unexpected error %s\n", err))
+	assert.Equal(t, readded.ID, th.ID, fmt.Sprintf("retrieve re-added thing: expected %s got %s\n", readded.ID, th.ID))
+}
+
+func TestThingRetrievalByKey(t *testing.T) {
+	email := "thing-retrieval-by-key@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+
+	thing := things.Thing{
+		ID:    idp.ID(),
+		Owner: email,
+		Key:   idp.ID(),
+	}
+	thingRepo.Save(context.Background(), thing)
+
+	cases := map[string]struct {
+		key string
+		err error
+	}{
+		"existing key":     {thing.Key, nil},
+		"non-existing key": {wrong, things.ErrNotFound},
+	}
+
+	for desc, tc := range cases {
+		th, err := thingRepo.ByKey(context.Background(), tc.key)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+		if err == nil {
+			assert.Equal(t, thing.ID, th.ID, fmt.Sprintf("%s: expected %s got %s\n", desc, thing.ID, th.ID))
+		}
+	}
+}
+
 func TestSingleThingRetrieval(t *testing.T) {
 	email := "thing-single-retrieval@example.com"
 	idp := uuid.New()
@@ -65,7 +258,7 @@ func TestSingleThingRetrieval(t *testing.T) {
 		Key:   idp.ID(),
 	}
 
-	thingRepo.Save(thing)
+	thingRepo.Save(context.Background(), thing)
 
 	cases := map[string]struct {
 		owner string
@@ -78,7 +271,7 @@ func TestSingleThingRetrieval(t *testing.T) {
 	}
 
 	for desc, tc := range cases {
-		_, err := thingRepo.One(tc.owner, tc.ID)
+		_, err := thingRepo.One(context.Background(), tc.owner, tc.ID)
 		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
 	}
 }
@@ -97,23 +290,385 @@ func TestMultiThingRetrieval(t *testing.T) {
 			Key:   idp.ID(),
 		}
 
-		thingRepo.Save(t)
+		thingRepo.Save(context.Background(), t)
+	}
+
+	cases := map[string]struct {
+		owner  string
+		offset int
+		limit  int
+		size   int
+		total  int
+	}{
+		"existing owner, retrieve all":    {email, 0, n, n, n},
+		"existing owner, retrieve subset": {email, 1, 6, 6, n},
+		"non-existing owner":              {wrong, 1, 6, 0, 0},
+	}
+
+	for desc, tc := range cases {
+		page := thingRepo.All(context.Background(), tc.owner, tc.offset, tc.limit, things.Sorting{}, things.ThingFilter{})
+		size := len(page.Things)
+		assert.Equal(t, tc.size, size, fmt.Sprintf("%s: expected %d got %d\n", desc, tc.size, size))
+		assert.Equal(t, tc.total, page.Total, fmt.Sprintf("%s: expected total %d got %d\n", desc, tc.total, page.Total))
+	}
+}
+
+func TestThingCount(t *testing.T) {
+	email := "thing-count@example.com"
+	otherEmail := "other-thing-count@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+
+	n := 5
+	for i := 0; i < n; i++ {
+		for _, owner := range []string{email, otherEmail} {
+			th := things.Thing{ID: idp.ID(), Owner: owner, Key: idp.ID()}
+			thingRepo.Save(context.Background(), th)
+		}
+	}
+	th := things.Thing{ID: idp.ID(), Owner: email, Key: idp.ID()}
+	thingRepo.Save(context.Background(), th)
+	thingRepo.Remove(context.Background(), email, th.ID)
+
+	cases := map[string]struct {
+		owner string
+		count int
+	}{
+		"existing owner":       {email, n},
+		"other existing owner": {otherEmail, n},
+		"non-existing owner":   {wrong, 0},
+	}
+
+	for desc, tc := range cases {
+		count := thingRepo.Count(context.Background(), tc.owner, things.ThingFilter{})
+		assert.Equal(t, tc.count, count, fmt.Sprintf("%s: expected %d got %d\n", desc, tc.count, count))
+	}
+}
+
+func TestThingCountFiltered(t *testing.T) {
+	email := "thing-count-filtered@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+
+	n := 4
+	for i := 0; i < n; i++ {
+		status := things.StatusEnabled
+		if i%2 == 0 {
+			status = things.StatusDisabled
+		}
+		thingRepo.Save(context.Background(), things.Thing{ID: idp.ID(), Owner: email, Type: "app", Name: "app", Key: idp.ID(), Status: things.StatusEnabled})
+		thingRepo.Save(context.Background(), things.Thing{
+			ID:       idp.ID(),
+			Owner:    email,
+			Type:     "device",
+			Name:     fmt.Sprintf("Sensor_%d", i),
+			Key:      idp.ID(),
+			Metadata: map[string]interface{}{"region": "eu"},
+			Tags:     []string{"lab"},
+			Status:   status,
+		})
+	}
+
+	cases := map[string]struct {
+		filter things.ThingFilter
+		count  int
+	}{
+		"count all things":          {things.ThingFilter{}, 2 * n},
+		"count disabled devices":    {things.ThingFilter{Type: "device", Status: things.StatusDisabled}, n / 2},
+		"count things by name":      {things.ThingFilter{Name: "sensor_"}, n},
+		"count things by wildcard":  {things.ThingFilter{Name: "%"}, 0},
+		"count things by tag":       {things.ThingFilter{Tag: "lab"}, n},
+		"count things by metadata":  {things.ThingFilter{Metadata: things.MetadataFilter{Key: "region", Value: "eu"}}, n},
+		"count things by all":       {things.ThingFilter{Name: "sensor_0", Type: "device", Status: things.StatusDisabled, Tag: "lab"}, 1},
+		"count things with no hits": {things.ThingFilter{Type: "app", Status: things.StatusDisabled}, 0},
+	}
+
+	for desc, tc := range cases {
+		count := thingRepo.Count(context.Background(), email, tc.filter)
+		assert.Equal(t, tc.count, count, fmt.Sprintf("%s: expected %d got %d\n", desc, tc.count, count))
+	}
+}
+
+func TestMultiThingRetrievalSorted(t *testing.T) {
+	email := "thing-multi-retrieval-sorted@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+
+	n := 5
+
+	for i := 0; i < n; i++ {
+		t := things.Thing{
+			ID:    idp.ID(),
+			Owner: email,
+			Name:  fmt.Sprintf("thing-%d", i),
+			Key:   idp.ID(),
+		}
+
+		thingRepo.Save(context.Background(), t)
+	}
+
+	page := thingRepo.All(context.Background(), email, 0, n, things.Sorting{Order: things.OrderName, Dir: things.DirDesc}, things.ThingFilter{})
+	for i, th := range page.Things {
+		expected := fmt.Sprintf("thing-%d", n-1-i)
+		assert.Equal(t, expected, th.Name, fmt.Sprintf("retrieve things sorted by name: expected %s got %s\n", expected, th.Name))
+	}
+}
+
+func TestMultiThingRetrievalByType(t *testing.T) {
+	email := "thing-multi-retrieval-by-type@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+
+	types := []string{"app", "device", "device"}
+	for _, typ := range types {
+		thingRepo.Save(context.Background(), things.Thing{
+			ID:    idp.ID(),
+			Owner: email,
+			Type:  typ,
+			Key:   idp.ID(),
+		})
+	}
+
+	cases := map[string]struct {
+		thingType string
+		total     int
+	}{
+		"retrieve apps":               {"app", 1},
+		"retrieve devices":            {"device", 2},
+		"retrieve things of any type": {"", len(types)},
+	}
+
+	for desc, tc := range cases {
+		page := thingRepo.All(context.Background(), email, 0, 10, things.Sorting{}, things.ThingFilter{Type: tc.thingType})
+		assert.Equal(t, tc.total, page.Total, fmt.Sprintf("%s: expected total %d got %d\n", desc, tc.total, page.Total))
+		assert.Equal(t, tc.total, len(page.Things), fmt.Sprintf("%s: expected size %d got %d\n", desc, tc.total, len(page.Things)))
+		for _, th := range page.Things {
+			if tc.thingType != "" {
+				assert.Equal(t, tc.thingType, th.Type, fmt.Sprintf("%s: expected type %s got %s\n", desc, tc.thingType, th.Type))
+			}
+		}
+	}
+}
+
+func TestMultiThingRetrievalByTag(t *testing.T) {
+	email := "thing-multi-retrieval-by-tag@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+
+	tags := [][]string{{"floor-1", "hvac"}, {"floor-1"}, {"hvac", "floor-2"}, nil}
+	for _, tt := range tags {
+		thingRepo.Save(context.Background(), things.Thing{
+			ID:    idp.ID(),
+			Owner: email,
+			Type:  "device",
+			Key:   idp.ID(),
+			Tags:  tt,
+		})
+	}
+
+	cases := map[string]struct {
+		tag   string
+		total int
+	}{
+		"retrieve things by shared tag":  {"hvac", 2},
+		"retrieve things by unique tag":  {"floor-2", 1},
+		"retrieve things by unknown tag": {"lighting", 0},
+		"retrieve things of any tag":     {"", len(tags)},
+	}
+
+	for desc, tc := range cases {
+		page := thingRepo.All(context.Background(), email, 0, 10, things.Sorting{}, things.ThingFilter{Tag: tc.tag})
+		assert.Equal(t, tc.total, page.Total, fmt.Sprintf("%s: expected total %d got %d\n", desc, tc.total, page.Total))
+		assert.Equal(t, tc.total, len(page.Things), fmt.Sprintf("%s: expected size %d got %d\n", desc, tc.total, len(page.Things)))
+		for _, th := range page.Things {
+			if tc.tag != "" {
+				assert.Contains(t, th.Tags, tc.tag, fmt.Sprintf("%s: expected tag %s in %v\n", desc, tc.tag, th.Tags))
+			}
+		}
+	}
+}
+func TestThingRetrievalAfter(t *testing.T) {
+	email := "thing-multi-retrieval-after@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+
+	n := 25
+	ids := []string{}
+	for i := 0; i < n; i++ {
+		th := things.Thing{
+			ID:    idp.ID(),
+			Owner: email,
+			Type:  "app",
+			Key:   idp.ID(),
+		}
+
+		thingRepo.Save(context.Background(), th)
+		ids = append(ids, th.ID)
+	}
+	sort.Strings(ids)
+
+	walked := []string{}
+	cursor := ""
+	for _, size := range []int{10, 10, 5, 0} {
+		ths := thingRepo.After(context.Background(), email, cursor, 10)
+		assert.Len(t, ths, size, fmt.Sprintf("retrieve things after %s: expected %d things got %d\n", cursor, size, len(ths)))
+
+		for _, th := range ths {
+			walked = append(walked, th.ID)
+		}
+
+		if len(ths) > 0 {
+			cursor = ths[len(ths)-1].ID
+		}
+	}
+	assert.Equal(t, ids, walked, fmt.Sprintf("walk things: expected %v got %v\n", ids, walked))
+
+	ths := thingRepo.After(context.Background(), wrong, "", 10)
+	assert.Empty(t, ths, fmt.Sprintf("retrieve things of non-existing owner: expected no things got %d\n", len(ths)))
+}
+
+func TestThingRetrievalByIDs(t *testing.T) {
+	email := "thing-multi-retrieval-by-ids@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+
+	ids := []string{}
+	for i := 0; i < 3; i++ {
+		th := things.Thing{
+			ID:    idp.ID(),
+			Owner: email,
+			Type:  "app",
+			Key:   idp.ID(),
+		}
+
+		thingRepo.Save(context.Background(), th)
+		ids = append(ids, th.ID)
+	}
+	thingRepo.Remove(context.Background(), email, ids[2])
+
+	cases := map[string]struct {
+		owner string
+		ids   []string
+		size  int
+	}{
+		"retrieve things by ids":                       {email, ids[:2], 2},
+		"retrieve things including non-existing thing": {email, []string{ids[0], idp.ID()}, 1},
+		"retrieve things including removed thing":      {email, ids, 2},
+		"retrieve things of non-existing owner":        {wrong, ids, 0},
+		"retrieve things without ids":                  {email, []string{}, 0},
+	}
+
+	for desc, tc := range cases {
+		ths := thingRepo.Multi(context.Background(), tc.owner, tc.ids)
+		assert.Len(t, ths, tc.size, fmt.Sprintf("%s: expected %d things got %d\n", desc, tc.size, len(ths)))
+	}
+}
+
+func TestThingSearch(t *testing.T) {
+	email := "thing-search@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+
+	n := 10
+
+	for i := 0; i < n; i++ {
+		t := things.Thing{
+			ID:    idp.ID(),
+			Owner: email,
+			Type:  "app",
+			Name:  fmt.Sprintf("Sensor-%d", i%2),
+			Key:   idp.ID(),
+		}
+
+		thingRepo.Save(context.Background(), t)
 	}
 
 	cases := map[string]struct {
 		owner  string
+		name   string
 		offset int
 		limit  int
 		size   int
 	}{
-		"existing owner, retrieve all":    {email, 0, n, n},
-		"existing owner, retrieve subset": {email, 1, 6, 6},
-		"non-existing owner":              {wrong, 1, 6, 0},
+		"existing owner, search by exact name":   {email, "Sensor-1", 0, n, n / 2},
+		"existing owner, search ignoring case":   {email, "sensor", 0, n, n},
+		"existing owner, search subset":          {email, "sensor", 1, 6, 6},
+		"existing owner, search with empty name": {email, "", 0, n, n},
+		"existing owner, search with wildcard":   {email, "%", 0, n, 0},
+		"non-existing owner":                     {wrong, "sensor", 0, n, 0},
+	}
+
+	for desc, tc := range cases {
+		ths := thingRepo.Search(context.Background(), tc.owner, tc.name, tc.offset, tc.limit)
+		size := len(ths)
+		assert.Equal(t, tc.size, size, fmt.Sprintf("%s: expected %d got %d\n", desc, tc.size, size))
+	}
+}
+
+func TestThingSearchRanking(t *testing.T) {
+	email := "thing-search-ranking@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+
+	for _, name := range []string{"water-pump", "pump-2", "Pump"} {
+		th := things.Thing{
+			ID:    idp.ID(),
+			Owner: email,
+			Type:  "app",
+			Name:  name,
+			Key:   idp.ID(),
+		}
+
+		thingRepo.Save(context.Background(), th)
+	}
+
+	ths := thingRepo.Search(context.Background(), email, "pump", 0, 10)
+
+	names := []string{}
+	for _, th := range ths {
+		names = append(names, th.Name)
+	}
+	expected := []string{"Pump", "pump-2", "water-pump"}
+	assert.Equal(t, expected, names, fmt.Sprintf("search ranking: expected %v got %v\n", expected, names))
+}
+
+func TestThingRetrievalByMetadata(t *testing.T) {
+	email := "thing-retrieval-by-metadata@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+
+	n := 10
+
+	for i := 0; i < n; i++ {
+		t := things.Thing{
+			ID:       idp.ID(),
+			Owner:    email,
+			Type:     "app",
+			Key:      idp.ID(),
+			Metadata: map[string]interface{}{"firmware": fmt.Sprintf("1.%d", i%2)},
+		}
+
+		thingRepo.Save(context.Background(), t)
+	}
+
+	cases := map[string]struct {
+		owner     string
+		metaKey   string
+		metaValue string
+		offset    int
+		limit     int
+		size      int
+	}{
+		"existing owner, retrieve by metadata":        {email, "firmware", "1.1", 0, n, n / 2},
+		"existing owner, retrieve subset by metadata": {email, "firmware", "1.0", 1, 3, 3},
+		"existing owner, non-existent metadata key":   {email, "location", "1.0", 0, n, 0},
+		"non-existing owner":                          {wrong, "firmware", "1.0", 0, n, 0},
 	}
 
 	for desc, tc := range cases {
-		n := len(thingRepo.All(tc.owner, tc.offset, tc.limit))
-		assert.Equal(t, tc.size, n, fmt.Sprintf("%s: expected %d got %d\n", desc, tc.size, n))
+		ths := thingRepo.AllByMetadata(context.Background(), tc.owner, tc.metaKey, tc.metaValue, tc.offset, tc.limit)
+		size := len(ths)
+		assert.Equal(t, tc.size, size, fmt.Sprintf("%s: expected %d got %d\n", desc, tc.size, size))
 	}
 }
 
@@ -126,17 +681,177 @@ func TestThingRemoval(t *testing.T) {
 		Owner: email,
 		Key:   idp.ID(),
 	}
-	thingRepo.Save(thing)
+	thingRepo.Save(context.Background(), thing)
 
 	// show that the removal works the same for both existing and non-existing
 	// (removed) thing
 	for i := 0; i < 2; i++ {
-		if err := thingRepo.Remove(email, thing.ID); err != nil {
+		if err := thingRepo.Remove(context.Background(), email, thing.ID); err != nil {
 			t.Fatalf("#%d: failed to remove thing due to: %s", i, err)
 		}
 
-		if _, err := thingRepo.One(email, thing.ID); err != things.ErrNotFound {
+		if _, err := thingRepo.One(context.Background(), email, thing.ID); err != things.ErrNotFound {
 			t.Fatalf("#%d: expected %s got %s", i, things.ErrNotFound, err)
 		}
 	}
+
+	page := thingRepo.AllDeleted(context.Background(), email, 0, 10, things.Sorting{})
+	assert.Equal(t, 1, page.Total, fmt.Sprintf("list removed things: expected total %d got %d\n", 1, page.Total))
+}
+
+func TestThingRemoveAll(t *testing.T) {
+	email := "thing-removal-all@example.com"
+	otherEmail := "thing-removal-all-other@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+
+	n := 3
+	for _, owner := range []string{email, otherEmail} {
+		for i := 0; i < n; i++ {
+			thingRepo.Save(context.Background(), things.Thing{ID: idp.ID(), Owner: owner, Type: "app", Key: idp.ID()})
+		}
+	}
+
+	err := thingRepo.RemoveAll(context.Background(), email)
+	assert.Nil(t, err, fmt.Sprintf("remove all things: unexpected error %s\n", err))
+
+	cases := map[string]struct {
+		owner   string
+		count   int
+		removed int
+	}{
+		"owner of removed things": {email, 0, n},
+		"other owner":             {otherEmail, n, 0},
+	}
+
+	for desc, tc := range cases {
+		count := thingRepo.Count(context.Background(), tc.owner, things.ThingFilter{})
+		assert.Equal(t, tc.count, count, fmt.Sprintf("%s: expected %d things got %d\n", desc, tc.count, count))
+
+		page := thingRepo.AllDeleted(context.Background(), tc.owner, 0, 10, things.Sorting{})
+		assert.Equal(t, tc.removed, page.Total, fmt.Sprintf("%s: expected %d removed things got %d\n", desc, tc.removed, page.Total))
+	}
+}
+
+func TestThingOwner(t *testing.T) {
+	email := "thing-owner@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+
+	thingID, _ := thingRepo.Save(context.Background(), things.Thing{ID: idp.ID(), Owner: email, Type: "app", Key: idp.ID()})
+	removedID, _ := thingRepo.Save(context.Background(), things.Thing{ID: idp.ID(), Owner: email, Type: "app", Key: idp.ID()})
+	thingRepo.Remove(context.Background(), email, removedID)
+
+	cases := map[string]struct {
+		thingID string
+		owner   string
+		err     error
+	}{
+		"retrieve owner of existing thing":     {thingID, email, nil},
+		"retrieve owner of removed thing":      {removedID, "", things.ErrNotFound},
+		"retrieve owner of non-existing thing": {wrong, "", things.ErrNotFound},
+	}
+
+	for desc, tc := range cases {
+		owner, err := thingRepo.Owner(context.Background(), tc.thingID)
+		assert.Equal(t, tc.owner, owner, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.owner, owner))
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+}
+
+func TestThingOwnerChange(t *testing.T) {
+	email := "thing-owner-change@example.com"
+	newOwner := "thing-new-owner@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+	thing := things.Thing{
+		ID:    idp.ID(),
+		Owner: email,
+		Key:   idp.ID(),
+	}
+	thingRepo.Save(context.Background(), thing)
+
+	cases := []struct {
+		desc  string
+		owner string
+		id    string
+		err   error
+	}{
+		{"change owner of existing thing", email, thing.ID, nil},
+		{"change owner of transferred thing", email, thing.ID, things.ErrNotFound},
+		{"change owner of non-existing thing", email, wrong, things.ErrNotFound},
+	}
+
+	for _, tc := range cases {
+		err := thingRepo.ChangeOwner(context.Background(), tc.owner, tc.id, newOwner)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+
+	_, err := thingRepo.One(context.Background(), email, thing.ID)
+	assert.Equal(t, things.ErrNotFound, err, fmt.Sprintf("retrieve thing as old owner: expected %s got %s\n", things.ErrNotFound, err))
+
+	_, err = thingRepo.One(context.Background(), newOwner, thing.ID)
+	assert.Nil(t, err, fmt.Sprintf("retrieve thing as new owner: unexpected error %s\n", err))
+}
+
+func TestThingRestore(t *testing.T) {
+	email := "thing-restore@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+	thing := things.Thing{
+		ID:    idp.ID(),
+		Owner: email,
+		Key:   idp.ID(),
+	}
+	thingRepo.Save(context.Background(), thing)
+	thingRepo.Remove(context.Background(), email, thing.ID)
+
+	cases := map[string]struct {
+		owner string
+		id    string
+		err   error
+	}{
+		"existing removed thing":                    {email, thing.ID, nil},
+		"non-existing thing with existing user":     {email, wrong, things.ErrNotFound},
+		"non-existing thing with non-existing user": {wrong, wrong, things.ErrNotFound},
+	}
+
+	for desc, tc := range cases {
+		err := thingRepo.Restore(context.Background(), tc.owner, tc.id)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+
+	_, err := thingRepo.One(context.Background(), email, thing.ID)
+	assert.Nil(t, err, fmt.Sprintf("retrieve restored thing: unexpected error %s\n", err))
+}
+
+func TestThingPurge(t *testing.T) {
+	email := "thing-purge@example.com"
+	idp := uuid.New()
+	thingRepo := postgres.NewThingRepository(db, testLog)
+	thing := things.Thing{
+		ID:    idp.ID(),
+		Owner: email,
+		Key:   idp.ID(),
+	}
+	thingRepo.Save(context.Background(), thing)
+	thingRepo.Remove(context.Background(), email, thing.ID)
+
+	_, err := thingRepo.Purge(context.Background(), time.Now().Add(-time.Hour))
+	assert.Nil(t, err, fmt.Sprintf("purge recently removed things: unexpected error %s\n", err))
+	page := thingRepo.AllDeleted(context.Background(), email, 0, 10, things.Sorting{})
+	assert.Equal(t, 1, page.Total, fmt.Sprintf("purge recently removed things: expected total %d got %d\n", 1, page.Total))
+
+	purged, err := thingRepo.Purge(context.Background(), time.Now().Add(time.Hour))
+	assert.Nil(t, err, fmt.Sprintf("purge removed things: unexpected error %s\n", err))
+	assert.True(t, purged >= 1, fmt.Sprintf("purge removed things: expected at least %d purged got %d\n", 1, purged))
+	err = thingRepo.Restore(context.Background(), email, thing.ID)
+	assert.Equal(t, things.ErrNotFound, err, fmt.Sprintf("restore purged thing: expected %s got %s\n", things.ErrNotFound, err))
+}
+
+func TestThingRepositoryPing(t *testing.T) {
+	thingRepo := postgres.NewThingRepository(db, testLog)
+
+	err := thingRepo.Ping(context.Background())
+	assert.Nil(t, err, fmt.Sprintf("ping thing repository: unexpected error %s\n", err))
 }
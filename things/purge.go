@@ -0,0 +1,30 @@
+package things
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mainflux/mainflux/logger"
+)
+
+// PurgePeriodically permanently removes the things and channels that were
+// removed longer than the retention period ago, once per the provided
+// interval, until the context is canceled. The purges are authorized by the
+// provided service key, and their failures are logged without stopping the
+// following ones.
+func PurgePeriodically(ctx context.Context, svc Service, key string, retention, interval time.Duration, logger logger.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if _, err := svc.PurgeDeleted(ctx, key, now.UTC().Add(-retention)); err != nil {
+				logger.Error(fmt.Sprintf("Failed to purge removed things and channels: %s", err))
+			}
+		}
+	}
+}
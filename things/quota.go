@@ -0,0 +1,36 @@
+package things
+
+import "context"
+
+// Quota specifies an API for retrieving the maximum number of entities each
+// user is allowed to own. Non-positive limit means the number is unlimited.
+type Quota interface {
+	// Things retrieves the maximum number of things the specified user is
+	// allowed to own.
+	Things(context.Context, string) (int, error)
+
+	// Channels retrieves the maximum number of channels the specified user
+	// is allowed to own.
+	Channels(context.Context, string) (int, error)
+}
+
+var _ Quota = (*staticQuota)(nil)
+
+type staticQuota struct {
+	things   int
+	channels int
+}
+
+// NewStaticQuota creates the quota that applies the same limits to all of
+// the users.
+func NewStaticQuota(things, channels int) Quota {
+	return staticQuota{things: things, channels: channels}
+}
+
+func (sq staticQuota) Things(context.Context, string) (int, error) {
+	return sq.things, nil
+}
+
+func (sq staticQuota) Channels(context.Context, string) (int, error) {
+	return sq.channels, nil
+}
@@ -0,0 +1,60 @@
+package things
+
+import "context"
+
+type recorderKey struct{}
+
+// callRecorder keeps the effects of the service call, so that the decorators
+// can report them without retrieving them once again: the owner identified
+// while serving the call, the things and channels it removed, and the
+// connections made and removed by it.
+type callRecorder struct {
+	owner        string
+	removed      []string
+	connected    []Connection
+	disconnected []Connection
+}
+
+// recordCall returns a copy of the provided context, in which the effects
+// of the service call are recorded to the returned recorder. If the context
+// already carries the recorder, the effects are recorded to it, so that all
+// of the decorators of the call observe the same effects.
+func recordCall(ctx context.Context) (context.Context, *callRecorder) {
+	if rec := recorder(ctx); rec != nil {
+		return ctx, rec
+	}
+
+	rec := &callRecorder{}
+	return context.WithValue(ctx, recorderKey{}, rec), rec
+}
+
+// recorder retrieves the recorder carried by the provided context. Nil is
+// returned if there is none, and the effects recorded to it are ignored.
+func recorder(ctx context.Context) *callRecorder {
+	rec, _ := ctx.Value(recorderKey{}).(*callRecorder)
+	return rec
+}
+
+func (rec *callRecorder) setOwner(owner string) {
+	if rec != nil {
+		rec.owner = owner
+	}
+}
+
+func (rec *callRecorder) remove(id string) {
+	if rec != nil {
+		rec.removed = append(rec.removed, id)
+	}
+}
+
+func (rec *callRecorder) connect(conn Connection) {
+	if rec != nil {
+		rec.connected = append(rec.connected, conn)
+	}
+}
+
+func (rec *callRecorder) disconnect(chanID, thingID string) {
+	if rec != nil {
+		rec.disconnected = append(rec.disconnected, Connection{ChanID: chanID, ThingID: thingID})
+	}
+}
@@ -0,0 +1,49 @@
+// Package redis contains Redis implementation of the things event stream.
+package redis
+
+import (
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/mainflux/mainflux/things"
+)
+
+const (
+	// streamID names the stream the events are appended to.
+	streamID = "mainflux.things"
+
+	// streamLen is the approximate number of the most recent events kept by
+	// the stream.
+	streamLen = 1000
+)
+
+var _ things.EventStream = (*eventStream)(nil)
+
+type eventStream struct {
+	client *redis.Client
+}
+
+// NewEventStream instantiates the event stream appending the events to the
+// Redis stream, as the entries holding the event's fields. The stream is
+// capped, so that only the most recent events are kept.
+func NewEventStream(client *redis.Client) things.EventStream {
+	return &eventStream{client}
+}
+
+func (es *eventStream) Publish(e things.Event) error {
+	values := map[string]interface{}{
+		"type":      e.Type,
+		"entity_id": e.EntityID,
+		"owner":     e.Owner,
+		"timestamp": e.Timestamp.UTC().Format(time.RFC3339Nano),
+	}
+	if e.ChanID != "" {
+		values["channel_id"] = e.ChanID
+	}
+
+	return es.client.XAdd(&redis.XAddArgs{
+		Stream:       streamID,
+		MaxLenApprox: streamLen,
+		Values:       values,
+	}).Err()
+}
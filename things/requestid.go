@@ -0,0 +1,18 @@
+package things
+
+import "context"
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of the provided context carrying the request
+// identifier.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID retrieves the request identifier carried by the provided context.
+// Empty string is returned if there is none.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
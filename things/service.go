@@ -2,10 +2,15 @@ package things
 
 import (
 	"context"
+	"crypto/subtle"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/mainflux/mainflux"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 var (
@@ -23,249 +28,1745 @@ var (
 
 	// ErrNotFound indicates a non-existent entity request.
 	ErrNotFound = errors.New("non-existent entity")
+
+	// ErrVersionMismatch indicates that the entity was modified since the
+	// version the update is based on was retrieved.
+	ErrVersionMismatch = errors.New("entity version mismatch")
+
+	// ErrUnavailable indicates that the service cannot serve requests
+	// because one of its dependencies is unreachable.
+	ErrUnavailable = errors.New("service unavailable")
+
+	// ErrQuotaExceeded indicates that the user already owns the maximum
+	// number of entities the quota allows.
+	ErrQuotaExceeded = errors.New("quota exceeded")
 )
 
+// BulkError wraps an error caused by a single element of a bulk request. It
+// carries the position of the offending element within the batch.
+type BulkError struct {
+	Index int
+	Err   error
+}
+
+func (e BulkError) Error() string {
+	return fmt.Sprintf("element %d: %s", e.Index, e.Err)
+}
+
+// NotConnectedError lists the channels a thing couldn't be disconnected from,
+// because it wasn't connected to them in the first place.
+type NotConnectedError struct {
+	ChanIDs []string
+}
+
+func (e NotConnectedError) Error() string {
+	return fmt.Sprintf("thing is not connected to channels %s", strings.Join(e.ChanIDs, ", "))
+}
+
+// MaxAccessBatchSize is the maximum number of access requests checked in a
+// single batch.
+const MaxAccessBatchSize = 1000
+
+// AccessRequest represents the request to access the channel having the
+// provided identifier in the provided mode using the provided thing's key.
+type AccessRequest struct {
+	ChanID string
+	Key    string
+	Mode   AccessMode
+}
+
+// AccessResult represents the outcome of the access request. It carries
+// either the identifier of the thing allowed to access the channel, or the
+// error explaining why the access was denied.
+type AccessResult struct {
+	ThingID string
+	Err     error
+}
+
 // Service specifies an API that must be fullfiled by the domain service
-// implementation, and all of its decorators (e.g. logging & metrics).
+// implementation, and all of its decorators (e.g. logging & metrics). Each
+// method takes the context of the request it serves, whose cancellation and
+// deadline apply to the calls it makes to the users service.
 type Service interface {
 	// AddThing adds new thing to the user identified by the provided key.
-	AddThing(string, Thing) (Thing, error)
+	// If the user already has the thing with the same external identifier,
+	// that thing is returned instead, marked as existing. The thing is
+	// stored under the provided ID if it is set, in which case ErrConflict
+	// is returned if the user already has the thing with that ID.
+	AddThing(context.Context, string, Thing) (Thing, error)
+
+	// CreateThings adds all of the provided things to the user identified by
+	// the provided key. Things are either created all at once, or not at all.
+	// If dry run is requested, the things are validated and returned as they
+	// would be created, but nothing is persisted.
+	CreateThings(context.Context, string, []Thing, bool) ([]Thing, error)
 
 	// UpdateThing updates the thing identified by the provided ID, that
+	// belongs to the user identified by the provided key. If the provided
+	// thing carries a non-zero version, ErrVersionMismatch is returned
+	// unless it equals the current version of the thing.
+	UpdateThing(context.Context, string, Thing) error
+
+	// UpdateKey replaces the access key of the thing identified by the
+	// provided ID, that belongs to the user identified by the provided key.
+	// The new key has to be KeyLength characters long.
+	UpdateKey(context.Context, string, string, string) error
+
+	// RotateKey replaces the access key of the thing identified by the
+	// provided ID, that belongs to the user identified by the provided key,
+	// with the newly generated one, and returns it.
+	RotateKey(context.Context, string, string) (string, error)
+
+	// DisableThing disables the thing identified by the provided ID, that
+	// belongs to the user identified by the provided key. Disabled thing
+	// keeps its connections, but it cannot access any of the channels.
+	DisableThing(context.Context, string, string) error
+
+	// EnableThing enables the thing identified by the provided ID, that
 	// belongs to the user identified by the provided key.
-	UpdateThing(string, Thing) error
+	EnableThing(context.Context, string, string) error
 
 	// ViewThing retrieves data about the thing identified with the provided
-	// ID, that belongs to the user identified by the provided key.
-	ViewThing(string, string) (Thing, error)
+	// ID, that belongs to the user identified by the provided key. The same
+	// ErrNotFound is returned whether the thing doesn't exist or belongs to
+	// another user, so the existence of other users' things isn't disclosed.
+	ViewThing(context.Context, string, string) (Thing, error)
+
+	// ViewThings retrieves data about the things identified with the
+	// provided IDs, that belong to the user identified by the provided key.
+	// Things are returned in the order of the provided IDs. Unknown things
+	// are skipped, unless the strict view is enabled, in which case
+	// ErrNotFound is returned instead.
+	ViewThings(context.Context, string, []string) ([]Thing, error)
+
+	// ViewThingByKey retrieves data about the thing identified by the
+	// provided thing key. Unlike the other view methods, the thing's own key
+	// is used instead of the user's.
+	ViewThingByKey(context.Context, string) (Thing, error)
 
 	// ListThings retrieves data about subset of things that belongs to the
-	// user identified by the provided key.
-	ListThings(string, int, int) ([]Thing, error)
+	// user identified by the provided key, and that match all of the
+	// criteria of the provided filter, sorted as specified.
+	ListThings(context.Context, string, int, int, Sorting, ThingFilter) (ThingPage, error)
+
+	// ListThingsAfter retrieves data about at most the specified number of
+	// things that belong to the user identified by the provided key, and
+	// whose identifiers follow the provided one. Things are sorted by their
+	// identifiers, so the last identifier of the retrieved page is the
+	// cursor of the next one. Empty identifier retrieves the first page.
+	ListThingsAfter(context.Context, string, string, int) ([]Thing, error)
+
+	// SearchThings retrieves data about subset of things that belongs to the
+	// user identified by the provided key, and whose names contain the
+	// provided value.
+	SearchThings(context.Context, string, string, int, int) ([]Thing, error)
+
+	// ListUnconnectedThings retrieves data about subset of things that
+	// belongs to the user identified by the provided key, and that aren't
+	// connected to any channel.
+	ListUnconnectedThings(context.Context, string, int, int) ([]Thing, error)
+
+	// ListThingsByMetadata retrieves data about subset of things that belongs
+	// to the user identified by the provided key, and whose metadata contain
+	// the provided key/value pair.
+	ListThingsByMetadata(context.Context, string, string, string, int, int) ([]Thing, error)
+
+	// ListDeletedThings retrieves data about subset of removed things that
+	// belongs to the user identified by the provided key, sorted as specified.
+	ListDeletedThings(context.Context, string, int, int, Sorting) (ThingPage, error)
+
+	// CountThings retrieves the number of things that belong to the user
+	// identified by the provided key, and match the provided filter.
+	// Removed things are not counted.
+	CountThings(context.Context, string, ThingFilter) (int, error)
 
 	// RemoveThing removes the thing identified with the provided ID, that
-	// belongs to the user identified by the provided key.
-	RemoveThing(string, string) error
+	// belongs to the user identified by the provided key, and disconnects it
+	// from all of the channels. Removed thing can be restored, but its
+	// connections are not.
+	RemoveThing(context.Context, string, string) error
+
+	// RemoveAllThings removes all of the things that belong to the user
+	// identified by the provided key, and disconnects them from all of the
+	// channels.
+	RemoveAllThings(context.Context, string) error
+
+	// RestoreThing restores the removed thing identified with the provided
+	// ID, that belongs to the user identified by the provided key.
+	RestoreThing(context.Context, string, string) error
+
+	// TransferThing transfers the thing identified by the provided ID, that
+	// belongs to the user identified by the provided key, to the user having
+	// the provided email. The thing is disconnected from all of the channels
+	// along with the transfer. ErrMalformedEntity is returned if no user
+	// having the provided email is registered.
+	TransferThing(context.Context, string, string, string) error
 
 	// CreateChannel adds new channel to the user identified by the provided key.
-	CreateChannel(string, Channel) (Channel, error)
+	CreateChannel(context.Context, string, Channel) (Channel, error)
 
 	// UpdateChannel updates the channel identified by the provided ID, that
 	// belongs to the user identified by the provided key.
-	UpdateChannel(string, Channel) error
+	UpdateChannel(context.Context, string, Channel) error
 
 	// ViewChannel retrieves data about the channel identified by the provided
 	// ID, that belongs to the user identified by the provided key.
-	ViewChannel(string, string) (Channel, error)
+	ViewChannel(context.Context, string, string) (Channel, error)
 
 	// ListChannels retrieves data about subset of channels that belongs to the
+	// user identified by the provided key, sorted as specified. The returned
+	// page also reports the total number of the user's channels matching the
+	// filter.
+	ListChannels(context.Context, string, int, int, Sorting, MetadataFilter) (ChannelPage, error)
+
+	// ListChannelsByThing retrieves data about subset of channels that have
+	// specified thing connected to them and that belong to the user identified
+	// by the provided key.
+	ListChannelsByThing(context.Context, string, string, int, int) ([]Channel, error)
+
+	// ListThingsByChannel retrieves data about subset of things that are
+	// in the specified state of connection to the specified channel, and that
+	// belong to the user identified by the provided key. Things disconnected
+	// within the disconnection window count as recently disconnected.
+	ListThingsByChannel(context.Context, string, string, int, int, ConnectionState) ([]Thing, error)
+
+	// CountChannels retrieves the number of channels that belong to the user
+	// identified by the provided key.
+	CountChannels(context.Context, string) (int, error)
+
+	// ChannelConnectionsCount retrieves the number of things connected to
+	// the channel identified by the provided ID, that belongs to the user
+	// identified by the provided key.
+	ChannelConnectionsCount(context.Context, string, string) (int, error)
+
+	// ChannelConnections retrieves the connections of all of the things
+	// connected to the channel identified by the provided ID, that belongs to
+	// the user identified by the provided key, sorted by the time they were
+	// made.
+	ChannelConnections(context.Context, string, string) ([]Connection, error)
+
+	// RemoveChannel removes the channel identified by the provided ID, that
+	// belongs to the user identified by the provided key. Removed channel
+	// keeps its connections, and can be restored.
+	RemoveChannel(context.Context, string, string) error
+
+	// RestoreChannel restores the removed channel identified with the
+	// provided ID, that belongs to the user identified by the provided key.
+	RestoreChannel(context.Context, string, string) error
+
+	// RemoveAllChannels removes all of the channels that belong to the user
+	// identified by the provided key, and disconnects all of the things from
+	// them.
+	RemoveAllChannels(context.Context, string) error
+
+	// TransferChannel transfers the channel identified by the provided ID,
+	// that belongs to the user identified by the provided key, to the user
+	// having the provided email. All of the things are disconnected from the
+	// channel before it is transferred. ErrMalformedEntity is returned if no
+	// user having the provided email is registered.
+	TransferChannel(context.Context, string, string, string) error
+
+	// Connect adds thing to the channel's list of connected things with the
+	// provided access mode, and returns the resulting connection. Connecting
+	// the already connected thing replaces the mode of its connection.
+	// ErrNotFound is returned if either the channel or the thing doesn't
+	// exist, ErrUnauthorizedAccess if either of them belongs to another
+	// user, and ErrMalformedEntity if the access mode is unknown.
+	Connect(context.Context, string, string, string, AccessMode) (Connection, error)
+
+	// ConnectMany connects the thing to all of the specified channels at
+	// once. If any of the channels doesn't exist, none of the connections
+	// is made.
+	ConnectMany(context.Context, string, string, []string) error
+
+	// ConnectThings connects all of the specified things to the channel at
+	// once. If the channel or any of the things doesn't exist, none of the
+	// connections is made.
+	ConnectThings(context.Context, string, string, []string) error
+
+	// SetChannelThings makes the specified things the only ones connected
+	// to the channel, by connecting the missing things and disconnecting the
+	// rest of them. If the channel or any of the things doesn't exist, the
+	// connections are left intact.
+	SetChannelThings(context.Context, string, string, []string) error
+
+	// Disconnect removes thing from the channel's list of connected
+	// things, and reports whether the thing was connected to the channel, so
+	// that disconnecting it again changes nothing. Missing and other users'
+	// channels and things are reported the same way as by Connect.
+	Disconnect(context.Context, string, string, string) (bool, error)
+
+	// DisconnectMany disconnects the thing from each of the specified
+	// channels. Disconnecting is attempted for every channel, and the
+	// channels the thing wasn't connected to are reported through the
+	// NotConnectedError.
+	DisconnectMany(context.Context, string, string, []string) error
+
+	// DisconnectAll removes thing from the lists of connected things of all
+	// of the channels that belong to the user identified by the provided key.
+	DisconnectAll(context.Context, string, string) error
+
+	// RebindConnections connects the thing identified by the second provided
+	// ID to all of the channels the thing identified by the first one is
+	// connected to, and disconnects the latter from them. Both things must
+	// belong to the user identified by the provided key.
+	RebindConnections(context.Context, string, string, string) error
+
+	// ThingChannelIDs retrieves the identifiers of all of the channels the
+	// thing identified by the provided ID is connected to, sorted in
+	// ascending order. The thing must belong to the user identified by the
+	// provided key.
+	ThingChannelIDs(context.Context, string, string) ([]string, error)
+
+	// IsConnected determines whether the thing identified by the provided ID
+	// is connected to the specified channel. Both of them must belong to the
 	// user identified by the provided key.
-	ListChannels(string, int, int) ([]Channel, error)
+	IsConnected(context.Context, string, string, string) (bool, error)
+
+	// CanAccess determines whether the channel can be accessed in the
+	// requested access mode using the provided key and returns thing's id if
+	// access is allowed. The access is denied if the mode of the thing's
+	// connection doesn't permit it.
+	CanAccess(context.Context, string, string, AccessMode) (string, error)
+
+	// CanAccessBatch checks each of the provided access requests the same
+	// way CanAccess does, and returns their results in the same order. A
+	// denied request doesn't affect the others, so each of the requests gets
+	// its result. ErrMalformedEntity is returned if the batch is larger than
+	// MaxAccessBatchSize.
+	CanAccessBatch(context.Context, []AccessRequest) ([]AccessResult, error)
+
+	// ChannelOwner retrieves the owner of the channel identified by the
+	// provided ID. It is meant for the other services, so instead of the
+	// user's key, the service key configured with WithServiceKey is used.
+	ChannelOwner(context.Context, string, string) (string, error)
+
+	// PurgeDeleted permanently removes the things and channels of all of
+	// the users, that were removed before the provided time, and returns
+	// their number. Like ChannelOwner, it is authorized by the service key
+	// instead of the user's key.
+	PurgeDeleted(context.Context, string, time.Time) (int, error)
+
+	// Health checks whether the service is able to serve requests. It
+	// returns ErrUnavailable if either the users service or the storage of
+	// things and channels cannot be reached.
+	Health(context.Context) error
+}
 
-	// RemoveChannel removes the thing identified by the provided ID, that
-	// belongs to the user identified by the provided key.
-	RemoveChannel(string, string) error
+var _ Service = (*thingsService)(nil)
 
-	// Connect adds thing to the channel's list of connected things.
-	Connect(string, string, string) error
+// DefaultIdentifyTimeout is the default period the service waits for the
+// users service to identify the user.
+const DefaultIdentifyTimeout = time.Second
 
-	// Disconnect removes thing from the channel's list of connected
-	// things.
-	Disconnect(string, string, string) error
+// DefaultIdentifyAttempts is the default number of attempts the service makes
+// to identify the user, if the users service is temporarily unavailable.
+const DefaultIdentifyAttempts = 3
 
-	// CanAccess determines whether the channel can be accessed using the
-	// provided key and returns thing's id if access is allowed.
-	CanAccess(string, string) (string, error)
-}
+// identifyBackoff is the delay before the first retry of the identification,
+// doubled before each of the following retries.
+const identifyBackoff = 50 * time.Millisecond
 
-var _ Service = (*thingsService)(nil)
+// connectionsPageSize is the number of entities whose connections are looked
+// up at once when all of the user's connections are recorded.
+const connectionsPageSize = 100
+
+// DefaultDisconnectionWindow is the default period during which the things
+// disconnected from the channel are listed as recently disconnected.
+const DefaultDisconnectionWindow = 24 * time.Hour
 
 type thingsService struct {
-	users    mainflux.UsersServiceClient
-	things   ThingRepository
-	channels ChannelRepository
-	idp      IdentityProvider
+	users         mainflux.UsersServiceClient
+	things        ThingRepository
+	channels      ChannelRepository
+	idp           IdentityProvider
+	keys          IdentityProvider
+	timeout       time.Duration
+	attempts      int
+	disconnWindow time.Duration
+	serviceKey    string
+	uniqueChNames bool
+	strictView    bool
+	quota         Quota
+	hooks         WebhookPolicy
 }
 
-// New instantiates the things service implementation.
-func New(users mainflux.UsersServiceClient, things ThingRepository, channels ChannelRepository, idp IdentityProvider) Service {
-	return &thingsService{
-		users:    users,
-		things:   things,
-		channels: channels,
-		idp:      idp,
+// Option configures the things service implementation.
+type Option func(*thingsService)
+
+// WithIdentifyTimeout sets the period the service waits for the users
+// service to identify the user. DefaultIdentifyTimeout is used if the option
+// is omitted.
+func WithIdentifyTimeout(d time.Duration) Option {
+	return func(ts *thingsService) {
+		ts.timeout = d
 	}
 }
 
-func (ts *thingsService) AddThing(key string, thing Thing) (Thing, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-	defer cancel()
+// WithIdentifyAttempts sets the maximum number of attempts the service makes
+// to identify the user, retrying only the calls that failed because the users
+// service was temporarily unavailable. All of the attempts are bound by the
+// identification timeout. ErrUnavailable is returned if none of them succeeds.
+// DefaultIdentifyAttempts is used if the option is omitted.
+func WithIdentifyAttempts(n int) Option {
+	return func(ts *thingsService) {
+		ts.attempts = n
+	}
+}
+
+// WithDisconnectionWindow sets the period during which the things
+// disconnected from the channel are listed as recently disconnected.
+// DefaultDisconnectionWindow is used if the option is omitted.
+func WithDisconnectionWindow(d time.Duration) Option {
+	return func(ts *thingsService) {
+		ts.disconnWindow = d
+	}
+}
+
+// WithKeyProvider sets the provider of the things' access keys. Unlike
+// identifiers, keys must not be predictable, so the provider must generate
+// random values. The provider of identifiers is used if the option is
+// omitted.
+func WithKeyProvider(keys IdentityProvider) Option {
+	return func(ts *thingsService) {
+		ts.keys = keys
+	}
+}
+
+// WithUniqueChannelNames makes the service reject the channels named the same
+// as the other channel of the same owner with ErrConflict. Names don't have
+// to be unique if the option is omitted.
+func WithUniqueChannelNames() Option {
+	return func(ts *thingsService) {
+		ts.uniqueChNames = true
+	}
+}
+
+// WithQuota sets the quota limiting the number of things and channels each
+// user can create. The number is unlimited if the option is omitted.
+func WithQuota(quota Quota) Option {
+	return func(ts *thingsService) {
+		ts.quota = quota
+	}
+}
 
-	res, err := ts.users.Identify(ctx, &mainflux.Token{Value: key})
+// WithStrictView makes the service reject the views of multiple things that
+// include any unknown thing, instead of skipping it.
+func WithStrictView() Option {
+	return func(ts *thingsService) {
+		ts.strictView = true
+	}
+}
+
+// WithWebhookPolicy sets the policy restricting the hosts targeted by the
+// channels' webhooks. If the option is omitted, the webhooks can't target
+// the DefaultWebhookDeniedNets.
+func WithWebhookPolicy(policy WebhookPolicy) Option {
+	return func(ts *thingsService) {
+		ts.hooks = policy
+	}
+}
+
+// WithServiceKey sets the key the other services use to access the
+// service-to-service API. If the option is omitted, that API rejects all of
+// the requests.
+func WithServiceKey(key string) Option {
+	return func(ts *thingsService) {
+		ts.serviceKey = key
+	}
+}
+
+// New instantiates the things service implementation. The provided identity
+// provider generates the identifiers of things and channels.
+func New(users mainflux.UsersServiceClient, things ThingRepository, channels ChannelRepository, idp IdentityProvider, opts ...Option) Service {
+	ts := &thingsService{
+		users:         users,
+		things:        things,
+		channels:      channels,
+		idp:           idp,
+		keys:          idp,
+		timeout:       DefaultIdentifyTimeout,
+		attempts:      DefaultIdentifyAttempts,
+		disconnWindow: DefaultDisconnectionWindow,
+		hooks:         defaultWebhookPolicy,
+	}
+
+	for _, opt := range opts {
+		opt(ts)
+	}
+
+	return ts
+}
+
+func (ts *thingsService) AddThing(ctx context.Context, key string, thing Thing) (Thing, error) {
+	owner, err := ts.identify(ctx, key)
 	if err != nil {
-		return Thing{}, ErrUnauthorizedAccess
+		return Thing{}, err
 	}
 
-	// TODO: drop completely in a separate ticket
-	thing.ID = ts.idp.ID()
-	thing.Owner = res.GetValue()
-	thing.Key = ts.idp.ID()
+	if err := thing.Validate(); err != nil {
+		return Thing{}, err
+	}
+
+	if thing.ExternalID != "" {
+		existing, err := ts.things.ByExternalID(ctx, owner, thing.ExternalID)
+		if err == nil {
+			existing.Existing = true
+			return existing, nil
+		}
+
+		if err != ErrNotFound {
+			return Thing{}, err
+		}
+	}
+
+	if err := ts.checkThingsQuota(ctx, owner, 1); err != nil {
+		return Thing{}, err
+	}
+
+	if thing.ID != "" {
+		if _, err := ts.things.One(ctx, owner, thing.ID); err == nil {
+			return Thing{}, ErrConflict
+		}
+	} else {
+		// TODO: drop completely in a separate ticket
+		thing.ID = ts.idp.ID()
+	}
+
+	thing.Owner = owner
+	thing.Key = ts.keys.ID()
+	thing.Status = StatusEnabled
+	thing.CreatedAt = time.Now().UTC()
+	thing.UpdatedAt = thing.CreatedAt
+	thing.Version = 1
 
-	if _, err := ts.things.Save(thing); err != nil {
+	if _, err := ts.things.Save(ctx, thing); err != nil {
 		return Thing{}, err
 	}
 
 	return thing, nil
 }
 
-func (ts *thingsService) UpdateThing(key string, thing Thing) error {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-	defer cancel()
+func (ts *thingsService) CreateThings(ctx context.Context, key string, things []Thing, dryRun bool) ([]Thing, error) {
+	owner, err := ts.identify(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	created := make([]Thing, len(things))
+	for i, thing := range things {
+		if err := thing.Validate(); err != nil {
+			return nil, BulkError{Index: i, Err: err}
+		}
+		created[i] = thing
+	}
+
+	if err := ts.checkThingsQuota(ctx, owner, len(created)); err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		if err := ts.checkCollisions(ctx, owner, created); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now().UTC()
+	for i, thing := range created {
+		thing.ID = ts.idp.ID()
+		thing.Owner = owner
+		thing.Key = ts.keys.ID()
+		thing.Status = StatusEnabled
+		thing.CreatedAt = now
+		thing.UpdatedAt = now
+		thing.Version = 1
+		created[i] = thing
+	}
+
+	if dryRun {
+		return created, nil
+	}
+
+	if _, err := ts.things.SaveBulk(ctx, created); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// checkThingsQuota returns ErrQuotaExceeded if creating the provided number
+// of things would make the specified user own more things than allowed.
+func (ts *thingsService) checkThingsQuota(ctx context.Context, owner string, n int) error {
+	if ts.quota == nil {
+		return nil
+	}
 
-	res, err := ts.users.Identify(ctx, &mainflux.Token{Value: key})
+	limit, err := ts.quota.Things(ctx, owner)
 	if err != nil {
-		return ErrUnauthorizedAccess
+		return err
 	}
 
-	thing.Owner = res.GetValue()
+	if limit > 0 && ts.things.Count(ctx, owner, ThingFilter{})+n > limit {
+		return ErrQuotaExceeded
+	}
 
-	return ts.things.Update(thing)
+	return nil
 }
 
-func (ts *thingsService) ViewThing(key, id string) (Thing, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-	defer cancel()
+// checkCollisions returns ErrConflict wrapped into BulkError for the first
+// thing whose external ID is either repeated within the batch, or already
+// taken by the owner's thing. Otherwise, the repository would reject the batch.
+func (ts *thingsService) checkCollisions(ctx context.Context, owner string, things []Thing) error {
+	extIDs := make(map[string]bool, len(things))
+	for i, thing := range things {
+		if thing.ExternalID == "" {
+			continue
+		}
+
+		if extIDs[thing.ExternalID] {
+			return BulkError{Index: i, Err: ErrConflict}
+		}
+		extIDs[thing.ExternalID] = true
+
+		_, err := ts.things.ByExternalID(ctx, owner, thing.ExternalID)
+		switch err {
+		case nil:
+			return BulkError{Index: i, Err: ErrConflict}
+		case ErrNotFound:
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
 
-	res, err := ts.users.Identify(ctx, &mainflux.Token{Value: key})
+func (ts *thingsService) UpdateThing(ctx context.Context, key string, thing Thing) error {
+	owner, err := ts.identify(ctx, key)
 	if err != nil {
-		return Thing{}, ErrUnauthorizedAccess
+		return err
+	}
+
+	if err := thing.Validate(); err != nil {
+		return err
 	}
 
-	return ts.things.One(res.GetValue(), id)
+	thing.Owner = owner
+	thing.UpdatedAt = time.Now().UTC()
+
+	return ts.things.Update(ctx, thing)
 }
 
-func (ts *thingsService) ListThings(key string, offset, limit int) ([]Thing, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-	defer cancel()
+func (ts *thingsService) UpdateKey(ctx context.Context, key, id, newKey string) error {
+	owner, err := ts.identify(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if err := validateKey(newKey); err != nil {
+		return err
+	}
+
+	return ts.things.UpdateKey(ctx, owner, id, newKey)
+}
 
-	res, err := ts.users.Identify(ctx, &mainflux.Token{Value: key})
+func (ts *thingsService) RotateKey(ctx context.Context, key, id string) (string, error) {
+	owner, err := ts.identify(ctx, key)
 	if err != nil {
-		return nil, ErrUnauthorizedAccess
+		return "", err
+	}
+
+	newKey := ts.keys.ID()
+	if err := ts.things.UpdateKey(ctx, owner, id, newKey); err != nil {
+		return "", err
 	}
 
-	return ts.things.All(res.GetValue(), offset, limit), nil
+	return newKey, nil
 }
 
-func (ts *thingsService) RemoveThing(key, id string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-	defer cancel()
+func (ts *thingsService) DisableThing(ctx context.Context, key, id string) error {
+	return ts.updateStatus(ctx, key, id, StatusDisabled)
+}
+
+func (ts *thingsService) EnableThing(ctx context.Context, key, id string) error {
+	return ts.updateStatus(ctx, key, id, StatusEnabled)
+}
 
-	res, err := ts.users.Identify(ctx, &mainflux.Token{Value: key})
+func (ts *thingsService) updateStatus(ctx context.Context, key, id, status string) error {
+	owner, err := ts.identify(ctx, key)
 	if err != nil {
-		return ErrUnauthorizedAccess
+		return err
 	}
 
-	return ts.things.Remove(res.GetValue(), id)
+	return ts.things.UpdateStatus(ctx, owner, id, status)
 }
 
-func (ts *thingsService) CreateChannel(key string, channel Channel) (Channel, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-	defer cancel()
+func (ts *thingsService) ViewThing(ctx context.Context, key, id string) (Thing, error) {
+	owner, err := ts.identify(ctx, key)
+	if err != nil {
+		return Thing{}, err
+	}
+
+	return ts.things.One(ctx, owner, id)
+}
 
-	res, err := ts.users.Identify(ctx, &mainflux.Token{Value: key})
+func (ts *thingsService) ViewThings(ctx context.Context, key string, ids []string) ([]Thing, error) {
+	owner, err := ts.identify(ctx, key)
 	if err != nil {
-		return Channel{}, ErrUnauthorizedAccess
+		return nil, err
 	}
 
-	// TODO: drop completely in a separate ticket
-	channel.ID = ts.idp.ID()
-	channel.Owner = res.GetValue()
+	found := map[string]Thing{}
+	for _, thing := range ts.things.Multi(ctx, owner, ids) {
+		found[thing.ID] = thing
+	}
 
-	if _, err := ts.channels.Save(channel); err != nil {
-		return Channel{}, err
+	ths := make([]Thing, 0, len(ids))
+	for _, id := range ids {
+		thing, ok := found[id]
+		if !ok {
+			if ts.strictView {
+				return nil, ErrNotFound
+			}
+			continue
+		}
+		ths = append(ths, thing)
 	}
 
-	return channel, nil
+	return ths, nil
 }
 
-func (ts *thingsService) UpdateChannel(key string, channel Channel) error {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-	defer cancel()
+func (ts *thingsService) ViewThingByKey(ctx context.Context, key string) (Thing, error) {
+	return ts.things.ByKey(ctx, key)
+}
 
-	res, err := ts.users.Identify(ctx, &mainflux.Token{Value: key})
+func (ts *thingsService) ListThings(ctx context.Context, key string, offset, limit int, sorting Sorting, filter ThingFilter) (ThingPage, error) {
+	owner, err := ts.identify(ctx, key)
 	if err != nil {
-		return ErrUnauthorizedAccess
+		return ThingPage{}, err
 	}
 
-	channel.Owner = res.GetValue()
-	return ts.channels.Update(channel)
+	if err := filter.validate(); err != nil {
+		return ThingPage{}, err
+	}
+
+	return ts.things.All(ctx, owner, offset, limit, sorting, filter), nil
 }
 
-func (ts *thingsService) ViewChannel(key, id string) (Channel, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-	defer cancel()
+func (ts *thingsService) ListThingsAfter(ctx context.Context, key, afterID string, limit int) ([]Thing, error) {
+	owner, err := ts.identify(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return ts.things.After(ctx, owner, afterID, limit), nil
+}
 
-	res, err := ts.users.Identify(ctx, &mainflux.Token{Value: key})
+func (ts *thingsService) SearchThings(ctx context.Context, key, name string, offset, limit int) ([]Thing, error) {
+	owner, err := ts.identify(ctx, key)
 	if err != nil {
-		return Channel{}, ErrUnauthorizedAccess
+		return nil, err
 	}
 
-	return ts.channels.One(res.GetValue(), id)
+	return ts.things.Search(ctx, owner, name, offset, limit), nil
 }
 
-func (ts *thingsService) ListChannels(key string, offset, limit int) ([]Channel, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-	defer cancel()
+func (ts *thingsService) ListUnconnectedThings(ctx context.Context, key string, offset, limit int) ([]Thing, error) {
+	owner, err := ts.identify(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return ts.channels.UnconnectedThings(ctx, owner, offset, limit), nil
+}
 
-	res, err := ts.users.Identify(ctx, &mainflux.Token{Value: key})
+func (ts *thingsService) ListThingsByMetadata(ctx context.Context, key, metaKey, metaValue string, offset, limit int) ([]Thing, error) {
+	owner, err := ts.identify(ctx, key)
 	if err != nil {
-		return nil, ErrUnauthorizedAccess
+		return nil, err
 	}
 
-	return ts.channels.All(res.GetValue(), offset, limit), nil
+	return ts.things.AllByMetadata(ctx, owner, metaKey, metaValue, offset, limit), nil
 }
 
-func (ts *thingsService) RemoveChannel(key, id string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-	defer cancel()
+func (ts *thingsService) ListDeletedThings(ctx context.Context, key string, offset, limit int, sorting Sorting) (ThingPage, error) {
+	owner, err := ts.identify(ctx, key)
+	if err != nil {
+		return ThingPage{}, err
+	}
+
+	return ts.things.AllDeleted(ctx, owner, offset, limit, sorting), nil
+}
 
-	res, err := ts.users.Identify(ctx, &mainflux.Token{Value: key})
+func (ts *thingsService) CountThings(ctx context.Context, key string, filter ThingFilter) (int, error) {
+	owner, err := ts.identify(ctx, key)
 	if err != nil {
-		return ErrUnauthorizedAccess
+		return 0, err
+	}
+
+	if err := filter.validate(); err != nil {
+		return 0, err
 	}
 
-	return ts.channels.Remove(res.GetValue(), id)
+	return ts.things.Count(ctx, owner, filter), nil
 }
 
-func (ts *thingsService) Connect(key, chanID, thingID string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-	defer cancel()
+func (ts *thingsService) RemoveThing(ctx context.Context, key, id string) error {
+	owner, err := ts.identify(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	// removing the missing thing changes nothing, so it isn't recorded
+	removed := false
+	if recorder(ctx) != nil {
+		_, err := ts.things.One(ctx, owner, id)
+		removed = err == nil
+	}
+
+	if err := ts.things.Remove(ctx, owner, id); err != nil {
+		return err
+	}
+
+	if removed {
+		recorder(ctx).remove(id)
+	}
+
+	return ts.disconnectAll(ctx, owner, id)
+}
 
-	res, err := ts.users.Identify(ctx, &mainflux.Token{Value: key})
+func (ts *thingsService) RemoveAllThings(ctx context.Context, key string) error {
+	owner, err := ts.identify(ctx, key)
 	if err != nil {
-		return ErrUnauthorizedAccess
+		return err
+	}
+
+	// the connections are collected only if they are recorded, since
+	// there may be plenty of them
+	var ids []string
+	var conns []Connection
+	if rec := recorder(ctx); rec != nil {
+		if ids, conns, err = ts.ownedThings(ctx, owner); err != nil {
+			return err
+		}
+	}
+
+	if err := ts.things.RemoveAll(ctx, owner); err != nil {
+		return err
 	}
 
-	return ts.channels.Connect(res.GetValue(), chanID, thingID)
+	if err := ts.channels.DisconnectAllThings(ctx, owner); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		recorder(ctx).remove(id)
+	}
+	for _, conn := range conns {
+		recorder(ctx).disconnect(conn.ChanID, conn.ThingID)
+	}
+
+	return nil
 }
 
-func (ts *thingsService) Disconnect(key, chanID, thingID string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-	defer cancel()
+func (ts *thingsService) RestoreThing(ctx context.Context, key, id string) error {
+	owner, err := ts.identify(ctx, key)
+	if err != nil {
+		return err
+	}
 
-	res, err := ts.users.Identify(ctx, &mainflux.Token{Value: key})
+	return ts.things.Restore(ctx, owner, id)
+}
+
+func (ts *thingsService) TransferThing(ctx context.Context, key, id, newOwner string) error {
+	owner, err := ts.identify(ctx, key)
 	if err != nil {
-		return ErrUnauthorizedAccess
+		return err
+	}
+
+	if _, err := ts.things.One(ctx, owner, id); err != nil {
+		return err
+	}
+
+	if newOwner == owner {
+		return nil
+	}
+
+	if newOwner, err = ts.resolveOwner(ctx, newOwner); err != nil {
+		return err
+	}
+
+	// the transferred thing is disconnected from the channels of its
+	// former owner by the repository, along with the owner change
+	var chanIDs []string
+	if recorder(ctx) != nil {
+		if chanIDs, err = ts.channels.Connections(ctx, owner, id); err != nil {
+			return err
+		}
+	}
+
+	if err := ts.things.ChangeOwner(ctx, owner, id, newOwner); err != nil {
+		return err
+	}
+
+	for _, chanID := range chanIDs {
+		recorder(ctx).disconnect(chanID, id)
 	}
 
-	return ts.channels.Disconnect(res.GetValue(), chanID, thingID)
+	return nil
 }
 
-func (ts *thingsService) CanAccess(key, channel string) (string, error) {
-	thingID, err := ts.channels.HasThing(channel, key)
+func (ts *thingsService) CreateChannel(ctx context.Context, key string, channel Channel) (Channel, error) {
+	owner, err := ts.identify(ctx, key)
 	if err != nil {
-		return "", ErrUnauthorizedAccess
+		return Channel{}, err
 	}
 
-	return thingID, nil
+	if err := ts.validateChannel(channel); err != nil {
+		return Channel{}, err
+	}
+
+	if err := ts.checkChannelName(ctx, owner, "", channel.Name); err != nil {
+		return Channel{}, err
+	}
+
+	if err := ts.checkChannelsQuota(ctx, owner); err != nil {
+		return Channel{}, err
+	}
+
+	// TODO: drop completely in a separate ticket
+	channel.ID = ts.idp.ID()
+	channel.Owner = owner
+	channel.CreatedAt = time.Now().UTC()
+	channel.UpdatedAt = channel.CreatedAt
+
+	if _, err := ts.channels.Save(ctx, channel); err != nil {
+		return Channel{}, err
+	}
+
+	return channel, nil
+}
+
+func (ts *thingsService) UpdateChannel(ctx context.Context, key string, channel Channel) error {
+	owner, err := ts.identify(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if err := ts.validateChannel(channel); err != nil {
+		return err
+	}
+
+	if err := ts.checkChannelName(ctx, owner, channel.ID, channel.Name); err != nil {
+		return err
+	}
+
+	channel.Owner = owner
+	channel.UpdatedAt = time.Now().UTC()
+
+	return ts.channels.Update(ctx, channel)
+}
+
+// checkChannelsQuota returns ErrQuotaExceeded if the specified user already
+// owns as many channels as allowed.
+func (ts *thingsService) checkChannelsQuota(ctx context.Context, owner string) error {
+	if ts.quota == nil {
+		return nil
+	}
+
+	limit, err := ts.quota.Channels(ctx, owner)
+	if err != nil {
+		return err
+	}
+
+	if limit > 0 && ts.channels.Count(ctx, owner) >= limit {
+		return ErrQuotaExceeded
+	}
+
+	return nil
+}
+
+// validateChannel validates the channel, including whether its webhook
+// targets the permitted host.
+func (ts *thingsService) validateChannel(channel Channel) error {
+	if err := channel.Validate(); err != nil {
+		return err
+	}
+
+	if channel.Webhook != nil {
+		return ts.hooks.Check(*channel.Webhook)
+	}
+
+	return nil
+}
+
+// checkChannelName returns ErrConflict if channel names must be unique, and
+// the channel other than the one identified by the provided ID, that is owned
+// by the specified user, already has the provided name.
+func (ts *thingsService) checkChannelName(ctx context.Context, owner, id, name string) error {
+	if !ts.uniqueChNames {
+		return nil
+	}
+
+	channel, err := ts.channels.ByName(ctx, owner, name)
+	switch err {
+	case nil:
+		if channel.ID != id {
+			return ErrConflict
+		}
+		return nil
+	case ErrNotFound:
+		return nil
+	default:
+		return err
+	}
+}
+
+func (ts *thingsService) ViewChannel(ctx context.Context, key, id string) (Channel, error) {
+	owner, err := ts.identify(ctx, key)
+	if err != nil {
+		return Channel{}, err
+	}
+
+	return ts.channels.One(ctx, owner, id)
+}
+
+func (ts *thingsService) ListChannels(ctx context.Context, key string, offset, limit int, sorting Sorting, filter MetadataFilter) (ChannelPage, error) {
+	owner, err := ts.identify(ctx, key)
+	if err != nil {
+		return ChannelPage{}, err
+	}
+
+	return ts.channels.All(ctx, owner, offset, limit, sorting, filter), nil
+}
+
+func (ts *thingsService) ListChannelsByThing(ctx context.Context, key, thingID string, offset, limit int) ([]Channel, error) {
+	owner, err := ts.identify(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return ts.channels.AllByThing(ctx, owner, thingID, offset, limit), nil
+}
+
+func (ts *thingsService) ListThingsByChannel(ctx context.Context, key, chanID string, offset, limit int, state ConnectionState) ([]Thing, error) {
+	owner, err := ts.identify(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := ConnectionFilter{
+		State:             state,
+		DisconnectedSince: time.Now().UTC().Add(-ts.disconnWindow),
+	}
+
+	return ts.channels.Things(ctx, owner, chanID, offset, limit, filter), nil
+}
+
+func (ts *thingsService) CountChannels(ctx context.Context, key string) (int, error) {
+	owner, err := ts.identify(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+
+	return ts.channels.Count(ctx, owner), nil
+}
+
+func (ts *thingsService) ChannelConnectionsCount(ctx context.Context, key, chanID string) (int, error) {
+	owner, err := ts.identify(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+
+	return ts.channels.CountThings(ctx, owner, chanID)
+}
+
+func (ts *thingsService) ChannelConnections(ctx context.Context, key, chanID string) ([]Connection, error) {
+	owner, err := ts.identify(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := ts.channels.One(ctx, owner, chanID); err != nil {
+		return nil, err
+	}
+
+	return ts.channels.ChannelConnections(ctx, owner, chanID)
+}
+
+func (ts *thingsService) RemoveChannel(ctx context.Context, key, id string) error {
+	owner, err := ts.identify(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	// the removed channel keeps its connections, but its things can no
+	// longer use them
+	conns, err := ts.liveConnections(ctx, owner, id)
+	if err != nil {
+		return err
+	}
+
+	// removing the missing channel changes nothing, so it isn't recorded
+	removed := false
+	if recorder(ctx) != nil {
+		_, err := ts.channels.One(ctx, owner, id)
+		removed = err == nil
+	}
+
+	if err := ts.channels.Remove(ctx, owner, id); err != nil {
+		return err
+	}
+
+	if removed {
+		recorder(ctx).remove(id)
+	}
+	for _, conn := range conns {
+		recorder(ctx).disconnect(conn.ChanID, conn.ThingID)
+	}
+
+	return nil
+}
+
+func (ts *thingsService) RestoreChannel(ctx context.Context, key, id string) error {
+	owner, err := ts.identify(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if err := ts.channels.Restore(ctx, owner, id); err != nil {
+		return err
+	}
+
+	conns, err := ts.liveConnections(ctx, owner, id)
+	for _, conn := range conns {
+		recorder(ctx).connect(conn)
+	}
+
+	return err
+}
+
+func (ts *thingsService) RemoveAllChannels(ctx context.Context, key string) error {
+	owner, err := ts.identify(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	// the connections are collected only if they are recorded, since
+	// there may be plenty of them
+	var ids []string
+	var conns []Connection
+	if rec := recorder(ctx); rec != nil {
+		if ids, conns, err = ts.ownedChannels(ctx, owner); err != nil {
+			return err
+		}
+	}
+
+	if err := ts.channels.RemoveAll(ctx, owner); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		recorder(ctx).remove(id)
+	}
+	for _, conn := range conns {
+		recorder(ctx).disconnect(conn.ChanID, conn.ThingID)
+	}
+
+	return nil
+}
+
+func (ts *thingsService) TransferChannel(ctx context.Context, key, id, newOwner string) error {
+	owner, err := ts.identify(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if _, err := ts.channels.One(ctx, owner, id); err != nil {
+		return err
+	}
+
+	if newOwner == owner {
+		return nil
+	}
+
+	if newOwner, err = ts.resolveOwner(ctx, newOwner); err != nil {
+		return err
+	}
+
+	// the transferred channel is disconnected from the things of its
+	// former owner
+	conns, err := ts.liveConnections(ctx, owner, id)
+	if err != nil {
+		return err
+	}
+
+	if err := ts.channels.ChangeOwner(ctx, owner, id, newOwner); err != nil {
+		return err
+	}
+
+	for _, conn := range conns {
+		recorder(ctx).disconnect(conn.ChanID, conn.ThingID)
+	}
+
+	return nil
+}
+
+func (ts *thingsService) Connect(ctx context.Context, key, chanID, thingID string, mode AccessMode) (Connection, error) {
+	owner, err := ts.identify(ctx, key)
+	if err != nil {
+		return Connection{}, err
+	}
+
+	if err := mode.Validate(); err != nil {
+		return Connection{}, err
+	}
+
+	if err := ts.checkConnectable(ctx, owner, chanID, thingID); err != nil {
+		return Connection{}, err
+	}
+
+	conn, err := ts.channels.Connect(ctx, owner, chanID, thingID, mode)
+	if err != nil {
+		return Connection{}, err
+	}
+	recorder(ctx).connect(conn)
+
+	return conn, nil
+}
+
+func (ts *thingsService) ConnectMany(ctx context.Context, key, thingID string, chanIDs []string) error {
+	owner, err := ts.identify(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	// the connections made before are left intact, so only the rest of
+	// them are recorded
+	var connected []string
+	if rec := recorder(ctx); rec != nil {
+		if connected, err = ts.channels.Connections(ctx, owner, thingID); err != nil {
+			return err
+		}
+	}
+
+	if err := ts.channels.ConnectMany(ctx, owner, thingID, chanIDs); err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool, len(connected))
+	for _, id := range connected {
+		seen[id] = true
+	}
+	for _, id := range chanIDs {
+		if !seen[id] {
+			seen[id] = true
+			recorder(ctx).connect(Connection{ChanID: id, ThingID: thingID, Mode: AccessPubSub})
+		}
+	}
+
+	return nil
+}
+
+func (ts *thingsService) ConnectThings(ctx context.Context, key, chanID string, thingIDs []string) error {
+	owner, err := ts.identify(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	// the connections made before are left intact, so only the rest of
+	// them are recorded
+	var conns []Connection
+	if rec := recorder(ctx); rec != nil {
+		if conns, err = ts.channels.ChannelConnections(ctx, owner, chanID); err != nil {
+			return err
+		}
+	}
+
+	if err := ts.channels.ConnectThings(ctx, owner, chanID, thingIDs); err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool, len(conns))
+	for _, conn := range conns {
+		seen[conn.ThingID] = true
+	}
+	for _, id := range thingIDs {
+		if !seen[id] {
+			seen[id] = true
+			recorder(ctx).connect(Connection{ChanID: chanID, ThingID: id, Mode: AccessPubSub})
+		}
+	}
+
+	return nil
+}
+
+func (ts *thingsService) SetChannelThings(ctx context.Context, key, chanID string, thingIDs []string) error {
+	owner, err := ts.identify(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if _, err := ts.channels.One(ctx, owner, chanID); err != nil {
+		return err
+	}
+
+	conns, err := ts.channels.ChannelConnections(ctx, owner, chanID)
+	if err != nil {
+		return err
+	}
+
+	desired := make(map[string]bool, len(thingIDs))
+	for _, id := range thingIDs {
+		desired[id] = true
+	}
+
+	current := make(map[string]bool, len(conns))
+	for _, conn := range conns {
+		current[conn.ThingID] = true
+	}
+
+	missing := []string{}
+	for _, id := range thingIDs {
+		if !current[id] {
+			missing = append(missing, id)
+			current[id] = true
+		}
+	}
+
+	// missing things are connected first, so that the connections remain
+	// intact if any of the desired things doesn't exist
+	if len(missing) > 0 {
+		if err := ts.channels.ConnectThings(ctx, owner, chanID, missing); err != nil {
+			return err
+		}
+	}
+
+	for _, id := range missing {
+		recorder(ctx).connect(Connection{ChanID: chanID, ThingID: id, Mode: AccessPubSub})
+	}
+
+	for _, conn := range conns {
+		if desired[conn.ThingID] {
+			continue
+		}
+
+		removed, err := ts.channels.Disconnect(ctx, owner, chanID, conn.ThingID)
+		if err != nil && err != ErrNotFound {
+			return err
+		}
+
+		if removed {
+			recorder(ctx).disconnect(chanID, conn.ThingID)
+		}
+	}
+
+	return nil
+}
+
+func (ts *thingsService) Disconnect(ctx context.Context, key, chanID, thingID string) (bool, error) {
+	owner, err := ts.identify(ctx, key)
+	if err != nil {
+		return false, err
+	}
+
+	if err := ts.checkConnectable(ctx, owner, chanID, thingID); err != nil {
+		return false, err
+	}
+
+	removed, err := ts.channels.Disconnect(ctx, owner, chanID, thingID)
+	if err != nil {
+		return false, err
+	}
+
+	if removed {
+		recorder(ctx).disconnect(chanID, thingID)
+	}
+
+	return removed, nil
+}
+
+// checkConnectable verifies that both the channel and the thing belong to
+// the specified user. ErrUnauthorizedAccess is returned if either of them is
+// owned by another user, and ErrNotFound if it doesn't exist at all.
+func (ts *thingsService) checkConnectable(ctx context.Context, owner, chanID, thingID string) error {
+	_, err := ts.channels.One(ctx, owner, chanID)
+	if err == ErrNotFound {
+		err = foreign(ts.channels.Owner(ctx, chanID))
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = ts.things.One(ctx, owner, thingID)
+	if err == ErrNotFound {
+		err = foreign(ts.things.Owner(ctx, thingID))
+	}
+
+	return err
+}
+
+// foreign maps the result of looking up the owner of the entity the user
+// doesn't own to the error reported to the user.
+func foreign(_ string, err error) error {
+	if err == nil {
+		return ErrUnauthorizedAccess
+	}
+
+	return err
+}
+
+func (ts *thingsService) DisconnectMany(ctx context.Context, key, thingID string, chanIDs []string) error {
+	owner, err := ts.identify(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if _, err := ts.things.One(ctx, owner, thingID); err != nil {
+		return err
+	}
+
+	var notConnected []string
+	for _, chanID := range chanIDs {
+		removed, err := ts.channels.Disconnect(ctx, owner, chanID, thingID)
+		if err == ErrNotFound || err == nil && !removed {
+			notConnected = append(notConnected, chanID)
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		recorder(ctx).disconnect(chanID, thingID)
+	}
+
+	if len(notConnected) > 0 {
+		return NotConnectedError{ChanIDs: notConnected}
+	}
+
+	return nil
+}
+
+func (ts *thingsService) DisconnectAll(ctx context.Context, key, thingID string) error {
+	owner, err := ts.identify(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if _, err := ts.things.One(ctx, owner, thingID); err != nil {
+		return err
+	}
+
+	return ts.disconnectAll(ctx, owner, thingID)
+}
+
+func (ts *thingsService) RebindConnections(ctx context.Context, key, fromID, toID string) error {
+	owner, err := ts.identify(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if fromID == toID {
+		return ErrMalformedEntity
+	}
+
+	for _, id := range []string{fromID, toID} {
+		if _, err := ts.things.One(ctx, owner, id); err != nil {
+			return err
+		}
+	}
+
+	var from, to []string
+	if rec := recorder(ctx); rec != nil {
+		if from, err = ts.channels.Connections(ctx, owner, fromID); err != nil {
+			return err
+		}
+		if to, err = ts.channels.Connections(ctx, owner, toID); err != nil {
+			return err
+		}
+	}
+
+	if err := ts.channels.Rebind(ctx, owner, fromID, toID); err != nil {
+		return err
+	}
+
+	bound := make(map[string]bool, len(to))
+	for _, id := range to {
+		bound[id] = true
+	}
+	for _, id := range from {
+		if !bound[id] {
+			recorder(ctx).connect(Connection{ChanID: id, ThingID: toID})
+		}
+		recorder(ctx).disconnect(id, fromID)
+	}
+
+	return nil
+}
+
+func (ts *thingsService) ThingChannelIDs(ctx context.Context, key, thingID string) ([]string, error) {
+	owner, err := ts.identify(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := ts.things.One(ctx, owner, thingID); err != nil {
+		return nil, err
+	}
+
+	return ts.channels.Connections(ctx, owner, thingID)
+}
+
+func (ts *thingsService) IsConnected(ctx context.Context, key, chanID, thingID string) (bool, error) {
+	owner, err := ts.identify(ctx, key)
+	if err != nil {
+		return false, err
+	}
+
+	return ts.channels.HasConnection(ctx, owner, chanID, thingID), nil
+}
+
+func (ts *thingsService) CanAccessBatch(ctx context.Context, reqs []AccessRequest) ([]AccessResult, error) {
+	return canAccessBatch(ctx, ts.CanAccess, reqs)
+}
+
+// canAccessBatch checks each of the provided access requests using the
+// provided access check.
+func canAccessBatch(ctx context.Context, canAccess func(context.Context, string, string, AccessMode) (string, error), reqs []AccessRequest) ([]AccessResult, error) {
+	if len(reqs) > MaxAccessBatchSize {
+		return nil, ErrMalformedEntity
+	}
+
+	results := make([]AccessResult, len(reqs))
+	for i, req := range reqs {
+		id, err := canAccess(ctx, req.Key, req.ChanID, req.Mode)
+		results[i] = AccessResult{ThingID: id, Err: err}
+	}
+
+	return results, nil
+}
+
+func (ts *thingsService) CanAccess(ctx context.Context, key, channel string, mode AccessMode) (string, error) {
+	if err := mode.Validate(); err != nil {
+		return "", err
+	}
+
+	thingID, err := ts.channels.HasThing(ctx, channel, key, mode)
+	if err != nil {
+		return "", ErrUnauthorizedAccess
+	}
+
+	return thingID, nil
+}
+
+func (ts *thingsService) ChannelOwner(ctx context.Context, key, chanID string) (string, error) {
+	if !ts.isService(key) {
+		return "", ErrUnauthorizedAccess
+	}
+
+	return ts.channels.Owner(ctx, chanID)
+}
+
+func (ts *thingsService) PurgeDeleted(ctx context.Context, key string, olderThan time.Time) (int, error) {
+	if !ts.isService(key) {
+		return 0, ErrUnauthorizedAccess
+	}
+
+	// channels are purged first, so that their connections to the purged
+	// things are removed along with them
+	channels, err := ts.channels.Purge(ctx, olderThan)
+	if err != nil {
+		return 0, err
+	}
+
+	ths, err := ts.things.Purge(ctx, olderThan)
+	if err != nil {
+		return channels, err
+	}
+
+	return channels + ths, nil
+}
+
+// isService determines whether the provided key is the configured service
+// key. No key is accepted if the service key isn't configured.
+func (ts *thingsService) isService(key string) bool {
+	return ts.serviceKey != "" && subtle.ConstantTimeCompare([]byte(key), []byte(ts.serviceKey)) == 1
+}
+
+// identify returns the identifier of the user identified by the provided key.
+// The users service is given at most the identification timeout to respond,
+// including the retries of the calls that failed because it was temporarily
+// unavailable, while the provided context still applies to the rest of the
+// request.
+func (ts *thingsService) identify(ctx context.Context, key string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, ts.timeout)
+	defer cancel()
+
+	backoff := identifyBackoff
+	for attempt := 1; ; attempt++ {
+		res, err := ts.users.Identify(ctx, &mainflux.Token{Value: key})
+		if err == nil {
+			recorder(ctx).setOwner(res.GetValue())
+			return res.GetValue(), nil
+		}
+
+		if !transient(err) {
+			return "", ErrUnauthorizedAccess
+		}
+
+		// the credentials can't be checked, so they aren't rejected
+		if attempt >= ts.attempts {
+			return "", ErrUnavailable
+		}
+
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-ctx.Done():
+			return "", ErrUnavailable
+		}
+	}
+}
+
+// resolveOwner retrieves the identifier of the registered user having the
+// provided email, so that nothing is transferred to the unknown user.
+func (ts *thingsService) resolveOwner(ctx context.Context, email string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, ts.timeout)
+	defer cancel()
+
+	res, err := ts.users.Resolve(ctx, &mainflux.Identity{Value: email})
+	switch status.Code(err) {
+	case codes.OK:
+		return res.GetValue(), nil
+	case codes.NotFound:
+		return "", ErrMalformedEntity
+	default:
+		return "", ErrUnavailable
+	}
+}
+
+// disconnectAll removes all connections of the thing, recording the ones
+// that existed when the call is recorded.
+func (ts *thingsService) disconnectAll(ctx context.Context, owner, thingID string) error {
+	var chanIDs []string
+	if rec := recorder(ctx); rec != nil {
+		ids, err := ts.channels.Connections(ctx, owner, thingID)
+		if err != nil {
+			return err
+		}
+		chanIDs = ids
+	}
+
+	if err := ts.channels.DisconnectAll(ctx, owner, thingID); err != nil {
+		return err
+	}
+
+	for _, id := range chanIDs {
+		recorder(ctx).disconnect(id, thingID)
+	}
+
+	return nil
+}
+
+// liveConnections returns the connections of the channel as long as it isn't
+// removed. The connections are only looked up when the call is recorded.
+func (ts *thingsService) liveConnections(ctx context.Context, owner, chanID string) ([]Connection, error) {
+	if recorder(ctx) == nil {
+		return nil, nil
+	}
+
+	if _, err := ts.channels.One(ctx, owner, chanID); err != nil {
+		if err == ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return ts.channels.ChannelConnections(ctx, owner, chanID)
+}
+
+// ownedThings returns the identifiers and the connections of all things
+// owned by the user.
+func (ts *thingsService) ownedThings(ctx context.Context, owner string) ([]string, []Connection, error) {
+	var ids []string
+	var conns []Connection
+	for offset := 0; ; offset += connectionsPageSize {
+		page := ts.things.All(ctx, owner, offset, connectionsPageSize, Sorting{}, ThingFilter{})
+
+		for _, th := range page.Things {
+			ids = append(ids, th.ID)
+
+			chanIDs, err := ts.channels.Connections(ctx, owner, th.ID)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			for _, id := range chanIDs {
+				conns = append(conns, Connection{ChanID: id, ThingID: th.ID})
+			}
+		}
+
+		if offset+connectionsPageSize >= page.Total {
+			return ids, conns, nil
+		}
+	}
+}
+
+// ownedChannels returns the identifiers and the connections of all channels
+// owned by the user.
+func (ts *thingsService) ownedChannels(ctx context.Context, owner string) ([]string, []Connection, error) {
+	var ids []string
+	var conns []Connection
+	for offset := 0; ; offset += connectionsPageSize {
+		page := ts.channels.All(ctx, owner, offset, connectionsPageSize, Sorting{}, MetadataFilter{})
+
+		for _, ch := range page.Channels {
+			ids = append(ids, ch.ID)
+
+			chConns, err := ts.channels.ChannelConnections(ctx, owner, ch.ID)
+			if err != nil {
+				return nil, nil, err
+			}
+			conns = append(conns, chConns...)
+		}
+
+		if offset+connectionsPageSize >= page.Total {
+			return ids, conns, nil
+		}
+	}
+}
+
+// transient determines whether the call to the users service failed due to
+// the temporary condition, so that it can be retried.
+func transient(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted, codes.Aborted:
+		return true
+	default:
+		return false
+	}
+}
+
+func (ts *thingsService) Health(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, ts.timeout)
+	defer cancel()
+
+	// Any response, including the rejection of an empty token, proves that
+	// the users service is reachable.
+	_, err := ts.users.Identify(ctx, &mainflux.Token{})
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return ErrUnavailable
+	}
+
+	if err := ts.things.Ping(ctx); err != nil {
+		return ErrUnavailable
+	}
+
+	if err := ts.channels.Ping(ctx); err != nil {
+		return ErrUnavailable
+	}
+
+	return nil
 }
@@ -1,11 +1,16 @@
 package things_test
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/mainflux/mainflux"
 	"github.com/mainflux/mainflux/things"
 	"github.com/mainflux/mainflux/things/mocks"
+	"github.com/mainflux/mainflux/things/ulid"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -29,6 +34,59 @@ func newService(tokens map[string]string) things.Service {
 	return things.New(users, thingsRepo, channelsRepo, idp)
 }
 
+func TestIdentifyTimeout(t *testing.T) {
+	delay := 1100 * time.Millisecond
+
+	cases := map[string]struct {
+		opts []things.Option
+		err  error
+	}{
+		"identify with default timeout": {nil, things.ErrUnauthorizedAccess},
+		"identify with longer timeout":  {[]things.Option{things.WithIdentifyTimeout(2 * time.Second)}, nil},
+	}
+
+	for desc, tc := range cases {
+		users := mocks.NewSlowUsersService(map[string]string{token: email}, delay)
+		thingsRepo := mocks.NewThingRepository()
+		channelsRepo := mocks.NewChannelRepository(thingsRepo)
+		svc := things.New(users, thingsRepo, channelsRepo, mocks.NewIdentityProvider(), tc.opts...)
+
+		_, err := svc.ListThings(context.Background(), token, 0, 10, things.Sorting{}, things.ThingFilter{})
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+}
+
+func TestIdentifyRetry(t *testing.T) {
+	cases := map[string]struct {
+		failures int
+		opts     []things.Option
+		key      string
+		err      error
+	}{
+		"identify after transient failure":                 {1, nil, token, nil},
+		"identify after too many transient failures":       {things.DefaultIdentifyAttempts, nil, token, things.ErrUnavailable},
+		"identify after transient failure with retries":    {3, []things.Option{things.WithIdentifyAttempts(4)}, token, nil},
+		"identify after transient failure without retries": {1, []things.Option{things.WithIdentifyAttempts(1)}, token, things.ErrUnavailable},
+		"identify with wrong credentials":                  {0, nil, wrong, things.ErrUnauthorizedAccess},
+		"identify after transient failure with short timeout": {
+			3,
+			[]things.Option{things.WithIdentifyAttempts(4), things.WithIdentifyTimeout(100 * time.Millisecond)},
+			token,
+			things.ErrUnavailable,
+		},
+	}
+
+	for desc, tc := range cases {
+		users := mocks.NewFlakyUsersService(map[string]string{token: email}, tc.failures)
+		thingsRepo := mocks.NewThingRepository()
+		channelsRepo := mocks.NewChannelRepository(thingsRepo)
+		svc := things.New(users, thingsRepo, channelsRepo, mocks.NewIdentityProvider(), tc.opts...)
+
+		_, err := svc.ListThings(context.Background(), tc.key, 0, 10, things.Sorting{}, things.ThingFilter{})
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+}
+
 func TestAddThing(t *testing.T) {
 	svc := newService(map[string]string{token: email})
 
@@ -40,17 +98,154 @@ func TestAddThing(t *testing.T) {
 		"add new app":                      {things.Thing{Type: "app", Name: "a"}, token, nil},
 		"add new device":                   {things.Thing{Type: "device", Name: "b"}, token, nil},
 		"add thing with wrong credentials": {things.Thing{Type: "app", Name: "d"}, wrong, things.ErrUnauthorizedAccess},
+		"add thing with empty name":        {things.Thing{Type: "app"}, token, things.ErrMalformedEntity},
+		"add thing with too long name":     {things.Thing{Type: "app", Name: strings.Repeat("a", things.MaxNameLength+1)}, token, things.ErrMalformedEntity},
+	}
+
+	for desc, tc := range cases {
+		_, err := svc.AddThing(context.Background(), tc.key, tc.thing)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+}
+
+func TestAddThingWithExternalID(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+
+	th := thing
+	th.ExternalID = "sensor-1"
+	saved, _ := svc.AddThing(context.Background(), token, th)
+
+	cases := map[string]struct {
+		extID    string
+		existing bool
+	}{
+		"add thing with existing external ID": {saved.ExternalID, true},
+		"add thing with new external ID":      {"sensor-2", false},
+	}
+
+	for desc, tc := range cases {
+		th := thing
+		th.ExternalID = tc.extID
+		added, err := svc.AddThing(context.Background(), token, th)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s\n", desc, err))
+		assert.Equal(t, tc.existing, added.Existing, fmt.Sprintf("%s: expected existing %t got %t\n", desc, tc.existing, added.Existing))
+		assert.Equal(t, tc.existing, added.ID == saved.ID, fmt.Sprintf("%s: expected same thing %t got %t\n", desc, tc.existing, added.ID == saved.ID))
+	}
+
+	page, _ := svc.ListThings(context.Background(), token, 0, 10, things.Sorting{}, things.ThingFilter{})
+	assert.Equal(t, 2, page.Total, fmt.Sprintf("list things: expected total %d got %d\n", 2, page.Total))
+}
+
+func TestAddThingWithID(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	id := "123e4567-e89b-12d3-a456-426614174000"
+
+	cases := []struct {
+		desc string
+		id   string
+		err  error
+	}{
+		{"add thing with supplied ID", id, nil},
+		{"add thing with existing ID", id, things.ErrConflict},
+		{"add thing without ID", "", nil},
+	}
+
+	for _, tc := range cases {
+		th := thing
+		th.ID = tc.id
+		saved, err := svc.AddThing(context.Background(), token, th)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		if err != nil {
+			continue
+		}
+
+		if tc.id != "" {
+			assert.Equal(t, tc.id, saved.ID, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.id, saved.ID))
+		} else {
+			assert.NotEmpty(t, saved.ID, fmt.Sprintf("%s: expected generated ID\n", tc.desc))
+		}
+	}
+}
+
+func TestCreateThings(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+
+	cases := map[string]struct {
+		things []things.Thing
+		key    string
+		size   int
+		err    error
+	}{
+		"create new things": {
+			things: []things.Thing{{Type: "app", Name: "a"}, {Type: "device", Name: "b"}},
+			key:    token,
+			size:   2,
+			err:    nil,
+		},
+		"create things with wrong credentials": {
+			things: []things.Thing{{Type: "app", Name: "c"}},
+			key:    wrong,
+			size:   0,
+			err:    things.ErrUnauthorizedAccess,
+		},
+		"create things with invalid thing": {
+			things: []things.Thing{{Type: "app", Name: "d"}, {Type: wrong, Name: "e"}},
+			key:    token,
+			size:   0,
+			err:    things.BulkError{Index: 1, Err: things.ErrMalformedEntity},
+		},
 	}
 
 	for desc, tc := range cases {
-		_, err := svc.AddThing(tc.key, tc.thing)
+		saved, err := svc.CreateThings(context.Background(), tc.key, tc.things, false)
+		size := len(saved)
+		assert.Equal(t, tc.size, size, fmt.Sprintf("%s: expected %d got %d\n", desc, tc.size, size))
 		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+		for _, th := range saved {
+			assert.NotEmpty(t, th.ID, fmt.Sprintf("%s: expected non-empty ID\n", desc))
+			assert.NotEmpty(t, th.Key, fmt.Sprintf("%s: expected non-empty key\n", desc))
+		}
+	}
+
+	page, _ := svc.ListThings(context.Background(), token, 0, 10, things.Sorting{}, things.ThingFilter{})
+	assert.Equal(t, 2, page.Total, fmt.Sprintf("expected %d saved things got %d\n", 2, page.Total))
+}
+
+func TestCreateThingsDryRun(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+
+	ths := []things.Thing{{Type: "app", Name: "a"}, {Type: "device", Name: "b"}}
+
+	saved, err := svc.CreateThings(context.Background(), token, ths, true)
+	assert.Nil(t, err, fmt.Sprintf("dry run valid things: unexpected error %s\n", err))
+	assert.Equal(t, len(ths), len(saved), fmt.Sprintf("dry run valid things: expected %d got %d\n", len(ths), len(saved)))
+	for _, th := range saved {
+		assert.NotEmpty(t, th.ID, fmt.Sprintf("dry run valid things: expected non-empty ID\n"))
 	}
+
+	_, err = svc.CreateThings(context.Background(), token, []things.Thing{{Type: "app", Name: "c"}, {Type: wrong, Name: "d"}}, true)
+	expected := things.BulkError{Index: 1, Err: things.ErrMalformedEntity}
+	assert.Equal(t, expected, err, fmt.Sprintf("dry run invalid things: expected %s got %s\n", expected, err))
+
+	_, err = svc.CreateThings(context.Background(), token, []things.Thing{{Type: "app", Name: "e", ExternalID: "ext"}, {Type: "app", Name: "f", ExternalID: "ext"}}, true)
+	expected = things.BulkError{Index: 1, Err: things.ErrConflict}
+	assert.Equal(t, expected, err, fmt.Sprintf("dry run repeated external ID: expected %s got %s\n", expected, err))
+
+	_, err = svc.CreateThings(context.Background(), token, []things.Thing{{Type: "app", Name: "e", ExternalID: "ext"}, {Type: "app", Name: "f", ExternalID: "ext"}}, false)
+	assert.Equal(t, things.ErrConflict, err, fmt.Sprintf("create repeated external ID: expected %s got %s\n", things.ErrConflict, err))
+
+	svc.AddThing(context.Background(), token, things.Thing{Type: "app", Name: "g", ExternalID: "taken"})
+	_, err = svc.CreateThings(context.Background(), token, []things.Thing{{Type: "app", Name: "h", ExternalID: "taken"}}, true)
+	expected = things.BulkError{Index: 0, Err: things.ErrConflict}
+	assert.Equal(t, expected, err, fmt.Sprintf("dry run taken external ID: expected %s got %s\n", expected, err))
+
+	page, _ := svc.ListThings(context.Background(), token, 0, 10, things.Sorting{}, things.ThingFilter{})
+	assert.Equal(t, 1, page.Total, fmt.Sprintf("expected %d saved things got %d\n", 1, page.Total))
 }
 
 func TestUpdateThing(t *testing.T) {
 	svc := newService(map[string]string{token: email})
-	saved, _ := svc.AddThing(token, thing)
+	saved, _ := svc.AddThing(context.Background(), token, thing)
 
 	cases := map[string]struct {
 		thing things.Thing
@@ -59,270 +254,2197 @@ func TestUpdateThing(t *testing.T) {
 	}{
 		"update existing thing":               {saved, token, nil},
 		"update thing with wrong credentials": {saved, wrong, things.ErrUnauthorizedAccess},
-		"update non-existing thing":           {things.Thing{ID: "2", Type: "app", Key: "x"}, token, things.ErrNotFound},
+		"update non-existing thing":           {things.Thing{ID: "2", Type: "app", Name: "test", Key: "x"}, token, things.ErrNotFound},
+		"update thing with empty name":        {things.Thing{ID: saved.ID, Type: "app"}, token, things.ErrMalformedEntity},
 	}
 
 	for desc, tc := range cases {
-		err := svc.UpdateThing(tc.key, tc.thing)
+		err := svc.UpdateThing(context.Background(), tc.key, tc.thing)
 		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
 	}
 }
 
-func TestViewThing(t *testing.T) {
+func TestUpdateThingTimestamps(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	saved, _ := svc.AddThing(context.Background(), token, thing)
+
+	svc.UpdateThing(context.Background(), token, saved)
+	updated, _ := svc.ViewThing(context.Background(), token, saved.ID)
+
+	assert.Equal(t, saved.CreatedAt, updated.CreatedAt, fmt.Sprintf("update thing: expected created at %s got %s\n", saved.CreatedAt, updated.CreatedAt))
+	assert.True(t, updated.UpdatedAt.After(saved.UpdatedAt), fmt.Sprintf("update thing: expected updated at after %s got %s\n", saved.UpdatedAt, updated.UpdatedAt))
+}
+
+func TestUpdateThingVersion(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	saved, _ := svc.AddThing(context.Background(), token, thing)
+	current, _ := svc.ViewThing(context.Background(), token, saved.ID)
+
+	cases := []struct {
+		desc    string
+		version uint64
+		err     error
+	}{
+		{"update thing with current version", current.Version, nil},
+		{"update thing with stale version", current.Version, things.ErrVersionMismatch},
+		{"update thing without version", 0, nil},
+	}
+
+	for _, tc := range cases {
+		th := current
+		th.Version = tc.version
+		err := svc.UpdateThing(context.Background(), token, th)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+
+	updated, _ := svc.ViewThing(context.Background(), token, saved.ID)
+	assert.Equal(t, current.Version+2, updated.Version, fmt.Sprintf("update thing: expected version %d got %d\n", current.Version+2, updated.Version))
+}
+
+func TestUpdateKey(t *testing.T) {
 	svc := newService(map[string]string{token: email})
-	saved, _ := svc.AddThing(token, thing)
+	saved, _ := svc.AddThing(context.Background(), token, thing)
+	other, _ := svc.AddThing(context.Background(), token, thing)
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	svc.Connect(context.Background(), token, sch.ID, saved.ID, things.AccessPubSub)
+
+	newKey := "9c3f1b2e-7d4a-4c1e-8f6b-0a5d3e2c1b7f"
 
 	cases := map[string]struct {
-		id  string
-		key string
-		err error
+		key    string
+		id     string
+		newKey string
+		err    error
 	}{
-		"view existing thing":               {saved.ID, token, nil},
-		"view thing with wrong credentials": {saved.ID, wrong, things.ErrUnauthorizedAccess},
-		"view non-existing thing":           {wrong, token, things.ErrNotFound},
+		"update key to too short key":          {token, saved.ID, "new-key", things.ErrMalformedEntity},
+		"update key to too long key":           {token, saved.ID, newKey + "0", things.ErrMalformedEntity},
+		"update key of non-existing thing":     {token, wrong, newKey, things.ErrNotFound},
+		"update key with wrong credentials":    {wrong, saved.ID, newKey, things.ErrUnauthorizedAccess},
+		"update key to the one already in use": {token, saved.ID, other.Key, things.ErrConflict},
+		"update key of existing thing":         {token, saved.ID, newKey, nil},
 	}
 
 	for desc, tc := range cases {
-		_, err := svc.ViewThing(tc.key, tc.id)
+		err := svc.UpdateKey(context.Background(), tc.key, tc.id, tc.newKey)
 		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
 	}
+
+	_, err := svc.CanAccess(context.Background(), saved.Key, sch.ID, things.AccessPubSub)
+	assert.Equal(t, things.ErrUnauthorizedAccess, err, fmt.Sprintf("access with old key: expected %s got %s\n", things.ErrUnauthorizedAccess, err))
+
+	_, err = svc.CanAccess(context.Background(), newKey, sch.ID, things.AccessPubSub)
+	assert.Nil(t, err, fmt.Sprintf("access with new key: unexpected error %s\n", err))
 }
 
-func TestListThings(t *testing.T) {
+func TestRotateKey(t *testing.T) {
 	svc := newService(map[string]string{token: email})
+	saved, _ := svc.AddThing(context.Background(), token, thing)
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	svc.Connect(context.Background(), token, sch.ID, saved.ID, things.AccessPubSub)
 
-	n := 10
-	for i := 0; i < n; i++ {
-		svc.AddThing(token, thing)
+	cases := map[string]struct {
+		key string
+		id  string
+		err error
+	}{
+		"rotate key of non-existing thing":  {token, wrong, things.ErrNotFound},
+		"rotate key with wrong credentials": {wrong, saved.ID, things.ErrUnauthorizedAccess},
+	}
+
+	for desc, tc := range cases {
+		_, err := svc.RotateKey(context.Background(), tc.key, tc.id)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
 	}
 
+	newKey, err := svc.RotateKey(context.Background(), token, saved.ID)
+	assert.Nil(t, err, fmt.Sprintf("rotate key of existing thing: unexpected error %s\n", err))
+	assert.NotEqual(t, saved.Key, newKey, fmt.Sprintf("rotate key of existing thing: expected key other than %s\n", saved.Key))
+
+	_, err = svc.CanAccess(context.Background(), saved.Key, sch.ID, things.AccessPubSub)
+	assert.Equal(t, things.ErrUnauthorizedAccess, err, fmt.Sprintf("access with old key: expected %s got %s\n", things.ErrUnauthorizedAccess, err))
+
+	_, err = svc.CanAccess(context.Background(), newKey, sch.ID, things.AccessPubSub)
+	assert.Nil(t, err, fmt.Sprintf("access with new key: unexpected error %s\n", err))
+}
+
+func TestDisableThing(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	saved, _ := svc.AddThing(context.Background(), token, thing)
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	svc.Connect(context.Background(), token, sch.ID, saved.ID, things.AccessPubSub)
+
 	cases := map[string]struct {
-		key    string
-		offset int
-		limit  int
-		size   int
-		err    error
+		id  string
+		key string
+		err error
 	}{
-		"list all things":             {token, 0, n, n, nil},
-		"list subset":                 {token, 1, 3, 3, nil},
-		"list half":                   {token, n / 2, n, n / 2, nil},
-		"list last thing":             {token, n - 1, n, 1, nil},
-		"list empty set":              {token, n + 1, n, 0, nil},
-		"list with negative offset":   {token, -1, n, 0, nil},
-		"list with negative limit":    {token, 1, -n, 0, nil},
-		"list with zero limit":        {token, 1, 0, 0, nil},
-		"list with wrong credentials": {wrong, 0, 0, 0, things.ErrUnauthorizedAccess},
+		"disable existing thing":               {saved.ID, token, nil},
+		"disable thing with wrong credentials": {saved.ID, wrong, things.ErrUnauthorizedAccess},
+		"disable non-existing thing":           {wrong, token, things.ErrNotFound},
 	}
 
 	for desc, tc := range cases {
-		ts, err := svc.ListThings(tc.key, tc.offset, tc.limit)
-		size := len(ts)
-		assert.Equal(t, tc.size, size, fmt.Sprintf("%s: expected %d got %d\n", desc, tc.size, size))
+		err := svc.DisableThing(context.Background(), tc.key, tc.id)
 		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
 	}
+
+	_, err := svc.CanAccess(context.Background(), saved.Key, sch.ID, things.AccessPubSub)
+	assert.Equal(t, things.ErrUnauthorizedAccess, err, fmt.Sprintf("access with disabled thing: expected %s got %s\n", things.ErrUnauthorizedAccess, err))
 }
 
-func TestRemoveThing(t *testing.T) {
+func TestEnableThing(t *testing.T) {
 	svc := newService(map[string]string{token: email})
-	saved, _ := svc.AddThing(token, thing)
+	saved, _ := svc.AddThing(context.Background(), token, thing)
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	svc.Connect(context.Background(), token, sch.ID, saved.ID, things.AccessPubSub)
+	svc.DisableThing(context.Background(), token, saved.ID)
 
 	cases := map[string]struct {
 		id  string
 		key string
 		err error
 	}{
-		"remove thing with wrong credentials": {saved.ID, "?", things.ErrUnauthorizedAccess},
-		"remove existing thing":               {saved.ID, token, nil},
-		"remove removed thing":                {saved.ID, token, nil},
-		"remove non-existing thing":           {"?", token, nil},
+		"enable existing thing":               {saved.ID, token, nil},
+		"enable thing with wrong credentials": {saved.ID, wrong, things.ErrUnauthorizedAccess},
+		"enable non-existing thing":           {wrong, token, things.ErrNotFound},
 	}
 
 	for desc, tc := range cases {
-		err := svc.RemoveThing(tc.key, tc.id)
+		err := svc.EnableThing(context.Background(), tc.key, tc.id)
 		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
 	}
+
+	_, err := svc.CanAccess(context.Background(), saved.Key, sch.ID, things.AccessPubSub)
+	assert.Nil(t, err, fmt.Sprintf("access with enabled thing: unexpected error %s\n", err))
 }
 
-func TestCreateChannel(t *testing.T) {
-	svc := newService(map[string]string{token: email})
+func TestViewThing(t *testing.T) {
+	otherToken := "other-token"
+	svc := newService(map[string]string{token: email, otherToken: "other@example.com"})
+	saved, _ := svc.AddThing(context.Background(), token, thing)
+	other, _ := svc.AddThing(context.Background(), otherToken, thing)
 
 	cases := map[string]struct {
-		channel things.Channel
-		key     string
-		err     error
+		id  string
+		key string
+		err error
 	}{
-		"create channel":                        {things.Channel{}, token, nil},
-		"create channel with wrong credentials": {things.Channel{}, wrong, things.ErrUnauthorizedAccess},
+		"view existing thing":               {saved.ID, token, nil},
+		"view thing with wrong credentials": {saved.ID, wrong, things.ErrUnauthorizedAccess},
+		"view non-existing thing":           {wrong, token, things.ErrNotFound},
+		"view thing owned by other user":    {other.ID, token, things.ErrNotFound},
 	}
 
 	for desc, tc := range cases {
-		_, err := svc.CreateChannel(tc.key, tc.channel)
+		_, err := svc.ViewThing(context.Background(), tc.key, tc.id)
 		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
 	}
 }
 
-func TestUpdateChannel(t *testing.T) {
-	svc := newService(map[string]string{token: email})
-	saved, _ := svc.CreateChannel(token, channel)
+func TestViewThings(t *testing.T) {
+	otherToken := "other-token"
+	users := mocks.NewUsersService(map[string]string{token: email, otherToken: "other@example.com"})
+	thingsRepo := mocks.NewThingRepository()
+	channelsRepo := mocks.NewChannelRepository(thingsRepo)
+	idp := mocks.NewIdentityProvider()
+	svc := things.New(users, thingsRepo, channelsRepo, idp)
+	strict := things.New(users, thingsRepo, channelsRepo, idp, things.WithStrictView())
+
+	th1, _ := svc.AddThing(context.Background(), token, thing)
+	th2, _ := svc.AddThing(context.Background(), token, thing)
+	other, _ := svc.AddThing(context.Background(), otherToken, thing)
 
 	cases := map[string]struct {
-		channel things.Channel
-		key     string
-		err     error
+		svc things.Service
+		key string
+		ids []string
+		res []string
+		err error
 	}{
-		"update existing channel":               {saved, token, nil},
-		"update channel with wrong credentials": {saved, wrong, things.ErrUnauthorizedAccess},
-		"update non-existing channel":           {things.Channel{ID: "2", Name: "test"}, token, things.ErrNotFound},
+		"view things": {svc, token, []string{th2.ID, th1.ID}, []string{th2.ID, th1.ID}, nil},
+		"view things including non-existing thing":          {svc, token, []string{th1.ID, wrong, th2.ID}, []string{th1.ID, th2.ID}, nil},
+		"view things including thing owned by other user":   {svc, token, []string{other.ID, th1.ID}, []string{th1.ID}, nil},
+		"view things with wrong credentials":                {svc, wrong, []string{th1.ID}, nil, things.ErrUnauthorizedAccess},
+		"strictly view things":                              {strict, token, []string{th1.ID, th2.ID}, []string{th1.ID, th2.ID}, nil},
+		"strictly view things including non-existing thing": {strict, token, []string{th1.ID, wrong, th2.ID}, nil, things.ErrNotFound},
 	}
 
 	for desc, tc := range cases {
-		err := svc.UpdateChannel(tc.key, tc.channel)
+		ths, err := tc.svc.ViewThings(context.Background(), tc.key, tc.ids)
 		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+
+		var res []string
+		for _, th := range ths {
+			res = append(res, th.ID)
+		}
+		assert.Equal(t, tc.res, res, fmt.Sprintf("%s: expected %v got %v\n", desc, tc.res, res))
 	}
 }
 
-func TestViewChannel(t *testing.T) {
+func TestViewThingByKey(t *testing.T) {
 	svc := newService(map[string]string{token: email})
-	saved, _ := svc.CreateChannel(token, channel)
+	saved, _ := svc.AddThing(context.Background(), token, thing)
 
 	cases := map[string]struct {
-		id  string
 		key string
 		err error
 	}{
-		"view existing channel":               {saved.ID, token, nil},
-		"view channel with wrong credentials": {saved.ID, wrong, things.ErrUnauthorizedAccess},
-		"view non-existing channel":           {wrong, token, things.ErrNotFound},
+		"view thing with existing key": {saved.Key, nil},
+		"view thing with unknown key":  {wrong, things.ErrNotFound},
 	}
 
 	for desc, tc := range cases {
-		_, err := svc.ViewChannel(tc.key, tc.id)
+		th, err := svc.ViewThingByKey(context.Background(), tc.key)
 		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+		if err == nil {
+			assert.Equal(t, saved.ID, th.ID, fmt.Sprintf("%s: expected %s got %s\n", desc, saved.ID, th.ID))
+		}
 	}
 }
 
-func TestListChannels(t *testing.T) {
+func TestListThings(t *testing.T) {
 	svc := newService(map[string]string{token: email})
 
 	n := 10
 	for i := 0; i < n; i++ {
-		svc.CreateChannel(token, channel)
+		svc.AddThing(context.Background(), token, thing)
 	}
+
 	cases := map[string]struct {
 		key    string
 		offset int
 		limit  int
 		size   int
+		total  int
 		err    error
 	}{
-		"list first 5 channels":                {token, 0, 5, 5, nil},
-		"list channels 5-10 channels":          {token, 5, 10, 5, nil},
-		"list last channel":                    {token, 6, 10, 4, nil},
-		"list offset < 0":                      {token, -1, 10, 0, nil},
-		"list limit < 0":                       {token, 1, -10, 0, nil},
-		"list limit = 0":                       {token, 1, 0, 0, nil},
-		"list channels with wrong credentials": {wrong, 0, 0, 0, things.ErrUnauthorizedAccess},
+		"list all things":             {token, 0, n, n, n, nil},
+		"list subset":                 {token, 1, 3, 3, n, nil},
+		"list half":                   {token, n / 2, n, n / 2, n, nil},
+		"list last thing":             {token, n - 1, n, 1, n, nil},
+		"list empty set":              {token, n + 1, n, 0, n, nil},
+		"list with negative offset":   {token, -1, n, 0, n, nil},
+		"list with negative limit":    {token, 1, -n, 0, n, nil},
+		"list with zero limit":        {token, 1, 0, 0, n, nil},
+		"list with wrong credentials": {wrong, 0, 0, 0, 0, things.ErrUnauthorizedAccess},
 	}
 
 	for desc, tc := range cases {
-		ch, err := svc.ListChannels(tc.key, tc.offset, tc.limit)
-		size := len(ch)
+		page, err := svc.ListThings(context.Background(), tc.key, tc.offset, tc.limit, things.Sorting{}, things.ThingFilter{})
+		size := len(page.Things)
 		assert.Equal(t, tc.size, size, fmt.Sprintf("%s: expected %d got %d\n", desc, tc.size, size))
+		assert.Equal(t, tc.total, page.Total, fmt.Sprintf("%s: expected total %d got %d\n", desc, tc.total, page.Total))
 		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
 	}
 }
 
-func TestRemoveChannel(t *testing.T) {
+func TestListThingsSorted(t *testing.T) {
 	svc := newService(map[string]string{token: email})
-	saved, _ := svc.CreateChannel(token, channel)
+
+	n := 5
+	for i := 0; i < n; i++ {
+		th := thing
+		th.Name = fmt.Sprintf("thing-%d", i)
+		svc.AddThing(context.Background(), token, th)
+	}
 
 	cases := map[string]struct {
-		id  string
-		key string
-		err error
+		sorting things.Sorting
+		first   string
+		last    string
 	}{
-		"remove channel with wrong credentials": {saved.ID, wrong, things.ErrUnauthorizedAccess},
-		"remove existing channel":               {saved.ID, token, nil},
-		"remove removed channel":                {saved.ID, token, nil},
-		"remove non-existing channel":           {saved.ID, token, nil},
+		"list sorted by default":         {things.Sorting{}, "thing-0", "thing-4"},
+		"list sorted by name descending": {things.Sorting{Order: things.OrderName, Dir: things.DirDesc}, "thing-4", "thing-0"},
+		"list sorted by creation time":   {things.Sorting{Order: things.OrderCreated, Dir: things.DirAsc}, "thing-0", "thing-4"},
 	}
 
 	for desc, tc := range cases {
-		err := svc.RemoveChannel(tc.key, tc.id)
-		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+		page, _ := svc.ListThings(context.Background(), token, 0, n, tc.sorting, things.ThingFilter{})
+		first, last := page.Things[0].Name, page.Things[n-1].Name
+		assert.Equal(t, tc.first, first, fmt.Sprintf("%s: expected first %s got %s\n", desc, tc.first, first))
+		assert.Equal(t, tc.last, last, fmt.Sprintf("%s: expected last %s got %s\n", desc, tc.last, last))
 	}
 }
 
-func TestConnect(t *testing.T) {
+func TestListThingsSortedByULID(t *testing.T) {
+	users := mocks.NewUsersService(map[string]string{token: email})
+	thingsRepo := mocks.NewThingRepository()
+	channelsRepo := mocks.NewChannelRepository(thingsRepo)
+	svc := things.New(users, thingsRepo, channelsRepo, ulid.New(), things.WithKeyProvider(mocks.NewIdentityProvider()))
+
+	n := 20
+	created := []string{}
+	for i := 0; i < n; i++ {
+		sth, _ := svc.AddThing(context.Background(), token, thing)
+		created = append(created, sth.ID)
+	}
+
+	page, err := svc.ListThings(context.Background(), token, 0, n, things.Sorting{Order: things.OrderID}, things.ThingFilter{})
+	assert.Nil(t, err, fmt.Sprintf("unexpected error %s\n", err))
+
+	listed := []string{}
+	for _, th := range page.Things {
+		listed = append(listed, th.ID)
+	}
+	assert.Equal(t, created, listed, fmt.Sprintf("expected things in creation order %v got %v\n", created, listed))
+}
+
+func TestListThingsByType(t *testing.T) {
 	svc := newService(map[string]string{token: email})
 
-	sth, _ := svc.AddThing(token, thing)
-	sch, _ := svc.CreateChannel(token, channel)
+	app := thing
+	app.Type = "app"
+	svc.AddThing(context.Background(), token, app)
+
+	device := thing
+	device.Type = "device"
+	sdev, _ := svc.AddThing(context.Background(), token, device)
 
 	cases := map[string]struct {
-		key     string
-		chanID  string
-		thingID string
-		err     error
+		thingType string
+		total     int
+		err       error
 	}{
-		"connect thing":                         {token, sch.ID, sth.ID, nil},
-		"connect thing with wrong credentials":  {wrong, sch.ID, sth.ID, things.ErrUnauthorizedAccess},
-		"connect thing to non-existing channel": {token, wrong, sth.ID, things.ErrNotFound},
+		"list devices":                {"device", 1, nil},
+		"list apps":                   {"app", 1, nil},
+		"list things of any type":     {"", 2, nil},
+		"list things of invalid type": {"gateway", 0, things.ErrMalformedEntity},
 	}
 
 	for desc, tc := range cases {
-		err := svc.Connect(tc.key, tc.chanID, tc.thingID)
+		page, err := svc.ListThings(context.Background(), token, 0, 10, things.Sorting{}, things.ThingFilter{Type: tc.thingType})
 		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+		assert.Equal(t, tc.total, page.Total, fmt.Sprintf("%s: expected total %d got %d\n", desc, tc.total, page.Total))
+	}
+
+	page, _ := svc.ListThings(context.Background(), token, 0, 10, things.Sorting{}, things.ThingFilter{Type: "device"})
+	for _, th := range page.Things {
+		assert.Equal(t, sdev.ID, th.ID, fmt.Sprintf("list devices: expected %s got %s\n", sdev.ID, th.ID))
 	}
 }
 
-func TestDisconnect(t *testing.T) {
+func TestListThingsByTag(t *testing.T) {
 	svc := newService(map[string]string{token: email})
 
-	sth, _ := svc.AddThing(token, thing)
-	sch, _ := svc.CreateChannel(token, channel)
-	svc.Connect(token, sch.ID, sth.ID)
+	tags := [][]string{{"floor-1", "hvac"}, {"floor-1"}, {"hvac", "floor-2"}, nil}
+	for _, tt := range tags {
+		th := thing
+		th.Tags = tt
+		svc.AddThing(context.Background(), token, th)
+	}
 
-	cases := []struct {
-		desc    string
-		key     string
-		chanID  string
-		thingID string
-		err     error
+	cases := map[string]struct {
+		tag   string
+		total int
 	}{
-		{"disconnect connected thing", token, sch.ID, sth.ID, nil},
-		{"disconnect disconnected thing", token, sch.ID, sth.ID, things.ErrNotFound},
-		{"disconnect thing with wrong credentials", wrong, sch.ID, sth.ID, things.ErrUnauthorizedAccess},
-		{"disconnect thing from non-existing channel", token, wrong, sth.ID, things.ErrNotFound},
-		{"disconnect non-existing thing", token, sch.ID, wrong, things.ErrNotFound},
+		"list things by shared tag":    {"hvac", 2},
+		"list things by another tag":   {"floor-1", 2},
+		"list things by unique tag":    {"floor-2", 1},
+		"list things by unknown tag":   {"lighting", 0},
+		"list things of any tag":       {"", len(tags)},
+		"list things by tag of prefix": {"floor", 0},
 	}
 
+	for desc, tc := range cases {
+		page, err := svc.ListThings(context.Background(), token, 0, 10, things.Sorting{}, things.ThingFilter{Tag: tc.tag})
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s\n", desc, err))
+		assert.Equal(t, tc.total, page.Total, fmt.Sprintf("%s: expected total %d got %d\n", desc, tc.total, page.Total))
+		for _, th := range page.Things {
+			if tc.tag != "" {
+				assert.Contains(t, th.Tags, tc.tag, fmt.Sprintf("%s: expected tag %s in %v\n", desc, tc.tag, th.Tags))
+			}
+		}
+	}
+
+	th := thing
+	th.Tags = []string{""}
+	_, err := svc.AddThing(context.Background(), token, th)
+	assert.Equal(t, things.ErrMalformedEntity, err, fmt.Sprintf("add thing with empty tag: expected %s got %s\n", things.ErrMalformedEntity, err))
+}
+
+func TestListThingsByFilter(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+
+	cases := []struct {
+		thingType string
+		tag       string
+		disabled  bool
+	}{
+		{"device", "hvac", true},
+		{"device", "hvac", false},
+		{"app", "hvac", true},
+		{"device", "lighting", true},
+	}
+	ids := []string{}
 	for _, tc := range cases {
-		err := svc.Disconnect(tc.key, tc.chanID, tc.thingID)
-		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		th := thing
+		th.Type = tc.thingType
+		th.Tags = []string{"floor-1", tc.tag}
+		sth, _ := svc.AddThing(context.Background(), token, th)
+		if tc.disabled {
+			svc.DisableThing(context.Background(), token, sth.ID)
+		}
+		ids = append(ids, sth.ID)
+	}
+
+	filter := things.ThingFilter{Type: "device", Status: things.StatusDisabled, Tag: "hvac"}
+	page, err := svc.ListThings(context.Background(), token, 0, 10, things.Sorting{}, filter)
+	assert.Nil(t, err, fmt.Sprintf("list things by filter: unexpected error %s\n", err))
+	assert.Equal(t, 1, page.Total, fmt.Sprintf("list things by filter: expected total 1 got %d\n", page.Total))
+	if assert.Len(t, page.Things, 1) {
+		assert.Equal(t, ids[0], page.Things[0].ID, fmt.Sprintf("list things by filter: expected %s got %s\n", ids[0], page.Things[0].ID))
 	}
 
+	filter.Status = "invalid"
+	_, err = svc.ListThings(context.Background(), token, 0, 10, things.Sorting{}, filter)
+	assert.Equal(t, things.ErrMalformedEntity, err, fmt.Sprintf("list things by invalid status: expected %s got %s\n", things.ErrMalformedEntity, err))
 }
 
-func TestCanAccess(t *testing.T) {
+func TestListThingsAfter(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+
+	n := 25
+	ids := []string{}
+	for i := 0; i < n; i++ {
+		sth, _ := svc.AddThing(context.Background(), token, thing)
+		ids = append(ids, sth.ID)
+	}
+
+	limit := 10
+	walked := []string{}
+	cursor := ""
+	for _, size := range []int{10, 10, 5, 0} {
+		ths, err := svc.ListThingsAfter(context.Background(), token, cursor, limit)
+		assert.Nil(t, err, fmt.Sprintf("list things after %s: unexpected error %s\n", cursor, err))
+		assert.Len(t, ths, size, fmt.Sprintf("list things after %s: expected %d things got %d\n", cursor, size, len(ths)))
+
+		for _, th := range ths {
+			walked = append(walked, th.ID)
+		}
+
+		if len(ths) > 0 {
+			cursor = ths[len(ths)-1].ID
+		}
+
+		// removing already walked thing must not shift the following pages
+		if len(walked) == limit {
+			svc.RemoveThing(context.Background(), token, walked[0])
+		}
+	}
+	assert.Equal(t, ids, walked, fmt.Sprintf("walk things: expected %v got %v\n", ids, walked))
+
+	_, err := svc.ListThingsAfter(context.Background(), wrong, "", limit)
+	assert.Equal(t, things.ErrUnauthorizedAccess, err, fmt.Sprintf("list things with wrong credentials: expected %s got %s\n", things.ErrUnauthorizedAccess, err))
+}
+
+func TestSearchThings(t *testing.T) {
 	svc := newService(map[string]string{token: email})
 
-	sth, _ := svc.AddThing(token, thing)
-	sch, _ := svc.CreateChannel(token, channel)
-	svc.Connect(token, sch.ID, sth.ID)
+	n := 10
+	for i := 0; i < n; i++ {
+		th := thing
+		th.Name = fmt.Sprintf("Sensor-%d", i%2)
+		svc.AddThing(context.Background(), token, th)
+	}
 
 	cases := map[string]struct {
-		key     string
-		channel string
-		err     error
+		key    string
+		name   string
+		offset int
+		limit  int
+		size   int
+		err    error
+	}{
+		"search by exact name":            {token, "Sensor-1", 0, n, n / 2, nil},
+		"search by case insensitive name": {token, "sensor", 0, n, n, nil},
+		"search subset":                   {token, "sensor-0", 1, 3, 3, nil},
+		"search with empty name":          {token, "", 0, n, n, nil},
+		"search with no match":            {token, "actuator", 0, n, 0, nil},
+		"search with wrong credentials":   {wrong, "sensor", 0, n, 0, things.ErrUnauthorizedAccess},
+	}
+
+	for desc, tc := range cases {
+		ths, err := svc.SearchThings(context.Background(), tc.key, tc.name, tc.offset, tc.limit)
+		size := len(ths)
+		assert.Equal(t, tc.size, size, fmt.Sprintf("%s: expected %d got %d\n", desc, tc.size, size))
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+}
+
+func TestSearchThingsRanking(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+
+	for _, name := range []string{"water-pump", "pump-2", "Pump"} {
+		th := thing
+		th.Name = name
+		svc.AddThing(context.Background(), token, th)
+	}
+
+	ths, err := svc.SearchThings(context.Background(), token, "pump", 0, 10)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+	names := []string{}
+	for _, th := range ths {
+		names = append(names, th.Name)
+	}
+	expected := []string{"Pump", "pump-2", "water-pump"}
+	assert.Equal(t, expected, names, fmt.Sprintf("search ranking: expected %v got %v\n", expected, names))
+}
+
+func TestListUnconnectedThings(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	rch, _ := svc.CreateChannel(context.Background(), token, channel)
+
+	n := 10
+	for i := 0; i < n; i++ {
+		sth, _ := svc.AddThing(context.Background(), token, thing)
+		if i%2 == 0 {
+			svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+			continue
+		}
+		// connections to the removed channel don't count
+		svc.Connect(context.Background(), token, rch.ID, sth.ID, things.AccessPubSub)
+	}
+	svc.RemoveChannel(context.Background(), token, rch.ID)
+
+	cases := map[string]struct {
+		key    string
+		offset int
+		limit  int
+		size   int
+		err    error
+	}{
+		"list unconnected things":                        {token, 0, n, n / 2, nil},
+		"list subset of unconnected things":              {token, 1, 3, 3, nil},
+		"list unconnected things past the last one":      {token, n / 2, n, 0, nil},
+		"list unconnected things with wrong credentials": {wrong, 0, n, 0, things.ErrUnauthorizedAccess},
+	}
+
+	for desc, tc := range cases {
+		ths, err := svc.ListUnconnectedThings(context.Background(), tc.key, tc.offset, tc.limit)
+		size := len(ths)
+		assert.Equal(t, tc.size, size, fmt.Sprintf("%s: expected %d got %d\n", desc, tc.size, size))
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+}
+
+func TestListThingsByMetadata(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+
+	n := 10
+	for i := 0; i < n; i++ {
+		th := thing
+		th.Metadata = map[string]interface{}{"firmware": fmt.Sprintf("1.%d", i%2)}
+		svc.AddThing(context.Background(), token, th)
+	}
+
+	cases := map[string]struct {
+		key       string
+		metaKey   string
+		metaValue string
+		offset    int
+		limit     int
+		size      int
+		err       error
+	}{
+		"list by metadata":                        {token, "firmware", "1.1", 0, n, n / 2, nil},
+		"list subset by metadata":                 {token, "firmware", "1.0", 1, 3, 3, nil},
+		"list by non-existent metadata key":       {token, "location", "1.0", 0, n, 0, nil},
+		"list by metadata with wrong value":       {token, "firmware", "2.0", 0, n, 0, nil},
+		"list by metadata with wrong credentials": {wrong, "firmware", "1.0", 0, n, 0, things.ErrUnauthorizedAccess},
+	}
+
+	for desc, tc := range cases {
+		ths, err := svc.ListThingsByMetadata(context.Background(), tc.key, tc.metaKey, tc.metaValue, tc.offset, tc.limit)
+		size := len(ths)
+		assert.Equal(t, tc.size, size, fmt.Sprintf("%s: expected %d got %d\n", desc, tc.size, size))
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+}
+
+func TestRemoveThing(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	saved, _ := svc.AddThing(context.Background(), token, thing)
+
+	cases := map[string]struct {
+		id  string
+		key string
+		err error
+	}{
+		"remove thing with wrong credentials": {saved.ID, "?", things.ErrUnauthorizedAccess},
+		"remove existing thing":               {saved.ID, token, nil},
+		"remove removed thing":                {saved.ID, token, nil},
+		"remove non-existing thing":           {"?", token, nil},
+	}
+
+	for desc, tc := range cases {
+		err := svc.RemoveThing(context.Background(), tc.key, tc.id)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+}
+
+func TestRemoveAllThings(t *testing.T) {
+	otherToken := "other-token"
+	svc := newService(map[string]string{token: email, otherToken: "other@example.com"})
+
+	n := 5
+	ths := []things.Thing{}
+	for i := 0; i < n; i++ {
+		sth, _ := svc.AddThing(context.Background(), token, thing)
+		ths = append(ths, sth)
+	}
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	svc.Connect(context.Background(), token, sch.ID, ths[0].ID, things.AccessPubSub)
+
+	for i := 0; i < n; i++ {
+		svc.AddThing(context.Background(), otherToken, thing)
+	}
+
+	err := svc.RemoveAllThings(context.Background(), wrong)
+	assert.Equal(t, things.ErrUnauthorizedAccess, err, fmt.Sprintf("remove all things with wrong credentials: expected %s got %s\n", things.ErrUnauthorizedAccess, err))
+
+	err = svc.RemoveAllThings(context.Background(), token)
+	assert.Nil(t, err, fmt.Sprintf("remove all things: unexpected error %s\n", err))
+
+	page, _ := svc.ListThings(context.Background(), token, 0, 10, things.Sorting{}, things.ThingFilter{})
+	assert.Empty(t, page.Things, fmt.Sprintf("list removed things: expected no things got %d\n", len(page.Things)))
+
+	_, err = svc.CanAccess(context.Background(), ths[0].Key, sch.ID, things.AccessPubSub)
+	assert.Equal(t, things.ErrUnauthorizedAccess, err, fmt.Sprintf("check access of removed thing: expected %s got %s\n", things.ErrUnauthorizedAccess, err))
+
+	page, _ = svc.ListThings(context.Background(), otherToken, 0, 10, things.Sorting{}, things.ThingFilter{})
+	assert.Len(t, page.Things, n, fmt.Sprintf("list other user's things: expected %d things got %d\n", n, len(page.Things)))
+}
+
+func TestRemoveThingDisconnects(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+
+	err := svc.RemoveThing(context.Background(), token, sth.ID)
+	assert.Nil(t, err, fmt.Sprintf("remove connected thing: unexpected error %s\n", err))
+
+	// connections are not restored along with the thing
+	svc.RestoreThing(context.Background(), token, sth.ID)
+	_, err = svc.CanAccess(context.Background(), sth.Key, sch.ID, things.AccessPubSub)
+	assert.Equal(t, things.ErrUnauthorizedAccess, err, fmt.Sprintf("check access of restored thing: expected %s got %s\n", things.ErrUnauthorizedAccess, err))
+}
+
+func TestTransferThing(t *testing.T) {
+	otherToken := "other-token"
+	otherEmail := "other@example.com"
+	svc := newService(map[string]string{token: email, otherToken: otherEmail})
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+
+	cases := []struct {
+		desc     string
+		id       string
+		key      string
+		newOwner string
+		err      error
+	}{
+		{"transfer thing with wrong credentials", sth.ID, wrong, otherEmail, things.ErrUnauthorizedAccess},
+		{"transfer non-existing thing", wrong, token, otherEmail, things.ErrNotFound},
+		{"transfer thing to unregistered user", sth.ID, token, "unknown@example.com", things.ErrMalformedEntity},
+		{"transfer existing thing", sth.ID, token, otherEmail, nil},
+		{"transfer transferred thing", sth.ID, token, otherEmail, things.ErrNotFound},
+	}
+
+	for _, tc := range cases {
+		err := svc.TransferThing(context.Background(), tc.key, tc.id, tc.newOwner)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+
+	_, err := svc.ViewThing(context.Background(), token, sth.ID)
+	assert.Equal(t, things.ErrNotFound, err, fmt.Sprintf("view thing as old owner: expected %s got %s\n", things.ErrNotFound, err))
+
+	_, err = svc.ViewThing(context.Background(), otherToken, sth.ID)
+	assert.Nil(t, err, fmt.Sprintf("view thing as new owner: unexpected error %s\n", err))
+
+	_, err = svc.CanAccess(context.Background(), sth.Key, sch.ID, things.AccessPubSub)
+	assert.Equal(t, things.ErrUnauthorizedAccess, err, fmt.Sprintf("check access of transferred thing: expected %s got %s\n", things.ErrUnauthorizedAccess, err))
+}
+
+func TestTransferThingConflict(t *testing.T) {
+	otherToken := "other-token"
+	otherEmail := "other@example.com"
+	svc := newService(map[string]string{token: email, otherToken: otherEmail})
+
+	th := things.Thing{Type: "app", Name: "ext", ExternalID: "ext"}
+	sth, _ := svc.AddThing(context.Background(), token, th)
+	svc.AddThing(context.Background(), otherToken, th)
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+
+	err := svc.TransferThing(context.Background(), token, sth.ID, otherEmail)
+	assert.Equal(t, things.ErrConflict, err, fmt.Sprintf("transfer thing with taken external ID: expected %s got %s\n", things.ErrConflict, err))
+
+	// failed transfer leaves the thing connected
+	_, err = svc.CanAccess(context.Background(), sth.Key, sch.ID, things.AccessPubSub)
+	assert.Nil(t, err, fmt.Sprintf("check access of thing that failed to transfer: unexpected error %s\n", err))
+}
+
+func TestRestoreThing(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	saved, _ := svc.AddThing(context.Background(), token, thing)
+	svc.RemoveThing(context.Background(), token, saved.ID)
+
+	_, err := svc.ViewThing(context.Background(), token, saved.ID)
+	assert.Equal(t, things.ErrNotFound, err, fmt.Sprintf("view removed thing: expected %s got %s\n", things.ErrNotFound, err))
+
+	cases := map[string]struct {
+		id  string
+		key string
+		err error
+	}{
+		"restore thing with wrong credentials": {saved.ID, wrong, things.ErrUnauthorizedAccess},
+		"restore removed thing":                {saved.ID, token, nil},
+		"restore non-existing thing":           {wrong, token, things.ErrNotFound},
+	}
+
+	for desc, tc := range cases {
+		err := svc.RestoreThing(context.Background(), tc.key, tc.id)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+
+	_, err = svc.ViewThing(context.Background(), token, saved.ID)
+	assert.Nil(t, err, fmt.Sprintf("view restored thing: unexpected error %s\n", err))
+}
+
+func TestReAddThingWithExternalID(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	th := things.Thing{Type: "app", Name: "ext", ExternalID: "ext"}
+	saved, _ := svc.AddThing(context.Background(), token, th)
+	svc.RemoveThing(context.Background(), token, saved.ID)
+
+	readded, err := svc.AddThing(context.Background(), token, th)
+	assert.Nil(t, err, fmt.Sprintf("re-add removed thing's external ID: unexpected error %s\n", err))
+	assert.False(t, readded.Existing, "re-add removed thing's external ID: expected new thing\n")
+	assert.NotEqual(t, saved.ID, readded.ID, fmt.Sprintf("re-add removed thing's external ID: expected ID other than %s\n", saved.ID))
+
+	err = svc.RestoreThing(context.Background(), token, saved.ID)
+	assert.Equal(t, things.ErrConflict, err, fmt.Sprintf("restore thing with taken external ID: expected %s got %s\n", things.ErrConflict, err))
+}
+
+func TestListDeletedThings(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+
+	n := 10
+	for i := 0; i < n; i++ {
+		sth, _ := svc.AddThing(context.Background(), token, thing)
+		if i%2 == 0 {
+			svc.RemoveThing(context.Background(), token, sth.ID)
+		}
+	}
+
+	page, err := svc.ListThings(context.Background(), token, 0, n, things.Sorting{}, things.ThingFilter{})
+	assert.Nil(t, err, fmt.Sprintf("list things: unexpected error %s\n", err))
+	assert.Equal(t, n/2, page.Total, fmt.Sprintf("list things: expected total %d got %d\n", n/2, page.Total))
+
+	cases := map[string]struct {
+		key    string
+		offset int
+		limit  int
+		size   int
+		total  int
+		err    error
+	}{
+		"list all deleted things":                    {token, 0, n, n / 2, n / 2, nil},
+		"list subset of deleted things":              {token, 1, 3, 3, n / 2, nil},
+		"list deleted things with wrong credentials": {wrong, 0, n, 0, 0, things.ErrUnauthorizedAccess},
+	}
+
+	for desc, tc := range cases {
+		page, err := svc.ListDeletedThings(context.Background(), tc.key, tc.offset, tc.limit, things.Sorting{})
+		size := len(page.Things)
+		assert.Equal(t, tc.size, size, fmt.Sprintf("%s: expected %d got %d\n", desc, tc.size, size))
+		assert.Equal(t, tc.total, page.Total, fmt.Sprintf("%s: expected total %d got %d\n", desc, tc.total, page.Total))
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+}
+
+func TestCreateChannel(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+
+	cases := map[string]struct {
+		channel things.Channel
+		key     string
+		err     error
+	}{
+		"create channel":                        {channel, token, nil},
+		"create channel with wrong credentials": {channel, wrong, things.ErrUnauthorizedAccess},
+		"create channel with empty name":        {things.Channel{}, token, things.ErrMalformedEntity},
+		"create channel with too long name":     {things.Channel{Name: strings.Repeat("a", things.MaxNameLength+1)}, token, things.ErrMalformedEntity},
+		"create channel with webhook":           {things.Channel{Name: "test", Webhook: &things.Webhook{URL: "https://example.com/hook"}}, token, nil},
+		"create channel with invalid webhook":   {things.Channel{Name: "test", Webhook: &things.Webhook{URL: "example.com/hook"}}, token, things.ErrMalformedEntity},
+		"create channel with local webhook":     {things.Channel{Name: "test", Webhook: &things.Webhook{URL: "http://169.254.169.254/hook"}}, token, things.ErrMalformedEntity},
+	}
+
+	for desc, tc := range cases {
+		_, err := svc.CreateChannel(context.Background(), tc.key, tc.channel)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+}
+
+func TestUpdateChannel(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	saved, _ := svc.CreateChannel(context.Background(), token, channel)
+
+	cases := map[string]struct {
+		channel things.Channel
+		key     string
+		err     error
+	}{
+		"update existing channel":               {saved, token, nil},
+		"update channel with wrong credentials": {saved, wrong, things.ErrUnauthorizedAccess},
+		"update non-existing channel":           {things.Channel{ID: "2", Name: "test"}, token, things.ErrNotFound},
+	}
+
+	for desc, tc := range cases {
+		err := svc.UpdateChannel(context.Background(), tc.key, tc.channel)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+}
+
+func TestQuota(t *testing.T) {
+	users := mocks.NewUsersService(map[string]string{token: email})
+	thingsRepo := mocks.NewThingRepository()
+	channelsRepo := mocks.NewChannelRepository(thingsRepo)
+	idp := mocks.NewIdentityProvider()
+	svc := things.New(users, thingsRepo, channelsRepo, idp, things.WithQuota(things.NewStaticQuota(3, 1)))
+
+	_, err := svc.AddThing(context.Background(), token, thing)
+	assert.Nil(t, err, fmt.Sprintf("add thing within quota: unexpected error %s\n", err))
+
+	_, err = svc.CreateThings(context.Background(), token, []things.Thing{thing, thing, thing}, false)
+	assert.Equal(t, things.ErrQuotaExceeded, err, fmt.Sprintf("create things over quota: expected %s got %s\n", things.ErrQuotaExceeded, err))
+
+	_, err = svc.CreateThings(context.Background(), token, []things.Thing{thing, thing}, false)
+	assert.Nil(t, err, fmt.Sprintf("create things within quota: unexpected error %s\n", err))
+
+	_, err = svc.AddThing(context.Background(), token, thing)
+	assert.Equal(t, things.ErrQuotaExceeded, err, fmt.Sprintf("add thing over quota: expected %s got %s\n", things.ErrQuotaExceeded, err))
+
+	page, _ := svc.ListThings(context.Background(), token, 0, 10, things.Sorting{}, things.ThingFilter{})
+	assert.Equal(t, 3, page.Total, fmt.Sprintf("list things: expected %d got %d\n", 3, page.Total))
+
+	_, err = svc.CreateChannel(context.Background(), token, channel)
+	assert.Nil(t, err, fmt.Sprintf("create channel within quota: unexpected error %s\n", err))
+
+	_, err = svc.CreateChannel(context.Background(), token, channel)
+	assert.Equal(t, things.ErrQuotaExceeded, err, fmt.Sprintf("create channel over quota: expected %s got %s\n", things.ErrQuotaExceeded, err))
+}
+
+func TestUniqueChannelNames(t *testing.T) {
+	otherToken := "other-token"
+	users := mocks.NewUsersService(map[string]string{token: email, otherToken: "other@example.com"})
+	thingsRepo := mocks.NewThingRepository()
+	channelsRepo := mocks.NewChannelRepository(thingsRepo)
+	idp := mocks.NewIdentityProvider()
+	svc := things.New(users, thingsRepo, channelsRepo, idp, things.WithUniqueChannelNames())
+
+	temp, _ := svc.CreateChannel(context.Background(), token, things.Channel{Name: "temperature"})
+	hum, _ := svc.CreateChannel(context.Background(), token, things.Channel{Name: "humidity"})
+
+	_, err := svc.CreateChannel(context.Background(), token, things.Channel{Name: "temperature"})
+	assert.Equal(t, things.ErrConflict, err, fmt.Sprintf("create channel with taken name: expected %s got %s\n", things.ErrConflict, err))
+
+	_, err = svc.CreateChannel(context.Background(), otherToken, things.Channel{Name: "temperature"})
+	assert.Nil(t, err, fmt.Sprintf("create channel with name taken by other user: unexpected error %s\n", err))
+
+	cases := []struct {
+		desc    string
+		channel things.Channel
+		err     error
+	}{
+		{"update channel with its own name", things.Channel{ID: temp.ID, Name: "temperature"}, nil},
+		{"update channel with taken name", things.Channel{ID: hum.ID, Name: "temperature"}, things.ErrConflict},
+		{"update channel with free name", things.Channel{ID: hum.ID, Name: "humidity-indoor"}, nil},
+	}
+
+	for _, tc := range cases {
+		err := svc.UpdateChannel(context.Background(), token, tc.channel)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+}
+
+func TestNonUniqueChannelNames(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+
+	svc.CreateChannel(context.Background(), token, things.Channel{Name: "temperature"})
+	_, err := svc.CreateChannel(context.Background(), token, things.Channel{Name: "temperature"})
+	assert.Nil(t, err, fmt.Sprintf("create channel with taken name: unexpected error %s\n", err))
+}
+
+func TestUpdateChannelTimestamps(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	saved, _ := svc.CreateChannel(context.Background(), token, channel)
+
+	svc.UpdateChannel(context.Background(), token, saved)
+	updated, _ := svc.ViewChannel(context.Background(), token, saved.ID)
+
+	assert.Equal(t, saved.CreatedAt, updated.CreatedAt, fmt.Sprintf("update channel: expected created at %s got %s\n", saved.CreatedAt, updated.CreatedAt))
+	assert.True(t, updated.UpdatedAt.After(saved.UpdatedAt), fmt.Sprintf("update channel: expected updated at after %s got %s\n", saved.UpdatedAt, updated.UpdatedAt))
+}
+
+func TestViewChannel(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	saved, _ := svc.CreateChannel(context.Background(), token, channel)
+
+	cases := map[string]struct {
+		id  string
+		key string
+		err error
+	}{
+		"view existing channel":               {saved.ID, token, nil},
+		"view channel with wrong credentials": {saved.ID, wrong, things.ErrUnauthorizedAccess},
+		"view non-existing channel":           {wrong, token, things.ErrNotFound},
+	}
+
+	for desc, tc := range cases {
+		_, err := svc.ViewChannel(context.Background(), tc.key, tc.id)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+}
+
+func TestCountThings(t *testing.T) {
+	otherToken, otherEmail := "other-token", "other@example.com"
+	svc := newService(map[string]string{
+		token:      email,
+		otherToken: otherEmail,
+	})
+
+	n := 5
+	for i := 0; i < n; i++ {
+		svc.AddThing(context.Background(), token, thing)
+		svc.AddThing(context.Background(), otherToken, thing)
+	}
+	sth, _ := svc.AddThing(context.Background(), otherToken, thing)
+	svc.RemoveThing(context.Background(), otherToken, sth.ID)
+	svc.AddThing(context.Background(), otherToken, thing)
+
+	cases := map[string]struct {
+		key   string
+		count int
+		err   error
+	}{
+		"count things":                        {token, n, nil},
+		"count other user's things":           {otherToken, n + 1, nil},
+		"count things with wrong credentials": {wrong, 0, things.ErrUnauthorizedAccess},
+	}
+
+	for desc, tc := range cases {
+		count, err := svc.CountThings(context.Background(), tc.key, things.ThingFilter{})
+		assert.Equal(t, tc.count, count, fmt.Sprintf("%s: expected %d got %d\n", desc, tc.count, count))
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+}
+
+func TestCountFilteredThings(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+
+	n := 6
+	for i := 0; i < n; i++ {
+		app := thing
+		app.Tags = []string{"lab"}
+		sth, _ := svc.AddThing(context.Background(), token, app)
+		if i%2 == 0 {
+			svc.DisableThing(context.Background(), token, sth.ID)
+		}
+
+		device := thing
+		device.Type = "device"
+		device.Name = fmt.Sprintf("Sensor-%d", i)
+		device.Metadata = map[string]interface{}{"region": "eu"}
+		sth, _ = svc.AddThing(context.Background(), token, device)
+		if i%3 == 0 {
+			svc.DisableThing(context.Background(), token, sth.ID)
+		}
+	}
+
+	cases := map[string]struct {
+		filter things.ThingFilter
+		count  int
+		err    error
+	}{
+		"count disabled devices":            {things.ThingFilter{Type: "device", Status: things.StatusDisabled}, n / 3, nil},
+		"count enabled apps":                {things.ThingFilter{Type: "app", Status: things.StatusEnabled}, n / 2, nil},
+		"count things by name":              {things.ThingFilter{Name: "sensor"}, n, nil},
+		"count things by tag":               {things.ThingFilter{Tag: "lab"}, n, nil},
+		"count things by metadata":          {things.ThingFilter{Metadata: things.MetadataFilter{Key: "region", Value: "eu"}}, n, nil},
+		"count things with invalid type":    {things.ThingFilter{Type: "invalid"}, 0, things.ErrMalformedEntity},
+		"count things with invalid status":  {things.ThingFilter{Status: "invalid"}, 0, things.ErrMalformedEntity},
+		"count things matching all filters": {things.ThingFilter{Name: "sensor-0", Status: things.StatusDisabled, Metadata: things.MetadataFilter{Key: "region", Value: "eu"}}, 1, nil},
+	}
+
+	for desc, tc := range cases {
+		count, err := svc.CountThings(context.Background(), token, tc.filter)
+		assert.Equal(t, tc.count, count, fmt.Sprintf("%s: expected %d got %d\n", desc, tc.count, count))
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+}
+
+func TestListChannels(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+
+	n := 10
+	for i := 0; i < n; i++ {
+		svc.CreateChannel(context.Background(), token, channel)
+	}
+	cases := map[string]struct {
+		key    string
+		offset int
+		limit  int
+		size   int
+		total  int
+		err    error
+	}{
+		"list first 5 channels":                {token, 0, 5, 5, n, nil},
+		"list channels 5-10 channels":          {token, 5, 10, 5, n, nil},
+		"list last channel":                    {token, 6, 10, 4, n, nil},
+		"list offset < 0":                      {token, -1, 10, 0, n, nil},
+		"list limit < 0":                       {token, 1, -10, 0, n, nil},
+		"list limit = 0":                       {token, 1, 0, 0, n, nil},
+		"list channels with wrong credentials": {wrong, 0, 0, 0, 0, things.ErrUnauthorizedAccess},
+	}
+
+	for desc, tc := range cases {
+		page, err := svc.ListChannels(context.Background(), tc.key, tc.offset, tc.limit, things.Sorting{}, things.MetadataFilter{})
+		size := len(page.Channels)
+		assert.Equal(t, tc.size, size, fmt.Sprintf("%s: expected %d got %d\n", desc, tc.size, size))
+		assert.Equal(t, tc.total, page.Total, fmt.Sprintf("%s: expected total %d got %d\n", desc, tc.total, page.Total))
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+}
+
+func TestListChannelsSorted(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+
+	n := 5
+	for i := 0; i < n; i++ {
+		ch := channel
+		ch.Name = fmt.Sprintf("channel-%d", i)
+		svc.CreateChannel(context.Background(), token, ch)
+	}
+
+	page, _ := svc.ListChannels(context.Background(), token, 0, n, things.Sorting{Order: things.OrderName, Dir: things.DirDesc}, things.MetadataFilter{})
+	for i, ch := range page.Channels {
+		expected := fmt.Sprintf("channel-%d", n-1-i)
+		assert.Equal(t, expected, ch.Name, fmt.Sprintf("list channels sorted by name descending: expected %s got %s\n", expected, ch.Name))
+	}
+}
+
+func TestListChannelsByMetadata(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+
+	n := 10
+	for i := 0; i < n; i++ {
+		ch := channel
+		ch.Metadata = map[string]interface{}{"region": "eu"}
+		if i%2 == 1 {
+			ch.Metadata["region"] = "us"
+		}
+		svc.CreateChannel(context.Background(), token, ch)
+	}
+
+	cases := map[string]struct {
+		filter things.MetadataFilter
+		size   int
+	}{
+		"list channels in the eu region":           {things.MetadataFilter{Key: "region", Value: "eu"}, n / 2},
+		"list channels in the us region":           {things.MetadataFilter{Key: "region", Value: "us"}, n / 2},
+		"list channels in non-existing region":     {things.MetadataFilter{Key: "region", Value: "asia"}, 0},
+		"list channels with non-existing metadata": {things.MetadataFilter{Key: "protocol", Value: "eu"}, 0},
+		"list channels without metadata filter":    {things.MetadataFilter{}, n},
+	}
+
+	for desc, tc := range cases {
+		page, err := svc.ListChannels(context.Background(), token, 0, n, things.Sorting{}, tc.filter)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s\n", desc, err))
+		assert.Len(t, page.Channels, tc.size, fmt.Sprintf("%s: expected %d got %d\n", desc, tc.size, len(page.Channels)))
+		assert.Equal(t, tc.size, page.Total, fmt.Sprintf("%s: expected total %d got %d\n", desc, tc.size, page.Total))
+		for _, ch := range page.Channels {
+			if tc.filter.Key == "" {
+				continue
+			}
+			assert.Equal(t, tc.filter.Value, ch.Metadata[tc.filter.Key], fmt.Sprintf("%s: expected %s got %v\n", desc, tc.filter.Value, ch.Metadata[tc.filter.Key]))
+		}
+	}
+}
+
+func TestCountChannels(t *testing.T) {
+	otherToken, otherEmail := "other-token", "other@example.com"
+	svc := newService(map[string]string{
+		token:      email,
+		otherToken: otherEmail,
+	})
+
+	n := 5
+	for i := 0; i < n; i++ {
+		svc.CreateChannel(context.Background(), token, channel)
+	}
+	sch, _ := svc.CreateChannel(context.Background(), otherToken, channel)
+	svc.CreateChannel(context.Background(), otherToken, channel)
+	svc.RemoveChannel(context.Background(), otherToken, sch.ID)
+
+	cases := map[string]struct {
+		key   string
+		count int
+		err   error
+	}{
+		"count channels":                        {token, n, nil},
+		"count other user's channels":           {otherToken, 1, nil},
+		"count channels with wrong credentials": {wrong, 0, things.ErrUnauthorizedAccess},
+	}
+
+	for desc, tc := range cases {
+		count, err := svc.CountChannels(context.Background(), tc.key)
+		assert.Equal(t, tc.count, count, fmt.Sprintf("%s: expected %d got %d\n", desc, tc.count, count))
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+}
+
+func TestChannelConnectionsCount(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	empty, _ := svc.CreateChannel(context.Background(), token, channel)
+
+	n := 3
+	for i := 0; i < n; i++ {
+		sth, _ := svc.AddThing(context.Background(), token, thing)
+		svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+	}
+
+	cases := map[string]struct {
+		key    string
+		chanID string
+		count  int
+		err    error
+	}{
+		"count things connected to channel":              {token, sch.ID, n, nil},
+		"count things connected to empty channel":        {token, empty.ID, 0, nil},
+		"count things connected to non-existing channel": {token, wrong, 0, things.ErrNotFound},
+		"count things connected with wrong credentials":  {wrong, sch.ID, 0, things.ErrUnauthorizedAccess},
+	}
+
+	for desc, tc := range cases {
+		count, err := svc.ChannelConnectionsCount(context.Background(), tc.key, tc.chanID)
+		assert.Equal(t, tc.count, count, fmt.Sprintf("%s: expected %d got %d\n", desc, tc.count, count))
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+}
+
+func TestChannelConnections(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	empty, _ := svc.CreateChannel(context.Background(), token, channel)
+
+	n := 3
+	ids := []string{}
+	for i := 0; i < n; i++ {
+		sth, _ := svc.AddThing(context.Background(), token, thing)
+		svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+		ids = append(ids, sth.ID)
+		time.Sleep(time.Millisecond)
+	}
+
+	cases := map[string]struct {
+		key    string
+		chanID string
+		ids    []string
+		err    error
+	}{
+		"retrieve connections of channel":              {token, sch.ID, ids, nil},
+		"retrieve connections of empty channel":        {token, empty.ID, []string{}, nil},
+		"retrieve connections of non-existing channel": {token, wrong, nil, things.ErrNotFound},
+		"retrieve connections with wrong credentials":  {wrong, sch.ID, nil, things.ErrUnauthorizedAccess},
+	}
+
+	for desc, tc := range cases {
+		conns, err := svc.ChannelConnections(context.Background(), tc.key, tc.chanID)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+		if tc.ids == nil {
+			continue
+		}
+
+		ids := []string{}
+		for i, conn := range conns {
+			ids = append(ids, conn.ThingID)
+			if i > 0 {
+				assert.True(t, conns[i-1].ConnectedAt.Before(conn.ConnectedAt), fmt.Sprintf("%s: expected connections sorted by time\n", desc))
+			}
+		}
+		assert.Equal(t, tc.ids, ids, fmt.Sprintf("%s: expected %v got %v\n", desc, tc.ids, ids))
+	}
+}
+
+func TestListChannelsByThing(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	n := 10
+	for i := 0; i < n; i++ {
+		sch, _ := svc.CreateChannel(context.Background(), token, channel)
+		if i%2 == 0 {
+			svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+		}
+	}
+
+	cases := map[string]struct {
+		key     string
+		thingID string
+		offset  int
+		limit   int
+		size    int
+		err     error
+	}{
+		"list all connected channels":          {token, sth.ID, 0, n, n / 2, nil},
+		"list subset of connected channels":    {token, sth.ID, 1, 2, 2, nil},
+		"list last connected channel":          {token, sth.ID, n/2 - 1, n, 1, nil},
+		"list channels of non-existing thing":  {token, wrong, 0, n, 0, nil},
+		"list channels with zero limit":        {token, sth.ID, 0, 0, 0, nil},
+		"list channels with wrong credentials": {wrong, sth.ID, 0, n, 0, things.ErrUnauthorizedAccess},
+	}
+
+	for desc, tc := range cases {
+		ch, err := svc.ListChannelsByThing(context.Background(), tc.key, tc.thingID, tc.offset, tc.limit)
+		size := len(ch)
+		assert.Equal(t, tc.size, size, fmt.Sprintf("%s: expected %d got %d\n", desc, tc.size, size))
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+}
+
+func TestListThingsByChannel(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	n := 10
+	for i := 0; i < n; i++ {
+		sth, _ := svc.AddThing(context.Background(), token, thing)
+		if i%2 == 0 {
+			svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+		}
+	}
+
+	cases := map[string]struct {
+		key    string
+		chanID string
+		offset int
+		limit  int
+		size   int
+		err    error
+	}{
+		"list all connected things":           {token, sch.ID, 0, n, n / 2, nil},
+		"list subset of connected things":     {token, sch.ID, 1, 2, 2, nil},
+		"list last connected thing":           {token, sch.ID, n/2 - 1, n, 1, nil},
+		"list things of non-existing channel": {token, wrong, 0, n, 0, nil},
+		"list things with zero limit":         {token, sch.ID, 0, 0, 0, nil},
+		"list things with wrong credentials":  {wrong, sch.ID, 0, n, 0, things.ErrUnauthorizedAccess},
+	}
+
+	for desc, tc := range cases {
+		ths, err := svc.ListThingsByChannel(context.Background(), tc.key, tc.chanID, tc.offset, tc.limit, things.StateConnected)
+		size := len(ths)
+		assert.Equal(t, tc.size, size, fmt.Sprintf("%s: expected %d got %d\n", desc, tc.size, size))
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+}
+
+func TestListThingsByChannelConnectionState(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	ids := make([]string, 4)
+	for i := range ids {
+		sth, _ := svc.AddThing(context.Background(), token, thing)
+		svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+		ids[i] = sth.ID
+	}
+
+	// the first disconnected thing is connected again, so it is listed as
+	// connected only
+	for _, id := range ids[1:] {
+		svc.Disconnect(context.Background(), token, sch.ID, id)
+	}
+	svc.Connect(context.Background(), token, sch.ID, ids[1], things.AccessPubSub)
+
+	cases := map[string]struct {
+		state things.ConnectionState
+		ids   []string
+	}{
+		"list connected things":                  {things.StateConnected, ids[:2]},
+		"list recently disconnected things":      {things.StateDisconnected, ids[2:]},
+		"list connected and disconnected things": {things.StateAny, ids},
+	}
+
+	for desc, tc := range cases {
+		ths, err := svc.ListThingsByChannel(context.Background(), token, sch.ID, 0, 10, tc.state)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s\n", desc, err))
+
+		listed := []string{}
+		for _, th := range ths {
+			listed = append(listed, th.ID)
+		}
+		assert.ElementsMatch(t, tc.ids, listed, fmt.Sprintf("%s: expected %v got %v\n", desc, tc.ids, listed))
+	}
+}
+
+func TestListThingsByChannelDisconnectionWindow(t *testing.T) {
+	users := mocks.NewUsersService(map[string]string{token: email})
+	thingsRepo := mocks.NewThingRepository()
+	channelsRepo := mocks.NewChannelRepository(thingsRepo)
+	idp := mocks.NewIdentityProvider()
+	svc := things.New(users, thingsRepo, channelsRepo, idp, things.WithDisconnectionWindow(-time.Minute))
+
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+	svc.Disconnect(context.Background(), token, sch.ID, sth.ID)
+
+	ths, err := svc.ListThingsByChannel(context.Background(), token, sch.ID, 0, 10, things.StateAny)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error %s\n", err))
+	assert.Empty(t, ths, fmt.Sprintf("expected no things outside of disconnection window got %v\n", ths))
+}
+
+func TestRemoveChannel(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	saved, _ := svc.CreateChannel(context.Background(), token, channel)
+
+	cases := map[string]struct {
+		id  string
+		key string
+		err error
+	}{
+		"remove channel with wrong credentials": {saved.ID, wrong, things.ErrUnauthorizedAccess},
+		"remove existing channel":               {saved.ID, token, nil},
+		"remove removed channel":                {saved.ID, token, nil},
+		"remove non-existing channel":           {saved.ID, token, nil},
+	}
+
+	for desc, tc := range cases {
+		err := svc.RemoveChannel(context.Background(), tc.key, tc.id)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+}
+
+func TestRemoveAllChannels(t *testing.T) {
+	otherToken := "other-token"
+	svc := newService(map[string]string{token: email, otherToken: "other@example.com"})
+
+	n := 5
+	chs := []things.Channel{}
+	for i := 0; i < n; i++ {
+		sch, _ := svc.CreateChannel(context.Background(), token, channel)
+		chs = append(chs, sch)
+	}
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	svc.Connect(context.Background(), token, chs[0].ID, sth.ID, things.AccessPubSub)
+
+	for i := 0; i < n; i++ {
+		svc.CreateChannel(context.Background(), otherToken, channel)
+	}
+
+	err := svc.RemoveAllChannels(context.Background(), wrong)
+	assert.Equal(t, things.ErrUnauthorizedAccess, err, fmt.Sprintf("remove all channels with wrong credentials: expected %s got %s\n", things.ErrUnauthorizedAccess, err))
+
+	err = svc.RemoveAllChannels(context.Background(), token)
+	assert.Nil(t, err, fmt.Sprintf("remove all channels: unexpected error %s\n", err))
+
+	page, _ := svc.ListChannels(context.Background(), token, 0, 10, things.Sorting{}, things.MetadataFilter{})
+	assert.Empty(t, page.Channels, fmt.Sprintf("list removed channels: expected no channels got %d\n", len(page.Channels)))
+
+	_, err = svc.CanAccess(context.Background(), sth.Key, chs[0].ID, things.AccessPubSub)
+	assert.Equal(t, things.ErrUnauthorizedAccess, err, fmt.Sprintf("check access to removed channel: expected %s got %s\n", things.ErrUnauthorizedAccess, err))
+
+	page, _ = svc.ListChannels(context.Background(), otherToken, 0, 10, things.Sorting{}, things.MetadataFilter{})
+	assert.Len(t, page.Channels, n, fmt.Sprintf("list other user's channels: expected %d channels got %d\n", n, len(page.Channels)))
+
+	err = svc.RestoreChannel(context.Background(), token, chs[0].ID)
+	assert.Nil(t, err, fmt.Sprintf("restore removed channel: unexpected error %s\n", err))
+
+	_, err = svc.CanAccess(context.Background(), sth.Key, chs[0].ID, things.AccessPubSub)
+	assert.Equal(t, things.ErrUnauthorizedAccess, err, fmt.Sprintf("check access to restored channel: expected %s got %s\n", things.ErrUnauthorizedAccess, err))
+}
+
+func TestTransferChannel(t *testing.T) {
+	otherToken := "other-token"
+	otherEmail := "other@example.com"
+	svc := newService(map[string]string{token: email, otherToken: otherEmail})
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+
+	cases := []struct {
+		desc     string
+		id       string
+		key      string
+		newOwner string
+		err      error
+	}{
+		{"transfer channel with wrong credentials", sch.ID, wrong, otherEmail, things.ErrUnauthorizedAccess},
+		{"transfer non-existing channel", wrong, token, otherEmail, things.ErrNotFound},
+		{"transfer channel to unregistered user", sch.ID, token, "unknown@example.com", things.ErrMalformedEntity},
+		{"transfer existing channel", sch.ID, token, otherEmail, nil},
+		{"transfer transferred channel", sch.ID, token, otherEmail, things.ErrNotFound},
+	}
+
+	for _, tc := range cases {
+		err := svc.TransferChannel(context.Background(), tc.key, tc.id, tc.newOwner)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+
+	_, err := svc.ViewChannel(context.Background(), token, sch.ID)
+	assert.Equal(t, things.ErrNotFound, err, fmt.Sprintf("view channel as old owner: expected %s got %s\n", things.ErrNotFound, err))
+
+	_, err = svc.ViewChannel(context.Background(), otherToken, sch.ID)
+	assert.Nil(t, err, fmt.Sprintf("view channel as new owner: unexpected error %s\n", err))
+
+	_, err = svc.CanAccess(context.Background(), sth.Key, sch.ID, things.AccessPubSub)
+	assert.Equal(t, things.ErrUnauthorizedAccess, err, fmt.Sprintf("check access of transferred channel: expected %s got %s\n", things.ErrUnauthorizedAccess, err))
+}
+
+func TestRemoveChannelDisconnects(t *testing.T) {
+	users := mocks.NewUsersService(map[string]string{token: email})
+	thingsRepo := mocks.NewThingRepository()
+	channelsRepo := mocks.NewChannelRepository(thingsRepo)
+	svc := things.New(users, thingsRepo, channelsRepo, mocks.NewIdentityProvider())
+
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	ths := []things.Thing{}
+	for i := 0; i < 3; i++ {
+		sth, _ := svc.AddThing(context.Background(), token, thing)
+		svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+		ths = append(ths, sth)
+	}
+
+	err := svc.RemoveChannel(context.Background(), token, sch.ID)
+	assert.Nil(t, err, fmt.Sprintf("remove channel with connected things: unexpected error %s\n", err))
+
+	for _, th := range ths {
+		_, err := svc.CanAccess(context.Background(), th.Key, sch.ID, things.AccessPubSub)
+		assert.Equal(t, things.ErrUnauthorizedAccess, err, fmt.Sprintf("check access to removed channel: expected %s got %s\n", things.ErrUnauthorizedAccess, err))
+
+		_, err = channelsRepo.HasThing(context.Background(), sch.ID, th.Key, things.AccessPubSub)
+		assert.Equal(t, things.ErrNotFound, err, fmt.Sprintf("check connection to removed channel: expected %s got %s\n", things.ErrNotFound, err))
+	}
+}
+
+func TestRestoreChannel(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+	svc.RemoveChannel(context.Background(), token, sch.ID)
+
+	_, err := svc.ViewChannel(context.Background(), token, sch.ID)
+	assert.Equal(t, things.ErrNotFound, err, fmt.Sprintf("view removed channel: expected %s got %s\n", things.ErrNotFound, err))
+
+	page, err := svc.ListChannels(context.Background(), token, 0, 10, things.Sorting{}, things.MetadataFilter{})
+	assert.Nil(t, err, fmt.Sprintf("list channels: unexpected error %s\n", err))
+	assert.Equal(t, 0, page.Total, fmt.Sprintf("list channels: expected total %d got %d\n", 0, page.Total))
+
+	_, err = svc.CanAccess(context.Background(), sth.Key, sch.ID, things.AccessPubSub)
+	assert.Equal(t, things.ErrUnauthorizedAccess, err, fmt.Sprintf("access removed channel: expected %s got %s\n", things.ErrUnauthorizedAccess, err))
+
+	cases := []struct {
+		desc string
+		id   string
+		key  string
+		err  error
+	}{
+		{"restore channel with wrong credentials", sch.ID, wrong, things.ErrUnauthorizedAccess},
+		{"restore non-existing channel", wrong, token, things.ErrNotFound},
+		{"restore removed channel", sch.ID, token, nil},
+		{"restore restored channel", sch.ID, token, things.ErrNotFound},
+	}
+
+	for _, tc := range cases {
+		err := svc.RestoreChannel(context.Background(), tc.key, tc.id)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+
+	ch, err := svc.ViewChannel(context.Background(), token, sch.ID)
+	assert.Nil(t, err, fmt.Sprintf("view restored channel: unexpected error %s\n", err))
+	assert.Equal(t, 1, len(ch.Things), fmt.Sprintf("view restored channel: expected %d connected things got %d\n", 1, len(ch.Things)))
+
+	page, err = svc.ListChannels(context.Background(), token, 0, 10, things.Sorting{}, things.MetadataFilter{})
+	assert.Nil(t, err, fmt.Sprintf("list channels: unexpected error %s\n", err))
+	assert.Equal(t, 1, page.Total, fmt.Sprintf("list channels: expected total %d got %d\n", 1, page.Total))
+
+	id, err := svc.CanAccess(context.Background(), sth.Key, sch.ID, things.AccessPubSub)
+	assert.Nil(t, err, fmt.Sprintf("access restored channel: unexpected error %s\n", err))
+	assert.Equal(t, sth.ID, id, fmt.Sprintf("access restored channel: expected %s got %s\n", sth.ID, id))
+}
+
+func TestConnect(t *testing.T) {
+	otherToken := "other-token"
+	svc := newService(map[string]string{token: email, otherToken: "other@example.com"})
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	oth, _ := svc.AddThing(context.Background(), otherToken, thing)
+	och, _ := svc.CreateChannel(context.Background(), otherToken, channel)
+
+	cases := map[string]struct {
+		key     string
+		chanID  string
+		thingID string
+		err     error
+	}{
+		"connect thing":                              {token, sch.ID, sth.ID, nil},
+		"connect thing with wrong credentials":       {wrong, sch.ID, sth.ID, things.ErrUnauthorizedAccess},
+		"connect thing to non-existing channel":      {token, wrong, sth.ID, things.ErrNotFound},
+		"connect non-existing thing":                 {token, sch.ID, wrong, things.ErrNotFound},
+		"connect thing of other user":                {token, sch.ID, oth.ID, things.ErrUnauthorizedAccess},
+		"connect thing to channel of other user":     {token, och.ID, sth.ID, things.ErrUnauthorizedAccess},
+		"connect thing of other user to its channel": {token, och.ID, oth.ID, things.ErrUnauthorizedAccess},
+	}
+
+	for desc, tc := range cases {
+		_, err := svc.Connect(context.Background(), tc.key, tc.chanID, tc.thingID, things.AccessPubSub)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+
+	conn, err := svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+	assert.Nil(t, err, fmt.Sprintf("reconnect thing: unexpected error %s\n", err))
+	assert.Equal(t, sch.ID, conn.ChanID, fmt.Sprintf("reconnect thing: expected channel %s got %s\n", sch.ID, conn.ChanID))
+	assert.Equal(t, sth.ID, conn.ThingID, fmt.Sprintf("reconnect thing: expected thing %s got %s\n", sth.ID, conn.ThingID))
+	assert.False(t, conn.ConnectedAt.IsZero(), fmt.Sprintf("reconnect thing: expected connection time to be set\n"))
+
+	again, _ := svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+	assert.Equal(t, conn.ConnectedAt, again.ConnectedAt, fmt.Sprintf("reconnect thing: expected connection time %s got %s\n", conn.ConnectedAt, again.ConnectedAt))
+}
+
+func TestConnectTwice(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+
+	for i := 0; i < 2; i++ {
+		_, err := svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+		assert.Nil(t, err, fmt.Sprintf("connect thing: unexpected error %s\n", err))
+	}
+
+	ths, _ := svc.ListThingsByChannel(context.Background(), token, sch.ID, 0, 10, things.StateConnected)
+	assert.Equal(t, 1, len(ths), fmt.Sprintf("list connected things: expected %d got %d\n", 1, len(ths)))
+
+	count, _ := svc.ChannelConnectionsCount(context.Background(), token, sch.ID)
+	assert.Equal(t, 1, count, fmt.Sprintf("count connected things: expected %d got %d\n", 1, count))
+
+	_, err := svc.Disconnect(context.Background(), token, sch.ID, sth.ID)
+	assert.Nil(t, err, fmt.Sprintf("disconnect thing: unexpected error %s\n", err))
+
+	count, _ = svc.ChannelConnectionsCount(context.Background(), token, sch.ID)
+	assert.Equal(t, 0, count, fmt.Sprintf("count connected things: expected %d got %d\n", 0, count))
+}
+
+func TestConnectMany(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	ch1, _ := svc.CreateChannel(context.Background(), token, channel)
+	ch2, _ := svc.CreateChannel(context.Background(), token, channel)
+
+	cases := []struct {
+		desc    string
+		key     string
+		thingID string
+		chanIDs []string
+		err     error
+	}{
+		{"connect thing to non-existing channel", token, sth.ID, []string{ch1.ID, wrong}, things.ErrNotFound},
+		{"connect non-existing thing", token, wrong, []string{ch1.ID, ch2.ID}, things.ErrNotFound},
+		{"connect thing with wrong credentials", wrong, sth.ID, []string{ch1.ID, ch2.ID}, things.ErrUnauthorizedAccess},
+		{"connect thing to channels", token, sth.ID, []string{ch1.ID, ch2.ID}, nil},
+		{"connect thing to already connected channels", token, sth.ID, []string{ch1.ID, ch2.ID}, nil},
+	}
+
+	for _, tc := range cases {
+		err := svc.ConnectMany(context.Background(), tc.key, tc.thingID, tc.chanIDs)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+
+	chs, _ := svc.ListChannelsByThing(context.Background(), token, sth.ID, 0, 10)
+	assert.Equal(t, 2, len(chs), fmt.Sprintf("list connected channels: expected %d got %d\n", 2, len(chs)))
+}
+
+func TestConnectManyIsAtomic(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+
+	err := svc.ConnectMany(context.Background(), token, sth.ID, []string{sch.ID, wrong})
+	assert.Equal(t, things.ErrNotFound, err, fmt.Sprintf("connect thing to non-existing channel: expected %s got %s\n", things.ErrNotFound, err))
+
+	chs, _ := svc.ListChannelsByThing(context.Background(), token, sth.ID, 0, 10)
+	assert.Empty(t, chs, fmt.Sprintf("list connected channels: expected none got %d\n", len(chs)))
+}
+
+func TestConnectThings(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+
+	th1, _ := svc.AddThing(context.Background(), token, thing)
+	th2, _ := svc.AddThing(context.Background(), token, thing)
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+
+	cases := []struct {
+		desc     string
+		key      string
+		chanID   string
+		thingIDs []string
+		err      error
+	}{
+		{"connect non-existing things to channel", token, sch.ID, []string{th1.ID, wrong}, things.ErrNotFound},
+		{"connect things to non-existing channel", token, wrong, []string{th1.ID, th2.ID}, things.ErrNotFound},
+		{"connect things with wrong credentials", wrong, sch.ID, []string{th1.ID, th2.ID}, things.ErrUnauthorizedAccess},
+		{"connect things to channel", token, sch.ID, []string{th1.ID, th2.ID}, nil},
+		{"connect already connected things to channel", token, sch.ID, []string{th1.ID, th2.ID}, nil},
+	}
+
+	for _, tc := range cases {
+		err := svc.ConnectThings(context.Background(), tc.key, tc.chanID, tc.thingIDs)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+
+	ths, _ := svc.ListThingsByChannel(context.Background(), token, sch.ID, 0, 10, things.StateConnected)
+	assert.Equal(t, 2, len(ths), fmt.Sprintf("list connected things: expected %d got %d\n", 2, len(ths)))
+}
+
+func TestConnectThingsIsAtomic(t *testing.T) {
+	otherToken := "other-token"
+	svc := newService(map[string]string{token: email, otherToken: "other@example.com"})
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	oth, _ := svc.AddThing(context.Background(), otherToken, thing)
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+
+	cases := []struct {
+		desc     string
+		thingIDs []string
+	}{
+		{"connect non-existing thing to channel", []string{sth.ID, wrong}},
+		{"connect thing of other user to channel", []string{sth.ID, oth.ID}},
+	}
+
+	for _, tc := range cases {
+		err := svc.ConnectThings(context.Background(), token, sch.ID, tc.thingIDs)
+		assert.Equal(t, things.ErrNotFound, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, things.ErrNotFound, err))
+
+		ths, _ := svc.ListThingsByChannel(context.Background(), token, sch.ID, 0, 10, things.StateConnected)
+		assert.Empty(t, ths, fmt.Sprintf("%s: expected no connected things got %d\n", tc.desc, len(ths)))
+	}
+}
+
+func TestSetChannelThings(t *testing.T) {
+	otherToken := "other-token"
+	svc := newService(map[string]string{token: email, otherToken: "other@example.com"})
+
+	ath, _ := svc.AddThing(context.Background(), token, thing)
+	bth, _ := svc.AddThing(context.Background(), token, thing)
+	cth, _ := svc.AddThing(context.Background(), token, thing)
+	oth, _ := svc.AddThing(context.Background(), otherToken, thing)
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	svc.ConnectThings(context.Background(), token, sch.ID, []string{ath.ID, bth.ID})
+
+	cases := []struct {
+		desc      string
+		key       string
+		chanID    string
+		thingIDs  []string
+		err       error
+		connected []string
+	}{
+		{"set things with wrong credentials", wrong, sch.ID, []string{bth.ID, cth.ID}, things.ErrUnauthorizedAccess, []string{ath.ID, bth.ID}},
+		{"set things of non-existing channel", token, wrong, []string{bth.ID, cth.ID}, things.ErrNotFound, []string{ath.ID, bth.ID}},
+		{"set non-existing thing", token, sch.ID, []string{bth.ID, wrong}, things.ErrNotFound, []string{ath.ID, bth.ID}},
+		{"set thing of other user", token, sch.ID, []string{bth.ID, oth.ID}, things.ErrNotFound, []string{ath.ID, bth.ID}},
+		{"set things of channel", token, sch.ID, []string{bth.ID, cth.ID}, nil, []string{bth.ID, cth.ID}},
+		{"set duplicated things of channel", token, sch.ID, []string{cth.ID, cth.ID}, nil, []string{cth.ID}},
+		{"set empty list of things", token, sch.ID, []string{}, nil, []string{}},
+	}
+
+	for _, tc := range cases {
+		err := svc.SetChannelThings(context.Background(), tc.key, tc.chanID, tc.thingIDs)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+
+		ths, _ := svc.ListThingsByChannel(context.Background(), token, sch.ID, 0, 10, things.StateConnected)
+		connected := []string{}
+		for _, th := range ths {
+			connected = append(connected, th.ID)
+		}
+		assert.ElementsMatch(t, tc.connected, connected, fmt.Sprintf("%s: expected connected things %v got %v\n", tc.desc, tc.connected, connected))
+	}
+}
+
+func TestDisconnect(t *testing.T) {
+	otherToken := "other-token"
+	svc := newService(map[string]string{token: email, otherToken: "other@example.com"})
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+	oth, _ := svc.AddThing(context.Background(), otherToken, thing)
+	och, _ := svc.CreateChannel(context.Background(), otherToken, channel)
+	svc.Connect(context.Background(), otherToken, och.ID, oth.ID, things.AccessPubSub)
+
+	cases := []struct {
+		desc    string
+		key     string
+		chanID  string
+		thingID string
+		removed bool
+		err     error
+	}{
+		{"disconnect connected thing", token, sch.ID, sth.ID, true, nil},
+		{"disconnect disconnected thing", token, sch.ID, sth.ID, false, nil},
+		{"disconnect thing with wrong credentials", wrong, sch.ID, sth.ID, false, things.ErrUnauthorizedAccess},
+		{"disconnect thing from non-existing channel", token, wrong, sth.ID, false, things.ErrNotFound},
+		{"disconnect non-existing thing", token, sch.ID, wrong, false, things.ErrNotFound},
+		{"disconnect thing of other user", token, sch.ID, oth.ID, false, things.ErrUnauthorizedAccess},
+		{"disconnect thing from channel of other user", token, och.ID, sth.ID, false, things.ErrUnauthorizedAccess},
+		{"disconnect thing of other user from its channel", token, och.ID, oth.ID, false, things.ErrUnauthorizedAccess},
+	}
+
+	for _, tc := range cases {
+		removed, err := svc.Disconnect(context.Background(), tc.key, tc.chanID, tc.thingID)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		assert.Equal(t, tc.removed, removed, fmt.Sprintf("%s: expected removed %t got %t\n", tc.desc, tc.removed, removed))
+	}
+}
+
+func TestThingChannelIDs(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	ach, _ := svc.CreateChannel(context.Background(), token, channel)
+	svc.CreateChannel(context.Background(), token, channel)
+	bch, _ := svc.CreateChannel(context.Background(), token, channel)
+	svc.Connect(context.Background(), token, bch.ID, sth.ID, things.AccessPubSub)
+	svc.Connect(context.Background(), token, ach.ID, sth.ID, things.AccessPubSub)
+
+	other, _ := svc.AddThing(context.Background(), token, thing)
+
+	cases := []struct {
+		desc    string
+		key     string
+		thingID string
+		ids     []string
+		err     error
+	}{
+		{"retrieve channels of connected thing", token, sth.ID, []string{ach.ID, bch.ID}, nil},
+		{"retrieve channels of non-connected thing", token, other.ID, []string{}, nil},
+		{"retrieve channels of non-existing thing", token, wrong, nil, things.ErrNotFound},
+		{"retrieve channels with wrong credentials", wrong, sth.ID, nil, things.ErrUnauthorizedAccess},
+	}
+
+	for _, tc := range cases {
+		ids, err := svc.ThingChannelIDs(context.Background(), tc.key, tc.thingID)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		assert.Equal(t, tc.ids, ids, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.ids, ids))
+	}
+}
+
+func TestIsConnected(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	other, _ := svc.CreateChannel(context.Background(), token, channel)
+	svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+
+	cases := []struct {
+		desc      string
+		key       string
+		chanID    string
+		thingID   string
+		connected bool
+		err       error
+	}{
+		{"check connected thing", token, sch.ID, sth.ID, true, nil},
+		{"check non-connected thing", token, other.ID, sth.ID, false, nil},
+		{"check non-existing channel", token, wrong, sth.ID, false, nil},
+		{"check non-existing thing", token, sch.ID, wrong, false, nil},
+		{"check connection with wrong credentials", wrong, sch.ID, sth.ID, false, things.ErrUnauthorizedAccess},
+	}
+
+	for _, tc := range cases {
+		connected, err := svc.IsConnected(context.Background(), tc.key, tc.chanID, tc.thingID)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		assert.Equal(t, tc.connected, connected, fmt.Sprintf("%s: expected %t got %t\n", tc.desc, tc.connected, connected))
+	}
+}
+
+func TestDisconnectMany(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	connected := []string{}
+	for i := 0; i < 2; i++ {
+		sch, _ := svc.CreateChannel(context.Background(), token, channel)
+		svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+		connected = append(connected, sch.ID)
+	}
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	notConnected := []string{sch.ID, wrong}
+
+	cases := []struct {
+		desc    string
+		key     string
+		thingID string
+		chanIDs []string
+		err     error
+	}{
+		{"disconnect thing with wrong credentials", wrong, sth.ID, connected, things.ErrUnauthorizedAccess},
+		{"disconnect non-existing thing", token, wrong, connected, things.ErrNotFound},
+		{
+			"disconnect thing from connected and non-connected channels",
+			token,
+			sth.ID,
+			[]string{connected[0], notConnected[0], connected[1], notConnected[1]},
+			things.NotConnectedError{ChanIDs: notConnected},
+		},
+		{"disconnect thing from already disconnected channels", token, sth.ID, connected, things.NotConnectedError{ChanIDs: connected}},
+	}
+
+	for _, tc := range cases {
+		err := svc.DisconnectMany(context.Background(), tc.key, tc.thingID, tc.chanIDs)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+
+	for _, id := range connected {
+		_, err := svc.CanAccess(context.Background(), sth.Key, id, things.AccessPubSub)
+		assert.Equal(t, things.ErrUnauthorizedAccess, err, fmt.Sprintf("check disconnected thing: expected %s got %s\n", things.ErrUnauthorizedAccess, err))
+	}
+}
+
+func TestDisconnectAll(t *testing.T) {
+	users := mocks.NewUsersService(map[string]string{token: email})
+	thingsRepo := mocks.NewThingRepository()
+	channelsRepo := mocks.NewChannelRepository(thingsRepo)
+	svc := things.New(users, thingsRepo, channelsRepo, mocks.NewIdentityProvider())
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	other, _ := svc.AddThing(context.Background(), token, thing)
+	chs := []things.Channel{}
+	for i := 0; i < 3; i++ {
+		sch, _ := svc.CreateChannel(context.Background(), token, channel)
+		svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+		svc.Connect(context.Background(), token, sch.ID, other.ID, things.AccessPubSub)
+		chs = append(chs, sch)
+	}
+
+	cases := map[string]struct {
+		key     string
+		thingID string
+		err     error
+	}{
+		"disconnect thing with wrong credentials": {wrong, sth.ID, things.ErrUnauthorizedAccess},
+		"disconnect non-existing thing":           {token, wrong, things.ErrNotFound},
+	}
+
+	for desc, tc := range cases {
+		err := svc.DisconnectAll(context.Background(), tc.key, tc.thingID)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+
+	err := svc.DisconnectAll(context.Background(), token, sth.ID)
+	assert.Nil(t, err, fmt.Sprintf("disconnect thing from all channels: unexpected error %s\n", err))
+
+	for _, ch := range chs {
+		_, err := channelsRepo.HasThing(context.Background(), ch.ID, sth.Key, things.AccessPubSub)
+		assert.Equal(t, things.ErrNotFound, err, fmt.Sprintf("check disconnected thing: expected %s got %s\n", things.ErrNotFound, err))
+
+		id, err := channelsRepo.HasThing(context.Background(), ch.ID, other.Key, things.AccessPubSub)
+		assert.Nil(t, err, fmt.Sprintf("check other connected thing: unexpected error %s\n", err))
+		assert.Equal(t, other.ID, id, fmt.Sprintf("check other connected thing: expected %s got %s\n", other.ID, id))
+	}
+}
+
+func TestRebindConnections(t *testing.T) {
+	users := mocks.NewUsersService(map[string]string{token: email})
+	thingsRepo := mocks.NewThingRepository()
+	channelsRepo := mocks.NewChannelRepository(thingsRepo)
+	svc := things.New(users, thingsRepo, channelsRepo, mocks.NewIdentityProvider())
+
+	tha, _ := svc.AddThing(context.Background(), token, thing)
+	thb, _ := svc.AddThing(context.Background(), token, thing)
+	chs := []things.Channel{}
+	for i := 0; i < 3; i++ {
+		sch, _ := svc.CreateChannel(context.Background(), token, channel)
+		svc.Connect(context.Background(), token, sch.ID, tha.ID, things.AccessPubSub)
+		chs = append(chs, sch)
+	}
+	svc.Connect(context.Background(), token, chs[0].ID, thb.ID, things.AccessPubSub)
+
+	cases := map[string]struct {
+		key    string
+		fromID string
+		toID   string
+		err    error
+	}{
+		"rebind connections with wrong credentials": {wrong, tha.ID, thb.ID, things.ErrUnauthorizedAccess},
+		"rebind connections of non-existing thing":  {token, wrong, thb.ID, things.ErrNotFound},
+		"rebind connections to non-existing thing":  {token, tha.ID, wrong, things.ErrNotFound},
+		"rebind connections of thing to itself":     {token, tha.ID, tha.ID, things.ErrMalformedEntity},
+	}
+
+	for desc, tc := range cases {
+		err := svc.RebindConnections(context.Background(), tc.key, tc.fromID, tc.toID)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+
+	err := svc.RebindConnections(context.Background(), token, tha.ID, thb.ID)
+	assert.Nil(t, err, fmt.Sprintf("rebind connections: unexpected error %s\n", err))
+
+	for _, ch := range chs {
+		_, err := channelsRepo.HasThing(context.Background(), ch.ID, tha.Key, things.AccessPubSub)
+		assert.Equal(t, things.ErrNotFound, err, fmt.Sprintf("check source thing: expected %s got %s\n", things.ErrNotFound, err))
+
+		id, err := channelsRepo.HasThing(context.Background(), ch.ID, thb.Key, things.AccessPubSub)
+		assert.Nil(t, err, fmt.Sprintf("check target thing: unexpected error %s\n", err))
+		assert.Equal(t, thb.ID, id, fmt.Sprintf("check target thing: expected %s got %s\n", thb.ID, id))
+	}
+
+	count, err := svc.ChannelConnectionsCount(context.Background(), token, chs[0].ID)
+	assert.Nil(t, err, fmt.Sprintf("count connected things: unexpected error %s\n", err))
+	assert.Equal(t, 1, count, fmt.Sprintf("count connected things: expected %d got %d\n", 1, count))
+
+	ids, err := svc.ThingChannelIDs(context.Background(), token, tha.ID)
+	assert.Nil(t, err, fmt.Sprintf("list source thing's channels: unexpected error %s\n", err))
+	assert.Empty(t, ids, fmt.Sprintf("list source thing's channels: expected none got %v\n", ids))
+}
+
+func TestDisconnectKeepsConnectedThings(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	connected := []things.Thing{}
+	for i := 0; i < 3; i++ {
+		sth, _ := svc.AddThing(context.Background(), token, thing)
+		svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+		connected = append(connected, sth)
+	}
+
+	_, err := svc.Disconnect(context.Background(), token, sch.ID, connected[1].ID)
+	assert.Nil(t, err, fmt.Sprintf("disconnect middle thing: unexpected error %s\n", err))
+
+	ch, _ := svc.ViewChannel(context.Background(), token, sch.ID)
+	assert.Len(t, ch.Things, 2, fmt.Sprintf("disconnect middle thing: expected %d things got %d\n", 2, len(ch.Things)))
+	for _, th := range ch.Things {
+		assert.NotEmpty(t, th.ID, "disconnect middle thing: unexpected thing with empty ID\n")
+	}
+	assert.Equal(t, []things.Thing{connected[0], connected[2]}, ch.Things, fmt.Sprintf("disconnect middle thing: expected %v got %v\n", []things.Thing{connected[0], connected[2]}, ch.Things))
+}
+
+func TestCanAccess(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	pth, _ := svc.AddThing(context.Background(), token, thing)
+	sbth, _ := svc.AddThing(context.Background(), token, thing)
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+	svc.Connect(context.Background(), token, sch.ID, pth.ID, things.AccessPub)
+	svc.Connect(context.Background(), token, sch.ID, sbth.ID, things.AccessSub)
+
+	cases := map[string]struct {
+		key     string
+		channel string
+		mode    things.AccessMode
+		err     error
+	}{
+		"allowed access":                      {sth.Key, sch.ID, things.AccessPubSub, nil},
+		"not-connected cannot access":         {"", sch.ID, things.AccessPubSub, things.ErrUnauthorizedAccess},
+		"access non-existing channel":         {sth.Key, wrong, things.AccessPubSub, things.ErrUnauthorizedAccess},
+		"publish using pub-sub connection":    {sth.Key, sch.ID, things.AccessPub, nil},
+		"subscribe using pub-sub connection":  {sth.Key, sch.ID, things.AccessSub, nil},
+		"publish using pub-only connection":   {pth.Key, sch.ID, things.AccessPub, nil},
+		"subscribe using pub-only connection": {pth.Key, sch.ID, things.AccessSub, things.ErrUnauthorizedAccess},
+		"pub-sub using pub-only connection":   {pth.Key, sch.ID, things.AccessPubSub, things.ErrUnauthorizedAccess},
+		"subscribe using sub-only connection": {sbth.Key, sch.ID, things.AccessSub, nil},
+		"publish using sub-only connection":   {sbth.Key, sch.ID, things.AccessPub, things.ErrUnauthorizedAccess},
+		"access with invalid mode":            {sth.Key, sch.ID, things.AccessMode("invalid"), things.ErrMalformedEntity},
+	}
+
+	for desc, tc := range cases {
+		_, err := svc.CanAccess(context.Background(), tc.key, tc.channel, tc.mode)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+}
+
+func TestConnectMode(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+
+	_, err := svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessMode("invalid"))
+	assert.Equal(t, things.ErrMalformedEntity, err, fmt.Sprintf("connect with invalid mode: expected %s got %s\n", things.ErrMalformedEntity, err))
+
+	conn, err := svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPub)
+	assert.Nil(t, err, fmt.Sprintf("connect with pub mode: unexpected error %s\n", err))
+	assert.Equal(t, things.AccessPub, conn.Mode, fmt.Sprintf("connect with pub mode: expected mode %s got %s\n", things.AccessPub, conn.Mode))
+
+	_, err = svc.CanAccess(context.Background(), sth.Key, sch.ID, things.AccessSub)
+	assert.Equal(t, things.ErrUnauthorizedAccess, err, fmt.Sprintf("subscribe using pub-only connection: expected %s got %s\n", things.ErrUnauthorizedAccess, err))
+
+	// reconnecting replaces the mode, but keeps the time of the connection
+	reconn, err := svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessSub)
+	assert.Nil(t, err, fmt.Sprintf("reconnect with sub mode: unexpected error %s\n", err))
+	assert.Equal(t, things.AccessSub, reconn.Mode, fmt.Sprintf("reconnect with sub mode: expected mode %s got %s\n", things.AccessSub, reconn.Mode))
+	assert.Equal(t, conn.ConnectedAt, reconn.ConnectedAt, fmt.Sprintf("reconnect with sub mode: expected connection time %s got %s\n", conn.ConnectedAt, reconn.ConnectedAt))
+
+	_, err = svc.CanAccess(context.Background(), sth.Key, sch.ID, things.AccessSub)
+	assert.Nil(t, err, fmt.Sprintf("subscribe using sub-only connection: unexpected error %s\n", err))
+
+	conns, err := svc.ChannelConnections(context.Background(), token, sch.ID)
+	assert.Nil(t, err, fmt.Sprintf("channel connections: unexpected error %s\n", err))
+	assert.Equal(t, 1, len(conns), fmt.Sprintf("channel connections: expected 1 connection got %d\n", len(conns)))
+	assert.Equal(t, things.AccessSub, conns[0].Mode, fmt.Sprintf("channel connections: expected mode %s got %s\n", things.AccessSub, conns[0].Mode))
+}
+
+func TestCanAccessBatch(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	oth, _ := svc.AddThing(context.Background(), token, thing)
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+
+	reqs := []things.AccessRequest{
+		{ChanID: sch.ID, Key: sth.Key, Mode: things.AccessPubSub},
+		{ChanID: sch.ID, Key: oth.Key, Mode: things.AccessPubSub},
+		{ChanID: wrong, Key: sth.Key, Mode: things.AccessPubSub},
+		{ChanID: sch.ID, Key: "", Mode: things.AccessPubSub},
+		{ChanID: sch.ID, Key: sth.Key, Mode: things.AccessPubSub},
+	}
+	expected := []things.AccessResult{
+		{ThingID: sth.ID, Err: nil},
+		{ThingID: "", Err: things.ErrUnauthorizedAccess},
+		{ThingID: "", Err: things.ErrUnauthorizedAccess},
+		{ThingID: "", Err: things.ErrUnauthorizedAccess},
+		{ThingID: sth.ID, Err: nil},
+	}
+
+	results, err := svc.CanAccessBatch(context.Background(), reqs)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	assert.Equal(t, expected, results, fmt.Sprintf("expected %v got %v\n", expected, results))
+
+	tooLarge := make([]things.AccessRequest, things.MaxAccessBatchSize+1)
+	_, err = svc.CanAccessBatch(context.Background(), tooLarge)
+	assert.Equal(t, things.ErrMalformedEntity, err, fmt.Sprintf("too large batch: expected %s got %s\n", things.ErrMalformedEntity, err))
+}
+
+func TestChannelOwner(t *testing.T) {
+	serviceKey := "service-key"
+	users := mocks.NewUsersService(map[string]string{token: email})
+	thingsRepo := mocks.NewThingRepository()
+	channelsRepo := mocks.NewChannelRepository(thingsRepo)
+	idp := mocks.NewIdentityProvider()
+	svc := things.New(users, thingsRepo, channelsRepo, idp, things.WithServiceKey(serviceKey))
+
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+
+	cases := []struct {
+		desc   string
+		key    string
+		chanID string
+		owner  string
+		err    error
+	}{
+		{"retrieve owner of existing channel", serviceKey, sch.ID, email, nil},
+		{"retrieve owner of non-existing channel", serviceKey, wrong, "", things.ErrNotFound},
+		{"retrieve owner with user's key", token, sch.ID, "", things.ErrUnauthorizedAccess},
+		{"retrieve owner with empty key", "", sch.ID, "", things.ErrUnauthorizedAccess},
+	}
+
+	for _, tc := range cases {
+		owner, err := svc.ChannelOwner(context.Background(), tc.key, tc.chanID)
+		assert.Equal(t, tc.owner, owner, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.owner, owner))
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+
+	unconfigured := newService(map[string]string{token: email})
+	_, err := unconfigured.ChannelOwner(context.Background(), "", sch.ID)
+	assert.Equal(t, things.ErrUnauthorizedAccess, err, fmt.Sprintf("service without key: expected %s got %s\n", things.ErrUnauthorizedAccess, err))
+}
+
+func TestPurgeDeleted(t *testing.T) {
+	serviceKey := "service-key"
+	users := mocks.NewUsersService(map[string]string{token: email})
+	thingsRepo := mocks.NewThingRepository()
+	channelsRepo := mocks.NewChannelRepository(thingsRepo)
+	idp := mocks.NewIdentityProvider()
+	svc := things.New(users, thingsRepo, channelsRepo, idp, things.WithServiceKey(serviceKey))
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	kept, _ := svc.AddThing(context.Background(), token, thing)
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	svc.RemoveThing(context.Background(), token, sth.ID)
+	svc.RemoveChannel(context.Background(), token, sch.ID)
+
+	retention := time.Hour
+	clock := time.Now().UTC()
+
+	_, err := svc.PurgeDeleted(context.Background(), token, clock.Add(-retention))
+	assert.Equal(t, things.ErrUnauthorizedAccess, err, fmt.Sprintf("purge with user's key: expected %s got %s\n", things.ErrUnauthorizedAccess, err))
+
+	purged, err := svc.PurgeDeleted(context.Background(), serviceKey, clock.Add(-retention))
+	assert.Nil(t, err, fmt.Sprintf("purge within retention: unexpected error %s\n", err))
+	assert.Equal(t, 0, purged, fmt.Sprintf("purge within retention: expected %d purged got %d\n", 0, purged))
+	page := thingsRepo.AllDeleted(context.Background(), email, 0, 10, things.Sorting{})
+	assert.Equal(t, 1, page.Total, fmt.Sprintf("purge within retention: expected %d removed things got %d\n", 1, page.Total))
+
+	clock = clock.Add(2 * retention)
+	purged, err = svc.PurgeDeleted(context.Background(), serviceKey, clock.Add(-retention))
+	assert.Nil(t, err, fmt.Sprintf("purge after retention: unexpected error %s\n", err))
+	assert.Equal(t, 2, purged, fmt.Sprintf("purge after retention: expected %d purged got %d\n", 2, purged))
+
+	page = thingsRepo.AllDeleted(context.Background(), email, 0, 10, things.Sorting{})
+	assert.Equal(t, 0, page.Total, fmt.Sprintf("purge after retention: expected %d removed things got %d\n", 0, page.Total))
+	err = svc.RestoreThing(context.Background(), token, sth.ID)
+	assert.Equal(t, things.ErrNotFound, err, fmt.Sprintf("restore purged thing: expected %s got %s\n", things.ErrNotFound, err))
+	err = svc.RestoreChannel(context.Background(), token, sch.ID)
+	assert.Equal(t, things.ErrNotFound, err, fmt.Sprintf("restore purged channel: expected %s got %s\n", things.ErrNotFound, err))
+	_, err = svc.ViewThing(context.Background(), token, kept.ID)
+	assert.Nil(t, err, fmt.Sprintf("view kept thing: unexpected error %s\n", err))
+}
+
+func TestHealth(t *testing.T) {
+	thingsRepo := mocks.NewThingRepository()
+	channelsRepo := mocks.NewChannelRepository(thingsRepo)
+	idp := mocks.NewIdentityProvider()
+
+	users := mocks.NewUsersService(map[string]string{token: email})
+
+	cases := map[string]struct {
+		users    mainflux.UsersServiceClient
+		things   things.ThingRepository
+		channels things.ChannelRepository
+		err      error
 	}{
-		"allowed access":              {sth.Key, sch.ID, nil},
-		"not-connected cannot access": {"", sch.ID, things.ErrUnauthorizedAccess},
-		"access non-existing channel": {sth.Key, wrong, things.ErrUnauthorizedAccess},
+		"check health with reachable users service":      {users, thingsRepo, channelsRepo, nil},
+		"check health with unreachable users service":    {mocks.NewUnavailableUsersService(), thingsRepo, channelsRepo, things.ErrUnavailable},
+		"check health with unreachable things storage":   {users, mocks.NewUnavailableThingRepository(thingsRepo), channelsRepo, things.ErrUnavailable},
+		"check health with unreachable channels storage": {users, thingsRepo, mocks.NewUnavailableChannelRepository(channelsRepo), things.ErrUnavailable},
 	}
 
 	for desc, tc := range cases {
-		_, err := svc.CanAccess(tc.key, tc.channel)
+		svc := things.New(tc.users, tc.things, tc.channels, idp)
+		err := svc.Health(context.Background())
 		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
 	}
 }
@@ -0,0 +1,43 @@
+package things
+
+const (
+	// OrderID sorts retrieved entities by their identifiers.
+	OrderID = "id"
+
+	// OrderName sorts retrieved entities by their names.
+	OrderName = "name"
+
+	// OrderCreated sorts retrieved entities by their creation time.
+	OrderCreated = "created"
+
+	// DirAsc sorts retrieved entities in ascending order.
+	DirAsc = "asc"
+
+	// DirDesc sorts retrieved entities in descending order.
+	DirDesc = "desc"
+)
+
+// Sorting specifies the order in which the subset of things or channels is
+// retrieved. Zero value sorts entities by their identifiers, in ascending
+// order.
+type Sorting struct {
+	Order string
+	Dir   string
+}
+
+// Validate returns an error if sorting specification is invalid.
+func (s Sorting) Validate() error {
+	switch s.Order {
+	case "", OrderID, OrderName, OrderCreated:
+	default:
+		return ErrMalformedEntity
+	}
+
+	switch s.Dir {
+	case "", DirAsc, DirDesc:
+	default:
+		return ErrMalformedEntity
+	}
+
+	return nil
+}
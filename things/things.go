@@ -1,18 +1,56 @@
 package things
 
-import "strings"
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxNameLength is the maximum length of thing's and channel's name.
+const MaxNameLength = 1024
+
+// KeyLength is the length of thing's access key, matching the length of
+// the generated keys.
+const KeyLength = 36
 
 // Thing represents a Mainflux thing. Each thing is owned by one user, and
 // it is assigned with the unique identifier and (temporary) access key.
 type Thing struct {
-	ID      string `json:"id"`
-	Owner   string `json:"-"`
-	Type    string `json:"type"`
-	Name    string `json:"name,omitempty"`
-	Key     string `json:"key"`
-	Payload string `json:"payload,omitempty"`
+	ID         string                 `json:"id"`
+	ExternalID string                 `json:"external_id,omitempty"`
+	Owner      string                 `json:"-"`
+	Type       string                 `json:"type"`
+	Name       string                 `json:"name,omitempty"`
+	Key        string                 `json:"key"`
+	Payload    string                 `json:"payload,omitempty"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+	Tags       []string               `json:"tags,omitempty"`
+	Status     string                 `json:"status,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+	UpdatedAt  time.Time              `json:"updated_at"`
+	Version    uint64                 `json:"version"`
+	DeletedAt  time.Time              `json:"-"`
+
+	// Existing marks the thing that was already created by its owner with
+	// the same external identifier. It is never persisted.
+	Existing bool `json:"-"`
 }
 
+// Deleted determines whether the thing is removed.
+func (t Thing) Deleted() bool {
+	return !t.DeletedAt.IsZero()
+}
+
+const (
+	// StatusEnabled marks the thing that is allowed to access its channels.
+	StatusEnabled = "enabled"
+
+	// StatusDisabled marks the thing that is temporarily denied access to
+	// its channels.
+	StatusDisabled = "disabled"
+)
+
 var thingTypes = map[string]bool{
 	"app":    true,
 	"device": true,
@@ -24,27 +62,168 @@ func (c *Thing) Validate() error {
 		return ErrMalformedEntity
 	}
 
+	for _, tag := range c.Tags {
+		if err := validateName(tag); err != nil {
+			return err
+		}
+	}
+
+	return validateName(c.Name)
+}
+
+func validateKey(key string) error {
+	if len(key) != KeyLength {
+		return ErrMalformedEntity
+	}
+
+	return nil
+}
+
+func validateName(name string) error {
+	if name == "" || utf8.RuneCountInString(name) > MaxNameLength {
+		return ErrMalformedEntity
+	}
+
+	return nil
+}
+
+// ThingFilter restricts the retrieved things to the ones matching all of
+// the specified criteria. Name is matched case insensitively as a substring,
+// while the remaining fields have to match exactly. Zero value matches all
+// things.
+type ThingFilter struct {
+	Name     string
+	Type     string
+	Status   string
+	Tag      string
+	Metadata MetadataFilter
+}
+
+func (f ThingFilter) validate() error {
+	if f.Type != "" && !thingTypes[f.Type] {
+		return ErrMalformedEntity
+	}
+
+	if f.Status != "" && f.Status != StatusEnabled && f.Status != StatusDisabled {
+		return ErrMalformedEntity
+	}
+
 	return nil
 }
 
+// ThingPage contains a subset of things owned by the user, along with the
+// total number of things the user owns.
+type ThingPage struct {
+	Things []Thing
+	Total  int
+	Offset int
+	Limit  int
+}
+
 // ThingRepository specifies a thing persistence API.
 type ThingRepository interface {
 	// Save persists the thing. Successful operation is indicated by non-nil
 	// error response.
-	Save(Thing) (string, error)
+	Save(context.Context, Thing) (string, error)
+
+	// SaveBulk persists all of the provided things at once. Either all things
+	// are saved and their identifiers returned, or none of them is saved and
+	// a non-nil error is returned.
+	SaveBulk(context.Context, []Thing) ([]string, error)
+
+	// Update performs an update to the existing thing. Unless the provided
+	// thing's version is zero, it must match the stored one, otherwise
+	// ErrVersionMismatch is returned. A non-nil error is returned to
+	// indicate operation failure.
+	Update(context.Context, Thing) error
 
-	// Update performs an update to the existing thing. A non-nil error is
-	// returned to indicate operation failure.
-	Update(Thing) error
+	// UpdateKey replaces the access key of the thing having the provided
+	// identifier, that is owned by the specified user. ErrConflict is
+	// returned if the key is already used by another thing.
+	UpdateKey(context.Context, string, string, string) error
+
+	// UpdateStatus changes the status of the thing having the provided
+	// identifier, that is owned by the specified user.
+	UpdateStatus(context.Context, string, string, string) error
 
 	// One retrieves the thing having the provided identifier, that is owned
-	// by the specified user.
-	One(string, string) (Thing, error)
+	// by the specified user. Removed things are not retrieved.
+	One(context.Context, string, string) (Thing, error)
+
+	// Owner retrieves the owner of the thing having the provided identifier,
+	// regardless of the user that owns it. Removed things are not retrieved.
+	Owner(context.Context, string) (string, error)
+
+	// ByKey retrieves the thing having the provided access key. Removed
+	// things are not retrieved.
+	ByKey(context.Context, string) (Thing, error)
+
+	// ByExternalID retrieves the thing having the provided external
+	// identifier, that is owned by the specified user. Removed things are not
+	// retrieved.
+	ByExternalID(context.Context, string, string) (Thing, error)
+
+	// All retrieves the subset of things owned by the specified user, that
+	// match the provided filter, sorted as specified. The returned page also
+	// reports the total number of things the user owns that match the filter.
+	// Removed things are not retrieved.
+	All(context.Context, string, int, int, Sorting, ThingFilter) ThingPage
+
+	// AllDeleted retrieves the subset of removed things owned by the
+	// specified user, sorted as specified. The returned page also reports the
+	// total number of removed things the user owns.
+	AllDeleted(context.Context, string, int, int, Sorting) ThingPage
+
+	// Multi retrieves the things owned by the specified user, having the
+	// provided identifiers, in no particular order. Unknown identifiers are
+	// skipped. Removed things are not retrieved.
+	Multi(context.Context, string, []string) []Thing
+
+	// After retrieves at most the specified number of things owned by the
+	// specified user, whose identifiers follow the provided one, sorted by
+	// their identifiers. Removed things are not retrieved.
+	After(context.Context, string, string, int) []Thing
+
+	// Count retrieves the number of things owned by the specified user,
+	// that match the provided filter. Removed things are not counted.
+	Count(context.Context, string, ThingFilter) int
+
+	// Search retrieves the subset of things owned by the specified user,
+	// whose names contain the provided value. Matching is case insensitive.
+	// Exact name matches are ranked first, followed by prefix matches and
+	// then by the remaining ones; each group is sorted by name.
+	Search(context.Context, string, string, int, int) []Thing
+
+	// AllByMetadata retrieves the subset of things owned by the specified
+	// user, whose metadata contain the provided key set to the provided value.
+	AllByMetadata(context.Context, string, string, string, int, int) []Thing
+
+	// ChangeOwner transfers the thing having the provided identifier, that
+	// is owned by the specified user, to the new owner. The thing is
+	// disconnected from all of the channels along with the transfer.
+	// ErrConflict is returned if the new owner already has the thing with
+	// the same identifier or external identifier, in which case the thing
+	// keeps its connections.
+	ChangeOwner(context.Context, string, string, string) error
+
+	// Remove marks the thing having the provided identifier, that is owned
+	// by the specified user, as removed at the current time. Removed thing
+	// can be restored.
+	Remove(context.Context, string, string) error
+
+	// RemoveAll marks all of the things owned by the specified user as
+	// removed.
+	RemoveAll(context.Context, string) error
+
+	// Restore restores the removed thing having the provided identifier, that
+	// is owned by the specified user. ErrConflict is returned if its external
+	// ID was taken by another thing in the meantime.
+	Restore(context.Context, string, string) error
 
-	// All retrieves the subset of things owned by the specified user.
-	All(string, int, int) []Thing
+	// Purge permanently removes the things of all of the users, that were
+	// removed before the provided time, and returns their number.
+	Purge(context.Context, time.Time) (int, error)
 
-	// Remove removes the thing having the provided identifier, that is owned
-	// by the specified user.
-	Remove(string, string) error
+	// Ping verifies that the underlying storage is reachable.
+	Ping(context.Context) error
 }
@@ -0,0 +1,246 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/mainflux/mainflux/things"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var _ things.ChannelRepository = (*channelRepositoryMiddleware)(nil)
+
+type channelRepositoryMiddleware struct {
+	tracer opentracing.Tracer
+	repo   things.ChannelRepository
+}
+
+// ChannelRepositoryMiddleware traces the channel repository operations.
+func ChannelRepositoryMiddleware(tracer opentracing.Tracer, repo things.ChannelRepository) things.ChannelRepository {
+	return &channelRepositoryMiddleware{
+		tracer: tracer,
+		repo:   repo,
+	}
+}
+
+func (crm *channelRepositoryMiddleware) Save(ctx context.Context, channel things.Channel) (string, error) {
+	span, ctx := StartSpan(ctx, crm.tracer, "channel_repository.save")
+	span.SetTag("channel_id", channel.ID)
+	defer span.Finish()
+
+	return crm.repo.Save(ctx, channel)
+}
+
+func (crm *channelRepositoryMiddleware) Update(ctx context.Context, channel things.Channel) error {
+	span, ctx := StartSpan(ctx, crm.tracer, "channel_repository.update")
+	span.SetTag("channel_id", channel.ID)
+	defer span.Finish()
+
+	return crm.repo.Update(ctx, channel)
+}
+
+func (crm *channelRepositoryMiddleware) One(ctx context.Context, owner, id string) (things.Channel, error) {
+	span, ctx := StartSpan(ctx, crm.tracer, "channel_repository.one")
+	span.SetTag("channel_id", id)
+	defer span.Finish()
+
+	return crm.repo.One(ctx, owner, id)
+}
+
+func (crm *channelRepositoryMiddleware) ByName(ctx context.Context, owner, name string) (things.Channel, error) {
+	span, ctx := StartSpan(ctx, crm.tracer, "channel_repository.by_name")
+	defer span.Finish()
+
+	return crm.repo.ByName(ctx, owner, name)
+}
+
+func (crm *channelRepositoryMiddleware) All(ctx context.Context, owner string, offset, limit int, sorting things.Sorting, filter things.MetadataFilter) things.ChannelPage {
+	span, ctx := StartSpan(ctx, crm.tracer, "channel_repository.all")
+	defer span.Finish()
+
+	return crm.repo.All(ctx, owner, offset, limit, sorting, filter)
+}
+
+func (crm *channelRepositoryMiddleware) AllByThing(ctx context.Context, owner, thingID string, offset, limit int) []things.Channel {
+	span, ctx := StartSpan(ctx, crm.tracer, "channel_repository.all_by_thing")
+	span.SetTag("thing_id", thingID)
+	defer span.Finish()
+
+	return crm.repo.AllByThing(ctx, owner, thingID, offset, limit)
+}
+
+func (crm *channelRepositoryMiddleware) Things(ctx context.Context, owner, chanID string, offset, limit int, filter things.ConnectionFilter) []things.Thing {
+	span, ctx := StartSpan(ctx, crm.tracer, "channel_repository.things")
+	span.SetTag("channel_id", chanID)
+	defer span.Finish()
+
+	return crm.repo.Things(ctx, owner, chanID, offset, limit, filter)
+}
+
+func (crm *channelRepositoryMiddleware) UnconnectedThings(ctx context.Context, owner string, offset, limit int) []things.Thing {
+	span, ctx := StartSpan(ctx, crm.tracer, "channel_repository.unconnected_things")
+	defer span.Finish()
+
+	return crm.repo.UnconnectedThings(ctx, owner, offset, limit)
+}
+
+func (crm *channelRepositoryMiddleware) Count(ctx context.Context, owner string) int {
+	span, ctx := StartSpan(ctx, crm.tracer, "channel_repository.count")
+	defer span.Finish()
+
+	return crm.repo.Count(ctx, owner)
+}
+
+func (crm *channelRepositoryMiddleware) CountThings(ctx context.Context, owner, chanID string) (int, error) {
+	span, ctx := StartSpan(ctx, crm.tracer, "channel_repository.count_things")
+	span.SetTag("channel_id", chanID)
+	defer span.Finish()
+
+	return crm.repo.CountThings(ctx, owner, chanID)
+}
+
+func (crm *channelRepositoryMiddleware) ChangeOwner(ctx context.Context, owner, id, newOwner string) error {
+	span, ctx := StartSpan(ctx, crm.tracer, "channel_repository.change_owner")
+	span.SetTag("channel_id", id)
+	defer span.Finish()
+
+	return crm.repo.ChangeOwner(ctx, owner, id, newOwner)
+}
+
+func (crm *channelRepositoryMiddleware) Remove(ctx context.Context, owner, id string) error {
+	span, ctx := StartSpan(ctx, crm.tracer, "channel_repository.remove")
+	span.SetTag("channel_id", id)
+	defer span.Finish()
+
+	return crm.repo.Remove(ctx, owner, id)
+}
+
+func (crm *channelRepositoryMiddleware) Restore(ctx context.Context, owner, id string) error {
+	span, ctx := StartSpan(ctx, crm.tracer, "channel_repository.restore")
+	span.SetTag("channel_id", id)
+	defer span.Finish()
+
+	return crm.repo.Restore(ctx, owner, id)
+}
+
+func (crm *channelRepositoryMiddleware) Purge(ctx context.Context, olderThan time.Time) (int, error) {
+	span, ctx := StartSpan(ctx, crm.tracer, "channel_repository.purge")
+	defer span.Finish()
+
+	return crm.repo.Purge(ctx, olderThan)
+}
+
+func (crm *channelRepositoryMiddleware) RemoveAll(ctx context.Context, owner string) error {
+	span, ctx := StartSpan(ctx, crm.tracer, "channel_repository.remove_all")
+	defer span.Finish()
+
+	return crm.repo.RemoveAll(ctx, owner)
+}
+
+func (crm *channelRepositoryMiddleware) Connect(ctx context.Context, owner, chanID, thingID string, mode things.AccessMode) (things.Connection, error) {
+	span, ctx := StartSpan(ctx, crm.tracer, "channel_repository.connect")
+	span.SetTag("channel_id", chanID)
+	span.SetTag("thing_id", thingID)
+	span.SetTag("mode", string(mode))
+	defer span.Finish()
+
+	return crm.repo.Connect(ctx, owner, chanID, thingID, mode)
+}
+
+func (crm *channelRepositoryMiddleware) ConnectMany(ctx context.Context, owner, thingID string, chanIDs []string) error {
+	span, ctx := StartSpan(ctx, crm.tracer, "channel_repository.connect_many")
+	span.SetTag("thing_id", thingID)
+	defer span.Finish()
+
+	return crm.repo.ConnectMany(ctx, owner, thingID, chanIDs)
+}
+
+func (crm *channelRepositoryMiddleware) ConnectThings(ctx context.Context, owner, chanID string, thingIDs []string) error {
+	span, ctx := StartSpan(ctx, crm.tracer, "channel_repository.connect_things")
+	span.SetTag("channel_id", chanID)
+	defer span.Finish()
+
+	return crm.repo.ConnectThings(ctx, owner, chanID, thingIDs)
+}
+
+func (crm *channelRepositoryMiddleware) Disconnect(ctx context.Context, owner, chanID, thingID string) (bool, error) {
+	span, ctx := StartSpan(ctx, crm.tracer, "channel_repository.disconnect")
+	span.SetTag("channel_id", chanID)
+	span.SetTag("thing_id", thingID)
+	defer span.Finish()
+
+	return crm.repo.Disconnect(ctx, owner, chanID, thingID)
+}
+
+func (crm *channelRepositoryMiddleware) DisconnectAll(ctx context.Context, owner, thingID string) error {
+	span, ctx := StartSpan(ctx, crm.tracer, "channel_repository.disconnect_all")
+	span.SetTag("thing_id", thingID)
+	defer span.Finish()
+
+	return crm.repo.DisconnectAll(ctx, owner, thingID)
+}
+
+func (crm *channelRepositoryMiddleware) Rebind(ctx context.Context, owner, fromID, toID string) error {
+	span, ctx := StartSpan(ctx, crm.tracer, "channel_repository.rebind")
+	span.SetTag("from_thing_id", fromID)
+	span.SetTag("to_thing_id", toID)
+	defer span.Finish()
+
+	return crm.repo.Rebind(ctx, owner, fromID, toID)
+}
+
+func (crm *channelRepositoryMiddleware) DisconnectAllThings(ctx context.Context, owner string) error {
+	span, ctx := StartSpan(ctx, crm.tracer, "channel_repository.disconnect_all_things")
+	defer span.Finish()
+
+	return crm.repo.DisconnectAllThings(ctx, owner)
+}
+
+func (crm *channelRepositoryMiddleware) HasConnection(ctx context.Context, owner, chanID, thingID string) bool {
+	span, ctx := StartSpan(ctx, crm.tracer, "channel_repository.has_connection")
+	span.SetTag("channel_id", chanID)
+	span.SetTag("thing_id", thingID)
+	defer span.Finish()
+
+	return crm.repo.HasConnection(ctx, owner, chanID, thingID)
+}
+
+func (crm *channelRepositoryMiddleware) Connections(ctx context.Context, owner, thingID string) ([]string, error) {
+	span, ctx := StartSpan(ctx, crm.tracer, "channel_repository.connections")
+	span.SetTag("thing_id", thingID)
+	defer span.Finish()
+
+	return crm.repo.Connections(ctx, owner, thingID)
+}
+
+func (crm *channelRepositoryMiddleware) ChannelConnections(ctx context.Context, owner, chanID string) ([]things.Connection, error) {
+	span, ctx := StartSpan(ctx, crm.tracer, "channel_repository.channel_connections")
+	span.SetTag("channel_id", chanID)
+	defer span.Finish()
+
+	return crm.repo.ChannelConnections(ctx, owner, chanID)
+}
+
+func (crm *channelRepositoryMiddleware) HasThing(ctx context.Context, chanID, key string, mode things.AccessMode) (string, error) {
+	span, ctx := StartSpan(ctx, crm.tracer, "channel_repository.has_thing")
+	span.SetTag("channel_id", chanID)
+	span.SetTag("mode", string(mode))
+	defer span.Finish()
+
+	return crm.repo.HasThing(ctx, chanID, key, mode)
+}
+
+func (crm *channelRepositoryMiddleware) Owner(ctx context.Context, chanID string) (string, error) {
+	span, ctx := StartSpan(ctx, crm.tracer, "channel_repository.owner")
+	span.SetTag("channel_id", chanID)
+	defer span.Finish()
+
+	return crm.repo.Owner(ctx, chanID)
+}
+
+func (crm *channelRepositoryMiddleware) Ping(ctx context.Context) error {
+	span, ctx := StartSpan(ctx, crm.tracer, "channel_repository.ping")
+	defer span.Finish()
+
+	return crm.repo.Ping(ctx)
+}
@@ -0,0 +1,4 @@
+// Package tracing contains the decorators that trace the operations of the
+// things service's dependencies, i.e. its repositories and the users service
+// client, using OpenTracing.
+package tracing
@@ -0,0 +1,187 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/mainflux/mainflux/things"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var _ things.ThingRepository = (*thingRepositoryMiddleware)(nil)
+
+type thingRepositoryMiddleware struct {
+	tracer opentracing.Tracer
+	repo   things.ThingRepository
+}
+
+// ThingRepositoryMiddleware traces the thing repository operations.
+func ThingRepositoryMiddleware(tracer opentracing.Tracer, repo things.ThingRepository) things.ThingRepository {
+	return &thingRepositoryMiddleware{
+		tracer: tracer,
+		repo:   repo,
+	}
+}
+
+func (trm *thingRepositoryMiddleware) Save(ctx context.Context, thing things.Thing) (string, error) {
+	span, ctx := StartSpan(ctx, trm.tracer, "thing_repository.save")
+	span.SetTag("thing_id", thing.ID)
+	defer span.Finish()
+
+	return trm.repo.Save(ctx, thing)
+}
+
+func (trm *thingRepositoryMiddleware) SaveBulk(ctx context.Context, ths []things.Thing) ([]string, error) {
+	span, ctx := StartSpan(ctx, trm.tracer, "thing_repository.save_bulk")
+	defer span.Finish()
+
+	return trm.repo.SaveBulk(ctx, ths)
+}
+
+func (trm *thingRepositoryMiddleware) Update(ctx context.Context, thing things.Thing) error {
+	span, ctx := StartSpan(ctx, trm.tracer, "thing_repository.update")
+	span.SetTag("thing_id", thing.ID)
+	defer span.Finish()
+
+	return trm.repo.Update(ctx, thing)
+}
+
+func (trm *thingRepositoryMiddleware) UpdateKey(ctx context.Context, owner, id, key string) error {
+	span, ctx := StartSpan(ctx, trm.tracer, "thing_repository.update_key")
+	span.SetTag("thing_id", id)
+	defer span.Finish()
+
+	return trm.repo.UpdateKey(ctx, owner, id, key)
+}
+
+func (trm *thingRepositoryMiddleware) UpdateStatus(ctx context.Context, owner, id, status string) error {
+	span, ctx := StartSpan(ctx, trm.tracer, "thing_repository.update_status")
+	span.SetTag("thing_id", id)
+	defer span.Finish()
+
+	return trm.repo.UpdateStatus(ctx, owner, id, status)
+}
+
+func (trm *thingRepositoryMiddleware) One(ctx context.Context, owner, id string) (things.Thing, error) {
+	span, ctx := StartSpan(ctx, trm.tracer, "thing_repository.one")
+	span.SetTag("thing_id", id)
+	defer span.Finish()
+
+	return trm.repo.One(ctx, owner, id)
+}
+
+func (trm *thingRepositoryMiddleware) Owner(ctx context.Context, id string) (string, error) {
+	span, ctx := StartSpan(ctx, trm.tracer, "thing_repository.owner")
+	span.SetTag("thing_id", id)
+	defer span.Finish()
+
+	return trm.repo.Owner(ctx, id)
+}
+
+func (trm *thingRepositoryMiddleware) ByKey(ctx context.Context, key string) (things.Thing, error) {
+	span, ctx := StartSpan(ctx, trm.tracer, "thing_repository.by_key")
+	defer span.Finish()
+
+	return trm.repo.ByKey(ctx, key)
+}
+
+func (trm *thingRepositoryMiddleware) ByExternalID(ctx context.Context, owner, extID string) (things.Thing, error) {
+	span, ctx := StartSpan(ctx, trm.tracer, "thing_repository.by_external_id")
+	defer span.Finish()
+
+	return trm.repo.ByExternalID(ctx, owner, extID)
+}
+
+func (trm *thingRepositoryMiddleware) All(ctx context.Context, owner string, offset, limit int, sorting things.Sorting, filter things.ThingFilter) things.ThingPage {
+	span, ctx := StartSpan(ctx, trm.tracer, "thing_repository.all")
+	defer span.Finish()
+
+	return trm.repo.All(ctx, owner, offset, limit, sorting, filter)
+}
+
+func (trm *thingRepositoryMiddleware) AllDeleted(ctx context.Context, owner string, offset, limit int, sorting things.Sorting) things.ThingPage {
+	span, ctx := StartSpan(ctx, trm.tracer, "thing_repository.all_deleted")
+	defer span.Finish()
+
+	return trm.repo.AllDeleted(ctx, owner, offset, limit, sorting)
+}
+
+func (trm *thingRepositoryMiddleware) Count(ctx context.Context, owner string, filter things.ThingFilter) int {
+	span, ctx := StartSpan(ctx, trm.tracer, "thing_repository.count")
+	defer span.Finish()
+
+	return trm.repo.Count(ctx, owner, filter)
+}
+
+func (trm *thingRepositoryMiddleware) Multi(ctx context.Context, owner string, ids []string) []things.Thing {
+	span, ctx := StartSpan(ctx, trm.tracer, "thing_repository.multi")
+	defer span.Finish()
+
+	return trm.repo.Multi(ctx, owner, ids)
+}
+
+func (trm *thingRepositoryMiddleware) After(ctx context.Context, owner, afterID string, limit int) []things.Thing {
+	span, ctx := StartSpan(ctx, trm.tracer, "thing_repository.after")
+	defer span.Finish()
+
+	return trm.repo.After(ctx, owner, afterID, limit)
+}
+
+func (trm *thingRepositoryMiddleware) Search(ctx context.Context, owner, name string, offset, limit int) []things.Thing {
+	span, ctx := StartSpan(ctx, trm.tracer, "thing_repository.search")
+	defer span.Finish()
+
+	return trm.repo.Search(ctx, owner, name, offset, limit)
+}
+
+func (trm *thingRepositoryMiddleware) AllByMetadata(ctx context.Context, owner, metaKey, metaValue string, offset, limit int) []things.Thing {
+	span, ctx := StartSpan(ctx, trm.tracer, "thing_repository.all_by_metadata")
+	defer span.Finish()
+
+	return trm.repo.AllByMetadata(ctx, owner, metaKey, metaValue, offset, limit)
+}
+
+func (trm *thingRepositoryMiddleware) ChangeOwner(ctx context.Context, owner, id, newOwner string) error {
+	span, ctx := StartSpan(ctx, trm.tracer, "thing_repository.change_owner")
+	span.SetTag("thing_id", id)
+	defer span.Finish()
+
+	return trm.repo.ChangeOwner(ctx, owner, id, newOwner)
+}
+
+func (trm *thingRepositoryMiddleware) Remove(ctx context.Context, owner, id string) error {
+	span, ctx := StartSpan(ctx, trm.tracer, "thing_repository.remove")
+	span.SetTag("thing_id", id)
+	defer span.Finish()
+
+	return trm.repo.Remove(ctx, owner, id)
+}
+
+func (trm *thingRepositoryMiddleware) RemoveAll(ctx context.Context, owner string) error {
+	span, ctx := StartSpan(ctx, trm.tracer, "thing_repository.remove_all")
+	defer span.Finish()
+
+	return trm.repo.RemoveAll(ctx, owner)
+}
+
+func (trm *thingRepositoryMiddleware) Restore(ctx context.Context, owner, id string) error {
+	span, ctx := StartSpan(ctx, trm.tracer, "thing_repository.restore")
+	span.SetTag("thing_id", id)
+	defer span.Finish()
+
+	return trm.repo.Restore(ctx, owner, id)
+}
+
+func (trm *thingRepositoryMiddleware) Purge(ctx context.Context, olderThan time.Time) (int, error) {
+	span, ctx := StartSpan(ctx, trm.tracer, "thing_repository.purge")
+	defer span.Finish()
+
+	return trm.repo.Purge(ctx, olderThan)
+}
+
+func (trm *thingRepositoryMiddleware) Ping(ctx context.Context) error {
+	span, ctx := StartSpan(ctx, trm.tracer, "thing_repository.ping")
+	defer span.Finish()
+
+	return trm.repo.Ping(ctx)
+}
@@ -0,0 +1,21 @@
+package tracing
+
+import (
+	"context"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+// StartSpan starts the span of the specified operation. If the provided
+// context carries a span, the new span is its child. The returned context
+// carries the new span, so that the operations it is passed to are traced as
+// the span's children.
+func StartSpan(ctx context.Context, tracer opentracing.Tracer, operation string) (opentracing.Span, context.Context) {
+	var opts []opentracing.StartSpanOption
+	if parent := opentracing.SpanFromContext(ctx); parent != nil {
+		opts = append(opts, opentracing.ChildOf(parent.Context()))
+	}
+
+	span := tracer.StartSpan(operation, opts...)
+	return span, opentracing.ContextWithSpan(ctx, span)
+}
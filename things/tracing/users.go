@@ -0,0 +1,38 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/mainflux/mainflux"
+	opentracing "github.com/opentracing/opentracing-go"
+	"google.golang.org/grpc"
+)
+
+var _ mainflux.UsersServiceClient = (*usersServiceMiddleware)(nil)
+
+type usersServiceMiddleware struct {
+	tracer opentracing.Tracer
+	users  mainflux.UsersServiceClient
+}
+
+// UsersServiceMiddleware traces the calls made to the users service.
+func UsersServiceMiddleware(tracer opentracing.Tracer, users mainflux.UsersServiceClient) mainflux.UsersServiceClient {
+	return &usersServiceMiddleware{
+		tracer: tracer,
+		users:  users,
+	}
+}
+
+func (usm *usersServiceMiddleware) Identify(ctx context.Context, in *mainflux.Token, opts ...grpc.CallOption) (*mainflux.Identity, error) {
+	span, ctx := StartSpan(ctx, usm.tracer, "users.identify")
+	defer span.Finish()
+
+	return usm.users.Identify(ctx, in, opts...)
+}
+
+func (usm *usersServiceMiddleware) Resolve(ctx context.Context, in *mainflux.Identity, opts ...grpc.CallOption) (*mainflux.Identity, error) {
+	span, ctx := StartSpan(ctx, usm.tracer, "users.resolve")
+	defer span.Finish()
+
+	return usm.users.Resolve(ctx, in, opts...)
+}
@@ -0,0 +1,77 @@
+// Package ulid provides a ULID identity provider.
+package ulid
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/mainflux/mainflux/things"
+)
+
+// entropySize is the number of random bytes following the 6-byte timestamp.
+const entropySize = 10
+
+var _ things.IdentityProvider = (*ulidIdentityProvider)(nil)
+
+type ulidIdentityProvider struct {
+	mu      sync.Mutex
+	last    uint64
+	entropy [entropySize]byte
+}
+
+// New instantiates a ULID identity provider. Generated identifiers start with
+// the millisecond timestamp of their creation, so sorting them sorts them by
+// creation time as well. Identifiers generated within the same millisecond
+// are incremented, instead of being random, to preserve the order. To fit the
+// existing identifier columns and validation, ULIDs are formatted as UUIDs.
+func New() things.IdentityProvider {
+	return &ulidIdentityProvider{}
+}
+
+func (idp *ulidIdentityProvider) ID() string {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+
+	// If the clock goes backwards, the last timestamp is kept until the clock
+	// catches up with it, so that the ordering is preserved.
+	ms := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+	switch {
+	case ms > idp.last:
+		idp.last = ms
+		idp.randomize()
+	case !increment(idp.entropy[:]):
+		// entropy is exhausted, so the next millisecond is used up front
+		idp.last++
+		idp.randomize()
+	}
+
+	var id [16]byte
+	binary.BigEndian.PutUint16(id[0:2], uint16(idp.last>>32))
+	binary.BigEndian.PutUint32(id[2:6], uint32(idp.last))
+	copy(id[6:], idp.entropy[:])
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:16])
+}
+
+func (idp *ulidIdentityProvider) randomize() {
+	if _, err := io.ReadFull(rand.Reader, idp.entropy[:]); err != nil {
+		panic(fmt.Sprintf("failed to read random bytes: %s", err))
+	}
+}
+
+// increment adds one to the provided big-endian number, and reports whether
+// it did so without overflowing.
+func increment(b []byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+
+	return false
+}
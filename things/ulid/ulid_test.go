@@ -0,0 +1,22 @@
+package ulid_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/mainflux/mainflux/things/ulid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestID(t *testing.T) {
+	idp := ulid.New()
+
+	prev := ""
+	for i := 0; i < 1000; i++ {
+		id := idp.ID()
+		assert.True(t, govalidator.IsUUID(id), fmt.Sprintf("expected %s to be formatted as UUID", id))
+		assert.True(t, id > prev, fmt.Sprintf("expected %s to follow %s", id, prev))
+		prev = id
+	}
+}
@@ -0,0 +1,400 @@
+package things
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mainflux/mainflux/logger"
+)
+
+const (
+	// WebhookSignatureHeader is the header carrying the signature of the
+	// webhook notification. The signature is the hex encoded HMAC-SHA256 of
+	// the request body, computed with the webhook's secret and prefixed with
+	// "sha256=".
+	WebhookSignatureHeader = "X-Mainflux-Signature"
+
+	// DefaultWebhookRetries is the default number of times the failed
+	// webhook notification is retried.
+	DefaultWebhookRetries = 3
+
+	// DefaultWebhookBackoff is the default delay before the first retry of
+	// the failed webhook notification. The delay doubles with each retry.
+	DefaultWebhookBackoff = time.Second
+)
+
+// DefaultWebhookDeniedNets lists the address ranges the webhooks can't
+// target by default: the unspecified, loopback, private, shared, link-local
+// and unique local addresses. The link-local ranges include the metadata
+// endpoints of the cloud providers.
+var DefaultWebhookDeniedNets = []string{
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"::/128",
+	"::1/128",
+	"fc00::/7",
+	"fe80::/10",
+}
+
+var errWebhookDenied = errors.New("webhook address is denied")
+
+var defaultWebhookPolicy, _ = NewWebhookPolicy(nil, DefaultWebhookDeniedNets)
+
+var webhookDialer = &net.Dialer{
+	Timeout:   30 * time.Second,
+	KeepAlive: 30 * time.Second,
+}
+
+// WebhookPolicy restricts the hosts the webhooks can target, so that they
+// can't be used to reach the internal services. The host is denied if it
+// resolves to any of the denied address ranges, unless it is allowed
+// explicitly.
+type WebhookPolicy struct {
+	allowed map[string]bool
+	denied  []*net.IPNet
+}
+
+// NewWebhookPolicy creates the policy denying the provided address ranges,
+// given in CIDR notation, to all of the hosts but the allowed ones.
+func NewWebhookPolicy(allowedHosts, deniedNets []string) (WebhookPolicy, error) {
+	p := WebhookPolicy{allowed: make(map[string]bool, len(allowedHosts))}
+	for _, host := range allowedHosts {
+		p.allowed[strings.ToLower(host)] = true
+	}
+
+	for _, cidr := range deniedNets {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return WebhookPolicy{}, err
+		}
+		p.denied = append(p.denied, n)
+	}
+
+	return p, nil
+}
+
+// Check returns ErrMalformedEntity if the webhook targets the denied host.
+// Only the hosts given as the addresses, and the local host, are checked,
+// since the rest of them are resolved once the notification is sent.
+func (p WebhookPolicy) Check(w Webhook) error {
+	u, err := url.Parse(w.URL)
+	if err != nil {
+		return ErrMalformedEntity
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if p.allowed[host] {
+		return nil
+	}
+
+	ip := net.ParseIP(host)
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		ip = net.IPv4(127, 0, 0, 1)
+	}
+
+	if ip != nil && p.denies(ip) {
+		return ErrMalformedEntity
+	}
+
+	return nil
+}
+
+// DialContext connects to the address like net.Dialer does, except that it
+// refuses to connect to the host resolved to any of the denied addresses.
+// It is meant to be used by the transport of the webhooks' HTTP client, so
+// that the policy applies to the redirects as well.
+func (p WebhookPolicy) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.allowed[strings.ToLower(host)] {
+		return webhookDialer.DialContext(ctx, network, addr)
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range addrs {
+		if p.denies(a.IP) {
+			return nil, errWebhookDenied
+		}
+	}
+
+	// dial the checked address, rather than resolving the host once again
+	return webhookDialer.DialContext(ctx, network, net.JoinHostPort(addrs[0].IP.String(), port))
+}
+
+func (p WebhookPolicy) denies(ip net.IP) bool {
+	for _, n := range p.denied {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HTTPClient specifies an API for sending the webhook notifications. It is
+// implemented by *http.Client.
+type HTTPClient interface {
+	// Do sends the HTTP request and returns the HTTP response.
+	Do(*http.Request) (*http.Response, error)
+}
+
+// WebhookNotification represents the payload posted to the channel's webhook
+// when the thing is connected to or disconnected from the channel. Event is
+// either EventConnect or EventDisconnect.
+type WebhookNotification struct {
+	Event     string    `json:"event"`
+	ChanID    string    `json:"channel_id"`
+	ThingID   string    `json:"thing_id"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+var _ Service = (*webhookService)(nil)
+
+type webhookService struct {
+	Service
+	channels ChannelRepository
+	client   HTTPClient
+	retries  int
+	backoff  time.Duration
+	logger   logger.Logger
+}
+
+// NewWebhookService decorates the provided service with notifying the
+// channels' webhooks about the connected and disconnected things. The
+// webhooks are resolved using the provided repository, and the notifications
+// are sent asynchronously. Each failed one is retried the provided number of
+// times, starting with the provided backoff. Failed notifications are logged
+// without failing the operation itself.
+func NewWebhookService(svc Service, channels ChannelRepository, client HTTPClient, retries int, backoff time.Duration, logger logger.Logger) Service {
+	return &webhookService{
+		Service:  svc,
+		channels: channels,
+		client:   client,
+		retries:  retries,
+		backoff:  backoff,
+		logger:   logger,
+	}
+}
+
+func (ws *webhookService) RemoveThing(ctx context.Context, key, id string) error {
+	ctx, rec := recordCall(ctx)
+	err := ws.Service.RemoveThing(ctx, key, id)
+	ws.notify(rec)
+	return err
+}
+
+func (ws *webhookService) RemoveAllThings(ctx context.Context, key string) error {
+	ctx, rec := recordCall(ctx)
+	err := ws.Service.RemoveAllThings(ctx, key)
+	ws.notify(rec)
+	return err
+}
+
+func (ws *webhookService) TransferThing(ctx context.Context, key, id, newOwner string) error {
+	ctx, rec := recordCall(ctx)
+	err := ws.Service.TransferThing(ctx, key, id, newOwner)
+	ws.notify(rec)
+	return err
+}
+
+func (ws *webhookService) RemoveChannel(ctx context.Context, key, id string) error {
+	ctx, rec := recordCall(ctx)
+	err := ws.Service.RemoveChannel(ctx, key, id)
+	ws.notify(rec)
+	return err
+}
+
+func (ws *webhookService) RestoreChannel(ctx context.Context, key, id string) error {
+	ctx, rec := recordCall(ctx)
+	err := ws.Service.RestoreChannel(ctx, key, id)
+	ws.notify(rec)
+	return err
+}
+
+func (ws *webhookService) RemoveAllChannels(ctx context.Context, key string) error {
+	ctx, rec := recordCall(ctx)
+	err := ws.Service.RemoveAllChannels(ctx, key)
+	ws.notify(rec)
+	return err
+}
+
+func (ws *webhookService) TransferChannel(ctx context.Context, key, id, newOwner string) error {
+	ctx, rec := recordCall(ctx)
+	err := ws.Service.TransferChannel(ctx, key, id, newOwner)
+	ws.notify(rec)
+	return err
+}
+
+func (ws *webhookService) Connect(ctx context.Context, key, chanID, thingID string, mode AccessMode) (Connection, error) {
+	ctx, rec := recordCall(ctx)
+	conn, err := ws.Service.Connect(ctx, key, chanID, thingID, mode)
+	ws.notify(rec)
+	return conn, err
+}
+
+func (ws *webhookService) ConnectMany(ctx context.Context, key, thingID string, chanIDs []string) error {
+	ctx, rec := recordCall(ctx)
+	err := ws.Service.ConnectMany(ctx, key, thingID, chanIDs)
+	ws.notify(rec)
+	return err
+}
+
+func (ws *webhookService) ConnectThings(ctx context.Context, key, chanID string, thingIDs []string) error {
+	ctx, rec := recordCall(ctx)
+	err := ws.Service.ConnectThings(ctx, key, chanID, thingIDs)
+	ws.notify(rec)
+	return err
+}
+
+func (ws *webhookService) SetChannelThings(ctx context.Context, key, chanID string, thingIDs []string) error {
+	ctx, rec := recordCall(ctx)
+	err := ws.Service.SetChannelThings(ctx, key, chanID, thingIDs)
+	ws.notify(rec)
+	return err
+}
+
+func (ws *webhookService) Disconnect(ctx context.Context, key, chanID, thingID string) (bool, error) {
+	ctx, rec := recordCall(ctx)
+	removed, err := ws.Service.Disconnect(ctx, key, chanID, thingID)
+	ws.notify(rec)
+	return removed, err
+}
+
+func (ws *webhookService) DisconnectMany(ctx context.Context, key, thingID string, chanIDs []string) error {
+	ctx, rec := recordCall(ctx)
+	err := ws.Service.DisconnectMany(ctx, key, thingID, chanIDs)
+	ws.notify(rec)
+	return err
+}
+
+func (ws *webhookService) DisconnectAll(ctx context.Context, key, thingID string) error {
+	ctx, rec := recordCall(ctx)
+	err := ws.Service.DisconnectAll(ctx, key, thingID)
+	ws.notify(rec)
+	return err
+}
+
+func (ws *webhookService) RebindConnections(ctx context.Context, key, fromID, toID string) error {
+	ctx, rec := recordCall(ctx)
+	err := ws.Service.RebindConnections(ctx, key, fromID, toID)
+	ws.notify(rec)
+	return err
+}
+
+// notify dispatches the notifications about the connections made and removed
+// by the recorded call in the background. The webhooks of the channels that
+// are already removed or transferred to another user aren't notified.
+func (ws *webhookService) notify(rec *callRecorder) {
+	now := time.Now().UTC()
+	var ns []WebhookNotification
+	for _, conn := range rec.connected {
+		ns = append(ns, WebhookNotification{Event: EventConnect, ChanID: conn.ChanID, ThingID: conn.ThingID, Timestamp: now})
+	}
+	for _, conn := range rec.disconnected {
+		ns = append(ns, WebhookNotification{Event: EventDisconnect, ChanID: conn.ChanID, ThingID: conn.ThingID, Timestamp: now})
+	}
+
+	if len(ns) > 0 {
+		go ws.resolve(rec.owner, ns)
+	}
+}
+
+// resolve looks up the webhooks of the notified channels, and dispatches the
+// notifications to them.
+func (ws *webhookService) resolve(owner string, ns []WebhookNotification) {
+	hooks := make(map[string]*Webhook)
+	for _, n := range ns {
+		hook, ok := hooks[n.ChanID]
+		if !ok {
+			channel, err := ws.channels.One(context.Background(), owner, n.ChanID)
+			if err != nil && err != ErrNotFound {
+				ws.logger.Warn(fmt.Sprintf("Failed to resolve webhook of channel %s: %s", n.ChanID, err))
+			}
+			hook = channel.Webhook
+			hooks[n.ChanID] = hook
+		}
+
+		if hook != nil {
+			go ws.dispatch(*hook, n)
+		}
+	}
+}
+
+func (ws *webhookService) dispatch(hook Webhook, n WebhookNotification) {
+	body, err := json.Marshal(n)
+	if err != nil {
+		ws.logger.Warn(fmt.Sprintf("Failed to encode %s notification of channel %s: %s", n.Event, n.ChanID, err))
+		return
+	}
+
+	backoff := ws.backoff
+	for attempt := 0; ; attempt++ {
+		err := ws.post(hook, body)
+		if err == nil {
+			return
+		}
+
+		if attempt >= ws.retries {
+			ws.logger.Warn(fmt.Sprintf("Failed to notify webhook of channel %s about %s of thing %s: %s", n.ChanID, n.Event, n.ThingID, err))
+			return
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func (ws *webhookService) post(hook Webhook, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if hook.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, signature(hook.Secret, body))
+	}
+
+	res, err := ws.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected response status %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+// signature computes the signature of the provided body, as carried by the
+// WebhookSignatureHeader.
+func signature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return fmt.Sprintf("sha256=%s", hex.EncodeToString(mac.Sum(nil)))
+}
@@ -0,0 +1,264 @@
+package things_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/things"
+	"github.com/mainflux/mainflux/things/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	hookURL    = "https://example.com/hook"
+	hookSecret = "secret"
+)
+
+func newWebhookService(client things.HTTPClient, retries int, backoff time.Duration, logger logger.Logger) things.Service {
+	users := mocks.NewUsersService(map[string]string{token: email})
+	thingsRepo := mocks.NewThingRepository()
+	channelsRepo := mocks.NewChannelRepository(thingsRepo)
+
+	svc := things.New(users, thingsRepo, channelsRepo, mocks.NewIdentityProvider())
+	return things.NewWebhookService(svc, channelsRepo, client, retries, backoff, logger)
+}
+
+// waitRequests waits until the client records the expected number of
+// requests, or the second passes.
+func waitRequests(client mocks.WebhookClient, n int) []mocks.WebhookRequest {
+	for i := 0; i < 100; i++ {
+		if reqs := client.Requests(); len(reqs) >= n {
+			return reqs
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return client.Requests()
+}
+
+// waitLogged waits until the log contains the provided message, or the second
+// passes.
+func waitLogged(log *syncBuffer, msg string) {
+	for i := 0; i < 100; i++ {
+		if strings.Contains(log.String(), msg) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// syncBuffer is the log writer that can be read while the dispatchers write
+// to it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (sb *syncBuffer) Write(p []byte) (int, error) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.buf.Write(p)
+}
+
+func (sb *syncBuffer) String() string {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.buf.String()
+}
+
+func TestWebhookService(t *testing.T) {
+	client := mocks.NewWebhookClient(0)
+	svc := newWebhookService(client, 0, time.Millisecond, logger.New(&syncBuffer{}))
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	ch := channel
+	ch.Webhook = &things.Webhook{URL: hookURL, Secret: hookSecret}
+	sch, _ := svc.CreateChannel(context.Background(), token, ch)
+
+	cases := []struct {
+		desc    string
+		operate func() error
+		event   string
+	}{
+		{
+			desc: "connect thing",
+			operate: func() error {
+				_, err := svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+				return err
+			},
+			event: things.EventConnect,
+		},
+		{
+			desc: "disconnect thing",
+			operate: func() error {
+				_, err := svc.Disconnect(context.Background(), token, sch.ID, sth.ID)
+				return err
+			},
+			event: things.EventDisconnect,
+		},
+	}
+
+	for i, tc := range cases {
+		err := tc.operate()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s\n", tc.desc, err))
+
+		reqs := waitRequests(client, i+1)
+		if !assert.Len(t, reqs, i+1, fmt.Sprintf("%s: expected %d requests got %d\n", tc.desc, i+1, len(reqs))) {
+			continue
+		}
+
+		req := reqs[i]
+		assert.Equal(t, hookURL, req.URL, fmt.Sprintf("%s: expected URL %s got %s\n", tc.desc, hookURL, req.URL))
+
+		var n things.WebhookNotification
+		err = json.Unmarshal(req.Body, &n)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s\n", tc.desc, err))
+		assert.Equal(t, tc.event, n.Event, fmt.Sprintf("%s: expected event %s got %s\n", tc.desc, tc.event, n.Event))
+		assert.Equal(t, sch.ID, n.ChanID, fmt.Sprintf("%s: expected channel %s got %s\n", tc.desc, sch.ID, n.ChanID))
+		assert.Equal(t, sth.ID, n.ThingID, fmt.Sprintf("%s: expected thing %s got %s\n", tc.desc, sth.ID, n.ThingID))
+
+		mac := hmac.New(sha256.New, []byte(hookSecret))
+		mac.Write(req.Body)
+		sig := fmt.Sprintf("sha256=%s", hex.EncodeToString(mac.Sum(nil)))
+		got := req.Header.Get(things.WebhookSignatureHeader)
+		assert.Equal(t, sig, got, fmt.Sprintf("%s: expected signature %s got %s\n", tc.desc, sig, got))
+	}
+}
+
+func TestWebhookServiceWithoutWebhook(t *testing.T) {
+	client := mocks.NewWebhookClient(0)
+	svc := newWebhookService(client, 0, time.Millisecond, logger.New(&syncBuffer{}))
+
+	sth, _ := svc.AddThing(context.Background(), token, thing)
+	sch, _ := svc.CreateChannel(context.Background(), token, channel)
+	svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+	svc.Disconnect(context.Background(), token, sch.ID, sth.ID)
+
+	// failed operations don't notify the webhook either
+	hch := channel
+	hch.Webhook = &things.Webhook{URL: hookURL}
+	shch, _ := svc.CreateChannel(context.Background(), token, hch)
+	svc.Connect(context.Background(), token, shch.ID, wrong, things.AccessPubSub)
+	svc.Disconnect(context.Background(), token, shch.ID, sth.ID)
+
+	// the notification made afterwards has to be the only one
+	svc.Connect(context.Background(), token, shch.ID, sth.ID, things.AccessPubSub)
+	reqs := waitRequests(client, 1)
+	if assert.Len(t, reqs, 1, fmt.Sprintf("expected %d requests got %d\n", 1, len(reqs))) {
+		var n things.WebhookNotification
+		err := json.Unmarshal(reqs[0].Body, &n)
+		assert.Nil(t, err, fmt.Sprintf("unexpected error %s\n", err))
+		assert.Equal(t, things.EventConnect, n.Event, fmt.Sprintf("expected event %s got %s\n", things.EventConnect, n.Event))
+		assert.Equal(t, shch.ID, n.ChanID, fmt.Sprintf("expected channel %s got %s\n", shch.ID, n.ChanID))
+	}
+}
+
+func TestWebhookServiceBulkConnections(t *testing.T) {
+	client := mocks.NewWebhookClient(0)
+	svc := newWebhookService(client, 0, time.Millisecond, logger.New(&syncBuffer{}))
+
+	th1, _ := svc.AddThing(context.Background(), token, thing)
+	th2, _ := svc.AddThing(context.Background(), token, thing)
+	ch := channel
+	ch.Webhook = &things.Webhook{URL: hookURL}
+	sch, _ := svc.CreateChannel(context.Background(), token, ch)
+
+	err := svc.ConnectThings(context.Background(), token, sch.ID, []string{th1.ID, th2.ID})
+	assert.Nil(t, err, fmt.Sprintf("connect things: unexpected error %s\n", err))
+
+	err = svc.DisconnectAll(context.Background(), token, th1.ID)
+	assert.Nil(t, err, fmt.Sprintf("disconnect all: unexpected error %s\n", err))
+
+	reqs := waitRequests(client, 3)
+	notified := map[string]int{}
+	for _, req := range reqs {
+		var n things.WebhookNotification
+		err := json.Unmarshal(req.Body, &n)
+		assert.Nil(t, err, fmt.Sprintf("unexpected error %s\n", err))
+		notified[fmt.Sprintf("%s %s", n.Event, n.ThingID)]++
+	}
+
+	expected := map[string]int{
+		fmt.Sprintf("%s %s", things.EventConnect, th1.ID):    1,
+		fmt.Sprintf("%s %s", things.EventConnect, th2.ID):    1,
+		fmt.Sprintf("%s %s", things.EventDisconnect, th1.ID): 1,
+	}
+	assert.Equal(t, expected, notified, fmt.Sprintf("expected notifications %v got %v\n", expected, notified))
+}
+
+func TestWebhookPolicy(t *testing.T) {
+	policy, err := things.NewWebhookPolicy([]string{"hooks.local"}, things.DefaultWebhookDeniedNets)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error %s\n", err))
+
+	cases := map[string]struct {
+		url string
+		err error
+	}{
+		"check public host":             {"https://example.com/hook", nil},
+		"check public address":          {"http://93.184.216.34/hook", nil},
+		"check loopback address":        {"http://127.0.0.1:8180/hook", things.ErrMalformedEntity},
+		"check loopback IPv6 address":   {"http://[::1]/hook", things.ErrMalformedEntity},
+		"check local host":              {"http://localhost/hook", things.ErrMalformedEntity},
+		"check private address":         {"http://10.0.0.5/hook", things.ErrMalformedEntity},
+		"check metadata address":        {"http://169.254.169.254/latest/meta-data", things.ErrMalformedEntity},
+		"check mapped loopback address": {"http://[::ffff:127.0.0.1]/hook", things.ErrMalformedEntity},
+		"check allowed host":            {"http://hooks.local/hook", nil},
+	}
+
+	for desc, tc := range cases {
+		err := policy.Check(things.Webhook{URL: tc.url})
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+
+	_, err = policy.DialContext(context.Background(), "tcp", "localhost:8180")
+	assert.NotNil(t, err, fmt.Sprintf("dial local host: expected error\n"))
+}
+
+func TestWebhookServiceRetries(t *testing.T) {
+	retries := 2
+
+	cases := []struct {
+		desc     string
+		failures int
+		requests int
+		logged   bool
+	}{
+		{"notify webhook that recovers", retries, retries + 1, false},
+		{"notify failing webhook", retries + 1, retries + 1, true},
+	}
+
+	for _, tc := range cases {
+		var log syncBuffer
+		client := mocks.NewWebhookClient(tc.failures)
+		svc := newWebhookService(client, retries, time.Millisecond, logger.New(&log))
+
+		sth, _ := svc.AddThing(context.Background(), token, thing)
+		ch := channel
+		ch.Webhook = &things.Webhook{URL: hookURL}
+		sch, _ := svc.CreateChannel(context.Background(), token, ch)
+
+		_, err := svc.Connect(context.Background(), token, sch.ID, sth.ID, things.AccessPubSub)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s\n", tc.desc, err))
+
+		// the dispatcher stops either on success, or after logging the failure
+		waitRequests(client, tc.requests)
+		if tc.logged {
+			waitLogged(&log, "Failed to notify webhook")
+		}
+		reqs := client.Requests()
+		assert.Len(t, reqs, tc.requests, fmt.Sprintf("%s: expected %d requests got %d\n", tc.desc, tc.requests, len(reqs)))
+
+		logged := strings.Contains(log.String(), "Failed to notify webhook")
+		assert.Equal(t, tc.logged, logged, fmt.Sprintf("%s: expected logged failure %t got %t\n", tc.desc, tc.logged, logged))
+	}
+}
@@ -12,20 +12,29 @@ var _ mainflux.UsersServiceClient = (*grpcClient)(nil)
 
 type grpcClient struct {
 	identify endpoint.Endpoint
+	resolve  endpoint.Endpoint
 }
 
 // NewClient returns new gRPC client instance.
 func NewClient(conn *grpc.ClientConn) mainflux.UsersServiceClient {
-	endpoint := kitgrpc.NewClient(
-		conn,
-		"mainflux.UsersService",
-		"Identify",
-		encodeIdentifyRequest,
-		decodeIdentifyResponse,
-		mainflux.Identity{},
-	).Endpoint()
-
-	return &grpcClient{endpoint}
+	return &grpcClient{
+		identify: kitgrpc.NewClient(
+			conn,
+			"mainflux.UsersService",
+			"Identify",
+			encodeIdentifyRequest,
+			decodeIdentifyResponse,
+			mainflux.Identity{},
+		).Endpoint(),
+		resolve: kitgrpc.NewClient(
+			conn,
+			"mainflux.UsersService",
+			"Resolve",
+			encodeResolveRequest,
+			decodeIdentifyResponse,
+			mainflux.Identity{},
+		).Endpoint(),
+	}
 }
 
 func (client grpcClient) Identify(ctx context.Context, token *mainflux.Token, _ ...grpc.CallOption) (*mainflux.Identity, error) {
@@ -38,11 +47,26 @@ func (client grpcClient) Identify(ctx context.Context, token *mainflux.Token, _
 	return &mainflux.Identity{Value: ir.id}, ir.err
 }
 
+func (client grpcClient) Resolve(ctx context.Context, id *mainflux.Identity, _ ...grpc.CallOption) (*mainflux.Identity, error) {
+	res, err := client.resolve(ctx, resolveReq{id.GetValue()})
+	if err != nil {
+		return nil, err
+	}
+
+	ir := res.(identityRes)
+	return &mainflux.Identity{Value: ir.id}, ir.err
+}
+
 func encodeIdentifyRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
 	req := grpcReq.(identityReq)
 	return &mainflux.Token{Value: req.token}, nil
 }
 
+func encodeResolveRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(resolveReq)
+	return &mainflux.Identity{Value: req.email}, nil
+}
+
 func decodeIdentifyResponse(_ context.Context, grpcRes interface{}) (interface{}, error) {
 	res := grpcRes.(*mainflux.Identity)
 	return identityRes{res.GetValue(), nil}, nil
@@ -20,3 +20,18 @@ func identifyEndpoint(svc users.Service) endpoint.Endpoint {
 		return identityRes{id, nil}, nil
 	}
 }
+
+func resolveEndpoint(svc users.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(resolveReq)
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		id, err := svc.Resolve(req.email)
+		if err != nil {
+			return identityRes{}, err
+		}
+		return identityRes{id, nil}, nil
+	}
+}
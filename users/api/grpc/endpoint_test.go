@@ -62,3 +62,31 @@ func TestIdentify(t *testing.T) {
 		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s", desc, tc.err, err))
 	}
 }
+
+func TestResolve(t *testing.T) {
+	svc := newService()
+	startGRPCServer(svc, port+1)
+	svc.Register(user)
+
+	usersAddr := fmt.Sprintf("localhost:%d", port+1)
+	conn, _ := grpc.Dial(usersAddr, grpc.WithInsecure())
+	client := grpcapi.NewClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	cases := map[string]struct {
+		email string
+		id    string
+		err   error
+	}{
+		"resolve registered user":     {user.Email, user.Email, nil},
+		"resolve non-registered user": {"jane.doe@email.com", "", status.Error(codes.NotFound, "user is not registered")},
+		"resolve user without email":  {"", "", status.Error(codes.InvalidArgument, "received invalid token request")},
+	}
+
+	for desc, tc := range cases {
+		id, err := client.Resolve(ctx, &mainflux.Identity{Value: tc.email})
+		assert.Equal(t, tc.id, id.GetValue(), fmt.Sprintf("%s: expected %s got %s", desc, tc.id, id.GetValue()))
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s", desc, tc.err, err))
+	}
+}
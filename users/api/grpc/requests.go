@@ -14,3 +14,14 @@ func (req identityReq) validate() error {
 	}
 	return nil
 }
+
+type resolveReq struct {
+	email string
+}
+
+func (req resolveReq) validate() error {
+	if req.email == "" {
+		return users.ErrMalformedEntity
+	}
+	return nil
+}
@@ -12,21 +12,36 @@ import (
 var _ mainflux.UsersServiceServer = (*grpcServer)(nil)
 
 type grpcServer struct {
-	handler kitgrpc.Handler
+	identify kitgrpc.Handler
+	resolve  kitgrpc.Handler
 }
 
 // NewServer returns new UsersServiceServer instance.
 func NewServer(svc users.Service) mainflux.UsersServiceServer {
-	handler := kitgrpc.NewServer(
-		identifyEndpoint(svc),
-		decodeIdentifyRequest,
-		encodeIdentifyResponse,
-	)
-	return &grpcServer{handler}
+	return &grpcServer{
+		identify: kitgrpc.NewServer(
+			identifyEndpoint(svc),
+			decodeIdentifyRequest,
+			encodeIdentifyResponse,
+		),
+		resolve: kitgrpc.NewServer(
+			resolveEndpoint(svc),
+			decodeResolveRequest,
+			encodeIdentifyResponse,
+		),
+	}
 }
 
 func (s *grpcServer) Identify(ctx context.Context, token *mainflux.Token) (*mainflux.Identity, error) {
-	_, res, err := s.handler.ServeGRPC(ctx, token)
+	_, res, err := s.identify.ServeGRPC(ctx, token)
+	if err != nil {
+		return nil, encodeError(err)
+	}
+	return res.(*mainflux.Identity), nil
+}
+
+func (s *grpcServer) Resolve(ctx context.Context, id *mainflux.Identity) (*mainflux.Identity, error) {
+	_, res, err := s.resolve.ServeGRPC(ctx, id)
 	if err != nil {
 		return nil, encodeError(err)
 	}
@@ -38,6 +53,11 @@ func decodeIdentifyRequest(_ context.Context, grpcReq interface{}) (interface{},
 	return identityReq{req.GetValue()}, nil
 }
 
+func decodeResolveRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*mainflux.Identity)
+	return resolveReq{req.GetValue()}, nil
+}
+
 func encodeIdentifyResponse(_ context.Context, grpcRes interface{}) (interface{}, error) {
 	res := grpcRes.(identityRes)
 	return &mainflux.Identity{Value: res.id}, encodeError(res.err)
@@ -53,6 +73,8 @@ func encodeError(err error) error {
 		return status.Error(codes.InvalidArgument, "received invalid token request")
 	case users.ErrUnauthorizedAccess:
 		return status.Error(codes.Unauthenticated, "failed to identify user from token")
+	case users.ErrNotFound:
+		return status.Error(codes.NotFound, "user is not registered")
 	default:
 		return status.Error(codes.Internal, "internal server error")
 	}
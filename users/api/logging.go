@@ -59,3 +59,16 @@ func (lm *loggingMiddleware) Identify(key string) (id string, err error) {
 
 	return lm.svc.Identify(key)
 }
+
+func (lm *loggingMiddleware) Resolve(email string) (id string, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method resolve for user %s took %s to complete", email, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.Resolve(email)
+}
@@ -51,3 +51,12 @@ func (ms *metricsMiddleware) Identify(key string) (string, error) {
 
 	return ms.svc.Identify(key)
 }
+
+func (ms *metricsMiddleware) Resolve(email string) (string, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "resolve").Add(1)
+		ms.latency.With("method", "resolve").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.Resolve(email)
+}
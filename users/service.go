@@ -35,6 +35,10 @@ type Service interface {
 	// is returned. If token is invalid, or invocation failed for some
 	// other reason, non-nil error values are returned in response.
 	Identify(string) (string, error)
+
+	// Resolve retrieves the identifier of the registered user having the
+	// provided email. ErrNotFound is returned if no such user is registered.
+	Resolve(string) (string, error)
 }
 
 var _ Service = (*usersService)(nil)
@@ -80,3 +84,11 @@ func (svc usersService) Identify(token string) (string, error) {
 	}
 	return id, nil
 }
+
+func (svc usersService) Resolve(email string) (string, error) {
+	user, err := svc.users.One(email)
+	if err != nil {
+		return "", err
+	}
+	return user.Email, nil
+}
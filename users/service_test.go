@@ -77,3 +77,23 @@ func TestIdentify(t *testing.T) {
 		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
 	}
 }
+
+func TestResolve(t *testing.T) {
+	svc := newService()
+	svc.Register(user)
+
+	cases := map[string]struct {
+		email string
+		id    string
+		err   error
+	}{
+		"resolve registered user":     {user.Email, user.Email, nil},
+		"resolve non-registered user": {wrong, "", users.ErrNotFound},
+	}
+
+	for desc, tc := range cases {
+		id, err := svc.Resolve(tc.email)
+		assert.Equal(t, tc.id, id, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.id, id))
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+}
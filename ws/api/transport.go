@@ -121,7 +121,8 @@ func authorize(r *http.Request) (subscription, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
 
-	id, err := auth.CanAccess(ctx, &mainflux.AccessReq{Token: authKey, ChanID: chanID})
+	// the connection is used both to publish and to receive the messages
+	id, err := auth.CanAccess(ctx, &mainflux.AccessReq{Token: authKey, ChanID: chanID, Mode: string(things.AccessPubSub)})
 	if err != nil {
 		return subscription{}, err
 	}
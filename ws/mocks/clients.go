@@ -7,6 +7,7 @@ import (
 	"github.com/mainflux/mainflux/things"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 )
 
 var _ mainflux.ThingsServiceClient = (*thingsClient)(nil)
@@ -33,3 +34,17 @@ func (tc thingsClient) CanAccess(ctx context.Context, req *mainflux.AccessReq, o
 
 	return &mainflux.Identity{Value: id}, nil
 }
+
+func (tc thingsClient) CanAccessBatch(ctx context.Context, req *mainflux.AccessBatchReq, opts ...grpc.CallOption) (*mainflux.AccessBatchRes, error) {
+	results := make([]*mainflux.AccessResult, len(req.GetRequests()))
+	for i, r := range req.GetRequests() {
+		id, err := tc.CanAccess(ctx, r, opts...)
+		if err != nil {
+			results[i] = &mainflux.AccessResult{Code: uint32(codes.PermissionDenied), Error: err.Error()}
+			continue
+		}
+		results[i] = &mainflux.AccessResult{Value: id.GetValue()}
+	}
+
+	return &mainflux.AccessBatchRes{Results: results}, nil
+}